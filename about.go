@@ -0,0 +1,187 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	rtdebug "runtime/debug" // debug is the --debug flag
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var aboutSBOM bool
+
+const aboutHelpTemplate = `USAGE:
+  snag about [--sbom]
+
+DESCRIPTION:
+  Show what this snag binary is: its version, the commit it was built
+  from, and how it was built. snag sends no telemetry; it only contacts
+  the URLs it is given, GitHub when --doctor checks for a newer release
+  (skipped with --offline), and the provider "snag auth" signs in to.
+
+  With --sbom, print the build's modules and settings as JSON, read from
+  the binary itself, so it can be checked without other tools.
+
+EXAMPLES:
+  snag about
+  snag about --sbom > snag-sbom.json
+
+OPTIONS:
+      --sbom                   Print dependencies and build provenance as JSON
+  -h, --help                   help for about
+`
+
+var aboutCmd = &cobra.Command{
+	Use:          "about",
+	Short:        "Show version, build provenance, and dependencies",
+	Args:         cobra.NoArgs,
+	RunE:         runAbout,
+	SilenceUsage: true,
+}
+
+func init() {
+	aboutCmd.Flags().BoolVar(&aboutSBOM, "sbom", false, "Print dependencies and build provenance as JSON")
+
+	aboutCmd.SetHelpTemplate(aboutHelpTemplate)
+
+	rootCmd.AddCommand(aboutCmd)
+}
+
+// BuildReport describes how the running binary was built, from the
+// module and build information the Go toolchain embeds in it.
+type BuildReport struct {
+	Name          string            `json:"name"`
+	Version       string            `json:"version"`
+	Module        string            `json:"module"`
+	ModuleVersion string            `json:"module_version"`
+	GoVersion     string            `json:"go_version"`
+	OS            string            `json:"os"`
+	Arch          string            `json:"arch"`
+	VCS           *VCSInfo          `json:"vcs,omitempty"`
+	BuildSettings map[string]string `json:"build_settings"`
+	Dependencies  []Dependency      `json:"dependencies"`
+}
+
+// VCSInfo is the commit a binary was built from.
+type VCSInfo struct {
+	System   string `json:"system"`
+	Revision string `json:"revision"`
+	Time     string `json:"time,omitempty"`
+	Modified bool   `json:"modified"`
+}
+
+// Dependency is a module compiled into the binary, with the go.sum hash
+// it was verified against.
+type Dependency struct {
+	Path    string      `json:"path"`
+	Version string      `json:"version"`
+	Sum     string      `json:"sum,omitempty"`
+	Replace *Dependency `json:"replace,omitempty"`
+}
+
+// newBuildReport builds a report from info, as returned by
+// debug.ReadBuildInfo.
+func newBuildReport(info *rtdebug.BuildInfo) *BuildReport {
+	report := &BuildReport{
+		Name:          "snag",
+		Version:       version,
+		Module:        info.Main.Path,
+		ModuleVersion: info.Main.Version,
+		GoVersion:     info.GoVersion,
+		OS:            runtime.GOOS,
+		Arch:          runtime.GOARCH,
+		BuildSettings: make(map[string]string),
+		Dependencies:  []Dependency{},
+	}
+
+	vcs := &VCSInfo{}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs":
+			vcs.System = setting.Value
+		case "vcs.revision":
+			vcs.Revision = setting.Value
+		case "vcs.time":
+			vcs.Time = setting.Value
+		case "vcs.modified":
+			vcs.Modified, _ = strconv.ParseBool(setting.Value)
+		default:
+			report.BuildSettings[setting.Key] = setting.Value
+		}
+	}
+	if vcs.Revision != "" {
+		report.VCS = vcs
+	}
+
+	for _, dep := range info.Deps {
+		report.Dependencies = append(report.Dependencies, newDependency(dep))
+	}
+
+	return report
+}
+
+func newDependency(mod *rtdebug.Module) Dependency {
+	dep := Dependency{Path: mod.Path, Version: mod.Version, Sum: mod.Sum}
+	if mod.Replace != nil {
+		replace := newDependency(mod.Replace)
+		dep.Replace = &replace
+	}
+	return dep
+}
+
+func (r *BuildReport) String() string {
+	commit := "(unknown)"
+	if r.VCS != nil {
+		commit = r.VCS.Revision
+		if r.VCS.Modified {
+			commit += " (modified)"
+		}
+		if r.VCS.Time != "" {
+			commit += ", " + r.VCS.Time
+		}
+	}
+
+	return fmt.Sprintf(`snag %s
+  Module:        %s %s
+  Commit:        %s
+  Go version:    %s
+  OS/Arch:       %s/%s
+  Dependencies:  %d modules (snag about --sbom to list them)
+  Telemetry:     none
+`, r.Version, r.Module, r.ModuleVersion, commit, r.GoVersion, r.OS, r.Arch, len(r.Dependencies))
+}
+
+func runAbout(cmd *cobra.Command, args []string) error {
+	logger = NewLogger(LevelNormal)
+
+	info, ok := rtdebug.ReadBuildInfo()
+	if !ok {
+		logger.Error("This snag binary has no build information")
+		logger.ErrorWithSuggestion(
+			"Build information is embedded by go build with module support",
+			"go install github.com/grantcarthew/snag@latest",
+		)
+		return fmt.Errorf("no build information")
+	}
+	report := newBuildReport(info)
+
+	if !aboutSBOM {
+		fmt.Fprint(cmd.OutOrStdout(), report.String())
+		return nil
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode build report: %w", err)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), string(data))
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"encoding/json"
+	rtdebug "runtime/debug"
+	"testing"
+)
+
+func TestNewBuildReport(t *testing.T) {
+	info := &rtdebug.BuildInfo{
+		GoVersion: "go1.25.3",
+		Main:      rtdebug.Module{Path: "github.com/grantcarthew/snag", Version: "v1.2.0"},
+		Deps: []*rtdebug.Module{
+			{Path: "github.com/go-rod/rod", Version: "v0.116.2", Sum: "h1:abc="},
+			{Path: "golang.org/x/net", Version: "v0.30.0", Replace: &rtdebug.Module{Path: "../net", Version: ""}},
+		},
+		Settings: []rtdebug.BuildSetting{
+			{Key: "-ldflags", Value: "-s -w"},
+			{Key: "CGO_ENABLED", Value: "0"},
+			{Key: "vcs", Value: "git"},
+			{Key: "vcs.revision", Value: "9543ee69bef932d3a8fe06505cfe080d9d8f61f2"},
+			{Key: "vcs.time", Value: "2025-10-22T14:20:33Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+
+	report := newBuildReport(info)
+
+	if report.Module != "github.com/grantcarthew/snag" || report.ModuleVersion != "v1.2.0" {
+		t.Errorf("module = %s %s", report.Module, report.ModuleVersion)
+	}
+	if report.VCS == nil || report.VCS.Revision != "9543ee69bef932d3a8fe06505cfe080d9d8f61f2" || !report.VCS.Modified {
+		t.Errorf("vcs = %+v, want the revision marked modified", report.VCS)
+	}
+	if report.BuildSettings["-ldflags"] != "-s -w" || report.BuildSettings["CGO_ENABLED"] != "0" {
+		t.Errorf("build settings = %v", report.BuildSettings)
+	}
+	if _, ok := report.BuildSettings["vcs.revision"]; ok {
+		t.Error("vcs settings should only be reported under vcs")
+	}
+	if len(report.Dependencies) != 2 || report.Dependencies[1].Replace == nil || report.Dependencies[1].Replace.Path != "../net" {
+		t.Errorf("dependencies = %+v", report.Dependencies)
+	}
+
+	assertContains(t, report.String(), "Commit:        9543ee69bef932d3a8fe06505cfe080d9d8f61f2 (modified), 2025-10-22T14:20:33Z")
+}
+
+func TestNewBuildReport_NoVCS(t *testing.T) {
+	report := newBuildReport(&rtdebug.BuildInfo{Main: rtdebug.Module{Path: "github.com/grantcarthew/snag", Version: "(devel)"}})
+
+	if report.VCS != nil {
+		t.Errorf("vcs = %+v, want none", report.VCS)
+	}
+	data, err := json.Marshal(report)
+	assertNoError(t, err)
+	assertContains(t, string(data), `"dependencies":[]`)
+	assertContains(t, report.String(), "Commit:        (unknown)")
+}
+
+func TestCLI_AboutSBOM(t *testing.T) {
+	stdout, _, err := runSnag("about", "--sbom")
+	assertNoError(t, err)
+
+	var report BuildReport
+	assertNoError(t, json.Unmarshal([]byte(stdout), &report))
+	if report.Module != "github.com/grantcarthew/snag" {
+		t.Errorf("module = %q", report.Module)
+	}
+	if len(report.Dependencies) == 0 {
+		t.Error("expected the binary's dependencies to be listed")
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	ActionClick    = "click"
+	ActionFill     = "fill"
+	ActionScrollTo = "scroll-to"
+)
+
+// PageAction is an interaction performed on a page after it loads and
+// before its content is extracted, such as dismissing a cookie wall or
+// pressing "Show more".
+type PageAction struct {
+	Kind     string
+	Selector string
+	Value    string
+}
+
+func (a PageAction) String() string {
+	return a.Kind + " " + a.Selector
+}
+
+// pageActions are the --actions, --click, --fill, and --scroll-to actions,
+// in the order they are run.
+var pageActions []PageAction
+
+// actionFlag is a repeatable flag that adds actions of one kind to a shared
+// list, so --click, --fill, and --scroll-to run in command line order.
+type actionFlag struct {
+	kind    string
+	actions *[]PageAction
+}
+
+func (f *actionFlag) String() string { return "" }
+
+func (f *actionFlag) Type() string { return "stringArray" }
+
+func (f *actionFlag) Set(value string) error {
+	action, err := parseAction(f.kind, value)
+	if err != nil {
+		return err
+	}
+	*f.actions = append(*f.actions, action)
+	return nil
+}
+
+// parseAction reads the flag value of an action: a selector, or
+// SELECTOR=VALUE for fill. The value starts after the first "=" following
+// the selector's last attribute selector, so "input[name=q]=snag" fills
+// input[name=q].
+func parseAction(kind, value string) (PageAction, error) {
+	action := PageAction{Kind: kind, Selector: strings.TrimSpace(value)}
+
+	if kind == ActionFill {
+		start := max(strings.LastIndex(value, "]"), 0)
+		i := strings.Index(value[start:], "=")
+		if i < 0 {
+			return PageAction{}, fmt.Errorf("expected SELECTOR=VALUE, got %q", value)
+		}
+		action.Selector = strings.TrimSpace(value[:start+i])
+		action.Value = value[start+i+1:]
+	}
+
+	if action.Selector == "" {
+		return PageAction{}, fmt.Errorf("%s requires a selector", kind)
+	}
+	return action, nil
+}
+
+// actionStep is one entry of an --actions file:
+//
+//	actions:
+//	  - click: "#accept-cookies"
+//	  - fill: { selector: "#search", value: "${SNAG_QUERY}" }
+//	  - click: "button.show-more"
+//	  - scroll-to: "#comments"
+type actionStep struct {
+	Click    string    `yaml:"click"`
+	Fill     *FlowFill `yaml:"fill"`
+	ScrollTo string    `yaml:"scroll-to"`
+}
+
+// parseActions decodes and validates actions YAML. Environment variables in
+// fill values are expanded, as in --flow files.
+func parseActions(data []byte) ([]PageAction, error) {
+	var file struct {
+		Actions []actionStep `yaml:"actions"`
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse actions: %w", err)
+	}
+	if len(file.Actions) == 0 {
+		return nil, fmt.Errorf("no actions")
+	}
+
+	actions := make([]PageAction, 0, len(file.Actions))
+	for i, step := range file.Actions {
+		var found []PageAction
+		if step.Click != "" {
+			found = append(found, PageAction{Kind: ActionClick, Selector: step.Click})
+		}
+		if step.Fill != nil {
+			if step.Fill.Selector == "" {
+				return nil, fmt.Errorf("action %d: fill requires a selector", i+1)
+			}
+			found = append(found, PageAction{Kind: ActionFill, Selector: step.Fill.Selector, Value: expandEnvVars(step.Fill.Value)})
+		}
+		if step.ScrollTo != "" {
+			found = append(found, PageAction{Kind: ActionScrollTo, Selector: step.ScrollTo})
+		}
+
+		switch len(found) {
+		case 0:
+			return nil, fmt.Errorf("action %d: expected click, fill, or scroll-to", i+1)
+		case 1:
+			actions = append(actions, found[0])
+		default:
+			return nil, fmt.Errorf("action %d: has more than one of click, fill, and scroll-to", i+1)
+		}
+	}
+	return actions, nil
+}
+
+func loadActions(path string) ([]PageAction, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		logger.Error("Failed to read actions file: %s", path)
+		return nil, fmt.Errorf("failed to read actions file: %w", err)
+	}
+
+	actions, err := parseActions(data)
+	if err != nil {
+		logger.Error("Invalid actions file %s: %v", path, err)
+		logger.ErrorWithSuggestion(
+			"Each action needs exactly one of click, fill, or scroll-to",
+			"snag --actions actions.yaml https://example.com",
+		)
+		return nil, err
+	}
+	return actions, nil
+}
+
+// runPageActions performs actions on page in order. Each waits up to
+// timeout for its element to appear.
+func runPageActions(page *rod.Page, actions []PageAction, timeout time.Duration) error {
+	for i, action := range actions {
+		logger.Verbose("Action %d/%d: %s", i+1, len(actions), action)
+
+		var err error
+		switch action.Kind {
+		case ActionClick:
+			err = clickElement(page, action.Selector, timeout)
+		case ActionFill:
+			err = fillElement(page, action.Selector, action.Value, timeout)
+		case ActionScrollTo:
+			err = scrollToElement(page, action.Selector, timeout)
+		default:
+			err = fmt.Errorf("unknown action: %s", action.Kind)
+		}
+		if err != nil {
+			logger.Error("Action %d (%s) failed: %v", i+1, action, err)
+			logger.ErrorWithSuggestion(
+				"Check the selector matches a visible element once the page has loaded",
+				fmt.Sprintf("snag --wait-for '%s' --%s '%s' <url>", action.Selector, action.Kind, action.Selector),
+			)
+			return err
+		}
+	}
+	return nil
+}
+
+// clickElement clicks the element matching selector and waits for the page
+// to settle.
+func clickElement(page *rod.Page, selector string, timeout time.Duration) error {
+	el, err := page.Timeout(timeout).Element(selector)
+	if err != nil {
+		return fmt.Errorf("failed to find %s: %w", selector, err)
+	}
+	if err := el.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return fmt.Errorf("failed to click %s: %w", selector, err)
+	}
+	if err := page.WaitStable(stabilizeTimeout()); err != nil {
+		logger.Debug("Page did not stabilize after click: %v", err)
+	}
+	return nil
+}
+
+// fillElement replaces the text of the input matching selector with value.
+func fillElement(page *rod.Page, selector, value string, timeout time.Duration) error {
+	el, err := page.Timeout(timeout).Element(selector)
+	if err != nil {
+		return fmt.Errorf("failed to find %s: %w", selector, err)
+	}
+	if err := el.SelectAllText(); err != nil {
+		logger.Debug("Failed to select existing text in %s: %v", selector, err)
+	}
+	if err := el.Input(value); err != nil {
+		return fmt.Errorf("failed to fill %s: %w", selector, err)
+	}
+	return nil
+}
+
+// scrollToElement scrolls the element matching selector into view, so
+// content loaded on scroll is fetched, and waits for the page to settle.
+func scrollToElement(page *rod.Page, selector string, timeout time.Duration) error {
+	el, err := page.Timeout(timeout).Element(selector)
+	if err != nil {
+		return fmt.Errorf("failed to find %s: %w", selector, err)
+	}
+	if err := el.ScrollIntoView(); err != nil {
+		return fmt.Errorf("failed to scroll to %s: %w", selector, err)
+	}
+	if err := page.WaitStable(stabilizeTimeout()); err != nil {
+		logger.Debug("Page did not stabilize after scroll: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseAction(t *testing.T) {
+	tests := []struct {
+		kind     string
+		value    string
+		selector string
+		fill     string
+	}{
+		{ActionClick, " #accept ", "#accept", ""},
+		{ActionScrollTo, "#comments", "#comments", ""},
+		{ActionFill, "#q=snag", "#q", "snag"},
+		{ActionFill, "input[name=q]=a=b", "input[name=q]", "a=b"},
+		{ActionFill, "#q=", "#q", ""},
+	}
+
+	for _, tt := range tests {
+		action, err := parseAction(tt.kind, tt.value)
+		if err != nil {
+			t.Errorf("parseAction(%s, %q) failed: %v", tt.kind, tt.value, err)
+			continue
+		}
+		if action.Selector != tt.selector || action.Value != tt.fill {
+			t.Errorf("parseAction(%s, %q) = %q, %q, expected %q, %q", tt.kind, tt.value, action.Selector, action.Value, tt.selector, tt.fill)
+		}
+	}
+
+	for _, bad := range []struct{ kind, value string }{
+		{ActionClick, "  "},
+		{ActionFill, "#q"},
+		{ActionFill, "=value"},
+	} {
+		if _, err := parseAction(bad.kind, bad.value); err == nil {
+			t.Errorf("parseAction(%s, %q) expected error", bad.kind, bad.value)
+		}
+	}
+}
+
+func TestParseActions(t *testing.T) {
+	t.Setenv("SNAG_QUERY", "rod")
+	data := []byte(`
+actions:
+  - click: "#accept-cookies"
+  - fill: { selector: "#search", value: "${SNAG_QUERY}" }
+  - scroll-to: "#comments"
+`)
+
+	actions, err := parseActions(data)
+	if err != nil {
+		t.Fatalf("parseActions failed: %v", err)
+	}
+
+	want := []PageAction{
+		{Kind: ActionClick, Selector: "#accept-cookies"},
+		{Kind: ActionFill, Selector: "#search", Value: "rod"},
+		{Kind: ActionScrollTo, Selector: "#comments"},
+	}
+	if len(actions) != len(want) {
+		t.Fatalf("expected %d actions, got %d", len(want), len(actions))
+	}
+	for i := range want {
+		if actions[i] != want[i] {
+			t.Errorf("action %d = %+v, want %+v", i+1, actions[i], want[i])
+		}
+	}
+}
+
+func TestParseActions_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"empty", "actions: []", "no actions"},
+		{"no action", "actions:\n  - {}", "expected click, fill, or scroll-to"},
+		{"multiple actions", "actions:\n  - click: a\n    scroll-to: b", "more than one"},
+		{"fill without selector", "actions:\n  - fill: { value: x }", "requires a selector"},
+		{"unknown action", "actions:\n  - hover: a", "failed to parse"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseActions([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCLI_FillWithoutValue(t *testing.T) {
+	_, stderr, err := runSnag("--fill", "#q", "https://example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "expected SELECTOR=VALUE")
+}
+
+func TestBrowser_PageActions(t *testing.T) {
+	if !isBrowserAvailable() {
+		t.Skip("Browser not available, skipping browser integration test")
+	}
+
+	server := startTestServer(t)
+	url := server.URL + "/interactive.html"
+
+	stdout, _, err := runSnag("--click", "#show-more", "--fill", "#q=snag", "--click", "#search", url)
+	assertNoError(t, err)
+	assertContains(t, stdout, "Hidden replies loaded.")
+	assertContains(t, stdout, "Searched for snag")
+
+	_, stderr, err := runSnag("--click", "#missing", "--timeout", "3", url)
+	assertError(t, err)
+	assertContains(t, stderr, "failed")
+}
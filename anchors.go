@@ -0,0 +1,162 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+var headingElements = map[string]bool{
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+// headingSlug returns the anchor markdown renderers such as GitHub generate
+// for a heading: lowercased, punctuation dropped, and spaces turned into
+// hyphens.
+func headingSlug(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(collapseSpace(text))) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
+
+// rewriteAnchors points in-page links (href="#id") at the slugs of the
+// headings they target, so links such as a table of contents still work
+// once the page is converted to markdown. A heading is the target of its
+// own id, of ids and names inside it, and of the id of a section it opens.
+// Links to anything else are left as they are.
+func rewriteAnchors(htmlContent string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return "", err
+	}
+
+	targets := make(map[string]string)
+	used := make(map[string]int)
+	for n := range doc.Descendants() {
+		if n.Type != html.ElementNode || !headingElements[n.Data] {
+			continue
+		}
+
+		slug := headingSlug(textContent(n))
+		if slug == "" {
+			continue
+		}
+		if count := used[slug]; count > 0 {
+			used[slug]++
+			slug = fmt.Sprintf("%s-%d", slug, count)
+		} else {
+			used[slug] = 1
+		}
+
+		for _, id := range headingIDs(n) {
+			if _, ok := targets[id]; !ok {
+				targets[id] = slug
+			}
+		}
+	}
+
+	if len(targets) == 0 {
+		return htmlContent, nil
+	}
+
+	rewritten := 0
+	for n := range doc.Descendants() {
+		if n.Type != html.ElementNode || n.Data != "a" {
+			continue
+		}
+		for i, a := range n.Attr {
+			if a.Key != "href" || !strings.HasPrefix(a.Val, "#") {
+				continue
+			}
+			id := a.Val[1:]
+			if unescaped, err := url.PathUnescape(id); err == nil {
+				id = unescaped
+			}
+			if slug, ok := targets[id]; ok {
+				n.Attr[i].Val = "#" + slug
+				rewritten++
+			}
+		}
+	}
+	if rewritten == 0 {
+		return htmlContent, nil
+	}
+	logger.Debug("Rewrote %d in-page link%s to heading anchors", rewritten, plural(rewritten))
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, doc); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// headingIDs returns the ids a link may use to target heading h.
+func headingIDs(h *html.Node) []string {
+	var ids []string
+	if id := attr(h, "id"); id != "" {
+		ids = append(ids, id)
+	}
+	for c := range h.Descendants() {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		for _, key := range []string{"id", "name"} {
+			if v := attr(c, key); v != "" {
+				ids = append(ids, v)
+			}
+		}
+	}
+
+	// <section id="x"><h2>…</h2> and <a id="x"></a><h2>…</h2>
+	if p := h.Parent; p != nil && p.Type == html.ElementNode && firstElementChild(p) == h {
+		if id := attr(p, "id"); id != "" && p.Data != "body" {
+			ids = append(ids, id)
+		}
+	}
+	if prev := previousElementSibling(h); prev != nil && prev.Data == "a" && strings.TrimSpace(textContent(prev)) == "" {
+		for _, key := range []string{"id", "name"} {
+			if v := attr(prev, key); v != "" {
+				ids = append(ids, v)
+			}
+		}
+	}
+
+	return ids
+}
+
+// firstElementChild returns the first element child of n, or nil.
+func firstElementChild(n *html.Node) *html.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode {
+			return c
+		}
+	}
+	return nil
+}
+
+// previousElementSibling returns the element before n, or nil.
+func previousElementSibling(n *html.Node) *html.Node {
+	for c := n.PrevSibling; c != nil; c = c.PrevSibling {
+		if c.Type == html.ElementNode {
+			return c
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHeadingSlug(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"Getting Started", "getting-started"},
+		{"  What's  new in v2.0? ", "whats-new-in-v20"},
+		{"API_Reference - Overview", "api_reference---overview"},
+		{"Café & Crème", "café--crème"},
+		{"!!!", ""},
+	}
+
+	for _, tt := range tests {
+		if got := headingSlug(tt.text); got != tt.expected {
+			t.Errorf("headingSlug(%q) = %q, expected %q", tt.text, got, tt.expected)
+		}
+	}
+}
+
+func TestConvertToMarkdown_InPageLinks(t *testing.T) {
+	input := `<nav><ul>
+<li><a href="#intro">Intro</a></li>
+<li><a href="#setup">Setup</a></li>
+<li><a href="#old-anchor">Legacy</a></li>
+<li><a href="#usage">Usage</a></li>
+<li><a href="#usage-2">Usage again</a></li>
+<li><a href="#missing">Missing</a></li>
+<li><a href="https://example.com/#intro">External</a></li>
+</ul></nav>
+<h2 id="intro">Introduction &amp; Goals</h2>
+<section id="setup"><h2>Set up <code>snag</code></h2></section>
+<a name="old-anchor"></a><h2>Legacy Notes</h2>
+<h2 id="usage">Usage</h2>
+<h3 id="usage-2">Usage</h3>`
+
+	cc := NewContentConverter(FormatMarkdown)
+	markdown, err := cc.convertToMarkdown(input)
+	if err != nil {
+		t.Fatalf("convertToMarkdown failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"[Intro](#introduction--goals)",
+		"[Setup](#set-up-snag)",
+		"[Legacy](#legacy-notes)",
+		"[Usage](#usage)",
+		"[Usage again](#usage-1)",
+		"[Missing](#missing)",
+		"[External](https://example.com/#intro)",
+	} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("missing %s in:\n%s", want, markdown)
+		}
+	}
+}
+
+func TestRewriteAnchors_NoInPageLinks(t *testing.T) {
+	input := `<h1 id="top">Title</h1><p><a href="/about">About</a></p>`
+	got, err := rewriteAnchors(input)
+	if err != nil {
+		t.Fatalf("rewriteAnchors failed: %v", err)
+	}
+	if got != input {
+		t.Errorf("rewriteAnchors changed HTML without in-page links:\n%s", got)
+	}
+}
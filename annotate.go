@@ -0,0 +1,119 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// annotationBackground is the colour of the --annotate banner, used to fill
+// the banner row where the page is wider than the viewport.
+var annotationBackground = color.RGBA{R: 0x1f, G: 0x29, B: 0x37, A: 0xff}
+
+// annotationScript adds the --annotate banner to the top of the viewport and
+// returns its size in CSS pixels. The banner is drawn by the page so it uses
+// the system's fonts.
+const annotationScript = `(text) => {
+	const banner = document.createElement('div');
+	banner.id = 'snag-annotation';
+	banner.textContent = text;
+	banner.setAttribute('style', [
+		'all: initial', 'display: block', 'position: fixed', 'top: 0', 'left: 0',
+		'z-index: 2147483647', 'width: 100vw', 'box-sizing: border-box',
+		'padding: 6px 10px', 'background: #1f2937', 'color: #f9fafb',
+		'font: 12px/1.4 ui-monospace, SFMono-Regular, Menlo, Consolas, monospace',
+		'white-space: nowrap', 'overflow: hidden', 'text-overflow: ellipsis',
+	].join(';'));
+	document.documentElement.appendChild(banner);
+	const rect = banner.getBoundingClientRect();
+	return {width: Math.ceil(rect.width), height: Math.ceil(rect.height)};
+}`
+
+// annotationText is the banner line for a capture of url at t.
+func annotationText(url string, t time.Time) string {
+	return fmt.Sprintf("%s  |  captured %s", url, t.Format(time.RFC3339))
+}
+
+// annotateScreenshot adds a banner with the page URL and capture time above
+// screenshot, so the image documents where and when it was taken. The
+// banner is rendered by the page, captured on its own, and removed again.
+func annotateScreenshot(page *rod.Page, screenshot []byte, captured time.Time) ([]byte, error) {
+	info, err := page.Info()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page info: %w", err)
+	}
+
+	logger.Verbose("Annotating screenshot...")
+	res, err := page.Eval(annotationScript, annotationText(redactIfEnabled(info.URL), captured))
+	if err != nil {
+		return nil, fmt.Errorf("failed to add annotation banner: %w", err)
+	}
+	defer func() {
+		if _, err := page.Eval(`() => document.getElementById('snag-annotation')?.remove()`); err != nil {
+			logger.Debug("Failed to remove annotation banner: %v", err)
+		}
+	}()
+
+	width := res.Value.Get("width").Num()
+	height := res.Value.Get("height").Num()
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("annotation banner has no size")
+	}
+
+	shot, err := proto.PageCaptureScreenshot{
+		Format: proto.PageCaptureScreenshotFormatPng,
+		Clip: &proto.PageViewport{
+			X:      0,
+			Y:      0,
+			Width:  width,
+			Height: height,
+			Scale:  1,
+		},
+	}.Call(page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture annotation banner: %w", err)
+	}
+
+	return addBanner(shot.Data, screenshot)
+}
+
+// addBanner stacks the banner PNG above the screenshot PNG. Where the
+// screenshot is wider than the banner, the banner row is extended with
+// annotationBackground.
+func addBanner(bannerPNG, screenshotPNG []byte) ([]byte, error) {
+	banner, err := png.Decode(bytes.NewReader(bannerPNG))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode annotation banner: %w", err)
+	}
+	screenshot, err := png.Decode(bytes.NewReader(screenshotPNG))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode screenshot: %w", err)
+	}
+
+	canvas := stitchImagesVertically([]image.Image{banner, screenshot})
+
+	bannerBounds := banner.Bounds()
+	if canvas.Bounds().Dx() > bannerBounds.Dx() {
+		fill := image.Rect(bannerBounds.Dx(), 0, canvas.Bounds().Dx(), bannerBounds.Dy())
+		draw.Draw(canvas, fill, image.NewUniform(annotationBackground), image.Point{}, draw.Src)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, canvas); err != nil {
+		return nil, fmt.Errorf("failed to encode annotated screenshot: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+	"time"
+)
+
+func solidPNG(t *testing.T, width, height int, c color.Color) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestAddBanner(t *testing.T) {
+	white := color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	red := color.RGBA{R: 0xff, A: 0xff}
+
+	data, err := addBanner(solidPNG(t, 40, 10, red), solidPNG(t, 100, 50, white))
+	if err != nil {
+		t.Fatalf("addBanner failed: %v", err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	if got := img.Bounds().Size(); got != image.Pt(100, 60) {
+		t.Fatalf("size = %v, want (100,60)", got)
+	}
+
+	checks := []struct {
+		x, y int
+		want color.RGBA
+	}{
+		{0, 0, red},
+		{39, 9, red},
+		{40, 0, annotationBackground},
+		{99, 9, annotationBackground},
+		{0, 10, white},
+		{99, 59, white},
+	}
+	for _, c := range checks {
+		if got := color.RGBAModel.Convert(img.At(c.x, c.y)); got != c.want {
+			t.Errorf("pixel (%d,%d) = %v, want %v", c.x, c.y, got, c.want)
+		}
+	}
+}
+
+func TestAddBanner_InvalidPNG(t *testing.T) {
+	if _, err := addBanner([]byte("not a png"), solidPNG(t, 1, 1, color.White)); err == nil {
+		t.Error("expected error for invalid banner")
+	}
+}
+
+func TestAnnotationText(t *testing.T) {
+	captured := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	want := "https://example.com/  |  captured 2026-10-16T09:30:00Z"
+	if got := annotationText("https://example.com/", captured); got != want {
+		t.Errorf("annotationText() = %q, want %q", got, want)
+	}
+}
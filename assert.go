@@ -0,0 +1,70 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-rod/rod"
+)
+
+// hasAssertions reports whether any --assert-* flag was given.
+func hasAssertions() bool {
+	return len(assertText) > 0 || len(assertSelector) > 0
+}
+
+// checkAssertions verifies the loaded page against --assert-contains and
+// --assert-selector. Every assertion is checked so a single run reports all
+// failures, and the returned error wraps ErrAssertionFailed.
+func checkAssertions(page *rod.Page) error {
+	if !hasAssertions() {
+		return nil
+	}
+
+	var failures []string
+
+	if len(assertText) > 0 {
+		res, err := page.Eval(`() => document.body ? document.body.innerText : ""`)
+		if err != nil {
+			return fmt.Errorf("failed to read page text for assertions: %w", err)
+		}
+		for _, text := range findMissingText(res.Value.Str(), assertText) {
+			logger.Error("Assertion failed: page does not contain %q", text)
+			failures = append(failures, fmt.Sprintf("text %q not found", text))
+		}
+	}
+
+	for _, selector := range assertSelector {
+		has, _, err := page.Has(selector)
+		if err != nil {
+			return fmt.Errorf("failed to check selector %s: %w", selector, err)
+		}
+		if !has {
+			logger.Error("Assertion failed: no element matches %s", selector)
+			failures = append(failures, fmt.Sprintf("selector %s not found", selector))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%w: %s", ErrAssertionFailed, strings.Join(failures, "; "))
+	}
+
+	logger.Verbose("All assertions passed")
+	return nil
+}
+
+// findMissingText returns the entries of want that do not appear in text.
+func findMissingText(text string, want []string) []string {
+	var missing []string
+	for _, w := range want {
+		if !strings.Contains(text, w) {
+			missing = append(missing, w)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindMissingText(t *testing.T) {
+	text := "Widget Pro\nPrice: $49.99\nIn stock"
+
+	tests := []struct {
+		name string
+		want []string
+		miss []string
+	}{
+		{"all present", []string{"Widget Pro", "In stock"}, nil},
+		{"one missing", []string{"Price", "Sold out"}, []string{"Sold out"}},
+		{"case sensitive", []string{"in stock"}, []string{"in stock"}},
+		{"none requested", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findMissingText(text, tt.want)
+			if !reflect.DeepEqual(got, tt.miss) {
+				t.Errorf("findMissingText() = %v, want %v", got, tt.miss)
+			}
+		})
+	}
+}
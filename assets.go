@@ -0,0 +1,269 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"golang.org/x/net/html"
+)
+
+// assetHashLength is the number of hex digits of the SHA-256 content hash
+// used to name stored assets.
+const assetHashLength = 16
+
+// assetExtensions maps image content types to the extension of stored
+// assets, for URLs whose path does not end in a known extension.
+var assetExtensions = map[string]string{
+	"image/png":     ".png",
+	"image/jpeg":    ".jpg",
+	"image/gif":     ".gif",
+	"image/webp":    ".webp",
+	"image/avif":    ".avif",
+	"image/bmp":     ".bmp",
+	"image/x-icon":  ".ico",
+	"image/svg+xml": ".svg",
+}
+
+// fetchAssetScript downloads url from within the page, with its cookies,
+// for images the page has not loaded yet, and returns it base64 encoded.
+const fetchAssetScript = `async (url) => {
+	const res = await fetch(url);
+	if (!res.ok) throw new Error('HTTP ' + res.status);
+	const bytes = new Uint8Array(await res.arrayBuffer());
+	let binary = '';
+	for (let i = 0; i < bytes.length; i += 0x8000) {
+		binary += String.fromCharCode(...bytes.subarray(i, i + 0x8000));
+	}
+	return btoa(binary);
+}`
+
+// AssetStore saves page images under the hash of their content in one
+// directory, so an image used by many pages of a batch is stored once.
+type AssetStore struct {
+	dir string
+
+	mu     sync.Mutex
+	byURL  map[string]string
+	stored int
+	reused int
+}
+
+// assetStore is the --assets-dir store shared by every page of the run.
+var assetStore *AssetStore
+
+func NewAssetStore(dir string) *AssetStore {
+	return &AssetStore{dir: dir, byURL: make(map[string]string)}
+}
+
+// Localize downloads the images in htmlContent and points their src at the
+// stored copies, relative to the directory of outputFile (or the current
+// directory for stdout). Images that cannot be downloaded keep their
+// original URL.
+func (s *AssetStore) Localize(page *rod.Page, htmlContent, outputFile string) (string, error) {
+	info, err := page.Info()
+	if err != nil {
+		return "", fmt.Errorf("failed to get page info: %w", err)
+	}
+	base, err := url.Parse(info.URL)
+	if err != nil {
+		return "", fmt.Errorf("invalid page URL %s: %w", info.URL, err)
+	}
+
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return "", err
+	}
+	for n := range doc.Descendants() {
+		if n.Type == html.ElementNode && n.Data == "base" {
+			if href := attr(n, "href"); href != "" {
+				if docBase, err := base.Parse(href); err == nil {
+					base = docBase
+				}
+			}
+			break
+		}
+	}
+
+	refDir := "."
+	if outputFile != "" {
+		refDir = filepath.Dir(outputFile)
+	}
+
+	localized := 0
+	for n := range doc.Descendants() {
+		if n.Type != html.ElementNode || n.Data != "img" {
+			continue
+		}
+		src := strings.TrimSpace(attr(n, "src"))
+		if src == "" || strings.HasPrefix(src, "data:") {
+			continue
+		}
+		ref, err := base.Parse(src)
+		if err != nil || (ref.Scheme != "http" && ref.Scheme != "https" && ref.Scheme != "file") {
+			continue
+		}
+
+		stored, err := s.store(page, ref.String())
+		if err != nil {
+			logger.Debug("Keeping remote image %s: %v", ref, err)
+			continue
+		}
+		rel, err := filepath.Rel(refDir, stored)
+		if err != nil {
+			rel = stored
+		}
+
+		setImageSource(n, filepath.ToSlash(rel))
+		localized++
+	}
+
+	if localized == 0 {
+		return htmlContent, nil
+	}
+	logger.Verbose("Stored %d image%s in %s", localized, plural(localized), s.dir)
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, doc); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Stats returns how many assets were written and how many references
+// reused an asset already in the store.
+func (s *AssetStore) Stats() (stored, reused int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stored, s.reused
+}
+
+// store saves the image at rawURL and returns the path of the stored copy.
+func (s *AssetStore) store(page *rod.Page, rawURL string) (string, error) {
+	s.mu.Lock()
+	if p, ok := s.byURL[rawURL]; ok {
+		s.reused++
+		s.mu.Unlock()
+		return p, nil
+	}
+	s.mu.Unlock()
+
+	data, err := readAsset(page, rawURL)
+	if err != nil {
+		return "", err
+	}
+	if len(data) == 0 {
+		return "", fmt.Errorf("empty response")
+	}
+
+	sum := sha256.Sum256(data)
+	name := hex.EncodeToString(sum[:])[:assetHashLength] + assetExtension(rawURL, data)
+	p := filepath.Join(s.dir, name)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.byURL[rawURL] = p
+
+	if _, err := os.Stat(p); err == nil {
+		s.reused++
+		return p, nil
+	}
+	if err := os.WriteFile(p, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write asset: %w", err)
+	}
+	s.stored++
+	logger.Debug("Stored %s as %s (%s)", rawURL, name, formatByteSize(int64(len(data))))
+	return p, nil
+}
+
+// readAsset returns the bytes of rawURL from the browser's cache when the
+// page loaded it, or downloads it within the page otherwise.
+func readAsset(page *rod.Page, rawURL string) ([]byte, error) {
+	res, err := proto.PageGetResourceContent{FrameID: page.FrameID, URL: rawURL}.Call(page)
+	if err == nil {
+		if !res.Base64Encoded {
+			return []byte(res.Content), nil
+		}
+		return base64.StdEncoding.DecodeString(res.Content)
+	}
+
+	// --no-activate never runs script in the page
+	if noActivate {
+		return nil, fmt.Errorf("not loaded by the page: %w", err)
+	}
+	fetched, err := page.Eval(fetchAssetScript, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(fetched.Value.Str())
+}
+
+// assetExtension picks the file extension of a stored asset: the URL's own
+// image extension when it has one, otherwise one for the sniffed type.
+func assetExtension(rawURL string, data []byte) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		ext := strings.ToLower(path.Ext(u.Path))
+		if ext == ".jpeg" {
+			ext = ".jpg"
+		}
+		for _, known := range assetExtensions {
+			if ext == known {
+				return ext
+			}
+		}
+	}
+
+	contentType := http.DetectContentType(data)
+	if ext, ok := assetExtensions[strings.SplitN(contentType, ";", 2)[0]]; ok {
+		return ext
+	}
+	if bytes.Contains(data[:min(len(data), 512)], []byte("<svg")) {
+		return ".svg"
+	}
+	return ".bin"
+}
+
+// setImageSource points img at src. srcset, sizes, and the <source>
+// alternatives of a <picture> would override src, so they are removed.
+func setImageSource(img *html.Node, src string) {
+	kept := img.Attr[:0]
+	for _, a := range img.Attr {
+		switch a.Key {
+		case "src":
+			a.Val = src
+		case "srcset", "sizes", "loading":
+			continue
+		}
+		kept = append(kept, a)
+	}
+	img.Attr = kept
+
+	if p := img.Parent; p != nil && p.Type == html.ElementNode && p.Data == "picture" {
+		var sources []*html.Node
+		for c := p.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && c.Data == "source" {
+				sources = append(sources, c)
+			}
+		}
+		for _, c := range sources {
+			p.RemoveChild(c)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestAssetExtension(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	tests := []struct {
+		url      string
+		data     []byte
+		expected string
+	}{
+		{"https://example.com/logo.PNG", png, ".png"},
+		{"https://example.com/photo.jpeg?w=200", []byte("x"), ".jpg"},
+		{"https://example.com/image?id=1", png, ".png"},
+		{"https://example.com/icon", []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"/>`), ".svg"},
+		{"https://example.com/blob", []byte("plain"), ".bin"},
+	}
+
+	for _, tt := range tests {
+		if got := assetExtension(tt.url, tt.data); got != tt.expected {
+			t.Errorf("assetExtension(%q) = %q, expected %q", tt.url, got, tt.expected)
+		}
+	}
+}
+
+func TestSetImageSource(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`<picture><source srcset="a.webp" type="image/webp"><img src="a.png" srcset="a@2x.png 2x" sizes="50vw" loading="lazy" alt="A"></picture>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n := range doc.Descendants() {
+		if n.Type == html.ElementNode && n.Data == "img" {
+			setImageSource(n, "assets/0123456789abcdef.png")
+			break
+		}
+	}
+
+	var buf bytes.Buffer
+	html.Render(&buf, doc)
+	got := buf.String()
+
+	assertContains(t, got, `<picture><img src="assets/0123456789abcdef.png" alt="A"/></picture>`)
+}
+
+func TestBrowser_AssetsDir(t *testing.T) {
+	if !isBrowserAvailable() {
+		t.Skip("Browser not available, skipping browser integration test")
+	}
+
+	server := startTestServer(t)
+	dir := t.TempDir()
+	assets := filepath.Join(dir, "assets")
+	output := filepath.Join(dir, "page.md")
+
+	_, _, err := runSnag("--assets-dir", assets, "-o", output, server.URL+"/assets.html")
+	assertNoError(t, err)
+
+	entries, err := os.ReadDir(assets)
+	if err != nil {
+		t.Fatalf("failed to read assets directory: %v", err)
+	}
+	if len(entries) != 1 || !strings.HasSuffix(entries[0].Name(), ".svg") {
+		t.Fatalf("expected one stored .svg asset, got %v", entries)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := "assets/" + entries[0].Name()
+	if strings.Count(string(data), ref) != 2 {
+		t.Errorf("expected both images to link %s, got:\n%s", ref, data)
+	}
+}
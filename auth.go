@@ -0,0 +1,548 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// AuthFileMode keeps stored tokens readable only by their owner.
+const AuthFileMode = 0600
+
+// AuthRequestTimeout bounds each request to a provider's OAuth endpoints.
+const AuthRequestTimeout = 30 * time.Second
+
+// authExpiryMargin refreshes tokens this long before they expire, so a
+// token does not run out partway through a fetch.
+const authExpiryMargin = time.Minute
+
+var (
+	authClientID     string
+	authClientSecret string
+	authScope        string
+	authRemove       bool
+)
+
+// AuthProvider is an OAuth provider snag auth signs in to with the device
+// flow (RFC 8628). Its token is sent only to Hosts.
+type AuthProvider struct {
+	Name         string
+	DeviceURL    string
+	TokenURL     string
+	Scope        string
+	Hosts        []string
+	NeedsSecret  bool
+	ClientIDHelp string
+}
+
+var knownAuthProviders = map[string]AuthProvider{
+	"github": {
+		Name:         "github",
+		DeviceURL:    "https://github.com/login/device/code",
+		TokenURL:     "https://github.com/login/oauth/access_token",
+		Scope:        "repo",
+		Hosts:        []string{"github.com", "*.github.com", "*.githubusercontent.com"},
+		ClientIDHelp: "Create an OAuth app with device flow enabled at https://github.com/settings/developers",
+	},
+	"google": {
+		Name:         "google",
+		DeviceURL:    "https://oauth2.googleapis.com/device/code",
+		TokenURL:     "https://oauth2.googleapis.com/token",
+		Scope:        "https://www.googleapis.com/auth/drive.readonly",
+		Hosts:        []string{"docs.google.com", "*.googleapis.com"},
+		NeedsSecret:  true,
+		ClientIDHelp: "Create an OAuth client of type \"TVs and Limited Input devices\" at https://console.cloud.google.com/apis/credentials",
+	},
+}
+
+const authHelpTemplate = `USAGE:
+  snag auth <provider> [--client-id ID] [--client-secret SECRET] [--scope SCOPES]
+  snag auth <provider> --remove
+
+DESCRIPTION:
+  Sign in to a documentation platform without a browser on this machine,
+  using the OAuth device flow. snag prints a code, you enter it at the
+  provider's page on any device, and the token is saved for --auth:
+
+    snag --auth github https://github.com/org/private-repo/wiki
+
+  The token is sent only to the provider's hosts, as with --host-headers:
+    github   github.com, *.github.com, *.githubusercontent.com
+    google   docs.google.com, *.googleapis.com
+
+  Providers need the client ID of an OAuth app you register, given with
+  --client-id or SNAG_GITHUB_CLIENT_ID / SNAG_GOOGLE_CLIENT_ID. Google also
+  needs the client secret (--client-secret or SNAG_GOOGLE_CLIENT_SECRET).
+  Tokens are saved, readable only by you, in snag/auth.json under the user
+  config directory, and expired tokens are refreshed when used.
+
+EXAMPLES:
+  snag auth github --client-id Iv1.0123456789abcdef
+  snag auth google --scope https://www.googleapis.com/auth/documents.readonly
+  snag auth github --remove
+
+OPTIONS:
+      --client-id string       OAuth client ID of your app (default: SNAG_<PROVIDER>_CLIENT_ID)
+      --client-secret string   OAuth client secret, for google (default: SNAG_GOOGLE_CLIENT_SECRET)
+      --scope string           Scopes to request (default: repo for github, drive.readonly for google)
+      --remove                 Delete the saved token of the provider
+  -h, --help                   help for auth
+`
+
+var authCmd = &cobra.Command{
+	Use:          "auth <provider>",
+	Short:        "Sign in to GitHub or Google with the OAuth device flow for --auth",
+	Args:         cobra.ExactArgs(1),
+	RunE:         runAuth,
+	SilenceUsage: true,
+}
+
+func init() {
+	authCmd.Flags().StringVar(&authClientID, "client-id", "", "OAuth client ID of your app (default: SNAG_<PROVIDER>_CLIENT_ID)")
+	authCmd.Flags().StringVar(&authClientSecret, "client-secret", "", "OAuth client secret, for google (default: SNAG_GOOGLE_CLIENT_SECRET)")
+	authCmd.Flags().StringVar(&authScope, "scope", "", "Scopes to request (default: repo for github, drive.readonly for google)")
+	authCmd.Flags().BoolVar(&authRemove, "remove", false, "Delete the saved token of the provider")
+
+	authCmd.SetHelpTemplate(authHelpTemplate)
+
+	rootCmd.AddCommand(authCmd)
+}
+
+// AuthToken is a saved OAuth token. The client credentials are kept with
+// it so it can be refreshed.
+type AuthToken struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type,omitempty"`
+	Scope        string    `json:"scope,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expires      time.Time `json:"expires,omitzero"`
+	ClientID     string    `json:"client_id"`
+	ClientSecret string    `json:"client_secret,omitempty"`
+}
+
+// expired reports whether the token is past, or about to reach, its expiry.
+func (t AuthToken) expired(now time.Time) bool {
+	return !t.Expires.IsZero() && now.Add(authExpiryMargin).After(t.Expires)
+}
+
+// tokenResponse is the JSON a token endpoint answers with, a token or an
+// OAuth error such as authorization_pending.
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	Scope        string `json:"scope"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	Error        string `json:"error"`
+	ErrorDesc    string `json:"error_description"`
+}
+
+// deviceCode is a device authorization response. Google names the page
+// verification_url rather than verification_uri.
+type deviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	VerificationURL string `json:"verification_url"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+	Error           string `json:"error"`
+	ErrorDesc       string `json:"error_description"`
+}
+
+// DeviceAuth runs the device flow against one provider.
+type DeviceAuth struct {
+	Provider     AuthProvider
+	ClientID     string
+	ClientSecret string
+	Scope        string
+
+	client *http.Client
+	now    func() time.Time
+	sleep  func(time.Duration)
+}
+
+func NewDeviceAuth(provider AuthProvider, clientID, clientSecret, scope string) *DeviceAuth {
+	if scope == "" {
+		scope = provider.Scope
+	}
+	return &DeviceAuth{
+		Provider:     provider,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Scope:        scope,
+		client:       &http.Client{Timeout: AuthRequestTimeout},
+		now:          time.Now,
+		sleep:        time.Sleep,
+	}
+}
+
+// postForm posts form to endpoint and decodes the JSON reply into v. OAuth
+// errors come back as JSON with a 4xx status, so the body is decoded
+// whatever the status.
+func (da *DeviceAuth) postForm(endpoint string, form url.Values, v any) error {
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// GitHub replies form-encoded unless asked for JSON
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := da.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDeviceAuthFailed, err)
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("%w: unexpected reply from %s (HTTP %d)", ErrDeviceAuthFailed, endpoint, resp.StatusCode)
+	}
+	return nil
+}
+
+// RequestCode starts the flow, returning the code for the user to enter.
+func (da *DeviceAuth) RequestCode() (*deviceCode, error) {
+	form := url.Values{"client_id": {da.ClientID}, "scope": {da.Scope}}
+
+	var code deviceCode
+	if err := da.postForm(da.Provider.DeviceURL, form, &code); err != nil {
+		return nil, err
+	}
+	if code.Error != "" {
+		return nil, fmt.Errorf("%w: %s", ErrDeviceAuthFailed, oauthErrorText(code.Error, code.ErrorDesc))
+	}
+	if code.DeviceCode == "" || code.UserCode == "" {
+		return nil, fmt.Errorf("%w: no device code in reply", ErrDeviceAuthFailed)
+	}
+	if code.VerificationURI == "" {
+		code.VerificationURI = code.VerificationURL
+	}
+	return &code, nil
+}
+
+// Poll waits for the user to enter code, polling at the interval the
+// provider asks for until the code expires.
+func (da *DeviceAuth) Poll(code *deviceCode) (AuthToken, error) {
+	interval := time.Duration(max(code.Interval, 1)) * time.Second
+	deadline := da.now().Add(time.Duration(code.ExpiresIn) * time.Second)
+	if code.ExpiresIn <= 0 {
+		deadline = da.now().Add(15 * time.Minute)
+	}
+
+	form := url.Values{
+		"client_id":   {da.ClientID},
+		"device_code": {code.DeviceCode},
+		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+	}
+	if da.ClientSecret != "" {
+		form.Set("client_secret", da.ClientSecret)
+	}
+
+	for da.now().Before(deadline) {
+		da.sleep(interval)
+
+		var reply tokenResponse
+		if err := da.postForm(da.Provider.TokenURL, form, &reply); err != nil {
+			return AuthToken{}, err
+		}
+
+		switch reply.Error {
+		case "":
+			if reply.AccessToken == "" {
+				return AuthToken{}, fmt.Errorf("%w: no access token in reply", ErrDeviceAuthFailed)
+			}
+			return da.newToken(reply), nil
+		case "authorization_pending":
+			continue
+		case "slow_down":
+			interval += 5 * time.Second
+			continue
+		default:
+			return AuthToken{}, fmt.Errorf("%w: %s", ErrDeviceAuthFailed, oauthErrorText(reply.Error, reply.ErrorDesc))
+		}
+	}
+	return AuthToken{}, fmt.Errorf("%w: the code expired before it was entered", ErrDeviceAuthFailed)
+}
+
+// Refresh exchanges the refresh token of token for a new access token.
+func (da *DeviceAuth) Refresh(token AuthToken) (AuthToken, error) {
+	form := url.Values{
+		"client_id":     {da.ClientID},
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {token.RefreshToken},
+	}
+	if da.ClientSecret != "" {
+		form.Set("client_secret", da.ClientSecret)
+	}
+
+	var reply tokenResponse
+	if err := da.postForm(da.Provider.TokenURL, form, &reply); err != nil {
+		return AuthToken{}, err
+	}
+	if reply.Error != "" || reply.AccessToken == "" {
+		return AuthToken{}, fmt.Errorf("%w: refresh failed: %s", ErrDeviceAuthFailed, oauthErrorText(reply.Error, reply.ErrorDesc))
+	}
+
+	refreshed := da.newToken(reply)
+	// Google keeps the refresh token the same and leaves it out
+	if refreshed.RefreshToken == "" {
+		refreshed.RefreshToken = token.RefreshToken
+	}
+	return refreshed, nil
+}
+
+func (da *DeviceAuth) newToken(reply tokenResponse) AuthToken {
+	token := AuthToken{
+		AccessToken:  reply.AccessToken,
+		TokenType:    reply.TokenType,
+		Scope:        reply.Scope,
+		RefreshToken: reply.RefreshToken,
+		ClientID:     da.ClientID,
+		ClientSecret: da.ClientSecret,
+	}
+	if reply.ExpiresIn > 0 {
+		token.Expires = da.now().Add(time.Duration(reply.ExpiresIn) * time.Second).UTC().Truncate(time.Second)
+	}
+	return token
+}
+
+func oauthErrorText(code, description string) string {
+	switch {
+	case code == "":
+		return "no access token in reply"
+	case description != "":
+		return code + " (" + description + ")"
+	}
+	return code
+}
+
+// authFilePath is where snag auth saves tokens: snag/auth.json under the
+// user config directory.
+func authFilePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot find the user config directory: %w", err)
+	}
+	return filepath.Join(dir, "snag", "auth.json"), nil
+}
+
+// loadAuthTokens reads saved tokens by provider. A missing file has none.
+func loadAuthTokens(path string) (map[string]AuthToken, error) {
+	tokens := make(map[string]AuthToken)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return tokens, nil
+}
+
+// saveAuthTokens writes tokens to path, readable only by the owner. The
+// file is replaced in one step so a failed write cannot lose other tokens.
+func saveAuthTokens(path string, tokens map[string]AuthToken) error {
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode tokens: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), AuthFileMode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+func lookupAuthProvider(name string) (AuthProvider, error) {
+	provider, ok := knownAuthProviders[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		names := make([]string, 0, len(knownAuthProviders))
+		for n := range knownAuthProviders {
+			names = append(names, n)
+		}
+		slices.Sort(names)
+		logger.Error("Unknown auth provider: %s", name)
+		logger.ErrorWithSuggestion(
+			"Providers are "+strings.Join(names, ", "),
+			"snag auth github",
+		)
+		return AuthProvider{}, fmt.Errorf("unknown auth provider: %s", name)
+	}
+	return provider, nil
+}
+
+func runAuth(cmd *cobra.Command, args []string) error {
+	logger = NewLogger(LevelNormal)
+
+	provider, err := lookupAuthProvider(args[0])
+	if err != nil {
+		return err
+	}
+	path, err := authFilePath()
+	if err != nil {
+		logger.Error("%v", err)
+		return err
+	}
+	tokens, err := loadAuthTokens(path)
+	if err != nil {
+		logger.Error("%v", err)
+		return err
+	}
+
+	if authRemove {
+		if _, ok := tokens[provider.Name]; !ok {
+			logger.Info("No saved %s token", provider.Name)
+			return nil
+		}
+		delete(tokens, provider.Name)
+		if err := saveAuthTokens(path, tokens); err != nil {
+			logger.Error("%v", err)
+			return err
+		}
+		logger.Success("Removed the %s token", provider.Name)
+		return nil
+	}
+
+	env := "SNAG_" + strings.ToUpper(provider.Name)
+	clientID := cmp.Or(strings.TrimSpace(authClientID), os.Getenv(env+"_CLIENT_ID"))
+	clientSecret := cmp.Or(strings.TrimSpace(authClientSecret), os.Getenv(env+"_CLIENT_SECRET"))
+	if clientID == "" {
+		logger.Error("snag auth %s needs the client ID of your OAuth app", provider.Name)
+		logger.ErrorWithSuggestion(
+			provider.ClientIDHelp,
+			fmt.Sprintf("snag auth %s --client-id <id>", provider.Name),
+		)
+		return fmt.Errorf("no client ID for %s", provider.Name)
+	}
+	if provider.NeedsSecret && clientSecret == "" {
+		logger.Error("snag auth %s needs the client secret of your OAuth app", provider.Name)
+		logger.ErrorWithSuggestion(
+			"Give the secret shown with the client ID",
+			fmt.Sprintf("snag auth %s --client-id <id> --client-secret <secret>", provider.Name),
+		)
+		return fmt.Errorf("no client secret for %s", provider.Name)
+	}
+
+	da := NewDeviceAuth(provider, clientID, clientSecret, strings.TrimSpace(authScope))
+	code, err := da.RequestCode()
+	if err != nil {
+		logger.Error("Failed to start %s sign-in: %v", provider.Name, err)
+		return err
+	}
+
+	logger.Info("Open %s and enter the code: %s", code.VerificationURI, code.UserCode)
+	logger.Info("Waiting for you to approve snag...")
+
+	token, err := da.Poll(code)
+	if err != nil {
+		logger.Error("%s sign-in failed: %v", provider.Name, err)
+		return err
+	}
+
+	tokens[provider.Name] = token
+	if err := saveAuthTokens(path, tokens); err != nil {
+		logger.Error("%v", err)
+		return err
+	}
+	logger.Success("Signed in to %s, token saved to %s", provider.Name, path)
+	logger.Info("Use it with: snag --auth %s <url>", provider.Name)
+	return nil
+}
+
+// authHeaderRules returns rules that send the saved token of each --auth
+// provider to that provider's hosts only. Expired tokens are refreshed and
+// saved first, or with --read-only only used for this run.
+func authHeaderRules(names []string) ([]HostHeaderRule, error) {
+	path, err := authFilePath()
+	if err != nil {
+		logger.Error("%v", err)
+		return nil, err
+	}
+	tokens, err := loadAuthTokens(path)
+	if err != nil {
+		logger.Error("%v", err)
+		return nil, err
+	}
+
+	var rules []HostHeaderRule
+	refreshed := false
+	for _, name := range names {
+		provider, err := lookupAuthProvider(name)
+		if err != nil {
+			return nil, err
+		}
+
+		token, ok := tokens[provider.Name]
+		if !ok {
+			logger.Error("No saved %s token for --auth %s", provider.Name, provider.Name)
+			logger.ErrorWithSuggestion(
+				"Sign in first with the device flow",
+				"snag auth "+provider.Name,
+			)
+			return nil, fmt.Errorf("%w: no %s token", ErrDeviceAuthFailed, provider.Name)
+		}
+
+		if token.expired(time.Now()) {
+			if token.RefreshToken == "" {
+				logger.Error("The saved %s token has expired", provider.Name)
+				logger.ErrorWithSuggestion("Sign in again", "snag auth "+provider.Name)
+				return nil, fmt.Errorf("%w: %s token expired", ErrDeviceAuthFailed, provider.Name)
+			}
+			logger.Verbose("Refreshing the %s token...", provider.Name)
+			token, err = NewDeviceAuth(provider, token.ClientID, token.ClientSecret, token.Scope).Refresh(token)
+			if err != nil {
+				logger.Error("Failed to refresh the %s token: %v", provider.Name, err)
+				logger.ErrorWithSuggestion("Sign in again", "snag auth "+provider.Name)
+				return nil, err
+			}
+			tokens[provider.Name] = token
+			refreshed = true
+		}
+
+		rule := HostHeaderRule{headers: map[string]string{"Authorization": "Bearer " + token.AccessToken}}
+		for _, pattern := range provider.Hosts {
+			hp, err := parseHostPattern(pattern)
+			if err != nil {
+				return nil, err
+			}
+			rule.hosts = append(rule.hosts, hp)
+		}
+		rules = append(rules, rule)
+	}
+
+	if refreshed && readOnly {
+		logger.Verbose("Not saving the refreshed token with --read-only")
+	} else if refreshed {
+		if err := saveAuthTokens(path, tokens); err != nil {
+			logger.Warning("Failed to save refreshed token: %v", err)
+		}
+	}
+	return rules, nil
+}
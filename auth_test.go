@@ -0,0 +1,216 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// startDeviceServer serves a device flow whose token endpoint answers
+// with replies in order, repeating the last.
+func startDeviceServer(t *testing.T, replies ...string) (AuthProvider, *[]string) {
+	t.Helper()
+	var grants []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/device", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("client_id") != "client-1" || r.Header.Get("Accept") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error": "invalid_client"}`)
+			return
+		}
+		fmt.Fprint(w, `{"device_code": "dev-1", "user_code": "ABCD-1234", "verification_url": "https://example.com/device", "expires_in": 900, "interval": 5}`)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		grants = append(grants, r.FormValue("grant_type"))
+		reply := replies[min(len(grants), len(replies))-1]
+		if reply == `{"error": "authorization_pending"}` {
+			w.WriteHeader(http.StatusPreconditionRequired)
+		}
+		fmt.Fprint(w, reply)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return AuthProvider{Name: "test", DeviceURL: server.URL + "/device", TokenURL: server.URL + "/token", Scope: "read"}, &grants
+}
+
+// newTestDeviceAuth returns a DeviceAuth on a fake clock that advances
+// when it sleeps, recording the intervals.
+func newTestDeviceAuth(provider AuthProvider) (*DeviceAuth, *[]time.Duration) {
+	da := NewDeviceAuth(provider, "client-1", "", "")
+	clock := time.Date(2025, 10, 22, 14, 0, 0, 0, time.UTC)
+	var sleeps []time.Duration
+	da.now = func() time.Time { return clock }
+	da.sleep = func(d time.Duration) {
+		sleeps = append(sleeps, d)
+		clock = clock.Add(d)
+	}
+	return da, &sleeps
+}
+
+func TestDeviceAuth_Flow(t *testing.T) {
+	provider, grants := startDeviceServer(t,
+		`{"error": "authorization_pending"}`,
+		`{"error": "slow_down"}`,
+		`{"access_token": "tok-1", "token_type": "bearer", "refresh_token": "ref-1", "expires_in": 3600}`,
+	)
+	da, sleeps := newTestDeviceAuth(provider)
+
+	code, err := da.RequestCode()
+	if err != nil {
+		t.Fatalf("RequestCode() error: %v", err)
+	}
+	if code.UserCode != "ABCD-1234" || code.VerificationURI != "https://example.com/device" {
+		t.Errorf("unexpected code: %+v", code)
+	}
+
+	token, err := da.Poll(code)
+	if err != nil {
+		t.Fatalf("Poll() error: %v", err)
+	}
+	if token.AccessToken != "tok-1" || token.RefreshToken != "ref-1" || token.ClientID != "client-1" {
+		t.Errorf("unexpected token: %+v", token)
+	}
+	if want := time.Date(2025, 10, 22, 15, 0, 20, 0, time.UTC); !token.Expires.Equal(want) {
+		t.Errorf("Expires = %v, want %v", token.Expires, want)
+	}
+
+	expected := []time.Duration{5 * time.Second, 5 * time.Second, 10 * time.Second}
+	if fmt.Sprint(*sleeps) != fmt.Sprint(expected) {
+		t.Errorf("poll intervals = %v, want %v", *sleeps, expected)
+	}
+	if len(*grants) != 3 || (*grants)[0] != "urn:ietf:params:oauth:grant-type:device_code" {
+		t.Errorf("grants = %v", *grants)
+	}
+}
+
+func TestDeviceAuth_Denied(t *testing.T) {
+	provider, _ := startDeviceServer(t, `{"error": "access_denied", "error_description": "The user denied the request"}`)
+	da, _ := newTestDeviceAuth(provider)
+
+	code, err := da.RequestCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = da.Poll(code)
+	if !errors.Is(err, ErrDeviceAuthFailed) {
+		t.Fatalf("expected ErrDeviceAuthFailed, got %v", err)
+	}
+	assertContains(t, err.Error(), "access_denied (The user denied the request)")
+}
+
+func TestDeviceAuth_Expired(t *testing.T) {
+	provider, grants := startDeviceServer(t, `{"error": "authorization_pending"}`)
+	da, _ := newTestDeviceAuth(provider)
+
+	code, err := da.RequestCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := da.Poll(code); err == nil {
+		t.Fatal("expected an error once the code expired")
+	}
+	if len(*grants) != 180 {
+		t.Errorf("expected 180 polls in 900s at 5s, got %d", len(*grants))
+	}
+}
+
+func TestDeviceAuth_Refresh(t *testing.T) {
+	provider, grants := startDeviceServer(t, `{"access_token": "tok-2", "expires_in": 3600}`)
+	da, _ := newTestDeviceAuth(provider)
+
+	token, err := da.Refresh(AuthToken{AccessToken: "tok-1", RefreshToken: "ref-1"})
+	if err != nil {
+		t.Fatalf("Refresh() error: %v", err)
+	}
+	if token.AccessToken != "tok-2" || token.RefreshToken != "ref-1" {
+		t.Errorf("unexpected token: %+v", token)
+	}
+	if (*grants)[0] != "refresh_token" {
+		t.Errorf("grant_type = %s", (*grants)[0])
+	}
+}
+
+func TestAuthTokens_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snag", "auth.json")
+
+	tokens, err := loadAuthTokens(path)
+	if err != nil || len(tokens) != 0 {
+		t.Fatalf("loadAuthTokens() of a missing file = %v, %v", tokens, err)
+	}
+
+	tokens["github"] = AuthToken{AccessToken: "tok-1", ClientID: "client-1"}
+	if err := saveAuthTokens(path, tokens); err != nil {
+		t.Fatalf("saveAuthTokens() error: %v", err)
+	}
+	if runtime.GOOS != "windows" {
+		if info, err := os.Stat(path); err != nil || info.Mode().Perm() != AuthFileMode {
+			t.Errorf("expected mode %o, got %v (%v)", AuthFileMode, info.Mode().Perm(), err)
+		}
+	}
+
+	loaded, err := loadAuthTokens(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded["github"].AccessToken != "tok-1" || !loaded["github"].Expires.IsZero() {
+		t.Errorf("loaded = %+v", loaded)
+	}
+}
+
+func TestAuthHeaderRules(t *testing.T) {
+	logger = NewLogger(LevelQuiet)
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("AppData", dir)
+
+	path, err := authFilePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := authHeaderRules([]string{"github"}); !errors.Is(err, ErrDeviceAuthFailed) {
+		t.Errorf("expected an error without a saved token, got %v", err)
+	}
+
+	tokens := map[string]AuthToken{"github": {AccessToken: "gh-token", ClientID: "client-1"}}
+	if err := saveAuthTokens(path, tokens); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := authHeaderRules([]string{"GitHub"})
+	if err != nil {
+		t.Fatalf("authHeaderRules() error: %v", err)
+	}
+	if got := headersForURL(rules, "https://raw.githubusercontent.com/org/repo/main/README.md")["Authorization"]; got != "Bearer gh-token" {
+		t.Errorf("Authorization = %q", got)
+	}
+	if got := headersForURL(rules, "https://cdn.example.com/app.js"); len(got) != 0 {
+		t.Errorf("expected no headers for other hosts, got %v", got)
+	}
+
+	tokens["github"] = AuthToken{AccessToken: "old", ClientID: "client-1", Expires: time.Now().Add(-time.Hour)}
+	if err := saveAuthTokens(path, tokens); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := authHeaderRules([]string{"github"}); !errors.Is(err, ErrDeviceAuthFailed) {
+		t.Errorf("expected an error for an expired token without a refresh token, got %v", err)
+	}
+
+	if _, err := authHeaderRules([]string{"gitlab"}); err == nil {
+		t.Error("expected an error for an unknown provider")
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Resource categories for --block.
+const (
+	BlockImages    = "images"
+	BlockFonts     = "fonts"
+	BlockMedia     = "media"
+	BlockAnalytics = "analytics"
+)
+
+var blockCategories = []string{BlockImages, BlockFonts, BlockMedia, BlockAnalytics}
+
+// blockResourceTypes maps --block categories to the resource types Chrome
+// reports for them.
+var blockResourceTypes = map[string]proto.NetworkResourceType{
+	BlockImages: proto.NetworkResourceTypeImage,
+	BlockFonts:  proto.NetworkResourceTypeFont,
+	BlockMedia:  proto.NetworkResourceTypeMedia,
+}
+
+// analyticsDomains are the tracking and analytics services --block
+// analytics refuses, along with their subdomains.
+var analyticsDomains = []string{
+	"google-analytics.com",
+	"googletagmanager.com",
+	"doubleclick.net",
+	"googlesyndication.com",
+	"connect.facebook.net",
+	"hotjar.com",
+	"segment.com",
+	"segment.io",
+	"mixpanel.com",
+	"amplitude.com",
+	"heap.io",
+	"heapanalytics.com",
+	"fullstory.com",
+	"clarity.ms",
+	"newrelic.com",
+	"nr-data.net",
+	"quantserve.com",
+	"scorecardresearch.com",
+	"plausible.io",
+	"static.cloudflareinsights.com",
+}
+
+// ResourceBlocker decides which of a page's requests to abort, built from
+// --block and --block-url-pattern. The page itself is never blocked by
+// category, only its subresources.
+type ResourceBlocker struct {
+	types     []proto.NetworkResourceType
+	analytics bool
+	patterns  []*regexp.Regexp
+}
+
+// NewResourceBlocker parses --block categories, each entry a comma
+// separated list, and --block-url-pattern globs.
+func NewResourceBlocker(categories, urlPatterns []string) (*ResourceBlocker, error) {
+	blocker := &ResourceBlocker{}
+	for _, entry := range categories {
+		for _, category := range strings.Split(entry, ",") {
+			category = strings.ToLower(strings.TrimSpace(category))
+			switch {
+			case category == "":
+				continue
+			case category == BlockAnalytics:
+				blocker.analytics = true
+			case blockResourceTypes[category] != "":
+				if !slices.Contains(blocker.types, blockResourceTypes[category]) {
+					blocker.types = append(blocker.types, blockResourceTypes[category])
+				}
+			default:
+				return nil, fmt.Errorf("unknown resource type: %s (use %s)", category, strings.Join(blockCategories, ", "))
+			}
+		}
+	}
+
+	for _, pattern := range urlPatterns {
+		re, err := urlGlob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		blocker.patterns = append(blocker.patterns, re)
+	}
+	return blocker, nil
+}
+
+// urlGlob compiles a --block-url-pattern, where * matches any run of
+// characters and the pattern must cover the whole URL, as in
+// "*.mp4" or "https://cdn.example.com/ads/*".
+func urlGlob(pattern string) (*regexp.Regexp, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" || strings.Trim(pattern, "*") == "" {
+		return nil, fmt.Errorf("URL pattern must contain more than wildcards: %q", pattern)
+	}
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.Compile("^" + strings.Join(parts, ".*") + "$")
+}
+
+// Enabled reports whether the blocker blocks anything.
+func (b *ResourceBlocker) Enabled() bool {
+	return len(b.types) > 0 || b.analytics || len(b.patterns) > 0
+}
+
+// Blocks reports whether a request for rawURL of resourceType should be
+// aborted.
+func (b *ResourceBlocker) Blocks(rawURL string, resourceType proto.NetworkResourceType) bool {
+	if slices.Contains(b.types, resourceType) {
+		return true
+	}
+	if b.analytics && isAnalyticsURL(rawURL) {
+		return true
+	}
+	for _, re := range b.patterns {
+		if re.MatchString(rawURL) {
+			return true
+		}
+	}
+	return false
+}
+
+func isAnalyticsURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	for _, domain := range analyticsDomains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// blocksImages reports whether --block includes images, which leaves them
+// out of screenshots, PDFs, and --assets-dir.
+func (b *ResourceBlocker) blocksImages() bool {
+	return slices.Contains(b.types, proto.NetworkResourceTypeImage)
+}
+
+// activeResourceBlocker builds the blocker from --block and
+// --block-url-pattern.
+func activeResourceBlocker() (*ResourceBlocker, error) {
+	return NewResourceBlocker(blockResources, blockURLs)
+}
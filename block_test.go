@@ -0,0 +1,73 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+func TestResourceBlocker(t *testing.T) {
+	blocker, err := NewResourceBlocker(
+		[]string{"images, Fonts", "analytics"},
+		[]string{"*.mp4", "https://cdn.example.com/ads/*"},
+	)
+	if err != nil {
+		t.Fatalf("NewResourceBlocker() error: %v", err)
+	}
+	if !blocker.Enabled() {
+		t.Fatal("expected blocker to be enabled")
+	}
+
+	tests := []struct {
+		url          string
+		resourceType proto.NetworkResourceType
+		expected     bool
+	}{
+		{"https://example.com/logo.png", proto.NetworkResourceTypeImage, true},
+		{"https://fonts.example.com/inter.woff2", proto.NetworkResourceTypeFont, true},
+		{"https://example.com/intro.webm", proto.NetworkResourceTypeMedia, false},
+		{"https://example.com/intro.mp4", proto.NetworkResourceTypeMedia, true},
+		{"https://example.com/intro.mp4?t=1", proto.NetworkResourceTypeMedia, false},
+		{"https://www.google-analytics.com/g/collect", proto.NetworkResourceTypeXHR, true},
+		{"https://googletagmanager.com/gtm.js", proto.NetworkResourceTypeScript, true},
+		{"https://notgoogletagmanager.com/gtm.js", proto.NetworkResourceTypeScript, false},
+		{"https://cdn.example.com/ads/banner.js", proto.NetworkResourceTypeScript, true},
+		{"https://cdn.example.com/app.js", proto.NetworkResourceTypeScript, false},
+		{"https://example.com/", proto.NetworkResourceTypeDocument, false},
+	}
+	for _, tt := range tests {
+		if got := blocker.Blocks(tt.url, tt.resourceType); got != tt.expected {
+			t.Errorf("Blocks(%q, %s) = %v, want %v", tt.url, tt.resourceType, got, tt.expected)
+		}
+	}
+}
+
+func TestNewResourceBlocker_Invalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		categories []string
+		patterns   []string
+	}{
+		{"unknown category", []string{"images,scripts"}, nil},
+		{"empty pattern", nil, []string{" "}},
+		{"wildcards only", nil, []string{"**"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewResourceBlocker(tt.categories, tt.patterns); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+
+	blocker, err := NewResourceBlocker(nil, nil)
+	if err != nil || blocker.Enabled() {
+		t.Errorf("empty blocker: enabled=%v err=%v", blocker.Enabled(), err)
+	}
+}
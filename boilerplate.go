@@ -0,0 +1,150 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// BoilerplateMinPages is the fewest pages a batch needs before repeated
+	// blocks are treated as boilerplate.
+	BoilerplateMinPages = 3
+
+	// BoilerplateThreshold is the share of pages a block must appear on to
+	// be treated as boilerplate.
+	BoilerplateThreshold = 0.6
+)
+
+// splitBlocks splits Markdown or text into blocks separated by blank lines,
+// keeping fenced code blocks whole.
+func splitBlocks(content string) []string {
+	var blocks []string
+	var current []string
+	inFence := false
+
+	flush := func() {
+		if len(current) > 0 {
+			blocks = append(blocks, strings.Join(current, "\n"))
+			current = nil
+		}
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+
+		if trimmed == "" && !inFence {
+			flush()
+			continue
+		}
+		current = append(current, line)
+	}
+	flush()
+
+	return blocks
+}
+
+// blockKey normalises whitespace so the same block matches across pages.
+func blockKey(block string) string {
+	return strings.Join(strings.Fields(block), " ")
+}
+
+// findBoilerplate returns the keys of blocks that appear on at least
+// BoilerplateThreshold of pages. Headings are never boilerplate, since
+// pages of the same kind often share section names.
+func findBoilerplate(pages [][]string) map[string]bool {
+	boilerplate := make(map[string]bool)
+	if len(pages) < BoilerplateMinPages {
+		return boilerplate
+	}
+
+	counts := make(map[string]int)
+	for _, blocks := range pages {
+		seen := make(map[string]bool)
+		for _, block := range blocks {
+			trimmed := strings.TrimSpace(block)
+			if level, _ := markdownHeading(trimmed); level > 0 && !strings.Contains(trimmed, "\n") {
+				continue
+			}
+
+			key := blockKey(block)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			counts[key]++
+		}
+	}
+
+	minCount := max(BoilerplateMinPages, int(float64(len(pages))*BoilerplateThreshold+0.999))
+	for key, count := range counts {
+		if count >= minCount {
+			boilerplate[key] = true
+		}
+	}
+
+	return boilerplate
+}
+
+// removeBoilerplate strips blocks repeated across most of the files at paths
+// and rewrites the files that change. Line endings and a byte order mark
+// written by --eol and --bom are kept. It returns the number of distinct
+// boilerplate blocks found.
+func removeBoilerplate(paths []string) (int, error) {
+	if len(paths) < BoilerplateMinPages {
+		return 0, nil
+	}
+
+	contents := make([]string, len(paths))
+	pages := make([][]string, len(paths))
+	for i, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		contents[i] = string(data)
+		normalized := strings.ReplaceAll(strings.TrimPrefix(contents[i], utf8BOM), "\r\n", "\n")
+		pages[i] = splitBlocks(normalized)
+	}
+
+	boilerplate := findBoilerplate(pages)
+	if len(boilerplate) == 0 {
+		return 0, nil
+	}
+
+	for i, path := range paths {
+		var kept []string
+		for _, block := range pages[i] {
+			if !boilerplate[blockKey(block)] {
+				kept = append(kept, block)
+			}
+		}
+		if len(kept) == len(pages[i]) {
+			continue
+		}
+
+		content := strings.Join(kept, "\n\n") + "\n"
+		if strings.Contains(contents[i], "\r\n") {
+			content = strings.ReplaceAll(content, "\n", "\r\n")
+		}
+		if strings.HasPrefix(contents[i], utf8BOM) {
+			content = utf8BOM + content
+		}
+
+		logger.Debug("Removed %d boilerplate blocks from %s", len(pages[i])-len(kept), path)
+		if err := os.WriteFile(path, []byte(content), DefaultFileMode); err != nil {
+			return 0, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+
+	return len(boilerplate), nil
+}
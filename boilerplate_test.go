@@ -0,0 +1,105 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testNav = "- [Home](/)\n- [Docs](/docs)"
+const testFooter = "© 2025 Example Corp"
+
+func TestSplitBlocks(t *testing.T) {
+	content := "# Title\n\nPara one\nline two\n\n```\ncode\n\nmore code\n```\n\n\nLast"
+	blocks := splitBlocks(content)
+
+	expected := []string{"# Title", "Para one\nline two", "```\ncode\n\nmore code\n```", "Last"}
+	if len(blocks) != len(expected) {
+		t.Fatalf("got %d blocks, expected %d: %q", len(blocks), len(expected), blocks)
+	}
+	for i := range expected {
+		if blocks[i] != expected[i] {
+			t.Errorf("block %d: got %q, expected %q", i, blocks[i], expected[i])
+		}
+	}
+}
+
+func TestRemoveBoilerplate(t *testing.T) {
+	dir := t.TempDir()
+
+	var paths []string
+	for i := 1; i <= 4; i++ {
+		body := fmt.Sprintf("Unique content for page %d.", i)
+		footer := testFooter
+		if i == 4 {
+			footer = "Different footer"
+		}
+		content := fmt.Sprintf("%s\n\n## Overview\n\n%s\n\n%s\n", testNav, body, footer)
+
+		path := filepath.Join(dir, fmt.Sprintf("page%d.md", i))
+		os.WriteFile(path, []byte(content), 0644)
+		paths = append(paths, path)
+	}
+
+	removed, err := removeBoilerplate(paths)
+	if err != nil {
+		t.Fatalf("removeBoilerplate failed: %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("expected 2 boilerplate blocks, got %d", removed)
+	}
+
+	data, _ := os.ReadFile(paths[0])
+	if got := string(data); got != "## Overview\n\nUnique content for page 1.\n" {
+		t.Errorf("unexpected page 1:\n%s", got)
+	}
+
+	data, _ = os.ReadFile(paths[3])
+	if got := string(data); got != "## Overview\n\nUnique content for page 4.\n\nDifferent footer\n" {
+		t.Errorf("unexpected page 4:\n%s", got)
+	}
+}
+
+func TestRemoveBoilerplate_KeepsEncoding(t *testing.T) {
+	dir := t.TempDir()
+
+	var paths []string
+	for i := 1; i <= 3; i++ {
+		content := utf8BOM + strings.ReplaceAll(fmt.Sprintf("%s\n\nPage %d\n", testNav, i), "\n", "\r\n")
+		path := filepath.Join(dir, fmt.Sprintf("page%d.md", i))
+		os.WriteFile(path, []byte(content), 0644)
+		paths = append(paths, path)
+	}
+
+	if _, err := removeBoilerplate(paths); err != nil {
+		t.Fatalf("removeBoilerplate failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(paths[1])
+	if got := string(data); got != utf8BOM+"Page 2\r\n" {
+		t.Errorf("unexpected page 2: %q", got)
+	}
+}
+
+func TestRemoveBoilerplate_TooFewPages(t *testing.T) {
+	dir := t.TempDir()
+
+	var paths []string
+	for i := 1; i <= 2; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("page%d.md", i))
+		os.WriteFile(path, []byte(testNav+"\n\nBody\n"), 0644)
+		paths = append(paths, path)
+	}
+
+	if removed, err := removeBoilerplate(paths); err != nil || removed != 0 {
+		t.Errorf("expected no removal with 2 pages, got %d, %v", removed, err)
+	}
+}
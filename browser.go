@@ -40,6 +40,12 @@ type BrowserManager struct {
 	forceHeadless    bool
 	openBrowser      bool
 	browserName      string
+	throttle         *proto.NetworkEmulateNetworkConditions
+	memoryLimit      int64
+	watchdog         *MemoryWatchdog
+	readOnly         bool
+	offline          bool
+	remoteOnly       bool
 }
 
 type BrowserOptions struct {
@@ -48,6 +54,13 @@ type BrowserOptions struct {
 	OpenBrowser   bool
 	UserAgent     string
 	UserDataDir   string
+	Throttle      *proto.NetworkEmulateNetworkConditions
+	MemoryLimit   int64
+	ReadOnly      bool
+	Offline       bool
+	// RemoteOnly connects to the browser on Port and never launches one,
+	// for a browser reached through --k8s-pod
+	RemoteOnly bool
 }
 
 type TabInfo struct {
@@ -123,10 +136,31 @@ func NewBrowserManager(opts BrowserOptions) *BrowserManager {
 		userDataDir:   opts.UserDataDir,
 		forceHeadless: opts.ForceHeadless,
 		openBrowser:   opts.OpenBrowser,
+		throttle:      opts.Throttle,
+		memoryLimit:   opts.MemoryLimit,
+		readOnly:      opts.ReadOnly,
+		offline:       opts.Offline,
+		remoteOnly:    opts.RemoteOnly,
 	}
 }
 
 func (bm *BrowserManager) Connect() (*rod.Browser, error) {
+	if bm.remoteOnly {
+		browser, err := bm.connectToExisting()
+		if err != nil {
+			logger.Error("Cannot connect to the browser on port %d", bm.port)
+			logger.ErrorWithSuggestion(
+				"The pod's browser must be running with --remote-debugging-port",
+				"chromium --headless --remote-debugging-port=9222 --remote-debugging-address=0.0.0.0",
+			)
+			return nil, err
+		}
+		logger.Success("Connected to remote browser")
+		bm.browser = browser
+		bm.wasLaunched = false
+		return browser, nil
+	}
+
 	if !bm.forceHeadless {
 		logger.Verbose("Checking for existing browser instance on port %d...", bm.port)
 		if browser, err := bm.connectToExisting(); err == nil {
@@ -141,6 +175,12 @@ func (bm *BrowserManager) Connect() (*rod.Browser, error) {
 			if bm.userAgent != "" {
 				logger.Warning("--user-agent ignored (browser already running with its own user agent)")
 			}
+			if bm.memoryLimit > 0 {
+				logger.Warning("--browser-memory-limit ignored (browser was not launched by snag)")
+			}
+			if bm.offline {
+				logger.Warning("--offline cannot stop background traffic of a browser not launched by snag")
+			}
 			bm.browser = browser
 			bm.wasLaunched = false
 			return browser, nil
@@ -151,6 +191,11 @@ func (bm *BrowserManager) Connect() (*rod.Browser, error) {
 	headless := bm.forceHeadless || !bm.openBrowser
 
 	if headless {
+		// The cleanup removes profiles from the temp directory, which
+		// --read-only leaves untouched
+		if !bm.readOnly {
+			cleanupZombieBrowsers()
+		}
 		logger.Verbose("Launching browser in headless mode...")
 	} else {
 		logger.Info("Launching browser in visible mode...")
@@ -167,12 +212,42 @@ func (bm *BrowserManager) Connect() (*rod.Browser, error) {
 		logger.Success("%s launched in visible mode", bm.browserName)
 	}
 
+	metrics.IncBrowserLaunches()
+
 	bm.browser = browser
 	bm.wasLaunched = true
 	bm.launchedHeadless = headless
+
+	if bm.memoryLimit > 0 && headless && bm.launcher != nil {
+		logger.Verbose("Watching browser memory (limit %s)", formatByteSize(bm.memoryLimit))
+		bm.watchdog = StartMemoryWatchdog(bm.launcher.PID(), bm.memoryLimit)
+	}
+
 	return browser, nil
 }
 
+// RestartIfOverMemory restarts a launched headless browser that has gone
+// over --browser-memory-limit. Call it between pages so no page is cut off.
+func (bm *BrowserManager) RestartIfOverMemory() error {
+	if bm.watchdog == nil {
+		return nil
+	}
+	exceeded, usage := bm.watchdog.Exceeded()
+	if !exceeded {
+		return nil
+	}
+
+	logger.Warning("Browser is using %s (limit %s), restarting it", formatByteSize(usage), formatByteSize(bm.memoryLimit))
+	bm.Close()
+	bm.browser = nil
+	bm.launcher = nil
+
+	if _, err := bm.Connect(); err != nil {
+		return fmt.Errorf("failed to restart browser: %w", err)
+	}
+	return nil
+}
+
 func (bm *BrowserManager) connectToExisting() (*rod.Browser, error) {
 	baseURL := fmt.Sprintf("http://127.0.0.1:%d", bm.port)
 	logger.Debug("Attempting connection to: %s", baseURL)
@@ -194,16 +269,33 @@ func (bm *BrowserManager) connectToExisting() (*rod.Browser, error) {
 	return browser.CancelTimeout(), nil
 }
 
+// setOfflineFlags stops the browser's own traffic to Google and other
+// services, such as component and Safe Browsing updates, so only the
+// pages snag loads reach the network
+func setOfflineFlags(l *launcher.Launcher) *launcher.Launcher {
+	return l.Set("disable-background-networking").
+		Set("disable-component-update").
+		Set("disable-domain-reliability").
+		Set("disable-client-side-phishing-detection").
+		Set("safebrowsing-disable-auto-update").
+		Set("no-pings").
+		Set("disable-sync").
+		Set("disable-features", "site-per-process", "TranslateUI", "OptimizationHints",
+			"MediaRouter", "AutofillServerCommunication", "CertificateTransparencyComponentUpdater")
+}
+
 func (bm *BrowserManager) launchBrowser(headless bool) (*rod.Browser, error) {
 	path, err := bm.findBrowserPath()
 	if err != nil {
 		return nil, err
 	}
 
+	// Leakless unpacks a helper binary into the temp directory and leaves
+	// it there, so read-only runs rely on Close to stop the browser
 	l := launcher.New().
 		Bin(path).
 		Headless(headless).
-		Leakless(headless).
+		Leakless(headless && !bm.readOnly).
 		Set("disable-blink-features", "AutomationControlled")
 
 	if bm.userAgent != "" {
@@ -214,12 +306,40 @@ func (bm *BrowserManager) launchBrowser(headless bool) (*rod.Browser, error) {
 	if bm.userDataDir != "" {
 		l = l.Set("user-data-dir", bm.userDataDir)
 		logger.Verbose("Using custom user data directory: %s", bm.userDataDir)
+	} else if !bm.readOnly {
+		profile, err := newProfileDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create browser profile: %w", err)
+		}
+		l = l.UserDataDir(profile)
+		logger.Debug("Using profile: %s", profile)
+	}
+
+	// The profile, with its cache and crash reports, goes in a directory
+	// Close removes whole, not under a shared one left behind
+	var tempProfile string
+	if bm.readOnly && bm.userDataDir == "" {
+		tempProfile, err = os.MkdirTemp("", "snag-profile-*")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temporary profile: %w", err)
+		}
+		l = l.Set("user-data-dir", tempProfile).
+			Set("disk-cache-dir", filepath.Join(tempProfile, "cache")).
+			Set("disable-breakpad")
+		logger.Debug("Using temporary profile: %s", tempProfile)
+	}
+
+	if bm.offline {
+		l = setOfflineFlags(l)
 	}
 
 	l = l.Set("remote-debugging-port", fmt.Sprintf("%d", bm.port))
 
 	controlURL, err := l.Launch()
 	if err != nil {
+		if tempProfile != "" {
+			os.RemoveAll(tempProfile)
+		}
 		return nil, fmt.Errorf("failed to launch browser: %w", err)
 	}
 	logger.Debug("Browser launched with control URL: %s", controlURL)
@@ -230,6 +350,10 @@ func (bm *BrowserManager) launchBrowser(headless bool) (*rod.Browser, error) {
 
 	if err := browser.Connect(); err != nil {
 		logger.Debug("Failed to connect to launched browser: %v", err)
+		if tempProfile != "" {
+			l.Kill()
+			l.Cleanup()
+		}
 		return nil, fmt.Errorf("%w: %w", ErrBrowserConnection, err)
 	}
 	logger.Debug("Successfully connected to launched browser")
@@ -247,6 +371,9 @@ func (bm *BrowserManager) OpenBrowserOnly() error {
 		if bm.userAgent != "" {
 			logger.Warning("--user-agent ignored (browser already running with its own user agent)")
 		}
+		if bm.offline {
+			logger.Warning("--offline cannot stop background traffic of a browser not launched by snag")
+		}
 		logger.Info("You can connect to it using: snag <url>")
 		return nil
 	}
@@ -273,6 +400,10 @@ func (bm *BrowserManager) OpenBrowserOnly() error {
 		logger.Verbose("Using custom user data directory: %s", bm.userDataDir)
 	}
 
+	if bm.offline {
+		l = setOfflineFlags(l)
+	}
+
 	controlURL, err := l.Launch()
 	if err != nil {
 		return fmt.Errorf("failed to launch browser: %w", err)
@@ -324,10 +455,24 @@ func (bm *BrowserManager) NewPage() (*rod.Page, error) {
 		}
 	}
 
+	if bm.throttle != nil {
+		if err := bm.throttle.Call(page); err != nil {
+			logger.Warning("Failed to apply network throttling: %v", err)
+		} else {
+			logger.Verbose("Network throttling: %.0f kbps down, %.0f kbps up, %.0fms latency",
+				bm.throttle.DownloadThroughput*8/1000, bm.throttle.UploadThroughput*8/1000, bm.throttle.Latency)
+		}
+	}
+
 	return page, nil
 }
 
 func (bm *BrowserManager) Close() {
+	if bm.watchdog != nil {
+		bm.watchdog.Stop()
+		bm.watchdog = nil
+	}
+
 	if bm.browser == nil {
 		return
 	}
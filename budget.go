@@ -0,0 +1,210 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// byteUnits are the suffixes parseByteSize accepts, in binary multiples to
+// match the sizes snag reports.
+var byteUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"gib", 1 << 30}, {"gb", 1 << 30}, {"g", 1 << 30},
+	{"mib", 1 << 20}, {"mb", 1 << 20}, {"m", 1 << 20},
+	{"kib", 1 << 10}, {"kb", 1 << 10}, {"k", 1 << 10},
+	{"b", 1},
+}
+
+// parseByteSize parses a size such as "50MB", "1.5GB", or "512k". A bare
+// number is bytes.
+func parseByteSize(value string) (int64, error) {
+	s := strings.ToLower(strings.TrimSpace(value))
+	multiplier := int64(1)
+	for _, unit := range byteUnits {
+		if rest, ok := strings.CutSuffix(s, unit.suffix); ok {
+			s = strings.TrimSpace(rest)
+			multiplier = unit.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size: %s", value)
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+// formatByteSize renders n bytes with the largest whole unit, e.g. 50.0 MB.
+func formatByteSize(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// ResourceBudget counts the requests a page makes and the bytes it
+// downloads while loading, and stops the page once --max-bytes or
+// --max-requests is exceeded.
+type ResourceBudget struct {
+	page        *rod.Page
+	maxBytes    int64
+	maxRequests int
+	cancel      context.CancelFunc
+	done        chan struct{}
+
+	mu       sync.Mutex
+	received map[proto.NetworkRequestID]int64
+	bytes    int64
+	requests int
+	exceeded string
+}
+
+// StartResourceBudget begins counting requests and bytes on page. A zero
+// limit is unlimited. Call Stop once the page has finished loading.
+func StartResourceBudget(page *rod.Page, maxBytes int64, maxRequests int) *ResourceBudget {
+	ctx, cancel := context.WithCancel(context.Background())
+	rb := &ResourceBudget{
+		page:        page,
+		maxBytes:    maxBytes,
+		maxRequests: maxRequests,
+		cancel:      cancel,
+		done:        make(chan struct{}),
+		received:    make(map[proto.NetworkRequestID]int64),
+	}
+
+	wait := page.Context(ctx).EachEvent(
+		func(e *proto.NetworkRequestWillBeSent) {
+			rb.mu.Lock()
+			defer rb.mu.Unlock()
+			rb.requests++
+			if rb.maxRequests > 0 && rb.requests > rb.maxRequests {
+				rb.exceed(fmt.Sprintf("more than %d requests", rb.maxRequests))
+			}
+		},
+		func(e *proto.NetworkDataReceived) {
+			n := int64(e.EncodedDataLength)
+			if n == 0 {
+				n = int64(e.DataLength)
+			}
+			rb.addBytes(e.RequestID, n, false)
+		},
+		func(e *proto.NetworkLoadingFinished) {
+			// The final count includes headers and corrects data events
+			// that reported no encoded length
+			rb.addBytes(e.RequestID, int64(e.EncodedDataLength), true)
+		},
+	)
+
+	go func() {
+		defer close(rb.done)
+		wait()
+	}()
+
+	return rb
+}
+
+// addBytes records n more bytes for a request, or its total when final is
+// set and n is larger than what was counted so far.
+func (rb *ResourceBudget) addBytes(id proto.NetworkRequestID, n int64, final bool) {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	if final {
+		if n <= rb.received[id] {
+			return
+		}
+		n -= rb.received[id]
+	}
+	rb.received[id] += n
+	rb.bytes += n
+
+	if rb.maxBytes > 0 && rb.bytes > rb.maxBytes {
+		rb.exceed(fmt.Sprintf("downloaded more than %s", formatByteSize(rb.maxBytes)))
+	}
+}
+
+// exceed marks the budget as exceeded and stops the page from loading
+// anything more. Callers hold rb.mu.
+func (rb *ResourceBudget) exceed(reason string) {
+	if rb.exceeded != "" {
+		return
+	}
+	rb.exceeded = reason
+
+	go func() {
+		logger.Warning("Resource budget exceeded (%s), stopping page load", reason)
+		if err := (proto.NetworkSetBlockedURLs{Urls: []string{"*"}}).Call(rb.page); err != nil {
+			logger.Debug("Failed to block further requests: %v", err)
+		}
+		if err := (proto.PageStopLoading{}).Call(rb.page); err != nil {
+			logger.Debug("Failed to stop page loading: %v", err)
+		}
+	}()
+}
+
+// Err returns an error wrapping ErrResourceBudget once a limit has been
+// exceeded, or nil.
+func (rb *ResourceBudget) Err() error {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	if rb.exceeded == "" {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrResourceBudget, rb.exceeded)
+}
+
+// Usage returns the bytes downloaded and requests made so far.
+func (rb *ResourceBudget) Usage() (int64, int) {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	return rb.bytes, rb.requests
+}
+
+// Stop ends counting and lifts the request block set when the budget was
+// exceeded, so a reused tab can load again.
+func (rb *ResourceBudget) Stop() {
+	rb.cancel()
+	<-rb.done
+
+	if rb.Err() != nil {
+		if err := (proto.NetworkSetBlockedURLs{Urls: []string{}}).Call(rb.page); err != nil {
+			logger.Debug("Failed to unblock requests: %v", err)
+		}
+	}
+}
+
+// activeResourceBudget starts a budget on page from --max-bytes and
+// --max-requests, or returns nil when neither is set.
+func activeResourceBudget(page *rod.Page) (*ResourceBudget, error) {
+	var limit int64
+	if strings.TrimSpace(maxBytes) != "" {
+		var err error
+		if limit, err = parseByteSize(maxBytes); err != nil {
+			return nil, err
+		}
+	}
+	if limit == 0 && maxRequests <= 0 {
+		return nil, nil
+	}
+	return StartResourceBudget(page, limit, max(maxRequests, 0)), nil
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"1024", 1024, false},
+		{"50MB", 50 << 20, false},
+		{"50 mb", 50 << 20, false},
+		{"1.5GB", 3 << 29, false},
+		{"512k", 512 << 10, false},
+		{"2GiB", 2 << 30, false},
+		{"10B", 10, false},
+		{"", 0, true},
+		{"MB", 0, true},
+		{"-5MB", 0, true},
+		{"fiftyMB", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseByteSize(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseByteSize(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseByteSize(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormatByteSize(t *testing.T) {
+	tests := map[int64]string{
+		512:      "512 B",
+		2048:     "2.0 KB",
+		50 << 20: "50.0 MB",
+		3 << 29:  "1.5 GB",
+	}
+	for n, want := range tests {
+		if got := formatByteSize(n); got != want {
+			t.Errorf("formatByteSize(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestResourceBudget_AddBytes(t *testing.T) {
+	rb := &ResourceBudget{received: make(map[proto.NetworkRequestID]int64)}
+
+	rb.addBytes("a", 100, false)
+	rb.addBytes("a", 50, false)
+	rb.addBytes("a", 180, true) // headers counted at finish
+	rb.addBytes("b", 0, false)
+	rb.addBytes("b", 20, true)
+	rb.addBytes("c", 40, false)
+	rb.addBytes("c", 10, true) // smaller final count is ignored
+
+	if bytes, _ := rb.Usage(); bytes != 240 {
+		t.Errorf("bytes = %d, want 240", bytes)
+	}
+	if err := rb.Err(); err != nil {
+		t.Errorf("unlimited budget exceeded: %v", err)
+	}
+
+	rb.exceeded = "more than 5 requests"
+	if err := rb.Err(); !errors.Is(err, ErrResourceBudget) {
+		t.Errorf("expected ErrResourceBudget, got %v", err)
+	}
+}
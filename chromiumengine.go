@@ -0,0 +1,93 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// chromiumEngine fetches pages in Chromium through PageFetcher, as the
+// Engine for --engine chromium. Fetches that need the page itself, such as
+// tabs, interaction, and PDF, drive the PageFetcher in handlers.go
+// directly.
+type chromiumEngine struct {
+	bm      *BrowserManager
+	page    *rod.Page
+	fetcher *PageFetcher
+}
+
+func (e *chromiumEngine) Start() error {
+	e.bm = NewBrowserManager(BrowserOptions{
+		Port:          port,
+		UserAgent:     validateUserAgent(userAgent, userAgent != ""),
+		ForceHeadless: forceHead,
+		ReadOnly:      readOnly,
+		Offline:       offline,
+		RemoteOnly:    k8sPod != "",
+	})
+	browserMutex.Lock()
+	browserManager = e.bm
+	browserMutex.Unlock()
+
+	if _, err := e.bm.Connect(); err != nil {
+		return err
+	}
+	page, err := e.bm.NewPage()
+	if err != nil {
+		e.bm.Close()
+		return err
+	}
+	e.page = page
+	e.fetcher = NewPageFetcher(page, timeout)
+	return nil
+}
+
+func (e *chromiumEngine) Load(url string) (*LoadedPage, error) {
+	html, err := e.fetcher.Fetch(FetchOptions{
+		URL:     url,
+		Timeout: timeout,
+		WaitFor: validateWaitFor(waitFor, waitFor != ""),
+		Headers: requestHeaders,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := e.page.Info()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page info: %w", err)
+	}
+	return &LoadedPage{
+		URL:      url,
+		FinalURL: info.URL,
+		Title:    info.Title,
+		HTML:     html,
+	}, nil
+}
+
+func (e *chromiumEngine) Screenshot() ([]byte, error) {
+	return e.page.Screenshot(true, &proto.PageCaptureScreenshot{
+		Format: proto.PageCaptureScreenshotFormatPng,
+	})
+}
+
+func (e *chromiumEngine) Close() {
+	if e.bm == nil {
+		return
+	}
+	if e.page != nil {
+		e.bm.ClosePage(e.page)
+	}
+	e.bm.Close()
+
+	browserMutex.Lock()
+	browserManager = nil
+	browserMutex.Unlock()
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewEngine(t *testing.T) {
+	for name, want := range map[string]Engine{
+		EngineChromium: &chromiumEngine{},
+		EngineWebKit:   &webkitEngine{},
+		EngineHTTP:     &httpEngine{},
+	} {
+		eng, err := newEngine(name)
+		if err != nil {
+			t.Fatalf("newEngine(%s) error = %v", name, err)
+		}
+		if got, want := fmt.Sprintf("%T", eng), fmt.Sprintf("%T", want); got != want {
+			t.Errorf("newEngine(%s) = %s, want %s", name, got, want)
+		}
+	}
+	if _, err := newEngine("gecko"); err == nil {
+		t.Error("newEngine(gecko) should fail")
+	}
+}
+
+func TestBrowser_ChromiumEngine(t *testing.T) {
+	if !isBrowserAvailable() {
+		t.Skip("Browser not available, skipping browser integration test")
+	}
+
+	savedHeadless, savedPort := forceHead, port
+	forceHead, port = true, 9333
+	defer func() { forceHead, port = savedHeadless, savedPort }()
+
+	server := startTestServer(t)
+	output := filepath.Join(t.TempDir(), "page.md")
+
+	eng, err := newEngine(EngineChromium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := eng.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer eng.Close()
+
+	if err := fetchWithEngine(eng, server.URL+"/simple.html", FormatMarkdown, output, "", time.Now()); err != nil {
+		t.Fatalf("fetchWithEngine() error = %v", err)
+	}
+	content, err := os.ReadFile(output)
+	assertNoError(t, err)
+	assertContains(t, string(content), "# Example Heading")
+}
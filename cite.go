@@ -0,0 +1,252 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+const (
+	CiteAPA     = "apa"
+	CiteMLA     = "mla"
+	CiteChicago = "chicago"
+	CiteBibTeX  = "bibtex"
+)
+
+// authorMetaNames are the <meta> names and properties pages use to credit
+// their authors, in order of preference.
+var authorMetaNames = []string{"citation_author", "dc.creator", "author", "article:author", "parsely-author", "sailthru.author"}
+
+// publishedMetaNames are the <meta> names and properties pages use for their
+// publication date, in order of preference.
+var publishedMetaNames = []string{"citation_publication_date", "citation_date", "article:published_time", "dc.date", "date", "pubdate"}
+
+// Citation is the source information a --cite block is built from. Authors
+// and Published are empty when the page does not declare them.
+type Citation struct {
+	Title     string
+	URL       string
+	Site      string
+	Authors   []string
+	Published time.Time
+	Accessed  time.Time
+}
+
+// newCitation collects the citation details of page.
+func newCitation(page *rod.Page) (*Citation, error) {
+	info, err := page.Info()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page info: %w", err)
+	}
+
+	c := &Citation{
+		Title:    strings.TrimSpace(info.Title),
+		URL:      info.URL,
+		Site:     extractDomain(info.URL),
+		Accessed: time.Now(),
+	}
+
+	res, err := page.Eval(`() => [...document.querySelectorAll('meta[content]')]
+		.map(m => [(m.getAttribute('name') || m.getAttribute('property') || '').toLowerCase(), m.content.trim()])
+		.filter(([key, value]) => key && value)`)
+	if err != nil {
+		logger.Debug("Failed to read citation meta tags: %v", err)
+		return c, nil
+	}
+
+	meta := make(map[string][]string)
+	for _, pair := range res.Value.Arr() {
+		kv := pair.Arr()
+		if len(kv) == 2 {
+			meta[kv[0].Str()] = append(meta[kv[0].Str()], kv[1].Str())
+		}
+	}
+
+	if site := meta["og:site_name"]; len(site) > 0 {
+		c.Site = site[0]
+	}
+	for _, name := range authorMetaNames {
+		for _, author := range meta[name] {
+			// article:author is often a profile URL rather than a name
+			if !strings.Contains(author, "://") {
+				c.Authors = append(c.Authors, author)
+			}
+		}
+		if len(c.Authors) > 0 {
+			break
+		}
+	}
+	for _, name := range publishedMetaNames {
+		if values := meta[name]; len(values) > 0 {
+			if published, ok := parseCitationDate(values[0]); ok {
+				c.Published = published
+				break
+			}
+		}
+	}
+
+	return c, nil
+}
+
+// parseCitationDate reads the date from the start of a meta tag value such
+// as "2025-02-04", "2025/02/04", or "2025-02-04T10:30:00Z".
+func parseCitationDate(value string) (time.Time, bool) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), "/", "-")
+	if len(value) < 10 {
+		return time.Time{}, false
+	}
+	t, err := time.Parse("2006-01-02", value[:10])
+	return t, err == nil
+}
+
+// Format renders the citation in style. Titles are italicized with
+// markdown emphasis when markdown is set.
+func (c *Citation) Format(style string, markdown bool) string {
+	title := c.Title
+	if title == "" {
+		title = c.URL
+	}
+	italic := func(s string) string {
+		if markdown {
+			return "*" + s + "*"
+		}
+		return s
+	}
+
+	switch style {
+	case CiteMLA:
+		// Smith, Jane. "Title." Site, 4 Feb. 2025, URL. Accessed 16 Oct. 2026.
+		var b strings.Builder
+		if len(c.Authors) > 0 {
+			b.WriteString(strings.TrimSuffix(joinAuthors(c.Authors, "and"), ".") + ". ")
+		}
+		fmt.Fprintf(&b, "\"%s.\" %s, ", strings.TrimSuffix(title, "."), italic(c.Site))
+		if !c.Published.IsZero() {
+			b.WriteString(mlaDate(c.Published) + ", ")
+		}
+		fmt.Fprintf(&b, "%s. Accessed %s.", c.URL, mlaDate(c.Accessed))
+		return b.String()
+
+	case CiteChicago:
+		// Smith, Jane. "Title." Site. February 4, 2025. Accessed October 16, 2026. URL.
+		var b strings.Builder
+		if len(c.Authors) > 0 {
+			b.WriteString(strings.TrimSuffix(joinAuthors(c.Authors, "and"), ".") + ". ")
+		}
+		fmt.Fprintf(&b, "\"%s.\" %s. ", strings.TrimSuffix(title, "."), c.Site)
+		if !c.Published.IsZero() {
+			b.WriteString(c.Published.Format("January 2, 2006") + ". ")
+		}
+		fmt.Fprintf(&b, "Accessed %s. %s.", c.Accessed.Format("January 2, 2006"), c.URL)
+		return b.String()
+
+	case CiteBibTeX:
+		year := c.Accessed.Year()
+		if !c.Published.IsZero() {
+			year = c.Published.Year()
+		}
+		key := SlugifyTitle(c.Site, 30)
+		if key == "" {
+			key = "web"
+		}
+		fields := []string{}
+		if len(c.Authors) > 0 {
+			fields = append(fields, fmt.Sprintf("  author       = {%s}", strings.Join(c.Authors, " and ")))
+		}
+		fields = append(fields,
+			fmt.Sprintf("  title        = {%s}", title),
+			fmt.Sprintf("  howpublished = {\\url{%s}}", c.URL),
+			fmt.Sprintf("  year         = {%d}", year),
+			fmt.Sprintf("  note         = {Accessed: %s}", c.Accessed.Format("2006-01-02")),
+		)
+		return fmt.Sprintf("@misc{%s%d,\n%s\n}", strings.ReplaceAll(key, "-", ""), year, strings.Join(fields, ",\n"))
+
+	default:
+		// Smith, J. (2025, February 4). Title. Site. Retrieved October 16, 2026, from URL
+		date := "n.d."
+		if !c.Published.IsZero() {
+			date = c.Published.Format("2006, January 2")
+		}
+		var b strings.Builder
+		if len(c.Authors) > 0 {
+			fmt.Fprintf(&b, "%s. (%s). %s. ", strings.TrimSuffix(joinAuthors(c.Authors, "&"), "."), date, italic(strings.TrimSuffix(title, ".")))
+		} else {
+			fmt.Fprintf(&b, "%s. (%s). ", italic(strings.TrimSuffix(title, ".")), date)
+		}
+		fmt.Fprintf(&b, "%s. Retrieved %s, from %s", c.Site, c.Accessed.Format("January 2, 2006"), c.URL)
+		return b.String()
+	}
+}
+
+// Block renders the citation as a source section to append to output in
+// format, set apart from the page content.
+func (c *Citation) Block(style string, format string) string {
+	text := c.Format(style, format == FormatMarkdown)
+
+	if style == CiteBibTeX {
+		switch format {
+		case FormatMarkdown:
+			return "\n\n---\n\n```bibtex\n" + text + "\n```\n"
+		case FormatOrg:
+			return "\n\n-----\n\n#+begin_src bibtex\n" + text + "\n#+end_src\n"
+		case FormatRST:
+			return "\n\n----\n\n.. code-block:: bibtex\n\n" + indentLines(text, "   ") + "\n"
+		case FormatHTML:
+			return "<footer class=\"snag-citation\"><pre>" + html.EscapeString(text) + "</pre></footer>\n"
+		}
+		return "\n\n" + text + "\n"
+	}
+
+	switch format {
+	case FormatMarkdown:
+		return "\n\n---\n\n**Source:** " + text + "\n"
+	case FormatOrg:
+		return "\n\n-----\n\n*Source:* " + text + "\n"
+	case FormatRST:
+		return "\n\n----\n\n**Source:** " + text + "\n"
+	case FormatHTML:
+		return "<footer class=\"snag-citation\"><p>Source: " + html.EscapeString(text) + "</p></footer>\n"
+	}
+	return "\n\nSource: " + text + "\n"
+}
+
+// appendCitation adds block to the end of content. For HTML it goes inside
+// the body when there is one.
+func appendCitation(content string, block string, format string) string {
+	if format == FormatHTML {
+		if i := strings.LastIndex(strings.ToLower(content), "</body>"); i >= 0 {
+			return content[:i] + block + content[i:]
+		}
+		return content + block
+	}
+	return strings.TrimRight(content, "\n") + block
+}
+
+// joinAuthors lists authors separated by commas, with conjunction before
+// the last, as in "Ann Lee, Bo Chan, & Cy Dee".
+func joinAuthors(authors []string, conjunction string) string {
+	switch len(authors) {
+	case 0:
+		return ""
+	case 1:
+		return authors[0]
+	case 2:
+		return authors[0] + " " + conjunction + " " + authors[1]
+	}
+	return strings.Join(authors[:len(authors)-1], ", ") + ", " + conjunction + " " + authors[len(authors)-1]
+}
+
+// mlaDate formats t the way MLA does, with abbreviated month names.
+func mlaDate(t time.Time) string {
+	months := []string{"Jan.", "Feb.", "Mar.", "Apr.", "May", "June", "July", "Aug.", "Sept.", "Oct.", "Nov.", "Dec."}
+	return fmt.Sprintf("%d %s %d", t.Day(), months[t.Month()-1], t.Year())
+}
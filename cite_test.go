@@ -0,0 +1,128 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func testCitation() *Citation {
+	return &Citation{
+		Title:     "Article Title",
+		URL:       "https://example.com/article",
+		Site:      "Example News",
+		Authors:   []string{"Jane Smith", "Bo Chan"},
+		Published: time.Date(2025, 2, 4, 0, 0, 0, 0, time.UTC),
+		Accessed:  time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestCitationFormat(t *testing.T) {
+	c := testCitation()
+
+	tests := []struct {
+		style    string
+		markdown bool
+		expected string
+	}{
+		{CiteAPA, true, "Jane Smith & Bo Chan. (2025, February 4). *Article Title*. Example News. Retrieved October 16, 2026, from https://example.com/article"},
+		{CiteAPA, false, "Jane Smith & Bo Chan. (2025, February 4). Article Title. Example News. Retrieved October 16, 2026, from https://example.com/article"},
+		{CiteMLA, false, `Jane Smith and Bo Chan. "Article Title." Example News, 4 Feb. 2025, https://example.com/article. Accessed 16 Oct. 2026.`},
+		{CiteChicago, false, `Jane Smith and Bo Chan. "Article Title." Example News. February 4, 2025. Accessed October 16, 2026. https://example.com/article.`},
+	}
+
+	for _, tt := range tests {
+		if got := c.Format(tt.style, tt.markdown); got != tt.expected {
+			t.Errorf("Format(%s, %v) =\n%s\nexpected:\n%s", tt.style, tt.markdown, got, tt.expected)
+		}
+	}
+}
+
+func TestCitationFormat_NoAuthorOrDate(t *testing.T) {
+	c := testCitation()
+	c.Authors = nil
+	c.Published = time.Time{}
+
+	expected := "Article Title. (n.d.). Example News. Retrieved October 16, 2026, from https://example.com/article"
+	if got := c.Format(CiteAPA, false); got != expected {
+		t.Errorf("APA without author =\n%s\nexpected:\n%s", got, expected)
+	}
+
+	expected = `"Article Title." Example News, https://example.com/article. Accessed 16 Oct. 2026.`
+	if got := c.Format(CiteMLA, false); got != expected {
+		t.Errorf("MLA without author =\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestCitationFormat_BibTeX(t *testing.T) {
+	expected := `@misc{examplenews2025,
+  author       = {Jane Smith and Bo Chan},
+  title        = {Article Title},
+  howpublished = {\url{https://example.com/article}},
+  year         = {2025},
+  note         = {Accessed: 2026-10-16}
+}`
+	if got := testCitation().Format(CiteBibTeX, true); got != expected {
+		t.Errorf("BibTeX =\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestAppendCitation(t *testing.T) {
+	c := testCitation()
+
+	got := appendCitation("# Article Title\n\nBody.\n\n", c.Block(CiteAPA, FormatMarkdown), FormatMarkdown)
+	if !strings.HasPrefix(got, "# Article Title\n\nBody.\n\n---\n\n**Source:** Jane Smith") || !strings.HasSuffix(got, "/article\n") {
+		t.Errorf("markdown citation:\n%s", got)
+	}
+
+	got = appendCitation("<html><body><p>Body.</p></body></html>", c.Block(CiteAPA, FormatHTML), FormatHTML)
+	if !strings.Contains(got, `<p>Body.</p><footer class="snag-citation"><p>Source: Jane Smith &amp; Bo Chan.`) || !strings.HasSuffix(got, "</body></html>") {
+		t.Errorf("html citation:\n%s", got)
+	}
+
+	got = appendCitation("Body.\n", c.Block(CiteBibTeX, FormatOrg), FormatOrg)
+	if !strings.Contains(got, "Body.\n\n-----\n\n#+begin_src bibtex\n@misc{") {
+		t.Errorf("org bibtex citation:\n%s", got)
+	}
+}
+
+func TestParseCitationDate(t *testing.T) {
+	tests := []struct {
+		value string
+		ok    bool
+	}{
+		{"2025-02-04", true},
+		{"2025/02/04", true},
+		{"2025-02-04T10:30:00+10:00", true},
+		{"February 4, 2025", false},
+		{"2025", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseCitationDate(tt.value)
+		if ok != tt.ok {
+			t.Errorf("parseCitationDate(%q) ok = %v, expected %v", tt.value, ok, tt.ok)
+		}
+		if ok && got.Format("2006-01-02") != "2025-02-04" {
+			t.Errorf("parseCitationDate(%q) = %v", tt.value, got)
+		}
+	}
+}
+
+func TestCLI_CiteInvalidStyle(t *testing.T) {
+	_, stderr, err := runSnag("--cite", "harvard", "example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "Citation style must be apa, mla, chicago, or bibtex")
+}
+
+func TestCLI_CiteWithPDF(t *testing.T) {
+	_, stderr, err := runSnag("--cite", "apa", "--format", "pdf", "example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "Cannot use --cite with --format pdf")
+}
@@ -17,6 +17,7 @@ import (
 	"os/signal"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"testing"
 )
@@ -314,6 +315,28 @@ func TestCLI_InvalidFormat(t *testing.T) {
 	assertContains(t, output, "format")
 }
 
+// TestCLI_InvalidExpectStatus tests invalid --expect-status values
+func TestCLI_InvalidExpectStatus(t *testing.T) {
+	stdout, stderr, err := runSnag("--expect-status", "2xy", "https://example.com")
+
+	assertError(t, err)
+	assertExitCode(t, err, 1)
+
+	output := stdout + stderr
+	assertContains(t, output, "expect-status")
+}
+
+// TestCLI_InvalidHostPattern tests invalid --block-host patterns
+func TestCLI_InvalidHostPattern(t *testing.T) {
+	stdout, stderr, err := runSnag("--block-host", "http://internal/", "https://example.com")
+
+	assertError(t, err)
+	assertExitCode(t, err, 1)
+
+	output := stdout + stderr
+	assertContains(t, output, "host pattern")
+}
+
 // TestCLI_InvalidTimeout tests invalid timeout values
 func TestCLI_InvalidTimeout(t *testing.T) {
 	tests := []struct {
@@ -478,6 +501,54 @@ func TestBrowser_FetchSimpleHTML(t *testing.T) {
 	}
 }
 
+// TestBrowser_ExpandCollapsedContent tests that <details> and hidden tab
+// panels are expanded before extraction, and left alone with --no-expand
+func TestBrowser_ExpandCollapsedContent(t *testing.T) {
+	if !isBrowserAvailable() {
+		t.Skip("Browser not available, skipping browser integration test")
+	}
+
+	server := startTestServer(t)
+	url := server.URL + "/collapsed.html"
+
+	stdout, _, err := runSnag("--format", "text", "--text-engine", "dom", url)
+	assertNoError(t, err)
+	assertContains(t, stdout, "Run the installer as an administrator.")
+	assertContains(t, stdout, "macOS")
+	assertContains(t, stdout, "Install with Homebrew.")
+
+	stdout, _, err = runSnag("--format", "text", "--text-engine", "dom", "--no-expand", url)
+	assertNoError(t, err)
+	assertNotContains(t, stdout, "Run the installer as an administrator.")
+	assertNotContains(t, stdout, "Install with Homebrew.")
+}
+
+// TestBrowser_Select tests --select extracts only the matching elements
+func TestBrowser_Select(t *testing.T) {
+	if !isBrowserAvailable() {
+		t.Skip("Browser not available, skipping browser integration test")
+	}
+
+	server := startTestServer(t)
+	url := server.URL + "/simple.html"
+
+	stdout, _, err := runSnag("--select", "h2", url)
+	assertNoError(t, err)
+	assertContains(t, stdout, "Second Level Heading")
+	assertNotContains(t, stdout, "Example Heading")
+	assertNotContains(t, stdout, "simple paragraph")
+
+	stdout, _, err = runSnag("--select", "h1, h2", "--no-activate", url)
+	assertNoError(t, err)
+	assertContains(t, stdout, "Example Heading")
+	assertContains(t, stdout, "Second Level Heading")
+	assertNotContains(t, stdout, "bold text")
+
+	_, stderr, err := runSnag("--select", ".missing", url)
+	assertError(t, err)
+	assertContains(t, stderr, "No element matches --select .missing")
+}
+
 // TestBrowser_FetchComplexHTML tests fetching complex.html with tables and lists
 func TestBrowser_FetchComplexHTML(t *testing.T) {
 	if !isBrowserAvailable() {
@@ -845,6 +916,258 @@ func TestBrowser_WaitForTimeout(t *testing.T) {
 	}
 }
 
+// TestBrowser_WaitForJS tests --wait-for-js polling a readiness global
+func TestBrowser_WaitForJS(t *testing.T) {
+	if !isBrowserAvailable() {
+		t.Skip("Browser not available, skipping browser integration test")
+	}
+
+	server := startTestServer(t)
+	url := server.URL + "/js-ready.html"
+
+	stdout, _, err := runSnag("--wait-for-js", "window.__APP_READY === true", "--timeout", "5", url)
+	assertNoError(t, err)
+	assertContains(t, stdout, "Rendered by the app.")
+
+	// An expression that throws counts as false until the timeout
+	_, stderr, err := runSnag("--wait-for-js", "window.__APP.ready", "--wait-timeout", "1s", url)
+	assertError(t, err)
+	assertContains(t, stderr, "Timeout waiting for expression")
+}
+
+// TestBrowser_FailOnErrorStatus tests that a 404 page is not saved
+func TestBrowser_FailOnErrorStatus(t *testing.T) {
+	if !isBrowserAvailable() {
+		t.Skip("Browser not available, skipping browser integration test")
+	}
+
+	server := startTestServer(t)
+	outputFile := filepath.Join(t.TempDir(), "missing.md")
+
+	_, stderr, err := runSnag("--fail-on-error-status", "-o", outputFile, server.URL+"/missing.html")
+	assertExitCode(t, err, ExitCodeHTTP)
+	assertContains(t, stderr, "Server returned HTTP 404")
+	if _, statErr := os.Stat(outputFile); statErr == nil {
+		t.Error("expected no output file for a 404 page")
+	}
+}
+
+// TestBrowser_BlockImages tests --block images keeping the browser from
+// requesting a page's images
+func TestBrowser_BlockImages(t *testing.T) {
+	if !isBrowserAvailable() {
+		t.Skip("Browser not available, skipping browser integration test")
+	}
+
+	var imageRequests atomic.Int64
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/photo.png" {
+			imageRequests.Add(1)
+			w.Header().Set("Content-Type", "image/png")
+			return
+		}
+		w.Write([]byte(`<html><head><title>Photos</title></head><body><h1>Photos</h1><img src="/photo.png" alt="Photo"></body></html>`))
+	})
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	stdout, _, err := runSnag("--block", "images", server.URL)
+	assertNoError(t, err)
+	assertContains(t, stdout, "# Photos")
+	if n := imageRequests.Load(); n != 0 {
+		t.Errorf("expected no image requests with --block images, got %d", n)
+	}
+}
+
+func TestBrowser_TraceHTTP(t *testing.T) {
+	if !isBrowserAvailable() {
+		t.Skip("Browser not available, skipping browser integration test")
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusFound)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Trace", "yes")
+		w.Write([]byte(`<html><head><title>New</title></head><body><h1>New</h1></body></html>`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	stdout, stderr, err := runSnag("-V", "--quiet", server.URL+"/old")
+	assertNoError(t, err)
+	assertContains(t, stdout, "# New")
+	assertContains(t, stderr, "> GET /old HTTP/1.1")
+	assertContains(t, stderr, "< HTTP/1.1 302 Found")
+	assertContains(t, stderr, "* Issue another request to this URL: '"+server.URL+"/new'")
+	assertContains(t, stderr, "> GET /new HTTP/1.1")
+	assertContains(t, stderr, "< HTTP/1.1 200 OK")
+	assertContains(t, stderr, "< X-Trace: yes")
+}
+
+func TestBrowser_FragmentOnly(t *testing.T) {
+	if !isBrowserAvailable() {
+		t.Skip("Browser not available, skipping browser integration test")
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><head><title>Docs</title></head><body>
+<h1>Docs</h1><p>Intro text</p>
+<h2 id="install">Install</h2><p>Install text</p><pre><code>go install</code></pre>
+<h3>From source</h3><p>Source text</p>
+<h2 id="usage">Usage</h2><p>Usage text</p><pre><code>snag url</code></pre>
+</body></html>`))
+	})
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	stdout, _, err := runSnag("--fragment-only", server.URL+"/#install")
+	assertNoError(t, err)
+	assertContains(t, stdout, "## Install")
+	assertContains(t, stdout, "Source text")
+	if strings.Contains(stdout, "Intro text") || strings.Contains(stdout, "Usage text") {
+		t.Errorf("expected only the install section, got:\n%s", stdout)
+	}
+
+	stdout, _, err = runSnag("--fragment-only", "--select", "pre", server.URL+"/#usage")
+	assertNoError(t, err)
+	assertContains(t, stdout, "snag url")
+	if strings.Contains(stdout, "go install") {
+		t.Errorf("expected --select to be limited to the usage section, got:\n%s", stdout)
+	}
+
+	_, stderr, err := runSnag("--fragment-only", server.URL+"/#missing")
+	assertError(t, err)
+	assertContains(t, stderr, "No element matches #missing")
+}
+
+func TestCLI_FragmentOnlyConflicts(t *testing.T) {
+	_, stderr, err := runSnag("--fragment-only", "-f", "pdf", "--pdf-selector", "main", "https://example.com/#intro")
+	assertError(t, err)
+	assertContains(t, stderr, "Cannot use --fragment-only with --pdf-selector")
+}
+
+func TestCLI_NoActivateRefusesCapture(t *testing.T) {
+	for _, format := range []string{"png", "pdf"} {
+		_, stderr, err := runSnag("--tab", "1", "--no-activate", "-f", format)
+		assertError(t, err)
+		assertContains(t, stderr, "--no-activate cannot be used with --format "+format)
+	}
+}
+
+func TestCLI_WatchRequiresTab(t *testing.T) {
+	_, stderr, err := runSnag("--watch", "https://example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "--watch requires --tab")
+}
+
+func TestCLI_WatchInterval(t *testing.T) {
+	_, stderr, err := runSnag("--tab", "dashboard", "--watch", "--interval", "100ms")
+	assertError(t, err)
+	assertContains(t, stderr, "Invalid --interval: 100ms")
+
+	_, stderr, err = runSnag("--tab", "dashboard", "--watch", "-o", "out.md")
+	assertError(t, err)
+	assertContains(t, stderr, "Cannot use --output with --watch")
+
+	_, stderr, err = runSnag("--tab", "dashboard", "--watch", "--output-tar", "out.tar")
+	assertError(t, err)
+	assertContains(t, stderr, "Cannot use --mirror-paths or --output-tar with --watch")
+}
+
+func TestCLI_EngineWebKitUnsupportedFlags(t *testing.T) {
+	_, stderr, err := runSnag("--engine", "webkit", "--click", "#more", "https://example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "--engine webkit does not support --click")
+
+	_, stderr, err = runSnag("--engine", "webkit", "-f", "pdf", "https://example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "--engine webkit cannot produce --format pdf")
+
+	_, stderr, err = runSnag("--engine", "gecko", "https://example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "Invalid --engine: gecko")
+}
+
+func TestCLI_EngineHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprintf(w, "<html><head><title>Plain</title></head><body><h1>Hello</h1><p>%s</p></body></html>", r.Header.Get("X-Test"))
+	}))
+	defer server.Close()
+
+	stdout, _, err := runSnag("--engine", "http", "--header", "X-Test: from-header", server.URL)
+	assertNoError(t, err)
+	assertContains(t, stdout, "# Hello")
+	assertContains(t, stdout, "from-header")
+
+	_, stderr, err := runSnag("--engine", "http", "--wait-for", "#app", server.URL)
+	assertError(t, err)
+	assertContains(t, stderr, "--engine http does not support --wait-for")
+
+	_, stderr, err = runSnag("--engine", "http", "-f", "png", server.URL)
+	assertError(t, err)
+	assertContains(t, stderr, "--engine http cannot produce --format png")
+
+	output := filepath.Join(t.TempDir(), "x.md")
+	_, stderr, err = runSnag("--engine", "http", "--log-format", "json", "-o", output, server.URL)
+	assertNoError(t, err)
+	assertContains(t, stderr, `"url":"`+server.URL)
+	assertContains(t, stderr, `"output":"`)
+	content, err := os.ReadFile(output)
+	assertNoError(t, err)
+	assertContains(t, string(content), "# Hello")
+}
+
+func TestCLI_LogFormatJSON(t *testing.T) {
+	_, stderr, err := runSnag("--log-format", "json", "--format", "doc", "https://example.com")
+	assertError(t, err)
+	assertContains(t, stderr, `"level":"error"`)
+	assertContains(t, stderr, `"message":"Invalid format 'doc'`)
+
+	_, stderr, err = runSnag("--log-format", "xml", "https://example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "Invalid --log-format: xml")
+}
+
+func TestCLI_BlockUnknownType(t *testing.T) {
+	_, stderr, err := runSnag("--block", "images,scripts", "https://example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "unknown resource type: scripts")
+}
+
+// TestBrowser_Eval tests --eval-expr changing the page and returning JSON
+func TestBrowser_Eval(t *testing.T) {
+	if !isBrowserAvailable() {
+		t.Skip("Browser not available, skipping browser integration test")
+	}
+
+	server := startTestServer(t)
+	url := server.URL + "/simple.html"
+
+	stdout, _, err := runSnag("--eval-expr", "document.querySelector('h1').textContent = 'Changed Heading'", url)
+	assertNoError(t, err)
+	assertContains(t, stdout, "# Changed Heading")
+
+	stdout, _, err = runSnag("--eval-expr", "({title: document.title, links: document.links.length})", "-f", "json", url)
+	assertNoError(t, err)
+	assertContains(t, stdout, `"title": "Simple Test Page"`)
+	assertContains(t, stdout, `"links": 1`)
+}
+
+func TestCLI_FormatJSONRequiresEval(t *testing.T) {
+	_, stderr, err := runSnag("-f", "json", "https://example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "--format json requires --eval or --eval-expr")
+}
+
+func TestCLI_IncludeSourceRequiresTextFormat(t *testing.T) {
+	_, stderr, err := runSnag("--include-source", "-f", "pdf", "https://example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "Cannot use --include-source with --format pdf")
+}
+
 // TestBrowser_DefaultTimeout tests that default timeout works
 func TestBrowser_DefaultTimeout(t *testing.T) {
 	if !isBrowserAvailable() {
@@ -1914,3 +2237,59 @@ func min(a, b int) int {
 	}
 	return b
 }
+
+// TestCLI_TemplateWithPDF tests that --template rejects binary formats
+func TestCLI_TemplateWithPDF(t *testing.T) {
+	stdout, stderr, err := runSnag("--template", "page.tmpl", "-f", "pdf", "https://example.com")
+
+	assertError(t, err)
+	assertContains(t, stdout+stderr, "--template")
+}
+
+func TestCLI_InvalidHeader(t *testing.T) {
+	_, stderr, err := runSnag("--header", "Authorization Bearer abc", "example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "Invalid --header")
+}
+
+func TestCLI_ReadOnlyRefusesSideFiles(t *testing.T) {
+	_, stderr, err := runSnag("--read-only", "--save-cookies", "cookies.json", "example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "Cannot use --save-cookies with --read-only")
+
+	assets := filepath.Join(t.TempDir(), "assets")
+	_, stderr, err = runSnag("--read-only", "--assets-dir", assets, "example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "Cannot use --assets-dir with --read-only")
+	if _, err := os.Stat(assets); !os.IsNotExist(err) {
+		t.Errorf("expected %s not to be created, got %v", assets, err)
+	}
+}
+
+// TestBrowser_ReadOnly checks that a launched browser leaves nothing in the
+// temp directory and only the output file is written
+func TestBrowser_ReadOnly(t *testing.T) {
+	if !isBrowserAvailable() {
+		t.Skip("Browser not available, skipping browser integration test")
+	}
+
+	server := startTestServer(t)
+	tmp := t.TempDir()
+	out := filepath.Join(t.TempDir(), "page.md")
+
+	cmd := exec.Command("./snag", "--read-only", "--force-headless", "-o", out, server.URL+"/simple.html")
+	cmd.Env = append(os.Environ(), "TMPDIR="+tmp)
+	_, stderr, err := runCommand(cmd)
+	if err != nil {
+		t.Fatalf("snag failed: %v\n%s", err, stderr)
+	}
+
+	if _, err := os.Stat(out); err != nil {
+		t.Errorf("output not written: %v", err)
+	}
+	entries, err := os.ReadDir(tmp)
+	assertNoError(t, err)
+	for _, entry := range entries {
+		t.Errorf("left behind in temp directory: %s", entry.Name())
+	}
+}
@@ -0,0 +1,284 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/net/html"
+)
+
+var baseURL string
+
+const convertHelpTemplate = `USAGE:
+  snag convert [options] [FILE | -]
+
+DESCRIPTION:
+  Convert HTML from a file or stdin without a browser. With --base-url,
+  relative links and image sources are resolved against the page's original
+  address, as if it had been fetched from there.
+
+EXAMPLES:
+  curl -s https://example.com | snag convert - --base-url https://example.com
+  snag convert page.html -o page.md
+  snag convert saved.html -f text --base-url https://example.com/docs/
+  snag convert article.html --readability -o article.md
+
+OPTIONS:
+      --base-url string        Resolve relative URLs against this address
+  -f, --format string          Output format: md | html | text | reader | org | rst | ipynb (default "md")
+  -o, --output string          Save output to file instead of stdout
+      --readability            Keep only the main article, dropping navigation, sidebars, and ads
+      --sanitize               With --format html, strip scripts, event handlers, and trackers
+      --html-pretty            With --format html, indent one block element per line
+      --html-minify            With --format html, drop comments and unneeded whitespace
+      --eol string             Line endings: lf | crlf (default: as converted)
+      --bom                    Start output with a UTF-8 byte order mark
+      --split-by string        With -o, write one Markdown file per heading: h1 to h6
+      --max-tokens int         Limit output to an estimated token count (0 = unlimited)
+      --token-overflow string  Over --max-tokens: truncate | split (with -o) (default truncate)
+
+  -q, --quiet                  Suppress all output except errors and content
+      --verbose                Enable verbose logging output
+      --debug                  Enable debug output
+  -h, --help                   help for convert
+`
+
+var convertCmd = &cobra.Command{
+	Use:          "convert [FILE | -]",
+	Short:        "Convert HTML from a file or stdin to Markdown or text",
+	Args:         cobra.MaximumNArgs(1),
+	RunE:         runConvert,
+	SilenceUsage: true,
+}
+
+func init() {
+	convertCmd.Flags().StringVar(&baseURL, "base-url", "", "Resolve relative URLs against this address")
+	convertCmd.Flags().StringVarP(&format, "format", "f", FormatMarkdown, "Output format: md | html | text | reader | org | rst | ipynb")
+	convertCmd.Flags().StringVarP(&output, "output", "o", "", "Save output to file instead of stdout")
+	convertCmd.Flags().BoolVar(&readability, "readability", false, "Keep only the main article, dropping navigation, sidebars, and ads")
+	convertCmd.Flags().BoolVar(&sanitize, "sanitize", false, "With --format html, strip scripts, event handlers, and trackers")
+	convertCmd.Flags().BoolVar(&htmlPretty, "html-pretty", false, "With --format html, indent one block element per line")
+	convertCmd.Flags().BoolVar(&htmlMinify, "html-minify", false, "With --format html, drop comments and unneeded whitespace")
+	convertCmd.Flags().StringVar(&eol, "eol", "", "Line endings: lf | crlf")
+	convertCmd.Flags().BoolVar(&bom, "bom", false, "Start output with a UTF-8 byte order mark")
+	convertCmd.Flags().StringVar(&splitBy, "split-by", "", "With -o, write one Markdown file per heading: h1 to h6")
+	convertCmd.Flags().IntVar(&maxTokens, "max-tokens", 0, "Limit output to an estimated token count (0 = unlimited)")
+	convertCmd.Flags().StringVar(&tokenOverflow, "token-overflow", TokenOverflowTruncate, "Over --max-tokens: truncate | split (with -o)")
+	convertCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Suppress all output except errors and content")
+	convertCmd.Flags().BoolVar(&verbose, "verbose", false, "Enable verbose logging output")
+	convertCmd.Flags().BoolVar(&debug, "debug", false, "Enable debug output")
+
+	convertCmd.MarkFlagsMutuallyExclusive("quiet", "verbose", "debug")
+	convertCmd.MarkFlagsMutuallyExclusive("html-pretty", "html-minify")
+	convertCmd.SetHelpTemplate(convertHelpTemplate)
+
+	rootCmd.AddCommand(convertCmd)
+}
+
+func runConvert(cmd *cobra.Command, args []string) error {
+	level := LevelNormal
+	if debug {
+		level = LevelDebug
+	} else if verbose {
+		level = LevelVerbose
+	} else if quiet {
+		level = LevelQuiet
+	}
+	logger = NewLogger(level)
+
+	source := "-"
+	if len(args) == 1 {
+		source = strings.TrimSpace(args[0])
+	}
+
+	outputFormat := normalizeFormat(format)
+	if err := validateFormat(outputFormat); err != nil {
+		return err
+	}
+	if outputFormat == FormatPDF || outputFormat == FormatPNG || outputFormat == FormatJSON {
+		logger.Error("Cannot convert HTML to %s without a browser", outputFormat)
+		logger.ErrorWithSuggestion(
+			"Use md, html, text, reader, org, rst, or ipynb with snag convert, or fetch the page directly",
+			"snag -f "+outputFormat+" file:///path/to/page.html",
+		)
+		return fmt.Errorf("unsupported convert format: %s", outputFormat)
+	}
+
+	if err := validateEOL(eol); err != nil {
+		return err
+	}
+
+	outputFile := strings.TrimSpace(output)
+
+	if splitBy != "" && (splitHeadingLevel(splitBy) == 0 || outputFormat != FormatMarkdown || outputFile == "") {
+		logger.Error("Invalid --split-by: %s", splitBy)
+		logger.ErrorWithSuggestion(
+			"--split-by needs a heading level from h1 to h6, Markdown output, and --output",
+			"snag convert page.html --split-by h2 -o page.md",
+		)
+		return fmt.Errorf("invalid split-by: %s", splitBy)
+	}
+
+	if err := validateTokenBudget(maxTokens, tokenOverflow); err != nil {
+		return err
+	}
+	if maxTokens > 0 && normalizeTokenOverflow(tokenOverflow) == TokenOverflowSplit && (outputFile == "" || splitBy != "") {
+		logger.Error("--token-overflow split needs --output and cannot be used with --split-by")
+		logger.ErrorWithSuggestion(
+			"Numbered files are named after the output file",
+			"snag convert page.html --max-tokens 8000 --token-overflow split -o page.md",
+		)
+		return fmt.Errorf("invalid token-overflow: %s", tokenOverflow)
+	}
+
+	base := strings.TrimSpace(baseURL)
+	if base != "" {
+		if err := validateBaseURL(base); err != nil {
+			return err
+		}
+	}
+
+	if outputFile != "" {
+		if err := validateOutputPath(outputFile); err != nil {
+			return err
+		}
+		checkExtensionMismatch(outputFile, outputFormat)
+	}
+
+	content, err := readConvertSource(source)
+	if err != nil {
+		return err
+	}
+
+	if base != "" {
+		logger.Verbose("Resolving relative URLs against %s", base)
+		content, err = resolveRelativeURLs(content, base)
+		if err != nil {
+			logger.Error("Failed to parse HTML: %v", err)
+			return err
+		}
+	}
+
+	converter := NewContentConverter(outputFormat)
+	converter.eol = normalizeEOL(eol)
+	converter.bom = bom
+	converter.splitLevel = splitHeadingLevel(splitBy)
+	converter.maxTokens = maxTokens
+	converter.tokenOverflow = normalizeTokenOverflow(tokenOverflow)
+	converter.mainContent = readability
+	converter.sanitize = sanitize
+	converter.htmlPretty = htmlPretty
+	converter.htmlMinify = htmlMinify
+	return converter.Process(content, outputFile)
+}
+
+func readConvertSource(source string) (string, error) {
+	var data []byte
+	var err error
+
+	if source == "-" {
+		logger.Verbose("Reading HTML from stdin...")
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		logger.Verbose("Reading HTML from %s...", source)
+		data, err = os.ReadFile(source)
+	}
+	if err != nil {
+		logger.Error("Failed to read HTML: %s", source)
+		return "", fmt.Errorf("failed to read %s: %w", source, err)
+	}
+
+	logger.Debug("Read %d bytes of HTML", len(data))
+	return string(data), nil
+}
+
+// urlAttributes lists the attributes holding a single URL.
+var urlAttributes = map[string]bool{
+	"href":       true,
+	"src":        true,
+	"action":     true,
+	"poster":     true,
+	"cite":       true,
+	"data":       true,
+	"formaction": true,
+}
+
+// resolveRelativeURLs rewrites relative URLs in htmlContent to absolute
+// ones. A <base href> in the document is honoured, resolved against base.
+// In-page fragment links are left as they are.
+func resolveRelativeURLs(htmlContent, base string) (string, error) {
+	resolveBase, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid base URL %s: %w", base, err)
+	}
+
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return "", err
+	}
+
+	for n := range doc.Descendants() {
+		if n.Type == html.ElementNode && n.Data == "base" {
+			for _, attr := range n.Attr {
+				if attr.Key == "href" {
+					if docBase, err := resolveBase.Parse(attr.Val); err == nil {
+						resolveBase = docBase
+					}
+				}
+			}
+			break
+		}
+	}
+
+	resolve := func(ref string) string {
+		ref = strings.TrimSpace(ref)
+		if ref == "" || strings.HasPrefix(ref, "#") {
+			return ref
+		}
+		resolved, err := resolveBase.Parse(ref)
+		if err != nil {
+			return ref
+		}
+		return resolved.String()
+	}
+
+	for n := range doc.Descendants() {
+		if n.Type != html.ElementNode || n.Data == "base" {
+			continue
+		}
+		for i, attr := range n.Attr {
+			switch {
+			case urlAttributes[attr.Key]:
+				n.Attr[i].Val = resolve(attr.Val)
+			case attr.Key == "srcset":
+				candidates := strings.Split(attr.Val, ",")
+				for j, candidate := range candidates {
+					fields := strings.Fields(candidate)
+					if len(fields) == 0 {
+						continue
+					}
+					fields[0] = resolve(fields[0])
+					candidates[j] = strings.Join(fields, " ")
+				}
+				n.Attr[i].Val = strings.Join(candidates, ", ")
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, doc); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveRelativeURLs(t *testing.T) {
+	input := `<html><body>
+<a href="/about">About</a>
+<a href="guide.html">Guide</a>
+<a href="#top">Top</a>
+<a href="https://other.example/x">Other</a>
+<img src="img/logo.png" srcset="img/a.png 1x, /img/b.png 2x">
+</body></html>`
+
+	got, err := resolveRelativeURLs(input, "https://example.com/docs/")
+	if err != nil {
+		t.Fatalf("resolveRelativeURLs failed: %v", err)
+	}
+
+	for _, want := range []string{
+		`href="https://example.com/about"`,
+		`href="https://example.com/docs/guide.html"`,
+		`href="#top"`,
+		`href="https://other.example/x"`,
+		`src="https://example.com/docs/img/logo.png"`,
+		`srcset="https://example.com/docs/img/a.png 1x, https://example.com/img/b.png 2x"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %s in:\n%s", want, got)
+		}
+	}
+}
+
+func TestResolveRelativeURLs_BaseElement(t *testing.T) {
+	input := `<html><head><base href="/v2/"></head><body><a href="intro">Intro</a></body></html>`
+
+	got, err := resolveRelativeURLs(input, "https://example.com/docs/")
+	if err != nil {
+		t.Fatalf("resolveRelativeURLs failed: %v", err)
+	}
+	if !strings.Contains(got, `href="https://example.com/v2/intro"`) {
+		t.Errorf("expected link resolved against <base>, got:\n%s", got)
+	}
+}
+
+func TestCLI_ConvertFile(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "page.html")
+	os.WriteFile(input, []byte(`<h1>Docs</h1><p><a href="setup">Setup</a></p>`), 0644)
+
+	stdout, _, err := runSnag("convert", input, "--base-url", "https://example.com/docs/")
+	if err != nil {
+		t.Fatalf("convert failed: %v", err)
+	}
+	assertContains(t, stdout, "# Docs")
+	assertContains(t, stdout, "[Setup](https://example.com/docs/setup)")
+}
+
+func TestCLI_ConvertInvalidBaseURL(t *testing.T) {
+	_, stderr, err := runSnag("convert", "-", "--base-url", "/relative")
+	assertError(t, err)
+	assertContains(t, stderr, "Invalid base URL")
+}
+
+func TestCLI_ConvertPDF(t *testing.T) {
+	_, stderr, err := runSnag("convert", "-", "-f", "pdf")
+	assertError(t, err)
+	assertContains(t, stderr, "without a browser")
+}
+
+func TestCLI_ConvertCRLF(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "page.html")
+	os.WriteFile(input, []byte("<p>one</p><p>two</p>"), 0644)
+	outFile := filepath.Join(dir, "page.md")
+
+	if _, _, err := runSnag("convert", input, "--eol", "crlf", "--bom", "-o", outFile); err != nil {
+		t.Fatalf("convert failed: %v", err)
+	}
+
+	data, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if got := string(data); got != "\ufeffone\r\n\r\ntwo" {
+		t.Errorf("unexpected output %q", got)
+	}
+}
+
+func TestCLI_InvalidEOL(t *testing.T) {
+	_, stderr, err := runSnag("--eol", "cr", "example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "Invalid --eol")
+}
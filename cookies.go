@@ -0,0 +1,264 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// CookieFileMode is the permission of files written by --save-cookies, which
+// hold session credentials.
+const CookieFileMode = 0600
+
+// netscapeHTTPOnly prefixes the domain of HttpOnly cookies in Netscape
+// cookie files, as written by curl and browser extensions.
+const netscapeHTTPOnly = "#HttpOnly_"
+
+// importedCookies are the cookies read from --cookies-file, set in each
+// browser before its first navigation.
+var importedCookies []*proto.NetworkCookieParam
+
+// cookiesApplied records the browsers importedCookies have been set in, so
+// cookies the site refreshes during a batch are not overwritten.
+var cookiesApplied sync.Map
+
+// cookieRecord is one cookie in a JSON cookie file. It reads the CDP and
+// Puppeteer layout and the expirationDate of browser extension exports.
+type cookieRecord struct {
+	Name           string  `json:"name"`
+	Value          string  `json:"value"`
+	Domain         string  `json:"domain"`
+	Path           string  `json:"path"`
+	Expires        float64 `json:"expires,omitempty"`
+	ExpirationDate float64 `json:"expirationDate,omitempty"`
+	HTTPOnly       bool    `json:"httpOnly"`
+	Secure         bool    `json:"secure"`
+	SameSite       string  `json:"sameSite,omitempty"`
+}
+
+// loadCookies reads a cookie file in JSON or Netscape cookies.txt format.
+// JSON may be an array of cookies or an object with a "cookies" array, as
+// in Playwright storage state.
+func loadCookies(path string) ([]*proto.NetworkCookieParam, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{') {
+		return parseJSONCookies(trimmed)
+	}
+	return parseNetscapeCookies(data)
+}
+
+func parseJSONCookies(data []byte) ([]*proto.NetworkCookieParam, error) {
+	var records []cookieRecord
+	if data[0] == '{' {
+		var state struct {
+			Cookies []cookieRecord `json:"cookies"`
+		}
+		if err := json.Unmarshal(data, &state); err != nil {
+			return nil, err
+		}
+		records = state.Cookies
+	} else if err := json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+
+	cookies := make([]*proto.NetworkCookieParam, 0, len(records))
+	for i, r := range records {
+		if r.Name == "" || r.Domain == "" {
+			return nil, fmt.Errorf("cookie %d: name and domain are required", i+1)
+		}
+		expires := r.Expires
+		if expires == 0 {
+			expires = r.ExpirationDate
+		}
+		cookies = append(cookies, newCookieParam(r.Name, r.Value, r.Domain, r.Path, expires, r.HTTPOnly, r.Secure, r.SameSite))
+	}
+	return cookies, nil
+}
+
+func parseNetscapeCookies(data []byte) ([]*proto.NetworkCookieParam, error) {
+	var cookies []*proto.NetworkCookieParam
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		httpOnly := false
+		if strings.HasPrefix(line, netscapeHTTPOnly) {
+			line = strings.TrimPrefix(line, netscapeHTTPOnly)
+			httpOnly = true
+		} else if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// domain, include subdomains, path, secure, expiry, name, value
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("line %d: expected 7 tab-separated fields, got %d", lineNum, len(fields))
+		}
+		expires, err := strconv.ParseFloat(fields[4], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid expiry: %s", lineNum, fields[4])
+		}
+		secure := strings.EqualFold(fields[3], "TRUE")
+		cookies = append(cookies, newCookieParam(fields[5], fields[6], fields[0], fields[2], expires, httpOnly, secure, ""))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return cookies, nil
+}
+
+func newCookieParam(name, value, domain, path string, expires float64, httpOnly, secure bool, sameSite string) *proto.NetworkCookieParam {
+	if path == "" {
+		path = "/"
+	}
+	cookie := &proto.NetworkCookieParam{
+		Name:     name,
+		Value:    value,
+		Domain:   domain,
+		Path:     path,
+		HTTPOnly: httpOnly,
+		Secure:   secure,
+	}
+	// Zero and negative expiries mark session cookies
+	if expires > 0 {
+		cookie.Expires = proto.TimeSinceEpoch(expires)
+	}
+	switch strings.ToLower(sameSite) {
+	case "strict":
+		cookie.SameSite = proto.NetworkCookieSameSiteStrict
+	case "lax":
+		cookie.SameSite = proto.NetworkCookieSameSiteLax
+	case "none", "no_restriction":
+		cookie.SameSite = proto.NetworkCookieSameSiteNone
+	}
+	return cookie
+}
+
+// applyCookies sets the --cookies-file cookies in page's browser, once per
+// browser.
+func applyCookies(page *rod.Page) error {
+	if len(importedCookies) == 0 {
+		return nil
+	}
+	if _, done := cookiesApplied.LoadOrStore(page.Browser(), true); done {
+		return nil
+	}
+	logger.Verbose("Setting %d cookie%s from --cookies-file", len(importedCookies), plural(len(importedCookies)))
+	if err := page.SetCookies(importedCookies); err != nil {
+		cookiesApplied.Delete(page.Browser())
+		return fmt.Errorf("failed to set cookies: %w", err)
+	}
+	return nil
+}
+
+// saveCookies writes every cookie in page's browser to path: in Netscape
+// format for .txt files and JSON otherwise. The file is replaced atomically
+// so a failed run never leaves it half written.
+func saveCookies(page *rod.Page, path string) error {
+	cookies, err := page.Browser().GetCookies()
+	if err != nil {
+		return fmt.Errorf("failed to read cookies: %w", err)
+	}
+
+	var data []byte
+	if strings.EqualFold(filepath.Ext(path), ".txt") {
+		data = formatNetscapeCookies(cookies)
+	} else {
+		data, err = formatJSONCookies(cookies)
+		if err != nil {
+			return err
+		}
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, CookieFileMode); err != nil {
+		return fmt.Errorf("failed to save cookies: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to save cookies: %w", err)
+	}
+	logger.Verbose("Saved %d cookie%s to %s", len(cookies), plural(len(cookies)), path)
+	return nil
+}
+
+func formatJSONCookies(cookies []*proto.NetworkCookie) ([]byte, error) {
+	records := make([]cookieRecord, 0, len(cookies))
+	for _, c := range cookies {
+		r := cookieRecord{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			HTTPOnly: c.HTTPOnly,
+			Secure:   c.Secure,
+			SameSite: string(c.SameSite),
+		}
+		if !c.Session {
+			r.Expires = float64(c.Expires)
+		}
+		records = append(records, r)
+	}
+
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode cookies: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+func formatNetscapeCookies(cookies []*proto.NetworkCookie) []byte {
+	var b strings.Builder
+	b.WriteString("# Netscape HTTP Cookie File\n")
+	b.WriteString("# Written by snag. Holds session credentials, keep it private.\n\n")
+
+	for _, c := range cookies {
+		domain := c.Domain
+		if c.HTTPOnly {
+			domain = netscapeHTTPOnly + domain
+		}
+		expires := int64(0)
+		if !c.Session {
+			expires = int64(c.Expires)
+		}
+		fmt.Fprintf(&b, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			domain,
+			netscapeBool(strings.HasPrefix(c.Domain, ".")),
+			c.Path,
+			netscapeBool(c.Secure),
+			expires,
+			c.Name,
+			c.Value,
+		)
+	}
+	return []byte(b.String())
+}
+
+func netscapeBool(v bool) string {
+	if v {
+		return "TRUE"
+	}
+	return "FALSE"
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+func writeCookieFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadCookies_JSON(t *testing.T) {
+	path := writeCookieFile(t, "cookies.json", `[
+  {"name": "sid", "value": "abc", "domain": ".example.com", "path": "/", "expires": 1893456000, "httpOnly": true, "secure": true, "sameSite": "Lax"},
+  {"name": "theme", "value": "dark", "domain": "example.com", "expirationDate": 1893456000.5, "sameSite": "no_restriction"},
+  {"name": "tmp", "value": "1", "domain": "example.com", "expires": -1}
+]`)
+
+	cookies, err := loadCookies(path)
+	if err != nil {
+		t.Fatalf("loadCookies failed: %v", err)
+	}
+	if len(cookies) != 3 {
+		t.Fatalf("got %d cookies, expected 3", len(cookies))
+	}
+
+	sid := cookies[0]
+	if sid.Name != "sid" || sid.Domain != ".example.com" || !sid.HTTPOnly || !sid.Secure || sid.SameSite != proto.NetworkCookieSameSiteLax || sid.Expires != 1893456000 {
+		t.Errorf("sid = %+v", sid)
+	}
+	if theme := cookies[1]; theme.Path != "/" || theme.Expires != 1893456000.5 || theme.SameSite != proto.NetworkCookieSameSiteNone {
+		t.Errorf("theme = %+v", theme)
+	}
+	if tmp := cookies[2]; tmp.Expires != 0 {
+		t.Errorf("session cookie expires = %v, want 0", tmp.Expires)
+	}
+}
+
+func TestLoadCookies_StorageState(t *testing.T) {
+	path := writeCookieFile(t, "state.json", `{"cookies": [{"name": "sid", "value": "abc", "domain": "example.com", "path": "/app"}], "origins": []}`)
+
+	cookies, err := loadCookies(path)
+	if err != nil {
+		t.Fatalf("loadCookies failed: %v", err)
+	}
+	if len(cookies) != 1 || cookies[0].Path != "/app" {
+		t.Errorf("cookies = %+v", cookies)
+	}
+}
+
+func TestLoadCookies_Netscape(t *testing.T) {
+	path := writeCookieFile(t, "cookies.txt", "# Netscape HTTP Cookie File\n\n"+
+		".example.com\tTRUE\t/\tTRUE\t1893456000\tsid\tabc\n"+
+		"#HttpOnly_example.com\tFALSE\t/app\tFALSE\t0\ttoken\tx=y\n")
+
+	cookies, err := loadCookies(path)
+	if err != nil {
+		t.Fatalf("loadCookies failed: %v", err)
+	}
+	if len(cookies) != 2 {
+		t.Fatalf("got %d cookies, expected 2", len(cookies))
+	}
+	if c := cookies[0]; c.Domain != ".example.com" || !c.Secure || c.HTTPOnly || c.Expires != 1893456000 {
+		t.Errorf("sid = %+v", c)
+	}
+	if c := cookies[1]; c.Domain != "example.com" || !c.HTTPOnly || c.Path != "/app" || c.Value != "x=y" || c.Expires != 0 {
+		t.Errorf("token = %+v", c)
+	}
+}
+
+func TestLoadCookies_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"short.txt":     "example.com\tTRUE\t/\n",
+		"expiry.txt":    "example.com\tTRUE\t/\tFALSE\tsoon\tsid\tabc\n",
+		"nodomain.json": `[{"name": "sid", "value": "abc"}]`,
+		"broken.json":   `[{"name": `,
+	}
+
+	for name, content := range tests {
+		if _, err := loadCookies(writeCookieFile(t, name, content)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestFormatCookies_RoundTrip(t *testing.T) {
+	cookies := []*proto.NetworkCookie{
+		{Name: "sid", Value: "abc", Domain: ".example.com", Path: "/", Expires: 1893456000, HTTPOnly: true, Secure: true, SameSite: proto.NetworkCookieSameSiteStrict},
+		{Name: "tmp", Value: "1", Domain: "example.com", Path: "/", Expires: -1, Session: true},
+	}
+
+	netscape := string(formatNetscapeCookies(cookies))
+	if !strings.Contains(netscape, "#HttpOnly_.example.com\tTRUE\t/\tTRUE\t1893456000\tsid\tabc\n") {
+		t.Errorf("netscape output:\n%s", netscape)
+	}
+	if !strings.Contains(netscape, "example.com\tFALSE\t/\tFALSE\t0\ttmp\t1\n") {
+		t.Errorf("netscape output:\n%s", netscape)
+	}
+
+	data, err := formatJSONCookies(cookies)
+	if err != nil {
+		t.Fatalf("formatJSONCookies failed: %v", err)
+	}
+
+	for name, content := range map[string]string{"cookies.txt": netscape, "cookies.json": string(data)} {
+		loaded, err := loadCookies(writeCookieFile(t, name, content))
+		if err != nil {
+			t.Fatalf("%s: loadCookies failed: %v", name, err)
+		}
+		if len(loaded) != 2 || loaded[0].Name != "sid" || !loaded[0].HTTPOnly || loaded[0].Expires != 1893456000 || loaded[1].Expires != 0 {
+			t.Errorf("%s: round trip = %+v, %+v", name, loaded[0], loaded[1])
+		}
+	}
+}
+
+func TestCLI_CookiesFileMissing(t *testing.T) {
+	_, stderr, err := runSnag("--cookies-file", "/nonexistent/cookies.json", "example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "Failed to read --cookies-file")
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+// DefaultCrawlDepth is how many links --crawl follows from the start pages.
+const DefaultCrawlDepth = 1
+
+// crawlSkipExtensions are link targets that are not pages, so --crawl does
+// not follow them.
+var crawlSkipExtensions = map[string]bool{
+	".7z": true, ".avi": true, ".bz2": true, ".css": true, ".csv": true,
+	".dmg": true, ".doc": true, ".docx": true, ".epub": true, ".exe": true,
+	".gif": true, ".gz": true, ".ico": true, ".iso": true, ".jpeg": true,
+	".jpg": true, ".js": true, ".json": true, ".mov": true, ".mp3": true,
+	".mp4": true, ".msi": true, ".pdf": true, ".png": true, ".ppt": true,
+	".pptx": true, ".rar": true, ".rss": true, ".svg": true, ".tar": true,
+	".tgz": true, ".wasm": true, ".webm": true, ".webp": true, ".woff": true,
+	".woff2": true, ".xls": true, ".xlsx": true, ".xml": true, ".zip": true,
+}
+
+// Crawler decides which links of fetched pages --crawl follows. Pages are
+// followed up to maxDepth links away from the start pages, on the hosts of
+// the start pages or the --allow-domain domains, and each URL only once.
+type Crawler struct {
+	maxDepth int
+	hosts    map[string]bool
+	domains  []string
+	depths   map[string]int
+}
+
+func NewCrawler(seeds []string, maxDepth int, allowDomains []string) *Crawler {
+	c := &Crawler{
+		maxDepth: maxDepth,
+		hosts:    make(map[string]bool),
+		depths:   make(map[string]int),
+	}
+	for _, d := range allowDomains {
+		d = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(d)), ".")
+		if d != "" {
+			c.domains = append(c.domains, d)
+		}
+	}
+	for _, seed := range seeds {
+		key, u, ok := crawlKey(seed)
+		if !ok {
+			continue
+		}
+		c.hosts[u.Hostname()] = true
+		c.depths[key] = 0
+	}
+	return c
+}
+
+// Follow returns the links found on the page at pageURL that have not been
+// seen yet and are in scope, or nil when the page is already at the
+// maximum depth.
+func (c *Crawler) Follow(pageURL string, links []string) []string {
+	from, _, _ := crawlKey(pageURL)
+	depth := c.depths[from]
+	if depth >= c.maxDepth {
+		return nil
+	}
+
+	var next []string
+	for _, link := range links {
+		key, u, ok := crawlKey(link)
+		if !ok || !c.inScope(u) {
+			continue
+		}
+		if _, seen := c.depths[key]; seen {
+			continue
+		}
+		c.depths[key] = depth + 1
+		next = append(next, key)
+	}
+	return next
+}
+
+// Seen marks finalURL, where a redirect from pageURL ended, as fetched so
+// links to it are not followed again.
+func (c *Crawler) Seen(pageURL, finalURL string) {
+	key, _, ok := crawlKey(finalURL)
+	if !ok {
+		return
+	}
+	if _, seen := c.depths[key]; !seen {
+		from, _, _ := crawlKey(pageURL)
+		c.depths[key] = c.depths[from]
+	}
+}
+
+func (c *Crawler) inScope(u *url.URL) bool {
+	host := u.Hostname()
+	if c.hosts[host] {
+		return true
+	}
+	for _, d := range c.domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// crawlKey normalizes a link for --crawl: fragments are dropped, so
+// anchors within a page do not count as new pages. ok is false for links
+// that are not http(s) pages.
+func crawlKey(raw string) (string, *url.URL, bool) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", nil, false
+	}
+	if crawlSkipExtensions[strings.ToLower(path.Ext(u.Path))] {
+		return "", nil, false
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.Host = strings.ToLower(u.Host)
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	return u.String(), u, true
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestCrawler(t *testing.T) {
+	c := NewCrawler([]string{"https://docs.example.com/start"}, 2, []string{"example.org"})
+
+	next := c.Follow("https://docs.example.com/start", []string{
+		"https://docs.example.com/a",
+		"https://docs.example.com/a#section",
+		"https://DOCS.example.com/b",
+		"https://docs.example.com/start",
+		"https://docs.example.com/guide.pdf",
+		"https://api.example.org/ref",
+		"https://example.org",
+		"https://example.com/",
+		"https://github.com/org/repo",
+		"mailto:docs@example.com",
+	})
+	want := []string{
+		"https://docs.example.com/a",
+		"https://docs.example.com/b",
+		"https://api.example.org/ref",
+		"https://example.org/",
+	}
+	if !slices.Equal(next, want) {
+		t.Errorf("Follow(start) = %v, want %v", next, want)
+	}
+
+	// Depth 1 pages are followed once more, depth 2 pages are not
+	next = c.Follow("https://docs.example.com/a", []string{"https://docs.example.com/b", "https://docs.example.com/c"})
+	if !slices.Equal(next, []string{"https://docs.example.com/c"}) {
+		t.Errorf("Follow(a) = %v, want only /c", next)
+	}
+	if next := c.Follow("https://docs.example.com/c", []string{"https://docs.example.com/d"}); next != nil {
+		t.Errorf("Follow(c) at max depth = %v, want nil", next)
+	}
+}
+
+func TestCrawlerSeenRedirect(t *testing.T) {
+	c := NewCrawler([]string{"http://example.com/old"}, 1, nil)
+	c.Seen("http://example.com/old", "http://example.com/new")
+
+	next := c.Follow("http://example.com/old", []string{"http://example.com/new", "http://example.com/other"})
+	if !slices.Equal(next, []string{"http://example.com/other"}) {
+		t.Errorf("Follow = %v, want the redirect target skipped", next)
+	}
+}
+
+func TestCrawlerDepthZero(t *testing.T) {
+	c := NewCrawler([]string{"https://example.com/"}, 0, nil)
+	if next := c.Follow("https://example.com", []string{"https://example.com/a"}); next != nil {
+		t.Errorf("Follow with depth 0 = %v, want nil", next)
+	}
+}
+
+func TestCLI_CrawlValidation(t *testing.T) {
+	_, stderr, err := runSnag("--crawl", "--depth", "-1", "example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "Invalid --depth: -1")
+
+	_, stderr, err = runSnag("--crawl", "-o", "out.md", "example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "Use --output-dir instead")
+
+	_, stderr, err = runSnag("--crawl", "--info", "example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "Cannot use --crawl with --info")
+}
+
+func TestBrowser_Crawl(t *testing.T) {
+	if !isBrowserAvailable() {
+		t.Skip("Browser not available, skipping browser integration test")
+	}
+
+	server := startTestServer(t)
+	dir := t.TempDir()
+
+	_, stderr, err := runSnag("--crawl", "-d", dir, server.URL+"/crawl/index.html")
+	assertNoError(t, err)
+	assertContains(t, stderr, "ok=3 fail=0")
+
+	entries, err := os.ReadDir(dir)
+	assertNoError(t, err)
+	if len(entries) != 3 {
+		t.Errorf("expected start page and 2 linked pages at depth 1, got %d files", len(entries))
+	}
+
+	dir = t.TempDir()
+	_, stderr, err = runSnag("--crawl", "--depth", "2", "-d", dir, server.URL+"/crawl/index.html")
+	assertNoError(t, err)
+	assertContains(t, stderr, "ok=4 fail=0")
+}
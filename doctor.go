@@ -19,6 +19,7 @@ import (
 type DoctorReport struct {
 	SnagVersion   string
 	LatestVersion string
+	Offline       bool // latest version not checked
 	GoVersion     string
 	OS            string
 	Arch          string
@@ -91,7 +92,12 @@ func CollectDoctorInfo(customPort int) (*DoctorReport, error) {
 		report.CustomPortStatus = checkPortConnection(customPort)
 	}
 
-	report.LatestVersion = checkLatestVersion()
+	// --offline only allows traffic to the target URLs
+	if offline {
+		report.Offline = true
+	} else {
+		report.LatestVersion = checkLatestVersion()
+	}
 
 	return report, nil
 }
@@ -182,6 +188,8 @@ func (dr *DoctorReport) String() string {
 		} else {
 			buf.WriteString(dr.formatItem("Latest version", dr.LatestVersion))
 		}
+	} else if dr.Offline {
+		buf.WriteString(dr.formatItem("Latest version", "(not checked, --offline)"))
 	}
 	buf.WriteString(dr.formatItem("Go version", dr.GoVersion))
 	buf.WriteString(dr.formatItem("OS/Arch", fmt.Sprintf("%s/%s", dr.OS, dr.Arch)))
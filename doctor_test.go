@@ -306,6 +306,25 @@ func TestDoctorReportString_NoLatestVersion(t *testing.T) {
 	}
 }
 
+// TestDoctorReportString_Offline tests the version check skipped by --offline.
+func TestDoctorReportString_Offline(t *testing.T) {
+	report := &DoctorReport{
+		SnagVersion: "0.0.5",
+		Offline:     true,
+		GoVersion:   "go1.25.3",
+		OS:          "linux",
+		Arch:        "amd64",
+		WorkingDir:  "/home/test/snag",
+		EnvVars:     map[string]string{},
+	}
+
+	output := report.String()
+
+	if !strings.Contains(output, "Latest version:      (not checked, --offline)") {
+		t.Error("String() should show the latest version was not checked")
+	}
+}
+
 // TestDoctorReportString_NoBrowser tests when no browser is detected.
 func TestDoctorReportString_NoBrowser(t *testing.T) {
 	report := &DoctorReport{
@@ -0,0 +1,259 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Redirect is one hop of the main document's redirect chain: the URL that
+// was requested and the redirect status it answered with.
+type Redirect struct {
+	URL    string `json:"url"`
+	Status int    `json:"status"`
+}
+
+// DocumentTracker follows the main document of a page through navigation,
+// recording the redirects it goes through and the final response.
+type DocumentTracker struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu        sync.Mutex
+	redirects []Redirect
+	response  *proto.NetworkResponse
+}
+
+// StartDocumentTracker begins listening for main document requests on page.
+// Call Stop once navigation has finished.
+func StartDocumentTracker(page *rod.Page) *DocumentTracker {
+	ctx, cancel := context.WithCancel(context.Background())
+	dt := &DocumentTracker{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	wait := page.Context(ctx).EachEvent(
+		func(e *proto.NetworkRequestWillBeSent) {
+			if e.Type != proto.NetworkResourceTypeDocument || e.FrameID != page.FrameID {
+				return
+			}
+			dt.mu.Lock()
+			defer dt.mu.Unlock()
+			if e.RedirectResponse == nil {
+				// A new navigation starts a new chain
+				dt.redirects = nil
+				dt.response = nil
+				return
+			}
+			dt.redirects = append(dt.redirects, Redirect{
+				URL:    e.RedirectResponse.URL,
+				Status: e.RedirectResponse.Status,
+			})
+		},
+		func(e *proto.NetworkResponseReceived) {
+			if e.Type != proto.NetworkResourceTypeDocument || e.FrameID != page.FrameID {
+				return
+			}
+			dt.mu.Lock()
+			dt.response = e.Response
+			dt.mu.Unlock()
+		},
+	)
+
+	go func() {
+		defer close(dt.done)
+		wait()
+	}()
+
+	return dt
+}
+
+// Stop ends tracking and waits for the event listener to exit.
+func (dt *DocumentTracker) Stop() {
+	dt.cancel()
+	<-dt.done
+}
+
+// Redirects returns the redirect chain of the last navigation, in order.
+// It is empty when the document was not redirected.
+func (dt *DocumentTracker) Redirects() []Redirect {
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+	return append([]Redirect{}, dt.redirects...)
+}
+
+// Status returns the HTTP status of the main document response, or 0 if no
+// response was seen, as for pages served from cache or file:// URLs.
+func (dt *DocumentTracker) Status() int {
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+	if dt.response == nil {
+		return 0
+	}
+	return dt.response.Status
+}
+
+// Headers returns the main document's response headers with lowercase
+// names, or an empty map if no response was seen.
+func (dt *DocumentTracker) Headers() map[string]string {
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+	headers := make(map[string]string)
+	if dt.response == nil {
+		return headers
+	}
+	for name, value := range dt.response.Headers {
+		headers[strings.ToLower(name)] = value.Str()
+	}
+	return headers
+}
+
+// Response returns the main document response, or nil if none was seen.
+func (dt *DocumentTracker) Response() *proto.NetworkResponse {
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+	return dt.response
+}
+
+// StatusMatcher matches HTTP statuses against an --expect-status list such
+// as "200,304" or "2xx,404".
+type StatusMatcher []statusRange
+
+type statusRange struct {
+	min, max int
+}
+
+// parseStatusMatcher parses a comma-separated list of statuses (404),
+// classes (2xx), and ranges (200-299).
+func parseStatusMatcher(spec string) (StatusMatcher, error) {
+	var matcher StatusMatcher
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+
+		var r statusRange
+		switch {
+		case len(part) == 3 && strings.HasSuffix(part, "xx") && part[0] >= '1' && part[0] <= '5':
+			class := int(part[0]-'0') * 100
+			r = statusRange{class, class + 99}
+		case strings.Contains(part, "-"):
+			lo, hi, _ := strings.Cut(part, "-")
+			minStatus, err1 := strconv.Atoi(strings.TrimSpace(lo))
+			maxStatus, err2 := strconv.Atoi(strings.TrimSpace(hi))
+			if err1 != nil || err2 != nil || minStatus > maxStatus {
+				return nil, fmt.Errorf("invalid status range: %s", part)
+			}
+			r = statusRange{minStatus, maxStatus}
+		default:
+			status, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid status: %s", part)
+			}
+			r = statusRange{status, status}
+		}
+
+		if r.min < 100 || r.max > 599 {
+			return nil, fmt.Errorf("status out of range 100-599: %s", part)
+		}
+		matcher = append(matcher, r)
+	}
+
+	if len(matcher) == 0 {
+		return nil, fmt.Errorf("no statuses given")
+	}
+	return matcher, nil
+}
+
+// Matches reports whether status is in any of the matcher's ranges.
+func (m StatusMatcher) Matches(status int) bool {
+	for _, r := range m {
+		if status >= r.min && status <= r.max {
+			return true
+		}
+	}
+	return false
+}
+
+// extractCanonical returns the absolute URL of the page's
+// <link rel="canonical">, or "" if it has none.
+func extractCanonical(page *rod.Page) string {
+	res, err := page.Eval(`() => document.querySelector('link[rel~="canonical" i]')?.href || ""`)
+	if err != nil {
+		logger.Debug("Failed to read canonical link: %v", err)
+		return ""
+	}
+	return res.Value.Str()
+}
+
+// sameDocumentURL reports whether two URLs address the same document,
+// ignoring fragments, host case, default ports, and a trailing slash.
+func sameDocumentURL(a, b string) bool {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+
+	normalize := func(u *url.URL) string {
+		host := strings.ToLower(u.Host)
+		host = strings.TrimSuffix(host, ":80")
+		host = strings.TrimSuffix(host, ":443")
+		path := strings.TrimSuffix(u.EscapedPath(), "/")
+		return strings.ToLower(u.Scheme) + "://" + host + path + "?" + u.RawQuery
+	}
+
+	return normalize(ua) == normalize(ub)
+}
+
+// reportHeaders logs the status and response headers of the main document
+// for --show-headers, sorted by name.
+func reportHeaders(status int, headers map[string]string) {
+	if status == 0 {
+		logger.Info("No HTTP response headers available")
+		return
+	}
+
+	logger.Info("HTTP %d", status)
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		logger.Info("%s: %s", name, headers[name])
+	}
+}
+
+// reportDocument logs the redirect chain of a navigation and warns when the
+// page declares a canonical URL other than the one it was fetched from.
+func reportDocument(finalURL string, redirects []Redirect, canonical string) {
+	if len(redirects) > 0 {
+		hops := make([]string, 0, len(redirects)+1)
+		for _, r := range redirects {
+			hops = append(hops, fmt.Sprintf("%s (%d)", r.URL, r.Status))
+		}
+		hops = append(hops, finalURL)
+		logger.Verbose("Redirected: %s", strings.Join(hops, " -> "))
+	}
+
+	if canonical != "" && finalURL != "" && !sameDocumentURL(canonical, finalURL) {
+		logger.Warning("Canonical URL differs from fetched URL: %s", canonical)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSameDocumentURL(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"https://example.com/docs/", "https://example.com/docs", true},
+		{"https://Example.com/docs", "https://example.com/docs#intro", true},
+		{"https://example.com:443/", "https://example.com", true},
+		{"https://example.com/docs", "http://example.com/docs", false},
+		{"https://example.com/docs?page=2", "https://example.com/docs", false},
+		{"https://mirror.example.net/docs", "https://example.com/docs", false},
+	}
+
+	for _, tt := range tests {
+		if got := sameDocumentURL(tt.a, tt.b); got != tt.want {
+			t.Errorf("sameDocumentURL(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestMarshalPageInfo_Redirects(t *testing.T) {
+	info := &PageInfo{
+		URL:       "https://example.com/new",
+		Canonical: "https://example.com/new",
+		Redirects: []Redirect{{URL: "http://example.com/old", Status: 301}},
+	}
+
+	jsonData, err := marshalPageInfo(info, []string{"canonical", "redirects"})
+	if err != nil {
+		t.Fatalf("marshalPageInfo failed: %v", err)
+	}
+
+	for _, want := range []string{`"canonical": "https://example.com/new"`, `"url": "http://example.com/old"`, `"status": 301`} {
+		if !strings.Contains(string(jsonData), want) {
+			t.Errorf("missing %s in:\n%s", want, jsonData)
+		}
+	}
+}
+
+func TestParseStatusMatcher(t *testing.T) {
+	tests := []struct {
+		spec    string
+		status  int
+		want    bool
+		wantErr bool
+	}{
+		{"200", 200, true, false},
+		{"200", 201, false, false},
+		{"2xx", 204, true, false},
+		{"2XX", 302, false, false},
+		{"2xx,304", 304, true, false},
+		{"200-299", 299, true, false},
+		{" 404 , 410 ", 410, true, false},
+		{"abc", 0, false, true},
+		{"6xx", 0, false, true},
+		{"99", 0, false, true},
+		{"300-200", 0, false, true},
+		{",", 0, false, true},
+	}
+
+	for _, tt := range tests {
+		matcher, err := parseStatusMatcher(tt.spec)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseStatusMatcher(%q) expected error", tt.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseStatusMatcher(%q) unexpected error: %v", tt.spec, err)
+			continue
+		}
+		if got := matcher.Matches(tt.status); got != tt.want {
+			t.Errorf("parseStatusMatcher(%q).Matches(%d) = %v, want %v", tt.spec, tt.status, got, tt.want)
+		}
+	}
+}
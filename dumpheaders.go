@@ -0,0 +1,163 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// ResponseRecord is one page of the --dump-headers file: the main document
+// response a fetch ended with, the redirects on the way, and how long each
+// phase took. Status is 0 when no response arrived, as when the host could
+// not be reached; Error then says why.
+type ResponseRecord struct {
+	URL           string            `json:"url"`
+	FinalURL      string            `json:"final_url,omitempty"`
+	Status        int               `json:"status"`
+	StatusText    string            `json:"status_text,omitempty"`
+	Protocol      string            `json:"protocol,omitempty"`
+	RemoteAddress string            `json:"remote_address,omitempty"`
+	FromCache     bool              `json:"from_cache,omitempty"`
+	Headers       map[string]string `json:"headers"`
+	Redirects     []Redirect        `json:"redirects"`
+	Timing        ResponseTiming    `json:"timing"`
+	Error         string            `json:"error,omitempty"`
+}
+
+// ResponseTiming breaks down the main document request in milliseconds.
+// Phases that did not happen, such as DNS and connect on a reused
+// connection, are left out. TotalMS runs from the start of navigation to
+// the end of the fetch, including waiting for the page to settle.
+type ResponseTiming struct {
+	DNSMS     *float64 `json:"dns_ms,omitempty"`
+	ConnectMS *float64 `json:"connect_ms,omitempty"`
+	TLSMS     *float64 `json:"tls_ms,omitempty"`
+	WaitMS    *float64 `json:"wait_ms,omitempty"`
+	HeadersMS *float64 `json:"headers_ms,omitempty"`
+	TotalMS   float64  `json:"total_ms"`
+}
+
+// ResponseLog collects the responses of every page fetched in a run for
+// --dump-headers.
+type ResponseLog struct {
+	mu      sync.Mutex
+	records []ResponseRecord
+}
+
+// responseLog is the --dump-headers log of the current run.
+var responseLog *ResponseLog
+
+func NewResponseLog() *ResponseLog {
+	return &ResponseLog{}
+}
+
+// Add records the outcome of fetching requestURL. response is nil when no
+// document response was seen. With --redact-urls the URLs of the record,
+// its redirects, and its Location header are redacted.
+func (rl *ResponseLog) Add(requestURL string, response *proto.NetworkResponse, redirects []Redirect, elapsed time.Duration, fetchErr error) {
+	record := newResponseRecord(requestURL, response, slices.Clone(redirects), elapsed)
+	if fetchErr != nil {
+		record.Error = fetchErr.Error()
+	}
+	if redactURLs {
+		record.URL = redactText(record.URL)
+		record.FinalURL = redactText(record.FinalURL)
+		record.Error = redactText(record.Error)
+		for i := range record.Redirects {
+			record.Redirects[i].URL = redactText(record.Redirects[i].URL)
+		}
+		if location, ok := record.Headers["location"]; ok {
+			record.Headers["location"] = redactText(location)
+		}
+	}
+
+	rl.mu.Lock()
+	rl.records = append(rl.records, record)
+	rl.mu.Unlock()
+}
+
+func newResponseRecord(requestURL string, response *proto.NetworkResponse, redirects []Redirect, elapsed time.Duration) ResponseRecord {
+	record := ResponseRecord{
+		URL:       requestURL,
+		Headers:   map[string]string{},
+		Redirects: redirects,
+		Timing:    ResponseTiming{TotalMS: roundMS(float64(elapsed) / float64(time.Millisecond))},
+	}
+	if record.Redirects == nil {
+		record.Redirects = []Redirect{}
+	}
+	if response == nil {
+		return record
+	}
+
+	record.FinalURL = response.URL
+	record.Status = response.Status
+	record.StatusText = response.StatusText
+	record.Protocol = response.Protocol
+	record.FromCache = response.FromDiskCache
+	if response.RemoteIPAddress != "" {
+		record.RemoteAddress = response.RemoteIPAddress
+		if response.RemotePort != nil {
+			record.RemoteAddress = net.JoinHostPort(response.RemoteIPAddress, strconv.Itoa(*response.RemotePort))
+		}
+	}
+	for name, value := range response.Headers {
+		record.Headers[strings.ToLower(name)] = value.Str()
+	}
+
+	if t := response.Timing; t != nil {
+		record.Timing.DNSMS = timingPhase(t.DNSStart, t.DNSEnd)
+		record.Timing.ConnectMS = timingPhase(t.ConnectStart, t.ConnectEnd)
+		record.Timing.TLSMS = timingPhase(t.SslStart, t.SslEnd)
+		record.Timing.WaitMS = timingPhase(t.SendEnd, t.ReceiveHeadersStart)
+		record.Timing.HeadersMS = timingPhase(0, t.ReceiveHeadersEnd)
+	}
+	return record
+}
+
+// timingPhase returns the length of a phase from Chrome's resource timing,
+// whose offsets are -1 for phases that did not happen.
+func timingPhase(start, end float64) *float64 {
+	if start < 0 || end <= 0 || end < start {
+		return nil
+	}
+	ms := roundMS(end - start)
+	return &ms
+}
+
+func roundMS(ms float64) float64 {
+	return math.Round(ms*10) / 10
+}
+
+// Write saves the records to path as a JSON array, in the order the
+// fetches finished.
+func (rl *ResponseLog) Write(path string) error {
+	rl.mu.Lock()
+	records := append([]ResponseRecord{}, rl.records...)
+	rl.mu.Unlock()
+
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode response headers: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), DefaultFileMode); err != nil {
+		return fmt.Errorf("failed to write response headers: %w", err)
+	}
+	logger.Success("Saved %d response%s to %s", len(records), plural(len(records)), path)
+	return nil
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/ysmood/gson"
+)
+
+func TestNewResponseRecord(t *testing.T) {
+	var response proto.NetworkResponse
+	err := json.Unmarshal([]byte(`{
+		"url": "https://example.com/missing/",
+		"status": 404,
+		"statusText": "Not Found",
+		"protocol": "h2",
+		"remoteIPAddress": "2606:2800:220:1::1",
+		"remotePort": 443,
+		"headers": {"Content-Type": "text/html", "Cache-Control": "no-cache"},
+		"timing": {
+			"requestTime": 1000, "dnsStart": 0.5, "dnsEnd": 12.25, "connectStart": 12.25, "connectEnd": 40,
+			"sslStart": 20, "sslEnd": 40, "sendStart": 40.5, "sendEnd": 41, "receiveHeadersStart": 90, "receiveHeadersEnd": 91.04
+		}
+	}`), &response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	redirects := []Redirect{{URL: "http://example.com/missing", Status: 301}}
+	record := newResponseRecord("http://example.com/missing", &response, redirects, 1500*time.Millisecond)
+
+	if record.Status != 404 || record.StatusText != "Not Found" || record.FinalURL != "https://example.com/missing/" {
+		t.Errorf("unexpected status or URL: %+v", record)
+	}
+	if record.RemoteAddress != "[2606:2800:220:1::1]:443" {
+		t.Errorf("RemoteAddress = %q", record.RemoteAddress)
+	}
+	if record.Headers["content-type"] != "text/html" || record.Headers["cache-control"] != "no-cache" {
+		t.Errorf("Headers = %v", record.Headers)
+	}
+	if len(record.Redirects) != 1 || record.Redirects[0].Status != 301 {
+		t.Errorf("Redirects = %v", record.Redirects)
+	}
+
+	timing := record.Timing
+	checks := []struct {
+		name     string
+		got      *float64
+		expected float64
+	}{
+		{"dns", timing.DNSMS, 11.8},
+		{"connect", timing.ConnectMS, 27.8},
+		{"tls", timing.TLSMS, 20},
+		{"wait", timing.WaitMS, 49},
+		{"headers", timing.HeadersMS, 91},
+	}
+	for _, c := range checks {
+		if c.got == nil || *c.got != c.expected {
+			t.Errorf("%s timing = %v, want %v", c.name, c.got, c.expected)
+		}
+	}
+	if timing.TotalMS != 1500 {
+		t.Errorf("TotalMS = %v, want 1500", timing.TotalMS)
+	}
+}
+
+func TestNewResponseRecord_ReusedConnection(t *testing.T) {
+	response := &proto.NetworkResponse{
+		URL:    "https://example.com/",
+		Status: 200,
+		Timing: &proto.NetworkResourceTiming{
+			DNSStart: -1, DNSEnd: -1, ConnectStart: -1, ConnectEnd: -1, SslStart: -1, SslEnd: -1,
+			SendStart: 0.2, SendEnd: 0.3, ReceiveHeadersStart: 20, ReceiveHeadersEnd: 21,
+		},
+	}
+
+	record := newResponseRecord("https://example.com/", response, nil, time.Second)
+	if record.Timing.DNSMS != nil || record.Timing.ConnectMS != nil || record.Timing.TLSMS != nil {
+		t.Errorf("expected no DNS, connect, or TLS timing: %+v", record.Timing)
+	}
+	if record.Redirects == nil {
+		t.Error("expected an empty redirect list, not nil")
+	}
+}
+
+func TestResponseLog_Write(t *testing.T) {
+	logger = NewLogger(LevelQuiet)
+
+	log := NewResponseLog()
+	log.Add("https://example.com/", &proto.NetworkResponse{URL: "https://example.com/", Status: 200}, nil, time.Second, nil)
+	log.Add("https://unreachable.invalid/", nil, nil, time.Second, errors.New("navigation failed"))
+
+	path := filepath.Join(t.TempDir(), "headers.json")
+	if err := log.Write(path); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var records []ResponseRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	if records[0].Status != 200 || records[0].Error != "" {
+		t.Errorf("first record = %+v", records[0])
+	}
+	if records[1].Status != 0 || records[1].Error != "navigation failed" {
+		t.Errorf("second record = %+v", records[1])
+	}
+}
+
+func TestResponseLog_RedactURLs(t *testing.T) {
+	redactURLs = true
+	defer func() { redactURLs = false }()
+
+	redirects := []Redirect{{URL: "https://example.com/login?token=secret", Status: 302}}
+	response := &proto.NetworkResponse{
+		URL:     "https://example.com/home?token=secret",
+		Status:  302,
+		Headers: proto.NetworkHeaders{"Location": gson.New("https://example.com/next?token=secret")},
+	}
+
+	log := NewResponseLog()
+	log.Add("https://example.com/login?token=secret", response, redirects, time.Second, errors.New("failed https://example.com/?token=secret"))
+
+	data, err := json.Marshal(log.records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("record not redacted: %s", data)
+	}
+	if redirects[0].URL != "https://example.com/login?token=secret" {
+		t.Errorf("caller's redirects were modified: %v", redirects)
+	}
+}
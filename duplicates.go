@@ -0,0 +1,170 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"os"
+	"strings"
+	"unicode"
+)
+
+const (
+	DuplicatesReport = "report"
+	DuplicatesSkip   = "skip"
+
+	// DuplicateShingleSize is the number of consecutive words hashed
+	// together when fingerprinting a page.
+	DuplicateShingleSize = 3
+
+	// DuplicateMaxDistance is the most simhash bits two pages may differ
+	// by and still be near-duplicates.
+	DuplicateMaxDistance = 3
+
+	// DuplicateMinWords is the fewest words a page needs for near-duplicate
+	// matching. Shorter pages only match when their words are identical,
+	// since a few words give too little for a simhash to go on.
+	DuplicateMinWords = 50
+)
+
+// pageFingerprint identifies the content of a page for duplicate detection.
+type pageFingerprint struct {
+	words   int
+	exact   uint64
+	simhash uint64
+}
+
+// fingerprintContent fingerprints converted page content by its words,
+// ignoring case, punctuation, and markup characters, so the same text in
+// print views or URL variants of a page matches.
+func fingerprintContent(content string) pageFingerprint {
+	words := strings.FieldsFunc(strings.ToLower(content), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	exact := fnv.New64a()
+	exact.Write([]byte(strings.Join(words, " ")))
+	fp := pageFingerprint{words: len(words), exact: exact.Sum64()}
+
+	var weights [64]int
+	for i := 0; i+DuplicateShingleSize <= len(words); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:i+DuplicateShingleSize], " ")))
+		sum := h.Sum64()
+		for bit := range weights {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+	for bit, w := range weights {
+		if w > 0 {
+			fp.simhash |= 1 << bit
+		}
+	}
+	return fp
+}
+
+// matches reports whether fp and other are the same content or, for pages
+// long enough to tell, near-duplicates.
+func (fp pageFingerprint) matches(other pageFingerprint) bool {
+	if fp.words == 0 || other.words == 0 {
+		return false
+	}
+	if fp.exact == other.exact {
+		return true
+	}
+	if fp.words < DuplicateMinWords || other.words < DuplicateMinWords {
+		return false
+	}
+	return bits.OnesCount64(fp.simhash^other.simhash) <= DuplicateMaxDistance
+}
+
+// findDuplicates returns, for each page, the index of the earlier page it
+// duplicates, or -1 for the first page with its content.
+func findDuplicates(contents []string) []int {
+	fingerprints := make([]pageFingerprint, len(contents))
+	originals := []int{}
+	dupOf := make([]int, len(contents))
+
+	for i, content := range contents {
+		fingerprints[i] = fingerprintContent(content)
+		dupOf[i] = -1
+		for _, j := range originals {
+			if fingerprints[i].matches(fingerprints[j]) {
+				dupOf[i] = j
+				break
+			}
+		}
+		if dupOf[i] < 0 {
+			originals = append(originals, i)
+		}
+	}
+	return dupOf
+}
+
+// handleBatchDuplicates flags pages of a batch whose content is effectively
+// the same as an earlier page, and with --duplicates skip deletes their
+// files. It returns the paths still written and the number of pages
+// skipped. Output written as several files per page, or streamed to a tar
+// archive, is left as it is.
+func handleBatchDuplicates(paths []string, outputFormat string) ([]string, int) {
+	if outputFormat == FormatPDF || outputFormat == FormatPNG || len(paths) < 2 {
+		return paths, 0
+	}
+	if tarOutput != nil || splitBy != "" || (maxTokens > 0 && normalizeTokenOverflow(tokenOverflow) == TokenOverflowSplit) {
+		logger.Debug("Skipping duplicate detection for split or archived output")
+		return paths, 0
+	}
+
+	contents := make([]string, len(paths))
+	for i, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			logger.Warning("Duplicate detection failed: %v", fmt.Errorf("failed to read %s: %w", path, err))
+			return paths, 0
+		}
+		contents[i] = string(data)
+	}
+
+	skip := normalizeDuplicates(duplicates) == DuplicatesSkip
+	kept := make([]string, 0, len(paths))
+	found := 0
+	for i, j := range findDuplicates(contents) {
+		if j < 0 {
+			kept = append(kept, paths[i])
+			continue
+		}
+		found++
+
+		if !skip {
+			logger.Warning("Duplicate: %s has the same content as %s", paths[i], paths[j])
+			kept = append(kept, paths[i])
+			continue
+		}
+		if err := os.Remove(paths[i]); err != nil {
+			logger.Warning("Failed to remove duplicate %s: %v", paths[i], err)
+			kept = append(kept, paths[i])
+			found--
+			continue
+		}
+		logger.Info("Skipped %s (same content as %s)", paths[i], paths[j])
+	}
+
+	if found == 0 {
+		return paths, 0
+	}
+	if !skip {
+		logger.Info("Found %d duplicate page%s (use --duplicates skip to drop them)", found, plural(found))
+		return paths, 0
+	}
+	return kept, found
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// articleText returns n sentences of distinct filler text about topic.
+func articleText(topic string, n int) string {
+	var b strings.Builder
+	for i := range n {
+		fmt.Fprintf(&b, "Section %d explains how %s handles case number %d with care and detail. ", i, topic, i*7)
+	}
+	return b.String()
+}
+
+func TestFindDuplicates(t *testing.T) {
+	article := "# Install\n\n" + articleText("installation", 40)
+	printView := "Install\n\n" + strings.ReplaceAll(articleText("installation", 40), "care", "CARE")
+	// A tracking-parameter variant differs in a link
+	variant := article + "\n\n[Share](https://example.com/install?utm_source=feed)"
+	other := "# Configure\n\n" + articleText("configuration", 40)
+	short := "Page not found."
+
+	got := findDuplicates([]string{article, other, printView, variant, short, short, ""})
+	want := []int{-1, -1, 0, 0, -1, 4, -1}
+	if !slices.Equal(got, want) {
+		t.Errorf("findDuplicates = %v, want %v", got, want)
+	}
+}
+
+func TestFingerprintContentShortPages(t *testing.T) {
+	a := fingerprintContent("Price: 10 dollars")
+	b := fingerprintContent("Price: 12 dollars")
+	if a.matches(b) {
+		t.Error("short pages with different words should not match")
+	}
+	if !a.matches(fingerprintContent("price 10 DOLLARS")) {
+		t.Error("short pages with the same words should match")
+	}
+}
+
+func TestHandleBatchDuplicates(t *testing.T) {
+	defer func() { duplicates = "" }()
+
+	dir := t.TempDir()
+	content := []string{articleText("snag", 30), articleText("rod", 30), articleText("snag", 30)}
+	paths := make([]string, len(content))
+	for i, c := range content {
+		paths[i] = filepath.Join(dir, fmt.Sprintf("page-%d.md", i))
+		if err := os.WriteFile(paths[i], []byte(c), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	duplicates = DuplicatesReport
+	kept, skipped := handleBatchDuplicates(paths, FormatMarkdown)
+	if skipped != 0 || len(kept) != 3 {
+		t.Errorf("report: kept %v, skipped %d", kept, skipped)
+	}
+
+	duplicates = DuplicatesSkip
+	kept, skipped = handleBatchDuplicates(paths, FormatMarkdown)
+	if skipped != 1 || !slices.Equal(kept, paths[:2]) {
+		t.Errorf("skip: kept %v, skipped %d", kept, skipped)
+	}
+	if _, err := os.Stat(paths[2]); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be deleted", paths[2])
+	}
+}
+
+func TestCLI_DuplicatesInvalid(t *testing.T) {
+	_, stderr, err := runSnag("--duplicates", "drop", "example.com", "example.org")
+	assertError(t, err)
+	assertContains(t, stderr, "Invalid --duplicates: drop")
+}
@@ -0,0 +1,276 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	EngineChromium = "chromium"
+	EngineWebKit   = "webkit"
+	EngineHTTP     = "http"
+)
+
+// Capability is something a fetch engine can do beyond returning a page's
+// HTML. Flags and formats that need a capability are rejected with engines
+// that lack it.
+type Capability uint
+
+const (
+	// CapJavaScript runs the page's scripts, so --wait-for has a DOM to
+	// watch.
+	CapJavaScript Capability = 1 << iota
+
+	// CapScreenshot captures the rendered page for --format png.
+	CapScreenshot
+
+	// CapPDF prints the rendered page for --format pdf.
+	CapPDF
+
+	// CapHeaders sends --header and --user-agent with the request.
+	CapHeaders
+
+	// CapDevTools covers everything that drives Chromium through the
+	// DevTools protocol: tabs, interaction, network control, and --format
+	// json.
+	CapDevTools
+)
+
+// engineCapabilities are the capabilities of each --engine. Every engine
+// implements Engine; Chromium alone also has CapDevTools, for which
+// handlers.go drives its PageFetcher directly.
+var engineCapabilities = map[string]Capability{
+	EngineChromium: CapJavaScript | CapScreenshot | CapPDF | CapHeaders | CapDevTools,
+	EngineWebKit:   CapJavaScript | CapScreenshot,
+	EngineHTTP:     CapHeaders,
+}
+
+// engineNeutralFlags work with every engine: they only shape the
+// conversion of the page's HTML, the output, and timing.
+var engineNeutralFlags = []string{
+	"engine", "format", "output", "output-dir", "url-file", "filename-template",
+	"timeout", "nav-timeout", "wait-timeout",
+	"readability", "sanitize", "html-pretty", "html-minify", "eol", "bom",
+	"split-by", "max-tokens", "token-overflow",
+	"no-env-expand", "redact-urls", "verbose", "quiet", "debug", "log-format",
+}
+
+// flagCapabilities are the capabilities flags beyond engineNeutralFlags
+// need. Flags in neither list need CapDevTools.
+var flagCapabilities = map[string]Capability{
+	"wait-for":   CapJavaScript,
+	"header":     CapHeaders,
+	"user-agent": CapHeaders,
+}
+
+// formatCapabilities are the capabilities output formats need. Formats
+// converted from HTML need none.
+var formatCapabilities = map[string]Capability{
+	FormatPNG:  CapScreenshot,
+	FormatPDF:  CapPDF,
+	FormatJSON: CapDevTools,
+}
+
+// engineSupports reports whether the named engine has all of caps.
+func engineSupports(name string, caps Capability) bool {
+	return engineCapabilities[name]&caps == caps
+}
+
+// unsupportedEngineFlags returns the flags set on cmd that the named
+// engine does not support.
+func unsupportedEngineFlags(cmd *cobra.Command, name string) []string {
+	var unsupported []string
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if slices.Contains(engineNeutralFlags, f.Name) {
+			return
+		}
+		caps, ok := flagCapabilities[f.Name]
+		if !ok {
+			caps = CapDevTools
+		}
+		if !engineSupports(name, caps) {
+			unsupported = append(unsupported, "--"+f.Name)
+		}
+	})
+	return unsupported
+}
+
+// engineHints suggest how to work within each engine's capabilities.
+var engineHints = map[string][2]string{
+	EngineWebKit: {
+		"Safari pages are converted from their HTML; tabs, interaction, and network options need Chromium",
+		"snag --engine webkit -f md -d out/ <url>",
+	},
+	EngineHTTP: {
+		"Pages fetched over HTTP are converted from the HTML the server sends; scripts, screenshots, and browser options need Chromium",
+		"snag --engine http -f md -d out/ <url>",
+	},
+}
+
+// validateEngineCapabilities rejects flags and an output format the named
+// engine cannot honour.
+func validateEngineCapabilities(cmd *cobra.Command, name, outputFormat string) error {
+	if name == EngineChromium {
+		return nil
+	}
+	hint := engineHints[name]
+
+	if unsupported := unsupportedEngineFlags(cmd, name); len(unsupported) > 0 {
+		logger.Error("--engine %s does not support %s", name, strings.Join(unsupported, ", "))
+		logger.ErrorWithSuggestion(hint[0], hint[1])
+		return fmt.Errorf("unsupported flags with --engine %s: %s", name, strings.Join(unsupported, ", "))
+	}
+	if caps, ok := formatCapabilities[outputFormat]; ok && !engineSupports(name, caps) {
+		logger.Error("--engine %s cannot produce --format %s", name, outputFormat)
+		logger.ErrorWithSuggestion(hint[0], hint[1])
+		return fmt.Errorf("unsupported format with --engine %s: %s", name, outputFormat)
+	}
+	return nil
+}
+
+// LoadedPage is a page an Engine has loaded.
+type LoadedPage struct {
+	URL      string
+	FinalURL string
+	Title    string
+	HTML     string
+}
+
+// Engine fetches pages for an --engine. Load may be called for several URLs
+// between Start and Close; Screenshot captures the page loaded last, and is
+// only called on engines with CapScreenshot.
+type Engine interface {
+	Start() error
+	Load(url string) (*LoadedPage, error)
+	Screenshot() ([]byte, error)
+	Close()
+}
+
+// newEngine returns the Engine for --engine name.
+func newEngine(name string) (Engine, error) {
+	switch name {
+	case EngineChromium:
+		return &chromiumEngine{}, nil
+	case EngineWebKit:
+		return &webkitEngine{}, nil
+	case EngineHTTP:
+		return &httpEngine{}, nil
+	}
+	return nil, fmt.Errorf("invalid engine: %s", name)
+}
+
+// handleEngine fetches urls with an Engine and converts them with the same
+// pipeline as Chromium pages.
+func handleEngine(name string, urls []string, outputNames map[string]string) error {
+	eng, err := newEngine(name)
+	if err != nil {
+		return err
+	}
+
+	outputFormat := normalizeFormat(format)
+	outputFile := strings.TrimSpace(output)
+	outDir := strings.TrimSpace(outputDir)
+	if len(urls) > 1 && outDir == "" {
+		outDir = "."
+	}
+	if outDir != "" {
+		if err := validateDirectory(outDir); err != nil {
+			return err
+		}
+	}
+	if outputFile != "" {
+		if err := validateOutputPath(outputFile); err != nil {
+			return err
+		}
+		checkExtensionMismatch(outputFile, outputFormat)
+	}
+
+	validated := make([]string, 0, len(urls))
+	for _, u := range urls {
+		v, err := validateURL(u)
+		if err != nil {
+			return err
+		}
+		validated = append(validated, v)
+	}
+
+	if err := eng.Start(); err != nil {
+		return err
+	}
+	defer eng.Close()
+
+	timestamp := time.Now()
+	failures := 0
+	for i, u := range validated {
+		path := outputFile
+		if name, ok := outputNames[urls[i]]; ok && path == "" {
+			dir := outDir
+			if dir == "" {
+				dir = "."
+			}
+			if path, err = namedOutputPath(dir, name); err != nil {
+				return err
+			}
+		}
+
+		if err := fetchWithEngine(eng, u, outputFormat, path, outDir, timestamp); err != nil {
+			logger.Error("Failed to fetch %s: %v", u, err)
+			failures++
+		}
+	}
+	logger.SetContext(LogContext{})
+
+	if failures == len(validated) {
+		return fmt.Errorf("failed to fetch %d URL%s", failures, plural(failures))
+	}
+	if failures > 0 {
+		logger.Warning("%d of %d URLs failed", failures, len(validated))
+	}
+	return nil
+}
+
+// fetchWithEngine loads one URL and writes it to outputFile, a file named
+// after the page in outDir, or stdout.
+func fetchWithEngine(eng Engine, url, outputFormat, outputFile, outDir string, timestamp time.Time) error {
+	logger.SetContext(LogContext{URL: url})
+	logger.Info("Fetching %s...", url)
+	page, err := eng.Load(url)
+	if err != nil {
+		return err
+	}
+	if page.FinalURL != url {
+		logger.Verbose("Redirected to %s", page.FinalURL)
+	}
+
+	if outputFile == "" && (outDir != "" || requiresOutputFile(outputFormat)) {
+		dir := outDir
+		if dir == "" {
+			dir = "."
+		}
+		outputFile, err = generateOutputFilename(page.Title, url, outputFormat, timestamp, dir)
+		if err != nil {
+			return err
+		}
+	}
+
+	converter := newPageConverter(outputFormat)
+	if outputFormat == FormatPNG {
+		data, err := eng.Screenshot()
+		if err != nil {
+			return fmt.Errorf("failed to capture screenshot: %w", err)
+		}
+		return converter.writeBinaryToFile(data, outputFile)
+	}
+	return converter.Process(page.HTML, outputFile)
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"os"
+	"regexp"
+	"strings"
+)
+
+// envVarPattern matches ${NAME} and ${NAME:-default}. A bare $NAME is left
+// alone, as URLs such as OData queries use $ in their own parameters.
+var envVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// expandEnvVars replaces ${NAME} in s with the environment variable NAME,
+// and ${NAME:-default} with default when NAME is unset or empty. A variable
+// that is unset with no default is replaced with nothing and logged as a
+// warning. With --no-env-expand, s is returned unchanged.
+func expandEnvVars(s string) string {
+	if noEnvExpand || !strings.Contains(s, "${") {
+		return s
+	}
+
+	return envVarPattern.ReplaceAllStringFunc(s, func(match string) string {
+		groups := envVarPattern.FindStringSubmatch(match)
+		name, hasDefault, fallback := groups[1], groups[2] != "", groups[3]
+
+		value, ok := os.LookupEnv(name)
+		if ok && (value != "" || !hasDefault) {
+			return value
+		}
+		if hasDefault {
+			return fallback
+		}
+		logger.Warning("Environment variable %s is not set", name)
+		return ""
+	})
+}
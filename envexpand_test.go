@@ -0,0 +1,51 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import "testing"
+
+func TestExpandEnvVars(t *testing.T) {
+	logger = NewLogger(LevelQuiet)
+	t.Setenv("SNAG_TEST_HOST", "staging.example.com")
+	t.Setenv("SNAG_TEST_EMPTY", "")
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"https://${SNAG_TEST_HOST}/docs", "https://staging.example.com/docs"},
+		{"https://${SNAG_TEST_UNSET:-docs.example.com}/", "https://docs.example.com/"},
+		{"https://${SNAG_TEST_HOST:-docs.example.com}/", "https://staging.example.com/"},
+		{"${SNAG_TEST_EMPTY:-fallback}", "fallback"},
+		{"[${SNAG_TEST_EMPTY}]", "[]"},
+		{"[${SNAG_TEST_UNSET}]", "[]"},
+		{"https://example.com/odata/People?$filter=age gt 30&$top=5", "https://example.com/odata/People?$filter=age gt 30&$top=5"},
+		{"https://example.com/${not a var}", "https://example.com/${not a var}"},
+	}
+
+	for _, tt := range tests {
+		if got := expandEnvVars(tt.input); got != tt.expected {
+			t.Errorf("expandEnvVars(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+
+	noEnvExpand = true
+	defer func() { noEnvExpand = false }()
+	if got := expandEnvVars("https://${SNAG_TEST_HOST}/"); got != "https://${SNAG_TEST_HOST}/" {
+		t.Errorf("expected no expansion with --no-env-expand, got %q", got)
+	}
+}
+
+func TestParseURLLine_EnvVars(t *testing.T) {
+	logger = NewLogger(LevelQuiet)
+	t.Setenv("SNAG_TEST_HOST", "staging.example.com")
+
+	entry, ok := parseURLLine("${SNAG_TEST_HOST}/api  api.md  # staging only", 1)
+	if !ok || entry.URL != "https://staging.example.com/api" || entry.Name != "api.md" {
+		t.Errorf("parseURLLine() = %+v, %v", entry, ok)
+	}
+}
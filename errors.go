@@ -22,4 +22,12 @@ var (
 	ErrNoTabMatch         = errors.New("no tab matches pattern")
 	ErrNoValidURLs        = errors.New("no valid URLs provided")
 	ErrOutputFlagConflict = errors.New("--output cannot be used with multiple content sources, use --output-dir instead")
+	ErrAssertionFailed    = errors.New("page assertion failed")
+	ErrNoFilesMatched     = errors.New("no files match pattern")
+	ErrHostBlocked        = errors.New("host is blocked")
+	ErrJSEvalDisabled     = errors.New("JavaScript evaluation is disabled")
+	ErrResourceBudget     = errors.New("resource budget exceeded")
+	ErrSelectorNotFound   = errors.New("no element matches selector")
+	ErrDeviceAuthFailed   = errors.New("device authorization failed")
+	ErrHTTPStatus         = errors.New("HTTP error status")
 )
@@ -0,0 +1,50 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// evalScript is the --eval or --eval-expr script run in each page before
+// extraction, and evalSource names it in audit logs.
+var (
+	evalScript string
+	evalSource string
+)
+
+// loadEvalScript returns the script to run from an --eval file or an
+// --eval-expr expression, and its source for audit logs.
+func loadEvalScript(file, expr string) (source, script string, err error) {
+	if file == "" {
+		return "--eval-expr", strings.TrimSpace(expr), nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		logger.Error("Failed to read --eval script: %s", file)
+		logger.ErrorWithSuggestion(
+			"Give the path of a JavaScript file",
+			"snag --eval expand.js https://example.com",
+		)
+		return "", "", fmt.Errorf("failed to read eval script: %w", err)
+	}
+	return "--eval " + file, strings.TrimSpace(string(data)), nil
+}
+
+// evalResultJSON formats the value a script returned as indented JSON.
+// Undefined, such as from a script that only changes the page, is null.
+func evalResultJSON(result *proto.RuntimeRemoteObject) string {
+	if result == nil || result.Type == proto.RuntimeRemoteObjectTypeUndefined {
+		return "null\n"
+	}
+	return result.Value.JSON("", "  ") + "\n"
+}
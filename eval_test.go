@@ -0,0 +1,62 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+func TestEvalResultJSON(t *testing.T) {
+	remote := func(raw string) *proto.RuntimeRemoteObject {
+		var obj proto.RuntimeRemoteObject
+		assertNoError(t, json.Unmarshal([]byte(raw), &obj))
+		return &obj
+	}
+
+	tests := []struct {
+		name   string
+		result *proto.RuntimeRemoteObject
+		want   string
+	}{
+		{"undefined", remote(`{"type": "undefined"}`), "null\n"},
+		{"no result", nil, "null\n"},
+		{"string", remote(`{"type": "string", "value": "ok"}`), "\"ok\"\n"},
+		{"object", remote(`{"type": "object", "value": {"count": 2}}`), "{\n  \"count\": 2\n}\n"},
+	}
+
+	for _, tt := range tests {
+		if got := evalResultJSON(tt.result); got != tt.want {
+			t.Errorf("evalResultJSON(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLoadEvalScript(t *testing.T) {
+	logger = NewLogger(LevelQuiet)
+
+	source, script, err := loadEvalScript("", "  document.title  ")
+	assertNoError(t, err)
+	if source != "--eval-expr" || script != "document.title" {
+		t.Errorf("loadEvalScript(expr) = %q, %q", source, script)
+	}
+
+	path := filepath.Join(t.TempDir(), "state.js")
+	assertNoError(t, os.WriteFile(path, []byte("window.__STATE__\n"), DefaultFileMode))
+	source, script, err = loadEvalScript(path, "")
+	assertNoError(t, err)
+	if source != "--eval "+path || script != "window.__STATE__" {
+		t.Errorf("loadEvalScript(file) = %q, %q", source, script)
+	}
+
+	_, _, err = loadEvalScript(filepath.Join(t.TempDir(), "missing.js"), "")
+	assertError(t, err)
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"github.com/go-rod/rod"
+)
+
+// expandScript opens collapsed <details> elements and shows every panel of
+// ARIA tab widgets, labelling each panel with its tab so sections stay
+// distinguishable once flattened. It returns the number of elements
+// expanded.
+const expandScript = `() => {
+	let expanded = 0;
+
+	for (const details of document.querySelectorAll('details:not([open])')) {
+		details.open = true;
+		expanded++;
+	}
+
+	for (const panel of document.querySelectorAll('[role="tabpanel"]')) {
+		const hidden = panel.hidden || panel.getAttribute('aria-hidden') === 'true' ||
+			getComputedStyle(panel).display === 'none';
+		if (hidden) {
+			panel.hidden = false;
+			panel.removeAttribute('aria-hidden');
+			panel.style.display = 'block';
+			expanded++;
+		}
+
+		let tab = null;
+		const labelledBy = panel.getAttribute('aria-labelledby');
+		if (labelledBy) {
+			tab = document.getElementById(labelledBy);
+		}
+		if (!tab && panel.id) {
+			tab = document.querySelector('[role="tab"][aria-controls="' + CSS.escape(panel.id) + '"]');
+		}
+		const label = tab ? tab.textContent.trim() : '';
+		if (label && !panel.querySelector(':scope > [data-snag-tab]')) {
+			const heading = document.createElement('p');
+			heading.setAttribute('data-snag-tab', '');
+			const strong = document.createElement('strong');
+			strong.textContent = label;
+			heading.appendChild(strong);
+			panel.prepend(heading);
+		}
+	}
+
+	return expanded;
+}`
+
+// expandHiddenContent reveals content hidden behind <details> and tab
+// widgets so it is extracted with the rest of the page. It changes the
+// live page, so it is skipped with --no-expand and --no-activate.
+func expandHiddenContent(page *rod.Page) {
+	if noExpand || noActivate {
+		return
+	}
+
+	res, err := page.Eval(expandScript)
+	if err != nil {
+		logger.Debug("Failed to expand hidden content: %v", err)
+		return
+	}
+	if n := res.Value.Int(); n > 0 {
+		logger.Verbose("Expanded %d collapsed section%s", n, plural(n))
+	}
+}
@@ -10,49 +10,153 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
 )
 
 type PageFetcher struct {
-	page    *rod.Page
-	timeout time.Duration
+	page        *rod.Page
+	navTimeout  time.Duration
+	waitTimeout time.Duration
+
+	status    int
+	partial   bool
+	headers   map[string]string
+	redirects []Redirect
+	canonical string
+	response  *proto.NetworkResponse
 }
 
 type FetchOptions struct {
 	URL     string
 	Timeout int
 	WaitFor string
+	Headers map[string]string
+	Actions []PageAction
 }
 
 func NewPageFetcher(page *rod.Page, timeout int) *PageFetcher {
 	if page == nil {
 		logger.Warning("NewPageFetcher called with nil page")
 	}
+	umbrella := time.Duration(timeout) * time.Second
 	return &PageFetcher{
-		page:    page,
-		timeout: time.Duration(timeout) * time.Second,
+		page:        page,
+		navTimeout:  phaseTimeout(navTimeout, umbrella),
+		waitTimeout: phaseTimeout(waitTimeout, umbrella),
 	}
 }
 
+// phaseTimeout returns override when it is set, otherwise fallback, so
+// --nav-timeout, --stabilize-timeout, and --wait-timeout can refine the
+// --timeout umbrella one phase at a time.
+func phaseTimeout(override, fallback time.Duration) time.Duration {
+	if override > 0 {
+		return override
+	}
+	return fallback
+}
+
+// stabilizeTimeout is how long to wait for a page to stop changing after
+// navigation or an interaction.
+func stabilizeTimeout() time.Duration {
+	return phaseTimeout(stabilizeTime, StabilizeTimeout)
+}
+
 func (pf *PageFetcher) Fetch(opts FetchOptions) (string, error) {
+	start := time.Now()
+	html, err := pf.fetch(opts)
+	metrics.ObserveFetch(time.Since(start), err)
+	if responseLog != nil {
+		responseLog.Add(opts.URL, pf.response, pf.redirects, time.Since(start), err)
+	}
+	return html, err
+}
+
+func (pf *PageFetcher) fetch(opts FetchOptions) (string, error) {
 	if pf.page == nil {
 		return "", fmt.Errorf("cannot fetch: page is nil")
 	}
 
 	logger.Info("Fetching %s...", opts.URL)
+	pf.partial = false
+	pf.response = nil
+	pf.redirects = nil
+
+	policy, err := activeHostPolicy()
+	if err != nil {
+		return "", err
+	}
+	if policy.Enabled() {
+		if err := policy.Check(opts.URL); err != nil {
+			logger.Error("Refusing to fetch %s: %v", opts.URL, err)
+			return "", err
+		}
+	}
+	blocker, err := activeResourceBlocker()
+	if err != nil {
+		return "", err
+	}
+	if policy.Enabled() || len(hostHeaderRules) > 0 || blocker.Enabled() {
+		defer guardRequests(pf.page, policy, hostHeaderRules, blocker)()
+	}
+
+	logger.Verbose("Navigating to %s (timeout: %s)...", opts.URL, pf.navTimeout)
+
+	tracker := StartDocumentTracker(pf.page)
+	defer tracker.Stop()
+	if traceHTTP {
+		defer StartHTTPTrace(pf.page).Stop()
+	}
+	// Failed fetches keep what was seen, so --dump-headers can show the
+	// response of a page that was refused
+	defer func() {
+		pf.response = tracker.Response()
+		pf.redirects = tracker.Redirects()
+	}()
+
+	budget, err := activeResourceBudget(pf.page)
+	if err != nil {
+		return "", err
+	}
+	if budget != nil {
+		defer budget.Stop()
+	}
 
-	logger.Verbose("Navigating to %s (timeout: %ds)...", opts.URL, opts.Timeout)
+	if err := applyCookies(pf.page); err != nil {
+		return "", err
+	}
+
+	if len(opts.Headers) > 0 {
+		restore, err := setExtraHeaders(pf.page, opts.Headers)
+		if err != nil {
+			return "", err
+		}
+		defer restore()
+	}
 
 	// Apply timeout to long-running operations (navigation, wait-for) using inline .Timeout()
 	// This creates temporary timeout clones that don't affect subsequent fast operations
 	// (HTML extraction, auth detection), preventing cumulative timeout issues
-	err := pf.page.Timeout(pf.timeout).Navigate(opts.URL)
+	err = pf.page.Timeout(pf.navTimeout).Navigate(opts.URL)
+	if budgetErr := pf.checkBudget(budget); budgetErr != nil {
+		return "", budgetErr
+	}
+	if err != nil && errors.Is(err, context.DeadlineExceeded) && bestEffort && pf.hasDocument() {
+		logger.Warning("Page load timeout exceeded (%s), keeping partial content (--best-effort)", pf.navTimeout)
+		pf.partial = true
+		if err := (proto.PageStopLoading{}).Call(pf.page); err != nil {
+			logger.Debug("Failed to stop page loading: %v", err)
+		}
+		err = nil
+	}
 	if err != nil {
 		if errors.Is(err, context.DeadlineExceeded) {
-			logger.Error("Page load timeout exceeded (%ds)", opts.Timeout)
+			logger.Error("Page load timeout exceeded (%s)", pf.navTimeout)
 			logger.ErrorWithSuggestion(
 				"The page took too long to load",
 				fmt.Sprintf("snag %s --timeout 60", opts.URL),
@@ -63,28 +167,83 @@ func (pf *PageFetcher) Fetch(opts FetchOptions) (string, error) {
 	}
 
 	logger.Verbose("Waiting for page to stabilize...")
-	err = pf.page.WaitStable(StabilizeTimeout)
+	err = pf.page.WaitStable(stabilizeTimeout())
 	if err != nil {
 		logger.Warning("Page did not stabilize: %v", err)
 	}
 
+	if err := pf.checkBudget(budget); err != nil {
+		return "", err
+	}
+
+	pf.status = tracker.Status()
+	pf.headers = tracker.Headers()
+	if showHeaders {
+		reportHeaders(pf.status, pf.headers)
+	}
+	if err := pf.checkStatus(opts.URL); err != nil {
+		return "", err
+	}
+
 	if opts.WaitFor != "" {
-		err := waitForSelector(pf.page, opts.WaitFor, pf.timeout)
+		err := waitForSelector(pf.page, opts.WaitFor, pf.waitTimeout)
+		if err != nil && errors.Is(err, context.DeadlineExceeded) && bestEffort {
+			logger.Warning("Selector %s not found within %s, keeping partial content (--best-effort)", opts.WaitFor, pf.waitTimeout)
+			pf.partial = true
+			err = nil
+		}
 		if err != nil {
 			if errors.Is(err, context.DeadlineExceeded) {
 				logger.ErrorWithSuggestion(
-					fmt.Sprintf("Selector not found within %ds", opts.Timeout),
+					fmt.Sprintf("Selector not found within %s", pf.waitTimeout),
 					fmt.Sprintf("snag --wait-for '%s' --timeout 60 %s", opts.WaitFor, opts.URL),
 				)
 			}
 			return "", err
 		}
+		if err := pf.checkBudget(budget); err != nil {
+			return "", err
+		}
+	}
+
+	if waitForJSExpr != "" {
+		err := waitForJS(pf.page, waitForJSExpr, pf.waitTimeout)
+		if err != nil && errors.Is(err, context.DeadlineExceeded) && bestEffort {
+			logger.Warning("Expression %s not true within %s, keeping partial content (--best-effort)", waitForJSExpr, pf.waitTimeout)
+			pf.partial = true
+			err = nil
+		}
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				logger.ErrorWithSuggestion(
+					fmt.Sprintf("Expression not true within %s", pf.waitTimeout),
+					fmt.Sprintf("snag --wait-for-js '%s' --wait-timeout 60s %s", waitForJSExpr, opts.URL),
+				)
+			}
+			return "", err
+		}
+		if err := pf.checkBudget(budget); err != nil {
+			return "", err
+		}
+	}
+
+	if len(opts.Actions) > 0 {
+		if err := runPageActions(pf.page, opts.Actions, pf.waitTimeout); err != nil {
+			return "", err
+		}
+		if err := pf.checkBudget(budget); err != nil {
+			return "", err
+		}
 	}
 
 	if authErr := pf.detectAuth(); authErr != nil {
 		return "", authErr
 	}
 
+	pf.redirects = tracker.Redirects()
+	pf.canonical = extractCanonical(pf.page)
+	reportDocument(pf.getURL(), pf.redirects, pf.canonical)
+
 	logger.Verbose("Extracting HTML content...")
 	html, err := pf.page.HTML()
 	if err != nil {
@@ -97,22 +256,125 @@ func (pf *PageFetcher) Fetch(opts FetchOptions) (string, error) {
 	return html, nil
 }
 
+// Status returns the HTTP status of the last fetched document, or 0 if it
+// is not known.
+func (pf *PageFetcher) Status() int {
+	return pf.status
+}
+
+// checkStatus logs the document status and checks it against
+// --expect-status, or fails any 4xx or 5xx with --fail-on-error-status.
+// Otherwise only 401 and 403 fail, in detectAuth.
+func (pf *PageFetcher) checkStatus(url string) error {
+	if pf.status == 0 {
+		logger.Debug("HTTP status not available for %s", url)
+		if strings.TrimSpace(expectStatus) != "" {
+			logger.Warning("--expect-status not checked: no HTTP status for %s", url)
+		}
+		if failOnStatus {
+			logger.Warning("--fail-on-error-status not checked: no HTTP status for %s", url)
+		}
+		return nil
+	}
+
+	logger.Verbose("HTTP status: %d", pf.status)
+
+	if spec := strings.TrimSpace(expectStatus); spec != "" {
+		matcher, err := parseStatusMatcher(spec)
+		if err != nil {
+			return err
+		}
+		if !matcher.Matches(pf.status) {
+			logger.Error("Unexpected HTTP status %d (expected %s)", pf.status, spec)
+			return fmt.Errorf("%w: HTTP status %d not in %s", ErrAssertionFailed, pf.status, spec)
+		}
+		return nil
+	}
+
+	if pf.status >= 400 && failOnStatus {
+		logger.Error("Server returned HTTP %d for %s", pf.status, url)
+		return fmt.Errorf("%w: HTTP %d", ErrHTTPStatus, pf.status)
+	}
+	if pf.status >= 400 && pf.status != 401 && pf.status != 403 {
+		logger.Warning("Server returned HTTP %d", pf.status)
+	}
+	return nil
+}
+
+// Partial reports whether the last fetch timed out and, with --best-effort,
+// kept whatever content had loaded.
+func (pf *PageFetcher) Partial() bool {
+	return pf.partial
+}
+
+// hasDocument reports whether navigation got far enough to replace the
+// blank page, so there is partial content worth keeping.
+func (pf *PageFetcher) hasDocument() bool {
+	info, err := pf.page.Info()
+	if err != nil {
+		return false
+	}
+	return info.URL != "" && info.URL != "about:blank"
+}
+
+// checkBudget logs and returns the error of an exceeded resource budget.
+// budget may be nil when no limits are set.
+func (pf *PageFetcher) checkBudget(budget *ResourceBudget) error {
+	if budget == nil {
+		return nil
+	}
+	bytes, requests := budget.Usage()
+	if err := budget.Err(); err != nil {
+		logger.Error("Page exceeded its resource budget after %d requests and %s", requests, formatByteSize(bytes))
+		logger.ErrorWithSuggestion(
+			"The page may be streaming data or loading without end",
+			"snag --max-bytes 200MB --max-requests 2000 <url>",
+		)
+		return err
+	}
+	logger.Debug("Resource usage: %d requests, %s", requests, formatByteSize(bytes))
+	return nil
+}
+
+// Headers returns the response headers of the last fetched document, with
+// lowercase names.
+func (pf *PageFetcher) Headers() map[string]string {
+	return pf.headers
+}
+
+// Redirects returns the redirect chain of the last fetch.
+func (pf *PageFetcher) Redirects() []Redirect {
+	return pf.redirects
+}
+
+// Canonical returns the canonical URL declared by the last fetched page.
+func (pf *PageFetcher) Canonical() string {
+	return pf.canonical
+}
+
 func (pf *PageFetcher) detectAuth() error {
 	if pf.page == nil {
 		return fmt.Errorf("cannot detect auth: page is nil")
 	}
 
-	// SECURITY: This JavaScript is hardcoded and safe. Never accept user-provided
-	// JavaScript for evaluation as it would create XSS vulnerabilities.
-	statusCode, err := pf.page.Eval(`() => {
-		return window.performance?.getEntriesByType?.('navigation')?.[0]?.responseStatus || 0;
-	}`)
+	status := pf.status
+	if status == 0 {
+		// Fall back to the performance API when no response event was seen.
+		// SECURITY: This JavaScript is hardcoded and safe. Never accept user-provided
+		// JavaScript for evaluation as it would create XSS vulnerabilities.
+		statusCode, err := pf.page.Eval(`() => {
+			return window.performance?.getEntriesByType?.('navigation')?.[0]?.responseStatus || 0;
+		}`)
+		if err != nil {
+			// Log but don't fail - this is best-effort auth detection
+			logger.Debug("Failed to get HTTP status via JavaScript: %v", err)
+		} else {
+			status = statusCode.Value.Int()
+		}
+	}
 
-	if err != nil {
-		// Log but don't fail - this is best-effort auth detection
-		logger.Debug("Failed to get HTTP status via JavaScript: %v", err)
-	} else if statusCode.Value.Int() > 0 {
-		status := statusCode.Value.Int()
+	// An explicit --expect-status has already accepted the status
+	if status > 0 && strings.TrimSpace(expectStatus) == "" {
 		logger.Debug("HTTP status code: %d", status)
 
 		if status == 401 || status == 403 {
@@ -193,3 +455,159 @@ func waitForSelector(page *rod.Page, selector string, timeout time.Duration) err
 	logger.Verbose("Selector found: %s", selector)
 	return nil
 }
+
+// WaitForJSInterval is how often --wait-for-js checks its expression.
+const WaitForJSInterval = 100 * time.Millisecond
+
+// waitForJS waits until the JavaScript expression is truthy in page. The
+// expression is polled inside the page, so it is evaluated, and audit
+// logged, as one user script. While it throws, say because the global it
+// reads does not exist yet, it counts as false.
+func waitForJS(page *rod.Page, expression string, timeout time.Duration) error {
+	if page == nil {
+		return fmt.Errorf("cannot wait for expression: page is nil")
+	}
+
+	logger.Verbose("Waiting for expression: %s", expression)
+
+	script := fmt.Sprintf(`new Promise((resolve) => {
+	const deadline = Date.now() + %d;
+	const check = () => {
+		try {
+			if ((() => (%s))()) return resolve(true);
+		} catch (e) {}
+		if (Date.now() >= deadline) return resolve(false);
+		setTimeout(check, %d);
+	};
+	check();
+})`, timeout.Milliseconds(), expression, WaitForJSInterval.Milliseconds())
+
+	res, err := evalUserScript(page, "--wait-for-js", script)
+	if err != nil {
+		return err
+	}
+	if !res.Value.Bool() {
+		logger.Error("Timeout waiting for expression: %s", expression)
+		return fmt.Errorf("timeout waiting for expression %s: %w", expression, context.DeadlineExceeded)
+	}
+
+	logger.Verbose("Expression is true: %s", expression)
+	return nil
+}
+
+// extractInnerText returns the rendered text of the page body, as the
+// browser lays it out: hidden elements are left out and CSS line breaks
+// are kept.
+func extractInnerText(page *rod.Page) (string, error) {
+	if page == nil {
+		return "", fmt.Errorf("cannot extract text: page is nil")
+	}
+
+	logger.Verbose("Extracting rendered text from the DOM...")
+
+	res, err := page.Eval(`() => document.body ? document.body.innerText : ""`)
+	if err != nil {
+		return "", fmt.Errorf("failed to extract text: %w", err)
+	}
+
+	text := res.Value.Str()
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	logger.Debug("Extracted %d bytes of rendered text", len(text))
+	return text, nil
+}
+
+// extractHTMLWithoutActivation reads the document markup through the CDP DOM
+// domain instead of evaluating script in the page. The tab is never focused,
+// so pages that react to focus or visibilitychange keep their current state.
+func extractHTMLWithoutActivation(page *rod.Page) (string, error) {
+	if page == nil {
+		return "", fmt.Errorf("cannot extract HTML: page is nil")
+	}
+
+	logger.Verbose("Reading DOM without activating tab...")
+
+	doc, err := proto.DOMGetDocument{}.Call(page)
+	if err != nil {
+		return "", fmt.Errorf("failed to get document: %w", err)
+	}
+
+	nodeID := doc.Root.NodeID
+	for _, child := range doc.Root.Children {
+		if strings.EqualFold(child.NodeName, "html") {
+			nodeID = child.NodeID
+			break
+		}
+	}
+
+	res, err := proto.DOMGetOuterHTML{NodeID: nodeID}.Call(page)
+	if err != nil {
+		return "", fmt.Errorf("failed to get outer HTML: %w", err)
+	}
+
+	logger.Debug("Read %d bytes of HTML via DOM domain", len(res.OuterHTML))
+	return res.OuterHTML, nil
+}
+
+// extractSelectedHTML returns the outer HTML of every element matching
+// selector, in document order, for --select. Like
+// extractHTMLWithoutActivation it reads the DOM over CDP, so it works with
+// --no-activate and never runs script in the page.
+func extractSelectedHTML(page *rod.Page, selector string) (string, error) {
+	logger.Verbose("Extracting elements matching %s...", selector)
+
+	doc, err := proto.DOMGetDocument{}.Call(page)
+	if err != nil {
+		return "", fmt.Errorf("failed to get document: %w", err)
+	}
+
+	matches, err := proto.DOMQuerySelectorAll{NodeID: doc.Root.NodeID, Selector: selector}.Call(page)
+	if err != nil {
+		logger.Error("Invalid --select selector: %s", selector)
+		return "", fmt.Errorf("invalid selector %s: %w", selector, err)
+	}
+	if len(matches.NodeIDs) == 0 {
+		logger.Error("No element matches --select %s", selector)
+		logger.ErrorWithSuggestion(
+			"Check the selector in the browser's developer tools, or wait for it to render",
+			fmt.Sprintf("snag --wait-for '%s' --select '%s' <url>", selector, selector),
+		)
+		return "", fmt.Errorf("%w: %s", ErrSelectorNotFound, selector)
+	}
+
+	parts := make([]string, 0, len(matches.NodeIDs))
+	for _, nodeID := range matches.NodeIDs {
+		res, err := proto.DOMGetOuterHTML{NodeID: nodeID}.Call(page)
+		if err != nil {
+			return "", fmt.Errorf("failed to get outer HTML: %w", err)
+		}
+		parts = append(parts, res.OuterHTML)
+	}
+
+	logger.Debug("Selected %d element%s matching %s", len(parts), plural(len(parts)), selector)
+	return strings.Join(parts, "\n"), nil
+}
+
+// setExtraHeaders adds headers to every request page makes until the
+// returned function is called. Header values are not logged as they are
+// often credentials.
+func setExtraHeaders(page *rod.Page, headers map[string]string) (func(), error) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	dict := make([]string, 0, len(headers)*2)
+	for _, name := range names {
+		dict = append(dict, name, headers[name])
+	}
+
+	logger.Verbose("Sending extra headers: %s", strings.Join(names, ", "))
+	cleanup, err := page.SetExtraHeaders(dict)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set request headers: %w", err)
+	}
+	return cleanup, nil
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewPageFetcher_PhaseTimeouts(t *testing.T) {
+	defer func() { navTimeout, stabilizeTime, waitTimeout = 0, 0, 0 }()
+
+	pf := NewPageFetcher(nil, 30)
+	if pf.navTimeout != 30*time.Second || pf.waitTimeout != 30*time.Second {
+		t.Errorf("umbrella not applied: nav %s, wait %s", pf.navTimeout, pf.waitTimeout)
+	}
+	if got := stabilizeTimeout(); got != StabilizeTimeout {
+		t.Errorf("stabilizeTimeout() = %s, want %s", got, StabilizeTimeout)
+	}
+
+	navTimeout, stabilizeTime, waitTimeout = 90*time.Second, 500*time.Millisecond, 5*time.Second
+	pf = NewPageFetcher(nil, 30)
+	if pf.navTimeout != 90*time.Second || pf.waitTimeout != 5*time.Second {
+		t.Errorf("overrides not applied: nav %s, wait %s", pf.navTimeout, pf.waitTimeout)
+	}
+	if got := stabilizeTimeout(); got != 500*time.Millisecond {
+		t.Errorf("stabilizeTimeout() = %s, want 500ms", got)
+	}
+}
+
+func TestCheckStatus_FailOnErrorStatus(t *testing.T) {
+	logger = NewLogger(LevelQuiet)
+	defer func() { failOnStatus = false }()
+
+	tests := []struct {
+		status int
+		fail   bool
+		failed bool
+	}{
+		{200, true, false},
+		{304, true, false},
+		{0, true, false},
+		{404, true, true},
+		{503, true, true},
+		{404, false, false},
+	}
+
+	for _, tt := range tests {
+		failOnStatus = tt.fail
+		pf := &PageFetcher{status: tt.status}
+		err := pf.checkStatus("https://example.com/")
+		if got := errors.Is(err, ErrHTTPStatus); got != tt.failed {
+			t.Errorf("status %d with --fail-on-error-status=%v: error %v", tt.status, tt.fail, err)
+		}
+	}
+}
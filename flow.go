@@ -0,0 +1,429 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v3"
+)
+
+// Flow is an ordered list of browser steps loaded from a --flow file:
+//
+//	steps:
+//	  - goto: https://example.com/login
+//	  - fill: { selector: "#email", value: "${SNAG_USER}" }
+//	  - click: "button[type=submit]"
+//	  - wait: ".dashboard"
+//	  - eval: "document.querySelector('.cookie-banner')?.remove()"
+//	  - snag: { format: pdf, name: dashboard.pdf }
+//
+// Before and after hooks run once around the whole flow; snag steps can
+// have their own around saving the page.
+type Flow struct {
+	Before []FlowHook `yaml:"before"`
+	After  []FlowHook `yaml:"after"`
+	Steps  []FlowStep `yaml:"steps"`
+}
+
+// FlowHook is a shell command or a JavaScript snippet run before or after
+// a flow or snag step, such as to refresh a token or index saved files.
+// Exactly one field must be set.
+type FlowHook struct {
+	Run  string `yaml:"run"`
+	Eval string `yaml:"eval"`
+}
+
+// FlowStep is a single flow action. Exactly one field must be set.
+type FlowStep struct {
+	Goto  string    `yaml:"goto"`
+	Click string    `yaml:"click"`
+	Fill  *FlowFill `yaml:"fill"`
+	Wait  string    `yaml:"wait"`
+	Eval  string    `yaml:"eval"`
+	Snag  *FlowSnag `yaml:"snag"`
+}
+
+// FlowFill types a value into the element matching selector. ${VAR} in
+// value is read from the environment so secrets stay out of the flow file.
+type FlowFill struct {
+	Selector string `yaml:"selector"`
+	Value    string `yaml:"value"`
+}
+
+// FlowSnag saves the current page. Name is relative to --output-dir and is
+// generated from the page title when empty.
+type FlowSnag struct {
+	Format string     `yaml:"format"`
+	Name   string     `yaml:"name"`
+	Before []FlowHook `yaml:"before"`
+	After  []FlowHook `yaml:"after"`
+}
+
+// Action returns the name of the step's action, or an error unless exactly
+// one action is set.
+func (s FlowStep) Action() (string, error) {
+	var actions []string
+	if s.Goto != "" {
+		actions = append(actions, "goto")
+	}
+	if s.Click != "" {
+		actions = append(actions, "click")
+	}
+	if s.Fill != nil {
+		actions = append(actions, "fill")
+	}
+	if s.Wait != "" {
+		actions = append(actions, "wait")
+	}
+	if s.Eval != "" {
+		actions = append(actions, "eval")
+	}
+	if s.Snag != nil {
+		actions = append(actions, "snag")
+	}
+
+	switch len(actions) {
+	case 0:
+		return "", fmt.Errorf("step has no action (expected goto, click, fill, wait, eval, or snag)")
+	case 1:
+		return actions[0], nil
+	default:
+		return "", fmt.Errorf("step has multiple actions: %s", strings.Join(actions, ", "))
+	}
+}
+
+// parseFlow decodes and validates flow YAML.
+func parseFlow(data []byte) (*Flow, error) {
+	var flow Flow
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&flow); err != nil {
+		return nil, fmt.Errorf("failed to parse flow: %w", err)
+	}
+
+	if len(flow.Steps) == 0 {
+		return nil, fmt.Errorf("flow has no steps")
+	}
+	if err := validateFlowHooks(flow.Before, "before hook"); err != nil {
+		return nil, err
+	}
+	if err := validateFlowHooks(flow.After, "after hook"); err != nil {
+		return nil, err
+	}
+
+	navigated := false
+	for i, step := range flow.Steps {
+		action, err := step.Action()
+		if err != nil {
+			return nil, fmt.Errorf("step %d: %w", i+1, err)
+		}
+
+		switch action {
+		case "goto":
+			navigated = true
+		case "fill":
+			if step.Fill.Selector == "" {
+				return nil, fmt.Errorf("step %d: fill requires a selector", i+1)
+			}
+		case "snag":
+			if step.Snag.Format != "" {
+				if f := normalizeFormat(step.Snag.Format); f != FormatMarkdown && f != FormatHTML &&
+					f != FormatText && f != FormatReader && f != FormatOrg && f != FormatRST && f != FormatNotebook && f != FormatPDF && f != FormatPNG {
+					return nil, fmt.Errorf("step %d: invalid format '%s'", i+1, step.Snag.Format)
+				}
+			}
+			if step.Snag.Name != "" {
+				if err := validateOutputName(step.Snag.Name); err != nil {
+					return nil, fmt.Errorf("step %d: %w", i+1, err)
+				}
+			}
+			if err := validateFlowHooks(step.Snag.Before, fmt.Sprintf("step %d: before hook", i+1)); err != nil {
+				return nil, err
+			}
+			if err := validateFlowHooks(step.Snag.After, fmt.Sprintf("step %d: after hook", i+1)); err != nil {
+				return nil, err
+			}
+		}
+
+		if action != "goto" && !navigated {
+			return nil, fmt.Errorf("step %d: %s before the first goto", i+1, action)
+		}
+	}
+
+	return &flow, nil
+}
+
+// validateFlowHooks checks that each hook has exactly one of run or eval.
+func validateFlowHooks(hooks []FlowHook, where string) error {
+	for i, hook := range hooks {
+		if (hook.Run == "") == (hook.Eval == "") {
+			return fmt.Errorf("%s %d: expected exactly one of run or eval", where, i+1)
+		}
+	}
+	return nil
+}
+
+// runFlowHooks runs hooks in order, stopping at the first that fails.
+// Shell commands get env added to snag's environment, and their output
+// goes to stderr so it does not mix with page content.
+func runFlowHooks(page *rod.Page, hooks []FlowHook, where string, env []string) error {
+	for i, hook := range hooks {
+		if hook.Eval != "" {
+			if _, err := evalUserScript(page, "--flow "+where+" hook", hook.Eval); err != nil {
+				return err
+			}
+			continue
+		}
+
+		logger.Verbose("Running %s hook %d: %s", where, i+1, hook.Run)
+		cmd := shellCommand(hook.Run)
+		cmd.Env = append(os.Environ(), env...)
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s hook %d failed: %w", where, i+1, err)
+		}
+	}
+	return nil
+}
+
+// shellCommand runs command with the platform's shell.
+func shellCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	return exec.Command("sh", "-c", command)
+}
+
+func loadFlow(path string) (*Flow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		logger.Error("Failed to read flow file: %s", path)
+		return nil, fmt.Errorf("failed to read flow file: %w", err)
+	}
+
+	flow, err := parseFlow(data)
+	if err != nil {
+		logger.Error("Invalid flow file %s: %v", path, err)
+		logger.ErrorWithSuggestion(
+			"Each step needs exactly one of goto, click, fill, wait, eval, or snag",
+			"snag --flow flow.yaml -d output/",
+		)
+		return nil, err
+	}
+
+	return flow, nil
+}
+
+// runFlowStep performs one flow step on page.
+func runFlowStep(page *rod.Page, step FlowStep, outDir string, pageTimeout time.Duration) error {
+	action, _ := step.Action()
+
+	switch action {
+	case "goto":
+		validatedURL, err := validateURL(expandEnvVars(step.Goto))
+		if err != nil {
+			return err
+		}
+		fetcher := NewPageFetcher(page, timeout)
+		_, err = fetcher.Fetch(FetchOptions{URL: validatedURL, Timeout: timeout, Headers: requestHeaders})
+		return err
+
+	case "click":
+		return clickElement(page, step.Click, pageTimeout)
+
+	case "fill":
+		return fillElement(page, step.Fill.Selector, expandEnvVars(step.Fill.Value), pageTimeout)
+
+	case "wait":
+		if d, err := time.ParseDuration(step.Wait); err == nil {
+			logger.Verbose("Waiting %s...", d)
+			time.Sleep(d)
+			return nil
+		}
+		return waitForSelector(page, step.Wait, phaseTimeout(waitTimeout, pageTimeout))
+
+	case "eval":
+		if _, err := evalUserScript(page, "--flow eval step", step.Eval); err != nil {
+			return err
+		}
+		if err := page.WaitStable(stabilizeTimeout()); err != nil {
+			logger.Debug("Page did not stabilize after eval: %v", err)
+		}
+		return nil
+
+	case "snag":
+		stepFormat := FormatMarkdown
+		if step.Snag.Format != "" {
+			stepFormat = normalizeFormat(step.Snag.Format)
+		}
+
+		var outputPath string
+		if step.Snag.Name != "" {
+			var err error
+			outputPath, err = namedOutputPath(outDir, expandEnvVars(step.Snag.Name))
+			if err != nil {
+				return fmt.Errorf("invalid snag name: %w", err)
+			}
+		} else {
+			info, err := page.Info()
+			if err != nil {
+				return fmt.Errorf("failed to get page info: %w", err)
+			}
+			outputPath, err = generateOutputFilename(filenameTitle(page, info.Title, info.URL), info.URL, stepFormat, time.Now(), outDir)
+			if err != nil {
+				return err
+			}
+		}
+
+		hookEnv := []string{"SNAG_OUTPUT_DIR=" + outDir}
+		if len(step.Snag.Before) > 0 || len(step.Snag.After) > 0 {
+			if info, err := page.Info(); err == nil {
+				hookEnv = append(hookEnv, "SNAG_URL="+info.URL)
+			}
+		}
+
+		if err := runFlowHooks(page, step.Snag.Before, "before", hookEnv); err != nil {
+			return err
+		}
+		if err := processPageContent(page, stepFormat, outputPath); err != nil {
+			return err
+		}
+		return runFlowHooks(page, step.Snag.After, "after", append(hookEnv, "SNAG_FILE="+outputPath))
+	}
+
+	return nil
+}
+
+func describeFlowStep(step FlowStep) string {
+	action, _ := step.Action()
+	switch action {
+	case "goto":
+		return "goto " + step.Goto
+	case "click":
+		return "click " + step.Click
+	case "fill":
+		return "fill " + step.Fill.Selector
+	case "wait":
+		return "wait " + step.Wait
+	case "eval":
+		return fmt.Sprintf("eval (%d bytes)", len(step.Eval))
+	case "snag":
+		if step.Snag.Name != "" {
+			return "snag " + step.Snag.Name
+		}
+		return "snag"
+	}
+	return action
+}
+
+// handleFlow runs the steps in the --flow file in a single tab, stopping at
+// the first step that fails.
+func handleFlow(cmd *cobra.Command) error {
+	flow, err := loadFlow(strings.TrimSpace(flowFile))
+	if err != nil {
+		return err
+	}
+
+	outDir := strings.TrimSpace(outputDir)
+	if outDir == "" {
+		outDir = "."
+	}
+
+	if err := validateTimeout(timeout); err != nil {
+		return err
+	}
+
+	if err := validatePort(port); err != nil {
+		return err
+	}
+
+	if err := validateDirectory(outDir); err != nil {
+		return err
+	}
+
+	validatedUserDataDir := ""
+	if cmd.Flags().Changed("user-data-dir") {
+		validatedDir, err := validateUserDataDir(userDataDir)
+		if err != nil {
+			return err
+		}
+		validatedUserDataDir = validatedDir
+	}
+
+	networkConditions, err := validateThrottle(throttle)
+	if err != nil {
+		return err
+	}
+
+	bm := NewBrowserManager(BrowserOptions{
+		Port:          port,
+		ForceHeadless: forceHead,
+		UserAgent:     validateUserAgent(userAgent, cmd.Flags().Changed("user-agent")),
+		UserDataDir:   validatedUserDataDir,
+		Throttle:      networkConditions,
+		ReadOnly:      readOnly,
+		Offline:       offline,
+		RemoteOnly:    k8sPod != "",
+	})
+	browserMutex.Lock()
+	browserManager = bm
+	browserMutex.Unlock()
+	defer func() {
+		bm.Close()
+		browserMutex.Lock()
+		browserManager = nil
+		browserMutex.Unlock()
+	}()
+
+	if _, err := bm.Connect(); err != nil {
+		return err
+	}
+
+	page, err := bm.NewPage()
+	if err != nil {
+		return err
+	}
+	if bm.launchedHeadless || closeTab {
+		defer bm.ClosePage(page)
+	}
+
+	pageTimeout := time.Duration(timeout) * time.Second
+	total := len(flow.Steps)
+	hookEnv := []string{"SNAG_OUTPUT_DIR=" + outDir}
+
+	if err := runFlowHooks(page, flow.Before, "before", hookEnv); err != nil {
+		logger.Error("%v", err)
+		return fmt.Errorf("flow failed before its first step: %w", err)
+	}
+
+	for i, step := range flow.Steps {
+		logger.Info("[%d/%d] %s", i+1, total, describeFlowStep(step))
+
+		if err := runFlowStep(page, step, outDir, pageTimeout); err != nil {
+			logger.Error("[%d/%d] Step failed: %v", i+1, total, err)
+			return fmt.Errorf("flow failed at step %d: %w", i+1, err)
+		}
+	}
+
+	if err := runFlowHooks(page, flow.After, "after", hookEnv); err != nil {
+		logger.Error("%v", err)
+		return fmt.Errorf("flow failed after its last step: %w", err)
+	}
+
+	logger.Success("Flow complete: %d step%s", total, plural(total))
+	return nil
+}
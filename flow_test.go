@@ -0,0 +1,124 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestParseFlow(t *testing.T) {
+	data := []byte(`
+steps:
+  - goto: https://example.com/login
+  - fill: { selector: "#email", value: "me@example.com" }
+  - click: "button[type=submit]"
+  - wait: 2s
+  - eval: "document.querySelector('.banner')?.remove()"
+  - snag: { format: pdf, name: settings.pdf }
+`)
+
+	flow, err := parseFlow(data)
+	if err != nil {
+		t.Fatalf("parseFlow failed: %v", err)
+	}
+
+	want := []string{"goto", "fill", "click", "wait", "eval", "snag"}
+	if len(flow.Steps) != len(want) {
+		t.Fatalf("expected %d steps, got %d", len(want), len(flow.Steps))
+	}
+	for i, step := range flow.Steps {
+		action, err := step.Action()
+		if err != nil || action != want[i] {
+			t.Errorf("step %d: action = %q (%v), want %q", i+1, action, err, want[i])
+		}
+	}
+
+	if flow.Steps[5].Snag.Format != "pdf" || flow.Steps[5].Snag.Name != "settings.pdf" {
+		t.Errorf("unexpected snag step: %+v", flow.Steps[5].Snag)
+	}
+}
+
+func TestParseFlow_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"empty", "steps: []", "no steps"},
+		{"no action", "steps:\n  - {}", "no action"},
+		{"multiple actions", "steps:\n  - goto: https://example.com\n    click: a", "multiple actions"},
+		{"before goto", "steps:\n  - click: a", "before the first goto"},
+		{"fill without selector", "steps:\n  - goto: https://example.com\n  - fill: { value: x }", "requires a selector"},
+		{"bad format", "steps:\n  - goto: https://example.com\n  - snag: { format: docx }", "invalid format"},
+		{"name outside output dir", "steps:\n  - goto: https://example.com\n  - snag: { name: ../secret.md }", "step 2: output name must be a relative path"},
+		{"unknown action", "steps:\n  - scroll: down", "failed to parse"},
+		{"empty hook", "before:\n  - {}\nsteps:\n  - goto: https://example.com", "before hook 1: expected exactly one of run or eval"},
+		{"hook with both", "steps:\n  - goto: https://example.com\n  - snag: { after: [{ run: x, eval: y }] }", "step 2: after hook 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseFlow([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestParseFlow_Hooks(t *testing.T) {
+	data := []byte(`
+before:
+  - run: ./refresh-token.sh
+steps:
+  - goto: https://example.com/report
+  - snag:
+      name: report.md
+      before: [{ eval: "document.querySelector('.banner')?.remove()" }]
+      after: [{ run: 'index-file "$SNAG_FILE"' }]
+after:
+  - run: ./publish.sh
+`)
+
+	flow, err := parseFlow(data)
+	if err != nil {
+		t.Fatalf("parseFlow failed: %v", err)
+	}
+	if len(flow.Before) != 1 || flow.Before[0].Run != "./refresh-token.sh" || len(flow.After) != 1 {
+		t.Errorf("unexpected flow hooks: before %+v, after %+v", flow.Before, flow.After)
+	}
+	snag := flow.Steps[1].Snag
+	if len(snag.Before) != 1 || snag.Before[0].Eval == "" || len(snag.After) != 1 || snag.After[0].Run == "" {
+		t.Errorf("unexpected snag hooks: %+v", snag)
+	}
+}
+
+func TestRunFlowHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands use sh")
+	}
+	logger = NewLogger(LevelQuiet)
+
+	out := filepath.Join(t.TempDir(), "hook.txt")
+	hooks := []FlowHook{{Run: `echo "$SNAG_URL $SNAG_FILE" > ` + out}}
+	env := []string{"SNAG_URL=https://example.com/", "SNAG_FILE=page.md"}
+	assertNoError(t, runFlowHooks(nil, hooks, "after", env))
+
+	data, err := os.ReadFile(out)
+	assertNoError(t, err)
+	if strings.TrimSpace(string(data)) != "https://example.com/ page.md" {
+		t.Errorf("hook saw %q", data)
+	}
+
+	err = runFlowHooks(nil, []FlowHook{{Run: "true"}, {Run: "exit 3"}}, "before", nil)
+	assertError(t, err)
+	assertContains(t, err.Error(), "before hook 2 failed")
+}
@@ -10,6 +10,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/JohannesKaufmann/html-to-markdown/v2/converter"
 	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/base"
@@ -26,6 +28,19 @@ const (
 	BytesPerKB      = 1024.0 // Bytes in a kilobyte
 )
 
+const (
+	TextEngineHTML2Text   = "html2text"
+	TextEngineDOM         = "dom"
+	TextEngineReadability = "readability"
+)
+
+const (
+	EOLLF   = "lf"
+	EOLCRLF = "crlf"
+
+	utf8BOM = "\ufeff"
+)
+
 var markdownConverter = converter.NewConverter(
 	converter.WithPlugins(
 		base.NewBasePlugin(),
@@ -36,7 +51,34 @@ var markdownConverter = converter.NewConverter(
 )
 
 type ContentConverter struct {
-	format string
+	format        string
+	restoreScroll bool
+	pdfA          bool
+	pdfStamp      bool
+	maxHeight     int
+	eol           string
+	bom           bool
+	splitLevel    int
+	maxTokens     int
+	tokenOverflow string
+	textEngine    string
+	citation      string
+	source        string
+	annotate      bool
+	viewportOnly  bool
+	shotSelector  string
+	viewWidth     int
+	viewHeight    int
+	pdfOutline    bool
+	pdfSplitLevel int
+	mainContent   bool
+	pdfSelector   string
+	pdfLayout     PDFLayout
+	fragment      string
+	fragmentOnly  bool
+	sanitize      bool
+	htmlPretty    bool
+	htmlMinify    bool
 }
 
 func NewContentConverter(format string) *ContentConverter {
@@ -46,19 +88,82 @@ func NewContentConverter(format string) *ContentConverter {
 }
 
 func (cc *ContentConverter) Process(html string, outputFile string) error {
+	content, err := cc.Convert(html)
+	if err != nil {
+		return err
+	}
+
+	return cc.ProcessText(content, outputFile)
+}
+
+// ProcessText writes already converted content, applying --include-source,
+// --cite, --redact, --split-by, --max-tokens, --eol, and --bom.
+func (cc *ContentConverter) ProcessText(content string, outputFile string) error {
+	if cc.source != "" {
+		content = cc.source + strings.TrimLeft(content, "\n")
+	}
+	if cc.citation != "" {
+		content = appendCitation(content, cc.citation, cc.format)
+	}
+	content = redactContent(content)
+
+	if cc.splitLevel > 0 && cc.format == FormatMarkdown && outputFile != "" {
+		return cc.writeSections(content, outputFile)
+	}
+
+	if cc.maxTokens > 0 {
+		if cc.tokenOverflow == TokenOverflowSplit && outputFile != "" {
+			return cc.writeTokenChunks(content, outputFile)
+		}
+		content = cc.applyTokenBudget(content)
+	}
+
+	content = cc.encodeText(content)
+
+	if outputFile != "" {
+		return cc.writeToFile(content, outputFile)
+	}
+
+	return cc.writeToStdout(content)
+}
+
+// Convert converts page HTML to the converter's text format.
+func (cc *ContentConverter) Convert(html string) (string, error) {
 	var content string
 	var err error
 
+	if cc.mainContent || (cc.format == FormatText && cc.textEngine == TextEngineReadability) {
+		logger.Verbose("Extracting main content...")
+		html, err = extractMainContent(html)
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrConversionFailed, err)
+		}
+	}
+
 	switch cc.format {
 	case FormatHTML:
 		content = html
 		logger.Verbose("Output format: HTML (passthrough)")
+		if cc.sanitize {
+			content, err = sanitizeHTML(content)
+			if err != nil {
+				return "", fmt.Errorf("%w: %w", ErrConversionFailed, err)
+			}
+		}
+		if cc.htmlPretty {
+			content, err = prettyHTML(content)
+		} else if cc.htmlMinify {
+			content, err = minifyHTML(content)
+		}
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrConversionFailed, err)
+		}
 
 	case FormatMarkdown:
 		logger.Verbose("Converting HTML to Markdown...")
 		content, err = cc.convertToMarkdown(html)
 		if err != nil {
-			return fmt.Errorf("%w: %w", ErrConversionFailed, err)
+			return "", fmt.Errorf("%w: %w", ErrConversionFailed, err)
 		}
 		logger.Debug("Converted to %d bytes of Markdown", len(content))
 
@@ -67,18 +172,70 @@ func (cc *ContentConverter) Process(html string, outputFile string) error {
 		content = cc.extractPlainText(html)
 		logger.Debug("Extracted %d bytes of plain text", len(content))
 
+	case FormatReader:
+		logger.Verbose("Converting HTML to reader text...")
+		content, err = convertHTMLToReader(html)
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrConversionFailed, err)
+		}
+		logger.Debug("Converted to %d bytes of reader text", len(content))
+
+	case FormatNotebook:
+		logger.Verbose("Converting HTML to a notebook...")
+		markdown, err := cc.convertToMarkdown(html)
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrConversionFailed, err)
+		}
+		content, err = markdownToNotebook(markdown)
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrConversionFailed, err)
+		}
+		logger.Debug("Converted to %d bytes of notebook JSON", len(content))
+
+	case FormatOrg, FormatRST:
+		dialect := orgDialect
+		if cc.format == FormatRST {
+			dialect = rstDialect
+		}
+		logger.Verbose("Converting HTML to %s...", cc.format)
+		content, err = convertHTMLToMarkup(html, dialect)
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrConversionFailed, err)
+		}
+		logger.Debug("Converted to %d bytes of %s", len(content), cc.format)
+
 	default:
-		return fmt.Errorf("unsupported format: %s", cc.format)
+		return "", fmt.Errorf("unsupported format: %s", cc.format)
 	}
 
-	if outputFile != "" {
-		return cc.writeToFile(content, outputFile)
+	metrics.AddConvertedBytes(cc.format, len(content))
+	return content, nil
+}
+
+// encodeText applies the --eol line ending and --bom options to converted
+// text. With no --eol, line endings are left as converted.
+func (cc *ContentConverter) encodeText(content string) string {
+	switch cc.eol {
+	case EOLLF:
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+	case EOLCRLF:
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+		content = strings.ReplaceAll(content, "\n", "\r\n")
 	}
 
-	return cc.writeToStdout(content)
+	if cc.bom && !strings.HasPrefix(content, utf8BOM) {
+		content = utf8BOM + content
+	}
+
+	return content
 }
 
 func (cc *ContentConverter) convertToMarkdown(html string) (string, error) {
+	html, err := rewriteAnchors(html)
+	if err != nil {
+		return "", err
+	}
+
 	markdown, err := markdownConverter.ConvertString(html)
 	if err != nil {
 		return "", err
@@ -110,6 +267,10 @@ func (cc *ContentConverter) writeToStdout(content string) error {
 }
 
 func (cc *ContentConverter) writeToFile(content string, filename string) error {
+	if tarOutput != nil {
+		return tarOutput.WriteFile(filename, []byte(content))
+	}
+
 	logger.Verbose("Writing to file: %s", filename)
 
 	if _, err := os.Stat(filename); err == nil {
@@ -122,47 +283,128 @@ func (cc *ContentConverter) writeToFile(content string, filename string) error {
 	}
 
 	sizeKB := float64(len(content)) / BytesPerKB
-	logger.Success("Saved to %s (%.1f KB)", filename, sizeKB)
+	logger.With(LogContext{Output: filename}).Success("Saved to %s (%.1f KB)", filename, sizeKB)
 
 	return nil
 }
 
 func (cc *ContentConverter) ProcessPage(page *rod.Page, outputFile string) error {
+	if cc.format == FormatPDF && cc.pdfSplitLevel > 0 {
+		return cc.writePDFSections(page, outputFile)
+	}
+
+	data, err := cc.Render(page)
+	if err != nil {
+		return err
+	}
+
+	if outputFile != "" {
+		return cc.writeBinaryToFile(data, outputFile)
+	}
+
+	return cc.writeBinaryToStdout(data)
+}
+
+// Render produces the converter's binary format from page.
+func (cc *ContentConverter) Render(page *rod.Page) ([]byte, error) {
 	var data []byte
 	var err error
 
 	switch cc.format {
 	case FormatPDF:
+		switch {
+		case cc.fragmentOnly:
+			restore, err := isolateFragment(page, cc.fragment)
+			if err != nil {
+				return nil, err
+			}
+			defer restore()
+		case cc.pdfSelector != "":
+			restore, err := isolateElement(page, cc.pdfSelector)
+			if err != nil {
+				return nil, err
+			}
+			defer restore()
+		case cc.fragment != "":
+			// Lazy content at the anchor loads before printing
+			scrollToFragment(page, cc.fragment)
+		}
+
 		logger.Verbose("Generating PDF...")
 		data, err = cc.generatePDF(page)
 		if err != nil {
-			return fmt.Errorf("failed to generate PDF: %w", err)
+			return nil, fmt.Errorf("failed to generate PDF: %w", err)
 		}
 		logger.Debug("Generated %d bytes of PDF", len(data))
 
+		if cc.pdfA {
+			data, err = cc.applyPDFA(data)
+			if err != nil {
+				return nil, err
+			}
+		}
+
 	case FormatPNG:
 		logger.Verbose("Capturing PNG screenshot...")
-		data, err = cc.captureScreenshot(page)
+		if cc.viewWidth > 0 && cc.viewHeight > 0 {
+			restore, err := setScreenshotViewport(page, cc.viewWidth, cc.viewHeight)
+			if err != nil {
+				return nil, err
+			}
+			defer restore()
+		}
+
+		captured := time.Now()
+		switch {
+		case cc.fragmentOnly:
+			data, err = captureFragmentScreenshot(page, cc.fragment, cc.maxHeight)
+		case cc.shotSelector != "":
+			data, err = captureElementScreenshot(page, cc.shotSelector, cc.maxHeight)
+		default:
+			if cc.viewportOnly && cc.fragment != "" {
+				scrollToFragment(page, cc.fragment)
+			}
+			data, err = cc.captureScreenshot(page)
+		}
 		if err != nil {
-			return fmt.Errorf("failed to capture PNG screenshot: %w", err)
+			return nil, fmt.Errorf("failed to capture PNG screenshot: %w", err)
+		}
+
+		if cc.annotate {
+			data, err = annotateScreenshot(page, data, captured)
+			if err != nil {
+				return nil, fmt.Errorf("failed to annotate PNG screenshot: %w", err)
+			}
 		}
 		logger.Debug("Captured %d bytes of PNG", len(data))
 
 	default:
-		return fmt.Errorf("unsupported binary format: %s", cc.format)
+		return nil, fmt.Errorf("unsupported binary format: %s", cc.format)
 	}
 
-	if outputFile != "" {
-		return cc.writeBinaryToFile(data, outputFile)
-	}
-
-	return cc.writeBinaryToStdout(data)
+	metrics.AddConvertedBytes(cc.format, len(data))
+	return data, nil
 }
 
 func (cc *ContentConverter) generatePDF(page *rod.Page) ([]byte, error) {
-	stream, err := page.PDF(&proto.PagePrintToPDF{
+	req := &proto.PagePrintToPDF{
 		PrintBackground: true,
-	})
+	}
+
+	// Chromium builds the outline from the heading structure of a tagged PDF
+	headings := 0
+	if cc.pdfOutline {
+		req.GenerateTaggedPDF = true
+		req.GenerateDocumentOutline = true
+		headings = countHeadings(page)
+	}
+
+	cc.pdfLayout.apply(req)
+	if cc.pdfStamp {
+		applyPDFStamp(req, time.Now())
+	}
+
+	stream, err := page.PDF(req)
 	if err != nil {
 		return nil, fmt.Errorf("PDF generation failed: %w", err)
 	}
@@ -172,12 +414,94 @@ func (cc *ContentConverter) generatePDF(page *rod.Page) ([]byte, error) {
 		return nil, fmt.Errorf("failed to read PDF data: %w", err)
 	}
 
+	if headings > 0 {
+		if hasPDFOutline(pdfData) {
+			logger.Verbose("Added PDF outline from %d heading%s", headings, plural(headings))
+		} else {
+			logger.Warning("Browser did not add a PDF outline, bookmarks need a recent Chrome or Chromium")
+		}
+	}
+
 	return pdfData, nil
 }
 
+// applyPDFA converts data to PDF/A for --pdf-a. It fails rather than fall
+// back to the standard PDF, which would not be fit for archiving.
+func (cc *ContentConverter) applyPDFA(data []byte) ([]byte, error) {
+	logger.Verbose("Converting PDF to PDF/A...")
+
+	converted, err := convertToPDFA(data)
+	if err != nil {
+		logger.Error("PDF/A conversion failed: %v", err)
+		logger.ErrorWithSuggestion(
+			"--pdf-a needs Ghostscript 9.50 or later; check it converts on its own",
+			"gs --version",
+		)
+		return nil, fmt.Errorf("failed to convert PDF to PDF/A: %w", err)
+	}
+
+	if issues := checkPDFACompliance(converted); len(issues) > 0 {
+		for _, issue := range issues {
+			logger.Warning("PDF/A compliance not achieved: %s", issue)
+		}
+	} else {
+		logger.Verbose("PDF/A validation passed")
+	}
+
+	return converted, nil
+}
+
 func (cc *ContentConverter) captureScreenshot(page *rod.Page) ([]byte, error) {
+	if cc.viewportOnly {
+		logger.Debug("Capturing the visible viewport only")
+		screenshotData, err := page.Screenshot(false, &proto.PageCaptureScreenshot{
+			Format: proto.PageCaptureScreenshotFormatPng,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("screenshot capture failed: %w", err)
+		}
+		return screenshotData, nil
+	}
+
+	// Existing tabs may have been scrolled by the user. Full-page capture
+	// resizes the viewport to the content size, so start from the top to
+	// avoid an offset or truncated image.
+	x, y := getScrollPosition(page)
+	if x != 0 || y != 0 {
+		logger.Debug("Page scrolled to (%d, %d), scrolling to top for capture", x, y)
+		scrollTo(page, 0, 0)
+		if cc.restoreScroll {
+			defer func() {
+				logger.Verbose("Restoring scroll position (%d, %d)...", x, y)
+				scrollTo(page, x, y)
+			}()
+		}
+	}
+
+	width, height, err := getContentSize(page)
+	if err != nil {
+		return nil, fmt.Errorf("screenshot capture failed: %w", err)
+	}
+
+	truncated := false
+	if cc.maxHeight > 0 && height > float64(cc.maxHeight) {
+		logger.Warning("Page height %.0fpx exceeds --max-height, truncating screenshot to %dpx", height, cc.maxHeight)
+		height = float64(cc.maxHeight)
+		truncated = true
+	}
+
+	if height > MaxScreenshotTileHeight || truncated {
+		logger.Verbose("Capturing %.0fx%.0f screenshot in tiles...", width, height)
+		screenshotData, err := captureTiledScreenshot(page, width, height)
+		if err != nil {
+			return nil, fmt.Errorf("screenshot capture failed: %w", err)
+		}
+		return screenshotData, nil
+	}
+
 	screenshotData, err := page.Screenshot(true, &proto.PageCaptureScreenshot{
-		Format: proto.PageCaptureScreenshotFormatPng,
+		Format:                proto.PageCaptureScreenshotFormatPng,
+		CaptureBeyondViewport: true,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("screenshot capture failed: %w", err)
@@ -186,6 +510,21 @@ func (cc *ContentConverter) captureScreenshot(page *rod.Page) ([]byte, error) {
 	return screenshotData, nil
 }
 
+func getScrollPosition(page *rod.Page) (int, int) {
+	res, err := page.Eval(`() => ({x: Math.round(window.scrollX), y: Math.round(window.scrollY)})`)
+	if err != nil {
+		logger.Debug("Failed to get scroll position: %v", err)
+		return 0, 0
+	}
+	return res.Value.Get("x").Int(), res.Value.Get("y").Int()
+}
+
+func scrollTo(page *rod.Page, x, y int) {
+	if _, err := page.Eval(`(x, y) => window.scrollTo(x, y)`, x, y); err != nil {
+		logger.Debug("Failed to scroll to (%d, %d): %v", x, y, err)
+	}
+}
+
 func (cc *ContentConverter) writeBinaryToStdout(data []byte) error {
 	logger.Verbose("Writing binary data to stdout...")
 
@@ -200,6 +539,10 @@ func (cc *ContentConverter) writeBinaryToStdout(data []byte) error {
 }
 
 func (cc *ContentConverter) writeBinaryToFile(data []byte, filename string) error {
+	if tarOutput != nil {
+		return tarOutput.WriteFile(filename, data)
+	}
+
 	logger.Verbose("Writing binary data to file: %s", filename)
 
 	if _, err := os.Stat(filename); err == nil {
@@ -212,7 +555,7 @@ func (cc *ContentConverter) writeBinaryToFile(data []byte, filename string) erro
 	}
 
 	sizeKB := float64(len(data)) / BytesPerKB
-	logger.Success("Saved to %s (%.1f KB)", filename, sizeKB)
+	logger.With(LogContext{Output: filename}).Success("Saved to %s (%.1f KB)", filename, sizeKB)
 
 	return nil
 }
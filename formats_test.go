@@ -632,3 +632,29 @@ func TestExtractPlainText_HiddenElements(t *testing.T) {
 		t.Error("should not contain JavaScript")
 	}
 }
+
+func TestEncodeText(t *testing.T) {
+	tests := []struct {
+		name     string
+		eol      string
+		bom      bool
+		input    string
+		expected string
+	}{
+		{"unchanged", "", false, "a\r\nb\nc", "a\r\nb\nc"},
+		{"lf", EOLLF, false, "a\r\nb\nc", "a\nb\nc"},
+		{"crlf", EOLCRLF, false, "a\r\nb\nc\n", "a\r\nb\r\nc\r\n"},
+		{"bom", "", true, "# Title\n", "\ufeff# Title\n"},
+		{"bom not doubled", "", true, "\ufeffx", "\ufeffx"},
+		{"crlf with bom", EOLCRLF, true, "a\nb", "\ufeffa\r\nb"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cc := &ContentConverter{format: FormatMarkdown, eol: tt.eol, bom: tt.bom}
+			if got := cc.encodeText(tt.input); got != tt.expected {
+				t.Errorf("encodeText(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
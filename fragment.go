@@ -0,0 +1,233 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+
+	"github.com/go-rod/rod"
+)
+
+// fragmentSectionScript finds the element a URL fragment names, by id or
+// by the name of an <a name> anchor, and the section it starts. A heading,
+// or an empty anchor at a heading, starts a section that runs to the next
+// heading of the same or a higher level; any other element is its own
+// section. mode says what to do with it:
+//
+//	scroll: scroll it to the top of the viewport
+//	html:   return the section's HTML, or the outer HTML of the elements
+//	        matching selector within it
+//	hide:   hide everything else, as pdfSelectorScript does
+//	box:    return the section's box relative to the top of the page
+//
+// It returns null when the fragment names no element.
+const fragmentSectionScript = `(fragment, mode, selector) => {
+	let target = document.getElementById(fragment) || document.getElementsByName(fragment)[0];
+	if (!target) {
+		return null;
+	}
+
+	const isHeading = (el) => el && /^H[1-6]$/.test(el.tagName);
+	if (!isHeading(target) && target.textContent.trim() === '') {
+		if (isHeading(target.parentElement)) {
+			target = target.parentElement;
+		} else if (isHeading(target.nextElementSibling)) {
+			target = target.nextElementSibling;
+		}
+	}
+
+	const range = document.createRange();
+	if (isHeading(target)) {
+		const level = Number(target.tagName[1]);
+		const headingSelector = ['h1', 'h2', 'h3', 'h4', 'h5', 'h6'].slice(0, level).join(', ');
+		const headings = [...document.body.querySelectorAll(headingSelector)];
+		const next = headings[headings.indexOf(target) + 1];
+		range.setStartBefore(target);
+		if (next) {
+			range.setEndBefore(next);
+		} else {
+			range.setEnd(document.body, document.body.childNodes.length);
+		}
+	} else {
+		range.selectNode(target);
+	}
+
+	if (mode === 'scroll') {
+		target.scrollIntoView({block: 'start'});
+		return true;
+	}
+
+	if (mode === 'html') {
+		if (!selector) {
+			const container = document.createElement('div');
+			container.appendChild(range.cloneContents());
+			return [container.innerHTML];
+		}
+		const contains = (el) => {
+			const node = document.createRange();
+			node.selectNode(el);
+			return range.compareBoundaryPoints(Range.START_TO_START, node) <= 0 &&
+				range.compareBoundaryPoints(Range.END_TO_END, node) >= 0;
+		};
+		return [...document.querySelectorAll(selector)].filter(contains).map(el => el.outerHTML);
+	}
+
+	if (mode === 'hide') {
+		const style = document.createElement('style');
+		style.id = 'snag-pdf-hide';
+		style.textContent = '.snag-pdf-hidden { display: none !important; }';
+		document.head.appendChild(style);
+
+		const hide = (el) => {
+			for (const child of el.children) {
+				if (!range.intersectsNode(child)) {
+					child.classList.add('snag-pdf-hidden');
+				} else {
+					hide(child);
+				}
+			}
+		};
+		hide(document.body);
+		return true;
+	}
+
+	target.scrollIntoView({block: 'nearest'});
+	const rect = range.getBoundingClientRect();
+	return {x: rect.left + window.scrollX, y: rect.top + window.scrollY, width: rect.width, height: rect.height};
+}`
+
+// urlFragment returns the anchor a URL's fragment names, or "" when there
+// is none. Fragments that look like client-side routes (#!/inbox, #/inbox)
+// or parameters (#access_token=...) are not anchors, and the text
+// fragment directive (#:~:text=...) is dropped.
+func urlFragment(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	fragment, _, _ := strings.Cut(u.Fragment, ":~:")
+	if fragment == "" || strings.HasPrefix(fragment, "!") || strings.HasPrefix(fragment, "/") || strings.Contains(fragment, "=") {
+		return ""
+	}
+	return fragment
+}
+
+// pageFragment returns the anchor of page's current URL.
+func pageFragment(page *rod.Page) string {
+	info, err := page.Info()
+	if err != nil {
+		logger.Debug("Failed to get page URL for fragment: %v", err)
+		return ""
+	}
+	return urlFragment(info.URL)
+}
+
+// scrollToFragment scrolls the element fragment names to the top of the
+// viewport, so a capture shows what the deep link points at.
+func scrollToFragment(page *rod.Page, fragment string) {
+	res, err := page.Eval(fragmentSectionScript, fragment, "scroll", "")
+	if err != nil {
+		logger.Debug("Failed to scroll to #%s: %v", fragment, err)
+		return
+	}
+	if res.Value.Nil() {
+		logger.Warning("No element matches #%s, capturing from the top of the page", fragment)
+		return
+	}
+	logger.Verbose("Scrolled to #%s", fragment)
+}
+
+// fragmentNotFound logs and returns the error for --fragment-only when the
+// fragment names no element.
+func fragmentNotFound(fragment string) error {
+	logger.Error("No element matches #%s for --fragment-only", fragment)
+	logger.ErrorWithSuggestion(
+		"Check the id in the browser's developer tools, or wait for it to render",
+		fmt.Sprintf("snag --wait-for '#%s' --fragment-only <url>", fragment),
+	)
+	return fmt.Errorf("%w: #%s", ErrSelectorNotFound, fragment)
+}
+
+// extractFragmentHTML returns the HTML of the section fragment names for
+// --fragment-only, or with a --select selector only the matching elements
+// within it.
+func extractFragmentHTML(page *rod.Page, fragment, selector string) (string, error) {
+	logger.Verbose("Extracting section #%s...", fragment)
+
+	res, err := page.Eval(fragmentSectionScript, fragment, "html", selector)
+	if err != nil {
+		if selector != "" {
+			logger.Error("Invalid --select selector: %s", selector)
+			return "", fmt.Errorf("invalid selector %s: %w", selector, err)
+		}
+		return "", fmt.Errorf("failed to extract #%s: %w", fragment, err)
+	}
+	if res.Value.Nil() {
+		return "", fragmentNotFound(fragment)
+	}
+
+	var parts []string
+	for _, part := range res.Value.Arr() {
+		parts = append(parts, part.Str())
+	}
+	if len(parts) == 0 {
+		logger.Error("No element in #%s matches --select %s", fragment, selector)
+		return "", fmt.Errorf("%w: %s in #%s", ErrSelectorNotFound, selector, fragment)
+	}
+	return strings.Join(parts, "\n"), nil
+}
+
+// isolateFragment hides everything on page but the section fragment names,
+// for PDF output with --fragment-only. Call the returned function to show
+// the page again.
+func isolateFragment(page *rod.Page, fragment string) (func(), error) {
+	res, err := page.Eval(fragmentSectionScript, fragment, "hide", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to isolate #%s: %w", fragment, err)
+	}
+	if res.Value.Nil() {
+		return nil, fragmentNotFound(fragment)
+	}
+
+	logger.Verbose("Printing only #%s", fragment)
+	return func() {
+		if _, err := page.Eval(pdfRestoreScript); err != nil {
+			logger.Debug("Failed to restore page after --fragment-only: %v", err)
+		}
+	}, nil
+}
+
+// captureFragmentScreenshot captures the section fragment names for PNG
+// output with --fragment-only, as captureElementScreenshot does for an
+// element.
+func captureFragmentScreenshot(page *rod.Page, fragment string, maxHeight int) ([]byte, error) {
+	logger.Verbose("Capturing section #%s...", fragment)
+
+	res, err := page.Eval(fragmentSectionScript, fragment, "box", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find #%s: %w", fragment, err)
+	}
+	if res.Value.Nil() {
+		return nil, fragmentNotFound(fragment)
+	}
+
+	x, y := res.Value.Get("x").Num(), res.Value.Get("y").Num()
+	width, height := math.Ceil(res.Value.Get("width").Num()), math.Ceil(res.Value.Get("height").Num())
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("section #%s has no size (is it hidden?)", fragment)
+	}
+	if maxHeight > 0 && height > float64(maxHeight) {
+		logger.Warning("Section height %.0fpx exceeds --max-height, truncating screenshot to %dpx", height, maxHeight)
+		height = float64(maxHeight)
+	}
+
+	return captureTiledRegion(page, x, y, width, height)
+}
@@ -0,0 +1,31 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import "testing"
+
+func TestURLFragment(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://example.com/docs#install", "install"},
+		{"https://example.com/docs#caf%C3%A9", "café"},
+		{"https://example.com/docs#usage:~:text=flags", "usage"},
+		{"https://example.com/docs", ""},
+		{"https://example.com/docs#", ""},
+		{"https://example.com/app#!/inbox", ""},
+		{"https://example.com/app#/settings", ""},
+		{"https://example.com/callback#access_token=abc", ""},
+		{"https://example.com/docs#:~:text=flags", ""},
+	}
+	for _, tt := range tests {
+		if got := urlFragment(tt.url); got != tt.want {
+			t.Errorf("urlFragment(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,441 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// GRPCChunkSize is the maximum number of content bytes sent in each
+// streamed Chunk message.
+const GRPCChunkSize = 64 * 1024
+
+// snagProtoFile mirrors proto/snag/v1/snag.proto. The descriptor is built
+// here rather than generated by protoc so the build needs no code generation
+// step; messages are handled with dynamicpb and are wire compatible with
+// clients generated from the .proto file.
+var snagProtoFile = mustBuildSnagProtoFile()
+
+func mustBuildSnagProtoFile() protoreflect.FileDescriptor {
+	str := descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()
+	byt := descriptorpb.FieldDescriptorProto_TYPE_BYTES.Enum()
+	i32 := descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum()
+	msg := descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	repeated := descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+
+	field := func(name string, number int32, typ *descriptorpb.FieldDescriptorProto_Type,
+		label *descriptorpb.FieldDescriptorProto_Label, typeName string) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(protoJSONName(name)),
+			Number:   proto.Int32(number),
+			Type:     typ,
+			Label:    label,
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	message := func(name string, fields ...*descriptorpb.FieldDescriptorProto) *descriptorpb.DescriptorProto {
+		return &descriptorpb.DescriptorProto{Name: proto.String(name), Field: fields}
+	}
+	method := func(name, input, output string, serverStreaming bool) *descriptorpb.MethodDescriptorProto {
+		m := &descriptorpb.MethodDescriptorProto{
+			Name:       proto.String(name),
+			InputType:  proto.String(".snag.v1." + input),
+			OutputType: proto.String(".snag.v1." + output),
+		}
+		if serverStreaming {
+			m.ServerStreaming = proto.Bool(true)
+		}
+		return m
+	}
+
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("proto/snag/v1/snag.proto"),
+		Package: proto.String("snag.v1"),
+		Syntax:  proto.String("proto3"),
+		Options: &descriptorpb.FileOptions{
+			GoPackage: proto.String("github.com/grantcarthew/snag/proto/snag/v1;snagv1"),
+		},
+		MessageType: []*descriptorpb.DescriptorProto{
+			message("FetchRequest",
+				field("url", 1, str, optional, ""),
+				field("format", 2, str, optional, ""),
+				field("wait_for", 3, str, optional, ""),
+			),
+			message("ListTabsRequest"),
+			message("Tab",
+				field("index", 1, i32, optional, ""),
+				field("url", 2, str, optional, ""),
+				field("title", 3, str, optional, ""),
+			),
+			message("ListTabsResponse",
+				field("tabs", 1, msg, repeated, ".snag.v1.Tab"),
+			),
+			message("FetchTabRequest",
+				field("tab", 1, str, optional, ""),
+				field("format", 2, str, optional, ""),
+				field("wait_for", 3, str, optional, ""),
+			),
+			message("ConvertRequest",
+				field("html", 1, str, optional, ""),
+				field("format", 2, str, optional, ""),
+			),
+			message("Chunk",
+				field("url", 1, str, optional, ""),
+				field("title", 2, str, optional, ""),
+				field("format", 3, str, optional, ""),
+				field("data", 4, byt, optional, ""),
+			),
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Snag"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				method("Fetch", "FetchRequest", "Chunk", true),
+				method("ListTabs", "ListTabsRequest", "ListTabsResponse", false),
+				method("FetchTab", "FetchTabRequest", "Chunk", true),
+				method("Convert", "ConvertRequest", "Chunk", true),
+			},
+		}},
+	}
+
+	fd, err := protodesc.NewFile(fdp, nil)
+	if err != nil {
+		panic(fmt.Sprintf("invalid snag proto descriptor: %v", err))
+	}
+	return fd
+}
+
+// protoJSONName returns the lowerCamelCase JSON name protoc assigns to a
+// snake_case field.
+func protoJSONName(name string) string {
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+func snagMessage(name string) *dynamicpb.Message {
+	return dynamicpb.NewMessage(snagProtoFile.Messages().ByName(protoreflect.Name(name)))
+}
+
+func getString(m protoreflect.Message, name string) string {
+	return m.Get(m.Descriptor().Fields().ByName(protoreflect.Name(name))).String()
+}
+
+func setField(m protoreflect.Message, name string, v protoreflect.Value) {
+	m.Set(m.Descriptor().Fields().ByName(protoreflect.Name(name)), v)
+}
+
+// snagServiceServer is the handler type for the snag.v1.Snag service.
+type snagServiceServer interface {
+	Fetch(req protoreflect.Message, stream grpc.ServerStream) error
+	ListTabs(ctx context.Context, req protoreflect.Message) (protoreflect.Message, error)
+	FetchTab(req protoreflect.Message, stream grpc.ServerStream) error
+	Convert(req protoreflect.Message, stream grpc.ServerStream) error
+}
+
+// snagServiceDesc describes the snag.v1.Snag service to grpc-go, taking the
+// place of the protoc-gen-go-grpc generated code.
+var snagServiceDesc = grpc.ServiceDesc{
+	ServiceName: "snag.v1.Snag",
+	HandlerType: (*snagServiceServer)(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "ListTabs",
+		Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+			req := snagMessage("ListTabsRequest")
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, req any) (any, error) {
+				return srv.(snagServiceServer).ListTabs(ctx, req.(*dynamicpb.Message))
+			}
+			if interceptor == nil {
+				return handler(ctx, req)
+			}
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/snag.v1.Snag/ListTabs"}
+			return interceptor(ctx, req, info, handler)
+		},
+	}},
+	Streams: []grpc.StreamDesc{
+		serverStreamDesc("Fetch", "FetchRequest", snagServiceServer.Fetch),
+		serverStreamDesc("FetchTab", "FetchTabRequest", snagServiceServer.FetchTab),
+		serverStreamDesc("Convert", "ConvertRequest", snagServiceServer.Convert),
+	},
+	Metadata: "proto/snag/v1/snag.proto",
+}
+
+func serverStreamDesc(name, request string,
+	fn func(snagServiceServer, protoreflect.Message, grpc.ServerStream) error) grpc.StreamDesc {
+	return grpc.StreamDesc{
+		StreamName:    name,
+		ServerStreams: true,
+		Handler: func(srv any, stream grpc.ServerStream) error {
+			req := snagMessage(request)
+			if err := stream.RecvMsg(req); err != nil {
+				return err
+			}
+			return fn(srv.(snagServiceServer), req, stream)
+		},
+	}
+}
+
+// GRPCServer implements the snag.v1.Snag service on top of the serve
+// command's browser.
+type GRPCServer struct {
+	bm *BrowserManager
+}
+
+// startGRPCServer serves the snag gRPC service on addr in the background.
+// The listener is opened synchronously so a bad address or busy port is
+// reported before the HTTP server starts.
+func startGRPCServer(addr string, bm *BrowserManager) (*grpc.Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		logger.Error("Failed to listen on %s: %v", addr, err)
+		logger.ErrorWithSuggestion(
+			"Choose a free host:port for the gRPC service",
+			"snag serve --grpc-listen 127.0.0.1:9090",
+		)
+		return nil, fmt.Errorf("failed to start gRPC server: %w", err)
+	}
+
+	s := newGRPCServer(bm)
+	go func() {
+		if err := s.Serve(ln); err != nil {
+			logger.Error("gRPC server failed: %v", err)
+		}
+	}()
+
+	logger.Success("Listening for gRPC on %s", ln.Addr())
+	return s, nil
+}
+
+func newGRPCServer(bm *BrowserManager) *grpc.Server {
+	s := grpc.NewServer()
+	s.RegisterService(&snagServiceDesc, &GRPCServer{bm: bm})
+
+	// Reflection lets clients such as grpcurl discover the service
+	if _, err := protoregistry.GlobalFiles.FindFileByPath(snagProtoFile.Path()); err != nil {
+		if err := protoregistry.GlobalFiles.RegisterFile(snagProtoFile); err != nil {
+			logger.Debug("Failed to register snag proto for reflection: %v", err)
+		}
+	}
+	reflection.Register(s)
+
+	return s
+}
+
+// requestFormat normalizes and validates a format from a request, defaulting
+// to Markdown.
+func requestFormat(value string) (string, error) {
+	f, err := remoteFormat(value)
+	if err != nil {
+		return "", status.Error(codes.InvalidArgument, err.Error())
+	}
+	return f, nil
+}
+
+func (g *GRPCServer) Fetch(req protoreflect.Message, stream grpc.ServerStream) error {
+	outputFormat, err := requestFormat(getString(req, "format"))
+	if err != nil {
+		return err
+	}
+
+	validated, err := remoteURL(getString(req, "url"))
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	page, err := g.bm.NewPage()
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "failed to create page: %v", err)
+	}
+	defer g.bm.ClosePage(page)
+
+	fetcher := NewPageFetcher(page, timeout)
+	_, err = fetcher.Fetch(FetchOptions{
+		URL:     validated,
+		Timeout: timeout,
+		WaitFor: strings.TrimSpace(getString(req, "wait_for")),
+	})
+	if err != nil {
+		return grpcFetchError(err)
+	}
+
+	return g.streamPage(page, outputFormat, stream)
+}
+
+func (g *GRPCServer) ListTabs(ctx context.Context, req protoreflect.Message) (protoreflect.Message, error) {
+	tabs, err := g.bm.ListTabs()
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "failed to list tabs: %v", err)
+	}
+
+	resp := snagMessage("ListTabsResponse")
+	list := resp.Mutable(resp.Descriptor().Fields().ByName("tabs")).List()
+	for _, t := range tabs {
+		tab := snagMessage("Tab")
+		setField(tab, "index", protoreflect.ValueOfInt32(int32(t.Index)))
+		setField(tab, "url", protoreflect.ValueOfString(t.URL))
+		setField(tab, "title", protoreflect.ValueOfString(t.Title))
+		list.Append(protoreflect.ValueOfMessage(tab))
+	}
+
+	return resp, nil
+}
+
+func (g *GRPCServer) FetchTab(req protoreflect.Message, stream grpc.ServerStream) error {
+	outputFormat, err := requestFormat(getString(req, "format"))
+	if err != nil {
+		return err
+	}
+
+	tabValue := strings.TrimSpace(getString(req, "tab"))
+	if tabValue == "" {
+		return status.Error(codes.InvalidArgument, "tab cannot be empty")
+	}
+
+	var page *rod.Page
+	if tabIndex, convErr := strconv.Atoi(tabValue); convErr == nil {
+		page, err = g.bm.GetTabByIndex(tabIndex)
+	} else {
+		page, err = g.bm.GetTabByPattern(tabValue)
+	}
+	if err != nil {
+		if errors.Is(err, ErrTabIndexInvalid) || errors.Is(err, ErrNoTabMatch) {
+			return status.Error(codes.NotFound, err.Error())
+		}
+		return status.Errorf(codes.Unavailable, "failed to find tab: %v", err)
+	}
+
+	if selector := strings.TrimSpace(getString(req, "wait_for")); selector != "" {
+		if err := waitForSelector(page, selector, time.Duration(timeout)*time.Second); err != nil {
+			return grpcFetchError(err)
+		}
+	}
+
+	return g.streamPage(page, outputFormat, stream)
+}
+
+func (g *GRPCServer) Convert(req protoreflect.Message, stream grpc.ServerStream) error {
+	outputFormat, err := requestFormat(getString(req, "format"))
+	if err != nil {
+		return err
+	}
+
+	html := getString(req, "html")
+
+	if outputFormat != FormatPDF && outputFormat != FormatPNG {
+		content, err := newPageConverter(outputFormat).Convert(html)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		return sendChunks(stream, "", "", outputFormat, []byte(content))
+	}
+
+	// Binary formats need the HTML rendered in a tab
+	if g.bm == nil {
+		return status.Errorf(codes.Unavailable, "no browser available to render %s", outputFormat)
+	}
+	page, err := g.bm.NewPage()
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "failed to create page: %v", err)
+	}
+	defer g.bm.ClosePage(page)
+
+	if err := page.SetDocumentContent(html); err != nil {
+		return status.Errorf(codes.Internal, "failed to load HTML: %v", err)
+	}
+	if err := page.WaitStable(stabilizeTimeout()); err != nil {
+		logger.Debug("Page did not stabilize: %v", err)
+	}
+
+	data, err := newPageConverter(outputFormat).Render(page)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return sendChunks(stream, "", "", outputFormat, data)
+}
+
+// streamPage converts page to outputFormat and streams it to the client.
+func (g *GRPCServer) streamPage(page *rod.Page, outputFormat string, stream grpc.ServerStream) error {
+	info, err := page.Info()
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to get page info: %v", err)
+	}
+
+	data, err := pageContent(page, outputFormat)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	return sendChunks(stream, info.URL, info.Title, outputFormat, data)
+}
+
+// sendChunks streams data in GRPCChunkSize pieces. The metadata is sent with
+// the first chunk, which is sent even when data is empty.
+func sendChunks(stream grpc.ServerStream, url, title, outputFormat string, data []byte) error {
+	first := true
+	for first || len(data) > 0 {
+		if err := stream.Context().Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+
+		n := min(len(data), GRPCChunkSize)
+		chunk := snagMessage("Chunk")
+		if first {
+			setField(chunk, "url", protoreflect.ValueOfString(url))
+			setField(chunk, "title", protoreflect.ValueOfString(title))
+			setField(chunk, "format", protoreflect.ValueOfString(outputFormat))
+			first = false
+		}
+		setField(chunk, "data", protoreflect.ValueOfBytes(data[:n]))
+
+		if err := stream.SendMsg(chunk); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// grpcFetchError maps a page load error to a gRPC status.
+func grpcFetchError(err error) error {
+	switch failureReason(err) {
+	case "timeout":
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case "auth":
+		return status.Error(codes.PermissionDenied, err.Error())
+	default:
+		return status.Error(codes.Unavailable, err.Error())
+	}
+}
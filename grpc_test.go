@@ -0,0 +1,188 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// dialTestGRPC starts the snag gRPC service without a browser on an
+// in-memory listener and returns a client connection to it.
+func dialTestGRPC(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+
+	ln := bufconn.Listen(1 << 20)
+	s := newGRPCServer(nil)
+	go s.Serve(ln)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return ln.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// callConvert invokes Convert and returns every chunk received.
+func callConvert(t *testing.T, conn *grpc.ClientConn, html, outputFormat string) ([]protoreflect.Message, error) {
+	t.Helper()
+
+	stream, err := conn.NewStream(context.Background(), &snagServiceDesc.Streams[2], "/snag.v1.Snag/Convert")
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+
+	req := snagMessage("ConvertRequest")
+	setField(req, "html", protoreflect.ValueOfString(html))
+	setField(req, "format", protoreflect.ValueOfString(outputFormat))
+	if err := stream.SendMsg(req); err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatalf("failed to close send: %v", err)
+	}
+
+	var chunks []protoreflect.Message
+	for {
+		chunk := snagMessage("Chunk")
+		err := stream.RecvMsg(chunk)
+		if errors.Is(err, io.EOF) {
+			return chunks, nil
+		}
+		if err != nil {
+			return chunks, err
+		}
+		chunks = append(chunks, chunk)
+	}
+}
+
+func chunkData(chunks []protoreflect.Message) []byte {
+	var buf bytes.Buffer
+	for _, c := range chunks {
+		buf.Write(c.Get(c.Descriptor().Fields().ByName("data")).Bytes())
+	}
+	return buf.Bytes()
+}
+
+func TestGRPCConvert_Markdown(t *testing.T) {
+	conn := dialTestGRPC(t)
+
+	chunks, err := callConvert(t, conn, "<h1>Hello</h1><p>World</p>", "md")
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	if len(chunks) != 1 {
+		t.Fatalf("expected 1 chunk, got %d", len(chunks))
+	}
+	if got := getString(chunks[0], "format"); got != FormatMarkdown {
+		t.Errorf("expected format %q, got %q", FormatMarkdown, got)
+	}
+	if got := string(chunkData(chunks)); !strings.Contains(got, "# Hello") || !strings.Contains(got, "World") {
+		t.Errorf("unexpected markdown: %q", got)
+	}
+}
+
+func TestGRPCConvert_StreamsLargeContent(t *testing.T) {
+	conn := dialTestGRPC(t)
+
+	html := "<pre>" + strings.Repeat("x", 3*GRPCChunkSize) + "</pre>"
+	chunks, err := callConvert(t, conn, html, "html")
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	if len(chunks) != 4 {
+		t.Errorf("expected 4 chunks, got %d", len(chunks))
+	}
+	if got := string(chunkData(chunks)); got != html {
+		t.Errorf("reassembled content does not match input (%d bytes, want %d)", len(got), len(html))
+	}
+	if got := getString(chunks[1], "format"); got != "" {
+		t.Errorf("expected metadata only on the first chunk, got format %q", got)
+	}
+}
+
+func TestGRPCConvert_InvalidFormat(t *testing.T) {
+	conn := dialTestGRPC(t)
+
+	_, err := callConvert(t, conn, "<p>x</p>", "docx")
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+}
+
+func TestGRPCConvert_BinaryWithoutBrowser(t *testing.T) {
+	conn := dialTestGRPC(t)
+
+	_, err := callConvert(t, conn, "<p>x</p>", "pdf")
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("expected Unavailable, got %v", err)
+	}
+}
+
+func TestSnagProtoFile_MatchesProtoSource(t *testing.T) {
+	source, err := os.ReadFile("proto/snag/v1/snag.proto")
+	if err != nil {
+		t.Fatalf("failed to read proto: %v", err)
+	}
+	text := string(source)
+
+	methods := snagProtoFile.Services().ByName("Snag").Methods()
+	if methods.Len() != 4 {
+		t.Errorf("expected 4 methods, got %d", methods.Len())
+	}
+	for i := 0; i < methods.Len(); i++ {
+		m := methods.Get(i)
+		output := string(m.Output().Name())
+		if m.IsStreamingServer() {
+			output = "stream " + output
+		}
+		want := "rpc " + string(m.Name()) + "(" + string(m.Input().Name()) + ") returns (" + output + ");"
+		if !strings.Contains(text, want) {
+			t.Errorf("proto source missing %q", want)
+		}
+	}
+
+	messages := snagProtoFile.Messages()
+	for i := 0; i < messages.Len(); i++ {
+		if want := "message " + string(messages.Get(i).Name()) + " {"; !strings.Contains(text, want) {
+			t.Errorf("proto source missing %q", want)
+		}
+	}
+}
+
+func TestProtoJSONName(t *testing.T) {
+	tests := map[string]string{
+		"url":      "url",
+		"wait_for": "waitFor",
+		"a_b_c":    "aBC",
+	}
+	for in, want := range tests {
+		if got := protoJSONName(in); got != want {
+			t.Errorf("protoJSONName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"time"
 
 	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
 	"github.com/spf13/cobra"
 )
 
@@ -59,17 +60,55 @@ func snag(config *Config) error {
 		defer bm.ClosePage(page)
 	}
 
+	var capture *ResponseCapture
+	if config.CaptureResponses != "" {
+		capture, err = StartResponseCapture(page, config.CaptureResponses,
+			responseCaptureDir(config.OutputFile, config.OutputDir))
+		if err != nil {
+			return err
+		}
+	}
+
+	var recorder *RequestRecorder
+	if config.EmitCurl != "" {
+		recorder = StartRequestRecorder(page)
+	}
+
+	var screenRecorder *ScreenRecorder
+	if config.Record != "" {
+		screenRecorder, err = StartScreenRecorder(page)
+		if err != nil {
+			return err
+		}
+	}
+
 	fetcher := NewPageFetcher(page, config.Timeout)
 
 	_, err = fetcher.Fetch(FetchOptions{
 		URL:     config.URL,
 		Timeout: config.Timeout,
 		WaitFor: config.WaitFor,
+		Headers: config.Headers,
+		Actions: config.Actions,
 	})
+	if capture != nil {
+		capture.Stop()
+	}
+	if recorder != nil {
+		if err := writeCurlScript(config.EmitCurl, config.URL, recorder.Stop(), true); err != nil {
+			logger.Warning("%v", err)
+		}
+	}
 	if err != nil {
 		return err
 	}
 
+	if screenRecorder != nil {
+		if err := screenRecorder.Finish(config.Record, config.RecordDuration); err != nil {
+			return err
+		}
+	}
+
 	if config.OutputDir != "" {
 		info, err := page.Info()
 		if err != nil {
@@ -77,7 +116,7 @@ func snag(config *Config) error {
 		}
 
 		config.OutputFile, err = generateOutputFilename(
-			info.Title, config.URL, config.Format,
+			filenameTitle(page, info.Title, info.URL), config.URL, config.Format,
 			time.Now(), config.OutputDir,
 		)
 		if err != nil {
@@ -87,14 +126,14 @@ func snag(config *Config) error {
 
 	// For binary formats without -o or -d: auto-generate filename in current directory
 	// Binary formats (PDF, PNG) should NEVER output to stdout (corrupts terminal)
-	if config.OutputFile == "" && (config.Format == FormatPDF || config.Format == FormatPNG) {
+	if config.OutputFile == "" && requiresOutputFile(config.Format) {
 		info, err := page.Info()
 		if err != nil {
 			return fmt.Errorf("failed to get page info: %w", err)
 		}
 
 		config.OutputFile, err = generateOutputFilename(
-			info.Title, config.URL, config.Format,
+			filenameTitle(page, info.Title, info.URL), config.URL, config.Format,
 			time.Now(), ".",
 		)
 		if err != nil {
@@ -106,26 +145,202 @@ func snag(config *Config) error {
 	return processPageContent(page, config.Format, config.OutputFile)
 }
 
-func processPageContent(page *rod.Page, format string, outputFile string) error {
+// newPageConverter returns a converter for format configured from the
+// command line flags.
+func newPageConverter(format string) *ContentConverter {
 	converter := NewContentConverter(format)
+	converter.restoreScroll = restoreScroll
+	converter.pdfA = pdfA
+	converter.pdfStamp = pdfStamp
+	converter.maxHeight = maxHeight
+	converter.eol = normalizeEOL(eol)
+	converter.bom = bom
+	converter.splitLevel = splitHeadingLevel(splitBy)
+	converter.maxTokens = maxTokens
+	converter.tokenOverflow = normalizeTokenOverflow(tokenOverflow)
+	converter.textEngine = normalizeTextEngine(textEngine)
+	converter.annotate = annotate
+	converter.pdfOutline = !noPDFOutline
+	converter.viewportOnly = !screenshotFull
+	converter.shotSelector = strings.TrimSpace(shotSelector)
+	converter.viewWidth, converter.viewHeight, _ = parseViewport(viewportSize)
+	converter.pdfSplitLevel = splitHeadingLevel(pdfSplitBy)
+	converter.mainContent = readability
+	converter.pdfSelector = strings.TrimSpace(pdfSelector)
+	converter.pdfLayout = PDFLayout{
+		Landscape:      pdfLandscape,
+		Scale:          pdfScale,
+		HeaderTemplate: pdfHeader,
+		FooterTemplate: pdfFooter,
+	}
+	converter.pdfLayout.PaperWidth, converter.pdfLayout.PaperHeight, _ = parsePDFPaper(pdfPaper)
+	converter.pdfLayout.Margins, _ = parsePDFMargins(pdfMargins)
+	converter.sanitize = sanitize
+	converter.htmlPretty = htmlPretty
+	converter.htmlMinify = htmlMinify
+	return converter
+}
+
+// requiresOutputFile reports whether output in format cannot go to stdout,
+// either because it is binary or because --split-by or --token-overflow
+// split writes several files.
+func requiresOutputFile(format string) bool {
+	if format == FormatPDF || format == FormatPNG {
+		return true
+	}
+	if format == FormatMarkdown && splitBy != "" {
+		return true
+	}
+	return maxTokens > 0 && normalizeTokenOverflow(tokenOverflow) == TokenOverflowSplit
+}
+
+func processPageContent(page *rod.Page, format string, outputFile string) error {
+	converter := newPageConverter(format)
+	converter.fragment = pageFragment(page)
+	if fragmentOnly {
+		if converter.fragment == "" {
+			logger.Warning("--fragment-only ignored: the URL has no #fragment")
+		}
+		converter.fragmentOnly = converter.fragment != ""
+	}
+
+	// The script may change the page, such as opening accordions, so it
+	// runs before assertions and extraction
+	var evalResult *proto.RuntimeRemoteObject
+	if evalScript != "" {
+		result, err := evalUserScript(page, evalSource, evalScript)
+		if err != nil {
+			return err
+		}
+		evalResult = result
+	}
+
+	if err := checkAssertions(page); err != nil {
+		return err
+	}
+
+	expandHiddenContent(page)
+
+	if imagesReport {
+		if images, err := extractImages(page); err != nil {
+			logger.Warning("%v", err)
+		} else {
+			reportImages(images)
+		}
+	}
+
+	if linkInventory != nil {
+		recordExternalLinks(page)
+	}
+
+	if path := strings.TrimSpace(saveCookiesTo); path != "" {
+		if err := saveCookies(page, path); err != nil {
+			logger.Warning("%v", err)
+		}
+	}
 
 	// Handle binary formats (PDF, PNG) that need the page object
 	if format == FormatPDF || format == FormatPNG {
 		return converter.ProcessPage(page, outputFile)
 	}
 
-	html, err := page.HTML()
-	if err != nil {
-		return fmt.Errorf("failed to extract HTML: %w", err)
+	if format == FormatJSON {
+		return converter.ProcessText(evalResultJSON(evalResult), outputFile)
+	}
+
+	if citeStyle != "" {
+		citation, err := newCitation(page)
+		if err != nil {
+			return err
+		}
+		converter.citation = citation.Block(normalizeCiteStyle(citeStyle), format)
+	}
+
+	if includeSource {
+		header, err := newSourceHeader(page, format)
+		if err != nil {
+			return err
+		}
+		converter.source = header
+	}
+
+	// innerText runs script in the page, so --no-activate reads the DOM
+	if format == FormatText && converter.textEngine == TextEngineDOM && outputTemplate == nil && selectCSS == "" && !converter.fragmentOnly && !noActivate {
+		text, err := extractInnerText(page)
+		if err != nil {
+			return err
+		}
+		return converter.ProcessText(text, outputFile)
+	}
+
+	var html string
+	var err error
+	if converter.fragmentOnly {
+		html, err = extractFragmentHTML(page, converter.fragment, selectCSS)
+		if err != nil {
+			return err
+		}
+	} else if selectCSS != "" {
+		html, err = extractSelectedHTML(page, selectCSS)
+		if err != nil {
+			return err
+		}
+	} else {
+		if noActivate {
+			html, err = extractHTMLWithoutActivation(page)
+		} else {
+			html, err = page.HTML()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to extract HTML: %w", err)
+		}
+	}
+
+	if assetStore != nil {
+		html, err = assetStore.Localize(page, html, outputFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	if outputTemplate != nil {
+		return converter.ProcessTemplate(page, html, outputTemplate, outputFile)
 	}
 
 	return converter.Process(html, outputFile)
 }
 
+// responseCaptureDir returns where captured response bodies are written:
+// alongside the output file, in the output directory, or the current directory.
+func responseCaptureDir(outputFile, outputDir string) string {
+	if outputDir != "" {
+		return outputDir
+	}
+	if outputFile != "" {
+		return filepath.Dir(outputFile)
+	}
+	return "."
+}
+
 func generateOutputFilename(title, url, format string,
 	timestamp time.Time, outputDir string) (string, error) {
+	if mirrorPaths {
+		return mirrorOutputPath(outputDir, url, format)
+	}
+
 	filename := GenerateFilename(title, format, timestamp, url)
 
+	if tarOutput != nil {
+		return tarOutput.Reserve(filepath.Join(outputDir, filename)), nil
+	}
+
+	// A --filename-template may name subdirectories
+	if dir := filepath.Dir(filename); dir != "." {
+		if err := os.MkdirAll(filepath.Join(outputDir, dir), 0755); err != nil {
+			return "", fmt.Errorf("failed to create directory for %s: %w", filename, err)
+		}
+	}
+
 	finalFilename, err := ResolveConflict(outputDir, filename)
 	if err != nil {
 		return "", fmt.Errorf("failed to resolve filename conflict: %w", err)
@@ -134,6 +349,25 @@ func generateOutputFilename(title, url, format string,
 	return filepath.Join(outputDir, finalFilename), nil
 }
 
+// namedOutputPath joins an output name from a URL file or --flow to the
+// output directory, creating any subdirectories it names. Names that would
+// escape the directory are refused.
+func namedOutputPath(outputDir, name string) (string, error) {
+	if err := validateOutputName(name); err != nil {
+		return "", err
+	}
+
+	outputPath := filepath.Join(outputDir, name)
+	if tarOutput != nil {
+		return outputPath, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory for %s: %w", outputPath, err)
+	}
+	return outputPath, nil
+}
+
 func connectToExistingBrowser(port int) (*BrowserManager, error) {
 	bm := NewBrowserManager(BrowserOptions{
 		Port: port,
@@ -251,8 +485,8 @@ func handleAllTabs(cmd *cobra.Command) error {
 	if cmd.Flags().Changed("user-data-dir") {
 		logger.Warning("--user-data-dir ignored when connecting to existing browser")
 	}
-	if cmd.Flags().Changed("timeout") && waitFor == "" {
-		logger.Warning("--timeout is ignored without --wait-for when using --all-tabs")
+	if cmd.Flags().Changed("timeout") && waitFor == "" && waitForJSExpr == "" {
+		logger.Warning("--timeout is ignored without --wait-for or --wait-for-js when using --all-tabs")
 	}
 
 	if err := validateFormat(outputFormat); err != nil {
@@ -293,10 +527,13 @@ func handleAllTabs(cmd *cobra.Command) error {
 
 	successCount := 0
 	failureCount := 0
+	skippedCount := 0
 
 	for _, tab := range tabs {
+		logger.SetContext(LogContext{URL: tab.URL, Tab: tab.Index})
 		if isNonFetchableURL(tab.URL) {
 			logger.Warning("[%d/%d] Skipping tab: %s (not fetchable)", tab.Index, len(tabs), tab.URL)
+			skippedCount++
 			continue
 		}
 
@@ -317,9 +554,17 @@ func handleAllTabs(cmd *cobra.Command) error {
 				continue
 			}
 		}
+		if waitForJSExpr != "" {
+			err := waitForJS(page, waitForJSExpr, time.Duration(timeout)*time.Second)
+			if err != nil {
+				logger.Error("[%d/%d] Wait failed: %v", tab.Index, len(tabs), err)
+				failureCount++
+				continue
+			}
+		}
 
 		outputPath, err := generateOutputFilename(
-			tab.Title, tab.URL, outputFormat,
+			filenameTitle(page, tab.Title, tab.URL), tab.URL, outputFormat,
 			timestamp, outDir,
 		)
 		if err != nil {
@@ -350,8 +595,10 @@ func handleAllTabs(cmd *cobra.Command) error {
 			}
 		}
 	}
+	logger.SetContext(LogContext{})
 
 	logger.Success("Batch complete: %d succeeded, %d failed", successCount, failureCount)
+	reportRunSummary(successCount, failureCount, skippedCount, timestamp)
 
 	if failureCount > 0 {
 		return fmt.Errorf("batch processing completed with %d failures", failureCount)
@@ -370,11 +617,14 @@ func handleTabFetch(cmd *cobra.Command) error {
 	if cmd.Flags().Changed("user-agent") {
 		logger.Warning("--user-agent is ignored with --tab (cannot change existing tab's user agent)")
 	}
+	if showHeaders {
+		logger.Warning("--show-headers is ignored with --tab (the tab's response is not available)")
+	}
 	if cmd.Flags().Changed("user-data-dir") {
 		logger.Warning("--user-data-dir ignored when connecting to existing browser")
 	}
-	if cmd.Flags().Changed("timeout") && !cmd.Flags().Changed("wait-for") {
-		logger.Warning("--timeout is ignored without --wait-for when using --tab")
+	if cmd.Flags().Changed("timeout") && !cmd.Flags().Changed("wait-for") && waitForJSExpr == "" {
+		logger.Warning("--timeout is ignored without --wait-for or --wait-for-js when using --tab")
 	}
 
 	// Validate early before expensive browser connection
@@ -397,6 +647,16 @@ func handleTabFetch(cmd *cobra.Command) error {
 		checkExtensionMismatch(outputFile, outputFormat)
 	}
 
+	watchDir := strings.TrimSpace(outputDir)
+	if watch {
+		if watchDir == "" {
+			watchDir = "."
+		}
+		if err := validateDirectory(watchDir); err != nil {
+			return err
+		}
+	}
+
 	bm, err := connectToExistingBrowser(port)
 	if err != nil {
 		return err
@@ -415,6 +675,10 @@ func handleTabFetch(cmd *cobra.Command) error {
 			end, err2 := strconv.Atoi(strings.TrimSpace(parts[1]))
 
 			if err1 == nil && err2 == nil && start > 0 && end > 0 {
+				if watch {
+					logger.Error("--watch needs a single tab, not the range %s", tabValue)
+					return fmt.Errorf("--watch needs a single tab")
+				}
 				if cmd.Flags().Changed("output") {
 					logger.Error("Cannot use --output with multiple tabs. Use --output-dir instead")
 					return ErrOutputFlagConflict
@@ -428,9 +692,11 @@ func handleTabFetch(cmd *cobra.Command) error {
 	var page *rod.Page
 	var multipleMatches bool
 	var matchedPages []*rod.Page
+	var tabNumber int
 
 	// Try parsing as tab index
 	if tabIndex, err := strconv.Atoi(tabValue); err == nil {
+		tabNumber = tabIndex
 		logger.Verbose("Fetching from tab index: %d", tabIndex)
 		page, err = bm.GetTabByIndex(tabIndex)
 		if err != nil {
@@ -468,6 +734,11 @@ func handleTabFetch(cmd *cobra.Command) error {
 	}
 
 	if multipleMatches {
+		if watch {
+			logger.Error("--watch needs a single tab, but pattern '%s' matched %d tabs", tabValue, len(matchedPages))
+			logger.Info("Run 'snag --list-tabs' and give the tab number or a more specific pattern")
+			return fmt.Errorf("--watch needs a single tab")
+		}
 		return handleTabPatternBatch(cmd, matchedPages, tabValue)
 	}
 
@@ -477,6 +748,7 @@ func handleTabFetch(cmd *cobra.Command) error {
 		return fmt.Errorf("failed to get page info: %w", err)
 	}
 
+	logger.SetContext(LogContext{URL: info.URL, Tab: tabNumber})
 	logger.Info("Fetching content from: %s", info.URL)
 
 	if validatedWaitFor != "" {
@@ -485,11 +757,20 @@ func handleTabFetch(cmd *cobra.Command) error {
 			return err
 		}
 	}
+	if waitForJSExpr != "" {
+		if err := waitForJS(page, waitForJSExpr, time.Duration(timeout)*time.Second); err != nil {
+			return err
+		}
+	}
+
+	if watch {
+		return watchTab(page, outputFormat, watchDir, watchInterval)
+	}
 
 	// For binary formats without -o or -d: auto-generate filename
-	if outputFile == "" && (outputFormat == FormatPDF || outputFormat == FormatPNG) {
+	if outputFile == "" && requiresOutputFile(outputFormat) {
 		outputFile, err = generateOutputFilename(
-			info.Title, info.URL, outputFormat,
+			filenameTitle(page, info.Title, info.URL), info.URL, outputFormat,
 			time.Now(), ".",
 		)
 		if err != nil {
@@ -518,6 +799,7 @@ func processBatchTabs(pages []*rod.Page, config *Config) error {
 			continue
 		}
 
+		logger.SetContext(LogContext{URL: info.URL})
 		logger.Info("[%d/%d] Processing: %s", current, total, info.URL)
 
 		if config.WaitFor != "" {
@@ -528,9 +810,17 @@ func processBatchTabs(pages []*rod.Page, config *Config) error {
 				continue
 			}
 		}
+		if waitForJSExpr != "" {
+			err := waitForJS(page, waitForJSExpr, time.Duration(config.Timeout)*time.Second)
+			if err != nil {
+				logger.Error("[%d/%d] Wait failed: %v", current, total, err)
+				failureCount++
+				continue
+			}
+		}
 
 		outputPath, err := generateOutputFilename(
-			info.Title, info.URL, config.Format,
+			filenameTitle(page, info.Title, info.URL), info.URL, config.Format,
 			timestamp, config.OutputDir,
 		)
 		if err != nil {
@@ -547,8 +837,10 @@ func processBatchTabs(pages []*rod.Page, config *Config) error {
 
 		successCount++
 	}
+	logger.SetContext(LogContext{})
 
 	logger.Success("Batch complete: %d succeeded, %d failed", successCount, failureCount)
+	reportRunSummary(successCount, failureCount, 0, timestamp)
 
 	if failureCount > 0 {
 		return fmt.Errorf("batch processing completed with %d failures", failureCount)
@@ -645,6 +937,9 @@ func handleOpenURLsInBrowser(cmd *cobra.Command, urls []string) error {
 	if cmd.Flags().Changed("wait-for") {
 		logger.Warning("--wait-for ignored with --open-browser (no content fetching)")
 	}
+	if cmd.Flags().Changed("wait-for-js") {
+		logger.Warning("--wait-for-js ignored with --open-browser (no content fetching)")
+	}
 	if closeTab {
 		logger.Warning("--close-tab ignored with --open-browser (no content fetching)")
 	}
@@ -684,6 +979,8 @@ func handleOpenURLsInBrowser(cmd *cobra.Command, urls []string) error {
 		ForceHeadless: false,
 		UserAgent:     validatedUserAgent,
 		UserDataDir:   validatedUserDataDir,
+		Offline:       offline,
+		RemoteOnly:    k8sPod != "",
 	})
 
 	browserMutex.Lock()
@@ -725,7 +1022,10 @@ func handleOpenURLsInBrowser(cmd *cobra.Command, urls []string) error {
 	return nil
 }
 
-func handleMultipleURLs(cmd *cobra.Command, urls []string) error {
+// handleMultipleURLs fetches urls as a batch. outputNames maps a URL to the
+// filename given for it in the URL file; other URLs get generated names.
+func handleMultipleURLs(cmd *cobra.Command, urls []string, outputNames map[string]string) error {
+	start := time.Now()
 	outputFile := strings.TrimSpace(output)
 	outDir := strings.TrimSpace(outputDir)
 
@@ -748,6 +1048,13 @@ func handleMultipleURLs(cmd *cobra.Command, urls []string) error {
 		}
 	}
 
+	curlScript := strings.TrimSpace(emitCurl)
+	if curlScript != "" {
+		if err := validateOutputPath(curlScript); err != nil {
+			return err
+		}
+	}
+
 	if cmd.Flags().Changed("output-dir") && outDir == "" {
 		outDir = "."
 	}
@@ -767,6 +1074,11 @@ func handleMultipleURLs(cmd *cobra.Command, urls []string) error {
 		validatedUserDataDir = validatedDir
 	}
 
+	networkConditions, err := validateThrottle(throttle)
+	if err != nil {
+		return err
+	}
+
 	var validatedURLs []string
 	for _, urlStr := range urls {
 		validatedURL, err := validateURL(urlStr)
@@ -777,6 +1089,52 @@ func handleMultipleURLs(cmd *cobra.Command, urls []string) error {
 		validatedURLs = append(validatedURLs, validatedURL)
 	}
 
+	var state *CrawlState
+	if path := strings.TrimSpace(stateFile); path != "" {
+		if err := validateStateFile(path, false); err != nil {
+			return err
+		}
+
+		state, err = OpenCrawlState(path)
+		if err != nil {
+			return err
+		}
+		defer state.Close()
+
+		added, err := state.Enqueue(validatedURLs)
+		if err != nil {
+			logger.Error("%v", err)
+			return err
+		}
+
+		// The frontier includes URLs left over from earlier runs
+		validatedURLs, err = state.Pending()
+		if err != nil {
+			logger.Error("%v", err)
+			return err
+		}
+
+		// Incremental runs also re-check every URL already fetched
+		if incremental {
+			recs, err := state.Records()
+			if err != nil {
+				logger.Error("%v", err)
+				return err
+			}
+			for _, rec := range recs {
+				if rec.Status == URLDone {
+					validatedURLs = append(validatedURLs, rec.URL)
+				}
+			}
+		}
+
+		if len(validatedURLs) == 0 {
+			logger.Success("Nothing to fetch: every URL in %s is done (use --incremental to check for changes)", path)
+			return nil
+		}
+		logger.Info("State %s: %d URL%s to process (%d new)", path, len(validatedURLs), plural(len(validatedURLs)), added)
+	}
+
 	if len(validatedURLs) == 0 {
 		logger.Error("No valid URLs to process")
 		return ErrNoValidURLs
@@ -788,6 +1146,11 @@ func handleMultipleURLs(cmd *cobra.Command, urls []string) error {
 		Port:          port,
 		ForceHeadless: forceHead,
 		UserDataDir:   validatedUserDataDir,
+		Throttle:      networkConditions,
+		MemoryLimit:   browserMemoryLimit(),
+		ReadOnly:      readOnly,
+		Offline:       offline,
+		RemoteOnly:    k8sPod != "",
 	})
 	browserMutex.Lock()
 	browserManager = bm
@@ -799,7 +1162,7 @@ func handleMultipleURLs(cmd *cobra.Command, urls []string) error {
 		browserMutex.Unlock()
 	}()
 
-	_, err := bm.Connect()
+	_, err = bm.Connect()
 	if err != nil {
 		return err
 	}
@@ -809,23 +1172,54 @@ func handleMultipleURLs(cmd *cobra.Command, urls []string) error {
 	}
 
 	validatedWaitFor := validateWaitFor(waitFor, cmd.Flags().Changed("wait-for"))
+	capturePattern := strings.TrimSpace(captureResp)
+
+	var crawler *Crawler
+	if crawl {
+		crawler = NewCrawler(validatedURLs, crawlDepth, allowDomains)
+	}
+	var dash *Dashboard
 
 	timestamp := time.Now()
 
 	successCount := 0
 	failureCount := 0
 
-	for i, validatedURL := range validatedURLs {
+	// fetchOne fetches and saves a single URL, returning the output path and
+	// the page fingerprint when --incremental is set. Given the previous
+	// record of the URL, it returns errNotModified if the server answers a
+	// conditional request with 304, or errContentUnchanged if the page hashes
+	// the same as before; nothing is written in either case. Failures are
+	// logged here with the batch position.
+	fetchOne := func(i int, validatedURL string, previous *URLRecord) (string, Fingerprint, error) {
 		current := i + 1
 		total := len(validatedURLs)
 
-		logger.Info("[%d/%d] Fetching: %s", current, total, validatedURL)
-
 		page, err := bm.NewPage()
 		if err != nil {
 			logger.Error("[%d/%d] Failed to create page: %v", current, total, err)
-			failureCount++
-			continue
+			return "", Fingerprint{}, err
+		}
+
+		// The conditional request needs the page for its cookies
+		if previous != nil && checkUnchanged(page, validatedURL, previous) {
+			bm.ClosePage(page)
+			return "", Fingerprint{}, errNotModified
+		}
+
+		var capture *ResponseCapture
+		if capturePattern != "" {
+			capture, err = StartResponseCapture(page, capturePattern, responseCaptureDir("", outDir))
+			if err != nil {
+				logger.Error("[%d/%d] Failed to start response capture: %v", current, total, err)
+				bm.ClosePage(page)
+				return "", Fingerprint{}, err
+			}
+		}
+
+		var recorder *RequestRecorder
+		if curlScript != "" {
+			recorder = StartRequestRecorder(page)
 		}
 
 		fetcher := NewPageFetcher(page, timeout)
@@ -833,48 +1227,179 @@ func handleMultipleURLs(cmd *cobra.Command, urls []string) error {
 			URL:     validatedURL,
 			Timeout: timeout,
 			WaitFor: validatedWaitFor,
+			Headers: requestHeaders,
+			Actions: pageActions,
 		})
+		if capture != nil {
+			capture.Stop()
+		}
+		if recorder != nil {
+			if err := writeCurlScript(curlScript, validatedURL, recorder.Stop(), i == 0); err != nil {
+				logger.Warning("[%d/%d] %v", current, total, err)
+			}
+		}
 		if err != nil {
 			logger.Error("[%d/%d] Failed to fetch: %v", current, total, err)
 			bm.ClosePage(page)
-			failureCount++
-			continue
+			return "", Fingerprint{}, err
 		}
 
 		info, err := page.Info()
 		if err != nil {
 			logger.Error("[%d/%d] Failed to get page info: %v", current, total, err)
 			bm.ClosePage(page)
-			failureCount++
-			continue
+			return "", Fingerprint{}, err
 		}
 
-		outputPath, err := generateOutputFilename(
-			info.Title, validatedURL, outputFormat,
-			timestamp, outDir,
-		)
+		var fp Fingerprint
+		if incremental {
+			html, err := page.HTML()
+			if err != nil {
+				logger.Error("[%d/%d] Failed to extract HTML: %v", current, total, err)
+				bm.ClosePage(page)
+				return "", Fingerprint{}, err
+			}
+			fp = responseFingerprint(fetcher, hashContent(html))
+			if previous != nil && fp.Hash == previous.Hash {
+				if bm.launchedHeadless || closeTab {
+					bm.ClosePage(page)
+				}
+				return "", fp, errContentUnchanged
+			}
+		}
+
+		var outputPath string
+		if name, ok := outputNames[validatedURL]; ok {
+			outputPath, err = namedOutputPath(outDir, name)
+			if err == nil {
+				checkExtensionMismatch(outputPath, outputFormat)
+			}
+		} else {
+			outputPath, err = generateOutputFilename(
+				filenameTitle(page, info.Title, info.URL), validatedURL, outputFormat,
+				timestamp, outDir,
+			)
+		}
 		if err != nil {
 			logger.Error("[%d/%d] Failed to generate filename: %v", current, total, err)
 			bm.ClosePage(page)
-			failureCount++
-			continue
+			return "", Fingerprint{}, err
 		}
 
 		if err := processPageContent(page, outputFormat, outputPath); err != nil {
 			logger.Error("[%d/%d] Failed to save content: %v", current, total, err)
 			bm.ClosePage(page)
-			failureCount++
-			continue
+			return "", Fingerprint{}, err
+		}
+
+		if crawler != nil {
+			crawler.Seen(validatedURL, info.URL)
+			links, err := extractLinks(page)
+			if err != nil {
+				logger.Warning("[%d/%d] %v", current, total, err)
+			} else if next := crawler.Follow(validatedURL, links); len(next) > 0 {
+				logger.Verbose("[%d/%d] Found %d new link%s to crawl", current, total, len(next), plural(len(next)))
+				validatedURLs = append(validatedURLs, next...)
+				dash.AddTotal(len(next))
+			}
 		}
 
 		if bm.launchedHeadless || closeTab {
 			bm.ClosePage(page)
 		}
 
+		return outputPath, fp, nil
+	}
+
+	unchangedCount := 0
+	var outputPaths []string
+
+	if tui {
+		dash = StartDashboard(len(validatedURLs))
+	}
+	defer dash.Stop()
+
+	// --crawl adds the links of each page to validatedURLs as it goes
+	for i := 0; i < len(validatedURLs); i++ {
+		validatedURL := validatedURLs[i]
+		current := i + 1
+		total := len(validatedURLs)
+
+		batchPause.Wait(fmt.Sprintf("[%d/%d] %s", current, total, validatedURL))
+		logger.SetContext(LogContext{URL: validatedURL})
+		logger.Info("[%d/%d] Fetching: %s", current, total, validatedURL)
+		dash.Begin(validatedURL)
+
+		var previous *URLRecord
+		if incremental {
+			rec, err := state.Get(validatedURL)
+			if err != nil {
+				logger.Warning("%v", err)
+			}
+			if rec != nil && rec.Status == URLDone {
+				previous = rec
+			}
+		}
+
+		if err := bm.RestartIfOverMemory(); err != nil {
+			logger.Error("%v", err)
+			return err
+		}
+
+		outputPath, fp, err := fetchOne(i, validatedURL, previous)
+		if errors.Is(err, errNotModified) || errors.Is(err, errContentUnchanged) {
+			if errors.Is(err, errNotModified) {
+				logger.Success("[%d/%d] Not modified since last fetch", current, total)
+			} else {
+				logger.Success("[%d/%d] Content unchanged since last fetch", current, total)
+			}
+			if err := state.RecordUnchanged(validatedURL); err != nil {
+				logger.Warning("%v", err)
+			}
+			unchangedCount++
+			dash.Done(false, false)
+			continue
+		}
+		if state != nil {
+			if err := state.Record(validatedURL, outputPath, err); err != nil {
+				logger.Warning("%v", err)
+			}
+		}
+		if err != nil {
+			failureCount++
+			dash.Done(false, true)
+			continue
+		}
+
+		if incremental {
+			if err := state.SetFingerprint(validatedURL, fp); err != nil {
+				logger.Warning("%v", err)
+			}
+		}
+
+		outputPaths = append(outputPaths, outputPath)
 		successCount++
+		dash.Done(true, false)
 	}
+	dash.Stop()
+	logger.SetContext(LogContext{})
 
-	logger.Success("Batch complete: %d succeeded, %d failed", successCount, failureCount)
+	if !keepBoiler {
+		stripBatchBoilerplate(outputPaths, outputFormat)
+	}
+	if duplicates != "" {
+		var skipped int
+		outputPaths, skipped = handleBatchDuplicates(outputPaths, outputFormat)
+		successCount -= skipped
+		unchangedCount += skipped
+	}
+
+	if incremental {
+		logger.Success("Batch complete: %d fetched, %d unchanged, %d failed", successCount, unchangedCount, failureCount)
+	} else {
+		logger.Success("Batch complete: %d succeeded, %d failed", successCount, failureCount)
+	}
+	reportRunSummary(successCount, failureCount, unchangedCount, start)
 
 	if failureCount > 0 {
 		return fmt.Errorf("batch processing completed with %d failures", failureCount)
@@ -883,6 +1408,28 @@ func handleMultipleURLs(cmd *cobra.Command, urls []string) error {
 	return nil
 }
 
+// stripBatchBoilerplate removes blocks such as navigation and footers that
+// repeat across most pages of a batch. Output written as several files per
+// page, or streamed to a tar archive, is left as it is.
+func stripBatchBoilerplate(paths []string, outputFormat string) {
+	if outputFormat != FormatMarkdown && outputFormat != FormatText {
+		return
+	}
+	if tarOutput != nil || splitBy != "" || (maxTokens > 0 && normalizeTokenOverflow(tokenOverflow) == TokenOverflowSplit) {
+		logger.Debug("Skipping boilerplate removal for split or archived output")
+		return
+	}
+
+	removed, err := removeBoilerplate(paths)
+	if err != nil {
+		logger.Warning("Boilerplate removal failed: %v", err)
+		return
+	}
+	if removed > 0 {
+		logger.Info("Removed %d boilerplate block%s repeated across pages (use --keep-boilerplate to keep)", removed, plural(removed))
+	}
+}
+
 func plural(n int) string {
 	if n == 1 {
 		return ""
@@ -890,64 +1437,113 @@ func plural(n int) string {
 	return "s"
 }
 
+// URLEntry is one line of a URL file: a URL and an optional output filename
+// relative to the output directory.
+type URLEntry struct {
+	URL  string
+	Name string
+}
+
 func loadURLsFromReader(reader io.Reader, source string) ([]string, error) {
-	var urls []string
+	entries, err := loadURLEntriesFromReader(reader, source)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]string, len(entries))
+	for i, entry := range entries {
+		urls[i] = entry.URL
+	}
+	return urls, nil
+}
+
+func loadURLEntriesFromReader(reader io.Reader, source string) ([]URLEntry, error) {
+	var entries []URLEntry
 	scanner := bufio.NewScanner(reader)
 	lineNum := 0
 
 	for scanner.Scan() {
 		lineNum++
-		line := strings.TrimSpace(scanner.Text())
-
-		if line == "" {
-			continue
+		if entry, ok := parseURLLine(scanner.Text(), lineNum); ok {
+			entries = append(entries, entry)
 		}
+	}
 
-		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
-			continue
-		}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading from %s: %w", source, err)
+	}
 
-		hasComment := false
-		for _, marker := range []string{" #", " //"} {
-			if idx := strings.Index(line, marker); idx != -1 {
-				line = strings.TrimSpace(line[:idx])
-				hasComment = true
-				break
-			}
-		}
+	if len(entries) == 0 {
+		return nil, ErrNoValidURLs
+	}
 
-		if !hasComment && strings.Contains(line, " ") {
-			logger.Warning("Line %d: URL contains space without comment marker - skipping: %s", lineNum, line)
-			continue
-		}
+	logger.Verbose("Loaded %d URLs from %s", len(entries), source)
+	return entries, nil
+}
 
-		if !strings.HasPrefix(line, "http://") && !strings.HasPrefix(line, "https://") && !strings.HasPrefix(line, "file://") {
-			line = "https://" + line
-		}
+// parseURLLine parses one line of a URL file: a URL, optionally followed by
+// an output filename. Comments are stripped, ${VAR} is read from the
+// environment, and https:// is added when no scheme is given. It returns false for blank lines, comments, and invalid
+// entries, logging a warning for the latter.
+func parseURLLine(raw string, lineNum int) (URLEntry, bool) {
+	line := strings.TrimSpace(raw)
 
-		if _, err := validateURL(line); err != nil {
-			logger.Warning("Line %d: Invalid URL - skipping: %s", lineNum, scanner.Text())
-			continue
+	if line == "" {
+		return URLEntry{}, false
+	}
+
+	if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+		return URLEntry{}, false
+	}
+
+	hasComment := false
+	for _, marker := range []string{" #", " //"} {
+		if idx := strings.Index(line, marker); idx != -1 {
+			line = strings.TrimSpace(line[:idx])
+			hasComment = true
+			break
 		}
+	}
 
-		urls = append(urls, line)
+	// Comments are stripped first, so a variable's value cannot start one
+	line = expandEnvVars(line)
+
+	var entry URLEntry
+	fields := strings.Fields(line)
+	switch len(fields) {
+	case 1:
+		entry.URL = fields[0]
+	case 2:
+		entry.URL = fields[0]
+		entry.Name = fields[1]
+		if err := validateOutputName(entry.Name); err != nil {
+			logger.Warning("Line %d: %v - skipping: %s", lineNum, err, raw)
+			return URLEntry{}, false
+		}
+	default:
+		if hasComment {
+			logger.Warning("Line %d: Expected a URL and optional filename - skipping: %s", lineNum, line)
+		} else {
+			logger.Warning("Line %d: URL contains space without comment marker - skipping: %s", lineNum, line)
+		}
+		return URLEntry{}, false
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading from %s: %w", source, err)
+	if !strings.HasPrefix(entry.URL, "http://") && !strings.HasPrefix(entry.URL, "https://") && !strings.HasPrefix(entry.URL, "file://") {
+		entry.URL = "https://" + entry.URL
 	}
 
-	if len(urls) == 0 {
-		return nil, ErrNoValidURLs
+	if _, err := validateURL(entry.URL); err != nil {
+		logger.Warning("Line %d: Invalid URL - skipping: %s", lineNum, raw)
+		return URLEntry{}, false
 	}
 
-	logger.Verbose("Loaded %d URLs from %s", len(urls), source)
-	return urls, nil
+	return entry, true
 }
 
-func loadURLsFromFile(filename string) ([]string, error) {
+func loadURLEntriesFromFile(filename string) ([]URLEntry, error) {
 	if filename == "-" {
-		return loadURLsFromReader(os.Stdin, "stdin")
+		return loadURLEntriesFromReader(os.Stdin, "stdin")
 	}
 
 	file, err := os.Open(filename)
@@ -957,7 +1553,7 @@ func loadURLsFromFile(filename string) ([]string, error) {
 	}
 	defer file.Close()
 
-	return loadURLsFromReader(file, filename)
+	return loadURLEntriesFromReader(file, filename)
 }
 
 func handleKillBrowser(cmd *cobra.Command) error {
@@ -8,6 +8,8 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -538,3 +540,55 @@ docs.company.com/api  // API docs
 		})
 	}
 }
+
+func TestLoadURLEntriesFromReader(t *testing.T) {
+	logger = NewLogger(LevelQuiet)
+
+	input := `example.com/docs intro.md
+https://example.org   # no name
+https://example.net guide/setup.md  # nested
+https://evil.example ../escape.md
+https://evil.example /etc/passwd.md
+https://example.com/a b c
+`
+
+	entries, err := loadURLEntriesFromReader(strings.NewReader(input), "stdin")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []URLEntry{
+		{URL: "https://example.com/docs", Name: "intro.md"},
+		{URL: "https://example.org"},
+		{URL: "https://example.net", Name: "guide/setup.md"},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("got %d entries, expected %d: %+v", len(entries), len(expected), entries)
+	}
+	for i, entry := range entries {
+		if entry != expected[i] {
+			t.Errorf("entry %d: got %+v, expected %+v", i, entry, expected[i])
+		}
+	}
+}
+
+func TestNamedOutputPath(t *testing.T) {
+	dir := t.TempDir()
+
+	path, err := namedOutputPath(dir, "docs/page.md")
+	if err != nil {
+		t.Fatalf("namedOutputPath() error: %v", err)
+	}
+	if path != filepath.Join(dir, "docs", "page.md") {
+		t.Errorf("namedOutputPath() = %s", path)
+	}
+	if info, err := os.Stat(filepath.Join(dir, "docs")); err != nil || !info.IsDir() {
+		t.Errorf("expected docs subdirectory to be created: %v", err)
+	}
+
+	for _, name := range []string{"../page.md", "/tmp/page.md", "docs/../../page.md"} {
+		if _, err := namedOutputPath(dir, name); err == nil {
+			t.Errorf("namedOutputPath(%q) should fail", name)
+		}
+	}
+}
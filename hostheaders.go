@@ -0,0 +1,155 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/go-rod/rod/lib/proto"
+	"go.yaml.in/yaml/v3"
+)
+
+// HostHeaderRule sends its headers only with requests to matching hosts,
+// for --host-headers.
+type HostHeaderRule struct {
+	hosts   []hostPattern
+	headers map[string]string
+}
+
+// hostHeaderRules are the rules loaded from --host-headers.
+var hostHeaderRules []HostHeaderRule
+
+// hostHeaderEntry is one rule of a --host-headers file:
+//
+//	rules:
+//	  - hosts: ["*.atlassian.net"]
+//	    headers:
+//	      Authorization: "Bearer ${JIRA_TOKEN}"
+//	  - hosts: [api.github.com]
+//	    headers:
+//	      Authorization: "token ${GITHUB_TOKEN}"
+type hostHeaderEntry struct {
+	Hosts   []string          `yaml:"hosts"`
+	Headers map[string]string `yaml:"headers"`
+}
+
+// parseHostHeaders decodes and validates --host-headers YAML. Host patterns
+// are those of --allow-host, and environment variables in header values are
+// expanded, as in --flow files.
+func parseHostHeaders(data []byte) ([]HostHeaderRule, error) {
+	var file struct {
+		Rules []hostHeaderEntry `yaml:"rules"`
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse host headers: %w", err)
+	}
+	if len(file.Rules) == 0 {
+		return nil, fmt.Errorf("no rules")
+	}
+
+	rules := make([]HostHeaderRule, 0, len(file.Rules))
+	for i, entry := range file.Rules {
+		if len(entry.Hosts) == 0 {
+			return nil, fmt.Errorf("rule %d: needs at least one host", i+1)
+		}
+		if len(entry.Headers) == 0 {
+			return nil, fmt.Errorf("rule %d: needs at least one header", i+1)
+		}
+
+		rule := HostHeaderRule{headers: make(map[string]string, len(entry.Headers))}
+		for _, pattern := range entry.Hosts {
+			hp, err := parseHostPattern(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %w", i+1, err)
+			}
+			rule.hosts = append(rule.hosts, hp)
+		}
+		for name, value := range entry.Headers {
+			name, value, err := parseHeader(name + ": " + expandEnvVars(value))
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %w", i+1, err)
+			}
+			rule.headers[name] = value
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func loadHostHeaders(path string) ([]HostHeaderRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		logger.Error("Failed to read host headers file: %s", path)
+		return nil, fmt.Errorf("failed to read host headers file: %w", err)
+	}
+
+	rules, err := parseHostHeaders(data)
+	if err != nil {
+		logger.Error("Invalid host headers file %s: %v", path, err)
+		logger.ErrorWithSuggestion(
+			"Each rule needs hosts (patterns as for --allow-host) and headers",
+			"snag --host-headers headers.yaml https://example.atlassian.net/wiki",
+		)
+		return nil, err
+	}
+	return rules, nil
+}
+
+// headersForURL returns the headers of every rule matching the host of
+// rawURL, merged in file order so later rules win. Only http and https
+// URLs get headers.
+func headersForURL(rules []HostHeaderRule, rawURL string) map[string]string {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	var headers map[string]string
+	for _, rule := range rules {
+		if !slices.ContainsFunc(rule.hosts, func(hp hostPattern) bool { return hp.matches(host) }) {
+			continue
+		}
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		for name, value := range rule.headers {
+			headers[name] = value
+		}
+	}
+	return headers
+}
+
+// withHeaders returns the request headers with extra added, replacing any
+// header of the same name regardless of case.
+func withHeaders(request proto.NetworkHeaders, extra map[string]string) []*proto.FetchHeaderEntry {
+	entries := make([]*proto.FetchHeaderEntry, 0, len(request)+len(extra))
+	for name, value := range request {
+		replaced := false
+		for extraName := range extra {
+			if strings.EqualFold(name, extraName) {
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			entries = append(entries, &proto.FetchHeaderEntry{Name: name, Value: value.Str()})
+		}
+	}
+	for name, value := range extra {
+		entries = append(entries, &proto.FetchHeaderEntry{Name: name, Value: value})
+	}
+	slices.SortFunc(entries, func(a, b *proto.FetchHeaderEntry) int { return strings.Compare(a.Name, b.Name) })
+	return entries
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+func TestParseHostHeaders(t *testing.T) {
+	logger = NewLogger(LevelQuiet)
+	t.Setenv("SNAG_TEST_JIRA_TOKEN", "jira-secret")
+
+	rules, err := parseHostHeaders([]byte(`
+rules:
+  - hosts: ["*.atlassian.net"]
+    headers:
+      Authorization: "Bearer ${SNAG_TEST_JIRA_TOKEN}"
+  - hosts: [api.github.com, "*.github.com"]
+    headers:
+      Authorization: token abc
+      X-GitHub-Api-Version: "2022-11-28"
+`))
+	if err != nil {
+		t.Fatalf("parseHostHeaders() error: %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(rules))
+	}
+
+	tests := []struct {
+		url      string
+		expected map[string]string
+	}{
+		{"https://acme.atlassian.net/browse/X-1", map[string]string{"Authorization": "Bearer jira-secret"}},
+		{"https://ACME.Atlassian.NET./wiki", map[string]string{"Authorization": "Bearer jira-secret"}},
+		{"https://atlassian.net/", nil},
+		{"https://api.github.com/repos", map[string]string{"Authorization": "token abc", "X-GitHub-Api-Version": "2022-11-28"}},
+		{"https://cdn.example.com/app.js", nil},
+		{"ftp://acme.atlassian.net/", nil},
+	}
+	for _, tt := range tests {
+		got := headersForURL(rules, tt.url)
+		if len(got) != len(tt.expected) {
+			t.Errorf("headersForURL(%q) = %v, want %v", tt.url, got, tt.expected)
+			continue
+		}
+		for name, value := range tt.expected {
+			if got[name] != value {
+				t.Errorf("headersForURL(%q)[%s] = %q, want %q", tt.url, name, got[name], value)
+			}
+		}
+	}
+}
+
+func TestParseHostHeaders_LaterRulesWin(t *testing.T) {
+	rules, err := parseHostHeaders([]byte(`
+rules:
+  - hosts: ["*.example.com"]
+    headers: { X-Team: all, X-Env: prod }
+  - hosts: [docs.example.com]
+    headers: { X-Team: docs }
+`))
+	if err != nil {
+		t.Fatalf("parseHostHeaders() error: %v", err)
+	}
+
+	got := headersForURL(rules, "https://docs.example.com/")
+	if got["X-Team"] != "docs" || got["X-Env"] != "prod" {
+		t.Errorf("headersForURL() = %v", got)
+	}
+}
+
+func TestParseHostHeaders_Invalid(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"rules: []",
+		"rules:\n  - headers: { X-A: b }",
+		"rules:\n  - hosts: [example.com]",
+		"rules:\n  - hosts: [https://example.com]\n    headers: { X-A: b }",
+		"rules:\n  - hosts: [example.com]\n    headers: { \"X A\": b }",
+		"rules:\n  - hosts: [example.com]\n    header: { X-A: b }",
+	} {
+		if _, err := parseHostHeaders([]byte(input)); err == nil {
+			t.Errorf("parseHostHeaders(%q) expected error", input)
+		}
+	}
+}
+
+func TestWithHeaders(t *testing.T) {
+	var request proto.NetworkHeaders
+	if err := json.Unmarshal([]byte(`{"authorization": "Basic old", "Accept": "text/html"}`), &request); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := withHeaders(request, map[string]string{"Authorization": "Bearer new"})
+	got := make(map[string]string)
+	for _, entry := range entries {
+		got[entry.Name] = entry.Value
+	}
+	if len(got) != 2 || got["Accept"] != "text/html" || got["Authorization"] != "Bearer new" {
+		t.Errorf("withHeaders() = %v", got)
+	}
+}
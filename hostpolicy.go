@@ -0,0 +1,271 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// HostLookupTimeout bounds the DNS lookup --no-private-ips makes per host.
+const HostLookupTimeout = 5 * time.Second
+
+// hostPattern is one --allow-host or --block-host entry: an exact host
+// (example.com), a subdomain wildcard (*.example.com), an IP address, or a
+// CIDR range (169.254.0.0/16).
+type hostPattern struct {
+	host     string
+	wildcard bool
+	prefix   netip.Prefix
+}
+
+func parseHostPattern(pattern string) (hostPattern, error) {
+	pattern = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(pattern)), ".")
+	if pattern == "" {
+		return hostPattern{}, fmt.Errorf("empty host pattern")
+	}
+
+	if prefix, err := netip.ParsePrefix(pattern); err == nil {
+		return hostPattern{prefix: prefix.Masked()}, nil
+	}
+	if addr, err := netip.ParseAddr(strings.Trim(pattern, "[]")); err == nil {
+		return hostPattern{prefix: netip.PrefixFrom(addr, addr.BitLen())}, nil
+	}
+
+	if strings.ContainsAny(pattern, "/:@?# ") {
+		return hostPattern{}, fmt.Errorf("invalid host pattern: %s", pattern)
+	}
+	if rest, ok := strings.CutPrefix(pattern, "*."); ok {
+		if rest == "" || strings.Contains(rest, "*") {
+			return hostPattern{}, fmt.Errorf("invalid host pattern: %s", pattern)
+		}
+		return hostPattern{host: rest, wildcard: true}, nil
+	}
+	if strings.Contains(pattern, "*") {
+		return hostPattern{}, fmt.Errorf("invalid host pattern: %s (wildcards must lead, as in *.example.com)", pattern)
+	}
+
+	return hostPattern{host: pattern}, nil
+}
+
+// matches reports whether host, a lowercase hostname or IP literal, is
+// covered by the pattern. Wildcards match subdomains only, not the domain
+// itself.
+func (hp hostPattern) matches(host string) bool {
+	if hp.prefix.IsValid() {
+		addr, err := netip.ParseAddr(host)
+		return err == nil && hp.prefix.Contains(addr.Unmap())
+	}
+	if hp.wildcard {
+		return strings.HasSuffix(host, "."+hp.host)
+	}
+	return host == hp.host
+}
+
+// HostPolicy decides which hosts snag may fetch from, built from
+// --allow-host, --block-host, and --no-private-ips. Blocked hosts win over
+// allowed ones, and a non-empty allow list blocks every host not on it.
+// With noPrivate, hosts that resolve to private, loopback, or link-local
+// addresses are blocked unless they are on the allow list.
+type HostPolicy struct {
+	allow     []hostPattern
+	block     []hostPattern
+	noPrivate bool
+
+	// lookup resolves a hostname; results are cached per host for the
+	// life of the policy.
+	lookup   func(ctx context.Context, host string) ([]netip.Addr, error)
+	mu       sync.Mutex
+	resolved map[string][]netip.Addr
+}
+
+// NewHostPolicy parses allow and block host patterns.
+func NewHostPolicy(allow, block []string, noPrivate bool) (*HostPolicy, error) {
+	policy := &HostPolicy{
+		noPrivate: noPrivate,
+		lookup: func(ctx context.Context, host string) ([]netip.Addr, error) {
+			return net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		},
+		resolved: make(map[string][]netip.Addr),
+	}
+	for _, pattern := range allow {
+		hp, err := parseHostPattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		policy.allow = append(policy.allow, hp)
+	}
+	for _, pattern := range block {
+		hp, err := parseHostPattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		policy.block = append(policy.block, hp)
+	}
+	return policy, nil
+}
+
+// Enabled reports whether the policy restricts any hosts.
+func (p *HostPolicy) Enabled() bool {
+	return len(p.allow) > 0 || len(p.block) > 0 || p.noPrivate
+}
+
+// Check returns an error wrapping ErrHostBlocked if rawURL may not be
+// fetched. With an allow list only http and https URLs are permitted.
+func (p *HostPolicy) Check(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidURL, rawURL)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		if len(p.allow) > 0 {
+			return fmt.Errorf("%w: %s URLs are not allowed with --allow-host", ErrHostBlocked, u.Scheme)
+		}
+		return nil
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	for _, hp := range p.block {
+		if hp.matches(host) {
+			return fmt.Errorf("%w: %s", ErrHostBlocked, host)
+		}
+	}
+
+	for _, hp := range p.allow {
+		if hp.matches(host) {
+			return nil
+		}
+	}
+	if len(p.allow) > 0 {
+		return fmt.Errorf("%w: %s is not an allowed host", ErrHostBlocked, host)
+	}
+
+	if p.noPrivate {
+		return p.checkPrivate(host)
+	}
+	return nil
+}
+
+// checkPrivate resolves host and blocks it if any of its addresses is not
+// publicly routable.
+func (p *HostPolicy) checkPrivate(host string) error {
+	addrs, err := p.resolve(host)
+	if err != nil {
+		return fmt.Errorf("%w: cannot resolve %s to check for private addresses: %w", ErrHostBlocked, host, err)
+	}
+
+	for _, addr := range addrs {
+		if isPrivateAddr(addr) {
+			if addr.String() == host {
+				return fmt.Errorf("%w: %s is a private address", ErrHostBlocked, host)
+			}
+			return fmt.Errorf("%w: %s resolves to private address %s", ErrHostBlocked, host, addr)
+		}
+	}
+	return nil
+}
+
+func (p *HostPolicy) resolve(host string) ([]netip.Addr, error) {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return []netip.Addr{addr.Unmap()}, nil
+	}
+
+	p.mu.Lock()
+	addrs, ok := p.resolved[host]
+	p.mu.Unlock()
+	if ok {
+		return addrs, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), HostLookupTimeout)
+	defer cancel()
+	addrs, err := p.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	for i, addr := range addrs {
+		addrs[i] = addr.Unmap()
+	}
+
+	p.mu.Lock()
+	p.resolved[host] = addrs
+	p.mu.Unlock()
+	return addrs, nil
+}
+
+// sharedAddressSpace is the carrier-grade NAT range of RFC 6598.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// isPrivateAddr reports whether addr is loopback, link-local (including the
+// 169.254.169.254 cloud metadata endpoint), RFC 1918 or unique local,
+// shared, or unspecified.
+func isPrivateAddr(addr netip.Addr) bool {
+	return addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsUnspecified() ||
+		sharedAddressSpace.Contains(addr)
+}
+
+// activeHostPolicy builds the policy from --allow-host, --block-host, and
+// --no-private-ips.
+func activeHostPolicy() (*HostPolicy, error) {
+	return NewHostPolicy(allowHosts, blockHosts, noPrivateIPs)
+}
+
+// guardRequests fails every request page makes to a host the policy blocks,
+// including redirects and subresources, so a permitted page cannot pull in
+// a forbidden address. Requests to hosts matching a --host-headers rule get
+// the rule's headers; each redirect is matched again, so headers do not
+// follow a redirect to another host. Requests the blocker matches are
+// aborted quietly. Call the returned function to stop guarding.
+func guardRequests(page *rod.Page, policy *HostPolicy, rules []HostHeaderRule, blocker *ResourceBlocker) func() {
+	var blocked atomic.Int64
+	router := page.HijackRequests()
+	router.MustAdd("*", func(ctx *rod.Hijack) {
+		requestURL := ctx.Request.URL().String()
+		if err := policy.Check(requestURL); err != nil {
+			logger.Warning("Blocked request to %s: %v", requestURL, err)
+			ctx.Response.Fail(proto.NetworkErrorReasonBlockedByClient)
+			return
+		}
+		if blocker.Blocks(requestURL, ctx.Request.Type()) {
+			logger.Debug("Blocked %s request to %s", ctx.Request.Type(), requestURL)
+			blocked.Add(1)
+			ctx.Response.Fail(proto.NetworkErrorReasonBlockedByClient)
+			return
+		}
+		if extra := headersForURL(rules, requestURL); len(extra) > 0 {
+			ctx.ContinueRequest(&proto.FetchContinueRequest{Headers: withHeaders(ctx.Request.Headers(), extra)})
+			return
+		}
+		ctx.ContinueRequest(&proto.FetchContinueRequest{})
+	})
+	go router.Run()
+
+	return func() {
+		if err := router.Stop(); err != nil {
+			logger.Debug("Failed to stop request guard: %v", err)
+		}
+		if n := int(blocked.Load()); n > 0 {
+			logger.Verbose("Blocked %d request%s", n, plural(n))
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestParseHostPattern_Invalid(t *testing.T) {
+	for _, pattern := range []string{"", "exa*mple.com", "*.", "*.*.example.com", "http://example.com", "example.com/path"} {
+		if _, err := parseHostPattern(pattern); err == nil {
+			t.Errorf("parseHostPattern(%q) expected error", pattern)
+		}
+	}
+}
+
+func TestHostPolicy_Check(t *testing.T) {
+	tests := []struct {
+		name    string
+		allow   []string
+		block   []string
+		url     string
+		blocked bool
+	}{
+		{"no policy", nil, nil, "https://example.com/", false},
+		{"blocked host", nil, []string{"example.com"}, "https://Example.COM./x", true},
+		{"blocked IP", nil, []string{"169.254.169.254"}, "http://169.254.169.254/latest/meta-data/", true},
+		{"blocked CIDR", nil, []string{"10.0.0.0/8"}, "http://10.1.2.3:8080/", true},
+		{"blocked IPv6", nil, []string{"::1"}, "http://[::1]/", true},
+		{"CIDR ignores names", nil, []string{"10.0.0.0/8"}, "https://example.com/", false},
+		{"wildcard subdomain", nil, []string{"*.internal"}, "https://db.internal/", true},
+		{"wildcard skips apex", nil, []string{"*.internal"}, "https://internal/", false},
+		{"allowed host", []string{"example.com"}, nil, "https://example.com/", false},
+		{"not allowed", []string{"example.com"}, nil, "https://other.com/", true},
+		{"block wins", []string{"*.example.com"}, []string{"admin.example.com"}, "https://admin.example.com/", true},
+		{"file with allow list", []string{"example.com"}, nil, "file:///etc/passwd", true},
+		{"file with block list", nil, []string{"example.com"}, "file:///tmp/page.html", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := NewHostPolicy(tt.allow, tt.block, false)
+			if err != nil {
+				t.Fatalf("NewHostPolicy: %v", err)
+			}
+			err = policy.Check(tt.url)
+			if tt.blocked != errors.Is(err, ErrHostBlocked) {
+				t.Errorf("Check(%q) = %v, blocked want %v", tt.url, err, tt.blocked)
+			}
+		})
+	}
+}
+
+func TestHostPolicy_NoPrivateIPs(t *testing.T) {
+	policy, err := NewHostPolicy(nil, nil, true)
+	if err != nil {
+		t.Fatalf("NewHostPolicy: %v", err)
+	}
+	policy.lookup = func(ctx context.Context, host string) ([]netip.Addr, error) {
+		t.Errorf("IP literal %s should not be resolved", host)
+		return nil, nil
+	}
+
+	tests := []struct {
+		url     string
+		blocked bool
+	}{
+		{"http://127.0.0.1/", true},
+		{"http://169.254.169.254/latest/meta-data/", true},
+		{"http://192.168.1.1/", true},
+		{"http://[fe80::1]/", true},
+		{"http://[::ffff:10.0.0.1]/", true},
+		{"http://100.64.0.1/", true},
+		{"http://0.0.0.0/", true},
+		{"https://93.184.216.34/", false},
+	}
+
+	for _, tt := range tests {
+		err := policy.Check(tt.url)
+		if tt.blocked != errors.Is(err, ErrHostBlocked) {
+			t.Errorf("Check(%q) = %v, blocked want %v", tt.url, err, tt.blocked)
+		}
+	}
+
+	allowed, err := NewHostPolicy([]string{"10.0.0.0/8", "intranet.example.com"}, nil, true)
+	if err != nil {
+		t.Fatalf("NewHostPolicy: %v", err)
+	}
+	allowed.lookup = policy.lookup
+	for _, u := range []string{"http://10.0.0.5/", "http://intranet.example.com/"} {
+		if err := allowed.Check(u); err != nil {
+			t.Errorf("explicitly allowed %s blocked: %v", u, err)
+		}
+	}
+}
+
+func TestHostPolicy_NoPrivateIPsResolves(t *testing.T) {
+	policy, err := NewHostPolicy(nil, nil, true)
+	if err != nil {
+		t.Fatalf("NewHostPolicy: %v", err)
+	}
+
+	lookups := 0
+	policy.lookup = func(ctx context.Context, host string) ([]netip.Addr, error) {
+		lookups++
+		if host == "rebind.example.com" {
+			return []netip.Addr{netip.MustParseAddr("93.184.216.34"), netip.MustParseAddr("::ffff:10.0.0.1")}, nil
+		}
+		return []netip.Addr{netip.MustParseAddr("93.184.216.34")}, nil
+	}
+
+	if err := policy.Check("https://example.com/"); err != nil {
+		t.Errorf("public host blocked: %v", err)
+	}
+	if err := policy.Check("https://example.com/other"); err != nil {
+		t.Errorf("public host blocked: %v", err)
+	}
+	if err := policy.Check("https://rebind.example.com/"); !errors.Is(err, ErrHostBlocked) {
+		t.Errorf("host resolving to a private address not blocked: %v", err)
+	}
+	if lookups != 2 {
+		t.Errorf("lookups = %d, want 2 (cached per host)", lookups)
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// htmlIndent is one level of --html-pretty indentation.
+const htmlIndent = "  "
+
+// inlineElements flow with the text around them. --html-pretty keeps them
+// on the line of their text; every other element starts a line of its own.
+var inlineElements = map[string]bool{
+	"a": true, "abbr": true, "b": true, "bdi": true, "bdo": true, "br": true,
+	"button": true, "cite": true, "code": true, "data": true, "del": true,
+	"dfn": true, "em": true, "i": true, "img": true, "input": true,
+	"ins": true, "kbd": true, "label": true, "mark": true, "meter": true,
+	"output": true, "picture": true, "progress": true, "q": true, "s": true,
+	"samp": true, "select": true, "small": true, "span": true,
+	"strong": true, "sub": true, "sup": true, "textarea": true, "time": true,
+	"u": true, "var": true, "wbr": true,
+}
+
+// verbatimElements keep their content exactly, since whitespace in them is
+// significant or they are not HTML. svg and math are written on one line.
+var verbatimElements = map[string]bool{
+	"pre": true, "textarea": true, "script": true, "style": true,
+	"svg": true, "math": true,
+}
+
+// prettyHTML reformats htmlContent with one block element per line,
+// indented by nesting, so page captures diff cleanly. Inline content stays
+// together on its line with runs of whitespace collapsed; pre, textarea,
+// script, and style are left untouched.
+func prettyHTML(htmlContent string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return "", err
+	}
+	collapseTextNodes(doc)
+
+	var b strings.Builder
+	for c := doc.FirstChild; c != nil; c = c.NextSibling {
+		if err := writePrettyNode(&b, c, 0); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+// minifyHTML writes htmlContent compactly: comments and whitespace that
+// browsers do not render are dropped, and other runs of whitespace are
+// collapsed to one space. Attributes and verbatim elements are unchanged.
+func minifyHTML(htmlContent string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return "", err
+	}
+	collapseTextNodes(doc)
+
+	var remove []*html.Node
+	for n := range doc.Descendants() {
+		switch {
+		case n.Type == html.CommentNode && !strings.HasPrefix(n.Data, "[if"):
+			remove = append(remove, n)
+		case n.Type == html.TextNode && strings.TrimSpace(n.Data) == "" && !insideVerbatim(n) && besideBlock(n):
+			remove = append(remove, n)
+		}
+	}
+	for _, n := range remove {
+		n.Parent.RemoveChild(n)
+	}
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, doc); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// collapseTextNodes collapses runs of whitespace in text outside verbatim
+// elements to a single space.
+func collapseTextNodes(doc *html.Node) {
+	for n := range doc.Descendants() {
+		if n.Type == html.TextNode && !insideVerbatim(n) {
+			n.Data = collapseSpace(n.Data)
+		}
+	}
+}
+
+// insideVerbatim reports whether n is within one of verbatimElements.
+func insideVerbatim(n *html.Node) bool {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode && verbatimElements[p.Data] {
+			return true
+		}
+	}
+	return false
+}
+
+// besideBlock reports whether whitespace text node n sits where browsers do
+// not render it: at the edge of a block, or next to a block element.
+func besideBlock(n *html.Node) bool {
+	if n.Parent == nil || isInlineNode(n.Parent) {
+		return false
+	}
+	prev, next := n.PrevSibling, n.NextSibling
+	return prev == nil || next == nil || !isInlineNode(prev) || !isInlineNode(next)
+}
+
+// isInlineNode reports whether n flows with the text around it.
+func isInlineNode(n *html.Node) bool {
+	switch n.Type {
+	case html.TextNode:
+		return true
+	case html.ElementNode:
+		return inlineElements[n.Data]
+	}
+	return false
+}
+
+func writePrettyNode(b *strings.Builder, n *html.Node, depth int) error {
+	indent := strings.Repeat(htmlIndent, depth)
+
+	if n.Type != html.ElementNode || verbatimElements[n.Data] || n.FirstChild == nil {
+		s, err := renderNode(n)
+		if err != nil {
+			return err
+		}
+		if n.Type == html.TextNode {
+			s = strings.TrimSpace(s)
+		}
+		if s != "" {
+			b.WriteString(indent + s + "\n")
+		}
+		return nil
+	}
+
+	startTag, endTag := elementTags(n)
+
+	if !hasBlockChild(n) {
+		inner, err := renderInline(n.FirstChild, nil)
+		if err != nil {
+			return err
+		}
+		b.WriteString(indent + startTag + strings.TrimSpace(inner) + endTag + "\n")
+		return nil
+	}
+
+	b.WriteString(indent + startTag + "\n")
+	for c := n.FirstChild; c != nil; {
+		if !isInlineNode(c) {
+			if err := writePrettyNode(b, c, depth+1); err != nil {
+				return err
+			}
+			c = c.NextSibling
+			continue
+		}
+
+		// A run of text and inline elements goes on one line
+		stop := c
+		for stop != nil && isInlineNode(stop) {
+			stop = stop.NextSibling
+		}
+		run, err := renderInline(c, stop)
+		if err != nil {
+			return err
+		}
+		if run = strings.TrimSpace(run); run != "" {
+			b.WriteString(indent + htmlIndent + run + "\n")
+		}
+		c = stop
+	}
+	b.WriteString(indent + endTag + "\n")
+	return nil
+}
+
+// hasBlockChild reports whether any child of n starts a line of its own.
+func hasBlockChild(n *html.Node) bool {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if !isInlineNode(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// renderInline renders the siblings from first up to, not including, end.
+func renderInline(first, end *html.Node) (string, error) {
+	var b strings.Builder
+	for c := first; c != end; c = c.NextSibling {
+		s, err := renderNode(c)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(s)
+	}
+	return b.String(), nil
+}
+
+func renderNode(n *html.Node) (string, error) {
+	var buf bytes.Buffer
+	if err := html.Render(&buf, n); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// elementTags returns the start and end tags of element n.
+func elementTags(n *html.Node) (string, string) {
+	var b strings.Builder
+	b.WriteString("<" + n.Data)
+	for _, a := range n.Attr {
+		key := a.Key
+		if a.Namespace != "" {
+			key = a.Namespace + ":" + key
+		}
+		fmt.Fprintf(&b, " %s=\"%s\"", key, html.EscapeString(a.Val))
+	}
+	b.WriteString(">")
+	return b.String(), "</" + n.Data + ">"
+}
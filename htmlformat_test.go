@@ -0,0 +1,84 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"testing"
+)
+
+const htmlFormatInput = `<!DOCTYPE html>
+<html><head><title>Docs</title>
+<!-- build 42 -->
+</head>
+<body>
+  <div class="main">
+    <h1>Hello   <em>world</em></h1>
+    <p>One <a href="/x?a=1&amp;b=2">two</a>
+    three</p>
+    <pre>  keep
+     this</pre>
+    <ul><li>a</li><li>b <b>c</b></li></ul>
+    <div>text <p>block</p> tail</div>
+  </div>
+</body></html>`
+
+func TestPrettyHTML(t *testing.T) {
+	expected := `<!DOCTYPE html>
+<html>
+  <head>
+    <title>Docs</title>
+    <!-- build 42 -->
+  </head>
+  <body>
+    <div class="main">
+      <h1>Hello <em>world</em></h1>
+      <p>One <a href="/x?a=1&amp;b=2">two</a> three</p>
+      <pre>  keep
+     this</pre>
+      <ul>
+        <li>a</li>
+        <li>b <b>c</b></li>
+      </ul>
+      <div>
+        text
+        <p>block</p>
+        tail
+      </div>
+    </div>
+  </body>
+</html>
+`
+
+	got, err := prettyHTML(htmlFormatInput)
+	if err != nil {
+		t.Fatalf("prettyHTML failed: %v", err)
+	}
+	if got != expected {
+		t.Errorf("prettyHTML:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestMinifyHTML(t *testing.T) {
+	expected := `<!DOCTYPE html><html><head><title>Docs</title></head><body><div class="main">` +
+		`<h1>Hello <em>world</em></h1><p>One <a href="/x?a=1&amp;b=2">two</a> three</p>` +
+		"<pre>  keep\n     this</pre>" +
+		`<ul><li>a</li><li>b <b>c</b></li></ul><div>text <p>block</p> tail</div></div></body></html>`
+
+	got, err := minifyHTML(htmlFormatInput)
+	if err != nil {
+		t.Fatalf("minifyHTML failed: %v", err)
+	}
+	if got != expected {
+		t.Errorf("minifyHTML:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestCLI_HTMLPrettyAndMinify(t *testing.T) {
+	_, stderr, err := runSnag("--html-pretty", "--html-minify", "https://example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "none of the others can be")
+}
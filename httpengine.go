@@ -0,0 +1,127 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// MaxHTTPBodySize caps the response body --engine http reads, so a server
+// streaming without end cannot exhaust memory.
+const MaxHTTPBodySize = 64 << 20
+
+// httpEngine fetches pages with a plain HTTP GET and no browser, for
+// --engine http. Pages are converted from the HTML the server sends, so
+// content that scripts add is missing, but no browser needs to be
+// installed.
+type httpEngine struct {
+	client *http.Client
+}
+
+func (e *httpEngine) Start() error {
+	umbrella := time.Duration(timeout) * time.Second
+	e.client = &http.Client{Timeout: phaseTimeout(navTimeout, umbrella)}
+	logger.Verbose("Fetching over HTTP without a browser")
+	return nil
+}
+
+func (e *httpEngine) Load(url string) (*LoadedPage, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNavigationFailed, err)
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.8")
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+	for name, value := range requestHeaders {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := e.client.Do(req)
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return nil, fmt.Errorf("%w: %w", ErrPageLoadTimeout, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNavigationFailed, err)
+	}
+	defer resp.Body.Close()
+
+	logger.Debug("HTTP %d for %s", resp.StatusCode, url)
+	switch {
+	case resp.StatusCode == 401 || resp.StatusCode == 403:
+		logger.Error("Authentication required (HTTP %d)", resp.StatusCode)
+		logger.ErrorWithSuggestion(
+			"This page requires authentication",
+			"snag --engine http --header \"Authorization: Bearer $TOKEN\" "+url,
+		)
+		return nil, ErrAuthRequired
+	case resp.StatusCode >= 400:
+		logger.Warning("Server returned HTTP %d", resp.StatusCode)
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil &&
+		mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("%w: %s is %s, not HTML", ErrNavigationFailed, url, mediaType)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxHTTPBodySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if len(body) > MaxHTTPBodySize {
+		return nil, fmt.Errorf("%w: %s is larger than %s", ErrNavigationFailed, url, formatByteSize(MaxHTTPBodySize))
+	}
+
+	page := &LoadedPage{
+		URL:      url,
+		FinalURL: resp.Request.URL.String(),
+		HTML:     string(body),
+	}
+	page.Title = htmlTitle(page.HTML)
+	return page, nil
+}
+
+func (e *httpEngine) Screenshot() ([]byte, error) {
+	return nil, fmt.Errorf("--engine http cannot take screenshots")
+}
+
+func (e *httpEngine) Close() {
+	if e.client != nil {
+		e.client.CloseIdleConnections()
+	}
+}
+
+// htmlTitle returns the text of the first <title> in htmlContent, with
+// whitespace collapsed.
+func htmlTitle(htmlContent string) string {
+	tokenizer := html.NewTokenizer(strings.NewReader(htmlContent))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return ""
+		case html.StartTagToken:
+			if name, _ := tokenizer.TagName(); string(name) != "title" {
+				continue
+			}
+			if tokenizer.Next() != html.TextToken {
+				return ""
+			}
+			return strings.Join(strings.Fields(string(tokenizer.Text())), " ")
+		}
+	}
+}
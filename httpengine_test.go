@@ -0,0 +1,115 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPEngine_Load(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("User-Agent"); got != "snag-test" {
+			t.Errorf("User-Agent = %q, want snag-test", got)
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html><head><title>\n  New\n  page </title></head><body>ok</body></html>"))
+	})
+	for _, status := range []int{401, 403, 500} {
+		mux.HandleFunc(fmt.Sprintf("/status/%d", status), func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(status)
+			w.Write([]byte("<html><head><title>Sign in</title></head><body>status</body></html>"))
+		})
+	}
+	mux.HandleFunc("/data.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{}"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	saved := userAgent
+	userAgent = "snag-test"
+	defer func() { userAgent = saved }()
+
+	eng := &httpEngine{}
+	if err := eng.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer eng.Close()
+
+	page, err := eng.Load(server.URL + "/old")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if page.FinalURL != server.URL+"/new" {
+		t.Errorf("FinalURL = %q, want %q", page.FinalURL, server.URL+"/new")
+	}
+	if page.Title != "New page" {
+		t.Errorf("Title = %q, want %q", page.Title, "New page")
+	}
+
+	if _, err := eng.Load(server.URL + "/data.json"); !errors.Is(err, ErrNavigationFailed) {
+		t.Errorf("Load(json) error = %v, want ErrNavigationFailed", err)
+	}
+
+	for _, status := range []int{401, 403} {
+		if _, err := eng.Load(fmt.Sprintf("%s/status/%d", server.URL, status)); !errors.Is(err, ErrAuthRequired) {
+			t.Errorf("Load(%d) error = %v, want ErrAuthRequired", status, err)
+		}
+	}
+
+	// Other error pages are saved with a warning, as with Chromium
+	if page, err := eng.Load(server.URL + "/status/500"); err != nil || page.Title != "Sign in" {
+		t.Errorf("Load(500) = %+v, %v", page, err)
+	}
+}
+
+func TestHTMLTitle(t *testing.T) {
+	tests := []struct {
+		html string
+		want string
+	}{
+		{"<title>Simple</title>", "Simple"},
+		{"<html><head><meta charset=utf-8><title> Spaced   out </title></head></html>", "Spaced out"},
+		{"<title></title>", ""},
+		{"<p>No title</p>", ""},
+	}
+	for _, tt := range tests {
+		if got := htmlTitle(tt.html); got != tt.want {
+			t.Errorf("htmlTitle(%q) = %q, want %q", tt.html, got, tt.want)
+		}
+	}
+}
+
+func TestEngineSupports(t *testing.T) {
+	tests := []struct {
+		engine string
+		caps   Capability
+		want   bool
+	}{
+		{EngineChromium, CapPDF | CapDevTools, true},
+		{EngineWebKit, CapScreenshot, true},
+		{EngineWebKit, CapPDF, false},
+		{EngineHTTP, CapHeaders, true},
+		{EngineHTTP, CapJavaScript, false},
+		{"gecko", CapHeaders, false},
+	}
+	for _, tt := range tests {
+		if got := engineSupports(tt.engine, tt.caps); got != tt.want {
+			t.Errorf("engineSupports(%s, %b) = %v, want %v", tt.engine, tt.caps, got, tt.want)
+		}
+	}
+}
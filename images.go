@@ -0,0 +1,90 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-rod/rod"
+)
+
+// ImageInfo describes one image for --images-report. MissingAlt is set when
+// the image has no text alternative at all; an empty alt marks it as
+// Decorative instead, which is correct for images that carry no meaning.
+type ImageInfo struct {
+	Src        string `json:"src"`
+	Alt        string `json:"alt"`
+	MissingAlt bool   `json:"missing_alt"`
+	Decorative bool   `json:"decorative"`
+	Caption    string `json:"caption"`
+}
+
+// imagesScript lists the page's images with the attributes that give them
+// an accessible name, and the caption of the figure they are in.
+const imagesScript = `() => [...document.images].map(img => {
+	const caption = img.closest('figure')?.querySelector('figcaption');
+	const role = (img.getAttribute('role') || '').toLowerCase();
+	return {
+		src: img.currentSrc || img.src,
+		alt: img.getAttribute('alt'),
+		label: img.getAttribute('aria-label') || (img.hasAttribute('aria-labelledby') ? 'labelledby' : ''),
+		hidden: role === 'presentation' || role === 'none' || img.getAttribute('aria-hidden') === 'true',
+		caption: caption ? caption.textContent.replace(/\s+/g, ' ').trim() : '',
+	};
+})`
+
+// extractImages returns every image on page, in document order.
+func extractImages(page *rod.Page) ([]ImageInfo, error) {
+	res, err := page.Eval(imagesScript)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list page images: %w", err)
+	}
+
+	images := []ImageInfo{}
+	for _, v := range res.Value.Arr() {
+		img := ImageInfo{
+			Src:     v.Get("src").Str(),
+			Caption: v.Get("caption").Str(),
+		}
+		hasAlt := !v.Get("alt").Nil()
+		if hasAlt {
+			img.Alt = strings.TrimSpace(v.Get("alt").Str())
+		}
+		labelled := v.Get("label").Str() != ""
+		hidden := v.Get("hidden").Bool()
+
+		img.Decorative = hidden || (hasAlt && img.Alt == "" && !labelled)
+		img.MissingAlt = !hasAlt && !labelled && !hidden
+		images = append(images, img)
+	}
+	return images, nil
+}
+
+// reportImages logs the --images-report audit: a count, then each image
+// without alt text.
+func reportImages(images []ImageInfo) {
+	missing := slices.DeleteFunc(slices.Clone(images), func(img ImageInfo) bool { return !img.MissingAlt })
+
+	logger.Info("Images: %d, %d missing alt text", len(images), len(missing))
+	for _, img := range missing {
+		if img.Caption != "" {
+			logger.Warning("Missing alt text: %s (caption: %s)", img.Src, img.Caption)
+		} else {
+			logger.Warning("Missing alt text: %s", img.Src)
+		}
+	}
+}
+
+// withImagesField adds "images" to fields for --images-report.
+func withImagesField(fields []string) []string {
+	if !imagesReport || slices.Contains(fields, "images") {
+		return fields
+	}
+	return append(slices.Clone(fields), "images")
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWithImagesField(t *testing.T) {
+	defer func() { imagesReport = false }()
+
+	imagesReport = false
+	if got := withImagesField(DefaultInfoFields); slices.Contains(got, "images") {
+		t.Errorf("withImagesField without --images-report = %v", got)
+	}
+
+	imagesReport = true
+	got := withImagesField(DefaultInfoFields)
+	if got[len(got)-1] != "images" {
+		t.Errorf("withImagesField = %v, want images last", got)
+	}
+	if slices.Contains(DefaultInfoFields, "images") {
+		t.Error("withImagesField modified DefaultInfoFields")
+	}
+}
+
+func TestBrowser_ImagesReport(t *testing.T) {
+	if !isBrowserAvailable() {
+		t.Skip("Browser not available, skipping browser integration test")
+	}
+
+	server := startTestServer(t)
+
+	stdout, _, err := runSnag("--info", "--images-report", server.URL+"/figures.html")
+	assertNoError(t, err)
+
+	var info struct {
+		Images []ImageInfo `json:"images"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &info); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout)
+	}
+	if len(info.Images) != 5 {
+		t.Fatalf("expected 5 images, got %d: %+v", len(info.Images), info.Images)
+	}
+
+	expected := []struct {
+		alt        string
+		caption    string
+		missing    bool
+		decorative bool
+	}{
+		{"Company logo", "Our logo, 2025", false, false},
+		{"", "Quarterly results", true, false},
+		{"", "", false, true},
+		{"", "", false, false},
+		{"", "", false, true},
+	}
+	for i, want := range expected {
+		got := info.Images[i]
+		if got.Alt != want.alt || got.Caption != want.caption || got.MissingAlt != want.missing || got.Decorative != want.decorative {
+			t.Errorf("image %d = %+v, want %+v", i+1, got, want)
+		}
+		if !strings.Contains(got.Src, "/logo.svg") {
+			t.Errorf("image %d src = %s", i+1, got.Src)
+		}
+	}
+
+	_, stderr, err := runSnag("--images-report", "-o", t.TempDir()+"/page.md", server.URL+"/figures.html")
+	assertNoError(t, err)
+	assertContains(t, stderr, "Images: 5, 1 missing alt text")
+	assertContains(t, stderr, "caption: Quarterly results")
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+const IncrementalCheckTimeout = 15 * time.Second
+
+// errContentUnchanged is returned when a re-fetched page hashes the same as
+// its previous version, so its output is not written again.
+var errContentUnchanged = errors.New("content unchanged")
+
+// errNotModified is returned when the server answers a conditional request
+// for a page with 304 Not Modified, so it is not fetched again.
+var errNotModified = errors.New("not modified")
+
+// Fingerprint identifies a fetched version of a page so later --incremental
+// runs can tell whether it changed.
+type Fingerprint struct {
+	ETag         string
+	LastModified string
+	Hash         string
+}
+
+// incrementalClient does not follow redirects: a page that now redirects
+// has changed, and the new location has not been checked against the host
+// policy.
+var incrementalClient = &http.Client{
+	Timeout: IncrementalCheckTimeout,
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// hashContent returns the hex SHA-256 of page HTML.
+func hashContent(html string) string {
+	sum := sha256.Sum256([]byte(html))
+	return hex.EncodeToString(sum[:])
+}
+
+func isHTTPURL(url string) bool {
+	lower := strings.ToLower(url)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// checkUnchanged sends a conditional GET with the ETag and Last-Modified
+// validators saved from the previous fetch and reports whether the server
+// answered 304 Not Modified. The request obeys the host policy and carries
+// the headers and browser cookies page would send, so it reaches the same
+// content as the fetch it may save. Without validators, or on any error,
+// the page is treated as changed.
+func checkUnchanged(page *rod.Page, url string, rec *URLRecord) bool {
+	if rec == nil || (rec.ETag == "" && rec.LastModified == "") || !isHTTPURL(url) {
+		return false
+	}
+
+	policy, err := activeHostPolicy()
+	if err != nil || policy.Check(url) != nil {
+		return false
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return false
+	}
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+	for name, value := range requestHeaders {
+		req.Header.Set(name, value)
+	}
+	for name, value := range headersForURL(hostHeaderRules, url) {
+		req.Header.Set(name, value)
+	}
+	if page != nil {
+		cookies, err := page.Cookies([]string{url})
+		if err != nil {
+			logger.Debug("Failed to get cookies for %s: %v", url, err)
+		}
+		for _, c := range cookies {
+			req.AddCookie(&http.Cookie{Name: c.Name, Value: c.Value})
+		}
+	}
+	if rec.ETag != "" {
+		req.Header.Set("If-None-Match", rec.ETag)
+	}
+	if rec.LastModified != "" {
+		req.Header.Set("If-Modified-Since", rec.LastModified)
+	}
+
+	resp, err := incrementalClient.Do(req)
+	if err != nil {
+		logger.Debug("Conditional request for %s failed: %v", url, err)
+		return false
+	}
+	resp.Body.Close()
+
+	logger.Debug("Conditional request for %s: HTTP %d", url, resp.StatusCode)
+	return resp.StatusCode == http.StatusNotModified
+}
+
+// responseFingerprint returns the fingerprint of a fetched page from the hash
+// of its HTML and the validators of the document response, so no extra
+// request is needed to learn them.
+func responseFingerprint(fetcher *PageFetcher, hash string) Fingerprint {
+	fp := Fingerprint{Hash: hash}
+	if fetcher.Status() == http.StatusOK {
+		headers := fetcher.Headers()
+		fp.ETag, fp.LastModified = headers["etag"], headers["last-modified"]
+	}
+	return fp
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func newValidatorServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v2"`)
+		w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		if r.Header.Get("If-None-Match") == `"v2"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte("<html><body>v2</body></html>"))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCheckUnchanged(t *testing.T) {
+	server := newValidatorServer(t)
+
+	tests := []struct {
+		name string
+		rec  *URLRecord
+		want bool
+	}{
+		{"matching etag", &URLRecord{ETag: `"v2"`}, true},
+		{"stale etag", &URLRecord{ETag: `"v1"`}, false},
+		{"no validators", &URLRecord{Hash: "abc"}, false},
+		{"no record", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkUnchanged(nil, server.URL, tt.rec); got != tt.want {
+				t.Errorf("checkUnchanged = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckUnchanged_Flags(t *testing.T) {
+	var hits int
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		auth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer server.Close()
+
+	savedHeaders, savedBlock := requestHeaders, blockHosts
+	defer func() { requestHeaders, blockHosts = savedHeaders, savedBlock }()
+
+	rec := &URLRecord{ETag: `"v1"`}
+	requestHeaders = map[string]string{"Authorization": "Bearer token"}
+	if !checkUnchanged(nil, server.URL, rec) {
+		t.Error("expected 304 to report unchanged")
+	}
+	if auth != "Bearer token" {
+		t.Errorf("Authorization = %q, want the --header value", auth)
+	}
+
+	blockHosts = []string{"127.0.0.1"}
+	if checkUnchanged(nil, server.URL, rec) {
+		t.Error("expected a blocked host to be treated as changed")
+	}
+	if hits != 1 {
+		t.Errorf("server hit %d times, want 1: blocked host was contacted", hits)
+	}
+}
+
+func TestHashContent(t *testing.T) {
+	if hashContent("<p>a</p>") != hashContent("<p>a</p>") {
+		t.Error("hash should be stable")
+	}
+	if hashContent("<p>a</p>") == hashContent("<p>b</p>") {
+		t.Error("different content should hash differently")
+	}
+}
+
+func TestCrawlState_Fingerprint(t *testing.T) {
+	state, err := OpenCrawlState(filepath.Join(t.TempDir(), "site.db"))
+	if err != nil {
+		t.Fatalf("OpenCrawlState failed: %v", err)
+	}
+	defer state.Close()
+
+	url := "https://example.com/"
+	if _, err := state.Enqueue([]string{url}); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if err := state.Record(url, "page.md", nil); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if err := state.SetFingerprint(url, Fingerprint{ETag: `"v1"`, Hash: "abc"}); err != nil {
+		t.Fatalf("SetFingerprint failed: %v", err)
+	}
+
+	rec, err := state.Get(url)
+	if err != nil || rec == nil {
+		t.Fatalf("Get = %v, %v", rec, err)
+	}
+	if rec.ETag != `"v1"` || rec.Hash != "abc" || rec.Checked == nil || rec.Output != "page.md" {
+		t.Errorf("unexpected record: %+v", rec)
+	}
+
+	if err := state.RecordUnchanged("https://missing.example/"); err == nil {
+		t.Error("expected error for URL not in state")
+	}
+}
+
+func TestCLI_IncrementalRequiresState(t *testing.T) {
+	_, stderr, err := runSnag("--incremental", "example.com", "example.org")
+	assertError(t, err)
+	assertContains(t, stderr, "--incremental requires --state")
+}
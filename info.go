@@ -7,11 +7,13 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -20,15 +22,33 @@ import (
 	"github.com/spf13/cobra"
 )
 
-// PageInfo represents metadata about a web page for JSON output.
+// PageInfo represents metadata about a web page for JSON output. Content,
+// Links, and Images are only filled in when requested with --fields. Status,
+// Headers, and Redirects describe the response that led to URL; they are
+// empty for existing tabs. Partial is set when --best-effort kept a page
+// that timed out.
 type PageInfo struct {
-	Title     string `json:"title"`
-	URL       string `json:"url"`
-	Domain    string `json:"domain"`
-	Slug      string `json:"slug"`
-	Timestamp string `json:"timestamp"`
+	Title     string            `json:"title"`
+	URL       string            `json:"url"`
+	Domain    string            `json:"domain"`
+	Slug      string            `json:"slug"`
+	Timestamp string            `json:"timestamp"`
+	Status    int               `json:"status"`
+	Partial   bool              `json:"partial"`
+	Headers   map[string]string `json:"headers"`
+	Canonical string            `json:"canonical"`
+	Redirects []Redirect        `json:"redirects"`
+	Content   string            `json:"content"`
+	Links     []string          `json:"links"`
+	Images    []ImageInfo       `json:"images"`
 }
 
+// InfoFields lists every field --fields accepts.
+var InfoFields = []string{"title", "url", "domain", "slug", "timestamp", "status", "partial", "headers", "canonical", "redirects", "content", "links", "images"}
+
+// DefaultInfoFields are the fields --info outputs without --fields.
+var DefaultInfoFields = []string{"title", "url", "domain", "slug", "timestamp", "status", "partial", "canonical", "redirects"}
+
 // ExtractPageInfo extracts metadata from a rod.Page and returns a PageInfo struct.
 func ExtractPageInfo(page *rod.Page) (*PageInfo, error) {
 	if page == nil {
@@ -41,7 +61,7 @@ func ExtractPageInfo(page *rod.Page) (*PageInfo, error) {
 	}
 
 	domain := extractDomain(pageInfo.URL)
-	slug := SlugifyTitle(pageInfo.Title, MaxSlugLength)
+	slug := TitleSlug(pageInfo.Title, MaxSlugLength)
 
 	return &PageInfo{
 		Title:     pageInfo.Title,
@@ -49,9 +69,87 @@ func ExtractPageInfo(page *rod.Page) (*PageInfo, error) {
 		Domain:    domain,
 		Slug:      slug,
 		Timestamp: time.Now().Format(time.RFC3339),
+		Headers:   map[string]string{},
+		Canonical: extractCanonical(page),
+		Redirects: []Redirect{},
 	}, nil
 }
 
+// ExtractPageContent fills in the Content, Links, and Images fields of info
+// when they are among fields.
+func ExtractPageContent(page *rod.Page, info *PageInfo, fields []string) error {
+	for _, field := range fields {
+		switch field {
+		case "content":
+			html, err := page.HTML()
+			if err != nil {
+				return fmt.Errorf("failed to get page HTML: %w", err)
+			}
+			content, err := NewContentConverter(FormatMarkdown).Convert(html)
+			if err != nil {
+				return err
+			}
+			info.Content = redactContent(content)
+
+		case "links":
+			links, err := extractLinks(page)
+			if err != nil {
+				return err
+			}
+			info.Links = links
+
+		case "images":
+			images, err := extractImages(page)
+			if err != nil {
+				return err
+			}
+			info.Images = images
+		}
+	}
+	return nil
+}
+
+// marshalPageInfo returns info as indented JSON holding only fields, in the
+// order given.
+func marshalPageInfo(info *PageInfo, fields []string) ([]byte, error) {
+	all, err := json.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(all, &values); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(field)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(values[field])
+	}
+	buf.WriteByte('}')
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// withHeadersField adds "headers" to fields for --show-headers.
+func withHeadersField(fields []string) []string {
+	if !showHeaders || slices.Contains(fields, "headers") {
+		return fields
+	}
+	return append(slices.Clone(fields), "headers")
+}
+
 // extractDomain extracts the domain from a URL string.
 func extractDomain(urlStr string) string {
 	parsedURL, err := url.Parse(urlStr)
@@ -72,9 +170,10 @@ func extractDomain(urlStr string) string {
 	return host
 }
 
-// OutputPageInfo writes the PageInfo as JSON to the specified output (stdout or file).
-func OutputPageInfo(info *PageInfo, outputFile string) error {
-	jsonData, err := json.MarshalIndent(info, "", "  ")
+// OutputPageInfo writes the selected fields of the PageInfo as JSON to the
+// specified output (stdout or file).
+func OutputPageInfo(info *PageInfo, fields []string, outputFile string) error {
+	jsonData, err := marshalPageInfo(info, fields)
 	if err != nil {
 		return fmt.Errorf("failed to marshal page info to JSON: %w", err)
 	}
@@ -107,6 +206,12 @@ func handleInfoFromURL(cmd *cobra.Command, urlStr string) error {
 		return err
 	}
 
+	fields, err := validateInfoFields(infoFields)
+	if err != nil {
+		return err
+	}
+	fields = withImagesField(withHeadersField(fields))
+
 	outputFile := strings.TrimSpace(output)
 	if cmd.Flags().Changed("output") && outputFile != "" {
 		if err := validateOutputPath(outputFile); err != nil {
@@ -125,10 +230,19 @@ func handleInfoFromURL(cmd *cobra.Command, urlStr string) error {
 
 	validatedWaitFor := validateWaitFor(waitFor, cmd.Flags().Changed("wait-for"))
 
+	networkConditions, err := validateThrottle(throttle)
+	if err != nil {
+		return err
+	}
+
 	bm := NewBrowserManager(BrowserOptions{
 		Port:          port,
 		ForceHeadless: forceHead,
 		UserDataDir:   validatedUserDataDir,
+		Throttle:      networkConditions,
+		ReadOnly:      readOnly,
+		Offline:       offline,
+		RemoteOnly:    k8sPod != "",
 	})
 
 	browserMutex.Lock()
@@ -161,6 +275,8 @@ func handleInfoFromURL(cmd *cobra.Command, urlStr string) error {
 		URL:     validatedURL,
 		Timeout: timeout,
 		WaitFor: validatedWaitFor,
+		Headers: requestHeaders,
+		Actions: pageActions,
 	})
 	if err != nil {
 		return err
@@ -170,8 +286,18 @@ func handleInfoFromURL(cmd *cobra.Command, urlStr string) error {
 	if err != nil {
 		return err
 	}
+	pageInfo.Status = fetcher.Status()
+	pageInfo.Partial = fetcher.Partial()
+	pageInfo.Headers = fetcher.Headers()
+	if redirects := fetcher.Redirects(); len(redirects) > 0 {
+		pageInfo.Redirects = redirects
+	}
+
+	if err := ExtractPageContent(page, pageInfo, fields); err != nil {
+		return err
+	}
 
-	return OutputPageInfo(pageInfo, outputFile)
+	return OutputPageInfo(pageInfo, fields, outputFile)
 }
 
 // handleInfoFromTab fetches page info from an existing tab and outputs as JSON.
@@ -185,10 +311,19 @@ func handleInfoFromTab(cmd *cobra.Command) error {
 	if cmd.Flags().Changed("user-agent") {
 		logger.Warning("--user-agent is ignored with --tab (cannot change existing tab's user agent)")
 	}
+	if showHeaders {
+		logger.Warning("--show-headers is ignored with --tab (the tab's response is not available)")
+	}
 	if cmd.Flags().Changed("user-data-dir") {
 		logger.Warning("--user-data-dir ignored when connecting to existing browser")
 	}
 
+	fields, err := validateInfoFields(infoFields)
+	if err != nil {
+		return err
+	}
+	fields = withImagesField(withHeadersField(fields))
+
 	outputFile := strings.TrimSpace(output)
 	if cmd.Flags().Changed("output") && outputFile != "" {
 		if err := validateOutputPath(outputFile); err != nil {
@@ -250,11 +385,20 @@ func handleInfoFromTab(cmd *cobra.Command) error {
 			}
 		}
 	}
+	if waitForJSExpr != "" {
+		if err := waitForJS(page, waitForJSExpr, time.Duration(timeout)*time.Second); err != nil {
+			return err
+		}
+	}
 
 	pageInfo, err := ExtractPageInfo(page)
 	if err != nil {
 		return err
 	}
 
-	return OutputPageInfo(pageInfo, outputFile)
+	if err := ExtractPageContent(page, pageInfo, fields); err != nil {
+		return err
+	}
+
+	return OutputPageInfo(pageInfo, fields, outputFile)
 }
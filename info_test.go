@@ -8,6 +8,8 @@ package main
 
 import (
 	"encoding/json"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -213,3 +215,117 @@ func TestExtractDomain_EdgeCases(t *testing.T) {
 		}
 	}
 }
+
+// TestMarshalPageInfo_Fields tests that only selected fields are output, in order
+func TestMarshalPageInfo_Fields(t *testing.T) {
+	info := &PageInfo{
+		Title:     "Test Page",
+		URL:       "https://example.com",
+		Domain:    "example.com",
+		Slug:      "test-page",
+		Timestamp: "2025-02-04T10:30:00+10:00",
+		Content:   "# Test Page\n",
+		Links:     []string{"https://example.com/about"},
+	}
+
+	jsonData, err := marshalPageInfo(info, []string{"url", "content", "links"})
+	if err != nil {
+		t.Fatalf("marshalPageInfo failed: %v", err)
+	}
+
+	expected := `{
+  "url": "https://example.com",
+  "content": "# Test Page\n",
+  "links": [
+    "https://example.com/about"
+  ]
+}`
+	if string(jsonData) != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", jsonData, expected)
+	}
+
+	jsonData, err = marshalPageInfo(info, DefaultInfoFields)
+	if err != nil {
+		t.Fatalf("marshalPageInfo failed: %v", err)
+	}
+	if strings.Contains(string(jsonData), "content") || strings.Contains(string(jsonData), "links") {
+		t.Errorf("default fields should not include content or links:\n%s", jsonData)
+	}
+}
+
+func TestValidateInfoFields(t *testing.T) {
+	fields, err := validateInfoFields(" Title, url ,title,,links")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(fields, ","); got != "title,url,links" {
+		t.Errorf("got %s, expected title,url,links", got)
+	}
+
+	if fields, _ := validateInfoFields(""); strings.Join(fields, ",") != strings.Join(DefaultInfoFields, ",") {
+		t.Errorf("expected default fields, got %v", fields)
+	}
+
+	if _, err := validateInfoFields("title,body"); err == nil {
+		t.Error("expected error for unknown field")
+	}
+}
+
+func TestCLI_FieldsRequiresInfo(t *testing.T) {
+	_, stderr, err := runSnag("--fields", "title", "example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "--fields requires --info")
+}
+
+func TestWithHeadersField(t *testing.T) {
+	defer func() { showHeaders = false }()
+
+	showHeaders = false
+	if got := withHeadersField(DefaultInfoFields); slices.Contains(got, "headers") {
+		t.Errorf("withHeadersField without --show-headers = %v", got)
+	}
+
+	showHeaders = true
+	got := withHeadersField(DefaultInfoFields)
+	if got[len(got)-1] != "headers" {
+		t.Errorf("withHeadersField = %v, want headers last", got)
+	}
+	if slices.Contains(DefaultInfoFields, "headers") {
+		t.Error("withHeadersField modified DefaultInfoFields")
+	}
+
+	fields := []string{"headers", "url"}
+	if got := withHeadersField(fields); len(got) != 2 {
+		t.Errorf("withHeadersField(%v) = %v, want unchanged", fields, got)
+	}
+}
+
+func TestMarshalPageInfo_Headers(t *testing.T) {
+	info := &PageInfo{Headers: map[string]string{"content-type": "text/html", "server": "nginx"}}
+
+	jsonData, err := marshalPageInfo(info, []string{"headers"})
+	if err != nil {
+		t.Fatalf("marshalPageInfo failed: %v", err)
+	}
+
+	for _, want := range []string{`"content-type": "text/html"`, `"server": "nginx"`} {
+		if !strings.Contains(string(jsonData), want) {
+			t.Errorf("missing %s in:\n%s", want, jsonData)
+		}
+	}
+}
+
+func TestMarshalPageInfo_Partial(t *testing.T) {
+	jsonData, err := marshalPageInfo(&PageInfo{Status: 200, Partial: true}, []string{"status", "partial"})
+	if err != nil {
+		t.Fatalf("marshalPageInfo failed: %v", err)
+	}
+
+	expected := `{
+  "status": 200,
+  "partial": true
+}`
+	if string(jsonData) != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", jsonData, expected)
+	}
+}
@@ -0,0 +1,344 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const MaxQueuedJobs = 1000
+
+// MaxFinishedJobs is how many done and failed jobs the queue keeps for
+// clients to read, in memory and in the jobs directory. The oldest are
+// dropped beyond it.
+const MaxFinishedJobs = 1000
+
+var ErrQueueFull = errors.New("job queue is full")
+
+type JobStatus string
+
+const (
+	JobQueued  JobStatus = "queued"
+	JobRunning JobStatus = "running"
+	JobDone    JobStatus = "done"
+	JobFailed  JobStatus = "failed"
+)
+
+// JobRequest is the body of POST /jobs. Either URL or URLs may be given.
+type JobRequest struct {
+	URL     string   `json:"url,omitempty"`
+	URLs    []string `json:"urls,omitempty"`
+	Format  string   `json:"format,omitempty"`
+	WaitFor string   `json:"wait_for,omitempty"`
+}
+
+// JobResult is the outcome of fetching one URL in a job.
+type JobResult struct {
+	URL    string `json:"url"`
+	Output string `json:"output,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Job is a batch of URLs fetched asynchronously by the job queue.
+type Job struct {
+	ID       string      `json:"id"`
+	Status   JobStatus   `json:"status"`
+	URLs     []string    `json:"urls"`
+	Format   string      `json:"format"`
+	WaitFor  string      `json:"wait_for,omitempty"`
+	Results  []JobResult `json:"results,omitempty"`
+	Created  time.Time   `json:"created"`
+	Started  *time.Time  `json:"started,omitempty"`
+	Finished *time.Time  `json:"finished,omitempty"`
+}
+
+// JobQueue holds submitted jobs and hands them to workers. When dir is set,
+// every job is persisted as JSON so queued and interrupted jobs resume after
+// a restart.
+type JobQueue struct {
+	mu           sync.Mutex
+	jobs         map[string]*Job
+	dir          string
+	pending      chan string
+	keepFinished int
+}
+
+// NewJobQueue creates a queue, loading any jobs persisted in dir. Jobs that
+// were queued or running when the server stopped are queued again.
+func NewJobQueue(dir string) (*JobQueue, error) {
+	q := &JobQueue{
+		jobs:         make(map[string]*Job),
+		dir:          dir,
+		pending:      make(chan string, MaxQueuedJobs),
+		keepFinished: MaxFinishedJobs,
+	}
+
+	if dir == "" {
+		return q, nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create jobs directory %s: %w", dir, err)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs in %s: %w", dir, err)
+	}
+
+	var resume []*Job
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			logger.Warning("Skipping unreadable job file %s: %v", path, err)
+			continue
+		}
+
+		var job Job
+		if err := json.Unmarshal(data, &job); err != nil || job.ID == "" {
+			logger.Warning("Skipping invalid job file %s", path)
+			continue
+		}
+
+		q.jobs[job.ID] = &job
+		if job.Status == JobQueued || job.Status == JobRunning {
+			resume = append(resume, &job)
+		}
+	}
+	q.evictFinished()
+
+	// Resume in submission order
+	sort.Slice(resume, func(i, j int) bool { return resume[i].Created.Before(resume[j].Created) })
+	for _, job := range resume {
+		job.Status = JobQueued
+		job.Started = nil
+		job.Results = nil
+		if err := q.save(job); err != nil {
+			logger.Warning("%v", err)
+		}
+		select {
+		case q.pending <- job.ID:
+		default:
+			logger.Warning("Job queue full, not resuming job %s", job.ID)
+		}
+	}
+
+	if len(q.jobs) > 0 {
+		logger.Info("Loaded %d job%s (%d resumed)", len(q.jobs), plural(len(q.jobs)), len(resume))
+	}
+
+	return q, nil
+}
+
+// remoteURL validates a URL sent by a client of snag serve.
+func remoteURL(u string) (string, error) {
+	v, err := validateURL(strings.TrimSpace(u))
+	if err != nil {
+		return "", fmt.Errorf("invalid URL '%s': %w", u, err)
+	}
+	// Remote callers must not be able to read files on the server
+	if strings.HasPrefix(strings.ToLower(v), "file://") {
+		return "", fmt.Errorf("file:// URLs are not allowed: %s", u)
+	}
+	return v, nil
+}
+
+// remoteFormat validates an output format sent by a client of snag serve,
+// defaulting to Markdown.
+func remoteFormat(value string) (string, error) {
+	if value == "" {
+		return FormatMarkdown, nil
+	}
+	switch f := normalizeFormat(value); f {
+	case FormatMarkdown, FormatHTML, FormatText, FormatReader, FormatOrg, FormatRST, FormatNotebook, FormatPDF, FormatPNG:
+		return f, nil
+	}
+	return "", fmt.Errorf("invalid format: %s", value)
+}
+
+// Submit validates req and queues a new job.
+func (q *JobQueue) Submit(req JobRequest) (*Job, error) {
+	urls := req.URLs
+	if req.URL != "" {
+		urls = append([]string{req.URL}, urls...)
+	}
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no URLs provided")
+	}
+
+	validated := make([]string, 0, len(urls))
+	for _, u := range urls {
+		v, err := remoteURL(u)
+		if err != nil {
+			return nil, err
+		}
+		validated = append(validated, v)
+	}
+
+	jobFormat, err := remoteFormat(req.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		return nil, err
+	}
+
+	job := &Job{
+		ID:      id,
+		Status:  JobQueued,
+		URLs:    validated,
+		Format:  jobFormat,
+		WaitFor: strings.TrimSpace(req.WaitFor),
+		Created: time.Now().UTC(),
+	}
+
+	q.mu.Lock()
+	q.jobs[id] = job
+	err = q.save(job)
+	q.mu.Unlock()
+	if err != nil {
+		logger.Warning("%v", err)
+	}
+
+	select {
+	case q.pending <- id:
+	default:
+		q.mu.Lock()
+		delete(q.jobs, id)
+		q.remove(id)
+		q.mu.Unlock()
+		return nil, ErrQueueFull
+	}
+
+	logger.Info("Queued job %s (%d URL%s)", id, len(validated), plural(len(validated)))
+	return q.Get(id)
+}
+
+// Get returns a snapshot of the job with the given ID.
+func (q *JobQueue) Get(id string) (*Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, ok := q.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("job not found: %s", id)
+	}
+
+	snapshot := *job
+	snapshot.Results = append([]JobResult(nil), job.Results...)
+	return &snapshot, nil
+}
+
+// Update applies fn to the job under the queue lock and persists it.
+func (q *JobQueue) Update(id string, fn func(*Job)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, ok := q.jobs[id]
+	if !ok {
+		return
+	}
+	fn(job)
+	if err := q.save(job); err != nil {
+		logger.Warning("%v", err)
+	}
+	if job.Status == JobDone || job.Status == JobFailed {
+		q.evictFinished()
+	}
+}
+
+// evictFinished drops the oldest done and failed jobs beyond
+// q.keepFinished. Callers must hold q.mu.
+func (q *JobQueue) evictFinished() {
+	var finished []*Job
+	for _, job := range q.jobs {
+		if job.Status == JobDone || job.Status == JobFailed {
+			finished = append(finished, job)
+		}
+	}
+	if len(finished) <= q.keepFinished {
+		return
+	}
+
+	sort.Slice(finished, func(i, j int) bool { return finishedAt(finished[i]).Before(finishedAt(finished[j])) })
+	for _, job := range finished[:len(finished)-q.keepFinished] {
+		delete(q.jobs, job.ID)
+		q.remove(job.ID)
+	}
+}
+
+// finishedAt is when job finished, or when it was created for jobs saved
+// without a finish time.
+func finishedAt(job *Job) time.Time {
+	if job.Finished != nil {
+		return *job.Finished
+	}
+	return job.Created
+}
+
+// Start runs workers goroutines that pass each queued job to process.
+func (q *JobQueue) Start(workers int, process func(id string)) {
+	for i := 0; i < workers; i++ {
+		go func() {
+			for id := range q.pending {
+				process(id)
+			}
+		}()
+	}
+}
+
+// save writes job to disk atomically. Callers must hold q.mu.
+func (q *JobQueue) save(job *Job) error {
+	if q.dir == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode job %s: %w", job.ID, err)
+	}
+
+	path := filepath.Join(q.dir, job.ID+".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, DefaultFileMode); err != nil {
+		return fmt.Errorf("failed to save job %s: %w", job.ID, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to save job %s: %w", job.ID, err)
+	}
+	return nil
+}
+
+// remove deletes a persisted job. Callers must hold q.mu.
+func (q *JobQueue) remove(id string) {
+	if q.dir == "" {
+		return
+	}
+	if err := os.Remove(filepath.Join(q.dir, id+".json")); err != nil && !os.IsNotExist(err) {
+		logger.Debug("Failed to remove job %s: %v", id, err)
+	}
+}
+
+func newJobID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate job ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
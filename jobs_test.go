@@ -0,0 +1,253 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJobQueue_Submit(t *testing.T) {
+	q, err := NewJobQueue("")
+	if err != nil {
+		t.Fatalf("NewJobQueue failed: %v", err)
+	}
+
+	job, err := q.Submit(JobRequest{URL: "example.com", URLs: []string{"https://example.org"}, Format: "txt"})
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+
+	if job.Status != JobQueued {
+		t.Errorf("expected status %q, got %q", JobQueued, job.Status)
+	}
+	if job.Format != FormatText {
+		t.Errorf("expected format %q, got %q", FormatText, job.Format)
+	}
+	if len(job.URLs) != 2 || job.URLs[0] != "https://example.com" {
+		t.Errorf("unexpected URLs: %v", job.URLs)
+	}
+
+	invalid := []JobRequest{
+		{},
+		{URL: "ftp://example.com"},
+		{URL: "file:///etc/passwd"},
+		{URL: "example.com", Format: "docx"},
+	}
+	for _, req := range invalid {
+		if _, err := q.Submit(req); err == nil {
+			t.Errorf("expected Submit(%+v) to fail", req)
+		}
+	}
+}
+
+func TestJobQueue_ResumesPersistedJobs(t *testing.T) {
+	dir := t.TempDir()
+
+	q, err := NewJobQueue(dir)
+	if err != nil {
+		t.Fatalf("NewJobQueue failed: %v", err)
+	}
+
+	queued, err := q.Submit(JobRequest{URL: "example.com"})
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	done, err := q.Submit(JobRequest{URL: "example.org"})
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	q.Update(done.ID, func(j *Job) { j.Status = JobDone })
+
+	reloaded, err := NewJobQueue(dir)
+	if err != nil {
+		t.Fatalf("NewJobQueue reload failed: %v", err)
+	}
+
+	if _, err := reloaded.Get(done.ID); err != nil {
+		t.Errorf("expected finished job to be loaded: %v", err)
+	}
+
+	select {
+	case id := <-reloaded.pending:
+		if id != queued.ID {
+			t.Errorf("expected queued job %s to resume, got %s", queued.ID, id)
+		}
+	default:
+		t.Fatal("expected queued job to be resumed")
+	}
+
+	if len(reloaded.pending) != 0 {
+		t.Errorf("expected only one resumed job, got %d more", len(reloaded.pending))
+	}
+}
+
+func TestJobQueue_EvictsFinishedJobs(t *testing.T) {
+	dir := t.TempDir()
+	q, err := NewJobQueue(dir)
+	if err != nil {
+		t.Fatalf("NewJobQueue failed: %v", err)
+	}
+	q.keepFinished = 2
+
+	var ids []string
+	for i := range 4 {
+		job, err := q.Submit(JobRequest{URL: fmt.Sprintf("example.com/%d", i)})
+		if err != nil {
+			t.Fatalf("Submit failed: %v", err)
+		}
+		ids = append(ids, job.ID)
+	}
+
+	// The last job stays queued and is never evicted
+	base := time.Now()
+	for i, id := range ids[:3] {
+		finished := base.Add(time.Duration(i) * time.Second)
+		q.Update(id, func(j *Job) { j.Status, j.Finished = JobDone, &finished })
+	}
+
+	if _, err := q.Get(ids[0]); err == nil {
+		t.Error("expected the oldest finished job to be evicted")
+	}
+	if _, err := os.Stat(filepath.Join(dir, ids[0]+".json")); !os.IsNotExist(err) {
+		t.Errorf("expected the evicted job file to be removed, got %v", err)
+	}
+	for _, id := range ids[1:] {
+		if _, err := q.Get(id); err != nil {
+			t.Errorf("expected job %s to be kept: %v", id, err)
+		}
+	}
+}
+
+func TestServer_JobsAPI(t *testing.T) {
+	q, err := NewJobQueue("")
+	if err != nil {
+		t.Fatalf("NewJobQueue failed: %v", err)
+	}
+	handler := (&Server{queue: q}).Handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/jobs", strings.NewReader(`{"url": "example.com", "format": "html"}`)))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("POST /jobs status = %d, body: %s", rec.Code, rec.Body.String())
+	}
+
+	var job Job
+	if err := json.Unmarshal(rec.Body.Bytes(), &job); err != nil {
+		t.Fatalf("failed to decode job: %v", err)
+	}
+	if rec.Header().Get("Location") != "/jobs/"+job.ID {
+		t.Errorf("unexpected Location header: %s", rec.Header().Get("Location"))
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs/"+job.ID, nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"status": "queued"`) {
+		t.Errorf("GET /jobs/{id} = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs/missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown job, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/jobs", strings.NewReader(`{"address": "example.com"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for unknown field, got %d", rec.Code)
+	}
+}
+
+func TestServer_FetchAPI_InvalidRequests(t *testing.T) {
+	handler := (&Server{}).Handler()
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"unknown field", `{"address": "example.com"}`, "invalid request body"},
+		{"missing URL", `{}`, "invalid URL"},
+		{"file URL", `{"url": "file:///etc/passwd"}`, "file:// URLs are not allowed"},
+		{"bad format", `{"url": "example.com", "format": "docx"}`, "invalid format: docx"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/fetch", strings.NewReader(tt.body)))
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("expected 400, got %d", rec.Code)
+			}
+			if !strings.Contains(rec.Body.String(), tt.want) {
+				t.Errorf("expected error containing %q, got %s", tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestFetchErrorStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{fmt.Errorf("%w: 10.0.0.1", ErrHostBlocked), http.StatusForbidden},
+		{fmt.Errorf("%w: 30s", ErrPageLoadTimeout), http.StatusGatewayTimeout},
+		{fmt.Errorf("%w: net::ERR_NAME_NOT_RESOLVED", ErrNavigationFailed), http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		if got := fetchErrorStatus(tt.err); got != tt.want {
+			t.Errorf("fetchErrorStatus(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+// TestBrowser_ServerFetch tests POST /fetch returning content from a pooled
+// tab that is reused for the next request
+func TestBrowser_ServerFetch(t *testing.T) {
+	if !isBrowserAvailable() {
+		t.Skip("Browser not available, skipping browser integration test")
+	}
+	logger = NewLogger(LevelQuiet)
+
+	bm := NewBrowserManager(BrowserOptions{Port: 9250, ForceHeadless: true})
+	if _, err := bm.Connect(); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	t.Cleanup(bm.Close)
+
+	pool := NewPagePool(bm, 1)
+	t.Cleanup(pool.Close)
+	handler := (&Server{bm: bm, pool: pool}).Handler()
+	server := startTestServer(t)
+
+	for _, format := range []string{"md", "html"} {
+		body := fmt.Sprintf(`{"url": %q, "format": %q}`, server.URL+"/simple.html", format)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/fetch", strings.NewReader(body)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("POST /fetch status = %d, body: %s", rec.Code, rec.Body.String())
+		}
+		if got := rec.Header().Get("Content-Type"); got != formatContentTypes[normalizeFormat(format)] {
+			t.Errorf("Content-Type = %q", got)
+		}
+		if !strings.Contains(rec.Body.String(), "Example Heading") {
+			t.Errorf("expected page content, got %s", rec.Body.String())
+		}
+	}
+
+	if n := len(pool.idle); n != 1 {
+		t.Errorf("expected the tab back in the pool, got %d idle", n)
+	}
+}
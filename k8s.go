@@ -0,0 +1,217 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// K8sPortForwardTimeout bounds how long kubectl may take to start
+// forwarding to the pod.
+const K8sPortForwardTimeout = 20 * time.Second
+
+// DefaultK8sBrowserPort is the remote debugging port the Chromium sidecar
+// is expected to listen on when --k8s-pod does not name one.
+const DefaultK8sBrowserPort = 9222
+
+// K8sPod is a --k8s-pod target: [namespace/]pod[:port].
+type K8sPod struct {
+	Namespace string
+	Name      string
+	Port      int
+}
+
+func (p K8sPod) String() string {
+	name := p.Name
+	if p.Namespace != "" {
+		name = p.Namespace + "/" + name
+	}
+	return fmt.Sprintf("%s:%d", name, p.Port)
+}
+
+// k8sNamePattern matches namespace and pod names (RFC 1123 subdomains).
+var k8sNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+
+// parseK8sPod parses [namespace/]pod[:port]. Without a namespace, kubectl
+// uses the one of the current context.
+func parseK8sPod(spec string) (K8sPod, error) {
+	pod := K8sPod{Port: DefaultK8sBrowserPort}
+
+	rest := strings.TrimSpace(spec)
+	if name, portText, ok := strings.Cut(rest, ":"); ok {
+		port, err := strconv.Atoi(portText)
+		if err != nil || port < 1 || port > 65535 {
+			return K8sPod{}, fmt.Errorf("invalid port in %s", spec)
+		}
+		pod.Port = port
+		rest = name
+	}
+	if namespace, name, ok := strings.Cut(rest, "/"); ok {
+		if !k8sNamePattern.MatchString(namespace) || len(namespace) > 63 {
+			return K8sPod{}, fmt.Errorf("invalid namespace: %s", namespace)
+		}
+		pod.Namespace = namespace
+		rest = name
+	}
+	if !k8sNamePattern.MatchString(rest) || len(rest) > 253 {
+		return K8sPod{}, fmt.Errorf("invalid pod name: %s", rest)
+	}
+	pod.Name = rest
+	return pod, nil
+}
+
+// portForwardLine matches kubectl's "Forwarding from 127.0.0.1:43817 -> 9222".
+var portForwardLine = regexp.MustCompile(`^Forwarding from 127\.0\.0\.1:(\d+) -> \d+`)
+
+// PortForward is a running kubectl port-forward to a pod's browser.
+type PortForward struct {
+	LocalPort int
+
+	cmd  *exec.Cmd
+	once sync.Once
+}
+
+// activePortForward is stopped by the signal handler, which exits without
+// running deferred calls.
+var (
+	activePortForward *PortForward
+	portForwardMutex  sync.Mutex
+)
+
+// startPortForward runs kubectl port-forward to pod on a free local port
+// and waits until it is forwarding. kubectl uses KUBECONFIG and the
+// current context, as it does on the command line.
+func startPortForward(kubectl string, pod K8sPod) (*PortForward, error) {
+	args := []string{"port-forward", "--address", "127.0.0.1"}
+	if pod.Namespace != "" {
+		args = append(args, "--namespace", pod.Namespace)
+	}
+	args = append(args, "pod/"+pod.Name, fmt.Sprintf(":%d", pod.Port))
+
+	logger.Debug("Running %s %s", kubectl, strings.Join(args, " "))
+	cmd := exec.Command(kubectl, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start kubectl: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start kubectl: %w", err)
+	}
+
+	ports := make(chan int, 1)
+	exited := make(chan struct{})
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			logger.Debug("kubectl: %s", scanner.Text())
+			if m := portForwardLine.FindStringSubmatch(scanner.Text()); m != nil {
+				port, _ := strconv.Atoi(m[1])
+				select {
+				case ports <- port:
+				default:
+				}
+			}
+		}
+		close(exited)
+	}()
+
+	pf := &PortForward{cmd: cmd}
+	select {
+	case pf.LocalPort = <-ports:
+		return pf, nil
+	case <-exited:
+		cmd.Wait()
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = "kubectl exited before forwarding"
+		}
+		return nil, fmt.Errorf("%w: port-forward to %s failed: %s", ErrBrowserConnection, pod, msg)
+	case <-time.After(K8sPortForwardTimeout):
+		pf.Stop()
+		return nil, fmt.Errorf("%w: port-forward to %s did not start within %s", ErrBrowserConnection, pod, K8sPortForwardTimeout)
+	}
+}
+
+// Stop ends the port-forward. It is safe to call more than once.
+func (pf *PortForward) Stop() {
+	pf.once.Do(func() {
+		if err := pf.cmd.Process.Kill(); err != nil {
+			logger.Debug("Failed to stop kubectl port-forward: %v", err)
+		}
+		pf.cmd.Wait()
+	})
+}
+
+// connectK8sPod forwards a local port to the browser in the --k8s-pod and
+// points --port at it. Call the returned function to stop forwarding.
+func connectK8sPod(spec string) (func(), error) {
+	pod, err := parseK8sPod(spec)
+	if err != nil {
+		logger.Error("Invalid --k8s-pod: %v", err)
+		logger.ErrorWithSuggestion(
+			"Name the pod as namespace/pod, with :port if its browser is not on 9222",
+			"snag --k8s-pod scraping/chromium-0 https://example.com",
+		)
+		return nil, err
+	}
+
+	kubectl, err := exec.LookPath("kubectl")
+	if err != nil {
+		logger.Error("--k8s-pod needs kubectl, which was not found in PATH")
+		logger.ErrorWithSuggestion(
+			"Install kubectl and configure access to the cluster",
+			"kubectl get pod "+pod.Name,
+		)
+		return nil, fmt.Errorf("%w: kubectl not found", ErrBrowserConnection)
+	}
+
+	logger.Verbose("Forwarding to browser in pod %s...", pod)
+	pf, err := startPortForward(kubectl, pod)
+	if err != nil {
+		logger.Error("%v", err)
+		logger.ErrorWithSuggestion(
+			"Check the pod is running and its browser listens with --remote-debugging-port",
+			fmt.Sprintf("kubectl port-forward %spod/%s :%d", podNamespaceArg(pod), pod.Name, pod.Port),
+		)
+		return nil, err
+	}
+	logger.Success("Forwarding 127.0.0.1:%d to pod %s", pf.LocalPort, pod)
+
+	portForwardMutex.Lock()
+	activePortForward = pf
+	portForwardMutex.Unlock()
+
+	port = pf.LocalPort
+	return stopActivePortForward, nil
+}
+
+func podNamespaceArg(pod K8sPod) string {
+	if pod.Namespace == "" {
+		return ""
+	}
+	return "-n " + pod.Namespace + " "
+}
+
+// stopActivePortForward stops the --k8s-pod port-forward, if any.
+func stopActivePortForward() {
+	portForwardMutex.Lock()
+	defer portForwardMutex.Unlock()
+	if activePortForward != nil {
+		activePortForward.Stop()
+		activePortForward = nil
+	}
+}
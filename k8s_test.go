@@ -0,0 +1,122 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestParseK8sPod(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected K8sPod
+		wantErr  bool
+	}{
+		{"chromium-0", K8sPod{Name: "chromium-0", Port: 9222}, false},
+		{"scraping/chromium-0", K8sPod{Namespace: "scraping", Name: "chromium-0", Port: 9222}, false},
+		{"scraping/chromium-0:9333", K8sPod{Namespace: "scraping", Name: "chromium-0", Port: 9333}, false},
+		{" chromium.browsers-0:9222 ", K8sPod{Name: "chromium.browsers-0", Port: 9222}, false},
+		{"", K8sPod{}, true},
+		{"scraping/", K8sPod{}, true},
+		{"/chromium-0", K8sPod{}, true},
+		{"Scraping/chromium-0", K8sPod{}, true},
+		{"scraping/chromium-0:0", K8sPod{}, true},
+		{"scraping/chromium-0:http", K8sPod{}, true},
+		{"a/b/c", K8sPod{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseK8sPod(tt.spec)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseK8sPod(%q) = %+v, want error", tt.spec, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseK8sPod(%q) error: %v", tt.spec, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("parseK8sPod(%q) = %+v, want %+v", tt.spec, got, tt.expected)
+		}
+	}
+}
+
+// fakeKubectl writes a kubectl stand-in that runs script.
+func fakeKubectl(t *testing.T, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake kubectl is a shell script")
+	}
+	path := filepath.Join(t.TempDir(), "kubectl")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestStartPortForward(t *testing.T) {
+	logger = NewLogger(LevelQuiet)
+	argsFile := filepath.Join(t.TempDir(), "args")
+	kubectl := fakeKubectl(t, `echo "$@" > `+argsFile+`
+echo "Forwarding from 127.0.0.1:45678 -> 9333"
+echo "Forwarding from [::1]:45678 -> 9333"
+exec sleep 60
+`)
+
+	pf, err := startPortForward(kubectl, K8sPod{Namespace: "scraping", Name: "chromium-0", Port: 9333})
+	if err != nil {
+		t.Fatalf("startPortForward() error: %v", err)
+	}
+	defer pf.Stop()
+
+	if pf.LocalPort != 45678 {
+		t.Errorf("LocalPort = %d, want 45678", pf.LocalPort)
+	}
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "port-forward --address 127.0.0.1 --namespace scraping pod/chromium-0 :9333"
+	if got := strings.TrimSpace(string(args)); got != want {
+		t.Errorf("kubectl args = %q, want %q", got, want)
+	}
+
+	pf.Stop()
+	pf.Stop()
+}
+
+func TestStartPortForward_Fails(t *testing.T) {
+	logger = NewLogger(LevelQuiet)
+	kubectl := fakeKubectl(t, `echo 'Error from server (NotFound): pods "chromium-0" not found' >&2
+exit 1
+`)
+
+	_, err := startPortForward(kubectl, K8sPod{Name: "chromium-0", Port: 9222})
+	if !errors.Is(err, ErrBrowserConnection) {
+		t.Fatalf("expected ErrBrowserConnection, got %v", err)
+	}
+	if !strings.Contains(err.Error(), `pods "chromium-0" not found`) {
+		t.Errorf("error should carry kubectl's message, got %v", err)
+	}
+}
+
+func TestCLI_K8sPodValidatesFlagsFirst(t *testing.T) {
+	// Without kubectl connecting fails, so only validation running first
+	// reports the flag error
+	t.Setenv("PATH", t.TempDir())
+
+	_, stderr, err := runSnag("--k8s-pod", "scraping/chromium-0", "--incremental", "https://example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "--incremental requires --state")
+	assertNotContains(t, stderr, "kubectl")
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+const (
+	// LatestRunName is the --latest-link pointer to the newest --run-dir run.
+	LatestRunName = "latest"
+
+	// LatestFilePrefix starts the --latest-link pointer to the newest
+	// snapshot of each page, so latest-example-page.md points at
+	// 2025-10-22-142033-example-page.md.
+	LatestFilePrefix = "latest-"
+)
+
+// latestCopies makes --latest-link copy snapshots instead of linking them,
+// since creating symlinks on Windows needs special privileges.
+var latestCopies = runtime.GOOS == "windows"
+
+// parseSnapshotName splits a generated filename or --run-dir directory name
+// into the time it was saved and the rest of the name, which is the same
+// for every snapshot of a page. ok is false for other names.
+func parseSnapshotName(name string) (saved time.Time, key string, ok bool) {
+	n := len(RunDirFormat)
+	if len(name) < n {
+		return time.Time{}, "", false
+	}
+	saved, err := time.ParseInLocation(RunDirFormat, name[:n], time.Local)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	if len(name) == n {
+		return saved, "", true
+	}
+	if name[n] != '-' || len(name) == n+1 {
+		return time.Time{}, "", false
+	}
+	return saved, name[n+1:], true
+}
+
+// linkLatestSnapshots points a latest- link in dir at the newest snapshot
+// of each page saved there, by this run or earlier ones.
+func linkLatestSnapshots(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	newest := make(map[string]string)
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		_, key, ok := parseSnapshotName(entry.Name())
+		if !ok || key == "" {
+			continue
+		}
+		// Timestamped names sort by time, and ReadDir sorts by name
+		newest[key] = entry.Name()
+	}
+
+	for key, name := range newest {
+		if err := updateLatestLink(dir, LatestFilePrefix+key, name); err != nil {
+			return err
+		}
+	}
+	logger.Verbose("Updated %d latest link%s in %s", len(newest), plural(len(newest)), dir)
+	return nil
+}
+
+// linkLatestRun points the latest link in parent at the run directory dir.
+func linkLatestRun(parent, dir string) error {
+	return updateLatestLink(parent, LatestRunName, filepath.Base(dir))
+}
+
+// updateLatestLink points the link name in dir at target, also in dir. The
+// link is replaced in one step, so readers never find it missing. With
+// latestCopies, target is copied to name instead.
+func updateLatestLink(dir, name, target string) error {
+	link := filepath.Join(dir, name)
+	if latestCopies {
+		return copyLatest(link, filepath.Join(dir, target))
+	}
+
+	if info, err := os.Lstat(link); err == nil && info.Mode()&os.ModeSymlink == 0 {
+		return fmt.Errorf("not replacing %s with a latest link: it is not a symlink", link)
+	}
+
+	tmp := filepath.Join(dir, "."+name+".tmp")
+	os.Remove(tmp)
+	if err := os.Symlink(target, tmp); err != nil {
+		return fmt.Errorf("failed to create latest link: %w", err)
+	}
+	if err := os.Rename(tmp, link); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to update latest link: %w", err)
+	}
+	logger.Debug("Linked %s to %s", link, target)
+	return nil
+}
+
+// copyLatest replaces dst with a copy of the file or directory src.
+func copyLatest(dst, src string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", src, err)
+	}
+	if err := os.RemoveAll(dst); err != nil {
+		return fmt.Errorf("failed to remove old %s: %w", dst, err)
+	}
+
+	if info.IsDir() {
+		if err := os.CopyFS(dst, os.DirFS(src)); err != nil {
+			return fmt.Errorf("failed to copy %s to %s: %w", src, dst, err)
+		}
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", src, err)
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, DefaultFileMode)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dst, err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to copy %s to %s: %w", src, dst, err)
+	}
+	return out.Close()
+}
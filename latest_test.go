@@ -0,0 +1,106 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseSnapshotName(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		ok   bool
+	}{
+		{"2025-10-22-142033-example-page.md", "example-page.md", true},
+		{"2025-10-22-142033", "", true},
+		{"2025-10-22-142033-2", "2", true},
+		{"2025-10-22-142033-", "", false},
+		{"2025-10-22-142033x", "", false},
+		{"2025-13-22-142033-page.md", "", false},
+		{"latest-example-page.md", "", false},
+		{"notes.md", "", false},
+	}
+
+	for _, tt := range tests {
+		saved, key, ok := parseSnapshotName(tt.name)
+		if ok != tt.ok || key != tt.key {
+			t.Errorf("parseSnapshotName(%q) = %q, %v, want %q, %v", tt.name, key, ok, tt.key, tt.ok)
+		}
+		if ok && !saved.Equal(time.Date(2025, 10, 22, 14, 20, 33, 0, time.Local)) {
+			t.Errorf("parseSnapshotName(%q) time = %v", tt.name, saved)
+		}
+	}
+}
+
+func TestLinkLatestSnapshots(t *testing.T) {
+	logger = NewLogger(LevelQuiet)
+	dir := t.TempDir()
+	for _, name := range []string{
+		"2025-10-21-090000-example-page.md",
+		"2025-10-22-142033-example-page.md",
+		"2025-10-22-142033-other-page.md",
+		"notes.md",
+	} {
+		assertNoError(t, os.WriteFile(filepath.Join(dir, name), []byte(name), DefaultFileMode))
+	}
+
+	assertNoError(t, linkLatestSnapshots(dir))
+	// Running again replaces the links
+	assertNoError(t, linkLatestSnapshots(dir))
+
+	for link, want := range map[string]string{
+		"latest-example-page.md": "2025-10-22-142033-example-page.md",
+		"latest-other-page.md":   "2025-10-22-142033-other-page.md",
+	} {
+		target, err := os.Readlink(filepath.Join(dir, link))
+		assertNoError(t, err)
+		if target != want {
+			t.Errorf("%s -> %s, want %s", link, target, want)
+		}
+	}
+	if _, err := os.Lstat(filepath.Join(dir, "latest-notes.md")); !os.IsNotExist(err) {
+		t.Error("expected no latest link for a file without a timestamp")
+	}
+}
+
+func TestUpdateLatestLink_NotSymlink(t *testing.T) {
+	dir := t.TempDir()
+	assertNoError(t, os.Mkdir(filepath.Join(dir, LatestRunName), 0755))
+
+	err := updateLatestLink(dir, LatestRunName, "2025-10-22-142033")
+	assertError(t, err)
+	assertContains(t, err.Error(), "not a symlink")
+}
+
+func TestUpdateLatestLink_Copy(t *testing.T) {
+	logger = NewLogger(LevelQuiet)
+	latestCopies = true
+	defer func() { latestCopies = false }()
+
+	dir := t.TempDir()
+	for _, run := range []string{"2025-10-21-090000", "2025-10-22-142033"} {
+		assertNoError(t, os.Mkdir(filepath.Join(dir, run), 0755))
+		assertNoError(t, os.WriteFile(filepath.Join(dir, run, RunManifestName), []byte(run), DefaultFileMode))
+		assertNoError(t, linkLatestRun(dir, filepath.Join(dir, run)))
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, LatestRunName, RunManifestName))
+	assertNoError(t, err)
+	if string(data) != "2025-10-22-142033" {
+		t.Errorf("latest copy has %q, want the newest run", data)
+	}
+}
+
+func TestCLI_LatestLinkRequiresOutputDir(t *testing.T) {
+	_, stderr, err := runSnag("--latest-link", "https://example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "--latest-link requires --output-dir")
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/go-rod/rod"
+)
+
+// MaxExamplePages is how many linking pages --links-report lists for each
+// external domain.
+const MaxExamplePages = 3
+
+// ExternalDomain is one entry of the --links-report: an external domain,
+// the distinct URLs linked on it, and the pages linking there. Count is
+// the number of page-to-URL links, counting each URL once per page.
+type ExternalDomain struct {
+	Domain       string   `json:"domain"`
+	Count        int      `json:"count"`
+	Pages        int      `json:"pages"`
+	Links        []string `json:"links"`
+	ExamplePages []string `json:"example_pages"`
+}
+
+// LinkInventory collects the external links of every page snagged in a
+// run for --links-report.
+type LinkInventory struct {
+	mu      sync.Mutex
+	domains map[string]*domainLinks
+}
+
+type domainLinks struct {
+	count int
+	links map[string]bool
+	pages []string
+}
+
+// linkInventory is the --links-report inventory of the current run.
+var linkInventory *LinkInventory
+
+func NewLinkInventory() *LinkInventory {
+	return &LinkInventory{domains: make(map[string]*domainLinks)}
+}
+
+// Add records the links of the page at pageURL that point to other
+// domains. Subdomains of the page's domain, and the reverse, are not
+// external.
+func (li *LinkInventory) Add(pageURL string, links []string) {
+	pageDomain := extractDomain(pageURL)
+	seen := make(map[string]bool)
+
+	li.mu.Lock()
+	defer li.mu.Unlock()
+
+	for _, link := range links {
+		u, err := url.Parse(link)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			continue
+		}
+		u.Fragment = ""
+		link = u.String()
+
+		domain := extractDomain(link)
+		if domain == "" || sameSite(domain, pageDomain) || seen[link] {
+			continue
+		}
+		seen[link] = true
+
+		d := li.domains[domain]
+		if d == nil {
+			d = &domainLinks{links: make(map[string]bool)}
+			li.domains[domain] = d
+		}
+		d.count++
+		d.links[link] = true
+		if !slices.Contains(d.pages, pageURL) {
+			d.pages = append(d.pages, pageURL)
+		}
+	}
+}
+
+// Report returns the external domains, most linked first. With
+// --redact-urls the links and example pages are redacted.
+func (li *LinkInventory) Report() []ExternalDomain {
+	li.mu.Lock()
+	defer li.mu.Unlock()
+
+	report := make([]ExternalDomain, 0, len(li.domains))
+	for domain, d := range li.domains {
+		links := make([]string, 0, len(d.links))
+		for link := range d.links {
+			links = append(links, redactIfEnabled(link))
+		}
+		slices.Sort(links)
+		// Links differing only in query values redact to the same URL
+		links = slices.Compact(links)
+
+		report = append(report, ExternalDomain{
+			Domain:       domain,
+			Count:        d.count,
+			Pages:        len(d.pages),
+			Links:        links,
+			ExamplePages: redactEachIfEnabled(d.pages[:min(len(d.pages), MaxExamplePages)]),
+		})
+	}
+	slices.SortFunc(report, func(a, b ExternalDomain) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), strings.Compare(a.Domain, b.Domain))
+	})
+	return report
+}
+
+// Write saves the report to path as JSON.
+func (li *LinkInventory) Write(path string) error {
+	report := li.Report()
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode links report: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), DefaultFileMode); err != nil {
+		return fmt.Errorf("failed to write links report: %w", err)
+	}
+	logger.Success("Saved %d external domain%s to %s", len(report), plural(len(report)), path)
+	return nil
+}
+
+// recordExternalLinks adds the links of page to the --links-report.
+func recordExternalLinks(page *rod.Page) {
+	info, err := page.Info()
+	if err != nil {
+		logger.Debug("Failed to get page info for --links-report: %v", err)
+		return
+	}
+	links, err := extractLinks(page)
+	if err != nil {
+		logger.Warning("%v", err)
+		return
+	}
+	linkInventory.Add(info.URL, links)
+}
+
+// sameSite reports whether domains a and b are the same or one is a
+// subdomain of the other.
+func sameSite(a, b string) bool {
+	return a == b || strings.HasSuffix(a, "."+b) || strings.HasSuffix(b, "."+a)
+}
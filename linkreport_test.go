@@ -0,0 +1,109 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLinkInventory(t *testing.T) {
+	li := NewLinkInventory()
+	li.Add("https://docs.example.com/a", []string{
+		"https://docs.example.com/b",
+		"https://example.com/",
+		"https://github.com/org/repo",
+		"https://github.com/org/repo#readme",
+		"https://www.github.com/org/other",
+		"mailto:team@example.com",
+		"https://npmjs.com/package/x",
+	})
+	li.Add("https://docs.example.com/b", []string{
+		"https://github.com/org/repo",
+	})
+	for _, page := range []string{"/c", "/d", "/e"} {
+		li.Add("https://docs.example.com"+page, []string{"https://npmjs.com/package/x"})
+	}
+
+	report := li.Report()
+	if len(report) != 2 {
+		t.Fatalf("expected 2 domains, got %d: %+v", len(report), report)
+	}
+
+	npm := report[0]
+	if npm.Domain != "npmjs.com" || npm.Count != 4 || npm.Pages != 4 {
+		t.Errorf("report[0] = %+v, want npmjs.com with 4 links on 4 pages", npm)
+	}
+	if len(npm.ExamplePages) != MaxExamplePages {
+		t.Errorf("expected %d example pages, got %v", MaxExamplePages, npm.ExamplePages)
+	}
+
+	gh := report[1]
+	if gh.Domain != "github.com" || gh.Count != 3 || gh.Pages != 2 {
+		t.Errorf("report[1] = %+v, want github.com with 3 links on 2 pages", gh)
+	}
+	wantLinks := []string{"https://github.com/org/repo", "https://www.github.com/org/other"}
+	if !slices.Equal(gh.Links, wantLinks) {
+		t.Errorf("github.com links = %v, want %v", gh.Links, wantLinks)
+	}
+}
+
+func TestLinkInventory_RedactURLs(t *testing.T) {
+	redactURLs = true
+	defer func() { redactURLs = false }()
+
+	li := NewLinkInventory()
+	li.Add("https://docs.example.com/a?session=secret", []string{
+		"https://github.com/login?token=one",
+		"https://github.com/login?token=two",
+	})
+
+	report := li.Report()
+	if len(report) != 1 {
+		t.Fatalf("expected 1 domain, got %d: %+v", len(report), report)
+	}
+	wantLinks := []string{"https://github.com/login?token=" + RedactedValue}
+	if !slices.Equal(report[0].Links, wantLinks) {
+		t.Errorf("links = %v, want %v", report[0].Links, wantLinks)
+	}
+	wantPages := []string{"https://docs.example.com/a?session=" + RedactedValue}
+	if !slices.Equal(report[0].ExamplePages, wantPages) {
+		t.Errorf("example pages = %v, want %v", report[0].ExamplePages, wantPages)
+	}
+}
+
+func TestLinkInventoryWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "links.json")
+
+	li := NewLinkInventory()
+	if err := li.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	assertNoError(t, err)
+	if string(data) != "[]\n" {
+		t.Errorf("empty report = %q, want []", data)
+	}
+
+	li.Add("https://example.com/", []string{"https://golang.org/doc"})
+	if err := li.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err = os.ReadFile(path)
+	assertNoError(t, err)
+
+	var report []ExternalDomain
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, data)
+	}
+	if len(report) != 1 || report[0].Domain != "golang.org" || report[0].ExamplePages[0] != "https://example.com/" {
+		t.Errorf("report = %+v", report)
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// localHTMLExtensions are the files collected when a directory is given
+// as an argument.
+var localHTMLExtensions = map[string]bool{
+	".html":  true,
+	".htm":   true,
+	".xhtml": true,
+}
+
+// isLocalPathArg reports whether a URL argument refers to local files
+// rather than a host. Arguments with a scheme are never local. Only
+// arguments that look like paths, or name an existing HTML file, are local:
+// a bare host such as example.com stays a URL even when a directory of that
+// name exists, as one does after a --mirror-paths run.
+func isLocalPathArg(arg string) bool {
+	if strings.Contains(arg, "://") {
+		return false
+	}
+
+	for _, prefix := range []string{"/", "./", "../", "~/", `.\`, `..\`} {
+		if strings.HasPrefix(arg, prefix) {
+			return true
+		}
+	}
+	if arg == "~" || filepath.IsAbs(arg) || strings.Contains(arg, "*") {
+		return true
+	}
+
+	info, err := os.Stat(arg)
+	if err != nil {
+		return false
+	}
+	if !info.IsDir() && localHTMLExtensions[strings.ToLower(filepath.Ext(arg))] {
+		return true
+	}
+	logger.Debug("Treating %s as a URL, use ./%s for the local path", arg, arg)
+	return false
+}
+
+// expandLocalPath turns a local file, directory, or glob argument into
+// file:// entries. Directories are searched recursively for HTML files and
+// globs support ** to match any number of directories. Entries from a
+// directory or glob are named after their path below it with ext, so the
+// source layout is kept in the output directory. It returns nil when arg is
+// not a local path.
+func expandLocalPath(arg, ext string) ([]URLEntry, error) {
+	if !isLocalPathArg(arg) {
+		return nil, nil
+	}
+	if arg == "~" || strings.HasPrefix(arg, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get home directory: %w", err)
+		}
+		arg = filepath.Join(home, arg[1:])
+	}
+
+	if !strings.ContainsAny(arg, "*?[") {
+		info, err := os.Stat(arg)
+		if err != nil {
+			logger.Error("File not found: %s", arg)
+			logger.ErrorWithSuggestion(
+				"Check the path, or add a scheme for remote URLs",
+				"snag https://example.com",
+			)
+			return nil, fmt.Errorf("file not found: %s", arg)
+		}
+
+		if !info.IsDir() {
+			fileURL, err := localFileURL(arg)
+			if err != nil {
+				return nil, err
+			}
+			return []URLEntry{{URL: fileURL}}, nil
+		}
+
+		entries, err := walkLocalFiles(arg, ext, func(rel string) bool {
+			return localHTMLExtensions[strings.ToLower(filepath.Ext(rel))]
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(entries) == 0 {
+			logger.Error("No HTML files found in directory: %s", arg)
+			logger.ErrorWithSuggestion(
+				"Use a glob to select other files",
+				fmt.Sprintf("snag '%s' -d output/", filepath.Join(arg, "**", "*.txt")),
+			)
+			return nil, ErrNoFilesMatched
+		}
+		return entries, nil
+	}
+
+	pattern := filepath.ToSlash(arg)
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		logger.Error("Invalid glob pattern: %s", arg)
+		return nil, fmt.Errorf("invalid glob pattern %s: %w", arg, err)
+	}
+
+	base, rest := splitGlob(pattern)
+	entries, err := walkLocalFiles(filepath.FromSlash(base), ext, func(rel string) bool {
+		return matchGlobSegments(rest, strings.Split(rel, "/"))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		logger.Error("No files match pattern: %s", arg)
+		logger.ErrorWithSuggestion(
+			"Check the pattern, and quote it so the shell does not expand it",
+			"snag './docs/**/*.html' -d output/",
+		)
+		return nil, ErrNoFilesMatched
+	}
+	return entries, nil
+}
+
+// splitGlob splits a slash-separated pattern into the leading directory
+// that contains no glob metacharacters and the remaining segments.
+func splitGlob(pattern string) (string, []string) {
+	segments := strings.Split(pattern, "/")
+
+	i := 0
+	for i < len(segments)-1 && !strings.ContainsAny(segments[i], "*?[") {
+		i++
+	}
+
+	base := strings.Join(segments[:i], "/")
+	if base == "" {
+		if strings.HasPrefix(pattern, "/") {
+			base = "/"
+		} else {
+			base = "."
+		}
+	}
+	return base, segments[i:]
+}
+
+// matchGlobSegments matches path segments against pattern segments, where a
+// ** segment matches zero or more path segments.
+func matchGlobSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchGlobSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+
+	matched, err := filepath.Match(pattern[0], segments[0])
+	return err == nil && matched && matchGlobSegments(pattern[1:], segments[1:])
+}
+
+// walkLocalFiles walks root and returns an entry for each regular file
+// whose slash-separated path relative to root is accepted by match.
+func walkLocalFiles(root, ext string, match func(rel string) bool) ([]URLEntry, error) {
+	var entries []URLEntry
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			logger.Warning("Skipping %s: %v", path, err)
+			return nil
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if !match(rel) {
+			return nil
+		}
+
+		fileURL, err := localFileURL(path)
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries, URLEntry{
+			URL:  fileURL,
+			Name: strings.TrimSuffix(rel, filepath.Ext(rel)) + ext,
+		})
+		return nil
+	})
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", root, err)
+	}
+
+	return entries, nil
+}
+
+// localFileURL returns the file:// URL for a local path.
+func localFileURL(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path %s: %w", path, err)
+	}
+
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(absPath)}
+	return u.String(), nil
+}
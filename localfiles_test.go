@@ -0,0 +1,131 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeLocalTree(t *testing.T, files ...string) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, name := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("<html></html>"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func entryNames(entries []URLEntry) string {
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name
+	}
+	return strings.Join(names, " ")
+}
+
+func TestExpandLocalPath(t *testing.T) {
+	logger = NewLogger(LevelQuiet)
+	root := writeLocalTree(t,
+		"index.html",
+		"notes.txt",
+		"guide/intro.htm",
+		"guide/deep/setup.html",
+	)
+
+	tests := []struct {
+		name string
+		arg  string
+		want string
+	}{
+		{"recursive glob", filepath.Join(root, "**", "*.html"), "guide/deep/setup.md index.md"},
+		{"single level glob", filepath.Join(root, "*", "*.htm"), "guide/intro.md"},
+		{"glob below static prefix", filepath.Join(root, "guide", "**", "*"), "deep/setup.md intro.md"},
+		{"directory", root, "guide/deep/setup.md guide/intro.md index.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := expandLocalPath(tt.arg, ".md")
+			if err != nil {
+				t.Fatalf("expandLocalPath failed: %v", err)
+			}
+			if got := entryNames(entries); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			for _, entry := range entries {
+				if !strings.HasPrefix(entry.URL, "file:///") {
+					t.Errorf("expected file URL, got %s", entry.URL)
+				}
+			}
+		})
+	}
+}
+
+func TestExpandLocalPath_File(t *testing.T) {
+	logger = NewLogger(LevelQuiet)
+	root := writeLocalTree(t, "my page.html")
+
+	entries, err := expandLocalPath(filepath.Join(root, "my page.html"), ".md")
+	if err != nil {
+		t.Fatalf("expandLocalPath failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name != "" || !strings.HasSuffix(entries[0].URL, "/my%20page.html") {
+		t.Errorf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestExpandLocalPath_NotLocal(t *testing.T) {
+	for _, arg := range []string{"example.com", "https://example.com/*", "example.com/search?q=go"} {
+		entries, err := expandLocalPath(arg, ".md")
+		if entries != nil || err != nil {
+			t.Errorf("expected %q to be treated as a URL, got %+v, %v", arg, entries, err)
+		}
+	}
+}
+
+func TestExpandLocalPath_HostDirectory(t *testing.T) {
+	logger = NewLogger(LevelQuiet)
+	root := writeLocalTree(t, "example.com/index.html", "page.html")
+	t.Chdir(root)
+
+	// --mirror-paths -d . leaves a directory named after the host
+	if entries, err := expandLocalPath("example.com", ".md"); entries != nil || err != nil {
+		t.Errorf("expected example.com to be treated as a URL, got %+v, %v", entries, err)
+	}
+
+	entries, err := expandLocalPath("./example.com", ".md")
+	if err != nil || entryNames(entries) != "index.md" {
+		t.Errorf("expected ./example.com to be local, got %+v, %v", entries, err)
+	}
+
+	entries, err = expandLocalPath("page.html", ".md")
+	if err != nil || len(entries) != 1 {
+		t.Errorf("expected page.html to be local, got %+v, %v", entries, err)
+	}
+}
+
+func TestExpandLocalPath_NoMatch(t *testing.T) {
+	logger = NewLogger(LevelQuiet)
+	root := writeLocalTree(t, "notes.txt")
+
+	if _, err := expandLocalPath(filepath.Join(root, "**", "*.html"), ".md"); !errors.Is(err, ErrNoFilesMatched) {
+		t.Errorf("expected ErrNoFilesMatched for glob, got %v", err)
+	}
+	if _, err := expandLocalPath(root, ".md"); !errors.Is(err, ErrNoFilesMatched) {
+		t.Errorf("expected ErrNoFilesMatched for directory, got %v", err)
+	}
+}
@@ -7,9 +7,14 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"strings"
+	"sync"
+	"time"
 )
 
 type LogLevel int
@@ -21,6 +26,11 @@ const (
 	LevelDebug
 )
 
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
 const (
 	colorReset  = "\033[0m"
 	colorRed    = "\033[31m"
@@ -29,21 +39,114 @@ const (
 	colorCyan   = "\033[36m"
 )
 
+// LogContext says what a log line is about. With --log-format json the
+// fields that are set are added to each line; text logs leave them out.
+type LogContext struct {
+	URL    string `json:"url,omitempty"`
+	Tab    int    `json:"tab,omitempty"`
+	Output string `json:"output,omitempty"`
+}
+
+// logEntry is one line of --log-format json.
+type logEntry struct {
+	Level      string `json:"level"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"`
+	Timestamp  string `json:"timestamp"`
+	LogContext
+}
+
 type Logger struct {
 	level  LogLevel
 	color  bool
+	redact bool
+	json   bool
 	writer io.Writer
+
+	mu      sync.Mutex
+	context LogContext
 }
 
 func NewLogger(level LogLevel) *Logger {
-	color := shouldUseColor()
+	jsonFormat := normalizeLogFormat(logFormat) == LogFormatJSON
 	return &Logger{
 		level:  level,
-		color:  color,
+		color:  !jsonFormat && shouldUseColor(),
+		redact: redactURLs,
+		json:   jsonFormat,
 		writer: os.Stderr,
 	}
 }
 
+// SetContext replaces the context added to later lines, as each URL or tab
+// of a batch starts. An empty LogContext clears it.
+func (l *Logger) SetContext(ctx LogContext) {
+	l.mu.Lock()
+	l.context = ctx
+	l.mu.Unlock()
+}
+
+// With returns a logger writing to the same place whose context has the
+// fields set in ctx added, for lines about one particular file.
+func (l *Logger) With(ctx LogContext) *Logger {
+	l.mu.Lock()
+	merged := l.context
+	l.mu.Unlock()
+
+	if ctx.URL != "" {
+		merged.URL = ctx.URL
+	}
+	if ctx.Tab != 0 {
+		merged.Tab = ctx.Tab
+	}
+	if ctx.Output != "" {
+		merged.Output = ctx.Output
+	}
+	return &Logger{
+		level:   l.level,
+		color:   l.color,
+		redact:  l.redact,
+		json:    l.json,
+		writer:  l.writer,
+		context: merged,
+	}
+}
+
+// writeJSON writes one --log-format json line. Each line is a single write,
+// so lines from concurrent fetches do not interleave.
+func (l *Logger) writeJSON(level, msg, suggestion string) {
+	l.mu.Lock()
+	ctx := l.context
+	l.mu.Unlock()
+	if l.redact {
+		ctx.URL = redactURL(ctx.URL)
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	err := encoder.Encode(logEntry{
+		Level:      level,
+		Message:    msg,
+		Suggestion: suggestion,
+		Timestamp:  time.Now().UTC().Format(time.RFC3339Nano),
+		LogContext: ctx,
+	})
+	if err != nil {
+		return
+	}
+	l.writer.Write(buf.Bytes())
+}
+
+// sprintf formats a message, masking URLs in it with --redact-urls.
+func (l *Logger) sprintf(format string, args ...interface{}) string {
+	msg := fmt.Sprintf(format, args...)
+	if l.redact {
+		msg = redactText(msg)
+	}
+	return msg
+}
+
 func shouldUseColor() bool {
 	// Respect NO_COLOR environment variable
 	if os.Getenv("NO_COLOR") != "" {
@@ -51,17 +154,16 @@ func shouldUseColor() bool {
 	}
 
 	// Check if stderr is a terminal (TTY)
-	fileInfo, err := os.Stderr.Stat()
-	if err != nil {
-		return false
-	}
-
-	return (fileInfo.Mode() & os.ModeCharDevice) != 0
+	return isTerminal(os.Stderr)
 }
 
 func (l *Logger) Success(format string, args ...interface{}) {
 	if l.level >= LevelNormal {
-		msg := fmt.Sprintf(format, args...)
+		msg := l.sprintf(format, args...)
+		if l.json {
+			l.writeJSON("success", msg, "")
+			return
+		}
 		prefix := "✓"
 		if l.color {
 			prefix = colorGreen + "✓" + colorReset
@@ -72,14 +174,22 @@ func (l *Logger) Success(format string, args ...interface{}) {
 
 func (l *Logger) Info(format string, args ...interface{}) {
 	if l.level >= LevelNormal {
-		msg := fmt.Sprintf(format, args...)
+		msg := l.sprintf(format, args...)
+		if l.json {
+			l.writeJSON("info", msg, "")
+			return
+		}
 		fmt.Fprintf(l.writer, "%s\n", msg)
 	}
 }
 
 func (l *Logger) Verbose(format string, args ...interface{}) {
 	if l.level >= LevelVerbose {
-		msg := fmt.Sprintf(format, args...)
+		msg := l.sprintf(format, args...)
+		if l.json {
+			l.writeJSON("verbose", msg, "")
+			return
+		}
 		if l.color {
 			msg = colorCyan + msg + colorReset
 		}
@@ -89,14 +199,22 @@ func (l *Logger) Verbose(format string, args ...interface{}) {
 
 func (l *Logger) Debug(format string, args ...interface{}) {
 	if l.level >= LevelDebug {
-		msg := fmt.Sprintf(format, args...)
+		msg := l.sprintf(format, args...)
+		if l.json {
+			l.writeJSON("debug", msg, "")
+			return
+		}
 		fmt.Fprintf(l.writer, "[DEBUG] %s\n", msg)
 	}
 }
 
 func (l *Logger) Warning(format string, args ...interface{}) {
 	if l.level >= LevelNormal {
-		msg := fmt.Sprintf(format, args...)
+		msg := l.sprintf(format, args...)
+		if l.json {
+			l.writeJSON("warning", msg, "")
+			return
+		}
 		prefix := "⚠"
 		if l.color {
 			prefix = colorYellow + "⚠" + colorReset
@@ -106,7 +224,11 @@ func (l *Logger) Warning(format string, args ...interface{}) {
 }
 
 func (l *Logger) Error(format string, args ...interface{}) {
-	msg := fmt.Sprintf(format, args...)
+	msg := l.sprintf(format, args...)
+	if l.json {
+		l.writeJSON("error", msg, "")
+		return
+	}
 	prefix := "✗"
 	if l.color {
 		prefix = colorRed + "✗" + colorReset
@@ -114,7 +236,28 @@ func (l *Logger) Error(format string, args ...interface{}) {
 	fmt.Fprintf(l.writer, "%s %s\n", prefix, msg)
 }
 
+// Trace writes a block of --trace-http output as it is. Tracing was asked
+// for explicitly, so it is written at every level, including --quiet.
+func (l *Logger) Trace(block string) {
+	if l.redact {
+		block = redactText(block)
+	}
+	if l.json {
+		l.writeJSON("trace", strings.TrimSuffix(block, "\n"), "")
+		return
+	}
+	io.WriteString(l.writer, block)
+}
+
 func (l *Logger) ErrorWithSuggestion(errMsg string, suggestion string) {
+	if l.redact {
+		errMsg = redactText(errMsg)
+		suggestion = redactText(suggestion)
+	}
+	if l.json {
+		l.writeJSON("error", errMsg, suggestion)
+		return
+	}
 	prefix := "✗"
 	if l.color {
 		prefix = colorRed + "✗" + colorReset
@@ -8,10 +8,12 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"os"
 	"strings"
 	"testing"
+	"time"
 )
 
 // newTestLogger creates a logger for testing that writes to a buffer
@@ -219,3 +221,57 @@ func TestNewLogger(t *testing.T) {
 		})
 	}
 }
+
+func TestLogger_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestLogger(LevelNormal, &buf)
+	logger.json = true
+
+	logger.SetContext(LogContext{URL: "https://example.com", Tab: 2})
+	logger.Info("Fetching %s...", "https://example.com")
+	logger.With(LogContext{Output: "page.md"}).Success("Saved to page.md")
+	logger.SetContext(LogContext{})
+	logger.ErrorWithSuggestion("Invalid --format: doc", "snag -f md <url>")
+	logger.Verbose("hidden at normal level")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d: %s", len(lines), buf.String())
+	}
+
+	var entries []logEntry
+	for _, line := range lines {
+		var entry logEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("line is not JSON: %s: %v", line, err)
+		}
+		if _, err := time.Parse(time.RFC3339Nano, entry.Timestamp); err != nil {
+			t.Errorf("invalid timestamp %q: %v", entry.Timestamp, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if e := entries[0]; e.Level != "info" || e.Message != "Fetching https://example.com..." || e.URL != "https://example.com" || e.Tab != 2 || e.Output != "" {
+		t.Errorf("unexpected info entry: %+v", e)
+	}
+	if e := entries[1]; e.Level != "success" || e.URL != "https://example.com" || e.Output != "page.md" {
+		t.Errorf("unexpected success entry: %+v", e)
+	}
+	if e := entries[2]; e.Level != "error" || e.Suggestion != "snag -f md <url>" || e.URL != "" {
+		t.Errorf("unexpected error entry: %+v", e)
+	}
+}
+
+func TestLogger_JSONRedactsContext(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestLogger(LevelNormal, &buf)
+	logger.json = true
+	logger.redact = true
+
+	logger.SetContext(LogContext{URL: "https://example.com/file?token=secret"})
+	logger.Info("Fetching")
+
+	if strings.Contains(buf.String(), "secret") {
+		t.Errorf("expected context URL to be redacted, got: %s", buf.String())
+	}
+}
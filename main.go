@@ -7,13 +7,16 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
+	"github.com/go-rod/rod/lib/proto"
 	"github.com/spf13/cobra"
 )
 
@@ -23,13 +26,21 @@ const (
 	FormatMarkdown = "md"
 	FormatHTML     = "html"
 	FormatText     = "text"
+	FormatOrg      = "org"
+	FormatRST      = "rst"
+	FormatReader   = "reader"
+	FormatNotebook = "ipynb"
 	FormatPDF      = "pdf"
 	FormatPNG      = "png"
+	FormatJSON     = "json" // result of --eval or --eval-expr
 )
 
 const (
 	ExitCodeSuccess   = 0
 	ExitCodeError     = 1
+	ExitCodeAssertion = 2   // --assert-contains or --assert-selector failed
+	ExitCodeBudget    = 3   // --max-bytes or --max-requests exceeded
+	ExitCodeHTTP      = 4   // --fail-on-error-status got a 4xx or 5xx
 	ExitCodeInterrupt = 130 // 128 + SIGINT (2)
 	ExitCodeSIGTERM   = 143 // 128 + SIGTERM (15)
 )
@@ -57,6 +68,17 @@ type Config struct {
 	OpenBrowser   bool
 	UserAgent     string
 	UserDataDir   string
+	Throttle      *proto.NetworkEmulateNetworkConditions
+	ReadOnly      bool
+	Offline       bool
+	RemoteOnly    bool
+	Headers       map[string]string
+	Actions       []PageAction
+
+	CaptureResponses string
+	EmitCurl         string
+	Record           string
+	RecordDuration   time.Duration
 }
 
 func (c *Config) BrowserOptions() BrowserOptions {
@@ -66,6 +88,10 @@ func (c *Config) BrowserOptions() BrowserOptions {
 		OpenBrowser:   c.OpenBrowser,
 		UserAgent:     c.UserAgent,
 		UserDataDir:   c.UserDataDir,
+		Throttle:      c.Throttle,
+		ReadOnly:      c.ReadOnly,
+		Offline:       c.Offline,
+		RemoteOnly:    c.RemoteOnly,
 	}
 }
 
@@ -76,28 +102,132 @@ var (
 )
 
 var (
-	urlFile     string
-	output      string
-	outputDir   string
-	format      string
-	timeout     int
-	waitFor     string
-	port        int
-	closeTab    bool
-	forceHead   bool
-	openBrowser bool
-	listTabs    bool
-	tab         string
-	allTabs     bool
-	killBrowser bool
-	doctor      bool
-	showVersion bool
-	info        bool
-	verbose     bool
-	quiet       bool
-	debug       bool
-	userAgent   string
-	userDataDir string
+	urlFile        string
+	output         string
+	outputDir      string
+	format         string
+	timeout        int
+	waitFor        string
+	port           int
+	k8sPod         string
+	closeTab       bool
+	forceHead      bool
+	openBrowser    bool
+	listTabs       bool
+	tab            string
+	allTabs        bool
+	watch          bool
+	engine         string
+	watchInterval  time.Duration
+	killBrowser    bool
+	doctor         bool
+	showVersion    bool
+	info           bool
+	verbose        bool
+	quiet          bool
+	debug          bool
+	userAgent      string
+	userDataDir    string
+	noActivate     bool
+	restoreScroll  bool
+	pdfA           bool
+	pdfStamp       bool
+	maxHeight      int
+	throttle       string
+	captureResp    string
+	emitCurl       string
+	matrix         string
+	record         string
+	recordDuration time.Duration
+	flowFile       string
+	assertText     []string
+	assertSelector []string
+	metricsListen  string
+	stream         bool
+	outputTar      string
+	stateFile      string
+	incremental    bool
+	eol            string
+	bom            bool
+	infoFields     string
+	splitBy        string
+	maxTokens      int
+	tokenOverflow  string
+	keepBoiler     bool
+	expectStatus   string
+	failOnStatus   bool
+	showHeaders    bool
+	traceHTTP      bool
+	redactURLs     bool
+	logFormat      string
+	allowHosts     []string
+	blockHosts     []string
+	blockResources []string
+	blockURLs      []string
+	noPrivateIPs   bool
+	maxBytes       string
+	maxRequests    int
+	memLimit       string
+	navTimeout     time.Duration
+	stabilizeTime  time.Duration
+	waitTimeout    time.Duration
+	bestEffort     bool
+	summaryFile    string
+	tui            bool
+	desktopNotify  bool
+	templateFile   string
+	textEngine     string
+	noExpand       bool
+	citeStyle      string
+	includeSource  bool
+	redact         []string
+	redactFile     string
+	headers        []string
+	cookiesFile    string
+	saveCookiesTo  string
+	annotate       bool
+	noPDFOutline   bool
+	pdfSplitBy     string
+	readability    bool
+	pdfSelector    string
+	selectCSS      string
+	fragmentOnly   bool
+	sanitize       bool
+	htmlPretty     bool
+	htmlMinify     bool
+	actionsFile    string
+	hostHeaders    string
+	authProviders  []string
+	assetsDir      string
+	screenshotFull bool
+	shotSelector   string
+	viewportSize   string
+	imagesReport   bool
+	linksReport    string
+	dumpHeaders    string
+	pdfPaper       string
+	pdfLandscape   bool
+	pdfMargins     string
+	pdfScale       float64
+	pdfHeader      string
+	pdfFooter      string
+	duplicates     string
+	crawl          bool
+	crawlDepth     int
+	allowDomains   []string
+	filenameLang   string
+	titleFrom      string
+	titleWait      time.Duration
+	runDir         bool
+	latestLink     bool
+	readOnly       bool
+	offline        bool
+	waitForJSExpr  string
+	evalFile       string
+	evalExpr       string
+	filenameTmpl   string
+	mirrorPaths    bool
+	noEnvExpand    bool
 )
 
 const helpTemplate = `USAGE:
@@ -108,7 +238,8 @@ DESCRIPTION:
   It can connect to existing browser sessions, launch headless browsers, or open
   visible browsers for authenticated sessions.
 
-  Output formats:  Markdown (md), HTML, text (txt), PDF, or PNG.
+  Output formats:  Markdown (md), HTML, text (txt), reader, Org mode, reStructuredText (rst),
+                   Jupyter notebook (ipynb), PDF, or PNG.
   Filename format: yyyy-mm-dd-hhmmss-<title>-<n>.<ext>
 
   The perfect companion for AI agents to gain context from web pages.
@@ -121,11 +252,32 @@ EXAMPLES:
   # Different output formats
   snag -f html example.com
   snag -f text example.com > page.txt
+  snag -f text --text-engine dom example.com  # Text as rendered, without hidden elements
+  snag --readability https://example.com/blog/post  # Article body only, like Reader Mode
+  snag --select "#main-content" example.com/docs  # Only the matching element(s)
+  snag --fragment-only "example.com/docs#install"  # Only the section the link points at
+  snag --url-file urls.txt -d site/ --assets-dir site/assets  # Images stored once per content
+  snag -f org -o page.org example.com  # Org mode; -f rst for reStructuredText
+  snag -f reader example.com | less    # Wrapped text with numbered link references
+  snag -f ipynb -o docs.ipynb example.com/docs  # Jupyter notebook, one cell per section
   snag -f pdf -o doc.pdf example.com
+  snag -f png --viewport 390x844 --screenshot-selector "#pricing" example.com  # One element at mobile width
+  snag -f text --eol crlf --bom -o page.txt example.com  # For Windows tools
+  snag --split-by h2 -d chunks/ example.com/docs  # One file per section
+  snag -f pdf --pdf-split-by h1 -o api.pdf example.com/api  # One PDF per section
+  snag -f pdf --pdf-paper a4 --pdf-margins 15mm -o report.pdf example.com  # A4 with 15mm margins
+  snag -f pdf --pdf-selector .invoice --tab billing -o invoice.pdf  # Print one element
+  snag --max-tokens 8000 example.com/docs          # Truncate to fit an LLM context budget
+  snag --template page.org.tmpl -o page.org example.com  # Custom output from a Go template
+  snag --cite apa -o notes.md example.com  # Append an APA source citation
+  snag --header "Authorization: Bearer $TOKEN" https://internal.example.com/dashboard
+  snag --cookies-file session.json --save-cookies session.json https://app.example.com  # Reuse a login
+  snag --redact '[\w.+-]+@[\w-]+\.[\w.]+' --redact 'sk-[A-Za-z0-9]{20,}' example.com  # Mask emails and API keys
 
   # Get page metadata as JSON
   snag --info example.com
   snag -i -t 1                         # Info from existing tab
+  snag -i --fields url,content,links example.com  # Page content for agents
 
   # Save to file
   snag -o page.md example.com
@@ -134,52 +286,216 @@ EXAMPLES:
   # Fetch multiple pages
   snag example.com github.com          # Auto-generated filenames to pwd
   snag -d output/ url1 url2 url3
+  snag './docs/**/*.html' -d out/      # Convert local files, keeping their layout
   snag --url-file urls.txt -d ./pages/
   cat urls.txt | snag --url-file -     # Read from stdin
   echo "example.com" | snag --url-file -
+  tail -f queue.txt | snag --url-file - --stream -d pages/  # Fetch as URLs arrive, JSONL results
+  snag --url-file urls.txt --output-tar - | ssh host tar -x -C pages/
+  snag --crawl --depth 2 -d docs/ https://example.com/docs/  # Archive a documentation site
+  snag --url-file urls.txt --state crawl.db -d pages/  # Resumable; rerun to continue
+  echo "example.com/docs intro.md" | snag --url-file - -d pages/  # Name the output file
+  snag --state crawl.db -d pages/      # Resume without the URL file
+  snag state crawl.db                  # Show what remains
+  snag --state site.db --incremental -d site/  # Only re-fetch pages that changed
+  snag --url-file urls.txt -d pages/ 2>&1 | grep '^snag: '  # ok=42 fail=3 skipped=5 duration=338s
+  snag --url-file big-crawl.txt --tui -d pages/  # Progress dashboard instead of scrolling logs
+  snag --url-file big-crawl.txt --notify-desktop -d pages/ &  # Get pinged when it's done
 
   # Work with browser tabs (index and listed in alphabetical order)
   snag --list-tabs                     # List all open tabs
   snag -t 1                            # Fetch first tab
   snag -t "github"                     # Match tab by URL pattern
   snag -t 2-5 -d tabs/                 # Fetch tabs 2 through 5
+  snag -t "mail" --no-activate         # Read tab without triggering focus side effects
   snag --all-tabs -d output/           # Fetch all open tabs
+  snag -t grafana --watch --interval 1m -d history/  # Save the tab each time it changes
 
   # Authenticated sessions
   snag --open-browser                  # Open browser, login manually
   snag -t "dashboard" -o data.md       # Fetch authenticated page
 
+  # Sign in without a browser, then send the token to GitHub only (see snag auth --help)
+  snag auth github --client-id Iv1.0123456789abcdef
+  snag --auth github https://github.com/org/private-repo/wiki
+
+  # Convert piped HTML without a browser (see snag convert --help)
+  curl -s example.com | snag convert - --base-url https://example.com
+
+  # Run as an HTTP job service (see snag serve --help)
+  snag serve --listen :8080 --jobs-dir jobs/ -d output/
+
+  # Delete old snapshots, keeping 10 of each page (see snag prune --help)
+  snag prune -d snapshots/ --keep 10 --older-than 30d
+
+  # Move cookies and crawl progress to another machine (see snag export-state --help)
+  snag export-state -o setup.tar.gz --cookies-file session.json --state crawl.db
+  snag import-state setup.tar.gz -d state/
+
+  # Show build provenance and dependencies as JSON
+  snag about --sbom
+
   # Advanced options
   snag --wait-for ".content" example.com
+  snag --click "#accept-cookies" --click ".show-more" example.com/thread  # Interact before capture
   snag --timeout 60 slow-site.com
+  snag --assert-selector ".price" --assert-contains "In stock" shop.example.com/item
+  snag --expect-status 404 example.com/removed-page
+  snag --show-headers -o page.md example.com
+  snag -V -o page.md example.com/old-link  # Trace requests and redirects like curl -v
+  snag --info --images-report example.com | jq '.images[] | select(.missing_alt)'  # Alt text audit
+  snag --url-file docs.txt -d docs/ --links-report links.json  # External links by domain
+  snag --redact-urls --url-file signed-urls.txt -d out/
+  snag --allow-host '*.example.com' --block-host 169.254.0.0/16 --url-file urls.txt -d out/
+  snag --no-private-ips --url-file untrusted-urls.txt -d out/
+  snag --max-bytes 50MB --max-requests 500 --url-file urls.txt -d out/
+  snag --block images,fonts,media,analytics --url-file urls.txt -d out/  # Text only, faster
+  snag --browser-memory-limit 2GB --url-file big-crawl.txt -d out/
+  snag --best-effort --timeout 20 --url-file archive.txt -d archive/
+  snag --capture-responses "*/api/*" -d data/ app.example.com
   snag --user-agent "Bot/1.0" example.com
+  snag --throttle slow-4g -f png example.com
+  snag --matrix "device=iPhone 14,Desktop;color-scheme=light,dark" -f png -d shots/ example.com
+  snag --record demo.gif --duration 15s example.com
+  snag --flow export.yaml -d exports/  # Run goto/click/fill/wait/snag steps
 
 OPTIONS:
   -l, --list-tabs              List all open tabs in the browser
   -t, --tab int|string         Fetch from existing tab by pattern (tab number or string)
   -a, --all-tabs               Process all open browser tabs (saves with auto-generated filenames)
-      --url-file string        Read URLs from file or stdin with "-" (one per line, supports comments)
-
-  -f, --format string          Output format: md | html | text | pdf | png (default md)
+      --no-activate            Read tab content via CDP without focusing or activating the tab
+      --watch                  Keep checking the --tab and save a new timestamped file each time its content changes
+      --interval duration      How often --watch checks the tab, e.g. 10s, 5m (default 30s)
+      --restore-scroll         Return tab to its prior scroll position after a PNG capture
+      --url-file string        Read URLs from file or stdin with "-" (one per line, optional filename, supports comments)
+      --no-env-expand          Leave ${VAR} in URL, --actions, --flow, and --host-headers files as written
+      --stream                 Fetch --url-file URLs as they arrive and print JSONL results
+      --state string           Track batch progress in a state file so runs can be stopped and resumed
+      --incremental            With --state, re-fetch done URLs only if their ETag, Last-Modified, or content changed
+      --crawl                  Also fetch the pages linked from each page, on the same host, saving each to --output-dir
+      --depth int              Links to follow from the start pages with --crawl (default 1)
+      --allow-domain stringArray
+                               Also crawl links to this domain and its subdomains (repeatable)
+      --summary-file string    Also write the batch summary line to file
+      --links-report string    Write the external links of every page, grouped by domain, to a JSON file
+      --tui                    Show batch progress as a terminal dashboard instead of scrolling logs
+      --notify-desktop         Show a desktop notification when a batch finishes
+      --flow string            Run a YAML flow of goto, click, fill, wait, and snag steps
+
+  -f, --format string          Output format: md | html | text | reader | org | rst | ipynb | pdf | png | json (default md)
   -i, --info                   Output page metadata as JSON (title, URL, domain, slug, timestamp)
+      --fields string          Fields for --info JSON: title,url,domain,slug,timestamp,status,partial,headers,canonical,redirects,content,links,images
+      --show-headers           Log the main document's response headers (adds headers to --info JSON)
+      --dump-headers string    Write the status, headers, redirects, and timing of every page to a JSON file
+  -V, --trace-http             Log the main document's requests, responses, and redirects like curl -v
+      --images-report          Log images missing alt text (adds every image, alt, and caption to --info JSON)
   -o, --output string          Save output to file instead of stdout
   -d, --output-dir string      Save files with auto-generated names to directory
+      --run-dir                Save each run to a new timestamped directory in --output-dir, with a manifest.json
+      --latest-link            Keep latest-* symlinks in --output-dir pointing at the newest snapshot of each page (or run with --run-dir)
+      --filename-lang string   Language of titles in generated filenames: transliterate and drop stopwords (en, de, ja, ...)
+      --title-from string      Where generated filenames take the title from, in order: og,title,h1,path (default og,title,h1,path)
+      --filename-template string  Name generated files with a Go template, e.g. "{{.Host}}/{{.Date}}-{{.Slug}}.{{.Ext}}"
+      --mirror-paths           With --output-dir, save pages as host/path/to/page.md, following their URLs
+      --output-tar string      Write batch output files to a tar archive, or stdout with "-"
+      --eol string             Line endings for text formats: lf | crlf (default: as converted)
+      --bom                    Start text formats with a UTF-8 byte order mark
+      --cite string            Append a source citation to text output: apa | mla | chicago | bibtex
+      --include-source         Start md and text output with the page title, source URL, and fetch time
+      --redact stringArray     Mask matches of a regular expression in text output with REDACTED (repeatable)
+      --redact-file string     Read --redact patterns from a file, one per line
+      --no-expand              Leave <details> and tab panels collapsed instead of expanding them before extraction
+      --select string          Extract only the elements matching this CSS selector
+      --fragment-only          Keep only the section the URL's #fragment points at (text, pdf, and png)
+      --assets-dir string      Save page images to this directory, named by content hash, and link them locally
+      --readability            Keep only the main article, dropping navigation, sidebars, and ads (like Reader Mode)
+      --sanitize               With --format html, strip scripts, event handlers, and trackers so output is safe to embed
+      --html-pretty            With --format html, indent one block element per line for diffing
+      --html-minify            With --format html, drop comments and unneeded whitespace for storage
+      --text-engine string     Text extractor for --format text: html2text | dom (rendered text) | readability (main article) (default html2text)
+      --template string        Render output through a Go text/template file (.Title, .URL, .Markdown, .Text, .Links, .Metadata)
+      --split-by string        Split Markdown into one file per heading: h1 to h6 (e.g. "h2")
+      --max-tokens int         Limit text output to an estimated token count (0 = unlimited)
+      --token-overflow string  Over --max-tokens: truncate (with a marker) | split into numbered files (default truncate)
+      --keep-boilerplate       Keep navigation, headers, and footers repeated across batch pages
+      --duplicates string      Find batch pages with the same content as an earlier page: report | skip (deletes them)
+      --pdf-a                  Produce PDF/A-2b archival output (requires Ghostscript)
+      --pdf-stamp              Add page numbers, source URL, and capture date to PDF footers
+      --pdf-selector string    Print only the element matching this CSS selector to PDF
+      --pdf-split-by string    Write one PDF per section starting at this heading level (h1-h6)
+      --no-pdf-outline         Leave out the PDF bookmarks built from the page's headings
+      --pdf-paper string       PDF paper size: a3 | a4 | a5 | letter | legal | tabloid (default: letter)
+      --pdf-landscape          Print PDFs in landscape orientation
+      --pdf-margins string     PDF margins as CSS shorthand with units, e.g. "10mm" or "20mm 15mm"
+      --pdf-scale float        Scale of the page in PDFs, 0.1 to 2 (default 1)
+      --pdf-header-template string
+                               HTML for the header of each PDF page (Chrome print template)
+      --pdf-footer-template string
+                               HTML for the footer of each PDF page (Chrome print template)
+      --annotate               Add a banner with the URL and capture time above PNG screenshots
+      --max-height int         Maximum PNG screenshot height in pixels (0 = unlimited)
+      --screenshot-full-page   Capture the whole page for PNG; =false for the visible viewport only (default true)
+      --screenshot-selector string
+                               Capture only the element matching this CSS selector for PNG
+      --viewport string        Viewport size for PNG capture, WIDTHxHEIGHT in CSS pixels, e.g. 1280x800
+      --record string          Record page load and scroll to an animated .gif or .webp
+      --duration duration      Length of --record capture (default 10s)
 
   -b, --open-browser           Open browser visibly with remote debugging enabled (no URL required)
   -c, --close-tab              Close the browser tab after fetching content
       --force-headless         Force headless mode even if the browser is running
+      --read-only              Write nothing but the output: no state or side files, and no browser profile left behind
+      --offline                Only contact the target URLs: no update check and no browser background traffic
   -p, --port int               Chromium/Chrome remote debugging port (default 9222)
+      --engine string          Fetch engine: chromium | webkit (Safari on macOS) | http (no browser) (default "chromium")
+      --k8s-pod string         Use the browser in a Kubernetes pod through kubectl port-forward: [namespace/]pod[:port]
       --user-agent string      Custom user agent (bypass headless detection)
+      --cookies-file string    Load cookies from a JSON or Netscape cookies.txt file before navigating
+      --save-cookies string    Save the browser's cookies to file after fetching (.txt for Netscape format, otherwise JSON)
+      --header stringArray     Send an extra HTTP header, "Name: Value", with page requests (repeatable)
+      --host-headers string    Send headers only to matching hosts, from a YAML file of rules
+      --auth stringArray       Send the token saved by "snag auth" to its provider's hosts: github | google (repeatable)
       --user-data-dir string   Custom Chromium/Chrome user data directory (for session isolation)
+      --matrix string          Fetch once per emulation combination, e.g. "device=iPhone 14,Desktop;color-scheme=light,dark"
+      --metrics-listen string  Serve Prometheus metrics at http://<addr>/metrics while snag runs
+      --throttle string        Emulate network conditions: slow-3g | 3g | slow-4g | 4g | custom:down,up,rtt
+      --browser-memory-limit string  Restart the launched headless browser between pages when it uses more memory than this, e.g. 2GB
+      --allow-host string      Only fetch from these hosts: example.com, *.example.com, IP, or CIDR (repeatable)
+      --block-host string      Never fetch from these hosts, e.g. 169.254.169.254 (repeatable)
+      --block string           Abort requests for these resources: images, fonts, media, analytics (comma-separated)
+      --block-url-pattern string  Abort requests whose URL matches this pattern, * matches anything (repeatable)
+      --no-private-ips         Refuse hosts that resolve to private, loopback, or link-local addresses unless allowed by --allow-host
+      --max-bytes string       Stop a page that downloads more than this, e.g. 50MB (exit code 3)
+      --max-requests int       Stop a page that makes more than this many requests (exit code 3)
 
       --timeout int            Page load timeout in seconds (default 30)
+      --nav-timeout duration   Navigation timeout, e.g. 45s (default: --timeout)
+      --stabilize-timeout duration  Time allowed for the page to settle after loading, e.g. 500ms, 10s (default 3s)
+      --wait-timeout duration  --wait-for and --wait-for-js timeout, e.g. 2m (default: --timeout)
+      --title-wait duration    Longest wait for a real page title before naming a file (0 to not wait) (default 3s)
+      --best-effort            On navigation, --wait-for, or --wait-for-js timeout, keep the content that loaded instead of failing
   -w, --wait-for string        Wait for CSS selector before extracting content
+      --wait-for-js string     Wait until a JavaScript expression is truthy, e.g. "window.__APP_READY === true"
+      --eval string            Run a JavaScript file in the page before extracting; with -f json, output its result
+      --eval-expr string       Run a JavaScript expression in the page before extracting; with -f json, output its result
+      --click stringArray      Click the element matching a CSS selector before extracting (repeatable)
+      --fill stringArray       Type into an input before extracting: "SELECTOR=VALUE" (repeatable)
+      --scroll-to stringArray  Scroll an element into view before extracting (repeatable)
+      --actions string         Run click, fill, and scroll-to actions from a YAML file before the flags' actions
+      --assert-contains string Fail with exit code 2 unless the page text contains string (repeatable)
+      --assert-selector string Fail with exit code 2 unless an element matches selector (repeatable)
+      --expect-status string   Fail with exit code 2 unless the HTTP status matches, e.g. "200", "2xx,304", "200-299"
+      --fail-on-error-status   Fail with exit code 4, writing nothing, when the HTTP status is 4xx or 5xx
+      --capture-responses string  Save XHR/fetch response bodies matching URL glob (e.g. "*/api/*")
+      --emit-curl string       Write curl equivalents of the page's XHR/fetch API calls to file
 
       --doctor                 Display comprehensive diagnostic information
   -k, --kill-browser           Kill browser processes with remote debugging enabled
 
       --debug                  Enable debug output
+      --log-format string      Log format on stderr: text | json (one object per line) (default "text")
   -q, --quiet                  Suppress all output except errors and content
+      --redact-urls            Mask query strings and credentials of URLs in logs and --stream results
       --verbose                Enable verbose logging output
 
   -h, --help                   help for snag
@@ -196,31 +512,144 @@ var rootCmd = &cobra.Command{
 
 func init() {
 	rootCmd.Flags().StringVar(&urlFile, "url-file", "", "Read URLs from file (one per line, supports comments)")
+	rootCmd.Flags().BoolVar(&noEnvExpand, "no-env-expand", false, "Leave ${VAR} in URL, --actions, --flow, and --host-headers files as written")
+	rootCmd.Flags().BoolVar(&crawl, "crawl", false, "Also fetch the pages linked from each page, on the same host, saving each to --output-dir")
+	rootCmd.Flags().IntVar(&crawlDepth, "depth", DefaultCrawlDepth, "Links to follow from the start pages with --crawl")
+	rootCmd.Flags().StringArrayVar(&allowDomains, "allow-domain", nil, "Also crawl links to this domain and its subdomains (repeatable)")
+	rootCmd.Flags().BoolVar(&stream, "stream", false, "Fetch --url-file URLs as they arrive and print JSONL results")
+	rootCmd.Flags().StringVar(&stateFile, "state", "", "Track batch progress in a state file so runs can be stopped and resumed")
+	rootCmd.Flags().BoolVar(&incremental, "incremental", false, "With --state, re-fetch done URLs only if their ETag, Last-Modified, or content changed")
+	rootCmd.Flags().StringVar(&flowFile, "flow", "", "Run a YAML flow of goto, click, fill, wait, and snag steps")
 	rootCmd.Flags().StringVarP(&output, "output", "o", "", "Save output to file instead of stdout")
 	rootCmd.Flags().StringVarP(&outputDir, "output-dir", "d", "", "Save files with auto-generated names to directory")
-	rootCmd.Flags().StringVarP(&format, "format", "f", FormatMarkdown, "Output format: md | html | text | pdf | png")
+	rootCmd.Flags().BoolVar(&runDir, "run-dir", false, "Save each run to a new timestamped directory in --output-dir, with a manifest.json")
+	rootCmd.Flags().BoolVar(&latestLink, "latest-link", false, "Keep latest-* symlinks in --output-dir pointing at the newest snapshot of each page (or run with --run-dir)")
+	rootCmd.Flags().StringVar(&filenameLang, "filename-lang", "", "Language of titles in generated filenames: transliterate and drop stopwords (en, de, ja, ...)")
+	rootCmd.Flags().StringVar(&titleFrom, "title-from", "", "Where generated filenames take the title from, in order: og,title,h1,path (default og,title,h1,path)")
+	rootCmd.Flags().StringVar(&filenameTmpl, "filename-template", "", "Name generated files with a Go template, e.g. \"{{.Host}}/{{.Date}}-{{.Slug}}.{{.Ext}}\"")
+	rootCmd.Flags().BoolVar(&mirrorPaths, "mirror-paths", false, "With --output-dir, save pages as host/path/to/page.md, following their URLs")
+	rootCmd.Flags().BoolVar(&tui, "tui", false, "Show batch progress as a terminal dashboard instead of scrolling logs")
+	rootCmd.Flags().BoolVar(&desktopNotify, "notify-desktop", false, "Show a desktop notification when a batch finishes")
+	rootCmd.Flags().StringVar(&summaryFile, "summary-file", "", "Also write the batch summary line to file")
+	rootCmd.Flags().StringVar(&linksReport, "links-report", "", "Write the external links of every page, grouped by domain, to a JSON file")
+	rootCmd.Flags().StringVar(&outputTar, "output-tar", "", "Write batch output files to a tar archive, or stdout with \"-\"")
+	rootCmd.Flags().StringVarP(&format, "format", "f", FormatMarkdown, "Output format: md | html | text | reader | org | rst | ipynb | pdf | png | json")
+	rootCmd.Flags().StringVar(&eol, "eol", "", "Line endings for text formats: lf | crlf")
+	rootCmd.Flags().StringVar(&citeStyle, "cite", "", "Append a source citation to text output: apa | mla | chicago | bibtex")
+	rootCmd.Flags().BoolVar(&includeSource, "include-source", false, "Start md and text output with the page title, source URL, and fetch time")
+	rootCmd.Flags().StringArrayVar(&redact, "redact", nil, "Mask matches of a regular expression in text output with REDACTED (repeatable)")
+	rootCmd.Flags().StringVar(&redactFile, "redact-file", "", "Read --redact patterns from a file, one per line")
+	rootCmd.Flags().BoolVar(&noExpand, "no-expand", false, "Leave <details> and tab panels collapsed instead of expanding them before extraction")
+	rootCmd.Flags().StringVar(&selectCSS, "select", "", "Extract only the elements matching this CSS selector")
+	rootCmd.Flags().BoolVar(&fragmentOnly, "fragment-only", false, "Keep only the section the URL's #fragment points at (text, pdf, and png)")
+	rootCmd.Flags().StringVar(&assetsDir, "assets-dir", "", "Save page images to this directory, named by content hash, and link them locally")
+	rootCmd.Flags().BoolVar(&readability, "readability", false, "Keep only the main article, dropping navigation, sidebars, and ads (like Reader Mode)")
+	rootCmd.Flags().BoolVar(&sanitize, "sanitize", false, "With --format html, strip scripts, event handlers, and trackers so output is safe to embed")
+	rootCmd.Flags().BoolVar(&htmlPretty, "html-pretty", false, "With --format html, indent one block element per line for diffing")
+	rootCmd.Flags().BoolVar(&htmlMinify, "html-minify", false, "With --format html, drop comments and unneeded whitespace for storage")
+	rootCmd.Flags().StringVar(&textEngine, "text-engine", TextEngineHTML2Text, "Text extractor for --format text: html2text | dom | readability")
+	rootCmd.Flags().StringVar(&templateFile, "template", "", "Render output through a Go text/template file instead of --format")
+	rootCmd.Flags().StringVar(&splitBy, "split-by", "", "Split Markdown into one file per heading: h1 to h6 (e.g. \"h2\")")
+	rootCmd.Flags().StringVar(&tokenOverflow, "token-overflow", TokenOverflowTruncate, "Over --max-tokens: truncate (with a marker) | split into numbered files")
 	rootCmd.Flags().StringVarP(&waitFor, "wait-for", "w", "", "Wait for CSS selector before extracting content")
+	rootCmd.Flags().StringVar(&waitForJSExpr, "wait-for-js", "", "Wait until a JavaScript expression is truthy before extracting content")
+	rootCmd.Flags().StringVar(&evalFile, "eval", "", "Run a JavaScript file in the page before extracting; with -f json, output its result")
+	rootCmd.Flags().StringVar(&evalExpr, "eval-expr", "", "Run a JavaScript expression in the page before extracting; with -f json, output its result")
+	rootCmd.Flags().Var(&actionFlag{kind: ActionClick, actions: &pageActions}, "click", "Click the element matching a CSS selector before extracting (repeatable)")
+	rootCmd.Flags().Var(&actionFlag{kind: ActionFill, actions: &pageActions}, "fill", "Type into an input before extracting: \"SELECTOR=VALUE\" (repeatable)")
+	rootCmd.Flags().Var(&actionFlag{kind: ActionScrollTo, actions: &pageActions}, "scroll-to", "Scroll an element into view before extracting (repeatable)")
+	rootCmd.Flags().StringVar(&actionsFile, "actions", "", "Run click, fill, and scroll-to actions from a YAML file before the flags' actions")
 	rootCmd.Flags().StringVarP(&tab, "tab", "t", "", "Fetch from existing tab by pattern (tab number or string)")
+	rootCmd.Flags().StringArrayVar(&headers, "header", nil, "Send an extra HTTP header, \"Name: Value\", with page requests (repeatable)")
+	rootCmd.Flags().StringVar(&hostHeaders, "host-headers", "", "Send headers only to matching hosts, from a YAML file of rules")
+	rootCmd.Flags().StringArrayVar(&authProviders, "auth", nil, "Send the token saved by \"snag auth\" to its provider's hosts: github | google (repeatable)")
+	rootCmd.Flags().StringVar(&cookiesFile, "cookies-file", "", "Load cookies from a JSON or Netscape cookies.txt file before navigating")
+	rootCmd.Flags().StringVar(&saveCookiesTo, "save-cookies", "", "Save the browser's cookies to file after fetching (.txt for Netscape format, otherwise JSON)")
 	rootCmd.Flags().StringVar(&userAgent, "user-agent", "", "Custom user agent (bypass headless detection)")
 	rootCmd.Flags().StringVar(&userDataDir, "user-data-dir", "", "Custom Chromium/Chrome user data directory (for session isolation)")
+	rootCmd.Flags().StringArrayVar(&assertText, "assert-contains", nil, "Fail with exit code 2 unless the page text contains string (repeatable)")
+	rootCmd.Flags().StringArrayVar(&assertSelector, "assert-selector", nil, "Fail with exit code 2 unless an element matches selector (repeatable)")
+	rootCmd.Flags().StringVar(&expectStatus, "expect-status", "", "Fail with exit code 2 unless the HTTP status matches, e.g. \"200\", \"2xx,304\", \"200-299\"")
+	rootCmd.Flags().BoolVar(&failOnStatus, "fail-on-error-status", false, "Fail with exit code 4, writing nothing, when the HTTP status is 4xx or 5xx")
+	rootCmd.Flags().StringVar(&captureResp, "capture-responses", "", "Save XHR/fetch response bodies matching URL glob (e.g. \"*/api/*\")")
+	rootCmd.Flags().StringVar(&emitCurl, "emit-curl", "", "Write curl equivalents of the page's XHR/fetch API calls to file")
+	rootCmd.Flags().StringVar(&record, "record", "", "Record page load and scroll to an animated .gif or .webp")
+	rootCmd.Flags().StringVar(&matrix, "matrix", "", "Fetch once per emulation combination, e.g. \"device=iPhone 14,Desktop;color-scheme=light,dark\"")
+	rootCmd.Flags().StringVar(&metricsListen, "metrics-listen", "", "Serve Prometheus metrics at http://<addr>/metrics while snag runs")
+	rootCmd.Flags().StringVar(&throttle, "throttle", "", "Emulate network conditions: slow-3g | 3g | slow-4g | 4g | custom:down,up,rtt")
 
 	rootCmd.Flags().IntVar(&timeout, "timeout", 30, "Page load timeout in seconds")
+	rootCmd.Flags().DurationVar(&navTimeout, "nav-timeout", 0, "Navigation timeout, e.g. 45s (default: --timeout)")
+	rootCmd.Flags().DurationVar(&stabilizeTime, "stabilize-timeout", 0, "Time allowed for the page to settle after loading, e.g. 500ms, 10s (default 3s)")
+	rootCmd.Flags().BoolVar(&bestEffort, "best-effort", false, "On navigation, --wait-for, or --wait-for-js timeout, keep the content that loaded instead of failing")
+	rootCmd.Flags().DurationVar(&waitTimeout, "wait-timeout", 0, "--wait-for and --wait-for-js timeout, e.g. 2m (default: --timeout)")
+	rootCmd.Flags().DurationVar(&titleWait, "title-wait", DefaultTitleWait, "Longest wait for a real page title before naming a file (0 to not wait)")
 	rootCmd.Flags().IntVarP(&port, "port", "p", 9222, "Chromium/Chrome remote debugging port")
+	rootCmd.Flags().StringVar(&engine, "engine", EngineChromium, "Fetch engine: chromium | webkit (Safari on macOS) | http (no browser)")
+	rootCmd.Flags().StringVar(&k8sPod, "k8s-pod", "", "Use the browser in a Kubernetes pod through kubectl port-forward: [namespace/]pod[:port]")
+	rootCmd.Flags().BoolVar(&annotate, "annotate", false, "Add a banner with the URL and capture time above PNG screenshots")
+	rootCmd.Flags().IntVar(&maxHeight, "max-height", 0, "Maximum PNG screenshot height in pixels (0 = unlimited)")
+	rootCmd.Flags().BoolVar(&screenshotFull, "screenshot-full-page", true, "Capture the whole page for PNG; =false for the visible viewport only")
+	rootCmd.Flags().StringVar(&shotSelector, "screenshot-selector", "", "Capture only the element matching this CSS selector for PNG")
+	rootCmd.Flags().StringVar(&viewportSize, "viewport", "", "Viewport size for PNG capture, WIDTHxHEIGHT in CSS pixels, e.g. 1280x800")
+	rootCmd.Flags().IntVar(&maxTokens, "max-tokens", 0, "Limit text output to an estimated token count (0 = unlimited)")
+	rootCmd.Flags().DurationVar(&recordDuration, "duration", DefaultRecordDuration, "Length of --record capture")
 
 	rootCmd.Flags().BoolVarP(&closeTab, "close-tab", "c", false, "Close the browser tab after fetching content")
 	rootCmd.Flags().BoolVar(&forceHead, "force-headless", false, "Force headless mode even if the browser is running")
+	rootCmd.Flags().BoolVar(&readOnly, "read-only", false, "Write nothing but the output: no state or side files, and no browser profile left behind")
+	rootCmd.Flags().BoolVar(&offline, "offline", false, "Only contact the target URLs: no update check and no browser background traffic")
 	rootCmd.Flags().BoolVarP(&openBrowser, "open-browser", "b", false, "Open browser visibly with remote debugging enabled (no URL required)")
 	rootCmd.Flags().BoolVarP(&listTabs, "list-tabs", "l", false, "List all open tabs in the browser")
 	rootCmd.Flags().BoolVarP(&allTabs, "all-tabs", "a", false, "Process all open browser tabs (saves with auto-generated filenames)")
+	rootCmd.Flags().BoolVar(&noActivate, "no-activate", false, "Read tab content via CDP without focusing or activating the tab")
+	rootCmd.Flags().BoolVar(&watch, "watch", false, "Keep checking the --tab and save a new timestamped file each time its content changes")
+	rootCmd.Flags().DurationVar(&watchInterval, "interval", DefaultWatchInterval, "How often --watch checks the tab, e.g. 10s, 5m")
+	rootCmd.Flags().BoolVar(&pdfA, "pdf-a", false, "Produce PDF/A-2b archival output (requires Ghostscript)")
+	rootCmd.Flags().StringVar(&pdfSelector, "pdf-selector", "", "Print only the element matching this CSS selector to PDF")
+	rootCmd.Flags().StringVar(&pdfSplitBy, "pdf-split-by", "", "Write one PDF per section starting at this heading level (h1-h6)")
+	rootCmd.Flags().BoolVar(&noPDFOutline, "no-pdf-outline", false, "Leave out the PDF bookmarks built from the page's headings")
+	rootCmd.Flags().StringVar(&pdfPaper, "pdf-paper", "", "PDF paper size: a3 | a4 | a5 | letter | legal | tabloid (default: letter)")
+	rootCmd.Flags().BoolVar(&pdfLandscape, "pdf-landscape", false, "Print PDFs in landscape orientation")
+	rootCmd.Flags().StringVar(&pdfMargins, "pdf-margins", "", "PDF margins as CSS shorthand with units, e.g. \"10mm\" or \"20mm 15mm\"")
+	rootCmd.Flags().Float64Var(&pdfScale, "pdf-scale", 1, "Scale of the page in PDFs, 0.1 to 2")
+	rootCmd.Flags().StringVar(&pdfHeader, "pdf-header-template", "", "HTML for the header of each PDF page (Chrome print template)")
+	rootCmd.Flags().StringVar(&pdfFooter, "pdf-footer-template", "", "HTML for the footer of each PDF page (Chrome print template)")
+	rootCmd.Flags().BoolVar(&pdfStamp, "pdf-stamp", false, "Add page numbers, source URL, and capture date to PDF footers")
+	rootCmd.Flags().BoolVar(&bom, "bom", false, "Start text formats with a UTF-8 byte order mark")
+	rootCmd.Flags().BoolVar(&keepBoiler, "keep-boilerplate", false, "Keep navigation, headers, and footers repeated across batch pages")
+	rootCmd.Flags().StringVar(&duplicates, "duplicates", "", "Find batch pages with the same content as an earlier page: report | skip (deletes them)")
+	rootCmd.Flags().BoolVar(&restoreScroll, "restore-scroll", false, "Return tab to its prior scroll position after a PNG capture")
 	rootCmd.Flags().BoolVarP(&killBrowser, "kill-browser", "k", false, "Kill browser processes with remote debugging enabled")
 	rootCmd.Flags().BoolVar(&doctor, "doctor", false, "Display comprehensive diagnostic information")
 	rootCmd.Flags().BoolVarP(&showVersion, "version", "v", false, "Display version information")
 	rootCmd.Flags().BoolVarP(&info, "info", "i", false, "Output page metadata as JSON (title, URL, domain, slug, timestamp)")
+	rootCmd.Flags().StringVar(&infoFields, "fields", "", "Fields for --info JSON: title,url,domain,slug,timestamp,status,partial,headers,canonical,redirects,content,links,images")
+	rootCmd.Flags().BoolVar(&showHeaders, "show-headers", false, "Log the main document's response headers (adds headers to --info JSON)")
+	rootCmd.Flags().StringVar(&dumpHeaders, "dump-headers", "", "Write the status, headers, redirects, and timing of every page to a JSON file")
+	rootCmd.Flags().BoolVarP(&traceHTTP, "trace-http", "V", false, "Log the main document's requests, responses, and redirects like curl -v")
+	rootCmd.Flags().BoolVar(&imagesReport, "images-report", false, "Log images missing alt text (adds every image, alt, and caption to --info JSON)")
 	rootCmd.Flags().BoolVar(&verbose, "verbose", false, "Enable verbose logging output")
+	rootCmd.Flags().StringArrayVar(&allowHosts, "allow-host", nil, "Only fetch from these hosts: example.com, *.example.com, IP, or CIDR (repeatable)")
+	rootCmd.Flags().StringArrayVar(&blockHosts, "block-host", nil, "Never fetch from these hosts, e.g. 169.254.169.254 (repeatable)")
+	rootCmd.Flags().StringArrayVar(&blockResources, "block", nil, "Abort requests for these resources: images, fonts, media, analytics (comma-separated)")
+	rootCmd.Flags().StringArrayVar(&blockURLs, "block-url-pattern", nil, "Abort requests whose URL matches this pattern, * matches anything (repeatable)")
+	rootCmd.Flags().StringVar(&maxBytes, "max-bytes", "", "Stop a page that downloads more than this, e.g. 50MB (exit code 3)")
+	rootCmd.Flags().IntVar(&maxRequests, "max-requests", 0, "Stop a page that makes more than this many requests (exit code 3)")
+	rootCmd.Flags().StringVar(&memLimit, "browser-memory-limit", "", "Restart the launched headless browser between pages when it uses more memory than this, e.g. 2GB")
+	rootCmd.Flags().BoolVar(&noPrivateIPs, "no-private-ips", false, "Refuse hosts that resolve to private, loopback, or link-local addresses unless allowed by --allow-host")
 	rootCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Suppress all output except errors and content")
+	rootCmd.Flags().BoolVar(&redactURLs, "redact-urls", false, "Mask query strings and credentials of URLs in logs and --stream results")
 	rootCmd.Flags().BoolVar(&debug, "debug", false, "Enable debug output")
+	rootCmd.Flags().StringVar(&logFormat, "log-format", LogFormatText, "Log format on stderr: text | json (one object per line)")
 
 	rootCmd.MarkFlagsMutuallyExclusive("quiet", "verbose", "debug")
+	rootCmd.MarkFlagsMutuallyExclusive("html-pretty", "html-minify")
+	rootCmd.MarkFlagsMutuallyExclusive("eval", "eval-expr")
+	rootCmd.MarkFlagsMutuallyExclusive("mirror-paths", "filename-template")
+	rootCmd.MarkFlagsMutuallyExclusive("expect-status", "fail-on-error-status")
+	rootCmd.MarkFlagsMutuallyExclusive("k8s-pod", "port")
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
 
 	rootCmd.SetHelpTemplate(helpTemplate)
 }
@@ -228,6 +657,7 @@ func init() {
 func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	watchPauseSignals()
 
 	go func() {
 		sig := <-sigChan
@@ -238,6 +668,7 @@ func main() {
 			browserManager.Close()
 		}
 		browserMutex.Unlock()
+		stopActivePortForward()
 
 		if sig == os.Interrupt {
 			os.Exit(ExitCodeInterrupt)
@@ -246,11 +677,27 @@ func main() {
 	}()
 
 	if err := rootCmd.Execute(); err != nil {
+		if errors.Is(err, ErrAssertionFailed) {
+			os.Exit(ExitCodeAssertion)
+		}
+		if errors.Is(err, ErrResourceBudget) {
+			os.Exit(ExitCodeBudget)
+		}
+		if errors.Is(err, ErrHTTPStatus) {
+			os.Exit(ExitCodeHTTP)
+		}
 		os.Exit(ExitCodeError)
 	}
 }
 
 func validateFlagCombinations(cmd *cobra.Command, hasURLs bool, hasMultipleURLs bool) error {
+	// Checked first, so no later check writes anything before it is refused
+	if readOnly {
+		if err := validateReadOnly(cmd.Flags().Changed); err != nil {
+			return err
+		}
+	}
+
 	if cmd.Flags().Changed("tab") && hasURLs {
 		logger.Error("Cannot use both --tab and URL arguments (mutually exclusive content sources)")
 		return ErrTabURLConflict
@@ -266,6 +713,42 @@ func validateFlagCombinations(cmd *cobra.Command, hasURLs bool, hasMultipleURLs
 		return fmt.Errorf("conflicting flags: --tab and --all-tabs")
 	}
 
+	if err := validateEngine(engine); err != nil {
+		return err
+	}
+	if err := validateEngineCapabilities(cmd, normalizeEngine(engine), normalizeFormat(format)); err != nil {
+		return err
+	}
+
+	if watch {
+		if !cmd.Flags().Changed("tab") {
+			logger.Error("--watch requires --tab")
+			logger.ErrorWithSuggestion(
+				"Name the tab to watch by number or pattern",
+				"snag --tab dashboard --watch --interval 30s -d out/",
+			)
+			return fmt.Errorf("--watch requires --tab")
+		}
+		if strings.TrimSpace(output) != "" {
+			logger.Error("Cannot use --output with --watch (each change is saved to a new file). Use --output-dir instead")
+			return ErrOutputFlagConflict
+		}
+		if mirrorPaths || strings.TrimSpace(outputTar) != "" {
+			logger.Error("Cannot use --mirror-paths or --output-tar with --watch")
+			return fmt.Errorf("conflicting flags: --watch with --mirror-paths or --output-tar")
+		}
+		if watchInterval < MinWatchInterval {
+			logger.Error("Invalid --interval: %s", watchInterval)
+			logger.ErrorWithSuggestion(
+				fmt.Sprintf("Check the tab at most once every %s", MinWatchInterval),
+				"snag --tab dashboard --watch --interval 30s -d out/",
+			)
+			return fmt.Errorf("invalid interval: %s", watchInterval)
+		}
+	} else if cmd.Flags().Changed("interval") {
+		logger.Warning("--interval ignored without --watch")
+	}
+
 	if openBrowser && forceHead {
 		logger.Error("Cannot use both --force-headless and --open-browser (conflicting modes)")
 		return fmt.Errorf("conflicting flags: --force-headless and --open-browser")
@@ -295,6 +778,36 @@ func validateFlagCombinations(cmd *cobra.Command, hasURLs bool, hasMultipleURLs
 		return ErrOutputFlagConflict
 	}
 
+	if crawl {
+		if !hasURLs {
+			logger.Error("--crawl requires at least one URL to start from")
+			logger.ErrorWithSuggestion(
+				"Give the start page of the site to crawl",
+				"snag --crawl --depth 2 -d docs/ https://example.com/docs/",
+			)
+			return ErrNoValidURLs
+		}
+		if info || stream || matrix != "" || flowFile != "" || openBrowser || stateFile != "" {
+			logger.Error("Cannot use --crawl with --info, --stream, --matrix, --flow, --open-browser, or --state")
+			return fmt.Errorf("conflicting flags: --crawl with --info, --stream, --matrix, --flow, --open-browser, or --state")
+		}
+		if crawlDepth < 0 {
+			logger.Error("Invalid --depth: %d", crawlDepth)
+			logger.ErrorWithSuggestion(
+				"Depth must be zero (start pages only) or a positive number of links",
+				"snag --crawl --depth 2 -d docs/ https://example.com/docs/",
+			)
+			return fmt.Errorf("invalid depth: %d", crawlDepth)
+		}
+	} else {
+		if cmd.Flags().Changed("depth") {
+			logger.Warning("--depth ignored without --crawl")
+		}
+		if len(allowDomains) > 0 {
+			logger.Warning("--allow-domain ignored without --crawl")
+		}
+	}
+
 	if allTabs && outputFile != "" {
 		logger.Error("Cannot use --output with multiple content sources. Use --output-dir instead")
 		return ErrOutputFlagConflict
@@ -304,6 +817,10 @@ func validateFlagCombinations(cmd *cobra.Command, hasURLs bool, hasMultipleURLs
 		logger.Warning("--close-tab is ignored in headless mode (tabs close automatically)")
 	}
 
+	if k8sPod != "" && userDataDir != "" {
+		logger.Warning("--user-data-dir ignored with --k8s-pod (the pod's browser keeps its own profile)")
+	}
+
 	if openBrowser && cmd.Flags().Changed("tab") {
 		logger.Warning("--tab ignored with --open-browser (no content fetching)")
 	}
@@ -312,6 +829,370 @@ func validateFlagCombinations(cmd *cobra.Command, hasURLs bool, hasMultipleURLs
 		logger.Warning("--all-tabs ignored with --open-browser (no content fetching)")
 	}
 
+	if noActivate && !cmd.Flags().Changed("tab") && !allTabs {
+		logger.Warning("--no-activate ignored without --tab or --all-tabs (new pages are never activated)")
+	} else if f := normalizeFormat(format); noActivate && (f == FormatPDF || f == FormatPNG) {
+		// Capturing resizes the viewport or lays the page out for print,
+		// which re-renders the tab the flag is meant to leave alone
+		logger.Error("--no-activate cannot be used with --format %s (capturing re-renders the tab)", f)
+		logger.ErrorWithSuggestion(
+			"Read the tab as HTML or Markdown instead",
+			"snag --tab 1 --no-activate -f html",
+		)
+		return fmt.Errorf("--no-activate cannot be used with --format %s", f)
+	}
+
+	if restoreScroll && !cmd.Flags().Changed("tab") && !allTabs {
+		logger.Warning("--restore-scroll ignored without --tab or --all-tabs (new pages start at the top)")
+	} else if restoreScroll && normalizeFormat(format) != FormatPNG {
+		logger.Warning("--restore-scroll only applies to --format png")
+	}
+
+	if pdfA && normalizeFormat(format) != FormatPDF {
+		logger.Warning("--pdf-a only applies to --format pdf")
+	}
+
+	if pdfStamp && normalizeFormat(format) != FormatPDF {
+		logger.Warning("--pdf-stamp only applies to --format pdf")
+	}
+
+	if err := validatePDFLayout(pdfPaper, pdfMargins, pdfScale); err != nil {
+		return err
+	}
+	for _, name := range []string{"pdf-paper", "pdf-landscape", "pdf-margins", "pdf-scale", "pdf-header-template", "pdf-footer-template"} {
+		if cmd.Flags().Changed(name) && normalizeFormat(format) != FormatPDF {
+			logger.Warning("--%s only applies to --format pdf", name)
+		}
+	}
+	if pdfStamp && pdfFooter != "" {
+		logger.Error("Cannot use --pdf-stamp with --pdf-footer-template (both set the PDF footer)")
+		logger.ErrorWithSuggestion(
+			"Add the pageNumber, totalPages, and url classes to your own footer instead",
+			`snag -f pdf --pdf-footer-template '<div style="font-size:8px"><span class="pageNumber"></span></div>' <url>`,
+		)
+		return fmt.Errorf("conflicting flags: --pdf-stamp and --pdf-footer-template")
+	}
+
+	if pdfSplitBy != "" {
+		if splitHeadingLevel(pdfSplitBy) == 0 {
+			logger.Error("Invalid --pdf-split-by: %s", pdfSplitBy)
+			logger.ErrorWithSuggestion(
+				"Split on a heading level from h1 to h6",
+				"snag -f pdf --pdf-split-by h1 -d sections/ <url>",
+			)
+			return fmt.Errorf("invalid pdf-split-by: %s", pdfSplitBy)
+		}
+		if normalizeFormat(format) != FormatPDF {
+			logger.Error("--pdf-split-by only applies to --format pdf")
+			return fmt.Errorf("--pdf-split-by requires pdf format")
+		}
+		if info || stream {
+			logger.Error("Cannot use --pdf-split-by with --info or --stream")
+			return fmt.Errorf("conflicting flags: --pdf-split-by with --info or --stream")
+		}
+	}
+
+	if strings.TrimSpace(pdfSelector) != "" {
+		if normalizeFormat(format) != FormatPDF {
+			logger.Error("--pdf-selector only applies to --format pdf")
+			return fmt.Errorf("--pdf-selector requires pdf format")
+		}
+		if pdfSplitBy != "" {
+			logger.Error("Cannot use --pdf-selector with --pdf-split-by")
+			return fmt.Errorf("conflicting flags: --pdf-selector and --pdf-split-by")
+		}
+	}
+
+	if fragmentOnly {
+		if strings.TrimSpace(pdfSelector) != "" || strings.TrimSpace(shotSelector) != "" || pdfSplitBy != "" {
+			logger.Error("Cannot use --fragment-only with --pdf-selector, --screenshot-selector, or --pdf-split-by")
+			return fmt.Errorf("conflicting flags: --fragment-only with --pdf-selector, --screenshot-selector, or --pdf-split-by")
+		}
+		if f := normalizeFormat(format); f == FormatJSON {
+			logger.Warning("--fragment-only does not apply to --format json")
+		} else if f == FormatText && normalizeTextEngine(textEngine) == TextEngineDOM {
+			logger.Warning("--text-engine dom is ignored with --fragment-only (the section's HTML is converted)")
+		}
+	}
+
+	if sanitize && normalizeFormat(format) != FormatHTML && templateFile == "" {
+		logger.Warning("--sanitize only applies to --format html")
+	}
+	if (htmlPretty || htmlMinify) && normalizeFormat(format) != FormatHTML {
+		logger.Warning("--html-pretty and --html-minify only apply to --format html")
+	}
+
+	if noPDFOutline && normalizeFormat(format) != FormatPDF {
+		logger.Warning("--no-pdf-outline only applies to --format pdf")
+	}
+
+	if annotate && normalizeFormat(format) != FormatPNG {
+		logger.Warning("--annotate only applies to --format png")
+	}
+
+	if err := validateEOL(eol); err != nil {
+		return err
+	}
+
+	if (eol != "" || bom) && (normalizeFormat(format) == FormatPDF || normalizeFormat(format) == FormatPNG) {
+		logger.Warning("--eol and --bom only apply to text formats (md, html, text, reader, org, rst)")
+	}
+
+	if splitBy != "" {
+		if splitHeadingLevel(splitBy) == 0 {
+			logger.Error("Invalid --split-by: %s", splitBy)
+			logger.ErrorWithSuggestion(
+				"Split on a heading level from h1 to h6",
+				"snag --split-by h2 -d chunks/ <url>",
+			)
+			return fmt.Errorf("invalid split-by: %s", splitBy)
+		}
+		if normalizeFormat(format) != FormatMarkdown {
+			logger.Error("--split-by only applies to --format md")
+			return fmt.Errorf("--split-by requires markdown format")
+		}
+		if info || stream {
+			logger.Error("Cannot use --split-by with --info or --stream")
+			return fmt.Errorf("conflicting flags: --split-by with --info or --stream")
+		}
+	}
+
+	if err := validateTextEngine(textEngine); err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("text-engine") && normalizeFormat(format) != FormatText {
+		logger.Warning("--text-engine only applies to --format text")
+	}
+
+	if dir := strings.TrimSpace(assetsDir); dir != "" {
+		if f := normalizeFormat(format); f == FormatPDF || f == FormatPNG {
+			logger.Warning("--assets-dir only applies to text formats (images are embedded in %s)", f)
+		}
+	}
+
+	selectCSS = strings.TrimSpace(selectCSS)
+	if selectCSS != "" {
+		if f := normalizeFormat(format); f == FormatPDF || f == FormatPNG {
+			logger.Warning("--select only applies to text formats, use --pdf-selector for PDFs")
+		} else if f == FormatText && normalizeTextEngine(textEngine) == TextEngineDOM {
+			logger.Warning("--text-engine dom is ignored with --select (selected HTML is converted)")
+		}
+	}
+
+	if readability {
+		if f := normalizeFormat(format); f == FormatPDF || f == FormatPNG {
+			logger.Warning("--readability only applies to text formats (md, html, text, reader, org, rst, ipynb)")
+		} else if f == FormatText && normalizeTextEngine(textEngine) == TextEngineDOM {
+			logger.Warning("--readability is ignored with --text-engine dom (text is read as rendered)")
+		}
+	}
+
+	if templateFile != "" {
+		if f := normalizeFormat(format); f == FormatPDF || f == FormatPNG {
+			logger.Error("Cannot use --template with --format %s (templates produce text)", f)
+			return fmt.Errorf("conflicting flags: --template and --format %s", f)
+		}
+		if info || splitBy != "" || maxTokens > 0 {
+			logger.Error("Cannot use --template with --info, --split-by, or --max-tokens")
+			return fmt.Errorf("conflicting flags: --template with --info, --split-by, or --max-tokens")
+		}
+		if err := validateTemplate(templateFile); err != nil {
+			return err
+		}
+	}
+
+	if citeStyle != "" {
+		if err := validateCiteStyle(citeStyle); err != nil {
+			return err
+		}
+		if f := normalizeFormat(format); f == FormatPDF || f == FormatPNG || f == FormatNotebook {
+			logger.Error("Cannot use --cite with --format %s", f)
+			return fmt.Errorf("conflicting flags: --cite and --format %s", f)
+		}
+		if info || templateFile != "" {
+			logger.Error("Cannot use --cite with --info or --template")
+			return fmt.Errorf("conflicting flags: --cite with --info or --template")
+		}
+	}
+
+	if includeSource {
+		if f := normalizeFormat(format); f != FormatMarkdown && f != FormatText {
+			logger.Error("Cannot use --include-source with --format %s", f)
+			logger.ErrorWithSuggestion(
+				"--include-source adds a header to Markdown and plain text output",
+				"snag --include-source --format text <url>",
+			)
+			return fmt.Errorf("conflicting flags: --include-source and --format %s", f)
+		}
+		if info || templateFile != "" {
+			logger.Error("Cannot use --include-source with --info or --template")
+			return fmt.Errorf("conflicting flags: --include-source with --info or --template")
+		}
+	}
+
+	if len(redact) > 0 || redactFile != "" {
+		if err := validateRedact(redact, redactFile); err != nil {
+			return err
+		}
+		if f := normalizeFormat(format); f == FormatPDF || f == FormatPNG {
+			logger.Warning("--redact only applies to text formats (md, html, text, reader, org, rst, ipynb)")
+		}
+	}
+
+	if err := validateCookies(strings.TrimSpace(cookiesFile), strings.TrimSpace(saveCookiesTo)); err != nil {
+		return err
+	}
+
+	if err := validateHeaders(headers); err != nil {
+		return err
+	}
+
+	if hostHeaders = strings.TrimSpace(hostHeaders); hostHeaders != "" {
+		rules, err := loadHostHeaders(hostHeaders)
+		if err != nil {
+			return err
+		}
+		hostHeaderRules = rules
+	}
+
+	if len(authProviders) > 0 {
+		rules, err := authHeaderRules(authProviders)
+		if err != nil {
+			return err
+		}
+		// --host-headers rules come later so they can override a token
+		hostHeaderRules = append(rules, hostHeaderRules...)
+	}
+
+	if actionsFile = strings.TrimSpace(actionsFile); actionsFile != "" {
+		fileActions, err := loadActions(actionsFile)
+		if err != nil {
+			return err
+		}
+		pageActions = append(fileActions, pageActions...)
+	}
+	if len(pageActions) > 0 && (cmd.Flags().Changed("tab") || allTabs || flowFile != "") {
+		logger.Warning("--click, --fill, --scroll-to, and --actions only apply to fetched URLs (ignored with --tab, --all-tabs, and --flow)")
+	}
+
+	if err := validateExpectStatus(expectStatus); err != nil {
+		return err
+	}
+
+	if err := validateHostPatterns(allowHosts, blockHosts); err != nil {
+		return err
+	}
+
+	if err := validateBlock(blockResources, blockURLs); err != nil {
+		return err
+	}
+	if len(blockResources) > 0 || len(blockURLs) > 0 {
+		if cmd.Flags().Changed("tab") || allTabs {
+			logger.Warning("--block and --block-url-pattern only apply to fetched URLs (ignored with --tab and --all-tabs)")
+		}
+		if blocker, _ := activeResourceBlocker(); blocker.blocksImages() {
+			if f := normalizeFormat(format); f == FormatPDF || f == FormatPNG {
+				logger.Warning("--block images leaves images out of the %s", strings.ToUpper(f))
+			}
+			if strings.TrimSpace(assetsDir) != "" {
+				logger.Warning("--block images leaves --assets-dir with no images to save")
+			}
+		}
+	}
+
+	if err := validateResourceBudget(maxBytes, maxRequests); err != nil {
+		return err
+	}
+
+	if err := validateMemoryLimit(memLimit); err != nil {
+		return err
+	}
+
+	if err := validatePhaseTimeouts(); err != nil {
+		return err
+	}
+
+	if err := validateTokenBudget(maxTokens, tokenOverflow); err != nil {
+		return err
+	}
+
+	if maxTokens > 0 {
+		if f := normalizeFormat(format); f == FormatPDF || f == FormatPNG || f == FormatNotebook {
+			logger.Error("--max-tokens only applies to text formats (md, html, text, reader, org, rst)")
+			return fmt.Errorf("--max-tokens requires a text format")
+		}
+		if splitBy != "" && normalizeTokenOverflow(tokenOverflow) == TokenOverflowSplit {
+			logger.Error("Cannot use --token-overflow split with --split-by (sections are truncated to --max-tokens)")
+			return fmt.Errorf("conflicting flags: --token-overflow split and --split-by")
+		}
+	} else if cmd.Flags().Changed("token-overflow") {
+		logger.Warning("--token-overflow ignored without --max-tokens")
+	}
+
+	if maxHeight < 0 {
+		logger.Error("Invalid --max-height: %d", maxHeight)
+		logger.ErrorWithSuggestion(
+			"Max height must be zero (unlimited) or a positive number of pixels",
+			"snag -f png --max-height 20000 <url>",
+		)
+		return fmt.Errorf("invalid max height: %d", maxHeight)
+	}
+
+	if captureResp != "" && (cmd.Flags().Changed("tab") || allTabs) {
+		logger.Warning("--capture-responses ignored with --tab or --all-tabs (existing tabs are already loaded)")
+	}
+
+	if emitCurl != "" && (cmd.Flags().Changed("tab") || allTabs) {
+		logger.Warning("--emit-curl ignored with --tab or --all-tabs (existing tabs are already loaded)")
+	}
+
+	if throttle != "" && (cmd.Flags().Changed("tab") || allTabs) {
+		logger.Warning("--throttle ignored with --tab or --all-tabs (existing tabs are already loaded)")
+	}
+
+	if record != "" && (cmd.Flags().Changed("tab") || allTabs) {
+		logger.Warning("--record ignored with --tab or --all-tabs (existing tabs are already loaded)")
+	} else if record != "" && (hasMultipleURLs || matrix != "") {
+		logger.Warning("--record ignored with multiple URLs or --matrix (single URL only)")
+	}
+
+	if cmd.Flags().Changed("duration") && record == "" {
+		logger.Warning("--duration ignored without --record")
+	}
+
+	if cmd.Flags().Changed("max-height") && normalizeFormat(format) != FormatPNG {
+		logger.Warning("--max-height only applies to --format png")
+	}
+
+	if err := validateViewport(viewportSize); err != nil {
+		return err
+	}
+	for _, name := range []string{"screenshot-full-page", "screenshot-selector", "viewport"} {
+		if cmd.Flags().Changed(name) && normalizeFormat(format) != FormatPNG {
+			logger.Warning("--%s only applies to --format png", name)
+		}
+	}
+	if strings.TrimSpace(shotSelector) != "" && cmd.Flags().Changed("screenshot-full-page") {
+		logger.Warning("--screenshot-full-page ignored with --screenshot-selector (the element is captured in full)")
+	}
+	if viewportSize != "" && strings.Contains(matrix, "device=") {
+		logger.Error("Cannot use --viewport with a --matrix device (the device sets the viewport)")
+		return fmt.Errorf("conflicting flags: --viewport and --matrix device")
+	}
+
+	if openBrowser && !hasURLs && hasAssertions() {
+		logger.Warning("--assert-contains and --assert-selector ignored with --open-browser (no content fetching)")
+	}
+
+	if cmd.Flags().Changed("fields") && !info {
+		logger.Error("--fields requires --info")
+		logger.ErrorWithSuggestion(
+			"Select fields of the --info JSON output",
+			"snag --info --fields title,url,content example.com",
+		)
+		return fmt.Errorf("--fields requires --info")
+	}
+
 	if info && cmd.Flags().Changed("format") {
 		logger.Error("Cannot use both --info and --format (--info always outputs JSON)")
 		return fmt.Errorf("conflicting flags: --info and --format")
@@ -332,6 +1213,218 @@ func validateFlagCombinations(cmd *cobra.Command, hasURLs bool, hasMultipleURLs
 		return fmt.Errorf("conflicting flags: --info and --all-tabs")
 	}
 
+	if flowFile != "" {
+		if hasURLs || cmd.Flags().Changed("tab") || allTabs {
+			logger.Error("Cannot use --flow with URL arguments, --tab, or --all-tabs (the flow defines its own pages)")
+			return fmt.Errorf("conflicting flags: --flow and other content sources")
+		}
+		if outputFile != "" {
+			logger.Error("Cannot use --output with --flow (each snag step names its own file). Use --output-dir instead")
+			return ErrOutputFlagConflict
+		}
+		if info || matrix != "" {
+			logger.Error("Cannot use --flow with --info or --matrix")
+			return fmt.Errorf("conflicting flags: --flow with --info or --matrix")
+		}
+		if cmd.Flags().Changed("format") {
+			logger.Warning("--format ignored with --flow (set format on each snag step)")
+		}
+	}
+
+	if stream {
+		if urlFile == "" {
+			logger.Error("--stream requires --url-file")
+			logger.ErrorWithSuggestion(
+				"Stream URLs from stdin or a named pipe",
+				"tail -f urls.txt | snag --url-file - --stream -d output/",
+			)
+			return fmt.Errorf("--stream requires --url-file")
+		}
+		if hasURLs || cmd.Flags().Changed("tab") || allTabs {
+			logger.Error("Cannot use --stream with URL arguments, --tab, or --all-tabs (URLs come from --url-file)")
+			return fmt.Errorf("conflicting flags: --stream and other content sources")
+		}
+		if outputFile != "" {
+			logger.Error("Cannot use --output with --stream (one file per URL). Use --output-dir instead")
+			return ErrOutputFlagConflict
+		}
+		if info || matrix != "" || flowFile != "" || openBrowser {
+			logger.Error("Cannot use --stream with --info, --matrix, --flow, or --open-browser")
+			return fmt.Errorf("conflicting flags: --stream with --info, --matrix, --flow, or --open-browser")
+		}
+		if captureResp != "" || emitCurl != "" || record != "" {
+			logger.Warning("--capture-responses, --emit-curl, and --record ignored with --stream")
+		}
+	}
+
+	if incremental && stateFile == "" {
+		logger.Error("--incremental requires --state")
+		logger.ErrorWithSuggestion(
+			"Incremental runs compare against the previous run's state file",
+			"snag --url-file urls.txt --state site.db --incremental -d site/",
+		)
+		return fmt.Errorf("--incremental requires --state")
+	}
+
+	if stateFile != "" {
+		if cmd.Flags().Changed("tab") || allTabs {
+			logger.Error("Cannot use --state with --tab or --all-tabs (state tracks URLs)")
+			return fmt.Errorf("conflicting flags: --state and --tab/--all-tabs")
+		}
+		if outputFile != "" {
+			logger.Error("Cannot use --output with --state (one file per URL). Use --output-dir instead")
+			return ErrOutputFlagConflict
+		}
+		if info || stream || matrix != "" || flowFile != "" || openBrowser {
+			logger.Error("Cannot use --state with --info, --stream, --matrix, --flow, or --open-browser")
+			return fmt.Errorf("conflicting flags: --state with --info, --stream, --matrix, --flow, or --open-browser")
+		}
+	}
+
+	if summaryFile != "" {
+		if err := validateOutputPath(summaryFile); err != nil {
+			return err
+		}
+	}
+
+	if err := validateFilenameLang(filenameLang); err != nil {
+		return err
+	}
+	if err := validateTitleFrom(titleFrom); err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("filename-template") {
+		if err := validateFilenameTemplate(strings.TrimSpace(filenameTmpl)); err != nil {
+			return err
+		}
+		if output != "" {
+			logger.Warning("--filename-template ignored with --output (the file is already named)")
+		}
+		if latestLink {
+			logger.Warning("--latest-link only links files with the default timestamped names")
+		}
+	}
+
+	if duplicates != "" {
+		if err := validateDuplicates(duplicates); err != nil {
+			return err
+		}
+		if f := normalizeFormat(format); f == FormatPDF || f == FormatPNG {
+			logger.Warning("--duplicates only applies to text formats")
+		} else if !hasMultipleURLs || stream {
+			logger.Warning("--duplicates only applies to batches of several URLs")
+		}
+	}
+
+	if linksReport != "" {
+		if err := validateOutputPath(linksReport); err != nil {
+			return err
+		}
+		if info {
+			logger.Warning("--links-report is ignored with --info")
+		}
+	}
+
+	if dumpHeaders != "" {
+		if err := validateOutputPath(dumpHeaders); err != nil {
+			return err
+		}
+		if cmd.Flags().Changed("tab") || allTabs {
+			logger.Warning("--dump-headers only records fetched URLs (ignored with --tab and --all-tabs)")
+		}
+	}
+
+	if traceHTTP && (cmd.Flags().Changed("tab") || allTabs) {
+		logger.Warning("--trace-http only traces fetched URLs (ignored with --tab and --all-tabs)")
+	}
+
+	if runDir && outDir == "" {
+		logger.Error("--run-dir requires --output-dir")
+		logger.ErrorWithSuggestion(
+			"Give the directory to create run directories in",
+			"snag --url-file urls.txt -d snapshots/ --run-dir",
+		)
+		return fmt.Errorf("--run-dir requires --output-dir")
+	}
+
+	if mirrorPaths && outDir == "" {
+		logger.Error("--mirror-paths requires --output-dir")
+		logger.ErrorWithSuggestion(
+			"Give the directory to mirror pages into",
+			"snag --crawl -d docs/ --mirror-paths https://example.com/docs/",
+		)
+		return fmt.Errorf("--mirror-paths requires --output-dir")
+	}
+	if mirrorPaths && latestLink {
+		logger.Warning("--latest-link only links files with the default timestamped names")
+	}
+
+	if cmd.Flags().Changed("wait-for-js") {
+		waitForJSExpr = strings.TrimSpace(waitForJSExpr)
+		if waitForJSExpr == "" {
+			logger.Warning("--wait-for-js is empty, ignoring")
+		}
+	}
+
+	if source, script, err := loadEvalScript(strings.TrimSpace(evalFile), evalExpr); err != nil {
+		return err
+	} else if script == "" && (cmd.Flags().Changed("eval") || cmd.Flags().Changed("eval-expr")) {
+		logger.Warning("%s is empty, ignoring", strings.Fields(source)[0])
+	} else {
+		evalSource, evalScript = source, script
+	}
+	if normalizeFormat(format) == FormatJSON && evalScript == "" {
+		logger.Error("--format json requires --eval or --eval-expr")
+		logger.ErrorWithSuggestion(
+			"JSON output is the value a script returns from the page",
+			`snag --eval-expr "window.__INITIAL_STATE__" -f json https://example.com`,
+		)
+		return fmt.Errorf("format json requires --eval or --eval-expr")
+	}
+
+	if latestLink && outDir == "" {
+		logger.Error("--latest-link requires --output-dir")
+		logger.ErrorWithSuggestion(
+			"Give the directory snapshots are saved to",
+			"snag --url-file urls.txt -d snapshots/ --latest-link",
+		)
+		return fmt.Errorf("--latest-link requires --output-dir")
+	}
+
+	if outputTar != "" {
+		if outputFile != "" || outDir != "" {
+			logger.Error("Cannot use --output or --output-dir with --output-tar (files are written to the archive)")
+			return ErrOutputFlagConflict
+		}
+		if info || stream || flowFile != "" || openBrowser {
+			logger.Error("Cannot use --output-tar with --info, --stream, --flow, or --open-browser")
+			return fmt.Errorf("conflicting flags: --output-tar with --info, --stream, --flow, or --open-browser")
+		}
+		if !hasMultipleURLs && urlFile == "" && stateFile == "" && !allTabs && matrix == "" {
+			logger.Error("--output-tar requires multiple URLs, --url-file, --all-tabs, or --matrix")
+			logger.ErrorWithSuggestion(
+				"Use --output-tar for batch fetches",
+				"snag --url-file urls.txt --output-tar - | tar -x",
+			)
+			return fmt.Errorf("--output-tar requires a batch of URLs or tabs")
+		}
+	}
+
+	if matrix != "" {
+		if cmd.Flags().Changed("tab") || allTabs {
+			logger.Error("Cannot use --matrix with --tab or --all-tabs (emulation requires a fresh page load)")
+			return fmt.Errorf("conflicting flags: --matrix and --tab/--all-tabs")
+		}
+		if outputFile != "" {
+			logger.Error("Cannot use --output with --matrix (one file per variant). Use --output-dir instead")
+			return ErrOutputFlagConflict
+		}
+		if info {
+			logger.Error("Cannot use --matrix with --info")
+			return fmt.Errorf("conflicting flags: --matrix and --info")
+		}
+	}
+
 	return nil
 }
 
@@ -346,25 +1439,51 @@ func runCobra(cmd *cobra.Command, args []string) error {
 	}
 
 	logger = NewLogger(level)
+	if err := validateLogFormat(logFormat); err != nil {
+		return err
+	}
 
 	var urls []string
+	outputNames := make(map[string]string)
 
 	outputFile := strings.TrimSpace(output)
 	outDir := strings.TrimSpace(outputDir)
 
-	// Load URLs from file if specified
-	if urlFile != "" {
-		fileURLs, err := loadURLsFromFile(strings.TrimSpace(urlFile))
+	// Load URLs from file if specified. With --stream the file is read
+	// incrementally by handleStream instead.
+	if urlFile != "" && !stream {
+		entries, err := loadURLEntriesFromFile(strings.TrimSpace(urlFile))
 		if err != nil {
 			return err
 		}
-		urls = append(urls, fileURLs...)
+		for _, entry := range entries {
+			urls = append(urls, entry.URL)
+			if entry.Name != "" {
+				outputNames[entry.URL] = entry.Name
+			}
+		}
 	}
 
 	for _, arg := range args {
 		trimmedArg := strings.TrimSpace(arg)
-		if trimmedArg != "" {
+		if trimmedArg == "" {
+			continue
+		}
+
+		// Local files, directories, and globs expand to file:// URLs
+		entries, err := expandLocalPath(trimmedArg, GetFileExtension(normalizeFormat(format)))
+		if err != nil {
+			return err
+		}
+		if entries == nil {
 			urls = append(urls, trimmedArg)
+			continue
+		}
+		for _, entry := range entries {
+			urls = append(urls, entry.URL)
+			if entry.Name != "" {
+				outputNames[entry.URL] = entry.Name
+			}
 		}
 	}
 
@@ -404,22 +1523,125 @@ func runCobra(cmd *cobra.Command, args []string) error {
 			logger.Error("Cannot use --kill-browser with --url-file (conflicting operations)")
 			return fmt.Errorf("conflicting flags: --kill-browser and --url-file")
 		}
+		if k8sPod != "" {
+			logger.Error("Cannot use --kill-browser with --k8s-pod (the pod's browser is not snag's to kill)")
+			return fmt.Errorf("conflicting flags: --kill-browser and --k8s-pod")
+		}
 		return handleKillBrowser(cmd)
 	}
 
+	if k8sPod != "" && (forceHead || openBrowser) {
+		logger.Error("Cannot use --force-headless or --open-browser with --k8s-pod (the pod's browser is used)")
+		return fmt.Errorf("conflicting flags: --k8s-pod with --force-headless or --open-browser")
+	}
+
 	if listTabs {
 		if len(urls) > 0 {
 			logger.Verbose("--list-tabs overrides URL arguments (URLs will be ignored)")
 		}
+		if k8sPod != "" {
+			stop, err := connectK8sPod(k8sPod)
+			if err != nil {
+				return err
+			}
+			defer stop()
+		}
 		return handleListTabs(cmd)
 	}
 
 	hasURLs := len(urls) > 0
-	hasMultipleURLs := len(urls) > 1
+	hasMultipleURLs := len(urls) > 1 || crawl
 	if err := validateFlagCombinations(cmd, hasURLs, hasMultipleURLs); err != nil {
 		return err
 	}
 
+	// Connected after validation, so a bad flag never starts a port-forward
+	if k8sPod != "" {
+		stop, err := connectK8sPod(k8sPod)
+		if err != nil {
+			return err
+		}
+		defer stop()
+	}
+
+	if dir := strings.TrimSpace(assetsDir); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			logger.Error("Failed to create assets directory: %s", dir)
+			return fmt.Errorf("failed to create assets directory: %w", err)
+		}
+		assetStore = NewAssetStore(dir)
+	}
+
+	if addr := strings.TrimSpace(metricsListen); addr != "" {
+		if err := startMetricsServer(addr); err != nil {
+			return err
+		}
+	}
+
+	if dest := strings.TrimSpace(outputTar); dest != "" {
+		t, err := openTarOutput(dest)
+		if err != nil {
+			return err
+		}
+		tarOutput = t
+		defer func() {
+			if err := t.Close(); err != nil {
+				logger.Error("%v", err)
+			}
+			tarOutput = nil
+		}()
+	}
+
+	if path := strings.TrimSpace(linksReport); path != "" {
+		linkInventory = NewLinkInventory()
+		defer func() {
+			if err := linkInventory.Write(path); err != nil {
+				logger.Error("%v", err)
+			}
+			linkInventory = nil
+		}()
+	}
+
+	if path := strings.TrimSpace(dumpHeaders); path != "" {
+		responseLog = NewResponseLog()
+		defer func() {
+			if err := responseLog.Write(path); err != nil {
+				logger.Error("%v", err)
+			}
+			responseLog = nil
+		}()
+	}
+
+	if runDir {
+		started := time.Now()
+		parent := outDir
+		dir, err := createRunDir(parent, started)
+		if err != nil {
+			return err
+		}
+		outputDir, outDir = dir, dir
+		defer func() {
+			manifest := &RunManifest{Started: started, Finished: time.Now(), URLs: urls}
+			if err := writeRunManifest(dir, manifest); err != nil {
+				logger.Error("%v", err)
+				return
+			}
+			// A run that saved nothing is not worth pointing at
+			if latestLink && len(manifest.Files) > 0 {
+				if err := linkLatestRun(parent, dir); err != nil {
+					logger.Error("%v", err)
+				}
+			}
+		}()
+	} else if latestLink {
+		dir := outDir
+		defer func() {
+			if err := linkLatestSnapshots(dir); err != nil {
+				logger.Error("%v", err)
+			}
+		}()
+	}
+
 	if info {
 		if cmd.Flags().Changed("tab") {
 			return handleInfoFromTab(cmd)
@@ -439,6 +1661,14 @@ func runCobra(cmd *cobra.Command, args []string) error {
 		return handleTabFetch(cmd)
 	}
 
+	if flowFile != "" {
+		return handleFlow(cmd)
+	}
+
+	if stream {
+		return handleStream(cmd)
+	}
+
 	if openBrowser && len(urls) == 0 {
 		if cmd.Flags().Changed("format") {
 			logger.Warning("--format ignored with --open-browser (no content fetching)")
@@ -452,9 +1682,17 @@ func runCobra(cmd *cobra.Command, args []string) error {
 		if cmd.Flags().Changed("timeout") {
 			logger.Warning("--timeout ignored with --open-browser (no content fetching)")
 		}
+		for _, name := range []string{"nav-timeout", "stabilize-timeout", "wait-timeout", "title-wait"} {
+			if cmd.Flags().Changed(name) {
+				logger.Warning("--%s ignored with --open-browser (no content fetching)", name)
+			}
+		}
 		if cmd.Flags().Changed("wait-for") {
 			logger.Warning("--wait-for ignored with --open-browser (no content fetching)")
 		}
+		if cmd.Flags().Changed("wait-for-js") {
+			logger.Warning("--wait-for-js ignored with --open-browser (no content fetching)")
+		}
 		if cmd.Flags().Changed("user-agent") {
 			logger.Warning("--user-agent ignored with --open-browser (no navigation)")
 		}
@@ -476,21 +1714,35 @@ func runCobra(cmd *cobra.Command, args []string) error {
 			Port:        port,
 			OpenBrowser: true,
 			UserDataDir: validatedUserDataDir,
+			Offline:     offline,
 		})
 		return bm.OpenBrowserOnly()
 	}
 
+	// A state file can resume a batch without any new URLs
+	if stateFile != "" {
+		return handleMultipleURLs(cmd, urls, outputNames)
+	}
+
 	if len(urls) == 0 {
 		logger.Error("No URLs provided")
 		logger.ErrorWithSuggestion("Provide URLs as arguments or use --url-file", "snag <url> or snag --url-file urls.txt")
 		return ErrNoValidURLs
 	}
 
+	if name := normalizeEngine(engine); name != EngineChromium {
+		return handleEngine(name, urls, outputNames)
+	}
+
 	if openBrowser && len(urls) > 0 {
 		return handleOpenURLsInBrowser(cmd, urls)
 	}
 
-	if len(urls) == 1 {
+	if matrix != "" {
+		return handleMatrix(cmd, urls)
+	}
+
+	if len(urls) == 1 && !crawl {
 		urlStr := urls[0]
 
 		validatedURL, err := validateURL(urlStr)
@@ -514,6 +1766,11 @@ func runCobra(cmd *cobra.Command, args []string) error {
 		validatedUserAgent := validateUserAgent(userAgent, cmd.Flags().Changed("user-agent"))
 		validatedWaitFor := validateWaitFor(waitFor, cmd.Flags().Changed("wait-for"))
 
+		networkConditions, err := validateThrottle(throttle)
+		if err != nil {
+			return err
+		}
+
 		config := &Config{
 			URL:           validatedURL,
 			OutputFile:    outputFile,
@@ -527,6 +1784,36 @@ func runCobra(cmd *cobra.Command, args []string) error {
 			OpenBrowser:   openBrowser,
 			UserAgent:     validatedUserAgent,
 			UserDataDir:   validatedUserDataDir,
+			Throttle:      networkConditions,
+			ReadOnly:      readOnly,
+			Offline:       offline,
+			RemoteOnly:    k8sPod != "",
+			Headers:       requestHeaders,
+			Actions:       pageActions,
+
+			CaptureResponses: strings.TrimSpace(captureResp),
+			EmitCurl:         strings.TrimSpace(emitCurl),
+			Record:           strings.TrimSpace(record),
+			RecordDuration:   recordDuration,
+		}
+
+		// A --url-file with a single URL still names its output for the archive
+		if tarOutput != nil {
+			config.OutputDir = "."
+		}
+
+		// A filename given in the URL file is used unless --output overrides it
+		if name, ok := outputNames[urlStr]; ok && config.OutputFile == "" {
+			dir := config.OutputDir
+			if dir == "" {
+				dir = "."
+			}
+			namedPath, err := namedOutputPath(dir, name)
+			if err != nil {
+				return err
+			}
+			config.OutputFile = namedPath
+			config.OutputDir = ""
 		}
 
 		logger.Debug("Config: format=%s, timeout=%d, port=%d", config.Format, config.Timeout, config.Port)
@@ -544,12 +1831,27 @@ func runCobra(cmd *cobra.Command, args []string) error {
 		}
 
 		if cmd.Flags().Changed("output") || config.OutputFile != "" {
-			if err := validateOutputPath(config.OutputFile); err != nil {
-				return err
+			// Named entries in an archive have no directory on disk to check
+			if tarOutput == nil {
+				if err := validateOutputPath(config.OutputFile); err != nil {
+					return err
+				}
 			}
 			checkExtensionMismatch(config.OutputFile, config.Format)
 		}
 
+		if config.EmitCurl != "" {
+			if err := validateOutputPath(config.EmitCurl); err != nil {
+				return err
+			}
+		}
+
+		if config.Record != "" {
+			if err := validateRecord(config.Record, config.RecordDuration); err != nil {
+				return err
+			}
+		}
+
 		if cmd.Flags().Changed("output-dir") && config.OutputDir == "" {
 			config.OutputDir = "."
 		}
@@ -562,8 +1864,9 @@ func runCobra(cmd *cobra.Command, args []string) error {
 
 		logger.Verbose("Configuration: format=%s, timeout=%ds, port=%d", config.Format, config.Timeout, config.Port)
 
+		logger.SetContext(LogContext{URL: config.URL})
 		return snag(config)
 	}
 
-	return handleMultipleURLs(cmd, urls)
+	return handleMultipleURLs(cmd, urls, outputNames)
 }
@@ -0,0 +1,521 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// markupDialect describes how a lightweight markup language such as Org
+// mode or reStructuredText writes each element. The shared renderer walks
+// the HTML and asks the dialect for the syntax.
+type markupDialect struct {
+	heading   func(level int, text string) string
+	emphasis  func(text string) string
+	strong    func(text string) string
+	code      func(text string) string
+	link      func(text, href string) string
+	image     func(alt, src string) string
+	codeBlock func(lang, code string) string
+	quote     func(body string) string
+	table     func(rows [][]string, header bool) string
+	escape    func(text string) string
+	rule      string
+
+	// orderedMarker returns the marker of item n of an ordered list
+	orderedMarker func(n int) string
+
+	// looseItems separates blocks inside list items with blank lines, as
+	// reStructuredText requires before nested lists.
+	looseItems bool
+
+	// inlineImages renders images within paragraph text. Otherwise images
+	// become blocks after the paragraph holding them.
+	inlineImages bool
+
+	// paragraph, if set, lays out the text of each paragraph, such as
+	// wrapping it to a width.
+	paragraph func(text string) string
+}
+
+// orgDialect writes Emacs Org mode.
+var orgDialect = markupDialect{
+	heading: func(level int, text string) string {
+		return strings.Repeat("*", level) + " " + text
+	},
+	emphasis: func(text string) string { return wrapInline(text, "/", "/") },
+	strong:   func(text string) string { return wrapInline(text, "*", "*") },
+	code:     func(text string) string { return wrapInline(text, "~", "~") },
+	link: func(text, href string) string {
+		if text == "" || text == href {
+			return "[[" + href + "]]"
+		}
+		return "[[" + href + "][" + text + "]]"
+	},
+	image: func(alt, src string) string {
+		return "[[" + src + "]]"
+	},
+	codeBlock: func(lang, code string) string {
+		var lines []string
+		for _, line := range strings.Split(code, "\n") {
+			// Lines that Org would read as headings or keywords are escaped
+			if strings.HasPrefix(line, "*") || strings.HasPrefix(line, "#+") {
+				line = "," + line
+			}
+			lines = append(lines, line)
+		}
+		if lang == "" {
+			return "#+BEGIN_EXAMPLE\n" + strings.Join(lines, "\n") + "\n#+END_EXAMPLE"
+		}
+		return "#+BEGIN_SRC " + lang + "\n" + strings.Join(lines, "\n") + "\n#+END_SRC"
+	},
+	quote: func(body string) string {
+		return "#+BEGIN_QUOTE\n" + body + "\n#+END_QUOTE"
+	},
+	table: func(rows [][]string, header bool) string {
+		widths := columnWidths(rows)
+		var lines []string
+		for i, row := range rows {
+			cells := make([]string, len(widths))
+			for j := range widths {
+				cell := ""
+				if j < len(row) {
+					cell = strings.ReplaceAll(row[j], "|", "\\vert{}")
+				}
+				cells[j] = cell + strings.Repeat(" ", widths[j]-utf8.RuneCountInString(cell))
+			}
+			lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+			if i == 0 && header {
+				rules := make([]string, len(widths))
+				for j, w := range widths {
+					rules[j] = strings.Repeat("-", w+2)
+				}
+				lines = append(lines, "|"+strings.Join(rules, "+")+"|")
+			}
+		}
+		return strings.Join(lines, "\n")
+	},
+	escape:        func(text string) string { return text },
+	rule:          "-----",
+	orderedMarker: func(n int) string { return fmt.Sprintf("%d. ", n) },
+	inlineImages:  true,
+}
+
+// rstUnderlines are the heading underline characters for levels 1 to 6.
+var rstUnderlines = []string{"=", "-", "~", "^", "\"", "'"}
+
+// rstDialect writes reStructuredText as read by Sphinx and docutils.
+var rstDialect = markupDialect{
+	heading: func(level int, text string) string {
+		return text + "\n" + strings.Repeat(rstUnderlines[level-1], max(utf8.RuneCountInString(text), 4))
+	},
+	emphasis: func(text string) string { return wrapInline(text, "*", "*") },
+	strong:   func(text string) string { return wrapInline(text, "**", "**") },
+	code:     func(text string) string { return wrapInline(text, "``", "``") },
+	link: func(text, href string) string {
+		if text == "" || text == href {
+			return "`<" + href + ">`__"
+		}
+		return "`" + strings.ReplaceAll(text, "`", "\\`") + " <" + href + ">`__"
+	},
+	image: func(alt, src string) string {
+		block := ".. image:: " + src
+		if alt != "" {
+			block += "\n   :alt: " + alt
+		}
+		return block
+	},
+	codeBlock: func(lang, code string) string {
+		block := "::"
+		if lang != "" {
+			block = ".. code-block:: " + lang
+		}
+		return block + "\n\n" + indentLines(code, "   ")
+	},
+	quote: func(body string) string {
+		return indentLines(body, "    ")
+	},
+	table: func(rows [][]string, header bool) string {
+		lines := []string{".. list-table::"}
+		if header {
+			lines = append(lines, "   :header-rows: 1")
+		}
+		lines = append(lines, "")
+		for _, row := range rows {
+			for j, cell := range row {
+				marker := "     - "
+				if j == 0 {
+					marker = "   * - "
+				}
+				lines = append(lines, marker+cell)
+			}
+		}
+		return strings.Join(lines, "\n")
+	},
+	escape: func(text string) string {
+		return strings.NewReplacer(`\`, `\\`, `*`, `\*`, "`", "\\`", `_`, `\_`, `|`, `\|`).Replace(text)
+	},
+	rule:          "----------",
+	orderedMarker: func(int) string { return "#. " },
+	looseItems:    true,
+}
+
+// wrapInline wraps text in inline markup, keeping surrounding whitespace
+// outside the markers since neither Org nor reStructuredText allows it
+// inside.
+func wrapInline(text, open, close string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	lead := text[:strings.Index(text, trimmed)]
+	trail := text[len(lead)+len(trimmed):]
+	return lead + open + trimmed + close + trail
+}
+
+// indentLines prefixes every non-empty line of text with indent.
+func indentLines(text, indent string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = indent + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// columnWidths returns the widest cell of each column of rows.
+func columnWidths(rows [][]string) []int {
+	var widths []int
+	for _, row := range rows {
+		for j, cell := range row {
+			if j >= len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[j] = max(widths[j], utf8.RuneCountInString(cell))
+		}
+	}
+	return widths
+}
+
+// skippedElements are never rendered.
+var skippedElements = map[string]bool{
+	"head": true, "script": true, "style": true, "noscript": true,
+	"template": true, "svg": true, "iframe": true, "button": true,
+	"input": true, "select": true, "textarea": true,
+}
+
+// blockElements start a new block when they appear among inline content.
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"body": true, "dd": true, "details": true, "dialog": true, "div": true,
+	"dl": true, "dt": true, "fieldset": true, "figcaption": true,
+	"figure": true, "footer": true, "h1": true, "h2": true, "h3": true,
+	"h4": true, "h5": true, "h6": true, "header": true, "hr": true,
+	"li": true, "main": true, "nav": true, "ol": true, "p": true,
+	"pre": true, "section": true, "summary": true, "table": true,
+	"ul": true, "html": true,
+}
+
+// markupRenderer converts an HTML tree to blocks of a markup dialect.
+type markupRenderer struct {
+	d markupDialect
+
+	// images holds images found in the current paragraph when the dialect
+	// cannot place them inline.
+	images []string
+}
+
+// convertHTMLToMarkup converts htmlContent to the given dialect.
+func convertHTMLToMarkup(htmlContent string, d markupDialect) (string, error) {
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return "", err
+	}
+
+	r := &markupRenderer{d: d}
+	blocks := r.blocks(doc)
+	if len(blocks) == 0 {
+		return "", nil
+	}
+	return strings.Join(blocks, "\n\n") + "\n", nil
+}
+
+// blocks renders the children of n as a list of blocks. Runs of inline
+// content between block elements become paragraphs.
+func (r *markupRenderer) blocks(n *html.Node) []string {
+	var blocks []string
+	var inline strings.Builder
+
+	flush := func() {
+		text := strings.TrimSpace(collapseLines(inline.String()))
+		if text != "" {
+			if r.d.paragraph != nil {
+				text = r.d.paragraph(text)
+			}
+			blocks = append(blocks, text)
+		}
+		inline.Reset()
+		blocks = append(blocks, r.images...)
+		r.images = nil
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && skippedElements[c.Data] {
+			continue
+		}
+		if c.Type != html.ElementNode || !blockElements[c.Data] {
+			inline.WriteString(r.inline(c))
+			continue
+		}
+
+		flush()
+		blocks = append(blocks, r.block(c)...)
+	}
+	flush()
+
+	return blocks
+}
+
+// block renders a block element as zero or more blocks.
+func (r *markupRenderer) block(n *html.Node) []string {
+	switch n.Data {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		text := strings.TrimSpace(collapseLines(r.inlineChildren(n)))
+		if text == "" {
+			return nil
+		}
+		return []string{r.d.heading(int(n.Data[1]-'0'), text)}
+
+	case "pre":
+		code := strings.TrimRight(textContent(n), "\n")
+		if code == "" {
+			return nil
+		}
+		return []string{r.d.codeBlock(codeLanguage(n), code)}
+
+	case "blockquote":
+		body := strings.Join(r.blocks(n), "\n\n")
+		if body == "" {
+			return nil
+		}
+		return []string{r.d.quote(body)}
+
+	case "ul", "ol":
+		if list := r.list(n); list != "" {
+			return []string{list}
+		}
+		return nil
+
+	case "hr":
+		return []string{r.d.rule}
+
+	case "table":
+		rows, header := r.tableRows(n)
+		if len(rows) == 0 {
+			return nil
+		}
+		return []string{r.d.table(rows, header)}
+
+	default:
+		return r.blocks(n)
+	}
+}
+
+// list renders a ul or ol element, indenting item continuation lines and
+// nested lists under the item's marker.
+func (r *markupRenderer) list(n *html.Node) string {
+	var items []string
+	count := 0
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || c.Data != "li" {
+			continue
+		}
+		count++
+
+		marker := "- "
+		if n.Data == "ol" {
+			marker = r.d.orderedMarker(count)
+		}
+
+		sep := "\n"
+		if r.d.looseItems {
+			sep = "\n\n"
+		}
+		body := strings.Join(r.blocks(c), sep)
+		items = append(items, marker+strings.TrimPrefix(indentLines(body, strings.Repeat(" ", len(marker))), strings.Repeat(" ", len(marker))))
+	}
+
+	if r.d.looseItems {
+		return strings.Join(items, "\n\n")
+	}
+	return strings.Join(items, "\n")
+}
+
+// tableRows returns the cell text of every row in a table and whether the
+// first row is a header.
+func (r *markupRenderer) tableRows(n *html.Node) ([][]string, bool) {
+	var rows [][]string
+	header := false
+
+	for row := range n.Descendants() {
+		if row.Type != html.ElementNode || row.Data != "tr" {
+			continue
+		}
+		var cells []string
+		allHeaders := true
+		for c := row.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode || (c.Data != "td" && c.Data != "th") {
+				continue
+			}
+			if c.Data == "td" {
+				allHeaders = false
+			}
+			cells = append(cells, strings.TrimSpace(collapseLines(strings.ReplaceAll(r.inlineChildren(c), "\n", " "))))
+		}
+		if len(cells) == 0 {
+			continue
+		}
+		if len(rows) == 0 {
+			header = allHeaders || (row.Parent != nil && row.Parent.Data == "thead")
+		}
+		rows = append(rows, cells)
+	}
+
+	return rows, header
+}
+
+// inline renders a node within paragraph text.
+func (r *markupRenderer) inline(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		return r.d.escape(collapseSpace(n.Data))
+	case html.ElementNode:
+	default:
+		return ""
+	}
+
+	if skippedElements[n.Data] {
+		return ""
+	}
+
+	switch n.Data {
+	case "em", "i", "cite", "dfn":
+		return r.d.emphasis(r.inlineChildren(n))
+	case "strong", "b":
+		return r.d.strong(r.inlineChildren(n))
+	case "code", "kbd", "samp", "tt":
+		return r.d.code(collapseSpace(textContent(n)))
+	case "br":
+		return "\n"
+	case "a":
+		text := r.inlineChildren(n)
+		href := strings.TrimSpace(attr(n, "href"))
+		if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+			return text
+		}
+		return r.d.link(strings.TrimSpace(text), href)
+	case "img":
+		src := strings.TrimSpace(attr(n, "src"))
+		if src == "" {
+			return ""
+		}
+		if r.d.inlineImages {
+			return r.d.image(attr(n, "alt"), src)
+		}
+		r.images = append(r.images, r.d.image(attr(n, "alt"), src))
+		return ""
+	}
+
+	return r.inlineChildren(n)
+}
+
+// inlineChildren renders the children of n as paragraph text, treating any
+// nested block elements as inline.
+func (r *markupRenderer) inlineChildren(n *html.Node) string {
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(r.inline(c))
+	}
+	return b.String()
+}
+
+// collapseSpace replaces runs of whitespace with a single space, as HTML
+// rendering does.
+func collapseSpace(text string) string {
+	if text == "" {
+		return ""
+	}
+	collapsed := strings.Join(strings.Fields(text), " ")
+	if collapsed == "" {
+		return " "
+	}
+	if strings.IndexAny(text[:1], " \t\r\n") == 0 {
+		collapsed = " " + collapsed
+	}
+	if strings.IndexAny(text[len(text)-1:], " \t\r\n") == 0 {
+		collapsed += " "
+	}
+	return collapsed
+}
+
+// collapseLines tidies rendered paragraph text: repeated spaces become one
+// and lines from <br> lose surrounding spaces.
+func collapseLines(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// textContent returns the raw text inside n.
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	for c := range n.Descendants() {
+		if c.Type == html.TextNode {
+			b.WriteString(c.Data)
+		}
+	}
+	return b.String()
+}
+
+// codeLanguage returns the language of a pre block from a language-x or
+// lang-x class on it or its code element.
+func codeLanguage(pre *html.Node) string {
+	nodes := []*html.Node{pre}
+	for c := pre.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Data == "code" {
+			nodes = append(nodes, c)
+		}
+	}
+	for _, n := range nodes {
+		for _, class := range strings.Fields(attr(n, "class")) {
+			for _, prefix := range []string{"language-", "lang-"} {
+				if lang, ok := strings.CutPrefix(class, prefix); ok && lang != "" {
+					return lang
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// attr returns the value of attribute key on n, or "".
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import "testing"
+
+const markupTestHTML = `<html><head><title>T</title><style>p{}</style></head><body>
+<h1>Guide</h1>
+<p>Some <em>emphasis</em>, <strong>bold</strong> and <code>x := 1</code>
+with a <a href="https://example.com/docs">link</a>.</p>
+<h2>Install</h2>
+<pre><code class="language-go">func main() {}
+</code></pre>
+<ul><li>One</li><li>Two<ul><li>Nested</li></ul></li></ul>
+<ol><li>First</li><li>Second</li></ol>
+<blockquote><p>Quoted</p></blockquote>
+<p><img src="/logo.png" alt="Logo"></p>
+<table><tr><th>Name</th><th>Value</th></tr><tr><td>a</td><td>1</td></tr></table>
+</body></html>`
+
+func TestConvertHTMLToMarkup_Org(t *testing.T) {
+	got, err := convertHTMLToMarkup(markupTestHTML, orgDialect)
+	if err != nil {
+		t.Fatalf("convertHTMLToMarkup failed: %v", err)
+	}
+
+	want := `* Guide
+
+Some /emphasis/, *bold* and ~x := 1~ with a [[https://example.com/docs][link]].
+
+** Install
+
+#+BEGIN_SRC go
+func main() {}
+#+END_SRC
+
+- One
+- Two
+  - Nested
+
+1. First
+2. Second
+
+#+BEGIN_QUOTE
+Quoted
+#+END_QUOTE
+
+[[/logo.png]]
+
+| Name | Value |
+|------+-------|
+| a    | 1     |
+`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestConvertHTMLToMarkup_RST(t *testing.T) {
+	got, err := convertHTMLToMarkup(markupTestHTML, rstDialect)
+	if err != nil {
+		t.Fatalf("convertHTMLToMarkup failed: %v", err)
+	}
+
+	want := "Guide\n=====\n\n" +
+		"Some *emphasis*, **bold** and ``x := 1`` with a `link <https://example.com/docs>`__.\n\n" +
+		"Install\n-------\n\n" +
+		".. code-block:: go\n\n   func main() {}\n\n" +
+		"- One\n\n- Two\n\n  - Nested\n\n" +
+		"#. First\n\n#. Second\n\n" +
+		"    Quoted\n\n" +
+		".. image:: /logo.png\n   :alt: Logo\n\n" +
+		".. list-table::\n   :header-rows: 1\n\n   * - Name\n     - Value\n   * - a\n     - 1\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRSTEscape(t *testing.T) {
+	if got := rstDialect.escape(`a*b_c|d`); got != `a\*b\_c\|d` {
+		t.Errorf("escape = %s", got)
+	}
+}
+
+func TestWrapInline(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"word", "/word/"},
+		{" word ", " /word/ "},
+		{"  ", "  "},
+	}
+	for _, tt := range tests {
+		if got := wrapInline(tt.in, "/", "/"); got != tt.want {
+			t.Errorf("wrapInline(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,401 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/devices"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/spf13/cobra"
+)
+
+const (
+	MatrixKeyDevice      = "device"
+	MatrixKeyColorScheme = "color-scheme"
+)
+
+// deviceDesktop is a plain 1920x1080 desktop viewport with the browser's own
+// user agent, matching the default headless viewport.
+var deviceDesktop = devices.Device{
+	Title: "Desktop",
+	Screen: devices.Screen{
+		DevicePixelRatio: 1,
+		Horizontal:       devices.ScreenSize{Width: 1920, Height: 1080},
+		Vertical:         devices.ScreenSize{Width: 1920, Height: 1080},
+	},
+}
+
+var deviceIPhone14 = devices.Device{
+	Title:        "iPhone 14",
+	Capabilities: []string{"touch", "mobile"},
+	UserAgent:    "Mozilla/5.0 (iPhone; CPU iPhone OS 16_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.0 Mobile/15E148 Safari/604.1",
+	Screen: devices.Screen{
+		DevicePixelRatio: 3,
+		Horizontal:       devices.ScreenSize{Width: 844, Height: 390},
+		Vertical:         devices.ScreenSize{Width: 390, Height: 844},
+	},
+}
+
+// emulatedDevices maps lower-case device names accepted by --matrix to their
+// emulation profiles.
+var emulatedDevices = map[string]devices.Device{
+	"desktop":       deviceDesktop,
+	"iphone 14":     deviceIPhone14,
+	"iphone x":      devices.IPhoneX,
+	"iphone se":     devices.IPhone5orSE,
+	"iphone 8":      devices.IPhone6or7or8,
+	"iphone 8 plus": devices.IPhone6or7or8Plus,
+	"pixel 2":       devices.Pixel2,
+	"pixel 2 xl":    devices.Pixel2XL,
+	"galaxy s5":     devices.GalaxyS5,
+	"galaxy fold":   devices.GalaxyFold,
+	"ipad":          devices.IPad,
+	"ipad mini":     devices.IPadMini,
+	"ipad pro":      devices.IPadPro,
+	"surface duo":   devices.SurfaceDuo,
+	"moto g4":       devices.MotoG4,
+}
+
+var validColorSchemes = map[string]bool{
+	"light":         true,
+	"dark":          true,
+	"no-preference": true,
+}
+
+// MatrixVariant is one combination of emulation settings from --matrix.
+type MatrixVariant struct {
+	Device      string
+	ColorScheme string
+}
+
+// Label returns a short human-readable name for the variant, used in logs
+// and output filenames.
+func (v MatrixVariant) Label() string {
+	var parts []string
+	if v.Device != "" {
+		parts = append(parts, v.Device)
+	}
+	if v.ColorScheme != "" {
+		parts = append(parts, v.ColorScheme)
+	}
+	return strings.Join(parts, " ")
+}
+
+func deviceNames() string {
+	names := make([]string, 0, len(emulatedDevices))
+	for name := range emulatedDevices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// parseMatrix parses a --matrix specification such as
+// "device=iPhone 14,Desktop;color-scheme=light,dark" into every combination
+// of the listed values.
+func parseMatrix(spec string) ([]MatrixVariant, error) {
+	var deviceValues, schemeValues []string
+
+	for _, dimension := range strings.Split(spec, ";") {
+		dimension = strings.TrimSpace(dimension)
+		if dimension == "" {
+			continue
+		}
+
+		key, values, found := strings.Cut(dimension, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid matrix dimension '%s' (expected key=value,...)", dimension)
+		}
+
+		var list []string
+		for _, value := range strings.Split(values, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				list = append(list, value)
+			}
+		}
+		if len(list) == 0 {
+			return nil, fmt.Errorf("matrix dimension '%s' has no values", key)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case MatrixKeyDevice:
+			for _, name := range list {
+				if _, ok := emulatedDevices[strings.ToLower(name)]; !ok {
+					return nil, fmt.Errorf("unknown device '%s' (supported: %s)", name, deviceNames())
+				}
+			}
+			deviceValues = list
+		case MatrixKeyColorScheme:
+			for i, scheme := range list {
+				list[i] = strings.ToLower(scheme)
+				if !validColorSchemes[list[i]] {
+					return nil, fmt.Errorf("unknown color scheme '%s' (supported: light, dark, no-preference)", scheme)
+				}
+			}
+			schemeValues = list
+		default:
+			return nil, fmt.Errorf("unknown matrix key '%s' (supported: %s, %s)", key, MatrixKeyDevice, MatrixKeyColorScheme)
+		}
+	}
+
+	if deviceValues == nil && schemeValues == nil {
+		return nil, fmt.Errorf("matrix is empty")
+	}
+
+	if deviceValues == nil {
+		deviceValues = []string{""}
+	}
+	if schemeValues == nil {
+		schemeValues = []string{""}
+	}
+
+	variants := make([]MatrixVariant, 0, len(deviceValues)*len(schemeValues))
+	for _, device := range deviceValues {
+		for _, scheme := range schemeValues {
+			variants = append(variants, MatrixVariant{Device: device, ColorScheme: scheme})
+		}
+	}
+
+	return variants, nil
+}
+
+// applyVariant configures page emulation for a matrix variant. It must be
+// called before navigation.
+func applyVariant(page *rod.Page, v MatrixVariant) error {
+	if v.Device != "" {
+		device := emulatedDevices[strings.ToLower(v.Device)]
+		if err := page.SetViewport(device.MetricsEmulation()); err != nil {
+			return fmt.Errorf("failed to emulate %s viewport: %w", v.Device, err)
+		}
+		if err := device.TouchEmulation().Call(page); err != nil {
+			return fmt.Errorf("failed to emulate %s touch: %w", v.Device, err)
+		}
+		if device.UserAgent != "" {
+			if err := page.SetUserAgent(device.UserAgentEmulation()); err != nil {
+				return fmt.Errorf("failed to emulate %s user agent: %w", v.Device, err)
+			}
+		}
+	}
+
+	if v.ColorScheme != "" {
+		err := proto.EmulationSetEmulatedMedia{
+			Features: []*proto.EmulationMediaFeature{
+				{Name: "prefers-color-scheme", Value: v.ColorScheme},
+			},
+		}.Call(page)
+		if err != nil {
+			return fmt.Errorf("failed to emulate %s color scheme: %w", v.ColorScheme, err)
+		}
+	}
+
+	return nil
+}
+
+func validateMatrix(spec string) ([]MatrixVariant, error) {
+	variants, err := parseMatrix(spec)
+	if err != nil {
+		logger.Error("Invalid --matrix: %v", err)
+		logger.ErrorWithSuggestion(
+			"Use key=value,... dimensions separated by ';'",
+			`snag --matrix "device=iPhone 14,Desktop;color-scheme=light,dark" <url>`,
+		)
+		return nil, fmt.Errorf("invalid matrix: %w", err)
+	}
+	return variants, nil
+}
+
+// handleMatrix fetches every URL once per matrix variant, saving each result
+// with the variant label in its filename.
+func handleMatrix(cmd *cobra.Command, urls []string) error {
+	variants, err := validateMatrix(matrix)
+	if err != nil {
+		return err
+	}
+
+	outputFormat := normalizeFormat(format)
+	outDir := strings.TrimSpace(outputDir)
+	if outDir == "" {
+		outDir = "."
+	}
+
+	if err := validateFormat(outputFormat); err != nil {
+		return err
+	}
+
+	if err := validateTimeout(timeout); err != nil {
+		return err
+	}
+
+	if err := validatePort(port); err != nil {
+		return err
+	}
+
+	if err := validateDirectory(outDir); err != nil {
+		return err
+	}
+
+	if cmd.Flags().Changed("user-agent") {
+		logger.Warning("--user-agent may be overridden by --matrix device emulation")
+	}
+
+	validatedUserDataDir := ""
+	if cmd.Flags().Changed("user-data-dir") {
+		validatedDir, err := validateUserDataDir(userDataDir)
+		if err != nil {
+			return err
+		}
+		validatedUserDataDir = validatedDir
+	}
+
+	networkConditions, err := validateThrottle(throttle)
+	if err != nil {
+		return err
+	}
+
+	var validatedURLs []string
+	for _, urlStr := range urls {
+		validatedURL, err := validateURL(urlStr)
+		if err != nil {
+			logger.Warning("Skipping invalid URL '%s': %v", urlStr, err)
+			continue
+		}
+		validatedURLs = append(validatedURLs, validatedURL)
+	}
+
+	if len(validatedURLs) == 0 {
+		logger.Error("No valid URLs to process")
+		return ErrNoValidURLs
+	}
+
+	total := len(validatedURLs) * len(variants)
+	logger.Info("Processing %d URL%s x %d variant%s...",
+		len(validatedURLs), plural(len(validatedURLs)), len(variants), plural(len(variants)))
+
+	bm := NewBrowserManager(BrowserOptions{
+		Port:          port,
+		ForceHeadless: forceHead,
+		UserAgent:     validateUserAgent(userAgent, cmd.Flags().Changed("user-agent")),
+		UserDataDir:   validatedUserDataDir,
+		Throttle:      networkConditions,
+		ReadOnly:      readOnly,
+		Offline:       offline,
+		RemoteOnly:    k8sPod != "",
+	})
+	browserMutex.Lock()
+	browserManager = bm
+	browserMutex.Unlock()
+	defer func() {
+		bm.Close()
+		browserMutex.Lock()
+		browserManager = nil
+		browserMutex.Unlock()
+	}()
+
+	if _, err := bm.Connect(); err != nil {
+		return err
+	}
+
+	validatedWaitFor := validateWaitFor(waitFor, cmd.Flags().Changed("wait-for"))
+	timestamp := time.Now()
+
+	successCount := 0
+	failureCount := 0
+	current := 0
+
+	for _, validatedURL := range validatedURLs {
+		for _, variant := range variants {
+			current++
+			label := variant.Label()
+
+			batchPause.Wait(fmt.Sprintf("[%d/%d] %s (%s)", current, total, validatedURL, label))
+			logger.Info("[%d/%d] Fetching: %s (%s)", current, total, validatedURL, label)
+
+			page, err := bm.NewPage()
+			if err != nil {
+				logger.Error("[%d/%d] Failed to create page: %v", current, total, err)
+				failureCount++
+				continue
+			}
+
+			if err := applyVariant(page, variant); err != nil {
+				logger.Error("[%d/%d] %v", current, total, err)
+				bm.ClosePage(page)
+				failureCount++
+				continue
+			}
+
+			fetcher := NewPageFetcher(page, timeout)
+			_, err = fetcher.Fetch(FetchOptions{
+				URL:     validatedURL,
+				Timeout: timeout,
+				WaitFor: validatedWaitFor,
+				Headers: requestHeaders,
+				Actions: pageActions,
+			})
+			if err != nil {
+				logger.Error("[%d/%d] Failed to fetch: %v", current, total, err)
+				bm.ClosePage(page)
+				failureCount++
+				continue
+			}
+
+			info, err := page.Info()
+			if err != nil {
+				logger.Error("[%d/%d] Failed to get page info: %v", current, total, err)
+				bm.ClosePage(page)
+				failureCount++
+				continue
+			}
+
+			// Truncate the title, not the variant label, so every variant
+			// keeps a distinct filename
+			labelSlug := SlugifyTitle(label, MaxSlugLength)
+			titleSlug := TitleSlug(filenameTitle(page, info.Title, info.URL), MaxSlugLength-len(labelSlug)-1)
+			if titleSlug == "" {
+				titleSlug = SlugifyTitle(GenerateURLSlug(validatedURL), MaxSlugLength-len(labelSlug)-1)
+			}
+
+			outputPath, err := generateOutputFilename(
+				titleSlug+" "+labelSlug, validatedURL, outputFormat,
+				timestamp, outDir,
+			)
+			if err != nil {
+				logger.Error("[%d/%d] Failed to generate filename: %v", current, total, err)
+				bm.ClosePage(page)
+				failureCount++
+				continue
+			}
+
+			if err := processPageContent(page, outputFormat, outputPath); err != nil {
+				logger.Error("[%d/%d] Failed to save content: %v", current, total, err)
+				bm.ClosePage(page)
+				failureCount++
+				continue
+			}
+
+			if bm.launchedHeadless || closeTab {
+				bm.ClosePage(page)
+			}
+
+			successCount++
+		}
+	}
+
+	logger.Success("Batch complete: %d succeeded, %d failed", successCount, failureCount)
+	reportRunSummary(successCount, failureCount, 0, timestamp)
+
+	if failureCount > 0 {
+		return fmt.Errorf("batch processing completed with %d failures", failureCount)
+	}
+
+	return nil
+}
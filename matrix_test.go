@@ -0,0 +1,59 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMatrix(t *testing.T) {
+	variants, err := parseMatrix("device=iPhone 14,Desktop;color-scheme=light,Dark")
+	if err != nil {
+		t.Fatalf("parseMatrix failed: %v", err)
+	}
+
+	want := []MatrixVariant{
+		{Device: "iPhone 14", ColorScheme: "light"},
+		{Device: "iPhone 14", ColorScheme: "dark"},
+		{Device: "Desktop", ColorScheme: "light"},
+		{Device: "Desktop", ColorScheme: "dark"},
+	}
+	if !reflect.DeepEqual(variants, want) {
+		t.Errorf("parseMatrix() = %+v, want %+v", variants, want)
+	}
+}
+
+func TestParseMatrix_SingleDimension(t *testing.T) {
+	variants, err := parseMatrix("color-scheme=dark")
+	if err != nil {
+		t.Fatalf("parseMatrix failed: %v", err)
+	}
+	if len(variants) != 1 || variants[0].Device != "" || variants[0].ColorScheme != "dark" {
+		t.Errorf("unexpected variants: %+v", variants)
+	}
+	if got := variants[0].Label(); got != "dark" {
+		t.Errorf("Label() = %q, want %q", got, "dark")
+	}
+}
+
+func TestParseMatrix_Invalid(t *testing.T) {
+	invalid := []string{
+		"",
+		"device",
+		"device=",
+		"device=Nokia 3310",
+		"color-scheme=sepia",
+		"orientation=landscape",
+	}
+
+	for _, spec := range invalid {
+		if _, err := parseMatrix(spec); err == nil {
+			t.Errorf("expected parseMatrix(%q) to fail", spec)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryCheckInterval is how often the watchdog samples browser memory.
+const MemoryCheckInterval = 2 * time.Second
+
+// sumTreeRSS adds up the resident memory, in bytes, of root and all of its
+// descendants from `ps -A -o pid=,ppid=,rss=` output, where rss is in KB.
+// Chromium runs each renderer, GPU, and utility process as a child of the
+// browser process, so the tree is what a crawl actually costs.
+func sumTreeRSS(psOutput string, root int) int64 {
+	children := make(map[int][]int)
+	rss := make(map[int]int64)
+
+	for _, line := range strings.Split(psOutput, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		pid, err1 := strconv.Atoi(fields[0])
+		ppid, err2 := strconv.Atoi(fields[1])
+		kb, err3 := strconv.ParseInt(fields[2], 10, 64)
+		if err1 != nil || err2 != nil || err3 != nil {
+			continue
+		}
+		children[ppid] = append(children[ppid], pid)
+		rss[pid] = kb * 1024
+	}
+
+	var total int64
+	seen := make(map[int]bool)
+	queue := []int{root}
+	for len(queue) > 0 {
+		pid := queue[0]
+		queue = queue[1:]
+		if seen[pid] {
+			continue
+		}
+		seen[pid] = true
+		total += rss[pid]
+		queue = append(queue, children[pid]...)
+	}
+
+	return total
+}
+
+// processTreeRSS returns the resident memory of pid and its descendants.
+func processTreeRSS(pid int) (int64, error) {
+	output, err := exec.Command("ps", "-A", "-o", "pid=,ppid=,rss=").Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list processes: %w", err)
+	}
+	return sumTreeRSS(string(output), pid), nil
+}
+
+// MemoryWatchdog samples the memory of a launched browser's process tree
+// and flags when it goes over --browser-memory-limit. Batch runs check the
+// flag between pages and restart the browser, so a crawl does not take
+// down a small machine and the current page is not cut off.
+type MemoryWatchdog struct {
+	pid   int
+	limit int64
+	stop  chan struct{}
+	done  chan struct{}
+
+	mu       sync.Mutex
+	usage    int64
+	exceeded bool
+}
+
+// StartMemoryWatchdog begins sampling the process tree rooted at pid.
+func StartMemoryWatchdog(pid int, limit int64) *MemoryWatchdog {
+	mw := &MemoryWatchdog{
+		pid:   pid,
+		limit: limit,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+
+	go func() {
+		defer close(mw.done)
+		ticker := time.NewTicker(MemoryCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			mw.sample()
+			select {
+			case <-mw.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return mw
+}
+
+func (mw *MemoryWatchdog) sample() {
+	usage, err := processTreeRSS(mw.pid)
+	if err != nil {
+		logger.Debug("Memory watchdog: %v", err)
+		return
+	}
+
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	mw.usage = usage
+	if usage > mw.limit && !mw.exceeded {
+		mw.exceeded = true
+		logger.Verbose("Browser memory %s is over the %s limit", formatByteSize(usage), formatByteSize(mw.limit))
+	}
+}
+
+// Exceeded reports whether the browser has gone over the limit, and the
+// memory it was last seen using.
+func (mw *MemoryWatchdog) Exceeded() (bool, int64) {
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	return mw.exceeded, mw.usage
+}
+
+// Stop ends sampling.
+func (mw *MemoryWatchdog) Stop() {
+	close(mw.stop)
+	<-mw.done
+}
+
+// browserMemoryLimit returns --browser-memory-limit in bytes, or 0 when it
+// is not set. The value is validated up front by validateMemoryLimit.
+func browserMemoryLimit() int64 {
+	if strings.TrimSpace(memLimit) == "" {
+		return 0
+	}
+	limit, err := parseByteSize(memLimit)
+	if err != nil {
+		return 0
+	}
+	return limit
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import "testing"
+
+func TestSumTreeRSS(t *testing.T) {
+	ps := `
+    1     0   1000
+  100     1  50000
+  101   100  20000
+  102   100  30000
+  103   102   5000
+  200     1  99999
+  bad  line
+`
+
+	tests := []struct {
+		root int
+		want int64
+	}{
+		{100, (50000 + 20000 + 30000 + 5000) * 1024},
+		{102, (30000 + 5000) * 1024},
+		{200, 99999 * 1024},
+		{999, 0},
+	}
+
+	for _, tt := range tests {
+		if got := sumTreeRSS(ps, tt.root); got != tt.want {
+			t.Errorf("sumTreeRSS(root %d) = %d, want %d", tt.root, got, tt.want)
+		}
+	}
+}
+
+func TestMemoryWatchdog_Exceeded(t *testing.T) {
+	mw := &MemoryWatchdog{limit: 1 << 30}
+	if exceeded, _ := mw.Exceeded(); exceeded {
+		t.Error("new watchdog reports exceeded")
+	}
+
+	mw.usage, mw.exceeded = 2<<30, true
+	exceeded, usage := mw.Exceeded()
+	if !exceeded || usage != 2<<30 {
+		t.Errorf("Exceeded() = %v, %d", exceeded, usage)
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fetchDurationBuckets are the upper bounds, in seconds, of the
+// snag_fetch_duration_seconds histogram.
+var fetchDurationBuckets = []float64{0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Metrics holds process-wide counters exposed in the Prometheus text format.
+// The Prometheus client library is deliberately not used; snag only needs a
+// handful of series and the exposition format is simple and stable.
+type Metrics struct {
+	mu sync.Mutex
+
+	fetches         uint64
+	failures        map[string]uint64
+	durationCounts  []uint64 // cumulative, one per fetchDurationBuckets entry
+	durationSum     float64
+	durationCount   uint64
+	convertedBytes  map[string]uint64
+	browserLaunches uint64
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{
+		failures:       make(map[string]uint64),
+		durationCounts: make([]uint64, len(fetchDurationBuckets)),
+		convertedBytes: make(map[string]uint64),
+	}
+}
+
+var metrics = NewMetrics()
+
+// ObserveFetch records a completed page fetch and, if err is non-nil, its
+// failure reason.
+func (m *Metrics) ObserveFetch(d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.fetches++
+	if err != nil {
+		m.failures[failureReason(err)]++
+	}
+
+	seconds := d.Seconds()
+	for i, bound := range fetchDurationBuckets {
+		if seconds <= bound {
+			m.durationCounts[i]++
+		}
+	}
+	m.durationSum += seconds
+	m.durationCount++
+}
+
+// AddConvertedBytes records the size of content produced in format.
+func (m *Metrics) AddConvertedBytes(format string, n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.convertedBytes[format] += uint64(n)
+}
+
+// IncBrowserLaunches records that snag launched a browser.
+func (m *Metrics) IncBrowserLaunches() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.browserLaunches++
+}
+
+// failureReason maps a fetch error to a low-cardinality label value.
+func failureReason(err error) string {
+	switch {
+	case errors.Is(err, ErrPageLoadTimeout), errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, ErrAuthRequired):
+		return "auth"
+	case errors.Is(err, ErrNavigationFailed):
+		return "navigation"
+	default:
+		return "other"
+	}
+}
+
+// WriteTo writes all metrics in the Prometheus text exposition format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+
+	b.WriteString("# HELP snag_fetches_total Page fetches attempted.\n")
+	b.WriteString("# TYPE snag_fetches_total counter\n")
+	fmt.Fprintf(&b, "snag_fetches_total %d\n", m.fetches)
+
+	b.WriteString("# HELP snag_fetch_failures_total Page fetches that failed, by reason.\n")
+	b.WriteString("# TYPE snag_fetch_failures_total counter\n")
+	for _, reason := range sortedKeys(m.failures) {
+		fmt.Fprintf(&b, "snag_fetch_failures_total{reason=%q} %d\n", reason, m.failures[reason])
+	}
+
+	b.WriteString("# HELP snag_fetch_duration_seconds Time taken to navigate and load a page.\n")
+	b.WriteString("# TYPE snag_fetch_duration_seconds histogram\n")
+	for i, bound := range fetchDurationBuckets {
+		fmt.Fprintf(&b, "snag_fetch_duration_seconds_bucket{le=\"%g\"} %d\n", bound, m.durationCounts[i])
+	}
+	fmt.Fprintf(&b, "snag_fetch_duration_seconds_bucket{le=\"+Inf\"} %d\n", m.durationCount)
+	fmt.Fprintf(&b, "snag_fetch_duration_seconds_sum %g\n", m.durationSum)
+	fmt.Fprintf(&b, "snag_fetch_duration_seconds_count %d\n", m.durationCount)
+
+	b.WriteString("# HELP snag_converted_bytes_total Bytes of output produced, by format.\n")
+	b.WriteString("# TYPE snag_converted_bytes_total counter\n")
+	for _, format := range sortedKeys(m.convertedBytes) {
+		fmt.Fprintf(&b, "snag_converted_bytes_total{format=%q} %d\n", format, m.convertedBytes[format])
+	}
+
+	b.WriteString("# HELP snag_browser_launches_total Browsers launched by snag, including restarts.\n")
+	b.WriteString("# TYPE snag_browser_launches_total counter\n")
+	fmt.Fprintf(&b, "snag_browser_launches_total %d\n", m.browserLaunches)
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// ServeHTTP serves the metrics for a Prometheus scrape.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if _, err := m.WriteTo(w); err != nil {
+		logger.Debug("Failed to write metrics: %v", err)
+	}
+}
+
+func sortedKeys(m map[string]uint64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// startMetricsServer serves /metrics on addr in the background for the life
+// of the process. The listener is opened synchronously so a bad address or
+// busy port is reported before any work starts.
+func startMetricsServer(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		logger.Error("Failed to listen on %s: %v", addr, err)
+		logger.ErrorWithSuggestion(
+			"Choose a free host:port for the metrics endpoint",
+			"snag --metrics-listen :9090 --url-file urls.txt",
+		)
+		return fmt.Errorf("failed to start metrics server: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics)
+
+	go func() {
+		if err := http.Serve(ln, mux); err != nil {
+			logger.Debug("Metrics server stopped: %v", err)
+		}
+	}()
+
+	logger.Verbose("Serving metrics on http://%s/metrics", ln.Addr())
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetricsWriteTo(t *testing.T) {
+	m := NewMetrics()
+	m.ObserveFetch(300*time.Millisecond, nil)
+	m.ObserveFetch(3*time.Second, ErrPageLoadTimeout)
+	m.ObserveFetch(90*time.Second, fmt.Errorf("%w: boom", ErrNavigationFailed))
+	m.AddConvertedBytes(FormatMarkdown, 100)
+	m.AddConvertedBytes(FormatMarkdown, 50)
+	m.AddConvertedBytes(FormatPDF, 2048)
+	m.IncBrowserLaunches()
+
+	var b strings.Builder
+	if _, err := m.WriteTo(&b); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	out := b.String()
+
+	want := []string{
+		"# TYPE snag_fetches_total counter\n",
+		"snag_fetches_total 3\n",
+		`snag_fetch_failures_total{reason="navigation"} 1` + "\n",
+		`snag_fetch_failures_total{reason="timeout"} 1` + "\n",
+		`snag_fetch_duration_seconds_bucket{le="0.5"} 1` + "\n",
+		`snag_fetch_duration_seconds_bucket{le="5"} 2` + "\n",
+		`snag_fetch_duration_seconds_bucket{le="60"} 2` + "\n",
+		`snag_fetch_duration_seconds_bucket{le="+Inf"} 3` + "\n",
+		"snag_fetch_duration_seconds_count 3\n",
+		`snag_converted_bytes_total{format="md"} 150` + "\n",
+		`snag_converted_bytes_total{format="pdf"} 2048` + "\n",
+		"snag_browser_launches_total 1\n",
+	}
+	for _, w := range want {
+		if !strings.Contains(out, w) {
+			t.Errorf("metrics output missing %q\n%s", w, out)
+		}
+	}
+}
+
+func TestFailureReason(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{ErrPageLoadTimeout, "timeout"},
+		{context.DeadlineExceeded, "timeout"},
+		{ErrAuthRequired, "auth"},
+		{fmt.Errorf("%w: dns", ErrNavigationFailed), "navigation"},
+		{errors.New("something else"), "other"},
+	}
+
+	for _, tt := range tests {
+		if got := failureReason(tt.err); got != tt.want {
+			t.Errorf("failureReason(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
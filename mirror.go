@@ -0,0 +1,115 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// MirrorIndexName names the file for a URL that ends in a slash, as a web
+// server would serve index.html.
+const MirrorIndexName = "index"
+
+// mirrorNames are the paths --mirror-paths has given out in this run, so
+// two URLs that map to the same file do not overwrite each other.
+var (
+	mirrorMu    sync.Mutex
+	mirrorNames = make(map[string]bool)
+)
+
+// mirrorFilename maps a URL to a relative path that follows its structure:
+// https://example.com/docs/guide/install.html becomes
+// example.com/docs/guide/install.md. URLs ending in a slash are saved as
+// index, and a query string is added to the file's name.
+func mirrorFilename(urlStr, format string) string {
+	ext := GetFileExtension(format)
+
+	parsedURL, err := url.Parse(urlStr)
+	if err != nil {
+		return "page" + ext
+	}
+
+	host := parsedURL.Hostname()
+	if host == "" {
+		host = "localhost"
+	}
+	if port := parsedURL.Port(); port != "" {
+		host += "_" + port
+	}
+
+	segments := []string{host}
+	urlPath := parsedURL.Path
+	if urlPath == "" || strings.HasSuffix(urlPath, "/") {
+		urlPath += MirrorIndexName
+	}
+	for _, segment := range strings.Split(path.Clean("/"+urlPath), "/") {
+		segment = strings.TrimSpace(filenameUnsafe.ReplaceAllString(segment, "-"))
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+
+	name := segments[len(segments)-1]
+	if len(segments) == 1 {
+		name = MirrorIndexName
+		segments = append(segments, name)
+	}
+	switch strings.ToLower(path.Ext(name)) {
+	case ".html", ".htm", ".php", ".asp", ".aspx", ".jsp":
+		name = strings.TrimSuffix(name, path.Ext(name))
+	}
+	if query := SlugifyTitle(parsedURL.RawQuery, MaxSlugLength); query != "" {
+		name += "_" + query
+	}
+	segments[len(segments)-1] = name + ext
+
+	return filepath.Join(segments...)
+}
+
+// mirrorOutputPath returns where --mirror-paths saves urlStr in outputDir,
+// creating its directories. A file from an earlier run is replaced, so
+// runs can be compared; a second URL with the same path in this run gets
+// a numbered name instead.
+func mirrorOutputPath(outputDir, urlStr, format string) (string, error) {
+	filename := reserveMirrorName(mirrorFilename(urlStr, format))
+	outputPath := filepath.Join(outputDir, filename)
+
+	if tarOutput != nil {
+		return tarOutput.Reserve(outputPath), nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory for %s: %w", outputPath, err)
+	}
+	return outputPath, nil
+}
+
+func reserveMirrorName(filename string) string {
+	mirrorMu.Lock()
+	defer mirrorMu.Unlock()
+
+	if !mirrorNames[filename] {
+		mirrorNames[filename] = true
+		return filename
+	}
+
+	ext := filepath.Ext(filename)
+	base := strings.TrimSuffix(filename, ext)
+	for counter := 1; ; counter++ {
+		candidate := fmt.Sprintf("%s-%d%s", base, counter, ext)
+		if !mirrorNames[candidate] {
+			mirrorNames[candidate] = true
+			return candidate
+		}
+	}
+}
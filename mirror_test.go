@@ -0,0 +1,64 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMirrorFilename(t *testing.T) {
+	tests := []struct {
+		url      string
+		format   string
+		expected string
+	}{
+		{"https://example.com/docs/guide/install", FormatMarkdown, "example.com/docs/guide/install.md"},
+		{"https://example.com/docs/guide/install.html", FormatMarkdown, "example.com/docs/guide/install.md"},
+		{"https://example.com/docs/", FormatMarkdown, "example.com/docs/index.md"},
+		{"https://example.com", FormatPDF, "example.com/index.pdf"},
+		{"https://example.com:8443/api/v1.2/users", FormatText, "example.com_8443/api/v1.2/users.txt"},
+		{"https://example.com/search?q=snag&page=2", FormatMarkdown, "example.com/search_q-snag-page-2.md"},
+		{"https://example.com/a/../../etc/passwd", FormatMarkdown, "example.com/etc/passwd.md"},
+		{"https://example.com/what%3Fis:this", FormatMarkdown, "example.com/what-is-this.md"},
+		{"file:///home/user/page.html", FormatMarkdown, "localhost/home/user/page.md"},
+	}
+
+	for _, tt := range tests {
+		if got := mirrorFilename(tt.url, tt.format); got != filepath.FromSlash(tt.expected) {
+			t.Errorf("mirrorFilename(%q) = %q, want %q", tt.url, got, tt.expected)
+		}
+	}
+}
+
+func TestMirrorOutputPath(t *testing.T) {
+	dir := t.TempDir()
+	defer func() { mirrorNames = make(map[string]bool) }()
+
+	first, err := mirrorOutputPath(dir, "https://example.com/docs/intro.html", FormatMarkdown)
+	assertNoError(t, err)
+	if first != filepath.Join(dir, "example.com", "docs", "intro.md") {
+		t.Errorf("first path = %q", first)
+	}
+	if info, err := os.Stat(filepath.Dir(first)); err != nil || !info.IsDir() {
+		t.Errorf("expected %s to be created", filepath.Dir(first))
+	}
+
+	// The same page under another URL in the same run is not overwritten
+	second, err := mirrorOutputPath(dir, "https://example.com/docs/intro", FormatMarkdown)
+	assertNoError(t, err)
+	if second != filepath.Join(dir, "example.com", "docs", "intro-1.md") {
+		t.Errorf("second path = %q", second)
+	}
+}
+
+func TestCLI_MirrorPathsRequiresOutputDir(t *testing.T) {
+	_, stderr, err := runSnag("--mirror-paths", "https://example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "--mirror-paths requires --output-dir")
+}
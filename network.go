@@ -0,0 +1,359 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// ResponseCapture saves XHR and fetch response bodies whose URL matches a
+// glob pattern while a page loads.
+type ResponseCapture struct {
+	page      *rod.Page
+	pattern   *regexp.Regexp
+	dir       string
+	timestamp time.Time
+	cancel    context.CancelFunc
+	done      chan struct{}
+
+	mu      sync.Mutex
+	pending map[proto.NetworkRequestID]*proto.NetworkResponse
+	saved   []string
+}
+
+// compileURLPattern converts a glob pattern into an anchored regular
+// expression. '*' matches any run of characters (including '/') and '?'
+// matches a single character.
+func compileURLPattern(pattern string) (*regexp.Regexp, error) {
+	quoted := regexp.QuoteMeta(pattern)
+	quoted = strings.ReplaceAll(quoted, `\*`, `.*`)
+	quoted = strings.ReplaceAll(quoted, `\?`, `.`)
+	return regexp.Compile("^" + quoted + "$")
+}
+
+// StartResponseCapture begins listening for network responses on page.
+// Call Stop once the page has finished loading.
+func StartResponseCapture(page *rod.Page, pattern, dir string) (*ResponseCapture, error) {
+	re, err := compileURLPattern(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid capture pattern '%s': %w", pattern, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rc := &ResponseCapture{
+		page:      page,
+		pattern:   re,
+		dir:       dir,
+		timestamp: time.Now(),
+		cancel:    cancel,
+		done:      make(chan struct{}),
+		pending:   make(map[proto.NetworkRequestID]*proto.NetworkResponse),
+	}
+
+	wait := page.Context(ctx).EachEvent(
+		func(e *proto.NetworkResponseReceived) {
+			if e.Type != proto.NetworkResourceTypeXHR && e.Type != proto.NetworkResourceTypeFetch {
+				return
+			}
+			if !rc.pattern.MatchString(e.Response.URL) {
+				return
+			}
+			rc.mu.Lock()
+			rc.pending[e.RequestID] = e.Response
+			rc.mu.Unlock()
+		},
+		func(e *proto.NetworkLoadingFinished) {
+			rc.mu.Lock()
+			resp, ok := rc.pending[e.RequestID]
+			delete(rc.pending, e.RequestID)
+			rc.mu.Unlock()
+			if ok {
+				rc.save(e.RequestID, resp)
+			}
+		},
+	)
+
+	go func() {
+		defer close(rc.done)
+		wait()
+	}()
+
+	logger.Verbose("Capturing responses matching: %s", pattern)
+	return rc, nil
+}
+
+func (rc *ResponseCapture) save(id proto.NetworkRequestID, resp *proto.NetworkResponse) {
+	body, err := proto.NetworkGetResponseBody{RequestID: id}.Call(rc.page)
+	if err != nil {
+		logger.Warning("Failed to read response body for %s: %v", resp.URL, err)
+		return
+	}
+
+	data := []byte(body.Body)
+	if body.Base64Encoded {
+		data, err = base64.StdEncoding.DecodeString(body.Body)
+		if err != nil {
+			logger.Warning("Failed to decode response body for %s: %v", resp.URL, err)
+			return
+		}
+	}
+
+	filename := responseFilename(resp.URL, resp.MIMEType, rc.timestamp)
+	finalFilename, err := ResolveConflict(rc.dir, filename)
+	if err != nil {
+		logger.Warning("Failed to resolve filename for %s: %v", resp.URL, err)
+		return
+	}
+	path := filepath.Join(rc.dir, finalFilename)
+
+	if err := os.WriteFile(path, data, DefaultFileMode); err != nil {
+		logger.Warning("Failed to save response body for %s: %v", resp.URL, err)
+		return
+	}
+
+	logger.Verbose("Captured response: %s -> %s", resp.URL, path)
+
+	rc.mu.Lock()
+	rc.saved = append(rc.saved, path)
+	rc.mu.Unlock()
+}
+
+// Stop ends the capture and returns the paths of the saved bodies.
+func (rc *ResponseCapture) Stop() []string {
+	rc.cancel()
+	<-rc.done
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if len(rc.saved) > 0 {
+		logger.Success("Captured %d response%s", len(rc.saved), plural(len(rc.saved)))
+	} else {
+		logger.Info("No responses matched the capture pattern")
+	}
+
+	return rc.saved
+}
+
+// responseFilename builds a filename for a captured response body from the
+// request URL path and the response MIME type.
+func responseFilename(urlStr, mimeType string, timestamp time.Time) string {
+	slug := ""
+	if parsed, err := url.Parse(urlStr); err == nil {
+		slug = SlugifyTitle(parsed.Host+" "+parsed.Path, MaxSlugLength)
+	}
+	if slug == "" {
+		slug = "response"
+	}
+
+	return fmt.Sprintf("%s-%s%s", timestamp.Format("2006-01-02-150405"), slug, mimeExtension(mimeType))
+}
+
+func mimeExtension(mimeType string) string {
+	mimeType = strings.ToLower(mimeType)
+	switch {
+	case strings.Contains(mimeType, "json"):
+		return ".json"
+	case strings.Contains(mimeType, "html"):
+		return ".html"
+	case strings.Contains(mimeType, "xml"):
+		return ".xml"
+	case strings.Contains(mimeType, "javascript"):
+		return ".js"
+	case strings.HasPrefix(mimeType, "text/"):
+		return ".txt"
+	default:
+		return ".bin"
+	}
+}
+
+// recordedRequest is an XHR or fetch request observed during page load.
+type recordedRequest struct {
+	Method  string
+	URL     string
+	Headers map[string]string
+	Body    string
+}
+
+// CurlScriptFileMode is the permission of scripts written by --emit-curl,
+// which hold the page's cookies and auth headers.
+const CurlScriptFileMode = 0600
+
+// RequestRecorder records the XHR and fetch requests a page makes while it
+// loads so they can be replayed outside the browser.
+type RequestRecorder struct {
+	page   *rod.Page
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu       sync.Mutex
+	seen     map[string]bool
+	requests []recordedRequest
+}
+
+// StartRequestRecorder begins recording requests on page. Call Stop once the
+// page has finished loading.
+func StartRequestRecorder(page *rod.Page) *RequestRecorder {
+	ctx, cancel := context.WithCancel(context.Background())
+	rr := &RequestRecorder{
+		page:   page,
+		cancel: cancel,
+		done:   make(chan struct{}),
+		seen:   make(map[string]bool),
+	}
+
+	wait := page.Context(ctx).EachEvent(func(e *proto.NetworkRequestWillBeSent) {
+		if e.Type != proto.NetworkResourceTypeXHR && e.Type != proto.NetworkResourceTypeFetch {
+			return
+		}
+
+		key := e.Request.Method + " " + e.Request.URL
+		headers := make(map[string]string, len(e.Request.Headers))
+		for name, value := range e.Request.Headers {
+			headers[name] = value.Str()
+		}
+
+		rr.mu.Lock()
+		defer rr.mu.Unlock()
+		if rr.seen[key] {
+			return
+		}
+		rr.seen[key] = true
+		rr.requests = append(rr.requests, recordedRequest{
+			Method:  e.Request.Method,
+			URL:     e.Request.URL,
+			Headers: headers,
+			Body:    e.Request.PostData,
+		})
+	})
+
+	go func() {
+		defer close(rr.done)
+		wait()
+	}()
+
+	logger.Verbose("Recording XHR/fetch requests...")
+	return rr
+}
+
+// Stop ends recording and returns a curl command for each unique request.
+// Cookies are read from the browser because Chromium adds them below the
+// layer that reports request headers.
+func (rr *RequestRecorder) Stop() []string {
+	rr.cancel()
+	<-rr.done
+
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	commands := make([]string, 0, len(rr.requests))
+	for _, req := range rr.requests {
+		cookies, err := rr.page.Cookies([]string{req.URL})
+		if err != nil {
+			logger.Debug("Failed to get cookies for %s: %v", req.URL, err)
+		}
+
+		pairs := make([]string, 0, len(cookies))
+		for _, c := range cookies {
+			pairs = append(pairs, c.Name+"="+c.Value)
+		}
+
+		commands = append(commands, buildCurlCommand(req, strings.Join(pairs, "; ")))
+	}
+
+	logger.Verbose("Recorded %d API request%s", len(commands), plural(len(commands)))
+	return commands
+}
+
+// buildCurlCommand renders a request as a copy-pasteable curl command.
+func buildCurlCommand(req recordedRequest, cookie string) string {
+	var b strings.Builder
+
+	b.WriteString("curl")
+	if req.Method != "" && req.Method != "GET" {
+		b.WriteString(" -X " + shellQuote(req.Method))
+	}
+	b.WriteString(" " + shellQuote(req.URL))
+
+	names := make([]string, 0, len(req.Headers))
+	for name := range req.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		b.WriteString(" \\\n  -H " + shellQuote(name+": "+req.Headers[name]))
+	}
+
+	if cookie != "" {
+		b.WriteString(" \\\n  -H " + shellQuote("Cookie: "+cookie))
+	}
+
+	if req.Body != "" {
+		b.WriteString(" \\\n  --data-raw " + shellQuote(req.Body))
+	}
+
+	return b.String()
+}
+
+// shellQuote wraps s in single quotes for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// writeCurlScript writes the curl commands recorded for pageURL to path. The
+// file is truncated when truncate is true, otherwise commands are appended so
+// batch runs produce a single script.
+func writeCurlScript(path, pageURL string, commands []string, truncate bool) error {
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if truncate {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	}
+
+	f, err := os.OpenFile(path, flags, CurlScriptFileMode)
+	if err != nil {
+		return fmt.Errorf("failed to open curl script %s: %w", path, err)
+	}
+	defer f.Close()
+
+	// OpenFile only sets the mode on create, and the script holds cookies
+	if err := f.Chmod(CurlScriptFileMode); err != nil {
+		return fmt.Errorf("failed to set mode of curl script %s: %w", path, err)
+	}
+
+	var b strings.Builder
+	if truncate {
+		b.WriteString("#!/bin/sh\n")
+	}
+	fmt.Fprintf(&b, "\n# API requests made by %s\n", pageURL)
+	if len(commands) == 0 {
+		b.WriteString("# (none)\n")
+	}
+	for _, cmd := range commands {
+		b.WriteString("\n" + cmd + "\n")
+	}
+
+	if _, err := f.WriteString(b.String()); err != nil {
+		return fmt.Errorf("failed to write curl script %s: %w", path, err)
+	}
+
+	logger.Success("Saved %d curl command%s to %s", len(commands), plural(len(commands)), path)
+	return nil
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompileURLPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		url     string
+		want    bool
+	}{
+		{"*/api/*", "https://example.com/api/users", true},
+		{"*/api/*", "https://example.com/v2/api/users?page=1", true},
+		{"*/api/*", "https://example.com/app.js", false},
+		{"https://example.com/*.json", "https://example.com/data/items.json", true},
+		{"https://example.com/*.json", "https://other.com/items.json", false},
+		{"*/item?", "https://example.com/item1", true},
+		{"*/item?", "https://example.com/item12", false},
+		{"*(group)*", "https://example.com/(group)/x", true},
+	}
+
+	for _, tt := range tests {
+		re, err := compileURLPattern(tt.pattern)
+		if err != nil {
+			t.Fatalf("compileURLPattern(%q) failed: %v", tt.pattern, err)
+		}
+		if got := re.MatchString(tt.url); got != tt.want {
+			t.Errorf("pattern %q vs %q = %v, want %v", tt.pattern, tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestResponseFilename(t *testing.T) {
+	ts := time.Date(2025, 10, 22, 14, 20, 33, 0, time.UTC)
+
+	tests := []struct {
+		url  string
+		mime string
+		want string
+	}{
+		{"https://example.com/api/users?page=2", "application/json", "2025-10-22-142033-example-com-api-users.json"},
+		{"https://example.com/feed", "application/rss+xml", "2025-10-22-142033-example-com-feed.xml"},
+		{"https://example.com/", "text/plain", "2025-10-22-142033-example-com.txt"},
+		{"::bad", "application/octet-stream", "2025-10-22-142033-response.bin"},
+	}
+
+	for _, tt := range tests {
+		if got := responseFilename(tt.url, tt.mime, ts); got != tt.want {
+			t.Errorf("responseFilename(%q, %q) = %q, want %q", tt.url, tt.mime, got, tt.want)
+		}
+	}
+}
+
+func TestBuildCurlCommand(t *testing.T) {
+	req := recordedRequest{
+		Method: "POST",
+		URL:    "https://example.com/graphql",
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+			"Accept":       "*/*",
+		},
+		Body: `{"query":"{ user(name: \"o'neil\") { id } }"}`,
+	}
+
+	got := buildCurlCommand(req, "session=abc")
+	want := "curl -X 'POST' 'https://example.com/graphql' \\\n" +
+		"  -H 'Accept: */*' \\\n" +
+		"  -H 'Content-Type: application/json' \\\n" +
+		"  -H 'Cookie: session=abc' \\\n" +
+		`  --data-raw '{"query":"{ user(name: \"o'\''neil\") { id } }"}'`
+
+	if got != want {
+		t.Errorf("buildCurlCommand() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestBuildCurlCommand_Get(t *testing.T) {
+	got := buildCurlCommand(recordedRequest{Method: "GET", URL: "https://example.com/api?a=1&b=2"}, "")
+	want := "curl 'https://example.com/api?a=1&b=2'"
+	if got != want {
+		t.Errorf("buildCurlCommand() = %q, want %q", got, want)
+	}
+}
+
+func TestWriteCurlScript(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.sh")
+	// A script left by an earlier run with a looser mode is tightened
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeCurlScript(path, "https://example.com/", []string{"curl 'https://example.com/api/a'"}, true); err != nil {
+		t.Fatalf("writeCurlScript() error: %v", err)
+	}
+	if err := writeCurlScript(path, "https://example.com/two", []string{"curl 'https://example.com/api/b'"}, false); err != nil {
+		t.Fatalf("writeCurlScript() append error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"#!/bin/sh", "api/a", "api/b"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("script missing %q:\n%s", want, data)
+		}
+	}
+	if runtime.GOOS != "windows" {
+		if info, err := os.Stat(path); err != nil || info.Mode().Perm() != CurlScriptFileMode {
+			t.Errorf("expected mode %o, got %v (%v)", CurlScriptFileMode, info.Mode().Perm(), err)
+		}
+	}
+}
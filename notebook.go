@@ -0,0 +1,61 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// NotebookSplitLevel splits notebooks before every heading, so each
+// section gets a cell of its own.
+const NotebookSplitLevel = 6
+
+// Notebook is a Jupyter notebook in nbformat 4.5.
+type Notebook struct {
+	Cells         []NotebookCell `json:"cells"`
+	Metadata      map[string]any `json:"metadata"`
+	NBFormat      int            `json:"nbformat"`
+	NBFormatMinor int            `json:"nbformat_minor"`
+}
+
+// NotebookCell is one notebook cell. Source holds the cell's lines, each
+// with its trailing newline except the last, as Jupyter writes them.
+type NotebookCell struct {
+	ID       string         `json:"id"`
+	CellType string         `json:"cell_type"`
+	Metadata map[string]any `json:"metadata"`
+	Source   []string       `json:"source"`
+}
+
+// markdownToNotebook returns markdown as notebook JSON with one Markdown
+// cell per heading section.
+func markdownToNotebook(markdown string) (string, error) {
+	nb := Notebook{
+		Cells:         []NotebookCell{},
+		Metadata:      map[string]any{},
+		NBFormat:      4,
+		NBFormatMinor: 5,
+	}
+
+	for i, section := range splitMarkdown(markdown, NotebookSplitLevel) {
+		source := strings.SplitAfter(strings.TrimRight(section.Content, "\n"), "\n")
+		nb.Cells = append(nb.Cells, NotebookCell{
+			ID:       fmt.Sprintf("cell-%d", i+1),
+			CellType: "markdown",
+			Metadata: map[string]any{},
+			Source:   source,
+		})
+	}
+
+	data, err := json.MarshalIndent(nb, "", " ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestMarkdownToNotebook(t *testing.T) {
+	markdown := "Intro text\n\n# Title\n\nBody line one\nline two\n\n## Section\n\n```\n# not a heading\n```\n"
+
+	data, err := markdownToNotebook(markdown)
+	if err != nil {
+		t.Fatalf("markdownToNotebook failed: %v", err)
+	}
+
+	var nb Notebook
+	if err := json.Unmarshal([]byte(data), &nb); err != nil {
+		t.Fatalf("notebook is not valid JSON: %v", err)
+	}
+	if nb.NBFormat != 4 || nb.NBFormatMinor != 5 {
+		t.Errorf("nbformat = %d.%d, want 4.5", nb.NBFormat, nb.NBFormatMinor)
+	}
+
+	want := [][]string{
+		{"Intro text"},
+		{"# Title\n", "\n", "Body line one\n", "line two"},
+		{"## Section\n", "\n", "```\n", "# not a heading\n", "```"},
+	}
+	if len(nb.Cells) != len(want) {
+		t.Fatalf("got %d cells, want %d", len(nb.Cells), len(want))
+	}
+	for i, cell := range nb.Cells {
+		if cell.CellType != "markdown" || cell.ID == "" {
+			t.Errorf("cell %d: type %q id %q", i, cell.CellType, cell.ID)
+		}
+		if !slices.Equal(cell.Source, want[i]) {
+			t.Errorf("cell %d source = %q, want %q", i, cell.Source, want[i])
+		}
+	}
+}
+
+func TestMarkdownToNotebook_Empty(t *testing.T) {
+	data, err := markdownToNotebook("")
+	if err != nil {
+		t.Fatalf("markdownToNotebook failed: %v", err)
+	}
+	var nb Notebook
+	if err := json.Unmarshal([]byte(data), &nb); err != nil {
+		t.Fatalf("notebook is not valid JSON: %v", err)
+	}
+	if nb.Cells == nil || len(nb.Cells) != 0 {
+		t.Errorf("cells = %v, want empty list", nb.Cells)
+	}
+}
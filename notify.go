@@ -0,0 +1,77 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// NotifyTitle is the title of desktop notifications sent by --notify-desktop.
+const NotifyTitle = "snag"
+
+// desktopNotifyCommand returns the command and arguments that show a native
+// notification on goos, or nil if the platform has no supported notifier.
+func desktopNotifyCommand(goos, title, message string) []string {
+	switch goos {
+	case "darwin":
+		quote := func(s string) string {
+			return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+		}
+		return []string{"osascript", "-e",
+			fmt.Sprintf("display notification %s with title %s", quote(message), quote(title))}
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return []string{"notify-send", "--app-name=snag", title, message}
+	case "windows":
+		quote := func(s string) string {
+			return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+		}
+		script := strings.Join([]string{
+			"Add-Type -AssemblyName System.Windows.Forms",
+			"$n = New-Object System.Windows.Forms.NotifyIcon",
+			"$n.Icon = [System.Drawing.SystemIcons]::Information",
+			"$n.Visible = $true",
+			fmt.Sprintf("$n.ShowBalloonTip(10000, %s, %s, 'Info')", quote(title), quote(message)),
+			"Start-Sleep -Seconds 10",
+			"$n.Dispose()",
+		}, "; ")
+		return []string{"powershell", "-NoProfile", "-NonInteractive", "-Command", script}
+	}
+	return nil
+}
+
+// notifyDesktop shows message as a native desktop notification. Failures
+// are logged as warnings; a missing notifier never fails the run.
+func notifyDesktop(message string) {
+	args := desktopNotifyCommand(runtime.GOOS, NotifyTitle, message)
+	if args == nil {
+		logger.Warning("Desktop notifications are not supported on %s", runtime.GOOS)
+		return
+	}
+
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		logger.Warning("Desktop notification skipped: %s not found in PATH", args[0])
+		return
+	}
+
+	logger.Debug("Sending desktop notification: %s", message)
+	cmd := exec.Command(path, args[1:]...)
+	if runtime.GOOS == "windows" {
+		// The balloon needs the process to stay alive while it shows
+		if err := cmd.Start(); err != nil {
+			logger.Warning("Failed to send desktop notification: %v", err)
+		}
+		return
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		logger.Debug("Notifier output: %s", string(output))
+		logger.Warning("Failed to send desktop notification: %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDesktopNotifyCommand(t *testing.T) {
+	mac := desktopNotifyCommand("darwin", "snag", `Saved "docs"`)
+	if len(mac) != 3 || mac[0] != "osascript" {
+		t.Fatalf("darwin command = %v", mac)
+	}
+	if want := `display notification "Saved \"docs\"" with title "snag"`; mac[2] != want {
+		t.Errorf("darwin script = %s, want %s", mac[2], want)
+	}
+
+	linux := desktopNotifyCommand("linux", "snag", "done")
+	if strings.Join(linux, " ") != "notify-send --app-name=snag snag done" {
+		t.Errorf("linux command = %v", linux)
+	}
+
+	windows := desktopNotifyCommand("windows", "snag", "it's done")
+	if windows[0] != "powershell" || !strings.Contains(windows[len(windows)-1], "'it''s done'") {
+		t.Errorf("windows command = %v", windows)
+	}
+
+	if got := desktopNotifyCommand("plan9", "snag", "done"); got != nil {
+		t.Errorf("plan9 command = %v, want nil", got)
+	}
+}
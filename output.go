@@ -13,6 +13,8 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync/atomic"
+	"text/template"
 	"time"
 )
 
@@ -60,10 +62,20 @@ func GetFileExtension(format string) string {
 		return ".html"
 	case FormatText:
 		return ".txt"
+	case FormatReader:
+		return ".txt"
+	case FormatNotebook:
+		return ".ipynb"
+	case FormatOrg:
+		return ".org"
+	case FormatRST:
+		return ".rst"
 	case FormatPDF:
 		return ".pdf"
 	case FormatPNG:
 		return ".png"
+	case FormatJSON:
+		return ".json"
 	default:
 		return ".md"
 	}
@@ -72,7 +84,7 @@ func GetFileExtension(format string) string {
 func GenerateFilename(title string, format string, timestamp time.Time, urlStr string) string {
 	timePrefix := timestamp.Format("2006-01-02-150405")
 
-	titleSlug := SlugifyTitle(title, MaxSlugLength)
+	titleSlug := TitleSlug(title, MaxSlugLength)
 	logger.Debug("Title '%s' slugified to '%s'", title, titleSlug)
 
 	if titleSlug == "" {
@@ -82,12 +94,93 @@ func GenerateFilename(title string, format string, timestamp time.Time, urlStr s
 
 	ext := GetFileExtension(format)
 
+	if filenameTemplate != nil {
+		data := newFilenameData(title, titleSlug, format, timestamp, urlStr)
+		filename, err := renderFilename(filenameTemplate, data)
+		if err == nil {
+			logger.Debug("Generated filename from template: %s", filename)
+			return filename
+		}
+		logger.Warning("--filename-template: %v, using the default name", err)
+	}
+
 	filename := fmt.Sprintf("%s-%s%s", timePrefix, titleSlug, ext)
 	logger.Debug("Generated filename: %s", filename)
 
 	return filename
 }
 
+// FilenameData is what a --filename-template is executed against.
+type FilenameData struct {
+	Title     string // Page title as found
+	Slug      string // Title slug, or the host slug for an untitled page
+	Host      string // URL host without the port, e.g. docs.example.com
+	Path      string // URL path slug, e.g. guide-install, or "index"
+	Index     int    // 1 for the first file named in this run, then 2, 3, ...
+	Date      string // 2025-10-22
+	Time      string // 142033
+	Timestamp string // 2025-10-22-142033
+	Format    string // md, html, pdf, ...
+	Ext       string // Extension without the dot, e.g. md or txt
+}
+
+// filenameTemplate is the parsed --filename-template, or nil for the
+// default timestamp-and-slug names.
+var filenameTemplate *template.Template
+
+// filenameIndex counts the files named from filenameTemplate in this run.
+var filenameIndex atomic.Int64
+
+// filenameUnsafe matches characters that are not allowed in filenames on
+// some system, other than the / that separates directories.
+var filenameUnsafe = regexp.MustCompile(`[<>:"\\|?*\x00-\x1f]+`)
+
+func newFilenameData(title, slug, format string, timestamp time.Time, urlStr string) FilenameData {
+	data := FilenameData{
+		Title:     title,
+		Slug:      slug,
+		Path:      "index",
+		Index:     int(filenameIndex.Add(1)),
+		Date:      timestamp.Format("2006-01-02"),
+		Time:      timestamp.Format("150405"),
+		Timestamp: timestamp.Format("2006-01-02-150405"),
+		Format:    format,
+		Ext:       strings.TrimPrefix(GetFileExtension(format), "."),
+	}
+	if parsedURL, err := url.Parse(urlStr); err == nil {
+		data.Host = parsedURL.Hostname()
+		if path := SlugifyTitle(parsedURL.Path, MaxSlugLength); path != "" {
+			data.Path = path
+		}
+	}
+	return data
+}
+
+// renderFilename executes tmpl for data. Characters that are unsafe in
+// filenames are replaced with hyphens, and / creates subdirectories, which
+// must stay inside the output directory.
+func renderFilename(tmpl *template.Template, data FilenameData) (string, error) {
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	raw := buf.String()
+	segments := strings.Split(raw, "/")
+	for i, segment := range segments {
+		segments[i] = strings.TrimSpace(filenameUnsafe.ReplaceAllString(segment, "-"))
+	}
+	filename := filepath.Clean(filepath.Join(segments...))
+
+	if filename == "." || strings.HasSuffix(raw, "/") {
+		return "", fmt.Errorf("template gave no filename for %s", data.Slug)
+	}
+	if strings.HasPrefix(raw, "/") || !filepath.IsLocal(filename) {
+		return "", fmt.Errorf("filename %s is outside the output directory", filename)
+	}
+	return filename, nil
+}
+
 func ResolveConflict(dir, filename string) (string, error) {
 	fullPath := filepath.Join(dir, filename)
 	logger.Debug("Checking for conflicts: %s", fullPath)
@@ -13,6 +13,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"text/template"
 	"time"
 )
 
@@ -421,3 +422,49 @@ func TestResolveConflict_HighCount(t *testing.T) {
 		t.Errorf("expected %q, got %q", expected, filename)
 	}
 }
+
+// TestGenerateFilename_Template tests --filename-template naming
+func TestGenerateFilename_Template(t *testing.T) {
+	defer func() { filenameTemplate = nil }()
+	filenameIndex.Store(0)
+	timestamp := time.Date(2025, 10, 21, 14, 30, 45, 0, time.UTC)
+
+	tests := []struct {
+		template string
+		title    string
+		url      string
+		expected string
+	}{
+		{"{{.Date}}-{{.Host}}-{{.Slug}}.{{.Ext}}", "Example Domain", "https://example.com:8443/", "2025-10-21-example.com-example-domain.md"},
+		{"{{.Host}}/{{.Path}}.{{.Ext}}", "Install", "https://docs.example.com/guide/install", filepath.Join("docs.example.com", "guide-install.md")},
+		{"{{.Host}}/{{.Path}}-{{.Time}}.{{.Ext}}", "", "https://example.com", filepath.Join("example.com", "index-143045.md")},
+		{"{{printf \"%03d\" .Index}}-{{.Title}}.{{.Ext}}", "Q&A: What? <Why>", "https://example.com", "004-Q&A- What- -Why-.md"},
+	}
+
+	for _, tt := range tests {
+		filenameTemplate = template.Must(template.New("filename").Parse(tt.template))
+		if got := GenerateFilename(tt.title, FormatMarkdown, timestamp, tt.url); got != tt.expected {
+			t.Errorf("template %q: expected %q, got %q", tt.template, tt.expected, got)
+		}
+	}
+}
+
+// TestRenderFilename_Invalid tests template output that cannot name a file
+func TestRenderFilename_Invalid(t *testing.T) {
+	data := FilenameData{Slug: "page", Host: "example.com", Ext: "md"}
+
+	for _, text := range []string{"../{{.Slug}}.{{.Ext}}", "/tmp/{{.Slug}}", "{{.Host}}/", "  "} {
+		tmpl := template.Must(template.New("filename").Parse(text))
+		if name, err := renderFilename(tmpl, data); err == nil {
+			t.Errorf("template %q: expected an error, got %q", text, name)
+		}
+	}
+
+	// Falls back to the default name
+	defer func() { filenameTemplate = nil }()
+	filenameTemplate = template.Must(template.New("filename").Parse("../{{.Slug}}.{{.Ext}}"))
+	got := GenerateFilename("Example", FormatMarkdown, time.Date(2025, 10, 21, 14, 30, 45, 0, time.UTC), "https://example.com")
+	if got != "2025-10-21-143045-example.md" {
+		t.Errorf("expected the default name, got %q", got)
+	}
+}
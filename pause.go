@@ -0,0 +1,66 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// PauseControl pauses a batch between URLs. The URL being fetched when it
+// is paused finishes first; the batch then waits until it is resumed.
+type PauseControl struct {
+	mu      sync.Mutex
+	paused  bool
+	resumed chan struct{}
+}
+
+// batchPause is paused and resumed by SIGUSR1 and SIGUSR2 where the
+// platform has them.
+var batchPause = &PauseControl{}
+
+// Pause pauses the batch. It reports false if it was already paused.
+func (p *PauseControl) Pause() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.paused {
+		return false
+	}
+	p.paused = true
+	p.resumed = make(chan struct{})
+	return true
+}
+
+// Resume resumes the batch. It reports false if it was not paused.
+func (p *PauseControl) Resume() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.paused {
+		return false
+	}
+	p.paused = false
+	close(p.resumed)
+	return true
+}
+
+// Wait blocks while the batch is paused, before the URL described by
+// next, and returns how long it waited.
+func (p *PauseControl) Wait(next string) time.Duration {
+	p.mu.Lock()
+	paused, resumed := p.paused, p.resumed
+	p.mu.Unlock()
+	if !paused {
+		return 0
+	}
+
+	logger.Warning("Paused before %s (%s)", next, resumeHint)
+	start := time.Now()
+	<-resumed
+	waited := time.Since(start).Round(time.Second)
+	logger.Info("Resumed after %s", waited)
+	return waited
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPauseControl(t *testing.T) {
+	logger = NewLogger(LevelQuiet)
+	p := &PauseControl{}
+
+	if p.Wait("[1/2] https://example.com") != 0 {
+		t.Error("Wait() should not block when not paused")
+	}
+	if p.Resume() {
+		t.Error("Resume() should report false when not paused")
+	}
+	if !p.Pause() || p.Pause() {
+		t.Error("Pause() should only report true the first time")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		p.Wait("[2/2] https://example.com/next")
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("Wait() returned while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if !p.Resume() {
+		t.Error("Resume() should report true when paused")
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Wait() did not return after Resume()")
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+var resumeHint = fmt.Sprintf("kill -USR2 %d to resume", os.Getpid())
+
+// watchPauseSignals pauses batchPause on SIGUSR1 and resumes it on
+// SIGUSR2. Without a batch running the signals are ignored, rather than
+// ending snag as they would by default.
+func watchPauseSignals() {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	go func() {
+		for sig := range sigChan {
+			if sig == syscall.SIGUSR1 {
+				if batchPause.Pause() && logger != nil {
+					logger.Info("Pausing after the current URL (%s)", resumeHint)
+				}
+			} else if batchPause.Resume() && logger != nil {
+				logger.Info("Resuming...")
+			}
+		}
+	}()
+}
@@ -0,0 +1,15 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build windows
+
+package main
+
+const resumeHint = "resume is not supported on Windows"
+
+// watchPauseSignals does nothing on Windows, which has no SIGUSR1 or
+// SIGUSR2.
+func watchPauseSignals() {}
@@ -0,0 +1,475 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"html"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// pdfSelectorScript hides everything on the page but the first element
+// matching selector and its ancestors, so only that element prints. It
+// returns false when nothing matches.
+const pdfSelectorScript = `(selector) => {
+	const target = document.querySelector(selector);
+	if (!target) {
+		return false;
+	}
+
+	const style = document.createElement('style');
+	style.id = 'snag-pdf-hide';
+	style.textContent = '.snag-pdf-hidden { display: none !important; }';
+	document.head.appendChild(style);
+
+	for (let el = target; el.parentElement; el = el.parentElement) {
+		for (const sibling of el.parentElement.children) {
+			if (sibling !== el && sibling.tagName !== 'HEAD') {
+				sibling.classList.add('snag-pdf-hidden');
+			}
+		}
+	}
+	return true;
+}`
+
+// pdfRestoreScript undoes the hiding done by pdfSelectorScript,
+// pdfSectionScript, and fragmentSectionScript.
+const pdfRestoreScript = `() => {
+	document.getElementById('snag-pdf-hide')?.remove();
+	for (const el of document.querySelectorAll('.snag-pdf-hidden')) {
+		el.classList.remove('snag-pdf-hidden');
+	}
+}`
+
+// PDFStampMarginInches reserves room at the bottom of each page for the
+// --pdf-stamp footer so it never overlaps page content.
+const PDFStampMarginInches = 0.6
+
+// Chromium cannot emit PDF/A directly, so archival output is produced by
+// re-distilling the printed PDF through Ghostscript, which embeds all fonts,
+// writes XMP metadata with the PDF/A identification, and never encrypts.
+// The sRGB OutputIntent PDF/A requires comes from pdfaDefScript.
+var ghostscriptPDFAArgs = []string{
+	"-dPDFA=2",
+	"-dBATCH",
+	"-dNOPAUSE",
+	"-dNOOUTERSAVE",
+	"-dQUIET",
+	"-dEmbedAllFonts=true",
+	"-dPDFACompatibilityPolicy=1",
+	"-sColorConversionStrategy=RGB",
+	"-sDEVICE=pdfwrite",
+}
+
+// pdfaDefScript is the PostScript prefix, after Ghostscript's PDFA_def.ps,
+// that embeds the ICC profile at %s as the document's OutputIntent.
+const pdfaDefScript = `%%!
+[/_objdef {icc_PDFA} /type /stream /OBJ pdfmark
+[{icc_PDFA} << /N 3 >> /PUT pdfmark
+[{icc_PDFA} (%s) (r) file /PUT pdfmark
+[/_objdef {OutputIntent_PDFA} /type /dict /OBJ pdfmark
+[{OutputIntent_PDFA} <<
+  /Type /OutputIntent
+  /S /GTS_PDFA1
+  /DestOutputProfile {icc_PDFA}
+  /OutputConditionIdentifier (sRGB)
+  /Info (sRGB IEC61966-2.1)
+>> /PUT pdfmark
+[{Catalog} << /OutputIntents [ {OutputIntent_PDFA} ] >> /PUT pdfmark
+`
+
+// psString escapes s for a PostScript string literal. Paths use forward
+// slashes, which Ghostscript accepts on every platform, so backslashes
+// need no escaping.
+func psString(s string) string {
+	return strings.NewReplacer(`(`, `\(`, `)`, `\)`).Replace(s)
+}
+
+// srgbICCProfile returns an ICC v2 display profile for sRGB: the D50
+// adapted primaries and the sRGB tone curve, as in the profile HP and
+// Microsoft published. Building it here saves depending on where, or
+// whether, the system keeps one.
+func srgbICCProfile() []byte {
+	s15 := func(v float64) uint32 { return uint32(int32(math.Round(v * 65536))) }
+	xyz := func(x, y, z float64) []byte {
+		b := []byte("XYZ \x00\x00\x00\x00")
+		for _, v := range []float64{x, y, z} {
+			b = binary.BigEndian.AppendUint32(b, s15(v))
+		}
+		return b
+	}
+
+	desc := []byte("desc\x00\x00\x00\x00")
+	desc = binary.BigEndian.AppendUint32(desc, 18)
+	desc = append(desc, "sRGB IEC61966-2.1\x00"...)
+	desc = append(desc, make([]byte, 4+4+2+1+67)...)
+
+	const points = 1024
+	trc := []byte("curv\x00\x00\x00\x00")
+	trc = binary.BigEndian.AppendUint32(trc, points)
+	for i := range points {
+		v := float64(i) / (points - 1)
+		if v <= 0.04045 {
+			v /= 12.92
+		} else {
+			v = math.Pow((v+0.055)/1.055, 2.4)
+		}
+		trc = binary.BigEndian.AppendUint16(trc, uint16(math.Round(v*65535)))
+	}
+
+	tags := []struct {
+		sig  string
+		data []byte
+	}{
+		{"desc", desc},
+		{"cprt", []byte("text\x00\x00\x00\x00No copyright, use freely\x00")},
+		{"wtpt", xyz(0.9505, 1.0, 1.0891)},
+		{"rXYZ", xyz(0.4361, 0.2225, 0.0139)},
+		{"gXYZ", xyz(0.3851, 0.7169, 0.0971)},
+		{"bXYZ", xyz(0.1431, 0.0606, 0.7141)},
+		{"rTRC", trc},
+		{"gTRC", trc},
+		{"bTRC", trc},
+	}
+
+	table := binary.BigEndian.AppendUint32(nil, uint32(len(tags)))
+	var body []byte
+	offset := 128 + 4 + 12*len(tags)
+	for _, tag := range tags {
+		for len(body)%4 != 0 {
+			body = append(body, 0)
+		}
+		table = append(table, tag.sig...)
+		table = binary.BigEndian.AppendUint32(table, uint32(offset+len(body)))
+		table = binary.BigEndian.AppendUint32(table, uint32(len(tag.data)))
+		body = append(body, tag.data...)
+	}
+
+	header := make([]byte, 128)
+	binary.BigEndian.PutUint32(header[0:], uint32(len(header)+len(table)+len(body)))
+	binary.BigEndian.PutUint32(header[8:], 0x02100000)
+	copy(header[12:], "mntrRGB XYZ ")
+	binary.BigEndian.PutUint16(header[24:], 2025)
+	binary.BigEndian.PutUint16(header[26:], 1)
+	binary.BigEndian.PutUint16(header[28:], 1)
+	copy(header[36:], "acsp")
+	binary.BigEndian.PutUint32(header[68:], s15(0.9642))
+	binary.BigEndian.PutUint32(header[72:], s15(1.0))
+	binary.BigEndian.PutUint32(header[76:], s15(0.8249))
+
+	return append(append(header, table...), body...)
+}
+
+// convertToPDFA converts PDF data to PDF/A-2b using Ghostscript.
+func convertToPDFA(data []byte) ([]byte, error) {
+	gsPath, err := exec.LookPath("gs")
+	if err != nil {
+		return nil, fmt.Errorf("ghostscript (gs) not found in PATH")
+	}
+	logger.Debug("Using Ghostscript at: %s", gsPath)
+
+	tmpDir, err := os.MkdirTemp("", "snag-pdfa-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	inPath := filepath.Join(tmpDir, "in.pdf")
+	outPath := filepath.Join(tmpDir, "out.pdf")
+	iccPath := filepath.ToSlash(filepath.Join(tmpDir, "srgb.icc"))
+	defPath := filepath.Join(tmpDir, "PDFA_def.ps")
+
+	if err := os.WriteFile(inPath, data, DefaultFileMode); err != nil {
+		return nil, fmt.Errorf("failed to write temp PDF: %w", err)
+	}
+	if err := os.WriteFile(iccPath, srgbICCProfile(), DefaultFileMode); err != nil {
+		return nil, fmt.Errorf("failed to write ICC profile: %w", err)
+	}
+	if err := os.WriteFile(defPath, []byte(fmt.Sprintf(pdfaDefScript, psString(iccPath))), DefaultFileMode); err != nil {
+		return nil, fmt.Errorf("failed to write PDF/A definitions: %w", err)
+	}
+
+	// Ghostscript runs with -dSAFER by default, so the definitions may
+	// only read the profile once its directory is permitted
+	args := append([]string{}, ghostscriptPDFAArgs...)
+	args = append(args,
+		"-sOutputICCProfile="+iccPath,
+		"--permit-file-read="+filepath.ToSlash(tmpDir)+"/",
+		"-sOutputFile="+outPath,
+		defPath,
+		inPath,
+	)
+
+	cmd := exec.Command(gsPath, args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		logger.Debug("Ghostscript output: %s", string(output))
+		return nil, fmt.Errorf("ghostscript conversion failed: %w", err)
+	}
+
+	converted, err := os.ReadFile(outPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read converted PDF: %w", err)
+	}
+
+	return converted, nil
+}
+
+// checkPDFACompliance performs a lightweight structural check of PDF/A
+// requirements and returns a description of each problem found. It is not a
+// full validator, but catches the failures Ghostscript silently falls back on.
+func checkPDFACompliance(data []byte) []string {
+	var issues []string
+
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return append(issues, "missing PDF header")
+	}
+
+	if !bytes.Contains(data, []byte("pdfaid:part")) {
+		issues = append(issues, "missing PDF/A identification in XMP metadata")
+	}
+
+	if !bytes.Contains(data, []byte("/Metadata")) {
+		issues = append(issues, "missing document metadata stream")
+	}
+
+	if !bytes.Contains(data, []byte("/OutputIntents")) {
+		issues = append(issues, "missing OutputIntent with an embedded ICC profile")
+	}
+
+	if bytes.Contains(data, []byte("/Encrypt")) {
+		issues = append(issues, "document is encrypted")
+	}
+
+	if bytes.Contains(data, []byte("/FontDescriptor")) &&
+		!bytes.Contains(data, []byte("/FontFile")) {
+		issues = append(issues, "fonts are not embedded")
+	}
+
+	return issues
+}
+
+// buildPDFStampFooter returns a Chromium print footer template showing the
+// source URL, capture date, and page numbers. The url, pageNumber, and
+// totalPages classes are filled in by Chromium at print time.
+func buildPDFStampFooter(captured time.Time) string {
+	date := html.EscapeString(captured.Format("2006-01-02 15:04:05 MST"))
+
+	return `<div style="font-size:8px;width:100%;margin:0 10mm;display:flex;justify-content:space-between;color:#555;">` +
+		`<span class="url" style="overflow:hidden;text-overflow:ellipsis;white-space:nowrap;max-width:60%;"></span>` +
+		`<span>Captured ` + date + `</span>` +
+		`<span><span class="pageNumber"></span> / <span class="totalPages"></span></span>` +
+		`</div>`
+}
+
+// applyPDFStamp adds the --pdf-stamp footer to a print request. It goes
+// after PDFLayout.apply, so the bottom margin is raised to fit the footer
+// even when --pdf-margins sets a smaller one.
+func applyPDFStamp(req *proto.PagePrintToPDF, captured time.Time) {
+	req.DisplayHeaderFooter = true
+	if req.HeaderTemplate == "" {
+		req.HeaderTemplate = "<span></span>"
+	}
+	req.FooterTemplate = buildPDFStampFooter(captured)
+
+	if req.MarginBottom == nil || *req.MarginBottom < PDFStampMarginInches {
+		margin := PDFStampMarginInches
+		req.MarginBottom = &margin
+	}
+}
+
+// countHeadings returns the number of visible h1 to h6 headings on page,
+// which become the entries of the PDF outline.
+func countHeadings(page *rod.Page) int {
+	res, err := page.Eval(`() => [...document.querySelectorAll('h1, h2, h3, h4, h5, h6')]
+		.filter(h => h.getClientRects().length > 0).length`)
+	if err != nil {
+		logger.Debug("Failed to count headings: %v", err)
+		return 0
+	}
+	return res.Value.Int()
+}
+
+// hasPDFOutline reports whether PDF data has a document outline, the
+// bookmarks PDF readers show in their sidebar.
+func hasPDFOutline(data []byte) bool {
+	return bytes.Contains(data, []byte("/Outlines"))
+}
+
+// isolateElement hides everything on page but the element matching
+// selector, for --pdf-selector. Call the returned function to show the
+// page again.
+func isolateElement(page *rod.Page, selector string) (func(), error) {
+	res, err := page.Eval(pdfSelectorScript, selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to isolate %s: %w", selector, err)
+	}
+	if !res.Value.Bool() {
+		logger.Error("No element matches --pdf-selector %s", selector)
+		logger.ErrorWithSuggestion(
+			"Check the selector in the browser's developer tools, or wait for it to render",
+			fmt.Sprintf("snag -f pdf --wait-for '%s' --pdf-selector '%s' <url>", selector, selector),
+		)
+		return nil, fmt.Errorf("%w: %s", ErrSelectorNotFound, selector)
+	}
+
+	logger.Verbose("Printing only %s", selector)
+	return func() {
+		if _, err := page.Eval(pdfRestoreScript); err != nil {
+			logger.Debug("Failed to restore page after --pdf-selector: %v", err)
+		}
+	}, nil
+}
+
+// pdfPaperSizes are the --pdf-paper sizes, width by height in inches.
+var pdfPaperSizes = map[string][2]float64{
+	"letter":  {8.5, 11},
+	"legal":   {8.5, 14},
+	"tabloid": {11, 17},
+	"a3":      {11.69, 16.54},
+	"a4":      {8.27, 11.69},
+	"a5":      {5.83, 8.27},
+}
+
+// pdfLengthUnits are the units accepted by --pdf-margins, in inches.
+var pdfLengthUnits = map[string]float64{
+	"in": 1,
+	"cm": 1 / 2.54,
+	"mm": 1 / 25.4,
+	"pt": 1.0 / 72,
+	"px": 1.0 / 96,
+}
+
+// MinPDFScale and MaxPDFScale bound --pdf-scale, as Chrome does.
+const (
+	MinPDFScale = 0.1
+	MaxPDFScale = 2.0
+)
+
+// PDFLayout is the page setup of PDF output. Zero values keep Chrome's
+// defaults: Letter portrait paper, about 0.4in margins, and scale 1.
+type PDFLayout struct {
+	PaperWidth     float64
+	PaperHeight    float64
+	Landscape      bool
+	Margins        []float64 // top, right, bottom, left in inches
+	Scale          float64
+	HeaderTemplate string
+	FooterTemplate string
+}
+
+// apply sets the layout on a print request. Chrome prints its own header
+// or footer for an empty template, so a blank one fills the unset side.
+func (l PDFLayout) apply(req *proto.PagePrintToPDF) {
+	req.Landscape = l.Landscape
+	if l.PaperWidth > 0 {
+		req.PaperWidth = &l.PaperWidth
+		req.PaperHeight = &l.PaperHeight
+	}
+	if len(l.Margins) == 4 {
+		req.MarginTop = &l.Margins[0]
+		req.MarginRight = &l.Margins[1]
+		req.MarginBottom = &l.Margins[2]
+		req.MarginLeft = &l.Margins[3]
+	}
+	if l.Scale > 0 && l.Scale != 1 {
+		req.Scale = &l.Scale
+	}
+
+	if l.HeaderTemplate == "" && l.FooterTemplate == "" {
+		return
+	}
+	req.DisplayHeaderFooter = true
+	if l.HeaderTemplate != "" {
+		req.HeaderTemplate = l.HeaderTemplate
+	} else if req.HeaderTemplate == "" {
+		req.HeaderTemplate = "<span></span>"
+	}
+	if l.FooterTemplate != "" {
+		req.FooterTemplate = l.FooterTemplate
+	} else if req.FooterTemplate == "" {
+		req.FooterTemplate = "<span></span>"
+	}
+}
+
+// parsePDFPaper returns the width and height in inches of a --pdf-paper
+// size. It returns zeros for an empty value.
+func parsePDFPaper(name string) (float64, float64, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return 0, 0, nil
+	}
+	size, ok := pdfPaperSizes[name]
+	if !ok {
+		return 0, 0, fmt.Errorf("unknown paper size %s", name)
+	}
+	return size[0], size[1], nil
+}
+
+// parsePDFMargins reads a --pdf-margins value in the CSS margin shorthand:
+// one to four lengths for top, right, bottom, and left, such as "10mm" or
+// "0.5in 1in". It returns nil for an empty value.
+func parsePDFMargins(spec string) ([]float64, error) {
+	fields := strings.Fields(strings.ReplaceAll(spec, ",", " "))
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	if len(fields) > 4 {
+		return nil, fmt.Errorf("expected 1 to 4 lengths, got %d", len(fields))
+	}
+
+	values := make([]float64, len(fields))
+	for i, field := range fields {
+		v, err := parsePDFLength(field)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+
+	switch len(values) {
+	case 1:
+		return []float64{values[0], values[0], values[0], values[0]}, nil
+	case 2:
+		return []float64{values[0], values[1], values[0], values[1]}, nil
+	case 3:
+		return []float64{values[0], values[1], values[2], values[1]}, nil
+	}
+	return values, nil
+}
+
+// parsePDFLength converts a length such as "10mm" or "0.5in" to inches. A
+// unit is required for anything but zero.
+func parsePDFLength(s string) (float64, error) {
+	s = strings.ToLower(s)
+	if s == "0" {
+		return 0, nil
+	}
+	if len(s) < 3 {
+		return 0, fmt.Errorf("invalid length %s (use mm, cm, in, pt, or px)", s)
+	}
+	perUnit, ok := pdfLengthUnits[s[len(s)-2:]]
+	if !ok {
+		return 0, fmt.Errorf("invalid length %s (use mm, cm, in, pt, or px)", s)
+	}
+	v, err := strconv.ParseFloat(s[:len(s)-2], 64)
+	if err != nil || v < 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+		return 0, fmt.Errorf("invalid length %s", s)
+	}
+	return v * perUnit, nil
+}
@@ -0,0 +1,282 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"encoding/binary"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+func TestCheckPDFACompliance(t *testing.T) {
+	compliant := "%PDF-1.7\n/Metadata 3 0 R\n/OutputIntents [6 0 R]\n<pdfaid:part>2</pdfaid:part>\n/FontDescriptor /FontFile2 5 0 R\n"
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"compliant", compliant, ""},
+		{"not a pdf", "hello", "missing PDF header"},
+		{"no pdfaid", strings.ReplaceAll(compliant, "pdfaid:part", "dc:title"), "PDF/A identification"},
+		{"no metadata", strings.Replace(compliant, "/Metadata", "/Info", 1), "metadata stream"},
+		{"no output intent", strings.Replace(compliant, "/OutputIntents", "/Outlines", 1), "OutputIntent"},
+		{"encrypted", compliant + "/Encrypt 9 0 R\n", "encrypted"},
+		{"unembedded fonts", strings.Replace(compliant, "/FontFile2", "/Widths", 1), "not embedded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := checkPDFACompliance([]byte(tt.data))
+			if tt.wantErr == "" {
+				if len(issues) != 0 {
+					t.Errorf("expected no issues, got %v", issues)
+				}
+				return
+			}
+			found := false
+			for _, issue := range issues {
+				if strings.Contains(issue, tt.wantErr) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected issue containing %q, got %v", tt.wantErr, issues)
+			}
+		})
+	}
+}
+
+func TestSRGBICCProfile(t *testing.T) {
+	profile := srgbICCProfile()
+
+	if size := binary.BigEndian.Uint32(profile); int(size) != len(profile) {
+		t.Errorf("header size = %d, want %d", size, len(profile))
+	}
+	if got := string(profile[12:24]); got != "mntrRGB XYZ " {
+		t.Errorf("class and color spaces = %q, want %q", got, "mntrRGB XYZ ")
+	}
+	if got := string(profile[36:40]); got != "acsp" {
+		t.Errorf("signature = %q, want acsp", got)
+	}
+
+	count := binary.BigEndian.Uint32(profile[128:])
+	tags := make(map[string]bool)
+	for i := range count {
+		entry := profile[132+12*i:]
+		offset, size := binary.BigEndian.Uint32(entry[4:]), binary.BigEndian.Uint32(entry[8:])
+		if offset%4 != 0 || int(offset+size) > len(profile) {
+			t.Errorf("tag %s at %d+%d is misaligned or out of bounds", entry[:4], offset, size)
+		}
+		tags[string(entry[:4])] = true
+	}
+	for _, sig := range []string{"desc", "cprt", "wtpt", "rXYZ", "gXYZ", "bXYZ", "rTRC", "gTRC", "bTRC"} {
+		if !tags[sig] {
+			t.Errorf("missing required tag %s", sig)
+		}
+	}
+}
+
+func TestPSString(t *testing.T) {
+	if got := psString("/tmp/snag (1)/srgb.icc"); got != `/tmp/snag \(1\)/srgb.icc` {
+		t.Errorf("psString() = %q", got)
+	}
+}
+
+func TestBuildPDFStampFooter(t *testing.T) {
+	captured := time.Date(2025, 10, 22, 14, 20, 33, 0, time.UTC)
+	footer := buildPDFStampFooter(captured)
+
+	for _, want := range []string{
+		`class="url"`,
+		`class="pageNumber"`,
+		`class="totalPages"`,
+		"Captured 2025-10-22 14:20:33 UTC",
+	} {
+		if !strings.Contains(footer, want) {
+			t.Errorf("expected footer to contain %q, got:\n%s", want, footer)
+		}
+	}
+}
+
+func TestHasPDFOutline(t *testing.T) {
+	withOutline := []byte("%PDF-1.7\n1 0 obj\n<< /Type /Catalog /Pages 2 0 R /Outlines 3 0 R >>\nendobj\n")
+	if !hasPDFOutline(withOutline) {
+		t.Error("expected outline to be detected")
+	}
+
+	withoutOutline := []byte("%PDF-1.7\n1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	if hasPDFOutline(withoutOutline) {
+		t.Error("expected no outline")
+	}
+}
+
+func TestCLI_PDFSelectorRequiresPDF(t *testing.T) {
+	_, stderr, err := runSnag("--pdf-selector", ".invoice", "example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "--pdf-selector only applies to --format pdf")
+}
+
+func TestCLI_PDFSelectorWithSplitBy(t *testing.T) {
+	_, stderr, err := runSnag("-f", "pdf", "--pdf-selector", ".invoice", "--pdf-split-by", "h1", "example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "Cannot use --pdf-selector with --pdf-split-by")
+}
+
+func TestCLI_PDFLayoutInvalid(t *testing.T) {
+	_, stderr, err := runSnag("-f", "pdf", "--pdf-margins", "10", "example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "Invalid --pdf-margins")
+
+	_, stderr, err = runSnag("-f", "pdf", "--pdf-scale", "3", "example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "Invalid --pdf-scale: 3")
+
+	_, stderr, err = runSnag("-f", "pdf", "--pdf-stamp", "--pdf-footer-template", "<div></div>", "example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "Cannot use --pdf-stamp with --pdf-footer-template")
+}
+
+func TestBrowser_PDFSelectorNotFound(t *testing.T) {
+	if !isBrowserAvailable() {
+		t.Skip("Browser not available, skipping browser integration test")
+	}
+
+	server := startTestServer(t)
+	output := filepath.Join(t.TempDir(), "page.pdf")
+
+	_, stderr, err := runSnag("-f", "pdf", "--pdf-selector", ".missing", "-o", output, server.URL+"/simple.html")
+	assertError(t, err)
+	assertContains(t, stderr, "No element matches --pdf-selector .missing")
+}
+
+func TestParsePDFMargins(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []float64
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"1in", []float64{1, 1, 1, 1}, false},
+		{"0.5in 1in", []float64{0.5, 1, 0.5, 1}, false},
+		{"1in 0 2in", []float64{1, 0, 2, 0}, false},
+		{"1in,2in,3in,4in", []float64{1, 2, 3, 4}, false},
+		{"25.4mm", []float64{1, 1, 1, 1}, false},
+		{"72pt 96px 2.54cm 1IN", []float64{1, 1, 1, 1}, false},
+		{"10", nil, true},
+		{"10em", nil, true},
+		{"-1in", nil, true},
+		{"infmm", nil, true},
+		{"nanmm", nil, true},
+		{"1in 1in 1in 1in 1in", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := parsePDFMargins(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parsePDFMargins(%q) = %v, want error", tt.spec, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parsePDFMargins(%q) error: %v", tt.spec, err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("parsePDFMargins(%q) = %v, want %v", tt.spec, got, tt.want)
+			}
+			for i := range got {
+				if math.Abs(got[i]-tt.want[i]) > 1e-9 {
+					t.Errorf("parsePDFMargins(%q) = %v, want %v", tt.spec, got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestParsePDFPaper(t *testing.T) {
+	w, h, err := parsePDFPaper(" A4 ")
+	if err != nil || w != 8.27 || h != 11.69 {
+		t.Errorf("parsePDFPaper(A4) = %v, %v, %v", w, h, err)
+	}
+	if w, h, err := parsePDFPaper(""); err != nil || w != 0 || h != 0 {
+		t.Errorf("parsePDFPaper(\"\") = %v, %v, %v", w, h, err)
+	}
+	if _, _, err := parsePDFPaper("b5"); err == nil {
+		t.Error("parsePDFPaper(b5) should fail")
+	}
+}
+
+func TestPDFLayoutApply(t *testing.T) {
+	req := &proto.PagePrintToPDF{}
+	PDFLayout{}.apply(req)
+	if req.PaperWidth != nil || req.MarginTop != nil || req.Scale != nil || req.DisplayHeaderFooter {
+		t.Errorf("empty layout changed request: %+v", req)
+	}
+
+	req = &proto.PagePrintToPDF{}
+	PDFLayout{
+		PaperWidth:     8.27,
+		PaperHeight:    11.69,
+		Landscape:      true,
+		Margins:        []float64{1, 2, 3, 4},
+		Scale:          0.8,
+		HeaderTemplate: "<div>Report</div>",
+	}.apply(req)
+	if *req.PaperWidth != 8.27 || *req.PaperHeight != 11.69 || !req.Landscape {
+		t.Errorf("paper = %v x %v landscape %v", *req.PaperWidth, *req.PaperHeight, req.Landscape)
+	}
+	if *req.MarginTop != 1 || *req.MarginRight != 2 || *req.MarginBottom != 3 || *req.MarginLeft != 4 {
+		t.Errorf("margins = %v %v %v %v", *req.MarginTop, *req.MarginRight, *req.MarginBottom, *req.MarginLeft)
+	}
+	if *req.Scale != 0.8 {
+		t.Errorf("scale = %v, want 0.8", *req.Scale)
+	}
+	if !req.DisplayHeaderFooter || req.HeaderTemplate != "<div>Report</div>" || req.FooterTemplate != "<span></span>" {
+		t.Errorf("header/footer = %v %q %q", req.DisplayHeaderFooter, req.HeaderTemplate, req.FooterTemplate)
+	}
+
+	// --pdf-stamp's footer is kept with a custom header
+	req = &proto.PagePrintToPDF{DisplayHeaderFooter: true, FooterTemplate: "stamp"}
+	PDFLayout{HeaderTemplate: "<div>Report</div>"}.apply(req)
+	if req.FooterTemplate != "stamp" {
+		t.Errorf("footer = %q, want stamp", req.FooterTemplate)
+	}
+}
+
+func TestApplyPDFStamp(t *testing.T) {
+	captured := time.Date(2025, 10, 22, 14, 20, 33, 0, time.UTC)
+
+	// --pdf-margins smaller than the footer is raised to fit it
+	req := &proto.PagePrintToPDF{}
+	PDFLayout{Margins: []float64{0.2, 0.2, 0.2, 0.2}, HeaderTemplate: "<div>Report</div>"}.apply(req)
+	applyPDFStamp(req, captured)
+	if *req.MarginBottom != PDFStampMarginInches || *req.MarginTop != 0.2 {
+		t.Errorf("margins = top %v bottom %v, want bottom %v", *req.MarginTop, *req.MarginBottom, PDFStampMarginInches)
+	}
+	if req.HeaderTemplate != "<div>Report</div>" || !strings.Contains(req.FooterTemplate, "pageNumber") {
+		t.Errorf("header/footer = %q %q", req.HeaderTemplate, req.FooterTemplate)
+	}
+
+	// A larger bottom margin is kept
+	req = &proto.PagePrintToPDF{}
+	PDFLayout{Margins: []float64{1, 1, 1, 1}}.apply(req)
+	applyPDFStamp(req, captured)
+	if *req.MarginBottom != 1 {
+		t.Errorf("bottom margin = %v, want 1", *req.MarginBottom)
+	}
+	if !req.DisplayHeaderFooter || req.HeaderTemplate != "<span></span>" {
+		t.Errorf("header = %v %q", req.DisplayHeaderFooter, req.HeaderTemplate)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+const (
+	// DefaultPoolSize is the number of tabs snag serve keeps for POST /fetch.
+	DefaultPoolSize = 4
+
+	// PoolResetTimeout bounds clearing a tab before it is reused.
+	PoolResetTimeout = 5 * time.Second
+)
+
+// PagePool lends out up to size tabs of one browser, reusing them between
+// requests so each fetch skips opening a new tab. Tabs are opened as they
+// are first needed.
+type PagePool struct {
+	bm    *BrowserManager
+	idle  chan *rod.Page
+	slots chan struct{}
+}
+
+// NewPagePool returns a pool of at most size tabs in bm's browser.
+func NewPagePool(bm *BrowserManager, size int) *PagePool {
+	pool := &PagePool{
+		bm:    bm,
+		idle:  make(chan *rod.Page, size),
+		slots: make(chan struct{}, size),
+	}
+	for range size {
+		pool.slots <- struct{}{}
+	}
+	return pool
+}
+
+// Acquire returns an idle tab, opening one if the pool is not full, or waits
+// for one to be released. It fails when ctx is done first.
+func (p *PagePool) Acquire(ctx context.Context) (*rod.Page, error) {
+	select {
+	case page := <-p.idle:
+		return page, nil
+	default:
+	}
+
+	select {
+	case page := <-p.idle:
+		return page, nil
+	case <-p.slots:
+		page, err := p.bm.NewPage()
+		if err != nil {
+			p.slots <- struct{}{}
+			return nil, err
+		}
+		return page, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("no tab available: %w", ctx.Err())
+	}
+}
+
+// Release returns page to the pool after clearing it, so the next request
+// does not see the last one's page. A tab that cannot be cleared, as when
+// it has crashed, is closed and replaced on demand.
+func (p *PagePool) Release(page *rod.Page) {
+	if err := page.Timeout(PoolResetTimeout).Navigate("about:blank"); err != nil {
+		logger.Debug("Discarding pooled tab: %v", err)
+		p.bm.ClosePage(page)
+		p.slots <- struct{}{}
+		return
+	}
+	p.idle <- page
+}
+
+// Close closes the idle tabs. Tabs still lent out close with the browser.
+func (p *PagePool) Close() {
+	for {
+		select {
+		case page := <-p.idle:
+			p.bm.ClosePage(page)
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	pruneKeep   int
+	pruneOlder  string
+	pruneDryRun bool
+)
+
+const pruneHelpTemplate = `USAGE:
+  snag prune -d DIR [--keep N] [--older-than AGE] [options]
+
+DESCRIPTION:
+  Delete old snapshots from an output directory. Snapshots are the files
+  snag names with a timestamp, 2025-10-22-142033-example-page.md, and the
+  directories --run-dir creates. Each page's snapshots are pruned on their
+  own, and all runs are pruned together.
+
+  The newest --keep snapshots of each page are always kept. With
+  --older-than, older snapshots are only deleted once they are past that
+  age. The newest snapshot of a page is never deleted, so latest links
+  keep working. Other files and directories are left alone.
+
+EXAMPLES:
+  snag prune -d snapshots/ --keep 10
+  snag prune -d snapshots/ --older-than 30d
+  snag prune -d snapshots/ --keep 10 --older-than 30d --dry-run
+
+OPTIONS:
+  -d, --output-dir string      Directory to prune
+      --keep int               Snapshots of each page to keep, newest first (default 1)
+      --older-than string      Only delete snapshots older than this: 30d, 2w, 12h
+      --dry-run                List what would be deleted without deleting it
+
+  -q, --quiet                  Suppress all output except errors
+      --verbose                Enable verbose logging output
+  -h, --help                   help for prune
+`
+
+var pruneCmd = &cobra.Command{
+	Use:          "prune",
+	Short:        "Delete old timestamped snapshots from an output directory",
+	Args:         cobra.NoArgs,
+	RunE:         runPrune,
+	SilenceUsage: true,
+}
+
+func init() {
+	pruneCmd.Flags().StringVarP(&outputDir, "output-dir", "d", "", "Directory to prune")
+	pruneCmd.Flags().IntVar(&pruneKeep, "keep", 1, "Snapshots of each page to keep, newest first")
+	pruneCmd.Flags().StringVar(&pruneOlder, "older-than", "", "Only delete snapshots older than this: 30d, 2w, 12h")
+	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "List what would be deleted without deleting it")
+	pruneCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Suppress all output except errors")
+	pruneCmd.Flags().BoolVar(&verbose, "verbose", false, "Enable verbose logging output")
+
+	pruneCmd.MarkFlagsMutuallyExclusive("quiet", "verbose")
+	pruneCmd.SetHelpTemplate(pruneHelpTemplate)
+
+	rootCmd.AddCommand(pruneCmd)
+}
+
+// snapshot is a timestamped file or --run-dir directory found by prune.
+// Snapshots of the same page, or all runs, share a group.
+type snapshot struct {
+	name  string
+	group string
+	saved time.Time
+	dir   bool
+}
+
+// runGroup groups --run-dir directories, which are named only by time.
+// No filename group can be empty, so it cannot clash.
+const runGroup = ""
+
+// findSnapshots returns the snapshots among entries of a directory.
+func findSnapshots(entries []os.DirEntry) []snapshot {
+	var snaps []snapshot
+	for _, entry := range entries {
+		saved, key, ok := parseSnapshotName(entry.Name())
+		if !ok {
+			continue
+		}
+		switch {
+		case entry.IsDir():
+			// 2025-10-22-142033 or, for runs in the same second, -2
+			if _, err := strconv.Atoi(key); key != "" && err != nil {
+				continue
+			}
+			snaps = append(snaps, snapshot{name: entry.Name(), group: runGroup, saved: saved, dir: true})
+		case entry.Type().IsRegular() && key != "":
+			snaps = append(snaps, snapshot{name: entry.Name(), group: key, saved: saved})
+		}
+	}
+	return snaps
+}
+
+// selectPrunable returns the snapshots to delete: in each group, those
+// after the newest keep that are also older than olderThan, when set.
+func selectPrunable(snaps []snapshot, keep int, olderThan time.Duration, now time.Time) []snapshot {
+	groups := make(map[string][]snapshot)
+	for _, s := range snaps {
+		groups[s.group] = append(groups[s.group], s)
+	}
+
+	var prune []snapshot
+	for _, group := range groups {
+		slices.SortFunc(group, func(a, b snapshot) int {
+			if c := b.saved.Compare(a.saved); c != 0 {
+				return c
+			}
+			return cmp.Compare(b.name, a.name)
+		})
+		for i, s := range group {
+			if i < keep || (olderThan > 0 && now.Sub(s.saved) <= olderThan) {
+				continue
+			}
+			prune = append(prune, s)
+		}
+	}
+	slices.SortFunc(prune, func(a, b snapshot) int { return cmp.Compare(a.name, b.name) })
+	return prune
+}
+
+// parseAge reads an --older-than age: a number of days or weeks such as
+// 30d or 2w, or a Go duration such as 12h.
+func parseAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if count, ok := strings.CutSuffix(value, suffix); ok {
+			n, err := strconv.Atoi(count)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid age: %s", value)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age: %s", value)
+	}
+	return d, nil
+}
+
+func runPrune(cmd *cobra.Command, args []string) error {
+	level := LevelNormal
+	if verbose {
+		level = LevelVerbose
+	} else if quiet {
+		level = LevelQuiet
+	}
+	logger = NewLogger(level)
+
+	dir := strings.TrimSpace(outputDir)
+	if dir == "" {
+		logger.Error("snag prune requires --output-dir")
+		logger.ErrorWithSuggestion(
+			"Give the directory snapshots are saved to",
+			"snag prune -d snapshots/ --keep 10",
+		)
+		return fmt.Errorf("prune requires --output-dir")
+	}
+
+	if !cmd.Flags().Changed("keep") && !cmd.Flags().Changed("older-than") {
+		logger.Error("snag prune needs --keep, --older-than, or both")
+		logger.ErrorWithSuggestion(
+			"Say how much history to keep",
+			"snag prune -d "+dir+" --keep 10 --older-than 30d",
+		)
+		return fmt.Errorf("no retention rule given")
+	}
+
+	if pruneKeep < 1 {
+		logger.Error("Invalid --keep: %d", pruneKeep)
+		logger.ErrorWithSuggestion(
+			"The newest snapshot of each page is always kept",
+			"snag prune -d "+dir+" --keep 1",
+		)
+		return fmt.Errorf("invalid keep: %d", pruneKeep)
+	}
+
+	var olderThan time.Duration
+	if pruneOlder != "" {
+		age, err := parseAge(pruneOlder)
+		if err != nil {
+			logger.Error("Invalid --older-than: %s", pruneOlder)
+			logger.ErrorWithSuggestion(
+				"Use days (30d), weeks (2w), or a duration (12h)",
+				"snag prune -d "+dir+" --older-than 30d",
+			)
+			return err
+		}
+		olderThan = age
+	}
+
+	if err := validateDirectory(dir); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		logger.Error("Failed to read %s: %v", dir, err)
+		return err
+	}
+	snaps := findSnapshots(entries)
+	prune := selectPrunable(snaps, pruneKeep, olderThan, time.Now())
+
+	if pruneDryRun {
+		for _, s := range prune {
+			fmt.Fprintln(cmd.OutOrStdout(), filepath.Join(dir, s.name))
+		}
+		logger.Info("Would delete %d of %d snapshot%s", len(prune), len(snaps), plural(len(snaps)))
+		return nil
+	}
+
+	deleted := 0
+	for _, s := range prune {
+		path := filepath.Join(dir, s.name)
+		remove := os.Remove
+		if s.dir {
+			remove = os.RemoveAll
+		}
+		if err := remove(path); err != nil {
+			logger.Warning("Failed to delete %s: %v", path, err)
+			continue
+		}
+		logger.Verbose("Deleted %s", path)
+		deleted++
+	}
+
+	logger.Success("Deleted %d of %d snapshot%s from %s", deleted, len(snaps), plural(len(snaps)), dir)
+	if deleted < len(prune) {
+		return fmt.Errorf("failed to delete %d snapshot%s", len(prune)-deleted, plural(len(prune)-deleted))
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"30d", 30 * 24 * time.Hour, true},
+		{"2w", 14 * 24 * time.Hour, true},
+		{"12h", 12 * time.Hour, true},
+		{"0d", 0, true},
+		{"d", 0, false},
+		{"-3d", 0, false},
+		{"1.5d", 0, false},
+		{"month", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, err := parseAge(tt.value)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("parseAge(%q) = %v, %v, want %v, ok=%v", tt.value, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestFindSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"2025-10-22-142033-page.md", "latest-page.md", "notes.md"} {
+		assertNoError(t, os.WriteFile(filepath.Join(dir, name), nil, DefaultFileMode))
+	}
+	for _, name := range []string{"2025-10-22-142033", "2025-10-22-142033-2", "2025-10-22-142033-old", "assets"} {
+		assertNoError(t, os.Mkdir(filepath.Join(dir, name), 0755))
+	}
+	assertNoError(t, os.Symlink("2025-10-22-142033-page.md", filepath.Join(dir, "2025-10-23-000000-page.md")))
+
+	entries, err := os.ReadDir(dir)
+	assertNoError(t, err)
+	var names []string
+	for _, s := range findSnapshots(entries) {
+		names = append(names, s.name)
+	}
+	want := []string{"2025-10-22-142033", "2025-10-22-142033-2", "2025-10-22-142033-page.md"}
+	if !slices.Equal(names, want) {
+		t.Errorf("findSnapshots() = %v, want %v", names, want)
+	}
+}
+
+func TestSelectPrunable(t *testing.T) {
+	now := time.Date(2025, 10, 22, 12, 0, 0, 0, time.Local)
+	day := func(n int) time.Time { return now.AddDate(0, 0, -n) }
+	snaps := []snapshot{
+		{name: "a-1", group: "a.md", saved: day(1)},
+		{name: "a-10", group: "a.md", saved: day(10)},
+		{name: "a-40", group: "a.md", saved: day(40)},
+		{name: "a-50", group: "a.md", saved: day(50)},
+		{name: "b-60", group: "b.md", saved: day(60)},
+		{name: "run-5", group: runGroup, saved: day(5), dir: true},
+		{name: "run-45", group: runGroup, saved: day(45), dir: true},
+	}
+
+	names := func(prune []snapshot) []string {
+		var out []string
+		for _, s := range prune {
+			out = append(out, s.name)
+		}
+		return out
+	}
+
+	tests := []struct {
+		keep      int
+		olderThan time.Duration
+		want      []string
+	}{
+		{keep: 2, want: []string{"a-40", "a-50"}},
+		{keep: 1, want: []string{"a-10", "a-40", "a-50", "run-45"}},
+		// The newest of each page is kept however old it is
+		{keep: 1, olderThan: 30 * 24 * time.Hour, want: []string{"a-40", "a-50", "run-45"}},
+		{keep: 3, olderThan: 30 * 24 * time.Hour, want: []string{"a-50"}},
+		{keep: 10, want: nil},
+	}
+
+	for _, tt := range tests {
+		got := names(selectPrunable(slices.Clone(snaps), tt.keep, tt.olderThan, now))
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("selectPrunable(keep=%d, olderThan=%v) = %v, want %v", tt.keep, tt.olderThan, got, tt.want)
+		}
+	}
+}
+
+func TestCLI_Prune(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().AddDate(0, 0, -40).Format(RunDirFormat)
+	recent := time.Now().AddDate(0, 0, -1).Format(RunDirFormat)
+	for _, name := range []string{old + "-page.md", recent + "-page.md", "notes.md"} {
+		assertNoError(t, os.WriteFile(filepath.Join(dir, name), nil, DefaultFileMode))
+	}
+
+	stdout, _, err := runSnag("prune", "-d", dir, "--older-than", "30d", "--dry-run")
+	assertNoError(t, err)
+	assertContains(t, stdout, old+"-page.md")
+
+	_, _, err = runSnag("prune", "-d", dir, "--older-than", "30d")
+	assertNoError(t, err)
+	entries, err := os.ReadDir(dir)
+	assertNoError(t, err)
+	if len(entries) != 2 {
+		t.Errorf("expected the recent snapshot and notes.md left, got %d entries", len(entries))
+	}
+
+	_, stderr, err := runSnag("prune", "-d", dir)
+	assertError(t, err)
+	assertContains(t, stderr, "needs --keep, --older-than, or both")
+
+	_, stderr, err = runSnag("prune", "-d", dir, "--keep", "0")
+	assertError(t, err)
+	assertContains(t, stderr, "Invalid --keep: 0")
+}
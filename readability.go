@@ -0,0 +1,248 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"math"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	// ReadabilityMinParagraph is the shortest paragraph text, in
+	// characters, that counts towards its container's score.
+	ReadabilityMinParagraph = 25
+
+	// ReadabilitySiblingShare is the share of the top score a sibling of
+	// the top candidate needs to be kept with it.
+	ReadabilitySiblingShare = 0.2
+)
+
+var (
+	// unlikelyCandidate matches class and id values of page chrome.
+	unlikelyCandidate = regexp.MustCompile(`(?i)banner|breadcrumb|combx|comment|community|cookie|disqus|extra|footer|gdpr|header|menu|modal|nav|pager|pagination|popup|promo|related|remark|rss|share|shoutbox|sidebar|skyscraper|social|sponsor|subscribe|tags|toolbar|widget|\bads?\b|advert`)
+
+	// maybeCandidate rescues elements that match unlikelyCandidate but may
+	// still hold the content, such as "article-header".
+	maybeCandidate = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+
+	// positiveCandidate and negativeCandidate adjust the score of elements
+	// by their class and id.
+	positiveCandidate = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|post|story|text|blog`)
+	negativeCandidate = regexp.MustCompile(`(?i)-ad-|hidden|\bhid\b|banner|combx|comment|com-|contact|footer|footnote|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|widget`)
+)
+
+// chromeElements are dropped before scoring as they never hold the article.
+var chromeElements = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true,
+	"nav": true, "footer": true, "aside": true,
+	"form": true, "iframe": true, "button": true, "svg": true,
+	"dialog": true, "menu": true,
+}
+
+// extractMainContent returns the HTML of the main article of a page, found
+// the way reader modes do: paragraphs score their containers by length and
+// commas, page chrome and link-heavy blocks score low, and the best
+// container is kept along with related siblings. Pages with no clear
+// article are returned as their cleaned body.
+func extractMainContent(htmlContent string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return "", err
+	}
+
+	removeChrome(doc)
+
+	body := findElement(doc, "body")
+	if body == nil {
+		body = doc
+	}
+
+	scores := scoreCandidates(body)
+
+	var top *html.Node
+	for n, score := range scores {
+		if top == nil || score > scores[top] {
+			top = n
+		}
+	}
+
+	var buf bytes.Buffer
+	if top == nil {
+		logger.Debug("Readability: no article found, keeping page body")
+		if err := renderChildren(&buf, body); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+
+	logger.Debug("Readability: top candidate <%s> scored %.1f", top.Data, scores[top])
+
+	threshold := math.Max(10, scores[top]*ReadabilitySiblingShare)
+	buf.WriteString("<article>")
+	for sibling := firstSibling(top); sibling != nil; sibling = sibling.NextSibling {
+		keep := sibling == top
+		if !keep && sibling.Type == html.ElementNode {
+			if score, ok := scores[sibling]; ok && score >= threshold {
+				keep = true
+			} else if sibling.Data == "p" {
+				text := strings.TrimSpace(textContent(sibling))
+				keep = len(text) > 80 && linkDensity(sibling) < 0.25
+			}
+		}
+		if keep {
+			if err := html.Render(&buf, sibling); err != nil {
+				return "", err
+			}
+		}
+	}
+	buf.WriteString("</article>")
+
+	return buf.String(), nil
+}
+
+// removeChrome deletes elements that are never part of an article:
+// scripts, navigation, and blocks whose class or id mark them as chrome.
+func removeChrome(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.CommentNode {
+			n.RemoveChild(c)
+		} else if c.Type == html.ElementNode {
+			match := attr(c, "class") + " " + attr(c, "id") + " " + attr(c, "role")
+			if chromeElements[c.Data] || attr(c, "aria-hidden") == "true" ||
+				(c.Data != "body" && c.Data != "article" && c.Data != "main" &&
+					unlikelyCandidate.MatchString(match) && !maybeCandidate.MatchString(match)) {
+				n.RemoveChild(c)
+			} else {
+				removeChrome(c)
+			}
+		}
+		c = next
+	}
+}
+
+// scoreCandidates scores the containers of every paragraph under root.
+// Each paragraph gives its parent its score and its grandparent half.
+func scoreCandidates(root *html.Node) map[*html.Node]float64 {
+	scores := make(map[*html.Node]float64)
+
+	initialize := func(n *html.Node) {
+		if _, ok := scores[n]; ok {
+			return
+		}
+		score := classWeight(n)
+		switch n.Data {
+		case "article":
+			score += 10
+		case "div", "main", "section":
+			score += 5
+		case "pre", "td", "blockquote":
+			score += 3
+		case "address", "ol", "ul", "dl", "dd", "dt", "li", "form":
+			score -= 3
+		case "h1", "h2", "h3", "h4", "h5", "h6", "th":
+			score -= 5
+		}
+		scores[n] = score
+	}
+
+	for n := range root.Descendants() {
+		if n.Type != html.ElementNode || (n.Data != "p" && n.Data != "pre" && n.Data != "td") {
+			continue
+		}
+
+		text := strings.TrimSpace(textContent(n))
+		if len(text) < ReadabilityMinParagraph {
+			continue
+		}
+
+		score := 1 + float64(strings.Count(text, ",")) + math.Min(float64(len(text))/100, 3)
+
+		parent := n.Parent
+		if parent == nil || parent.Type != html.ElementNode {
+			continue
+		}
+		initialize(parent)
+		scores[parent] += score
+
+		if grandparent := parent.Parent; grandparent != nil && grandparent.Type == html.ElementNode && grandparent.Data != "html" {
+			initialize(grandparent)
+			scores[grandparent] += score / 2
+		}
+	}
+
+	for n := range scores {
+		scores[n] *= 1 - linkDensity(n)
+	}
+
+	return scores
+}
+
+// classWeight scores an element by whether its class and id look like
+// content or chrome.
+func classWeight(n *html.Node) float64 {
+	weight := 0.0
+	for _, value := range []string{attr(n, "class"), attr(n, "id")} {
+		if value == "" {
+			continue
+		}
+		if negativeCandidate.MatchString(value) {
+			weight -= 25
+		}
+		if positiveCandidate.MatchString(value) {
+			weight += 25
+		}
+	}
+	return weight
+}
+
+// linkDensity returns the share of n's text that is inside links.
+func linkDensity(n *html.Node) float64 {
+	total := len(strings.TrimSpace(textContent(n)))
+	if total == 0 {
+		return 0
+	}
+	linked := 0
+	for c := range n.Descendants() {
+		if c.Type == html.ElementNode && c.Data == "a" {
+			linked += len(strings.TrimSpace(textContent(c)))
+		}
+	}
+	return math.Min(float64(linked)/float64(total), 1)
+}
+
+// findElement returns the first element named tag under n, or nil.
+func findElement(n *html.Node, tag string) *html.Node {
+	for c := range n.Descendants() {
+		if c.Type == html.ElementNode && c.Data == tag {
+			return c
+		}
+	}
+	return nil
+}
+
+// firstSibling returns the first child of n's parent.
+func firstSibling(n *html.Node) *html.Node {
+	if n.Parent == nil {
+		return n
+	}
+	return n.Parent.FirstChild
+}
+
+// renderChildren writes the HTML of n's children to buf.
+func renderChildren(buf *bytes.Buffer, n *html.Node) error {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if err := html.Render(buf, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
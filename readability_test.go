@@ -0,0 +1,93 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const readabilityTestHTML = `<html><head><title>Post</title><script>track()</script></head><body>
+<nav><a href="/">Home</a> <a href="/blog">Blog</a></nav>
+<div class="sidebar"><p>Subscribe to our newsletter for weekly updates, tips, and offers.</p></div>
+<div id="main">
+  <div class="post-body">
+    <h1>Understanding Widgets</h1>
+    <p>Widgets are small, composable parts that, when combined, form larger systems of surprising power.</p>
+    <p>In this article, we look at how widgets are built, tested, and shipped, and why it matters.</p>
+    <p>Finally, we discuss the trade-offs, pitfalls, and patterns teams meet when adopting them at scale.</p>
+  </div>
+  <div class="comments"><p>Great post, thanks for sharing this with everyone here!</p></div>
+</div>
+<footer><p>Copyright 2025, Example Inc. All rights reserved, worldwide.</p></footer>
+</body></html>`
+
+func TestExtractMainContent(t *testing.T) {
+	logger = NewLogger(LevelQuiet)
+
+	got, err := extractMainContent(readabilityTestHTML)
+	if err != nil {
+		t.Fatalf("extractMainContent failed: %v", err)
+	}
+
+	for _, want := range []string{"Understanding Widgets", "composable parts", "trade-offs"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"Home", "newsletter", "Great post", "Copyright", "track()"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("unexpected %q in:\n%s", unwanted, got)
+		}
+	}
+}
+
+func TestExtractMainContent_NoArticle(t *testing.T) {
+	logger = NewLogger(LevelQuiet)
+
+	got, err := extractMainContent(`<html><body><h1>Hi</h1><p>Short.</p></body></html>`)
+	if err != nil {
+		t.Fatalf("extractMainContent failed: %v", err)
+	}
+	if !strings.Contains(got, "<h1>Hi</h1>") || !strings.Contains(got, "Short.") {
+		t.Errorf("expected the page body, got:\n%s", got)
+	}
+}
+
+func TestConvert_MainContent(t *testing.T) {
+	logger = NewLogger(LevelQuiet)
+
+	for _, format := range []string{FormatMarkdown, FormatReader, FormatOrg} {
+		cc := NewContentConverter(format)
+		cc.mainContent = true
+
+		got, err := cc.Convert(readabilityTestHTML)
+		if err != nil {
+			t.Fatalf("%s: Convert failed: %v", format, err)
+		}
+		if !strings.Contains(got, "composable parts") {
+			t.Errorf("%s: missing article in:\n%s", format, got)
+		}
+		if strings.Contains(got, "newsletter") || strings.Contains(got, "Blog") {
+			t.Errorf("%s: unexpected page chrome in:\n%s", format, got)
+		}
+	}
+}
+
+func TestCLI_ConvertReadability(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "post.html")
+	os.WriteFile(input, []byte(readabilityTestHTML), 0644)
+
+	stdout, _, err := runSnag("convert", input, "--readability")
+	if err != nil {
+		t.Fatalf("convert failed: %v", err)
+	}
+	assertContains(t, stdout, "# Understanding Widgets")
+	assertNotContains(t, stdout, "Copyright 2025")
+}
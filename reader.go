@@ -0,0 +1,139 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ReaderWidth is the column paragraphs are wrapped at in reader output.
+const ReaderWidth = 80
+
+// newReaderDialect returns the dialect for --format reader: plain text
+// with underlined headings, wrapped paragraphs, and numbered link
+// references. The returned slice collects link targets in reference order.
+func newReaderDialect() (markupDialect, *[]string) {
+	refs := &[]string{}
+	numbers := make(map[string]int)
+
+	plain := func(text string) string { return text }
+
+	d := markupDialect{
+		heading: func(level int, text string) string {
+			switch level {
+			case 1:
+				return text + "\n" + strings.Repeat("=", utf8.RuneCountInString(text))
+			case 2:
+				return text + "\n" + strings.Repeat("-", utf8.RuneCountInString(text))
+			default:
+				return text
+			}
+		},
+		emphasis: plain,
+		strong:   plain,
+		code:     plain,
+		link: func(text, href string) string {
+			if text == "" || text == href {
+				return href
+			}
+			n, ok := numbers[href]
+			if !ok {
+				*refs = append(*refs, href)
+				n = len(*refs)
+				numbers[href] = n
+			}
+			return fmt.Sprintf("%s [%d]", text, n)
+		},
+		image: func(alt, src string) string {
+			if alt == "" {
+				return "[Image]"
+			}
+			return "[Image: " + alt + "]"
+		},
+		codeBlock: func(lang, code string) string {
+			return indentLines(code, "    ")
+		},
+		quote: func(body string) string {
+			lines := strings.Split(body, "\n")
+			for i, line := range lines {
+				lines[i] = strings.TrimRight("> "+line, " ")
+			}
+			return strings.Join(lines, "\n")
+		},
+		table: func(rows [][]string, header bool) string {
+			widths := columnWidths(rows)
+			var lines []string
+			for i, row := range rows {
+				cells := make([]string, len(row))
+				for j, cell := range row {
+					cells[j] = cell + strings.Repeat(" ", widths[j]-utf8.RuneCountInString(cell))
+				}
+				lines = append(lines, strings.TrimRight(strings.Join(cells, "  "), " "))
+				if i == 0 && header {
+					rules := make([]string, len(widths))
+					for j, w := range widths {
+						rules[j] = strings.Repeat("-", w)
+					}
+					lines = append(lines, strings.Join(rules, "  "))
+				}
+			}
+			return strings.Join(lines, "\n")
+		},
+		escape:        plain,
+		rule:          "* * *",
+		orderedMarker: func(n int) string { return fmt.Sprintf("%d. ", n) },
+		inlineImages:  true,
+		paragraph: func(text string) string {
+			return wrapText(text, ReaderWidth)
+		},
+	}
+
+	return d, refs
+}
+
+// convertHTMLToReader converts htmlContent to reader text, listing the
+// targets of numbered links at the end.
+func convertHTMLToReader(htmlContent string) (string, error) {
+	dialect, refs := newReaderDialect()
+
+	content, err := convertHTMLToMarkup(htmlContent, dialect)
+	if err != nil || len(*refs) == 0 {
+		return content, err
+	}
+
+	var b strings.Builder
+	b.WriteString(content)
+	b.WriteString("\nLinks\n-----\n\n")
+	for i, href := range *refs {
+		fmt.Fprintf(&b, "[%d] %s\n", i+1, href)
+	}
+	return b.String(), nil
+}
+
+// wrapText wraps each line of text at width columns, breaking between
+// words. Words longer than width are left on a line of their own.
+func wrapText(text string, width int) string {
+	var out []string
+	for _, line := range strings.Split(text, "\n") {
+		var current string
+		for _, word := range strings.Fields(line) {
+			switch {
+			case current == "":
+				current = word
+			case utf8.RuneCountInString(current)+1+utf8.RuneCountInString(word) > width:
+				out = append(out, current)
+				current = word
+			default:
+				current += " " + word
+			}
+		}
+		out = append(out, current)
+	}
+	return strings.Join(out, "\n")
+}
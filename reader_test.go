@@ -0,0 +1,67 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConvertHTMLToReader(t *testing.T) {
+	input := `<h1>Guide</h1>
+<p>Read the <a href="https://example.com/docs">docs</a> and the
+<a href="https://example.com/faq">FAQ</a>, then the <a href="https://example.com/docs">docs</a> again.
+See https://example.com or <a href="https://example.com">https://example.com</a>.</p>
+<h2>Setup</h2>
+<pre>go install</pre>`
+
+	got, err := convertHTMLToReader(input)
+	if err != nil {
+		t.Fatalf("convertHTMLToReader failed: %v", err)
+	}
+
+	want := `Guide
+=====
+
+Read the docs [1] and the FAQ [2], then the docs [1] again. See
+https://example.com or https://example.com.
+
+Setup
+-----
+
+    go install
+
+Links
+-----
+
+[1] https://example.com/docs
+[2] https://example.com/faq
+`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	long := strings.Repeat("x", 15)
+	tests := []struct {
+		text  string
+		width int
+		want  string
+	}{
+		{"one two three", 20, "one two three"},
+		{"one two three four", 9, "one two\nthree\nfour"},
+		{"short " + long + " end", 10, "short\n" + long + "\nend"},
+		{"line one\nline two", 20, "line one\nline two"},
+	}
+
+	for _, tt := range tests {
+		if got := wrapText(tt.text, tt.width); got != tt.want {
+			t.Errorf("wrapText(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+		}
+	}
+}
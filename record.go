@@ -0,0 +1,262 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+const (
+	DefaultRecordDuration = 10 * time.Second
+	MaxRecordDuration     = 5 * time.Minute
+	RecordMaxWidth        = 1280
+	RecordJPEGQuality     = 80
+	RecordScrollInterval  = 100 * time.Millisecond
+	RecordMinFrameDelay   = 20 * time.Millisecond
+	RecordFinalFrameHold  = time.Second
+)
+
+// recordedFrame is a single screencast frame and the time it was painted.
+type recordedFrame struct {
+	data []byte
+	at   time.Time
+}
+
+// ScreenRecorder collects screencast frames from a page so they can be
+// assembled into an animation.
+type ScreenRecorder struct {
+	page   *rod.Page
+	start  time.Time
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu     sync.Mutex
+	frames []recordedFrame
+}
+
+// StartScreenRecorder begins a screencast on page. Call Finish to stop
+// recording and write the animation.
+func StartScreenRecorder(page *rod.Page) (*ScreenRecorder, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sr := &ScreenRecorder{
+		page:   page,
+		start:  time.Now(),
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	wait := page.Context(ctx).EachEvent(func(e *proto.PageScreencastFrame) {
+		at := time.Now()
+		if e.Metadata != nil && e.Metadata.Timestamp != 0 {
+			at = e.Metadata.Timestamp.Time()
+		}
+
+		sr.mu.Lock()
+		sr.frames = append(sr.frames, recordedFrame{data: e.Data, at: at})
+		sr.mu.Unlock()
+
+		// Chromium stops sending frames until each one is acknowledged
+		if err := (proto.PageScreencastFrameAck{SessionID: e.SessionID}).Call(page); err != nil {
+			logger.Debug("Failed to acknowledge screencast frame: %v", err)
+		}
+	})
+
+	go func() {
+		defer close(sr.done)
+		wait()
+	}()
+
+	quality := RecordJPEGQuality
+	maxWidth := RecordMaxWidth
+	err := proto.PageStartScreencast{
+		Format:   proto.PageStartScreencastFormatJpeg,
+		Quality:  &quality,
+		MaxWidth: &maxWidth,
+	}.Call(page)
+	if err != nil {
+		cancel()
+		<-sr.done
+		return nil, fmt.Errorf("failed to start screencast: %w", err)
+	}
+
+	logger.Verbose("Recording screencast...")
+	return sr, nil
+}
+
+// Finish keeps recording while scrolling through the page until duration has
+// elapsed since the recording started, then writes the animation to path.
+func (sr *ScreenRecorder) Finish(path string, duration time.Duration) error {
+	if remaining := duration - time.Since(sr.start); remaining > 0 {
+		logger.Verbose("Scrolling page for %s...", remaining.Round(time.Millisecond))
+		autoScroll(sr.page, remaining)
+	}
+
+	if err := (proto.PageStopScreencast{}).Call(sr.page); err != nil {
+		logger.Debug("Failed to stop screencast: %v", err)
+	}
+	sr.cancel()
+	<-sr.done
+
+	sr.mu.Lock()
+	frames := sr.frames
+	sr.mu.Unlock()
+
+	if len(frames) == 0 {
+		return fmt.Errorf("no screencast frames were captured")
+	}
+
+	logger.Verbose("Encoding %d frames...", len(frames))
+
+	var data []byte
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".webp":
+		data, err = encodeWebP(frames)
+	default:
+		data, err = encodeGIF(frames)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, data, DefaultFileMode); err != nil {
+		return fmt.Errorf("failed to write recording %s: %w", path, err)
+	}
+
+	sizeKB := float64(len(data)) / BytesPerKB
+	logger.Success("Saved recording to %s (%d frames, %.1f KB)", path, len(frames), sizeKB)
+	return nil
+}
+
+// autoScroll scrolls steadily from the current position to the bottom of the
+// page over the given duration so the recording shows the whole page.
+func autoScroll(page *rod.Page, duration time.Duration) {
+	steps := int(duration / RecordScrollInterval)
+	if steps < 1 {
+		steps = 1
+	}
+
+	res, err := page.Eval(`() => Math.max(0, document.documentElement.scrollHeight - window.innerHeight - window.scrollY)`)
+	if err != nil {
+		logger.Debug("Failed to get scrollable height: %v", err)
+		time.Sleep(duration)
+		return
+	}
+	step := res.Value.Num() / float64(steps)
+
+	for i := 0; i < steps; i++ {
+		if step > 0 {
+			if _, err := page.Eval(`(dy) => window.scrollBy(0, dy)`, step); err != nil {
+				logger.Debug("Failed to scroll: %v", err)
+			}
+		}
+		time.Sleep(RecordScrollInterval)
+	}
+}
+
+// frameDelays returns how long each frame should be displayed. Chromium only
+// sends a frame when the page repaints, so each delay is the gap until the
+// next frame. The last frame is held so the animation does not loop abruptly.
+func frameDelays(frames []recordedFrame) []time.Duration {
+	delays := make([]time.Duration, len(frames))
+	for i := range frames {
+		if i == len(frames)-1 {
+			delays[i] = RecordFinalFrameHold
+			break
+		}
+		delays[i] = max(frames[i+1].at.Sub(frames[i].at), RecordMinFrameDelay)
+	}
+	return delays
+}
+
+// encodeGIF assembles JPEG frames into a looping animated GIF.
+func encodeGIF(frames []recordedFrame) ([]byte, error) {
+	anim := &gif.GIF{}
+	delays := frameDelays(frames)
+
+	for i, frame := range frames {
+		img, err := jpeg.Decode(bytes.NewReader(frame.data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode frame %d: %w", i, err)
+		}
+
+		paletted := image.NewPaletted(img.Bounds(), palette.Plan9)
+		draw.FloydSteinberg.Draw(paletted, img.Bounds(), img, img.Bounds().Min)
+
+		anim.Image = append(anim.Image, paletted)
+		// GIF delays are in hundredths of a second
+		anim.Delay = append(anim.Delay, int(delays[i]/(10*time.Millisecond)))
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		return nil, fmt.Errorf("failed to encode GIF: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// encodeWebP assembles JPEG frames into an animated WebP. The Go standard
+// library has no WebP encoder, so this uses img2webp from libwebp.
+func encodeWebP(frames []recordedFrame) ([]byte, error) {
+	toolPath, err := exec.LookPath("img2webp")
+	if err != nil {
+		return nil, fmt.Errorf("img2webp not found in PATH (install libwebp, or record to .gif)")
+	}
+	logger.Debug("Using img2webp at: %s", toolPath)
+
+	tmpDir, err := os.MkdirTemp("", "snag-record-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	delays := frameDelays(frames)
+	args := []string{"-loop", "0", "-lossy"}
+
+	for i, frame := range frames {
+		framePath := filepath.Join(tmpDir, fmt.Sprintf("frame-%05d.jpg", i))
+		if err := os.WriteFile(framePath, frame.data, DefaultFileMode); err != nil {
+			return nil, fmt.Errorf("failed to write frame %d: %w", i, err)
+		}
+		args = append(args, "-d", strconv.FormatInt(delays[i].Milliseconds(), 10), framePath)
+	}
+
+	outPath := filepath.Join(tmpDir, "out.webp")
+	args = append(args, "-o", outPath)
+
+	cmd := exec.Command(toolPath, args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		logger.Debug("img2webp output: %s", string(output))
+		return nil, fmt.Errorf("img2webp encoding failed: %w", err)
+	}
+
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encoded WebP: %w", err)
+	}
+
+	return data, nil
+}
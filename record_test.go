@@ -0,0 +1,80 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"testing"
+	"time"
+)
+
+func jpegFrame(t *testing.T, c color.Color, at time.Time) recordedFrame {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 8, 6))
+	for y := 0; y < 6; y++ {
+		for x := 0; x < 8; x++ {
+			img.Set(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("failed to encode test frame: %v", err)
+	}
+	return recordedFrame{data: buf.Bytes(), at: at}
+}
+
+func TestFrameDelays(t *testing.T) {
+	start := time.Now()
+	frames := []recordedFrame{
+		{at: start},
+		{at: start.Add(250 * time.Millisecond)},
+		{at: start.Add(255 * time.Millisecond)},
+	}
+
+	delays := frameDelays(frames)
+
+	want := []time.Duration{250 * time.Millisecond, RecordMinFrameDelay, RecordFinalFrameHold}
+	for i := range want {
+		if delays[i] != want[i] {
+			t.Errorf("delay[%d] = %s, want %s", i, delays[i], want[i])
+		}
+	}
+}
+
+func TestEncodeGIF(t *testing.T) {
+	start := time.Now()
+	frames := []recordedFrame{
+		jpegFrame(t, color.White, start),
+		jpegFrame(t, color.Black, start.Add(500*time.Millisecond)),
+	}
+
+	data, err := encodeGIF(frames)
+	if err != nil {
+		t.Fatalf("encodeGIF failed: %v", err)
+	}
+
+	anim, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to decode GIF: %v", err)
+	}
+
+	if len(anim.Image) != 2 {
+		t.Fatalf("expected 2 frames, got %d", len(anim.Image))
+	}
+	if anim.Delay[0] != 50 || anim.Delay[1] != 100 {
+		t.Errorf("unexpected delays: %v", anim.Delay)
+	}
+	if b := anim.Image[0].Bounds(); b.Dx() != 8 || b.Dy() != 6 {
+		t.Errorf("unexpected frame size: %v", b)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// RedactedValue replaces secrets masked by --redact-urls and --redact.
+const RedactedValue = "REDACTED"
+
+// redactPatterns are the compiled --redact and --redact-file expressions
+// masked in converted content before it is written.
+var redactPatterns []*regexp.Regexp
+
+// urlPattern finds absolute URLs embedded in free text such as log lines
+// and error messages.
+var urlPattern = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^\s"'<>]+`)
+
+// redactURL masks the userinfo and query values of rawURL, keeping query
+// keys so redacted URLs can still be told apart. Strings that do not parse
+// as URLs are returned unchanged.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	changed := false
+	if u.User != nil {
+		u.User = url.User(RedactedValue)
+		changed = true
+	}
+
+	if u.RawQuery != "" {
+		var params []string
+		for _, param := range strings.Split(u.RawQuery, "&") {
+			key, _, _ := strings.Cut(param, "=")
+			params = append(params, key+"="+RedactedValue)
+		}
+		u.RawQuery = strings.Join(params, "&")
+		changed = true
+	}
+
+	if !changed {
+		return rawURL
+	}
+	return u.String()
+}
+
+// redactText masks every URL in text with redactURL. Trailing punctuation
+// is left outside the URL so sentences stay intact.
+func redactText(text string) string {
+	return urlPattern.ReplaceAllStringFunc(text, func(match string) string {
+		trimmed := strings.TrimRight(match, ".,;:)]")
+		return redactURL(trimmed) + match[len(trimmed):]
+	})
+}
+
+// redactIfEnabled applies redactText when --redact-urls is set.
+func redactIfEnabled(text string) string {
+	if !redactURLs {
+		return text
+	}
+	return redactText(text)
+}
+
+// redactEachIfEnabled applies redactIfEnabled to every string in texts,
+// returning a new slice.
+func redactEachIfEnabled(texts []string) []string {
+	redacted := make([]string, len(texts))
+	for i, text := range texts {
+		redacted[i] = redactIfEnabled(text)
+	}
+	return redacted
+}
+
+// loadRedactPatterns compiles the --redact expressions followed by those in
+// file, one per line. Blank lines and lines starting with # are skipped.
+func loadRedactPatterns(patterns []string, file string) ([]*regexp.Regexp, error) {
+	if file != "" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			patterns = append(patterns, line)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// redactContent masks every match of the --redact patterns in content.
+func redactContent(content string) string {
+	if len(redactPatterns) == 0 {
+		return content
+	}
+
+	masked := 0
+	for _, re := range redactPatterns {
+		content = re.ReplaceAllStringFunc(content, func(string) string {
+			masked++
+			return RedactedValue
+		})
+	}
+	if masked > 0 {
+		logger.Verbose("Redacted %d occurrence%s", masked, plural(masked))
+	}
+	return content
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+const (
+	// RunDirFormat names --run-dir directories after the time the run
+	// started, matching the prefix of generated filenames.
+	RunDirFormat = "2006-01-02-150405"
+
+	// RunManifestName is the file listing a run's URLs and files, written
+	// inside its --run-dir directory when the run ends.
+	RunManifestName = "manifest.json"
+)
+
+// RunManifest records one --run-dir run. Files are the paths written to
+// the run directory, relative to it.
+type RunManifest struct {
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+	URLs     []string  `json:"urls"`
+	Files    []string  `json:"files"`
+}
+
+// createRunDir creates a new directory for one run under parent, named
+// after started. Runs started in the same second get a numbered suffix, so
+// no two runs share a directory.
+func createRunDir(parent string, started time.Time) (string, error) {
+	if err := validateDirectory(parent); err != nil {
+		return "", err
+	}
+
+	name := started.Format(RunDirFormat)
+	for n := 1; ; n++ {
+		dir := filepath.Join(parent, name)
+		if n > 1 {
+			dir = fmt.Sprintf("%s-%d", dir, n)
+		}
+		err := os.Mkdir(dir, 0755)
+		if err == nil {
+			logger.Verbose("Saving run to %s", dir)
+			return dir, nil
+		}
+		if !os.IsExist(err) {
+			return "", fmt.Errorf("failed to create run directory: %w", err)
+		}
+	}
+}
+
+// writeRunManifest lists every file in the run directory dir, which only
+// this run writes to, in m and saves it there. With --redact-urls the URLs
+// are redacted.
+func writeRunManifest(dir string, m *RunManifest) error {
+	m.Files = []string{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if rel != RunManifestName {
+			m.Files = append(m.Files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list run directory: %w", err)
+	}
+	slices.Sort(m.Files)
+	// Also turns nil URLs into an empty list
+	m.URLs = redactEachIfEnabled(m.URLs)
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode run manifest: %w", err)
+	}
+	path := filepath.Join(dir, RunManifestName)
+	if err := os.WriteFile(path, append(data, '\n'), DefaultFileMode); err != nil {
+		return fmt.Errorf("failed to write run manifest: %w", err)
+	}
+	logger.Verbose("Saved run manifest to %s", path)
+	return nil
+}
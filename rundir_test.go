@@ -0,0 +1,124 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreateRunDir(t *testing.T) {
+	logger = NewLogger(LevelQuiet)
+	parent := t.TempDir()
+	started := time.Date(2025, 10, 22, 14, 20, 33, 0, time.UTC)
+
+	first, err := createRunDir(parent, started)
+	assertNoError(t, err)
+	if want := filepath.Join(parent, "2025-10-22-142033"); first != want {
+		t.Errorf("createRunDir() = %q, want %q", first, want)
+	}
+
+	// A second run in the same second gets its own directory
+	second, err := createRunDir(parent, started)
+	assertNoError(t, err)
+	if want := filepath.Join(parent, "2025-10-22-142033-2"); second != want {
+		t.Errorf("createRunDir() = %q, want %q", second, want)
+	}
+
+	_, err = createRunDir(filepath.Join(parent, "missing"), started)
+	assertError(t, err)
+}
+
+func TestWriteRunManifest(t *testing.T) {
+	logger = NewLogger(LevelQuiet)
+	dir := t.TempDir()
+	for _, name := range []string{"b.md", "a.md", filepath.Join("assets", "logo.png")} {
+		path := filepath.Join(dir, name)
+		assertNoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assertNoError(t, os.WriteFile(path, []byte("x"), DefaultFileMode))
+	}
+
+	started := time.Now()
+	err := writeRunManifest(dir, &RunManifest{Started: started, Finished: started, URLs: []string{"https://example.com"}})
+	assertNoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(dir, RunManifestName))
+	assertNoError(t, err)
+	var m RunManifest
+	assertNoError(t, json.Unmarshal(data, &m))
+	if want := []string{"a.md", "assets/logo.png", "b.md"}; !slices.Equal(m.Files, want) {
+		t.Errorf("Files = %v, want %v", m.Files, want)
+	}
+	if !slices.Equal(m.URLs, []string{"https://example.com"}) {
+		t.Errorf("URLs = %v", m.URLs)
+	}
+
+	// Writing it again does not list the manifest itself
+	assertNoError(t, writeRunManifest(dir, &RunManifest{}))
+	data, err = os.ReadFile(filepath.Join(dir, RunManifestName))
+	assertNoError(t, err)
+	assertNoError(t, json.Unmarshal(data, &m))
+	if len(m.Files) != 3 {
+		t.Errorf("Files = %v, want 3 files", m.Files)
+	}
+}
+
+func TestWriteRunManifest_RedactURLs(t *testing.T) {
+	logger = NewLogger(LevelQuiet)
+	redactURLs = true
+	defer func() { redactURLs = false }()
+
+	dir := t.TempDir()
+	assertNoError(t, writeRunManifest(dir, &RunManifest{URLs: []string{"https://example.com/?token=secret"}}))
+
+	data, err := os.ReadFile(filepath.Join(dir, RunManifestName))
+	assertNoError(t, err)
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("manifest not redacted:\n%s", data)
+	}
+	assertContains(t, string(data), "token="+RedactedValue)
+}
+
+func TestCLI_RunDirRequiresOutputDir(t *testing.T) {
+	_, stderr, err := runSnag("--run-dir", "https://example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "--run-dir requires --output-dir")
+}
+
+func TestBrowser_RunDir(t *testing.T) {
+	if !isBrowserAvailable() {
+		t.Skip("Browser not available, skipping browser integration test")
+	}
+
+	server := startTestServer(t)
+	dir := t.TempDir()
+
+	for range 2 {
+		_, _, err := runSnag("--run-dir", "-d", dir, server.URL+"/simple.html", server.URL+"/complex.html")
+		assertNoError(t, err)
+	}
+
+	runs, err := os.ReadDir(dir)
+	assertNoError(t, err)
+	if len(runs) != 2 {
+		t.Fatalf("expected 2 run directories, got %d", len(runs))
+	}
+	for _, run := range runs {
+		data, err := os.ReadFile(filepath.Join(dir, run.Name(), RunManifestName))
+		assertNoError(t, err)
+		var m RunManifest
+		assertNoError(t, json.Unmarshal(data, &m))
+		if len(m.URLs) != 2 || len(m.Files) != 2 {
+			t.Errorf("%s manifest has %d URLs and %d files, want 2 and 2", run.Name(), len(m.URLs), len(m.Files))
+		}
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// unsafeElements are removed with their content by --sanitize: they run
+// code, embed other documents, or change how the page's URLs resolve.
+var unsafeElements = map[string]bool{
+	"script":   true,
+	"noscript": true,
+	"iframe":   true,
+	"frame":    true,
+	"frameset": true,
+	"object":   true,
+	"embed":    true,
+	"applet":   true,
+	"portal":   true,
+	"base":     true,
+}
+
+// unsafeLinkRels are <link rel> values that fetch or run resources ahead of
+// use. Stylesheets and icons are kept.
+var unsafeLinkRels = map[string]bool{
+	"preload":       true,
+	"modulepreload": true,
+	"prefetch":      true,
+	"prerender":     true,
+	"preconnect":    true,
+	"dns-prefetch":  true,
+	"import":        true,
+}
+
+// trackerHosts are analytics and advertising domains. Elements loading from
+// them or their subdomains are removed by --sanitize.
+var trackerHosts = []string{
+	"google-analytics.com",
+	"googletagmanager.com",
+	"googleadservices.com",
+	"googlesyndication.com",
+	"doubleclick.net",
+	"connect.facebook.net",
+	"analytics.twitter.com",
+	"bat.bing.com",
+	"clarity.ms",
+	"hotjar.com",
+	"segment.com",
+	"segment.io",
+	"mixpanel.com",
+	"scorecardresearch.com",
+	"quantserve.com",
+	"hs-analytics.net",
+	"nr-data.net",
+}
+
+// sanitizeHTML strips scripts, event handlers, and trackers from
+// htmlContent so it can be embedded or served again without running the
+// original site's code. Markup, styles, and ordinary links and images are
+// kept.
+func sanitizeHTML(htmlContent string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return "", err
+	}
+
+	var remove []*html.Node
+	attributes := 0
+	for n := range doc.Descendants() {
+		if n.Type != html.ElementNode {
+			continue
+		}
+		if unsafeElement(n) {
+			remove = append(remove, n)
+			continue
+		}
+
+		kept := n.Attr[:0]
+		for _, a := range n.Attr {
+			if unsafeAttribute(a) {
+				attributes++
+				continue
+			}
+			kept = append(kept, a)
+		}
+		n.Attr = kept
+	}
+
+	for _, n := range remove {
+		if n.Parent != nil {
+			n.Parent.RemoveChild(n)
+		}
+	}
+
+	if len(remove) == 0 && attributes == 0 {
+		return htmlContent, nil
+	}
+	logger.Verbose("Sanitized HTML: removed %d element%s and %d attribute%s",
+		len(remove), plural(len(remove)), attributes, plural(attributes))
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, doc); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// unsafeElement reports whether --sanitize removes element n.
+func unsafeElement(n *html.Node) bool {
+	if unsafeElements[n.Data] {
+		return true
+	}
+
+	switch n.Data {
+	case "link":
+		for _, rel := range strings.Fields(strings.ToLower(attr(n, "rel"))) {
+			if unsafeLinkRels[rel] {
+				return true
+			}
+		}
+	case "meta":
+		switch strings.ToLower(attr(n, "http-equiv")) {
+		case "refresh", "set-cookie":
+			return true
+		}
+	case "img":
+		if trackingPixel(n) {
+			return true
+		}
+	}
+
+	// Links to tracker sites are kept; only what the page loads from them goes
+	if src := attr(n, "src"); src != "" && isTrackerURL(src) {
+		return true
+	}
+	return n.Data == "link" && isTrackerURL(attr(n, "href"))
+}
+
+// unsafeAttribute reports whether --sanitize removes attribute a: event
+// handlers, inline documents, pings, and script URLs.
+func unsafeAttribute(a html.Attribute) bool {
+	key := strings.ToLower(a.Key)
+	switch {
+	case strings.HasPrefix(key, "on"):
+		return true
+	case key == "srcdoc" || key == "ping":
+		return true
+	case urlAttributes[key]:
+		return isScriptURL(a.Val)
+	}
+	return false
+}
+
+// isScriptURL reports whether ref runs code when followed or loaded.
+func isScriptURL(ref string) bool {
+	// Browsers ignore whitespace and control characters in the scheme
+	ref = strings.Map(func(r rune) rune {
+		if r <= ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(ref))
+
+	return strings.HasPrefix(ref, "javascript:") ||
+		strings.HasPrefix(ref, "vbscript:") ||
+		strings.HasPrefix(ref, "data:text/html")
+}
+
+// isTrackerURL reports whether ref loads from one of trackerHosts.
+func isTrackerURL(ref string) bool {
+	u, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil || u.Host == "" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, tracker := range trackerHosts {
+		if host == tracker || strings.HasSuffix(host, "."+tracker) {
+			return true
+		}
+	}
+	return false
+}
+
+// trackingPixel reports whether img is a 1x1 (or smaller) image, the usual
+// shape of a tracking beacon.
+func trackingPixel(img *html.Node) bool {
+	width, errW := strconv.Atoi(strings.TrimSpace(attr(img, "width")))
+	height, errH := strconv.Atoi(strings.TrimSpace(attr(img, "height")))
+	return errW == nil && errH == nil && width <= 1 && height <= 1
+}
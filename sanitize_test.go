@@ -0,0 +1,90 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeHTML(t *testing.T) {
+	input := `<html><head>
+<title>Docs</title>
+<meta http-equiv="refresh" content="0; url=https://evil.example/">
+<link rel="stylesheet" href="/site.css">
+<link rel="preload" href="/app.js" as="script">
+<base href="https://evil.example/">
+<script>alert(1)</script>
+<script async src="https://www.googletagmanager.com/gtag/js?id=G-1"></script>
+</head><body onload="init()">
+<h1 class="title" onclick="track()">Docs</h1>
+<p><a href="javascript:alert(1)">Bad</a> <a href=" JaVa&#x09;script:alert(1)">Obfuscated</a> <a href="/guide" ping="https://t.example/">Guide</a></p>
+<p><a href="https://marketingplatform.google.com/about/analytics/">About Analytics</a></p>
+<img src="/logo.png" alt="Logo" onerror="steal()">
+<img src="https://t.example/pixel.gif" width="1" height="1">
+<img src="https://stats.g.doubleclick.net/x.gif" alt="">
+<iframe srcdoc="<script>alert(1)</script>"></iframe>
+<noscript><img src="https://www.facebook.com/tr?id=1" width="1" height="1"></noscript>
+<svg><a href="javascript:alert(1)"><text>svg</text></a></svg>
+</body></html>`
+
+	got, err := sanitizeHTML(input)
+	if err != nil {
+		t.Fatalf("sanitizeHTML failed: %v", err)
+	}
+
+	for _, want := range []string{
+		`<title>Docs</title>`,
+		`<link rel="stylesheet" href="/site.css"/>`,
+		`<h1 class="title">Docs</h1>`,
+		`<a href="/guide">Guide</a>`,
+		`<a href="https://marketingplatform.google.com/about/analytics/">About Analytics</a>`,
+		`<img src="/logo.png" alt="Logo"/>`,
+		`<a>Bad</a>`,
+		`<a>Obfuscated</a>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %s in:\n%s", want, got)
+		}
+	}
+
+	for _, unwanted := range []string{
+		"<script", "alert(1)", "googletagmanager", "refresh", "preload", "<base",
+		"onload", "onclick", "onerror", "ping=", "javascript:", "pixel.gif",
+		"doubleclick", "<iframe", "<noscript", "facebook.com/tr",
+	} {
+		if strings.Contains(strings.ToLower(got), strings.ToLower(unwanted)) {
+			t.Errorf("expected %s to be removed from:\n%s", unwanted, got)
+		}
+	}
+}
+
+func TestSanitizeHTML_Unchanged(t *testing.T) {
+	input := `<p>Plain <a href="https://example.com">content</a></p>`
+
+	got, err := sanitizeHTML(input)
+	if err != nil {
+		t.Fatalf("sanitizeHTML failed: %v", err)
+	}
+	if got != input {
+		t.Errorf("expected safe HTML to be returned as is, got:\n%s", got)
+	}
+}
+
+func TestCLI_ConvertSanitize(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "page.html")
+	os.WriteFile(input, []byte(`<h1 onclick="x()">Docs</h1><script>alert(1)</script>`), 0644)
+
+	stdout, _, err := runSnag("convert", input, "-f", "html", "--sanitize")
+	if err != nil {
+		t.Fatalf("convert failed: %v", err)
+	}
+	assertContains(t, stdout, "<h1>Docs</h1>")
+	assertNotContains(t, stdout, "alert(1)")
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"math"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// MaxScreenshotTileHeight is the tallest region captured in a single CDP
+// call. Chromium's compositor texture limit is 16384px and many GPUs are
+// lower, so pages taller than this are captured in tiles and stitched.
+const MaxScreenshotTileHeight = 8192
+
+// getContentSize returns the full CSS content size of the page.
+func getContentSize(page *rod.Page) (float64, float64, error) {
+	metrics, err := proto.PageGetLayoutMetrics{}.Call(page)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get layout metrics: %w", err)
+	}
+
+	if metrics.CSSContentSize == nil {
+		return 0, 0, fmt.Errorf("failed to get css content size")
+	}
+
+	return metrics.CSSContentSize.Width, metrics.CSSContentSize.Height, nil
+}
+
+// captureTiledScreenshot captures a width x height region from the top of the
+// page in tiles of at most MaxScreenshotTileHeight and stitches them into a
+// single PNG.
+func captureTiledScreenshot(page *rod.Page, width, height float64) ([]byte, error) {
+	return captureTiledRegion(page, 0, 0, width, height)
+}
+
+// captureTiledRegion captures the width x height region at (x, y) of the
+// page, in tiles like captureTiledScreenshot.
+func captureTiledRegion(page *rod.Page, x, y, width, height float64) ([]byte, error) {
+	var tiles []image.Image
+
+	for offset := 0.0; offset < height; offset += MaxScreenshotTileHeight {
+		tileHeight := math.Min(MaxScreenshotTileHeight, height-offset)
+		logger.Debug("Capturing screenshot tile at y=%.0f (height %.0f)", y+offset, tileHeight)
+
+		shot, err := proto.PageCaptureScreenshot{
+			Format: proto.PageCaptureScreenshotFormatPng,
+			Clip: &proto.PageViewport{
+				X:      x,
+				Y:      y + offset,
+				Width:  width,
+				Height: tileHeight,
+				Scale:  1,
+			},
+			CaptureBeyondViewport: true,
+		}.Call(page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to capture tile at y=%.0f: %w", offset, err)
+		}
+
+		tile, err := png.Decode(bytes.NewReader(shot.Data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode tile at y=%.0f: %w", offset, err)
+		}
+		tiles = append(tiles, tile)
+	}
+
+	logger.Verbose("Stitching %d screenshot tiles...", len(tiles))
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, stitchImagesVertically(tiles)); err != nil {
+		return nil, fmt.Errorf("failed to encode stitched screenshot: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// elementBoxScript returns the box of the first element matching selector,
+// relative to the top of the page, or null when nothing matches.
+const elementBoxScript = `(selector) => {
+	const el = document.querySelector(selector);
+	if (!el) return null;
+	el.scrollIntoView({block: 'nearest'});
+	const rect = el.getBoundingClientRect();
+	return {x: rect.left + window.scrollX, y: rect.top + window.scrollY, width: rect.width, height: rect.height};
+}`
+
+// captureElementScreenshot captures the element matching selector, in full
+// even where it extends beyond the viewport. A maxHeight above zero limits
+// the height of the image.
+func captureElementScreenshot(page *rod.Page, selector string, maxHeight int) ([]byte, error) {
+	logger.Verbose("Capturing element %s...", selector)
+
+	res, err := page.Eval(elementBoxScript, selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector %s: %w", selector, err)
+	}
+	if res.Value.Nil() {
+		logger.Error("No element matches --screenshot-selector %s", selector)
+		logger.ErrorWithSuggestion(
+			"Check the selector in the browser's developer tools, or wait for it to render",
+			fmt.Sprintf("snag -f png --wait-for '%s' --screenshot-selector '%s' <url>", selector, selector),
+		)
+		return nil, fmt.Errorf("%w: %s", ErrSelectorNotFound, selector)
+	}
+
+	x, y := res.Value.Get("x").Num(), res.Value.Get("y").Num()
+	width, height := math.Ceil(res.Value.Get("width").Num()), math.Ceil(res.Value.Get("height").Num())
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("element %s has no size (is it hidden?)", selector)
+	}
+	if maxHeight > 0 && height > float64(maxHeight) {
+		logger.Warning("Element height %.0fpx exceeds --max-height, truncating screenshot to %dpx", height, maxHeight)
+		height = float64(maxHeight)
+	}
+
+	return captureTiledRegion(page, x, y, width, height)
+}
+
+// setScreenshotViewport resizes the page's viewport to width x height CSS
+// pixels for --viewport, and returns a func that restores it.
+func setScreenshotViewport(page *rod.Page, width, height int) (func(), error) {
+	logger.Verbose("Setting viewport to %dx%d...", width, height)
+	err := page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{
+		Width:             width,
+		Height:            height,
+		DeviceScaleFactor: 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set viewport: %w", err)
+	}
+
+	// Responsive layouts and lazy content settle after a resize
+	if err := page.WaitStable(stabilizeTimeout()); err != nil {
+		logger.Debug("Page did not stabilize after resize: %v", err)
+	}
+
+	return func() {
+		if err := (proto.EmulationClearDeviceMetricsOverride{}).Call(page); err != nil {
+			logger.Debug("Failed to restore viewport: %v", err)
+		}
+	}, nil
+}
+
+// stitchImagesVertically stacks images top to bottom. The result is as wide
+// as the widest image.
+func stitchImagesVertically(images []image.Image) *image.RGBA {
+	width, height := 0, 0
+	for _, img := range images {
+		width = max(width, img.Bounds().Dx())
+		height += img.Bounds().Dy()
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	y := 0
+	for _, img := range images {
+		b := img.Bounds()
+		dst := image.Rect(0, y, b.Dx(), y+b.Dy())
+		draw.Draw(canvas, dst, img, b.Min, draw.Src)
+		y += b.Dy()
+	}
+
+	return canvas
+}
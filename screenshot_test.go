@@ -0,0 +1,101 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func solidImage(w, h int, c color.Color) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestStitchImagesVertically(t *testing.T) {
+	red := color.RGBA{255, 0, 0, 255}
+	blue := color.RGBA{0, 0, 255, 255}
+
+	result := stitchImagesVertically([]image.Image{
+		solidImage(10, 5, red),
+		solidImage(8, 3, blue),
+	})
+
+	if got := result.Bounds().Dx(); got != 10 {
+		t.Errorf("expected width 10, got %d", got)
+	}
+	if got := result.Bounds().Dy(); got != 8 {
+		t.Errorf("expected height 8, got %d", got)
+	}
+	if got := result.RGBAAt(0, 4); got != red {
+		t.Errorf("expected red at (0,4), got %v", got)
+	}
+	if got := result.RGBAAt(0, 5); got != blue {
+		t.Errorf("expected blue at (0,5), got %v", got)
+	}
+	if got := result.RGBAAt(9, 6); got.A != 0 {
+		t.Errorf("expected transparent padding at (9,6), got %v", got)
+	}
+}
+
+func TestStitchImagesVertically_Empty(t *testing.T) {
+	result := stitchImagesVertically(nil)
+	if !result.Bounds().Empty() {
+		t.Errorf("expected empty image, got bounds %v", result.Bounds())
+	}
+}
+
+func TestBrowser_ScreenshotSizeControls(t *testing.T) {
+	if !isBrowserAvailable() {
+		t.Skip("Browser not available, skipping browser integration test")
+	}
+
+	server := startTestServer(t)
+	url := server.URL + "/complex.html"
+	dir := t.TempDir()
+
+	decode := func(path string) image.Image {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", path, err)
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("failed to decode %s: %v", path, err)
+		}
+		return img
+	}
+
+	viewport := filepath.Join(dir, "viewport.png")
+	_, _, err := runSnag("-f", "png", "--viewport", "400x300", "--screenshot-full-page=false", "-o", viewport, url)
+	assertNoError(t, err)
+	if b := decode(viewport).Bounds(); b.Dx() != 400 || b.Dy() != 300 {
+		t.Errorf("expected a 400x300 viewport capture, got %dx%d", b.Dx(), b.Dy())
+	}
+
+	element := filepath.Join(dir, "element.png")
+	_, _, err = runSnag("-f", "png", "--viewport", "400x300", "--screenshot-selector", "h1", "-o", element, url)
+	assertNoError(t, err)
+	if b := decode(element).Bounds(); b.Dx() > 400 || b.Dy() >= 300 {
+		t.Errorf("expected the h1 only, got %dx%d", b.Dx(), b.Dy())
+	}
+
+	_, stderr, err := runSnag("-f", "png", "--screenshot-selector", ".missing", "-o", element, url)
+	assertError(t, err)
+	assertContains(t, stderr, "No element matches --screenshot-selector .missing")
+}
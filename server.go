@@ -0,0 +1,507 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/spf13/cobra"
+)
+
+const (
+	DefaultListenAddr  = "127.0.0.1:8080"
+	DefaultJobWorkers  = 2
+	MaxJobRequestBytes = 1 << 20
+)
+
+var (
+	listenAddr string
+	jobWorkers int
+	poolSize   int
+	jobsDir    string
+	grpcListen string
+
+	// serveNoPrivate is --no-private-ips for snag serve, where it is on by
+	// default since job URLs come from clients.
+	serveNoPrivate bool
+
+	// serveJSEval is --allow-js-eval, off by default so clients cannot run
+	// scripts in the server's browser.
+	serveJSEval bool
+)
+
+const serveHelpTemplate = `USAGE:
+  snag serve [options]
+
+DESCRIPTION:
+  Run snag as an HTTP service backed by a single browser.
+
+  POST /fetch       Fetch {"url": "...", "format": "md", "wait_for": ".content"}
+                    and return the converted content as the response body
+  POST /jobs        Submit {"urls": [...], "format": "md", "wait_for": ".content"}
+                    Returns 202 with the job ID
+  GET  /jobs/{id}   Job status and the output file for each URL
+  GET  /metrics     Prometheus metrics
+
+  With --grpc-listen, the snag.v1.Snag gRPC service (Fetch, ListTabs, FetchTab,
+  Convert) is also served. See proto/snag/v1/snag.proto.
+
+EXAMPLES:
+  snag serve
+  snag serve --listen :8080 --workers 4 -d /srv/snag --jobs-dir /srv/snag/jobs
+  curl -d '{"url": "https://example.com"}' localhost:8080/fetch
+  curl -d '{"url": "https://example.com"}' localhost:8080/jobs
+  snag serve --grpc-listen 127.0.0.1:9090
+
+OPTIONS:
+      --listen string          Address to listen on (default "127.0.0.1:8080")
+      --workers int            Number of jobs processed concurrently (default 2)
+      --pool-size int          Number of tabs kept open for POST /fetch (default 4)
+      --jobs-dir string        Persist jobs to directory so they survive restarts
+      --grpc-listen string     Also serve the gRPC API on this address
+  -d, --output-dir string      Directory for job output files (default ".")
+  -p, --port int               Chromium/Chrome remote debugging port (default 9222)
+      --timeout int            Page load timeout in seconds (default 30)
+      --force-headless         Force headless mode even if the browser is running
+      --offline                Only contact the target URLs: no browser background traffic
+      --allow-host string      Only fetch from these hosts: example.com, *.example.com, IP, or CIDR (repeatable)
+      --block-host string      Never fetch from these hosts, e.g. 169.254.169.254 (repeatable)
+      --max-bytes string       Stop a page that downloads more than this, e.g. 50MB
+      --max-requests int       Stop a page that makes more than this many requests
+      --no-private-ips         Refuse hosts that resolve to private, loopback, or link-local addresses
+                               unless allowed by --allow-host (default true, disable with =false)
+      --allow-js-eval          Allow user-supplied JavaScript to run in the server's browser (audit logged)
+
+      --debug                  Enable debug output
+      --redact-urls            Mask query strings and credentials of URLs in logs
+      --verbose                Enable verbose logging output
+  -h, --help                   help for serve
+`
+
+var serveCmd = &cobra.Command{
+	Use:          "serve",
+	Short:        "Run snag as an HTTP service with an async job queue",
+	Args:         cobra.NoArgs,
+	RunE:         runServe,
+	SilenceUsage: true,
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&listenAddr, "listen", DefaultListenAddr, "Address to listen on")
+	serveCmd.Flags().IntVar(&jobWorkers, "workers", DefaultJobWorkers, "Number of jobs processed concurrently")
+	serveCmd.Flags().IntVar(&poolSize, "pool-size", DefaultPoolSize, "Number of tabs kept open for POST /fetch")
+	serveCmd.Flags().StringVar(&jobsDir, "jobs-dir", "", "Persist jobs to directory so they survive restarts")
+	serveCmd.Flags().StringVar(&grpcListen, "grpc-listen", "", "Also serve the gRPC API on this address")
+	serveCmd.Flags().StringVarP(&outputDir, "output-dir", "d", "", "Directory for job output files")
+	serveCmd.Flags().IntVarP(&port, "port", "p", 9222, "Chromium/Chrome remote debugging port")
+	serveCmd.Flags().IntVar(&timeout, "timeout", DefaultTimeout, "Page load timeout in seconds")
+	serveCmd.Flags().BoolVar(&forceHead, "force-headless", false, "Force headless mode even if the browser is running")
+	serveCmd.Flags().BoolVar(&offline, "offline", false, "Only contact the target URLs: no browser background traffic")
+	serveCmd.Flags().StringArrayVar(&allowHosts, "allow-host", nil, "Only fetch from these hosts: example.com, *.example.com, IP, or CIDR (repeatable)")
+	serveCmd.Flags().StringArrayVar(&blockHosts, "block-host", nil, "Never fetch from these hosts, e.g. 169.254.169.254 (repeatable)")
+	serveCmd.Flags().StringVar(&maxBytes, "max-bytes", "", "Stop a page that downloads more than this, e.g. 50MB")
+	serveCmd.Flags().IntVar(&maxRequests, "max-requests", 0, "Stop a page that makes more than this many requests")
+	serveCmd.Flags().BoolVar(&serveNoPrivate, "no-private-ips", true, "Refuse hosts that resolve to private, loopback, or link-local addresses unless allowed by --allow-host")
+	serveCmd.Flags().BoolVar(&serveJSEval, "allow-js-eval", false, "Allow user-supplied JavaScript to run in the server's browser (audit logged)")
+	serveCmd.Flags().BoolVar(&redactURLs, "redact-urls", false, "Mask query strings and credentials of URLs in logs")
+	serveCmd.Flags().BoolVar(&verbose, "verbose", false, "Enable verbose logging output")
+	serveCmd.Flags().BoolVar(&debug, "debug", false, "Enable debug output")
+
+	serveCmd.MarkFlagsMutuallyExclusive("verbose", "debug")
+	serveCmd.SetHelpTemplate(serveHelpTemplate)
+
+	rootCmd.AddCommand(serveCmd)
+}
+
+// Server exposes snag over HTTP.
+type Server struct {
+	bm        *BrowserManager
+	pool      *PagePool
+	queue     *JobQueue
+	outputDir string
+}
+
+// FetchRequest is the body of POST /fetch.
+type FetchRequest struct {
+	URL     string `json:"url"`
+	Format  string `json:"format,omitempty"`
+	WaitFor string `json:"wait_for,omitempty"`
+}
+
+// formatContentTypes are the Content-Type headers of POST /fetch responses.
+var formatContentTypes = map[string]string{
+	FormatMarkdown: "text/markdown; charset=utf-8",
+	FormatHTML:     "text/html; charset=utf-8",
+	FormatText:     "text/plain; charset=utf-8",
+	FormatReader:   "text/plain; charset=utf-8",
+	FormatOrg:      "text/org; charset=utf-8",
+	FormatRST:      "text/x-rst; charset=utf-8",
+	FormatNotebook: "application/x-ipynb+json",
+	FormatPDF:      "application/pdf",
+	FormatPNG:      "image/png",
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	level := LevelNormal
+	if debug {
+		level = LevelDebug
+	} else if verbose {
+		level = LevelVerbose
+	}
+	logger = NewLogger(level)
+
+	outDir := strings.TrimSpace(outputDir)
+	if outDir == "" {
+		outDir = "."
+	}
+
+	if err := validateTimeout(timeout); err != nil {
+		return err
+	}
+
+	if err := validatePort(port); err != nil {
+		return err
+	}
+
+	if err := validateDirectory(outDir); err != nil {
+		return err
+	}
+
+	if err := validateHostPatterns(allowHosts, blockHosts); err != nil {
+		return err
+	}
+
+	if err := validateResourceBudget(maxBytes, maxRequests); err != nil {
+		return err
+	}
+	noPrivateIPs = serveNoPrivate
+	allowJSEval = serveJSEval
+	if allowJSEval {
+		logger.Warning("--allow-js-eval: user-supplied JavaScript can run in the server's browser")
+	}
+	if !noPrivateIPs {
+		logger.Warning("--no-private-ips=false: jobs can fetch internal network addresses")
+	}
+
+	if jobWorkers < 1 {
+		logger.Error("Invalid --workers: %d", jobWorkers)
+		logger.ErrorWithSuggestion(
+			"Workers must be at least 1",
+			"snag serve --workers 2",
+		)
+		return fmt.Errorf("invalid workers: %d", jobWorkers)
+	}
+
+	if poolSize < 1 {
+		logger.Error("Invalid --pool-size: %d", poolSize)
+		logger.ErrorWithSuggestion(
+			"The pool needs at least 1 tab",
+			"snag serve --pool-size 4",
+		)
+		return fmt.Errorf("invalid pool size: %d", poolSize)
+	}
+
+	queue, err := NewJobQueue(strings.TrimSpace(jobsDir))
+	if err != nil {
+		logger.Error("%v", err)
+		return err
+	}
+
+	bm := NewBrowserManager(BrowserOptions{
+		Port:          port,
+		ForceHeadless: forceHead,
+		Offline:       offline,
+		RemoteOnly:    k8sPod != "",
+	})
+	browserMutex.Lock()
+	browserManager = bm
+	browserMutex.Unlock()
+	defer func() {
+		bm.Close()
+		browserMutex.Lock()
+		browserManager = nil
+		browserMutex.Unlock()
+	}()
+
+	if _, err := bm.Connect(); err != nil {
+		return err
+	}
+
+	if addr := strings.TrimSpace(grpcListen); addr != "" {
+		grpcServer, err := startGRPCServer(addr, bm)
+		if err != nil {
+			return err
+		}
+		defer grpcServer.Stop()
+	}
+
+	pool := NewPagePool(bm, poolSize)
+	defer pool.Close()
+
+	srv := &Server{bm: bm, pool: pool, queue: queue, outputDir: outDir}
+	queue.Start(jobWorkers, srv.runJob)
+
+	logger.Success("Listening on http://%s (%d worker%s, %d pooled tab%s)",
+		listenAddr, jobWorkers, plural(jobWorkers), poolSize, plural(poolSize))
+
+	httpServer := &http.Server{
+		Addr:              listenAddr,
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("Server failed: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// Handler returns the HTTP routes for the server.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /fetch", s.handleFetch)
+	mux.HandleFunc("POST /jobs", s.handleSubmitJob)
+	mux.HandleFunc("GET /jobs/{id}", s.handleGetJob)
+	mux.Handle("GET /metrics", metrics)
+	return mux
+}
+
+// handleFetch fetches one URL in a pooled tab and responds with the
+// converted content. The final URL after redirects is sent as the
+// Content-Location header.
+func (s *Server) handleFetch(w http.ResponseWriter, r *http.Request) {
+	var req FetchRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxJobRequestBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+
+	validated, err := remoteURL(req.URL)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	outputFormat, err := remoteFormat(req.Format)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := s.pool.Acquire(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	defer s.pool.Release(page)
+
+	fetcher := NewPageFetcher(page, timeout)
+	_, err = fetcher.Fetch(FetchOptions{
+		URL:     validated,
+		Timeout: timeout,
+		WaitFor: strings.TrimSpace(req.WaitFor),
+	})
+	if err != nil {
+		writeJSONError(w, fetchErrorStatus(err), err.Error())
+		return
+	}
+
+	info, err := page.Info()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("failed to get page info: %v", err))
+		return
+	}
+	data, err := pageContent(page, outputFormat)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", formatContentTypes[outputFormat])
+	w.Header().Set("Content-Location", info.URL)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(data); err != nil {
+		logger.Debug("Failed to write response: %v", err)
+	}
+}
+
+// fetchErrorStatus maps a page load error to an HTTP status.
+func fetchErrorStatus(err error) int {
+	if errors.Is(err, ErrHostBlocked) {
+		return http.StatusForbidden
+	}
+	if failureReason(err) == "timeout" {
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusBadGateway
+}
+
+// pageContent converts a loaded page to outputFormat.
+func pageContent(page *rod.Page, outputFormat string) ([]byte, error) {
+	converter := newPageConverter(outputFormat)
+	if outputFormat == FormatPDF || outputFormat == FormatPNG {
+		return converter.Render(page)
+	}
+
+	html, err := page.HTML()
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract HTML: %w", err)
+	}
+	content, err := converter.Convert(html)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(content), nil
+}
+
+func (s *Server) handleSubmitJob(w http.ResponseWriter, r *http.Request) {
+	var req JobRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxJobRequestBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+
+	job, err := s.queue.Submit(req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, ErrQueueFull) {
+			status = http.StatusServiceUnavailable
+		}
+		writeJSONError(w, status, err.Error())
+		return
+	}
+
+	w.Header().Set("Location", "/jobs/"+job.ID)
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+func (s *Server) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	job, err := s.queue.Get(r.PathValue("id"))
+	if err != nil {
+		writeJSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// runJob fetches every URL in a job, writing results to a per-job
+// subdirectory of the output directory.
+func (s *Server) runJob(id string) {
+	job, err := s.queue.Get(id)
+	if err != nil {
+		return
+	}
+
+	started := time.Now().UTC()
+	s.queue.Update(id, func(j *Job) {
+		j.Status = JobRunning
+		j.Started = &started
+	})
+	logger.Info("Running job %s", id)
+
+	jobDir := filepath.Join(s.outputDir, id)
+	if err := os.MkdirAll(jobDir, 0755); err != nil {
+		s.finishJob(id, []JobResult{{Error: err.Error()}}, JobFailed)
+		return
+	}
+
+	results := make([]JobResult, 0, len(job.URLs))
+	failures := 0
+	for _, u := range job.URLs {
+		path, err := fetchToFile(s.bm, u, job.Format, job.WaitFor, jobDir, "")
+		result := JobResult{URL: u, Output: path}
+		if err != nil {
+			result.Error = err.Error()
+			failures++
+		}
+		results = append(results, result)
+
+		s.queue.Update(id, func(j *Job) {
+			j.Results = append(j.Results, result)
+		})
+	}
+
+	status := JobDone
+	if failures == len(job.URLs) {
+		status = JobFailed
+	}
+	s.finishJob(id, nil, status)
+	logger.Info("Job %s %s: %d succeeded, %d failed", id, status, len(results)-failures, failures)
+}
+
+func (s *Server) finishJob(id string, extra []JobResult, status JobStatus) {
+	finished := time.Now().UTC()
+	s.queue.Update(id, func(j *Job) {
+		j.Results = append(j.Results, extra...)
+		j.Status = status
+		j.Finished = &finished
+	})
+}
+
+// fetchToFile loads url in a new tab of bm and saves it to dir, returning
+// the output path. The file is called name, or a name generated from the
+// page title when name is empty.
+func fetchToFile(bm *BrowserManager, url, outputFormat, selector, dir, name string) (string, error) {
+	page, err := bm.NewPage()
+	if err != nil {
+		return "", fmt.Errorf("failed to create page: %w", err)
+	}
+	defer bm.ClosePage(page)
+
+	fetcher := NewPageFetcher(page, timeout)
+	if _, err := fetcher.Fetch(FetchOptions{URL: url, Timeout: timeout, WaitFor: selector}); err != nil {
+		return "", err
+	}
+
+	var outputPath string
+	if name != "" {
+		outputPath, err = namedOutputPath(dir, name)
+	} else {
+		var info *proto.TargetTargetInfo
+		info, err = page.Info()
+		if err != nil {
+			return "", fmt.Errorf("failed to get page info: %w", err)
+		}
+		outputPath, err = generateOutputFilename(filenameTitle(page, info.Title, info.URL), url, outputFormat, time.Now(), dir)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err := processPageContent(page, outputFormat, outputPath); err != nil {
+		return "", err
+	}
+
+	return outputPath, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		logger.Debug("Failed to write response: %v", err)
+	}
+}
+
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
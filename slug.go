@@ -0,0 +1,170 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// slugLanguage is how --filename-lang turns titles in one language into
+// slugs: letters spelled out in ASCII, words left out (written as they are
+// transliterated), and scripts kept as they are because they have no
+// practical transliteration.
+type slugLanguage struct {
+	letters   map[rune]string
+	stopwords []string
+	native    []*unicode.RangeTable
+}
+
+// slugLetters transliterates letters of every language that do not come
+// apart into an ASCII letter and accents, plus Cyrillic and Greek.
+var slugLetters = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'ł': "l", 'đ': "d", 'ð': "d",
+	'þ': "th", 'ı': "i", 'ħ': "h", 'ŋ': "ng",
+
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya", 'є': "ye",
+	'і': "i", 'ї': "yi", 'ґ': "g",
+
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i",
+	'θ': "th", 'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x",
+	'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y",
+	'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+}
+
+// cjkScripts are kept in Chinese, Japanese, and Korean slugs. The
+// prolonged sound mark and iteration mark belong to no one script.
+var cjkScripts = []*unicode.RangeTable{
+	unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul,
+	{R16: []unicode.Range16{{Lo: 0x3005, Hi: 0x3005, Stride: 1}, {Lo: 0x30fc, Hi: 0x30fc, Stride: 1}}},
+}
+
+// slugLanguages are the --filename-lang languages.
+var slugLanguages = map[string]slugLanguage{
+	"en": {stopwords: []string{"a", "an", "and", "as", "at", "by", "for", "from", "in", "is", "of", "on", "or", "the", "to", "with"}},
+	"de": {
+		letters:   map[rune]string{'ä': "ae", 'ö': "oe", 'ü': "ue"},
+		stopwords: []string{"am", "an", "auf", "aus", "bei", "das", "dem", "den", "der", "des", "die", "ein", "eine", "einer", "eines", "fuer", "im", "in", "mit", "oder", "und", "von", "vom", "zu", "zum", "zur"},
+	},
+	"fr": {stopwords: []string{"a", "au", "aux", "d", "de", "des", "du", "en", "et", "l", "la", "le", "les", "ou", "par", "pour", "sur", "un", "une"}},
+	"es": {stopwords: []string{"a", "al", "con", "de", "del", "el", "en", "la", "las", "lo", "los", "o", "para", "por", "un", "una", "y"}},
+	"it": {stopwords: []string{"a", "al", "con", "da", "del", "della", "di", "e", "il", "in", "l", "la", "le", "lo", "per", "su", "un", "una"}},
+	"pt": {stopwords: []string{"a", "ao", "as", "com", "da", "das", "de", "do", "dos", "e", "em", "na", "no", "o", "os", "para", "por", "um", "uma"}},
+	"nl": {stopwords: []string{"de", "een", "en", "het", "in", "met", "of", "op", "te", "van", "voor"}},
+	"ru": {stopwords: []string{"dlya", "i", "iz", "k", "na", "o", "ob", "ot", "po", "s", "so", "u", "v", "vo"}},
+	"uk": {
+		letters:   map[rune]string{'г': "h", 'и': "y"},
+		stopwords: []string{"dlya", "i", "na", "po", "ta", "u", "v", "y", "z"},
+	},
+	"el": {stopwords: []string{"gia", "i", "kai", "me", "o", "se", "ta", "tis", "to", "ton", "tou"}},
+	"ja": {native: cjkScripts},
+	"zh": {native: cjkScripts},
+	"ko": {native: cjkScripts},
+}
+
+// slugLanguageNames lists the --filename-lang languages for messages.
+func slugLanguageNames() []string {
+	names := make([]string, 0, len(slugLanguages))
+	for name := range slugLanguages {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func normalizeFilenameLang(lang string) string {
+	return strings.ToLower(strings.TrimSpace(lang))
+}
+
+// TitleSlug slugifies a page or section title for a filename, in the
+// language set by --filename-lang.
+func TitleSlug(title string, maxLen int) string {
+	lang, ok := slugLanguages[normalizeFilenameLang(filenameLang)]
+	if !ok {
+		return SlugifyTitle(title, maxLen)
+	}
+	return lang.slugify(title, maxLen)
+}
+
+// slugify transliterates title into lowercase words, drops stopwords unless
+// nothing else is left, and joins the words with hyphens. Slugs are cut to
+// maxLen bytes without splitting a character.
+func (l slugLanguage) slugify(title string, maxLen int) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(norm.NFC.String(title)) {
+		if s, ok := l.transliterate(r); ok {
+			b.WriteString(s)
+		} else {
+			b.WriteByte(' ')
+		}
+	}
+
+	words := strings.Fields(b.String())
+	kept := slices.DeleteFunc(slices.Clone(words), func(w string) bool {
+		return slices.Contains(l.stopwords, w)
+	})
+	if len(kept) > 0 {
+		words = kept
+	}
+
+	slug := strings.Join(words, "-")
+	if len(slug) > maxLen {
+		cut := maxLen
+		for cut > 0 && !utf8.RuneStart(slug[cut]) {
+			cut--
+		}
+		slug = strings.TrimRight(slug[:cut], "-")
+	}
+	return slug
+}
+
+// transliterate returns r as slug text: ASCII letters and digits, letters
+// of native scripts, and other letters spelled in ASCII, with accents
+// dropped. ok is false for anything else, which separates words.
+func (l slugLanguage) transliterate(r rune) (string, bool) {
+	if s, ok := l.letters[r]; ok {
+		return s, true
+	}
+	if s, ok := slugLetters[r]; ok {
+		return s, true
+	}
+	if r < utf8.RuneSelf {
+		if ('a' <= r && r <= 'z') || ('0' <= r && r <= '9') {
+			return string(r), true
+		}
+		return "", false
+	}
+	if len(l.native) > 0 && unicode.In(r, l.native...) {
+		return string(r), true
+	}
+
+	// é is e with an acute accent, ά is α with one
+	decomposed := norm.NFD.String(string(r))
+	if decomposed == string(r) {
+		return "", false
+	}
+	var b strings.Builder
+	for _, d := range decomposed {
+		if unicode.Is(unicode.Mn, d) {
+			continue
+		}
+		s, ok := l.transliterate(d)
+		if !ok {
+			return "", false
+		}
+		b.WriteString(s)
+	}
+	return b.String(), true
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTitleSlug(t *testing.T) {
+	defer func() { filenameLang = "" }()
+
+	tests := []struct {
+		lang  string
+		title string
+		want  string
+	}{
+		{"", "Über uns – Die Geschichte", "ber-uns-die-geschichte"},
+		{"en", "The Art of Computer Programming", "art-computer-programming"},
+		{"en", "The End", "end"},
+		{"en", "Of The", "of-the"},
+		{"de", "Über uns – Die Geschichte der Straße", "ueber-uns-geschichte-strasse"},
+		{"de", "Anleitung für Einsteiger", "anleitung-einsteiger"},
+		{"fr", "L'histoire de la Côte d'Ivoire", "histoire-cote-ivoire"},
+		{"es", "Año nuevo en España", "ano-nuevo-espana"},
+		{"ru", "Руководство для начинающих", "rukovodstvo-nachinayushchikh"},
+		{"ru", "Объявление", "obyavlenie"},
+		{"uk", "Інструкція та гайд", "instruktsiya-hayd"},
+		{"el", "Η Ιστορία της Αθήνας", "istoria-athinas"},
+		{"ja", "日本語のページ – ドキュメント", "日本語のページ-ドキュメント"},
+		{"ja", "Go言語入門 (第2版)", "go言語入門-第2版"},
+		{"ko", "한국어 문서", "한국어-문서"},
+		{"DE", "Grüße", "gruesse"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang+" "+tt.title, func(t *testing.T) {
+			filenameLang = tt.lang
+			if got := TitleSlug(tt.title, MaxSlugLength); got != tt.want {
+				t.Errorf("TitleSlug(%q) with %q = %q, want %q", tt.title, tt.lang, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTitleSlugTruncatesWholeCharacters(t *testing.T) {
+	defer func() { filenameLang = "" }()
+	filenameLang = "ja"
+
+	slug := TitleSlug("日本語のドキュメントのタイトルがとても長い場合のテストです", 20)
+	if len(slug) > 20 || !utf8.ValidString(slug) {
+		t.Errorf("TitleSlug = %q (%d bytes), want valid UTF-8 of at most 20 bytes", slug, len(slug))
+	}
+}
+
+func TestCLI_FilenameLangInvalid(t *testing.T) {
+	_, stderr, err := runSnag("--filename-lang", "xx", "example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "Invalid --filename-lang: xx")
+}
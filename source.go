@@ -0,0 +1,47 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+// newSourceHeader reads the title and URL of page for --include-source. The
+// page's canonical URL is preferred over the one it was fetched from, so
+// tracking parameters and mirrors point back to one address.
+func newSourceHeader(page *rod.Page, format string) (string, error) {
+	info, err := page.Info()
+	if err != nil {
+		return "", fmt.Errorf("failed to get page info: %w", err)
+	}
+
+	sourceURL := info.URL
+	if canonical := extractCanonical(page); canonical != "" {
+		sourceURL = canonical
+	}
+	return sourceHeader(info.Title, sourceURL, time.Now(), format), nil
+}
+
+// sourceHeader formats the block --include-source puts at the top of the
+// output: the title as a heading, then the source URL and fetch time. A
+// page without a title gets only the source line.
+func sourceHeader(title, sourceURL string, fetched time.Time, format string) string {
+	title = strings.Join(strings.Fields(title), " ")
+	source := fmt.Sprintf("Source: %s (fetched %s)\n\n", sourceURL, fetched.Format(time.RFC3339))
+
+	if title == "" {
+		return source
+	}
+	if format == FormatMarkdown {
+		return "# " + title + "\n\n" + source
+	}
+	return title + "\n\n" + source
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSourceHeader(t *testing.T) {
+	fetched := time.Date(2025, 10, 22, 14, 20, 33, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		title    string
+		format   string
+		expected string
+	}{
+		{"markdown", "Install  Guide\n", FormatMarkdown, "# Install Guide\n\nSource: https://example.com/install (fetched 2025-10-22T14:20:33Z)\n\n"},
+		{"text", "Install Guide", FormatText, "Install Guide\n\nSource: https://example.com/install (fetched 2025-10-22T14:20:33Z)\n\n"},
+		{"no title", " ", FormatMarkdown, "Source: https://example.com/install (fetched 2025-10-22T14:20:33Z)\n\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sourceHeader(tt.title, "https://example.com/install", fetched, tt.format)
+			if got != tt.expected {
+				t.Errorf("sourceHeader() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
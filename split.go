@@ -0,0 +1,224 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-rod/rod"
+)
+
+// pdfSectionScript finds the sections of the page that start at headings
+// of level or higher. With index -1 it returns the heading text of each
+// section, "" for content before the first heading. Otherwise it hides
+// every element outside section index so only that section prints.
+const pdfSectionScript = `(level, index) => {
+	const selector = ['h1', 'h2', 'h3', 'h4', 'h5', 'h6'].slice(0, level).join(', ');
+	const body = document.body;
+	const sections = [];
+
+	const intro = document.createRange();
+	intro.setStart(body, 0);
+	const headings = [...body.querySelectorAll(selector)];
+	if (headings.length > 0) {
+		intro.setEndBefore(headings[0]);
+	} else {
+		intro.setEnd(body, body.childNodes.length);
+	}
+	if (intro.toString().trim() !== '') {
+		sections.push({heading: '', range: intro});
+	}
+
+	headings.forEach((heading, i) => {
+		const range = document.createRange();
+		range.setStartBefore(heading);
+		if (i + 1 < headings.length) {
+			range.setEndBefore(headings[i + 1]);
+		} else {
+			range.setEnd(body, body.childNodes.length);
+		}
+		sections.push({heading: heading.textContent.trim(), range});
+	});
+
+	if (index < 0) {
+		return sections.map(s => s.heading);
+	}
+
+	const style = document.createElement('style');
+	style.id = 'snag-pdf-hide';
+	style.textContent = '.snag-pdf-hidden { display: none !important; }';
+	document.head.appendChild(style);
+
+	const range = sections[index].range;
+	const hide = (el) => {
+		for (const child of el.children) {
+			if (!range.intersectsNode(child)) {
+				child.classList.add('snag-pdf-hidden');
+			} else {
+				hide(child);
+			}
+		}
+	};
+	hide(body);
+	return [];
+}`
+
+// MarkdownSection is one chunk of a Markdown document split by --split-by.
+type MarkdownSection struct {
+	Heading string
+	Content string
+}
+
+// splitHeadingLevel returns the heading level of a --split-by value such as
+// "h2", or 0 if it is not h1 to h6.
+func splitHeadingLevel(value string) int {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if len(value) != 2 || value[0] != 'h' || value[1] < '1' || value[1] > '6' {
+		return 0
+	}
+	return int(value[1] - '0')
+}
+
+// markdownHeading returns the level and text of an ATX heading line, or 0
+// if line is not a heading.
+func markdownHeading(line string) (int, string) {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || (level < len(line) && line[level] != ' ') {
+		return 0, ""
+	}
+
+	text := strings.TrimSpace(line[level:])
+	text = strings.TrimSpace(strings.TrimRight(text, "#"))
+	return level, text
+}
+
+// splitMarkdown splits content before every heading of level or higher,
+// ignoring headings inside fenced code blocks. Content before the first
+// heading becomes a section with no heading.
+func splitMarkdown(content string, level int) []MarkdownSection {
+	var sections []MarkdownSection
+	var current MarkdownSection
+	var b strings.Builder
+	inFence := false
+
+	flush := func() {
+		current.Content = strings.TrimSpace(b.String())
+		if current.Content != "" {
+			current.Content += "\n"
+			sections = append(sections, current)
+		}
+		b.Reset()
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+
+		if !inFence {
+			if headingLevel, text := markdownHeading(line); headingLevel > 0 && headingLevel <= level {
+				flush()
+				current = MarkdownSection{Heading: text}
+			}
+		}
+
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	flush()
+
+	return sections
+}
+
+// sectionFilename names section index of outputFile, e.g. page.md becomes
+// page-02-installation.md.
+func sectionFilename(outputFile string, index int, heading string) string {
+	ext := filepath.Ext(outputFile)
+	base := strings.TrimSuffix(outputFile, ext)
+
+	slug := TitleSlug(heading, MaxSlugLength)
+	if slug == "" {
+		slug = "intro"
+	}
+
+	return fmt.Sprintf("%s-%02d-%s%s", base, index, slug, ext)
+}
+
+// writeSections splits Markdown content at cc.splitLevel headings and writes
+// each section to its own file named after outputFile.
+func (cc *ContentConverter) writeSections(content string, outputFile string) error {
+	sections := splitMarkdown(content, cc.splitLevel)
+	logger.Verbose("Split into %d sections at h%d headings", len(sections), cc.splitLevel)
+
+	for i, section := range sections {
+		content := section.Content
+		if cc.maxTokens > 0 {
+			content = cc.applyTokenBudget(content)
+		}
+
+		filename := sectionFilename(outputFile, i+1, section.Heading)
+		if err := cc.writeToFile(cc.encodeText(content), filename); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writePDFSections prints each section of page that starts at a
+// cc.pdfSplitLevel heading to its own PDF named after outputFile, by hiding
+// the rest of the page while it prints.
+func (cc *ContentConverter) writePDFSections(page *rod.Page, outputFile string) error {
+	if outputFile == "" {
+		return fmt.Errorf("--pdf-split-by requires an output file")
+	}
+
+	res, err := page.Eval(pdfSectionScript, cc.pdfSplitLevel, -1)
+	if err != nil {
+		return fmt.Errorf("failed to find page sections: %w", err)
+	}
+	headings := res.Value.Arr()
+	logger.Verbose("Splitting PDF into %d sections at h%d headings", len(headings), cc.pdfSplitLevel)
+
+	restore := func() {
+		if _, err := page.Eval(pdfRestoreScript); err != nil {
+			logger.Debug("Failed to restore page after PDF split: %v", err)
+		}
+	}
+
+	for i, heading := range headings {
+		if _, err := page.Eval(pdfSectionScript, cc.pdfSplitLevel, i); err != nil {
+			restore()
+			return fmt.Errorf("failed to isolate section %d: %w", i+1, err)
+		}
+
+		data, err := cc.generatePDF(page)
+		restore()
+		if err != nil {
+			return fmt.Errorf("failed to generate PDF for section %d: %w", i+1, err)
+		}
+		if cc.pdfA {
+			if data, err = cc.applyPDFA(data); err != nil {
+				return err
+			}
+		}
+		metrics.AddConvertedBytes(cc.format, len(data))
+
+		filename := sectionFilename(outputFile, i+1, heading.Str())
+		if err := cc.writeBinaryToFile(data, filename); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplitHeadingLevel(t *testing.T) {
+	tests := map[string]int{"h1": 1, "H2": 2, " h6 ": 6, "h7": 0, "h": 0, "div": 0, "": 0}
+	for input, want := range tests {
+		if got := splitHeadingLevel(input); got != want {
+			t.Errorf("splitHeadingLevel(%q) = %d, want %d", input, got, want)
+		}
+	}
+}
+
+func TestSplitMarkdown(t *testing.T) {
+	content := `# Guide
+
+Welcome.
+
+## Install
+
+Run it.
+
+` + "```sh\n## not a heading\n```" + `
+
+### Details
+
+More.
+
+## Usage ##
+
+Use it.
+`
+
+	sections := splitMarkdown(content, 2)
+	if len(sections) != 3 {
+		t.Fatalf("expected 3 sections, got %d: %+v", len(sections), sections)
+	}
+
+	if sections[0].Heading != "Guide" || sections[0].Content != "# Guide\n\nWelcome.\n" {
+		t.Errorf("unexpected first section: %+v", sections[0])
+	}
+	if sections[1].Heading != "Install" || sections[1].Content != "## Install\n\nRun it.\n\n```sh\n## not a heading\n```\n\n### Details\n\nMore.\n" {
+		t.Errorf("unexpected install section: %+v", sections[1])
+	}
+	if sections[2].Heading != "Usage" {
+		t.Errorf("unexpected usage heading: %q", sections[2].Heading)
+	}
+}
+
+func TestSectionFilename(t *testing.T) {
+	if got := sectionFilename("out/page.md", 2, "Getting Started!"); got != "out/page-02-getting-started.md" {
+		t.Errorf("got %s", got)
+	}
+	if got := sectionFilename("page.md", 1, ""); got != "page-01-intro.md" {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestCLI_ConvertSplitBy(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "page.html")
+	os.WriteFile(input, []byte("<h1>Guide</h1><p>Hi</p><h2>One</h2><p>1</p><h2>Two</h2><p>2</p>"), 0644)
+
+	if _, _, err := runSnag("convert", input, "--split-by", "h2", "-o", filepath.Join(dir, "guide.md")); err != nil {
+		t.Fatalf("convert failed: %v", err)
+	}
+
+	for _, name := range []string{"guide-01-guide.md", "guide-02-one.md", "guide-03-two.md"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %s: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "guide.md")); err == nil {
+		t.Error("unsplit guide.md should not be written")
+	}
+}
+
+func TestCLI_SplitByRequiresMarkdown(t *testing.T) {
+	_, stderr, err := runSnag("--split-by", "h2", "-f", "text", "example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "--split-by only applies to --format md")
+}
+
+func TestCLI_PDFSplitByRequiresPDF(t *testing.T) {
+	_, stderr, err := runSnag("--pdf-split-by", "h1", "-f", "md", "example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "--pdf-split-by only applies to --format pdf")
+}
+
+func TestCLI_PDFSplitByInvalidLevel(t *testing.T) {
+	_, stderr, err := runSnag("--pdf-split-by", "h7", "-f", "pdf", "example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "Invalid --pdf-split-by: h7")
+}
+
+func TestBrowser_PDFSplitBy(t *testing.T) {
+	if !isBrowserAvailable() {
+		t.Skip("Browser not available, skipping browser integration test")
+	}
+
+	server := startTestServer(t)
+	dir := t.TempDir()
+
+	_, _, err := runSnag("-f", "pdf", "--pdf-split-by", "h2", "-o", filepath.Join(dir, "page.pdf"), server.URL+"/simple.html")
+	assertNoError(t, err)
+
+	for _, name := range []string{"page-01-example-heading.pdf", "page-02-second-level-heading.pdf"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("expected %s: %v", name, err)
+			continue
+		}
+		if !strings.HasPrefix(string(data), "%PDF-") {
+			t.Errorf("%s is not a PDF", name)
+		}
+	}
+}
@@ -0,0 +1,346 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	bolt "go.etcd.io/bbolt"
+)
+
+const StateLockTimeout = time.Second
+
+var (
+	stateFrontierBucket = []byte("frontier")
+	stateURLsBucket     = []byte("urls")
+)
+
+type URLStatus string
+
+const (
+	URLPending URLStatus = "pending"
+	URLDone    URLStatus = "done"
+	URLFailed  URLStatus = "failed"
+)
+
+// URLRecord is the persisted status of one URL in a crawl state file.
+type URLRecord struct {
+	URL      string     `json:"url"`
+	Status   URLStatus  `json:"status"`
+	Output   string     `json:"output,omitempty"`
+	Error    string     `json:"error,omitempty"`
+	Attempts int        `json:"attempts"`
+	Seq      uint64     `json:"seq"`
+	Added    time.Time  `json:"added"`
+	Fetched  *time.Time `json:"fetched,omitempty"`
+
+	// Set by --incremental to detect changes on later runs
+	ETag         string     `json:"etag,omitempty"`
+	LastModified string     `json:"last_modified,omitempty"`
+	Hash         string     `json:"hash,omitempty"`
+	Checked      *time.Time `json:"checked,omitempty"`
+}
+
+// CrawlState persists the frontier, visited set, and per-URL status of a
+// batch fetch in a bbolt file, so an interrupted run can be restarted and
+// what remains can be inspected with `snag state`.
+//
+// The frontier bucket maps an insertion sequence number to a URL and holds
+// every URL not yet fetched successfully, in the order it was added. The
+// urls bucket maps each URL ever seen to its URLRecord.
+type CrawlState struct {
+	db   *bolt.DB
+	path string
+}
+
+// OpenCrawlState opens or creates the state file at path.
+func OpenCrawlState(path string) (*CrawlState, error) {
+	db, err := bolt.Open(path, DefaultFileMode, &bolt.Options{Timeout: StateLockTimeout})
+	if err != nil {
+		if errors.Is(err, bolt.ErrTimeout) {
+			logger.Error("State file %s is in use by another snag process", path)
+			return nil, fmt.Errorf("state file is locked: %s", path)
+		}
+		logger.Error("Failed to open state file: %s", path)
+		return nil, fmt.Errorf("failed to open state file: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{stateFrontierBucket, stateURLsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize state file %s: %w", path, err)
+	}
+
+	return &CrawlState{db: db, path: path}, nil
+}
+
+func (s *CrawlState) Close() error {
+	return s.db.Close()
+}
+
+func seqKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
+
+func getURLRecord(urls *bolt.Bucket, url string) (*URLRecord, error) {
+	data := urls.Get([]byte(url))
+	if data == nil {
+		return nil, nil
+	}
+	var rec URLRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("corrupt state record for %s: %w", url, err)
+	}
+	return &rec, nil
+}
+
+func putURLRecord(urls *bolt.Bucket, rec *URLRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return urls.Put([]byte(rec.URL), data)
+}
+
+// Enqueue adds URLs that have never been seen to the frontier and returns
+// how many were added. URLs already in the state, fetched or not, are left
+// unchanged.
+func (s *CrawlState) Enqueue(urls []string) (int, error) {
+	added := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		frontier := tx.Bucket(stateFrontierBucket)
+		records := tx.Bucket(stateURLsBucket)
+
+		for _, url := range urls {
+			existing, err := getURLRecord(records, url)
+			if err != nil {
+				return err
+			}
+			if existing != nil {
+				continue
+			}
+
+			seq, err := frontier.NextSequence()
+			if err != nil {
+				return err
+			}
+			rec := &URLRecord{URL: url, Status: URLPending, Seq: seq, Added: time.Now().UTC()}
+			if err := putURLRecord(records, rec); err != nil {
+				return err
+			}
+			if err := frontier.Put(seqKey(seq), []byte(url)); err != nil {
+				return err
+			}
+			added++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to update state file %s: %w", s.path, err)
+	}
+	return added, nil
+}
+
+// Pending returns the URLs remaining in the frontier, oldest first. Failed
+// URLs stay in the frontier so they are retried on the next run.
+func (s *CrawlState) Pending() ([]string, error) {
+	var urls []string
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(stateFrontierBucket).ForEach(func(_, v []byte) error {
+			urls = append(urls, string(v))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file %s: %w", s.path, err)
+	}
+	return urls, nil
+}
+
+// Record stores the outcome of fetching url. A successful fetch removes it
+// from the frontier.
+func (s *CrawlState) Record(url, output string, fetchErr error) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		records := tx.Bucket(stateURLsBucket)
+		rec, err := getURLRecord(records, url)
+		if err != nil {
+			return err
+		}
+		if rec == nil {
+			return fmt.Errorf("URL not in state: %s", url)
+		}
+
+		now := time.Now().UTC()
+		rec.Attempts++
+		rec.Fetched = &now
+		if fetchErr != nil {
+			rec.Status = URLFailed
+			rec.Error = redactIfEnabled(fetchErr.Error())
+		} else {
+			rec.Status = URLDone
+			rec.Output = output
+			rec.Error = ""
+			if err := tx.Bucket(stateFrontierBucket).Delete(seqKey(rec.Seq)); err != nil {
+				return err
+			}
+		}
+		return putURLRecord(records, rec)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update state file %s: %w", s.path, err)
+	}
+	return nil
+}
+
+// Get returns the record for url, or nil if it is not in the state.
+func (s *CrawlState) Get(url string) (*URLRecord, error) {
+	var rec *URLRecord
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		rec, err = getURLRecord(tx.Bucket(stateURLsBucket), url)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file %s: %w", s.path, err)
+	}
+	return rec, nil
+}
+
+// update applies fn to the record for url and saves it.
+func (s *CrawlState) update(url string, fn func(*URLRecord)) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		records := tx.Bucket(stateURLsBucket)
+		rec, err := getURLRecord(records, url)
+		if err != nil {
+			return err
+		}
+		if rec == nil {
+			return fmt.Errorf("URL not in state: %s", url)
+		}
+		fn(rec)
+		return putURLRecord(records, rec)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update state file %s: %w", s.path, err)
+	}
+	return nil
+}
+
+// SetFingerprint stores the validators and content hash of the version of
+// url that was just fetched.
+func (s *CrawlState) SetFingerprint(url string, fp Fingerprint) error {
+	return s.update(url, func(rec *URLRecord) {
+		rec.ETag = fp.ETag
+		rec.LastModified = fp.LastModified
+		rec.Hash = fp.Hash
+		now := time.Now().UTC()
+		rec.Checked = &now
+	})
+}
+
+// RecordUnchanged notes that url was checked and has not changed since its
+// last fetch.
+func (s *CrawlState) RecordUnchanged(url string) error {
+	return s.update(url, func(rec *URLRecord) {
+		now := time.Now().UTC()
+		rec.Checked = &now
+	})
+}
+
+// Records returns every URL in the state, in the order they were added.
+func (s *CrawlState) Records() ([]URLRecord, error) {
+	var recs []URLRecord
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(stateURLsBucket).ForEach(func(k, v []byte) error {
+			var rec URLRecord
+			if err := json.Unmarshal(v, &rec); err != nil {
+				return fmt.Errorf("corrupt state record for %s: %w", k, err)
+			}
+			recs = append(recs, rec)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file %s: %w", s.path, err)
+	}
+
+	// bbolt iterates in key (URL) order
+	sort.Slice(recs, func(i, j int) bool { return recs[i].Seq < recs[j].Seq })
+	return recs, nil
+}
+
+// writeStateSummary prints status counts followed by every URL that has not
+// been fetched successfully.
+func writeStateSummary(w io.Writer, recs []URLRecord) {
+	counts := make(map[URLStatus]int)
+	for _, rec := range recs {
+		counts[rec.Status]++
+	}
+
+	fmt.Fprintf(w, "%d URL%s: %d done, %d pending, %d failed\n",
+		len(recs), plural(len(recs)), counts[URLDone], counts[URLPending], counts[URLFailed])
+
+	for _, rec := range recs {
+		switch rec.Status {
+		case URLPending:
+			fmt.Fprintf(w, "  pending  %s\n", rec.URL)
+		case URLFailed:
+			fmt.Fprintf(w, "  failed   %s (%d attempt%s: %s)\n", rec.URL, rec.Attempts, plural(rec.Attempts), rec.Error)
+		}
+	}
+}
+
+var stateCmd = &cobra.Command{
+	Use:          "state FILE",
+	Short:        "Show what remains in a --state file",
+	Args:         cobra.ExactArgs(1),
+	RunE:         runState,
+	SilenceUsage: true,
+}
+
+func init() {
+	rootCmd.AddCommand(stateCmd)
+}
+
+func runState(cmd *cobra.Command, args []string) error {
+	logger = NewLogger(LevelNormal)
+
+	if err := validateStateFile(args[0], true); err != nil {
+		return err
+	}
+
+	state, err := OpenCrawlState(args[0])
+	if err != nil {
+		return err
+	}
+	defer state.Close()
+
+	recs, err := state.Records()
+	if err != nil {
+		logger.Error("%v", err)
+		return err
+	}
+
+	writeStateSummary(cmd.OutOrStdout(), recs)
+	return nil
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCrawlState_Resume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crawl.db")
+
+	state, err := OpenCrawlState(path)
+	if err != nil {
+		t.Fatalf("OpenCrawlState failed: %v", err)
+	}
+
+	added, err := state.Enqueue([]string{"https://a.example", "https://b.example", "https://c.example"})
+	if err != nil || added != 3 {
+		t.Fatalf("Enqueue = %d, %v; want 3", added, err)
+	}
+
+	if err := state.Record("https://a.example", "a.md", nil); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if err := state.Record("https://b.example", "", errors.New("timeout")); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if err := state.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// Reopen as a restarted run would, adding one known and one new URL
+	state, err = OpenCrawlState(path)
+	if err != nil {
+		t.Fatalf("OpenCrawlState failed: %v", err)
+	}
+	defer state.Close()
+
+	added, err = state.Enqueue([]string{"https://a.example", "https://d.example"})
+	if err != nil || added != 1 {
+		t.Fatalf("Enqueue = %d, %v; want 1", added, err)
+	}
+
+	pending, err := state.Pending()
+	if err != nil {
+		t.Fatalf("Pending failed: %v", err)
+	}
+	want := "https://b.example https://c.example https://d.example"
+	if got := strings.Join(pending, " "); got != want {
+		t.Errorf("Pending = %s, want %s", got, want)
+	}
+
+	recs, err := state.Records()
+	if err != nil {
+		t.Fatalf("Records failed: %v", err)
+	}
+	if len(recs) != 4 {
+		t.Fatalf("expected 4 records, got %d", len(recs))
+	}
+	if recs[0].Status != URLDone || recs[0].Output != "a.md" {
+		t.Errorf("unexpected record for a: %+v", recs[0])
+	}
+	if recs[1].Status != URLFailed || recs[1].Error != "timeout" || recs[1].Attempts != 1 {
+		t.Errorf("unexpected record for b: %+v", recs[1])
+	}
+	if recs[3].URL != "https://d.example" || recs[3].Status != URLPending {
+		t.Errorf("unexpected record for d: %+v", recs[3])
+	}
+}
+
+func TestWriteStateSummary(t *testing.T) {
+	recs := []URLRecord{
+		{URL: "https://a.example", Status: URLDone},
+		{URL: "https://b.example", Status: URLFailed, Attempts: 2, Error: "timeout"},
+		{URL: "https://c.example", Status: URLPending},
+	}
+
+	var b strings.Builder
+	writeStateSummary(&b, recs)
+	out := b.String()
+
+	for _, want := range []string{
+		"3 URLs: 1 done, 1 pending, 1 failed",
+		"failed   https://b.example (2 attempts: timeout)",
+		"pending  https://c.example",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("summary missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "a.example") {
+		t.Errorf("summary should not list done URLs:\n%s", out)
+	}
+}
+
+func TestCLI_StateMissingFile(t *testing.T) {
+	_, stderr, err := runSnag("state", filepath.Join(t.TempDir(), "missing.db"))
+	assertError(t, err)
+	assertContains(t, stderr, "State file does not exist")
+}
+
+func TestCLI_StateWithTab(t *testing.T) {
+	_, stderr, err := runSnag("--state", filepath.Join(t.TempDir(), "crawl.db"), "--tab", "1")
+	assertError(t, err)
+	assertContains(t, stderr, "Cannot use --state with --tab")
+}
@@ -0,0 +1,491 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// StateBundleVersion is the format of archives written by export-state.
+	StateBundleVersion = 1
+
+	// StateBundleManifest is the first entry of a state archive and lists
+	// what it holds.
+	StateBundleManifest = "snag-state.json"
+)
+
+// State bundle entry kinds, each restored for the flag that uses it.
+const (
+	BundleCookies = "cookies"
+	BundleState   = "state"
+	BundleProfile = "profile"
+	BundleJobs    = "jobs"
+)
+
+// bundleFlags are the flags that use each kind of imported state.
+var bundleFlags = map[string]string{
+	BundleCookies: "--cookies-file",
+	BundleState:   "--state",
+	BundleProfile: "--user-data-dir",
+	BundleJobs:    "--jobs-dir",
+}
+
+// profileSkipFiles are browser profile files that only mean something to
+// the browser process that made them.
+var profileSkipFiles = map[string]bool{
+	"SingletonLock": true, "SingletonCookie": true, "SingletonSocket": true,
+	"lockfile": true, "LOCK": true,
+}
+
+// StateBundle is the manifest of a state archive.
+type StateBundle struct {
+	Version     int           `json:"version"`
+	SnagVersion string        `json:"snag_version"`
+	Created     time.Time     `json:"created"`
+	Entries     []BundleEntry `json:"entries"`
+}
+
+// BundleEntry is one kind of state in an archive, stored at Path: a file,
+// or for profiles and job directories, a directory.
+type BundleEntry struct {
+	Kind string `json:"kind"`
+	Path string `json:"path"`
+}
+
+const exportStateHelpTemplate = `USAGE:
+  snag export-state -o FILE [--cookies-file FILE] [--state FILE] [--user-data-dir DIR] [--jobs-dir DIR]
+
+DESCRIPTION:
+  Bundle the state of a tuned snag setup into one .tar.gz archive, to move it
+  to another machine or into CI with snag import-state. snag keeps no state of
+  its own, so name each part to include: a cookies file, a --state crawl
+  file, a browser profile with its logins and cache, or a serve jobs
+  directory.
+
+  Close the browser before exporting its profile. Browsers encrypt profile
+  cookies for the machine they run on, so sessions usually move better as a
+  --cookies-file.
+
+EXAMPLES:
+  snag export-state -o setup.tar.gz --cookies-file session.json --state crawl.db
+  snag export-state -o profile.tar.gz --user-data-dir ~/.snag-profile
+
+OPTIONS:
+  -o, --output string          Archive to write
+      --cookies-file string    Cookies file to include (JSON or Netscape cookies.txt)
+      --state string           Crawl state file to include
+      --user-data-dir string   Browser profile directory to include
+      --jobs-dir string        snag serve jobs directory to include
+
+  -q, --quiet                  Suppress all output except errors
+      --verbose                Enable verbose logging output
+  -h, --help                   help for export-state
+`
+
+const importStateHelpTemplate = `USAGE:
+  snag import-state FILE [-d DIR]
+
+DESCRIPTION:
+  Unpack an archive made by snag export-state into a directory and show the
+  flags that use each part. Nothing already in the directory is replaced.
+
+EXAMPLES:
+  snag import-state setup.tar.gz -d state/
+  snag --cookies-file state/cookies.json --state state/crawl.db --url-file urls.txt -d pages/
+
+OPTIONS:
+  -d, --output-dir string      Directory to unpack into (default ".")
+
+  -q, --quiet                  Suppress all output except errors
+      --verbose                Enable verbose logging output
+  -h, --help                   help for import-state
+`
+
+var exportStateCmd = &cobra.Command{
+	Use:          "export-state",
+	Short:        "Bundle cookies, crawl state, and a browser profile into an archive",
+	Args:         cobra.NoArgs,
+	RunE:         runExportState,
+	SilenceUsage: true,
+}
+
+var importStateCmd = &cobra.Command{
+	Use:          "import-state FILE",
+	Short:        "Unpack an export-state archive",
+	Args:         cobra.ExactArgs(1),
+	RunE:         runImportState,
+	SilenceUsage: true,
+}
+
+func init() {
+	exportStateCmd.Flags().StringVarP(&output, "output", "o", "", "Archive to write")
+	exportStateCmd.Flags().StringVar(&cookiesFile, "cookies-file", "", "Cookies file to include (JSON or Netscape cookies.txt)")
+	exportStateCmd.Flags().StringVar(&stateFile, "state", "", "Crawl state file to include")
+	exportStateCmd.Flags().StringVar(&userDataDir, "user-data-dir", "", "Browser profile directory to include")
+	exportStateCmd.Flags().StringVar(&jobsDir, "jobs-dir", "", "snag serve jobs directory to include")
+	exportStateCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Suppress all output except errors")
+	exportStateCmd.Flags().BoolVar(&verbose, "verbose", false, "Enable verbose logging output")
+	exportStateCmd.MarkFlagsMutuallyExclusive("quiet", "verbose")
+	exportStateCmd.SetHelpTemplate(exportStateHelpTemplate)
+
+	importStateCmd.Flags().StringVarP(&outputDir, "output-dir", "d", "", "Directory to unpack into")
+	importStateCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Suppress all output except errors")
+	importStateCmd.Flags().BoolVar(&verbose, "verbose", false, "Enable verbose logging output")
+	importStateCmd.MarkFlagsMutuallyExclusive("quiet", "verbose")
+	importStateCmd.SetHelpTemplate(importStateHelpTemplate)
+
+	rootCmd.AddCommand(exportStateCmd)
+	rootCmd.AddCommand(importStateCmd)
+}
+
+func runExportState(cmd *cobra.Command, args []string) error {
+	level := LevelNormal
+	if verbose {
+		level = LevelVerbose
+	} else if quiet {
+		level = LevelQuiet
+	}
+	logger = NewLogger(level)
+
+	dest := strings.TrimSpace(output)
+	if dest == "" {
+		logger.Error("snag export-state requires --output")
+		logger.ErrorWithSuggestion(
+			"Give the archive to write",
+			"snag export-state -o setup.tar.gz --cookies-file session.json",
+		)
+		return fmt.Errorf("export-state requires --output")
+	}
+
+	sources := map[string]string{
+		BundleCookies: strings.TrimSpace(cookiesFile),
+		BundleState:   strings.TrimSpace(stateFile),
+		BundleProfile: strings.TrimSpace(userDataDir),
+		BundleJobs:    strings.TrimSpace(jobsDir),
+	}
+	bundle := StateBundle{Version: StateBundleVersion, SnagVersion: version, Created: time.Now().UTC()}
+	for _, kind := range []string{BundleCookies, BundleState, BundleProfile, BundleJobs} {
+		if src := sources[kind]; src != "" {
+			bundle.Entries = append(bundle.Entries, BundleEntry{Kind: kind, Path: bundlePath(kind, src)})
+		}
+	}
+	if len(bundle.Entries) == 0 {
+		logger.Error("Nothing to export")
+		logger.ErrorWithSuggestion(
+			"Name the state to include with --cookies-file, --state, --user-data-dir, or --jobs-dir",
+			"snag export-state -o setup.tar.gz --cookies-file session.json --state crawl.db",
+		)
+		return fmt.Errorf("no state to export")
+	}
+
+	for _, entry := range bundle.Entries {
+		src := sources[entry.Kind]
+		info, err := os.Stat(src)
+		if err != nil {
+			logger.Error("Cannot read %s for %s: %v", src, bundleFlags[entry.Kind], err)
+			return fmt.Errorf("failed to read %s: %w", src, err)
+		}
+		isDir := entry.Kind == BundleProfile || entry.Kind == BundleJobs
+		if info.IsDir() && !isDir {
+			logger.Error("%s %s is a directory, not a file", bundleFlags[entry.Kind], src)
+			return fmt.Errorf("not a file: %s", src)
+		}
+		if !info.IsDir() && isDir {
+			logger.Error("%s %s is not a directory", bundleFlags[entry.Kind], src)
+			return fmt.Errorf("not a directory: %s", src)
+		}
+	}
+
+	if err := validateOutputPath(dest); err != nil {
+		return err
+	}
+	// The archive holds cookies, tokens, and the browser profile
+	file, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		logger.Error("Failed to create archive: %s", dest)
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	// OpenFile only sets the mode on create, so an existing archive is
+	// tightened before anything is written to it
+	err = file.Chmod(0600)
+	if err == nil {
+		err = writeStateBundle(file, bundle, sources)
+	}
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(dest)
+		logger.Error("%v", err)
+		return err
+	}
+
+	logger.Success("Exported %d part%s of snag state to %s", len(bundle.Entries), plural(len(bundle.Entries)), dest)
+	return nil
+}
+
+// bundlePath is where state of kind from src is stored in an archive.
+// Cookies keep their extension, which tells snag their format.
+func bundlePath(kind, src string) string {
+	switch kind {
+	case BundleCookies:
+		ext := strings.ToLower(filepath.Ext(src))
+		if ext == "" {
+			ext = ".json"
+		}
+		return "cookies" + ext
+	case BundleState:
+		return "crawl.db"
+	}
+	return kind
+}
+
+// writeStateBundle writes the manifest and each part of bundle, read from
+// sources, as a gzipped tar stream.
+func writeStateBundle(w io.Writer, bundle StateBundle, sources map[string]string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	manifest, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state manifest: %w", err)
+	}
+	if err := writeTarFile(tw, StateBundleManifest, bundle.Created, manifest); err != nil {
+		return err
+	}
+
+	for _, entry := range bundle.Entries {
+		src := sources[entry.Kind]
+		switch entry.Kind {
+		case BundleState:
+			err = writeStateSnapshot(tw, entry.Path, src)
+		case BundleProfile, BundleJobs:
+			err = writeTarDir(tw, entry.Path, src, entry.Kind == BundleProfile)
+		default:
+			err = writeTarPath(tw, entry.Path, src)
+		}
+		if err != nil {
+			return err
+		}
+		logger.Verbose("Added %s from %s", entry.Kind, src)
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+	return nil
+}
+
+// writeStateSnapshot adds a consistent copy of the crawl state file src,
+// read in a transaction so a run using it cannot change it mid-copy.
+func writeStateSnapshot(tw *tar.Writer, name, src string) error {
+	db, err := bolt.Open(src, DefaultFileMode, &bolt.Options{Timeout: StateLockTimeout, ReadOnly: true})
+	if err != nil {
+		if errors.Is(err, bolt.ErrTimeout) {
+			return fmt.Errorf("state file %s is in use by another snag process", src)
+		}
+		return fmt.Errorf("failed to open state file: %w", err)
+	}
+	defer db.Close()
+
+	return db.View(func(tx *bolt.Tx) error {
+		header := &tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: DefaultFileMode, Size: tx.Size(), ModTime: time.Now()}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to add %s: %w", name, err)
+		}
+		if _, err := tx.WriteTo(tw); err != nil {
+			return fmt.Errorf("failed to add %s: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// writeTarDir adds the regular files under the directory src as name/...
+// Symlinks, sockets, and, for browser profiles, lock files are left out.
+func writeTarDir(tw *tar.Writer, name, src string, profile bool) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", p, err)
+		}
+		if !d.Type().IsRegular() || (profile && profileSkipFiles[d.Name()]) {
+			return nil
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		return writeTarPath(tw, path.Join(name, filepath.ToSlash(rel)), p)
+	})
+}
+
+// writeTarPath adds the file src as name.
+func writeTarPath(tw *tar.Writer, name, src string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", src, err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", src, err)
+	}
+
+	header := &tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: int64(info.Mode().Perm()), Size: info.Size(), ModTime: info.ModTime()}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	if _, err := io.CopyN(tw, f, info.Size()); err != nil {
+		return fmt.Errorf("failed to add %s: %w", src, err)
+	}
+	return nil
+}
+
+func writeTarFile(tw *tar.Writer, name string, modTime time.Time, data []byte) error {
+	header := &tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: DefaultFileMode, Size: int64(len(data)), ModTime: modTime}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	return nil
+}
+
+func runImportState(cmd *cobra.Command, args []string) error {
+	level := LevelNormal
+	if verbose {
+		level = LevelVerbose
+	} else if quiet {
+		level = LevelQuiet
+	}
+	logger = NewLogger(level)
+
+	dir := strings.TrimSpace(outputDir)
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		logger.Error("Failed to create %s", dir)
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	src := strings.TrimSpace(args[0])
+	file, err := os.Open(src)
+	if err != nil {
+		logger.Error("Failed to open archive: %s", src)
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	bundle, err := readStateBundle(file, dir)
+	if err != nil {
+		logger.Error("%v", err)
+		return err
+	}
+
+	for _, entry := range bundle.Entries {
+		logger.Success("Imported %s: %s %s", entry.Kind, bundleFlags[entry.Kind], filepath.Join(dir, filepath.FromSlash(entry.Path)))
+	}
+	return nil
+}
+
+// readStateBundle unpacks a state archive into dir and returns its
+// manifest. It refuses entries the manifest does not list, entries outside
+// dir, and parts that already exist in dir.
+func readStateBundle(r io.Reader, dir string) (*StateBundle, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a snag state archive: %w", err)
+	}
+	tr := tar.NewReader(gz)
+
+	header, err := tr.Next()
+	if err != nil || header.Name != StateBundleManifest {
+		return nil, fmt.Errorf("not a snag state archive: missing %s", StateBundleManifest)
+	}
+	var bundle StateBundle
+	if err := json.NewDecoder(tr).Decode(&bundle); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", StateBundleManifest, err)
+	}
+	if bundle.Version > StateBundleVersion {
+		return nil, fmt.Errorf("state archive version %d needs a newer snag", bundle.Version)
+	}
+
+	for _, entry := range bundle.Entries {
+		if _, ok := bundleFlags[entry.Kind]; !ok || !filepath.IsLocal(entry.Path) {
+			return nil, fmt.Errorf("invalid state archive entry: %s %s", entry.Kind, entry.Path)
+		}
+		target := filepath.Join(dir, filepath.FromSlash(entry.Path))
+		if _, err := os.Lstat(target); err == nil {
+			return nil, fmt.Errorf("%s already exists, not replacing it (import into an empty directory with -d)", target)
+		}
+	}
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read state archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if !filepath.IsLocal(header.Name) || !bundle.lists(header.Name) {
+			return nil, fmt.Errorf("unexpected entry in state archive: %s", header.Name)
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory for %s: %w", target, err)
+		}
+		f, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, fs.FileMode(header.Mode).Perm()|0600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", target, err)
+		}
+		_, err = io.Copy(f, tr)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", target, err)
+		}
+		logger.Debug("Unpacked %s", target)
+	}
+	return &bundle, nil
+}
+
+// lists reports whether name is one of the bundle's files or inside one of
+// its directories.
+func (b *StateBundle) lists(name string) bool {
+	name = path.Clean(name)
+	for _, entry := range b.Entries {
+		if name == entry.Path || strings.HasPrefix(name, entry.Path+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestStateBundleRoundTrip(t *testing.T) {
+	logger = NewLogger(LevelQuiet)
+	src := t.TempDir()
+
+	cookies := filepath.Join(src, "session.txt")
+	assertNoError(t, os.WriteFile(cookies, []byte("# Netscape HTTP Cookie File\n"), DefaultFileMode))
+
+	statePath := filepath.Join(src, "crawl.db")
+	state, err := OpenCrawlState(statePath)
+	assertNoError(t, err)
+	_, err = state.Enqueue([]string{"https://example.com/a", "https://example.com/b"})
+	assertNoError(t, err)
+	assertNoError(t, state.Close())
+
+	profile := filepath.Join(src, "profile")
+	assertNoError(t, os.MkdirAll(filepath.Join(profile, "Default"), 0755))
+	assertNoError(t, os.WriteFile(filepath.Join(profile, "Default", "Preferences"), []byte("{}"), DefaultFileMode))
+	assertNoError(t, os.Symlink("host-1234", filepath.Join(profile, "SingletonLock")))
+
+	sources := map[string]string{BundleCookies: cookies, BundleState: statePath, BundleProfile: profile}
+	bundle := StateBundle{Version: StateBundleVersion, Created: time.Now(), Entries: []BundleEntry{
+		{Kind: BundleCookies, Path: bundlePath(BundleCookies, cookies)},
+		{Kind: BundleState, Path: bundlePath(BundleState, statePath)},
+		{Kind: BundleProfile, Path: bundlePath(BundleProfile, profile)},
+	}}
+	var archive bytes.Buffer
+	assertNoError(t, writeStateBundle(&archive, bundle, sources))
+
+	dest := t.TempDir()
+	got, err := readStateBundle(bytes.NewReader(archive.Bytes()), dest)
+	assertNoError(t, err)
+	if len(got.Entries) != 3 {
+		t.Fatalf("imported %d entries, want 3", len(got.Entries))
+	}
+
+	if _, err := os.Stat(filepath.Join(dest, "cookies.txt")); err != nil {
+		t.Errorf("cookies not imported with their extension: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "profile", "Default", "Preferences")); err != nil {
+		t.Errorf("profile file not imported: %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(dest, "profile", "SingletonLock")); !os.IsNotExist(err) {
+		t.Error("expected profile lock to be left out")
+	}
+
+	imported, err := OpenCrawlState(filepath.Join(dest, "crawl.db"))
+	assertNoError(t, err)
+	defer imported.Close()
+	pending, err := imported.Pending()
+	assertNoError(t, err)
+	if len(pending) != 2 {
+		t.Errorf("imported state has %d pending URLs, want 2", len(pending))
+	}
+
+	// Importing again does not replace what is there
+	_, err = readStateBundle(bytes.NewReader(archive.Bytes()), dest)
+	assertError(t, err)
+	assertContains(t, err.Error(), "already exists")
+}
+
+func TestReadStateBundle_RejectsUnlistedEntries(t *testing.T) {
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	manifest, _ := json.Marshal(StateBundle{Version: StateBundleVersion, Entries: []BundleEntry{{Kind: BundleJobs, Path: "jobs"}}})
+	assertNoError(t, writeTarFile(tw, StateBundleManifest, time.Now(), manifest))
+	assertNoError(t, writeTarFile(tw, "../outside.txt", time.Now(), []byte("x")))
+	assertNoError(t, tw.Close())
+	assertNoError(t, gz.Close())
+
+	dest := t.TempDir()
+	_, err := readStateBundle(&archive, dest)
+	assertError(t, err)
+	assertContains(t, err.Error(), "unexpected entry")
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dest), "outside.txt")); !os.IsNotExist(err) {
+		t.Error("entry was written outside the import directory")
+	}
+}
+
+func TestReadStateBundle_NotABundle(t *testing.T) {
+	_, err := readStateBundle(bytes.NewReader([]byte("plain text")), t.TempDir())
+	assertError(t, err)
+	assertContains(t, err.Error(), "not a snag state archive")
+}
+
+func TestCLI_ExportState(t *testing.T) {
+	_, stderr, err := runSnag("export-state", "--cookies-file", "session.json")
+	assertError(t, err)
+	assertContains(t, stderr, "requires --output")
+
+	_, stderr, err = runSnag("export-state", "-o", filepath.Join(t.TempDir(), "state.tar.gz"))
+	assertError(t, err)
+	assertContains(t, stderr, "Nothing to export")
+
+	dir := t.TempDir()
+	cookies := filepath.Join(dir, "session.json")
+	assertNoError(t, os.WriteFile(cookies, []byte("[]"), DefaultFileMode))
+	archive := filepath.Join(dir, "state.tar.gz")
+	_, _, err = runSnag("export-state", "-o", archive, "--cookies-file", cookies)
+	assertNoError(t, err)
+	if runtime.GOOS != "windows" {
+		if info, err := os.Stat(archive); err != nil || info.Mode().Perm() != 0600 {
+			t.Errorf("expected mode 600, got %v (%v)", info.Mode().Perm(), err)
+		}
+
+		// Exporting over a readable archive tightens its mode
+		assertNoError(t, os.Chmod(archive, 0644))
+		_, _, err = runSnag("export-state", "-o", archive, "--cookies-file", cookies)
+		assertNoError(t, err)
+		if info, err := os.Stat(archive); err != nil || info.Mode().Perm() != 0600 {
+			t.Errorf("expected mode 600 after re-export, got %v (%v)", info.Mode().Perm(), err)
+		}
+	}
+
+	_, stderr, err = runSnag("import-state", archive, "-d", filepath.Join(dir, "imported"))
+	assertNoError(t, err)
+	assertContains(t, stderr, "--cookies-file "+filepath.Join(dir, "imported", "cookies.json"))
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// StreamResult is the JSON line written to stdout for each URL fetched in
+// --stream mode.
+type StreamResult struct {
+	URL    string `json:"url"`
+	Output string `json:"output,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// streamURLs reads URLs from r line by line, calling fetch for each as soon
+// as it is read and writing a StreamResult to w. It returns when r reaches
+// EOF.
+func streamURLs(r io.Reader, w io.Writer, fetch func(entry URLEntry) (string, error)) (succeeded, failed int, err error) {
+	scanner := bufio.NewScanner(r)
+	encoder := json.NewEncoder(w)
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+		entry, ok := parseURLLine(scanner.Text(), lineNum)
+		if !ok {
+			continue
+		}
+
+		logger.Info("[%d] Fetching: %s", succeeded+failed+1, entry.URL)
+
+		result := StreamResult{URL: redactIfEnabled(entry.URL)}
+		path, fetchErr := fetch(entry)
+		if fetchErr != nil {
+			logger.Error("[%d] Failed: %v", succeeded+failed+1, fetchErr)
+			result.Error = redactIfEnabled(fetchErr.Error())
+			failed++
+		} else {
+			result.Output = path
+			succeeded++
+		}
+
+		if err := encoder.Encode(result); err != nil {
+			return succeeded, failed, fmt.Errorf("failed to write result: %w", err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return succeeded, failed, fmt.Errorf("error reading URLs: %w", err)
+	}
+
+	return succeeded, failed, nil
+}
+
+// handleStream fetches URLs from --url-file as they arrive, so snag can run
+// as a long-lived worker reading from stdin or a named pipe. Each page is
+// saved to the output directory and its result printed to stdout as JSONL.
+func handleStream(cmd *cobra.Command) error {
+	start := time.Now()
+	outDir := strings.TrimSpace(outputDir)
+	if outDir == "" {
+		outDir = "."
+	}
+
+	outputFormat := normalizeFormat(format)
+	if err := validateFormat(outputFormat); err != nil {
+		return err
+	}
+
+	if err := validateTimeout(timeout); err != nil {
+		return err
+	}
+
+	if err := validatePort(port); err != nil {
+		return err
+	}
+
+	if err := validateDirectory(outDir); err != nil {
+		return err
+	}
+
+	validatedUserDataDir := ""
+	if cmd.Flags().Changed("user-data-dir") {
+		validatedDir, err := validateUserDataDir(userDataDir)
+		if err != nil {
+			return err
+		}
+		validatedUserDataDir = validatedDir
+	}
+
+	networkConditions, err := validateThrottle(throttle)
+	if err != nil {
+		return err
+	}
+
+	validatedWaitFor := validateWaitFor(waitFor, cmd.Flags().Changed("wait-for"))
+
+	source := strings.TrimSpace(urlFile)
+	var reader io.Reader = os.Stdin
+	if source != "-" {
+		// Opening a named pipe blocks until a writer connects
+		file, err := os.Open(source)
+		if err != nil {
+			logger.Error("Failed to open URL file: %s", source)
+			return fmt.Errorf("failed to open URL file: %w", err)
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	bm := NewBrowserManager(BrowserOptions{
+		Port:          port,
+		ForceHeadless: forceHead,
+		UserAgent:     validateUserAgent(userAgent, cmd.Flags().Changed("user-agent")),
+		UserDataDir:   validatedUserDataDir,
+		Throttle:      networkConditions,
+		MemoryLimit:   browserMemoryLimit(),
+		ReadOnly:      readOnly,
+		Offline:       offline,
+		RemoteOnly:    k8sPod != "",
+	})
+	browserMutex.Lock()
+	browserManager = bm
+	browserMutex.Unlock()
+	defer func() {
+		bm.Close()
+		browserMutex.Lock()
+		browserManager = nil
+		browserMutex.Unlock()
+	}()
+
+	if _, err := bm.Connect(); err != nil {
+		return err
+	}
+
+	logger.Verbose("Streaming URLs from %s...", source)
+
+	var dash *Dashboard
+	if tui {
+		dash = StartDashboard(0)
+	}
+	defer dash.Stop()
+
+	succeeded, failed, err := streamURLs(reader, os.Stdout, func(entry URLEntry) (string, error) {
+		if err := bm.RestartIfOverMemory(); err != nil {
+			return "", err
+		}
+		dash.Begin(entry.URL)
+		path, err := fetchToFile(bm, entry.URL, outputFormat, validatedWaitFor, outDir, entry.Name)
+		dash.Done(err == nil, err != nil)
+		return path, err
+	})
+	dash.Stop()
+	if err != nil {
+		logger.Error("%v", err)
+		return err
+	}
+
+	logger.Success("Stream complete: %d succeeded, %d failed", succeeded, failed)
+	reportRunSummary(succeeded, failed, 0, start)
+
+	if failed > 0 {
+		return fmt.Errorf("stream processing completed with %d failures", failed)
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestStreamURLs(t *testing.T) {
+	input := strings.NewReader(`# queue
+example.com
+https://fail.example.com  # will fail
+
+not a url
+`)
+
+	var out bytes.Buffer
+	var fetched []string
+	succeeded, failed, err := streamURLs(input, &out, func(entry URLEntry) (string, error) {
+		fetched = append(fetched, entry.URL)
+		if strings.Contains(entry.URL, "fail") {
+			return "", errors.New("navigation failed")
+		}
+		return "out/page.md", nil
+	})
+	if err != nil {
+		t.Fatalf("streamURLs failed: %v", err)
+	}
+	if succeeded != 1 || failed != 1 {
+		t.Errorf("expected 1 succeeded and 1 failed, got %d and %d", succeeded, failed)
+	}
+	if want := []string{"https://example.com", "https://fail.example.com"}; strings.Join(fetched, " ") != strings.Join(want, " ") {
+		t.Errorf("fetched %v, want %v", fetched, want)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 JSONL records, got %d: %q", len(lines), out.String())
+	}
+
+	var first, second StreamResult
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("invalid JSON %q: %v", lines[0], err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &second); err != nil {
+		t.Fatalf("invalid JSON %q: %v", lines[1], err)
+	}
+	if first.URL != "https://example.com" || first.Output != "out/page.md" || first.Error != "" {
+		t.Errorf("unexpected first result: %+v", first)
+	}
+	if second.Error != "navigation failed" || second.Output != "" {
+		t.Errorf("unexpected second result: %+v", second)
+	}
+}
+
+func TestStreamURLs_FetchesBeforeEOF(t *testing.T) {
+	pr, pw := io.Pipe()
+	fetched := make(chan string)
+
+	done := make(chan error)
+	go func() {
+		_, _, err := streamURLs(pr, io.Discard, func(entry URLEntry) (string, error) {
+			fetched <- entry.URL
+			return "", nil
+		})
+		done <- err
+	}()
+
+	// The first URL must be fetched while the writer is still open
+	go pw.Write([]byte("example.com\n"))
+	if got := <-fetched; got != "https://example.com" {
+		t.Errorf("expected https://example.com, got %s", got)
+	}
+
+	pw.Close()
+	if err := <-done; err != nil {
+		t.Errorf("streamURLs failed: %v", err)
+	}
+}
+
+func TestCLI_StreamRequiresURLFile(t *testing.T) {
+	_, stderr, err := runSnag("--stream")
+	assertError(t, err)
+	assertContains(t, stderr, "--stream requires --url-file")
+}
+
+func TestCLI_StreamWithOutput(t *testing.T) {
+	_, stderr, err := runSnag("--url-file", "-", "--stream", "-o", "out.md")
+	assertError(t, err)
+	assertContains(t, stderr, "Cannot use --output with --stream")
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// RunSummary holds the outcome counts of a batch run for the final
+// summary line.
+type RunSummary struct {
+	OK       int
+	Failed   int
+	Skipped  int
+	Duration time.Duration
+}
+
+// String formats the summary as a single line of key=value pairs that
+// scripts can parse, e.g. "snag: ok=42 fail=3 skipped=5 duration=338s".
+func (s RunSummary) String() string {
+	return fmt.Sprintf("snag: ok=%d fail=%d skipped=%d duration=%ds",
+		s.OK, s.Failed, s.Skipped, int(s.Duration.Round(time.Second).Seconds()))
+}
+
+// Message describes the summary in words for desktop notifications.
+func (s RunSummary) Message() string {
+	msg := fmt.Sprintf("Batch complete: %d succeeded, %d failed", s.OK, s.Failed)
+	if s.Skipped > 0 {
+		msg += fmt.Sprintf(", %d skipped", s.Skipped)
+	}
+	return msg + " in " + s.Duration.Round(time.Second).String()
+}
+
+// reportRunSummary prints the summary line of a batch that started at start
+// to stderr, whatever the log level, writes it to --summary-file, and sends
+// a --notify-desktop notification.
+func reportRunSummary(ok, failed, skipped int, start time.Time) {
+	summary := RunSummary{
+		OK:       ok,
+		Failed:   failed,
+		Skipped:  skipped,
+		Duration: time.Since(start),
+	}
+	line := summary.String()
+
+	if logger.json {
+		logger.writeJSON("summary", line, "")
+	} else {
+		fmt.Fprintln(os.Stderr, line)
+	}
+
+	if summaryFile != "" {
+		if err := os.WriteFile(summaryFile, []byte(line+"\n"), DefaultFileMode); err != nil {
+			logger.Warning("Failed to write summary file: %v", err)
+		}
+	}
+
+	if desktopNotify {
+		notifyDesktop(summary.Message())
+	}
+}
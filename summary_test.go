@@ -0,0 +1,60 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunSummaryString(t *testing.T) {
+	tests := []struct {
+		summary RunSummary
+		want    string
+	}{
+		{RunSummary{}, "snag: ok=0 fail=0 skipped=0 duration=0s"},
+		{RunSummary{OK: 42, Failed: 3, Skipped: 5, Duration: 338 * time.Second}, "snag: ok=42 fail=3 skipped=5 duration=338s"},
+		{RunSummary{OK: 1, Duration: 1600 * time.Millisecond}, "snag: ok=1 fail=0 skipped=0 duration=2s"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.summary.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestRunSummaryMessage(t *testing.T) {
+	summary := RunSummary{OK: 42, Failed: 3, Duration: 338 * time.Second}
+	if got, want := summary.Message(), "Batch complete: 42 succeeded, 3 failed in 5m38s"; got != want {
+		t.Errorf("Message() = %q, want %q", got, want)
+	}
+
+	summary.Skipped = 5
+	if got, want := summary.Message(), "Batch complete: 42 succeeded, 3 failed, 5 skipped in 5m38s"; got != want {
+		t.Errorf("Message() = %q, want %q", got, want)
+	}
+}
+
+func TestReportRunSummary_File(t *testing.T) {
+	logger = NewLogger(LevelQuiet)
+	summaryFile = filepath.Join(t.TempDir(), "summary.txt")
+	defer func() { summaryFile = "" }()
+
+	reportRunSummary(2, 1, 0, time.Now())
+
+	data, err := os.ReadFile(summaryFile)
+	if err != nil {
+		t.Fatalf("failed to read summary file: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "snag: ok=2 fail=1 skipped=0 duration=") || !strings.HasSuffix(string(data), "s\n") {
+		t.Errorf("summary file = %q", data)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tarOutput, when set by --output-tar, receives every output file in place
+// of the filesystem.
+var tarOutput *TarOutput
+
+// TarOutput writes output files as entries of a tar stream so batch results
+// can be piped straight into tar, ssh, or an uploader.
+type TarOutput struct {
+	mu     sync.Mutex
+	tw     *tar.Writer
+	closer io.Closer
+	names  map[string]bool
+}
+
+func NewTarOutput(w io.Writer) *TarOutput {
+	return &TarOutput{
+		tw:    tar.NewWriter(w),
+		names: make(map[string]bool),
+	}
+}
+
+// openTarOutput creates a TarOutput writing to dest, or stdout when dest is
+// "-". Like tar itself, it refuses to write an archive to a terminal.
+func openTarOutput(dest string) (*TarOutput, error) {
+	if dest == "-" {
+		if fileInfo, err := os.Stdout.Stat(); err == nil && fileInfo.Mode()&os.ModeCharDevice != 0 {
+			logger.Error("Refusing to write tar archive to a terminal")
+			logger.ErrorWithSuggestion(
+				"Pipe or redirect stdout when using --output-tar -",
+				"snag --url-file urls.txt --output-tar - | tar -x -C pages/",
+			)
+			return nil, fmt.Errorf("refusing to write tar archive to a terminal")
+		}
+		return NewTarOutput(os.Stdout), nil
+	}
+
+	if err := validateOutputPath(dest); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Create(dest)
+	if err != nil {
+		logger.Error("Failed to create tar file: %s", dest)
+		return nil, fmt.Errorf("failed to create tar file: %w", err)
+	}
+
+	t := NewTarOutput(file)
+	t.closer = file
+	return t, nil
+}
+
+// entryName converts an output path to a tar entry name.
+func entryName(filename string) string {
+	return strings.TrimPrefix(path.Clean(filepath.ToSlash(filename)), "/")
+}
+
+// Reserve returns a name for filename that is not already used in the
+// archive, adding a numeric suffix on conflict as ResolveConflict does on
+// disk.
+func (t *TarOutput) Reserve(filename string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	name := entryName(filename)
+	if !t.names[name] {
+		t.names[name] = true
+		return name
+	}
+
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for counter := 1; ; counter++ {
+		candidate := fmt.Sprintf("%s-%d%s", base, counter, ext)
+		if !t.names[candidate] {
+			t.names[candidate] = true
+			return candidate
+		}
+	}
+}
+
+// WriteFile adds data to the archive as a regular file.
+func (t *TarOutput) WriteFile(filename string, data []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	name := entryName(filename)
+	t.names[name] = true
+
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     DefaultFileMode,
+		Size:     int64(len(data)),
+		ModTime:  time.Now(),
+	}
+	if err := t.tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write tar header for %s: %w", name, err)
+	}
+	if _, err := t.tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s to tar: %w", name, err)
+	}
+	// Flush so each entry reaches the consumer as soon as it is complete
+	if err := t.tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write %s to tar: %w", name, err)
+	}
+
+	sizeKB := float64(len(data)) / BytesPerKB
+	logger.Success("Added %s to tar (%.1f KB)", name, sizeKB)
+	return nil
+}
+
+// Close writes the archive trailer and closes the destination file.
+func (t *TarOutput) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	err := t.tw.Close()
+	if t.closer != nil {
+		if cerr := t.closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to finish tar archive: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestTarOutput_WriteFile(t *testing.T) {
+	var buf bytes.Buffer
+	out := NewTarOutput(&buf)
+
+	if err := out.WriteFile("./one.md", []byte("# One")); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := out.WriteFile("shots/two.png", []byte{0x89, 'P', 'N', 'G'}); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := out.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	tr := tar.NewReader(&buf)
+	want := map[string]string{"one.md": "# One", "shots/two.png": "\x89PNG"}
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid tar stream: %v", err)
+		}
+		data, _ := io.ReadAll(tr)
+		expected, ok := want[header.Name]
+		if !ok {
+			t.Errorf("unexpected entry %q", header.Name)
+			continue
+		}
+		if string(data) != expected {
+			t.Errorf("entry %q = %q, want %q", header.Name, data, expected)
+		}
+		if header.Mode != DefaultFileMode {
+			t.Errorf("entry %q mode = %o, want %o", header.Name, header.Mode, DefaultFileMode)
+		}
+		delete(want, header.Name)
+	}
+	if len(want) > 0 {
+		t.Errorf("missing entries: %v", want)
+	}
+}
+
+func TestTarOutput_Reserve(t *testing.T) {
+	out := NewTarOutput(io.Discard)
+
+	names := []string{
+		out.Reserve("page.md"),
+		out.Reserve("./page.md"),
+		out.Reserve("page.md"),
+		out.Reserve("other.md"),
+	}
+	want := []string{"page.md", "page-1.md", "page-2.md", "other.md"}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("Reserve #%d = %q, want %q", i+1, names[i], want[i])
+		}
+	}
+}
+
+func TestCLI_OutputTarRequiresBatch(t *testing.T) {
+	_, stderr, err := runSnag("--output-tar", "out.tar", "example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "--output-tar requires multiple URLs")
+}
+
+func TestCLI_OutputTarWithOutputDir(t *testing.T) {
+	_, stderr, err := runSnag("--output-tar", "-", "-d", "out", "example.com", "example.org")
+	assertError(t, err)
+	assertContains(t, stderr, "Cannot use --output or --output-dir with --output-tar")
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+// TemplateData is the page result a --template file is executed against.
+// Metadata holds the page's <meta> tags by name or property.
+type TemplateData struct {
+	Title     string
+	URL       string
+	Domain    string
+	Timestamp string
+	Markdown  string
+	Text      string
+	HTML      string
+	Links     []string
+	Metadata  map[string]string
+}
+
+// outputTemplate is the parsed --template, or nil when output uses --format.
+var outputTemplate *template.Template
+
+// templateFuncs are the helpers available in --template files on top of the
+// text/template builtins.
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"trim":  strings.TrimSpace,
+}
+
+// loadOutputTemplate reads and parses the template file at path.
+func loadOutputTemplate(path string) (*template.Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return template.New(filepath.Base(path)).
+		Funcs(templateFuncs).
+		Option("missingkey=zero").
+		Parse(string(data))
+}
+
+// extractLinks returns the unique absolute URLs of the page's links.
+func extractLinks(page *rod.Page) ([]string, error) {
+	res, err := page.Eval(`() => [...new Set([...document.links].map(a => a.href))]`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page links: %w", err)
+	}
+	links := []string{}
+	for _, link := range res.Value.Arr() {
+		links = append(links, link.Str())
+	}
+	return links, nil
+}
+
+// extractMetadata returns the content of the page's <meta> tags keyed by
+// their name or property, such as "description" or "og:image".
+func extractMetadata(page *rod.Page) map[string]string {
+	metadata := make(map[string]string)
+	res, err := page.Eval(`() => [...document.querySelectorAll('meta[content]')]
+		.map(m => [m.getAttribute('name') || m.getAttribute('property') || '', m.content])
+		.filter(([key]) => key)`)
+	if err != nil {
+		logger.Debug("Failed to read meta tags: %v", err)
+		return metadata
+	}
+	for _, pair := range res.Value.Arr() {
+		kv := pair.Arr()
+		if len(kv) == 2 {
+			metadata[strings.ToLower(kv[0].Str())] = kv[1].Str()
+		}
+	}
+	return metadata
+}
+
+// newTemplateData collects the result of page, whose HTML has already been
+// extracted, for a --template.
+func newTemplateData(page *rod.Page, html string) (*TemplateData, error) {
+	info, err := page.Info()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page info: %w", err)
+	}
+
+	links, err := extractLinks(page)
+	if err != nil {
+		return nil, err
+	}
+
+	markdown, err := NewContentConverter(FormatMarkdown).Convert(html)
+	if err != nil {
+		return nil, err
+	}
+
+	// --sanitize covers the HTML a template passes through, as with --format html
+	rawHTML := html
+	if sanitize {
+		rawHTML, err = sanitizeHTML(html)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &TemplateData{
+		Title:     info.Title,
+		URL:       info.URL,
+		Domain:    extractDomain(info.URL),
+		Timestamp: time.Now().Format(time.RFC3339),
+		Markdown:  markdown,
+		Text:      NewContentConverter(FormatText).extractPlainText(html),
+		HTML:      rawHTML,
+		Links:     links,
+		Metadata:  extractMetadata(page),
+	}, nil
+}
+
+// renderTemplate executes tmpl against data.
+func renderTemplate(tmpl *template.Template, data *TemplateData) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+	return b.String(), nil
+}
+
+// ProcessTemplate renders the page through tmpl and writes the result like
+// text output, honouring --eol and --bom.
+func (cc *ContentConverter) ProcessTemplate(page *rod.Page, html string, tmpl *template.Template, outputFile string) error {
+	logger.Verbose("Rendering template %s...", tmpl.Name())
+
+	if cc.mainContent {
+		logger.Verbose("Extracting main content...")
+		article, err := extractMainContent(html)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrConversionFailed, err)
+		}
+		html = article
+	}
+
+	data, err := newTemplateData(page, html)
+	if err != nil {
+		return err
+	}
+
+	content, err := renderTemplate(tmpl, data)
+	if err != nil {
+		return err
+	}
+
+	content = cc.encodeText(redactContent(content))
+	if outputFile != "" {
+		return cc.writeToFile(content, outputFile)
+	}
+	return cc.writeToStdout(content)
+}
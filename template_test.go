@@ -0,0 +1,71 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"text/template"
+)
+
+func loadTestTemplate(t *testing.T, source string) *template.Template {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "digest.tmpl")
+	if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := loadOutputTemplate(path)
+	if err != nil {
+		t.Fatalf("loadOutputTemplate failed: %v", err)
+	}
+	return tmpl
+}
+
+func TestRenderTemplate(t *testing.T) {
+	tmpl := loadTestTemplate(t, `* {{upper .Title}}
+{{index .Metadata "description"}}{{index .Metadata "keywords"}}
+{{join .Links ", "}}
+`)
+	if tmpl.Name() != "digest.tmpl" {
+		t.Errorf("template name = %s, want digest.tmpl", tmpl.Name())
+	}
+
+	got, err := renderTemplate(tmpl, &TemplateData{
+		Title:    "Example",
+		Links:    []string{"https://a.example", "https://b.example"},
+		Metadata: map[string]string{"description": "An example page"},
+	})
+	if err != nil {
+		t.Fatalf("renderTemplate failed: %v", err)
+	}
+
+	want := "* EXAMPLE\nAn example page\nhttps://a.example, https://b.example\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRenderTemplate_UnknownField(t *testing.T) {
+	tmpl := loadTestTemplate(t, "{{.Body}}")
+	if _, err := renderTemplate(tmpl, &TemplateData{}); err == nil {
+		t.Error("expected error for unknown field")
+	}
+}
+
+func TestLoadOutputTemplate_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.tmpl")
+	if err := os.WriteFile(path, []byte("{{.Title"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadOutputTemplate(path); err == nil {
+		t.Error("expected parse error")
+	}
+	if _, err := loadOutputTemplate(filepath.Join(t.TempDir(), "missing.tmpl")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
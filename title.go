@@ -0,0 +1,213 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"golang.org/x/net/html"
+)
+
+const (
+	TitleSourceOG    = "og"
+	TitleSourceTitle = "title"
+	TitleSourceH1    = "h1"
+	TitleSourcePath  = "path"
+)
+
+// DefaultTitleWait is how long generated filenames wait for a page to
+// show a real title, and TitlePollInterval how often it is checked.
+const (
+	DefaultTitleWait  = 3 * time.Second
+	TitlePollInterval = 100 * time.Millisecond
+)
+
+// DefaultTitleSources is the order filenames take their title from when
+// --title-from is not set. The URL host is always the last resort.
+var DefaultTitleSources = []string{TitleSourceOG, TitleSourceTitle, TitleSourceH1, TitleSourcePath}
+
+// placeholderTitles are titles single-page apps show before they render,
+// which make useless filenames.
+var placeholderTitles = []string{
+	"loading", "loading…", "loading...", "please wait", "untitled",
+	"untitled document", "document", "react app", "vite app", "new tab",
+}
+
+// parseTitleSources reads a --title-from list such as "h1,title". It
+// returns DefaultTitleSources for an empty value.
+func parseTitleSources(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return DefaultTitleSources, nil
+	}
+
+	var sources []string
+	for _, s := range strings.Split(value, ",") {
+		s = strings.ToLower(strings.TrimSpace(s))
+		switch s {
+		case "":
+			continue
+		case TitleSourceOG, TitleSourceTitle, TitleSourceH1, TitleSourcePath:
+			if !slices.Contains(sources, s) {
+				sources = append(sources, s)
+			}
+		default:
+			return nil, fmt.Errorf("unknown title source %q", s)
+		}
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no title sources given")
+	}
+	return sources, nil
+}
+
+// filenameTitle picks the title a generated filename is named after: the
+// first --title-from source that gives a real title. title is the page's
+// <title> and pageURL its address. Until one of the page's own sources has
+// a title that is not a placeholder, it waits up to --title-wait for the
+// page to render one. An empty result falls back to the URL host.
+func filenameTitle(page *rod.Page, title, pageURL string) string {
+	sources, _ := parseTitleSources(titleFrom)
+	pageSources := slices.DeleteFunc(slices.Clone(sources), func(s string) bool { return s == TitleSourcePath })
+	if len(pageSources) == 0 {
+		return pickTitle(page, sources, title, pageURL)
+	}
+
+	start := time.Now()
+	for {
+		if t := pickTitle(page, pageSources, title, pageURL); t != "" {
+			if waited := time.Since(start); waited >= TitlePollInterval {
+				logger.Verbose("Waited %s for page title", waited.Round(TitlePollInterval))
+			}
+			return t
+		}
+		if time.Since(start) >= titleWait {
+			break
+		}
+		time.Sleep(TitlePollInterval)
+		if info, err := page.Info(); err == nil {
+			title = info.Title
+		}
+	}
+	if titleWait > 0 {
+		logger.Debug("No page title after %s", titleWait)
+	}
+	return pickTitle(page, sources, title, pageURL)
+}
+
+// pickTitle returns the first real title from sources.
+func pickTitle(page *rod.Page, sources []string, title, pageURL string) string {
+	for _, source := range sources {
+		var candidate string
+		switch source {
+		case TitleSourceOG:
+			candidate = metaContent(page, `meta[property="og:title"], meta[name="og:title"]`)
+		case TitleSourceTitle:
+			candidate = title
+		case TitleSourceH1:
+			candidate = elementText(page, "h1")
+		case TitleSourcePath:
+			candidate = urlPathTitle(pageURL)
+		}
+
+		candidate = collapseSpace(candidate)
+		if candidate == "" || isPlaceholderTitle(candidate) {
+			continue
+		}
+		if source != TitleSourceTitle {
+			logger.Debug("Naming file after %s: %s", source, candidate)
+		}
+		return candidate
+	}
+	return ""
+}
+
+func isPlaceholderTitle(title string) bool {
+	return slices.Contains(placeholderTitles, strings.ToLower(strings.TrimSpace(title)))
+}
+
+// urlPathTitle turns the last segment of a URL path into words, so
+// /docs/getting-started.html gives "getting started".
+func urlPathTitle(pageURL string) string {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+	segment := path.Base(strings.TrimRight(u.Path, "/"))
+	if segment == "." || segment == "/" {
+		return ""
+	}
+	segment = strings.TrimSuffix(segment, path.Ext(segment))
+	if segment == "index" {
+		return urlPathTitle(u.JoinPath("..").String())
+	}
+	return strings.NewReplacer("-", " ", "_", " ", "+", " ").Replace(segment)
+}
+
+// metaContent returns the content attribute of the first element matching
+// selector. It reads the DOM over CDP, so no script runs in the page.
+func metaContent(page *rod.Page, selector string) string {
+	nodeID := querySelector(page, selector)
+	if nodeID == 0 {
+		return ""
+	}
+	attrs, err := proto.DOMGetAttributes{NodeID: nodeID}.Call(page)
+	if err != nil {
+		return ""
+	}
+	for i := 0; i+1 < len(attrs.Attributes); i += 2 {
+		if attrs.Attributes[i] == "content" {
+			return attrs.Attributes[i+1]
+		}
+	}
+	return ""
+}
+
+// elementText returns the text of the first element matching selector.
+func elementText(page *rod.Page, selector string) string {
+	nodeID := querySelector(page, selector)
+	if nodeID == 0 {
+		return ""
+	}
+	res, err := proto.DOMGetOuterHTML{NodeID: nodeID}.Call(page)
+	if err != nil {
+		return ""
+	}
+	doc, err := html.Parse(strings.NewReader(res.OuterHTML))
+	if err != nil {
+		return ""
+	}
+
+	var b strings.Builder
+	for n := range doc.Descendants() {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+	}
+	return b.String()
+}
+
+// querySelector returns the node of the first element matching selector,
+// or 0 when there is none.
+func querySelector(page *rod.Page, selector string) proto.DOMNodeID {
+	doc, err := proto.DOMGetDocument{}.Call(page)
+	if err != nil {
+		logger.Debug("Failed to get document: %v", err)
+		return 0
+	}
+	res, err := proto.DOMQuerySelector{NodeID: doc.Root.NodeID, Selector: selector}.Call(page)
+	if err != nil {
+		return 0
+	}
+	return res.NodeID
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseTitleSources(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{"", DefaultTitleSources, false},
+		{"h1", []string{"h1"}, false},
+		{" H1 , title,h1 ", []string{"h1", "title"}, false},
+		{"path,og", []string{"path", "og"}, false},
+		{"heading", nil, true},
+		{",", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseTitleSources(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseTitleSources(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("parseTitleSources(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestURLPathTitle(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://example.com/docs/getting-started.html", "getting started"},
+		{"https://example.com/docs/api_reference/", "api reference"},
+		{"https://example.com/guide/index.html", "guide"},
+		{"https://example.com/", ""},
+		{"https://example.com", ""},
+		{"https://example.com/index.html", ""},
+	}
+
+	for _, tt := range tests {
+		if got := urlPathTitle(tt.url); got != tt.want {
+			t.Errorf("urlPathTitle(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestIsPlaceholderTitle(t *testing.T) {
+	for _, title := range []string{"Loading…", "loading...", " React App ", "Untitled"} {
+		if !isPlaceholderTitle(title) {
+			t.Errorf("isPlaceholderTitle(%q) = false, want true", title)
+		}
+	}
+	for _, title := range []string{"Loading Docks of Rotterdam", "Documentation"} {
+		if isPlaceholderTitle(title) {
+			t.Errorf("isPlaceholderTitle(%q) = true, want false", title)
+		}
+	}
+}
+
+func TestCLI_TitleFromInvalid(t *testing.T) {
+	_, stderr, err := runSnag("--title-from", "og,heading", "example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "Invalid --title-from")
+}
+
+func TestBrowser_TitleFallback(t *testing.T) {
+	if !isBrowserAvailable() {
+		t.Skip("Browser not available, skipping browser integration test")
+	}
+
+	server := startTestServer(t)
+
+	tests := []struct {
+		titleFrom string
+		want      string
+	}{
+		{"", "-release-notes.md"},
+		{"path", "-placeholder-title.md"},
+		{"title", "-127-0-0-1-"},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		args := []string{"-d", dir, server.URL + "/placeholder-title.html"}
+		if tt.titleFrom != "" {
+			args = append([]string{"--title-from", tt.titleFrom}, args...)
+		}
+		_, _, err := runSnag(args...)
+		assertNoError(t, err)
+
+		entries, err := os.ReadDir(dir)
+		assertNoError(t, err)
+		if len(entries) != 1 || !strings.Contains(entries[0].Name(), tt.want) {
+			t.Errorf("--title-from %q wrote %v, want a name containing %q", tt.titleFrom, entries, tt.want)
+		}
+	}
+
+	// The title is set after load, within --title-wait
+	dir := t.TempDir()
+	_, _, err := runSnag("--title-from", "title", "-d", dir, server.URL+"/placeholder-title.html#late-title")
+	assertNoError(t, err)
+	entries, err := os.ReadDir(dir)
+	assertNoError(t, err)
+	if len(entries) != 1 || !strings.Contains(entries[0].Name(), "-changelog.md") {
+		t.Errorf("expected file named after the late title, got %v", entries)
+	}
+}
+
+func TestCLI_TitleWaitInvalid(t *testing.T) {
+	_, stderr, err := runSnag("--title-wait", "-1s", "example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "Invalid --title-wait")
+}
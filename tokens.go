@@ -0,0 +1,195 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	TokenOverflowTruncate = "truncate"
+	TokenOverflowSplit    = "split"
+
+	// CharsPerToken is the average length of an English word piece in
+	// cl100k-style tokenizers.
+	CharsPerToken = 4
+)
+
+// estimateTokens approximates the token count of content the way
+// cl100k-style BPE tokenizers behave on typical text: runs of ASCII letters
+// and digits cost one token per CharsPerToken characters, each punctuation
+// mark or symbol costs one token, and other scripts cost one token per
+// character. Single spaces merge into the following word.
+func estimateTokens(content string) int {
+	tokens := 0
+	run := 0
+	newlines := 0
+
+	endRun := func() {
+		if run > 0 {
+			tokens += (run + CharsPerToken - 1) / CharsPerToken
+			run = 0
+		}
+	}
+
+	for _, r := range content {
+		switch {
+		case r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			run++
+			newlines = 0
+		case r == '\n':
+			endRun()
+			// Consecutive newlines share a token
+			if newlines == 0 {
+				tokens++
+			}
+			newlines++
+		case unicode.IsSpace(r):
+			endRun()
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			endRun()
+			tokens++
+			newlines = 0
+		default:
+			endRun()
+			tokens++
+			newlines = 0
+		}
+	}
+	endRun()
+
+	return tokens
+}
+
+// chunkByTokens splits content into chunks of at most maxTokens estimated
+// tokens. It breaks between paragraphs where possible, then between lines,
+// and only cuts inside a line that is over budget on its own.
+func chunkByTokens(content string, maxTokens int) []string {
+	if estimateTokens(content) <= maxTokens {
+		return []string{content}
+	}
+
+	var chunks []string
+	var current strings.Builder
+
+	add := func(piece string) {
+		if current.Len() > 0 && estimateTokens(current.String()+piece) > maxTokens {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+		current.WriteString(piece)
+	}
+
+	for _, paragraph := range strings.SplitAfter(content, "\n\n") {
+		if estimateTokens(paragraph) <= maxTokens {
+			add(paragraph)
+			continue
+		}
+		for _, line := range strings.SplitAfter(paragraph, "\n") {
+			if estimateTokens(line) <= maxTokens {
+				add(line)
+				continue
+			}
+			for _, piece := range cutByTokens(line, maxTokens) {
+				add(piece)
+			}
+		}
+	}
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+
+	return chunks
+}
+
+// cutByTokens cuts a single line into pieces of at most maxTokens, breaking
+// after whitespace where possible.
+func cutByTokens(line string, maxTokens int) []string {
+	var pieces []string
+
+	for line != "" {
+		if estimateTokens(line) <= maxTokens {
+			pieces = append(pieces, line)
+			break
+		}
+
+		// Find the longest prefix within budget
+		lo, hi := 1, len(line)
+		for lo < hi {
+			mid := (lo + hi + 1) / 2
+			for mid > lo && !utf8.RuneStart(line[mid]) {
+				mid--
+			}
+			if estimateTokens(line[:mid]) <= maxTokens {
+				lo = mid
+			} else {
+				hi = mid - 1
+			}
+		}
+		for lo < len(line) && !utf8.RuneStart(line[lo]) {
+			lo++
+		}
+
+		cut := lo
+		if space := strings.LastIndexAny(line[:lo], " \t"); space > 0 {
+			cut = space + 1
+		}
+
+		pieces = append(pieces, line[:cut])
+		line = line[cut:]
+	}
+
+	return pieces
+}
+
+// truncateToTokens shortens content to about maxTokens estimated tokens,
+// ending it with a marker giving the original size.
+func truncateToTokens(content string, maxTokens int) string {
+	total := estimateTokens(content)
+	if total <= maxTokens {
+		return content
+	}
+
+	marker := fmt.Sprintf("\n\n[Truncated: ~%d of ~%d tokens]\n", maxTokens, total)
+	budget := max(maxTokens-estimateTokens(marker), 1)
+
+	kept := strings.TrimRight(chunkByTokens(content, budget)[0], "\n")
+	return kept + marker
+}
+
+// tokenChunkFilename names chunk index of outputFile, e.g. page.md becomes
+// page-02.md.
+func tokenChunkFilename(outputFile string, index int) string {
+	ext := filepath.Ext(outputFile)
+	return fmt.Sprintf("%s-%02d%s", strings.TrimSuffix(outputFile, ext), index, ext)
+}
+
+// applyTokenBudget truncates content to cc.maxTokens with
+// --token-overflow truncate.
+func (cc *ContentConverter) applyTokenBudget(content string) string {
+	logger.Verbose("Estimated %d tokens (budget %d)", estimateTokens(content), cc.maxTokens)
+	return truncateToTokens(content, cc.maxTokens)
+}
+
+// writeTokenChunks writes content split into chunks of at most cc.maxTokens
+// estimated tokens, each to its own numbered file named after outputFile.
+func (cc *ContentConverter) writeTokenChunks(content string, outputFile string) error {
+	chunks := chunkByTokens(content, cc.maxTokens)
+	logger.Verbose("Estimated %d tokens, split into %d files of up to %d", estimateTokens(content), len(chunks), cc.maxTokens)
+
+	for i, chunk := range chunks {
+		if err := cc.writeToFile(cc.encodeText(chunk), tokenChunkFilename(outputFile, i+1)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
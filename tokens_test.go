@@ -0,0 +1,106 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"", 0},
+		{"hello", 2},
+		{"hello world", 4},
+		{"Hi, there!", 5},
+		{"a\n\n\nb", 3},
+		{"日本語", 3},
+	}
+
+	for _, tt := range tests {
+		if got := estimateTokens(tt.input); got != tt.expected {
+			t.Errorf("estimateTokens(%q) = %d, want %d", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestChunkByTokens(t *testing.T) {
+	paragraph := strings.Repeat("word ", 20) + "\n\n"
+	content := strings.Repeat(paragraph, 5)
+
+	// Each paragraph is 21 tokens, so two fit per chunk
+	chunks := chunkByTokens(content, 50)
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %d", len(chunks))
+	}
+	if strings.Join(chunks, "") != content {
+		t.Error("chunks should join back to the original content")
+	}
+	for i, chunk := range chunks {
+		if tokens := estimateTokens(chunk); tokens > 50 {
+			t.Errorf("chunk %d has %d tokens, over budget", i, tokens)
+		}
+	}
+
+	// A single long line is cut at word boundaries
+	long := strings.Repeat("word ", 100)
+	chunks = chunkByTokens(long, 30)
+	if strings.Join(chunks, "") != long {
+		t.Error("cut chunks should join back to the original line")
+	}
+	for i, chunk := range chunks {
+		if tokens := estimateTokens(chunk); tokens > 30 {
+			t.Errorf("chunk %d has %d tokens, over budget", i, tokens)
+		}
+		if !strings.HasSuffix(chunk, " ") {
+			t.Errorf("chunk %d should end at a word boundary: %q", i, chunk)
+		}
+	}
+}
+
+func TestTruncateToTokens(t *testing.T) {
+	short := "A short page.\n"
+	if got := truncateToTokens(short, 100); got != short {
+		t.Errorf("content within budget should be unchanged, got %q", got)
+	}
+
+	content := strings.Repeat("Some paragraph text here.\n\n", 100)
+	got := truncateToTokens(content, 60)
+	if !strings.Contains(got, "[Truncated: ~60 of ~") {
+		t.Errorf("missing truncation marker: %q", got)
+	}
+	if tokens := estimateTokens(got); tokens > 60 {
+		t.Errorf("truncated content has %d tokens, over budget", tokens)
+	}
+}
+
+func TestCLI_ConvertTokenSplit(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "page.html")
+	os.WriteFile(input, []byte(strings.Repeat("<p>"+strings.Repeat("word ", 40)+"</p>", 3)), 0644)
+
+	if _, _, err := runSnag("convert", input, "--max-tokens", "60", "--token-overflow", "split", "-o", filepath.Join(dir, "page.md")); err != nil {
+		t.Fatalf("convert failed: %v", err)
+	}
+
+	for _, name := range []string{"page-01.md", "page-02.md", "page-03.md"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %s: %v", name, err)
+		}
+	}
+}
+
+func TestCLI_InvalidTokenOverflow(t *testing.T) {
+	_, stderr, err := runSnag("--max-tokens", "100", "--token-overflow", "drop", "example.com")
+	assertError(t, err)
+	assertContains(t, stderr, "Invalid --token-overflow")
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// HTTPTrace logs the main document's requests and responses as they happen,
+// in the style of curl -v, for --trace-http. Subresources are left out.
+type HTTPTrace struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu        sync.Mutex
+	requestID proto.NetworkRequestID
+	request   *proto.NetworkRequest
+}
+
+// StartHTTPTrace begins tracing main document requests on page. Call Stop
+// once navigation has finished.
+func StartHTTPTrace(page *rod.Page) *HTTPTrace {
+	ctx, cancel := context.WithCancel(context.Background())
+	ht := &HTTPTrace{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	wait := page.Context(ctx).EachEvent(
+		func(e *proto.NetworkRequestWillBeSent) {
+			if e.Type != proto.NetworkResourceTypeDocument || e.FrameID != page.FrameID {
+				return
+			}
+			ht.mu.Lock()
+			defer ht.mu.Unlock()
+			if e.RedirectResponse != nil && ht.request != nil {
+				logger.Trace(traceExchange(ht.request, e.RedirectResponse) +
+					fmt.Sprintf("* Issue another request to this URL: '%s'\n", e.Request.URL))
+			}
+			ht.requestID = e.RequestID
+			ht.request = e.Request
+		},
+		func(e *proto.NetworkResponseReceived) {
+			if e.Type != proto.NetworkResourceTypeDocument || e.FrameID != page.FrameID {
+				return
+			}
+			ht.mu.Lock()
+			defer ht.mu.Unlock()
+			if ht.request == nil || e.RequestID != ht.requestID {
+				return
+			}
+			logger.Trace(traceExchange(ht.request, e.Response))
+			ht.request = nil
+		},
+		func(e *proto.NetworkLoadingFailed) {
+			ht.mu.Lock()
+			defer ht.mu.Unlock()
+			if ht.request == nil || e.RequestID != ht.requestID {
+				return
+			}
+			logger.Trace(traceRequest(ht.request, nil) + fmt.Sprintf("* Request failed: %s\n", e.ErrorText))
+			ht.request = nil
+		},
+	)
+
+	go func() {
+		defer close(ht.done)
+		wait()
+	}()
+
+	return ht
+}
+
+// Stop ends tracing and waits for the event listener to exit.
+func (ht *HTTPTrace) Stop() {
+	ht.cancel()
+	<-ht.done
+}
+
+// traceExchange formats a request and the response it got as curl -v
+// does: connection notes with *, request lines with >, and response lines
+// with <.
+func traceExchange(request *proto.NetworkRequest, response *proto.NetworkResponse) string {
+	var b strings.Builder
+	host := traceHost(request.URL)
+
+	switch {
+	case response.FromDiskCache || response.FromPrefetchCache:
+		b.WriteString("* Served from cache\n")
+	case response.FromServiceWorker:
+		b.WriteString("* Served by service worker\n")
+	case response.ConnectionReused:
+		fmt.Fprintf(&b, "* Re-using existing connection with host %s\n", host)
+	case response.RemoteIPAddress != "":
+		remote := response.RemoteIPAddress
+		if response.RemotePort != nil {
+			remote += " port " + strconv.Itoa(*response.RemotePort)
+		}
+		fmt.Fprintf(&b, "* Connected to %s (%s)\n", host, remote)
+	}
+
+	b.WriteString(traceRequest(request, response))
+
+	fmt.Fprintf(&b, "< %s %d", traceProtocol(response.Protocol), response.Status)
+	if response.StatusText != "" {
+		b.WriteString(" " + response.StatusText)
+	}
+	b.WriteString("\n")
+	for _, line := range traceHeaders(response.Headers) {
+		b.WriteString("< " + line + "\n")
+	}
+	b.WriteString("<\n")
+	return b.String()
+}
+
+// traceRequest formats the request line and headers. The headers Chromium
+// sent on the wire, when the response reports them, replace those the
+// page asked for, as they include Host, cookies, and the like.
+func traceRequest(request *proto.NetworkRequest, response *proto.NetworkResponse) string {
+	var b strings.Builder
+
+	target := request.URL
+	if u, err := url.Parse(request.URL); err == nil {
+		target = u.RequestURI()
+	}
+	protocol := "HTTP/1.1"
+	headers := request.Headers
+	if response != nil {
+		protocol = traceProtocol(response.Protocol)
+		if len(response.RequestHeaders) > 0 {
+			headers = response.RequestHeaders
+		}
+	}
+
+	fmt.Fprintf(&b, "> %s %s %s\n", request.Method, target, protocol)
+	lines := traceHeaders(headers)
+	if !slices.ContainsFunc(lines, func(line string) bool {
+		return strings.HasPrefix(strings.ToLower(line), "host:") || strings.HasPrefix(line, ":authority:")
+	}) {
+		b.WriteString("> Host: " + traceHost(request.URL) + "\n")
+	}
+	for _, line := range lines {
+		b.WriteString("> " + line + "\n")
+	}
+	b.WriteString(">\n")
+	return b.String()
+}
+
+// traceProtocol turns Chromium's protocol names, such as h2 and
+// http/1.1, into the versions curl shows.
+func traceProtocol(protocol string) string {
+	switch strings.ToLower(protocol) {
+	case "h2":
+		return "HTTP/2"
+	case "h3", "h3-29":
+		return "HTTP/3"
+	case "http/1.0":
+		return "HTTP/1.0"
+	}
+	return "HTTP/1.1"
+}
+
+// traceHeaders returns headers as "Name: value" lines sorted by name.
+// Values holding several headers of one name, joined with newlines by
+// Chromium, become one line each.
+func traceHeaders(headers proto.NetworkHeaders) []string {
+	var lines []string
+	for name, value := range headers {
+		for _, v := range strings.Split(value.Str(), "\n") {
+			lines = append(lines, name+": "+v)
+		}
+	}
+	slices.SortStableFunc(lines, func(a, b string) int {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	})
+	return lines
+}
+
+func traceHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Host
+}
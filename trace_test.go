@@ -0,0 +1,88 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/ysmood/gson"
+)
+
+func TestTraceExchange(t *testing.T) {
+	port := 443
+	request := &proto.NetworkRequest{
+		URL:    "https://example.com/docs?page=2",
+		Method: "GET",
+		Headers: proto.NetworkHeaders{
+			"User-Agent": gson.New("snag-test"),
+		},
+	}
+	response := &proto.NetworkResponse{
+		Status:          301,
+		StatusText:      "Moved Permanently",
+		Protocol:        "h2",
+		RemoteIPAddress: "93.184.216.34",
+		RemotePort:      &port,
+		Headers: proto.NetworkHeaders{
+			"location":   gson.New("https://example.com/docs/?page=2"),
+			"set-cookie": gson.New("a=1\nb=2"),
+		},
+	}
+
+	want := "* Connected to example.com (93.184.216.34 port 443)\n" +
+		"> GET /docs?page=2 HTTP/2\n" +
+		"> Host: example.com\n" +
+		"> User-Agent: snag-test\n" +
+		">\n" +
+		"< HTTP/2 301 Moved Permanently\n" +
+		"< location: https://example.com/docs/?page=2\n" +
+		"< set-cookie: a=1\n" +
+		"< set-cookie: b=2\n" +
+		"<\n"
+	if got := traceExchange(request, response); got != want {
+		t.Errorf("traceExchange() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestTraceRequest_WireHeaders(t *testing.T) {
+	request := &proto.NetworkRequest{
+		URL:     "http://localhost:8080/",
+		Method:  "GET",
+		Headers: proto.NetworkHeaders{"Accept": gson.New("*/*")},
+	}
+	response := &proto.NetworkResponse{
+		Protocol: "http/1.1",
+		RequestHeaders: proto.NetworkHeaders{
+			"Host":   gson.New("localhost:8080"),
+			"Cookie": gson.New("session=abc"),
+		},
+	}
+
+	want := "> GET / HTTP/1.1\n" +
+		"> Cookie: session=abc\n" +
+		"> Host: localhost:8080\n" +
+		">\n"
+	if got := traceRequest(request, response); got != want {
+		t.Errorf("traceRequest() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestTraceProtocol(t *testing.T) {
+	tests := map[string]string{
+		"h2":       "HTTP/2",
+		"h3":       "HTTP/3",
+		"http/1.1": "HTTP/1.1",
+		"http/1.0": "HTTP/1.0",
+		"":         "HTTP/1.1",
+	}
+	for protocol, want := range tests {
+		if got := traceProtocol(protocol); got != want {
+			t.Errorf("traceProtocol(%q) = %q, want %q", protocol, got, want)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// DashboardRefresh is how often the --tui dashboard redraws.
+	DashboardRefresh = 500 * time.Millisecond
+
+	// DashboardLogLines is the number of recent log lines the dashboard
+	// shows below the progress panel.
+	DashboardLogLines = 8
+)
+
+// Dashboard renders batch progress in place on the terminal for --tui.
+// While it runs, log output is captured and shown as the most recent lines
+// under the progress panel instead of scrolling. A nil *Dashboard is valid
+// and does nothing, so callers need not check whether --tui is set.
+type Dashboard struct {
+	out      io.Writer
+	restore  io.Writer
+	width    int
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+
+	mu       sync.Mutex
+	total    int
+	ok       int
+	failed   int
+	skipped  int
+	start    time.Time
+	current  string
+	began    time.Time
+	recent   []string
+	partial  string
+	rendered int
+}
+
+// StartDashboard takes over stderr for a batch of total URLs, or an open
+// ended stream when total is 0. It returns nil, after a warning, when
+// stderr is not a terminal.
+func StartDashboard(total int) *Dashboard {
+	if !isTerminal(os.Stderr) {
+		logger.Warning("--tui ignored: stderr is not a terminal")
+		return nil
+	}
+	if logger.json {
+		logger.Warning("--tui ignored with --log-format json")
+		return nil
+	}
+
+	d := &Dashboard{
+		out:     os.Stderr,
+		restore: logger.writer,
+		width:   terminalWidth(),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+		total:   total,
+		start:   time.Now(),
+	}
+	logger.writer = d
+
+	go func() {
+		defer close(d.done)
+		ticker := time.NewTicker(DashboardRefresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				d.render()
+			case <-d.stop:
+				return
+			}
+		}
+	}()
+
+	d.render()
+	return d
+}
+
+// Begin marks url as the page the worker is fetching.
+func (d *Dashboard) Begin(url string) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.current = url
+	d.began = time.Now()
+	d.mu.Unlock()
+}
+
+// AddTotal adds n URLs found during the batch, such as --crawl links.
+func (d *Dashboard) AddTotal(n int) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.total += n
+	d.mu.Unlock()
+}
+
+// Done records the outcome of the current page: ok, failed, or skipped
+// when neither is set.
+func (d *Dashboard) Done(ok bool, failed bool) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	switch {
+	case ok:
+		d.ok++
+	case failed:
+		d.failed++
+	default:
+		d.skipped++
+	}
+	d.current = ""
+	d.mu.Unlock()
+	d.render()
+}
+
+// Stop draws the final state and hands stderr back to the logger. It is
+// safe to call more than once.
+func (d *Dashboard) Stop() {
+	if d == nil {
+		return
+	}
+	d.stopOnce.Do(func() {
+		close(d.stop)
+		<-d.done
+		d.render()
+		logger.writer = d.restore
+	})
+}
+
+// Write captures log output for the recent lines panel.
+func (d *Dashboard) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	text := d.partial + string(p)
+	lines := strings.Split(text, "\n")
+	d.partial = lines[len(lines)-1]
+	d.recent = append(d.recent, lines[:len(lines)-1]...)
+	if len(d.recent) > DashboardLogLines {
+		d.recent = d.recent[len(d.recent)-DashboardLogLines:]
+	}
+	return len(p), nil
+}
+
+// render redraws the dashboard over its previous frame.
+func (d *Dashboard) render() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	lines := d.view(time.Now())
+
+	var b strings.Builder
+	if d.rendered > 0 {
+		// Move to the first line of the last frame and clear below it
+		fmt.Fprintf(&b, "\033[%dF\033[J", d.rendered)
+	}
+	for _, line := range lines {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	fmt.Fprint(d.out, b.String())
+	d.rendered = len(lines)
+}
+
+// view returns the dashboard lines for the state at now. Callers hold d.mu.
+func (d *Dashboard) view(now time.Time) []string {
+	processed := d.ok + d.failed + d.skipped
+	elapsed := now.Sub(d.start)
+
+	progress := fmt.Sprintf("%d", processed)
+	if d.total > 0 {
+		progress = fmt.Sprintf("%d/%d %d%%", processed, d.total, processed*100/d.total)
+	}
+
+	rate := 0.0
+	if elapsed > 0 {
+		rate = float64(processed) / elapsed.Minutes()
+	}
+
+	eta := "-"
+	if d.total > 0 && processed > 0 && processed < d.total {
+		remaining := time.Duration(float64(elapsed) / float64(processed) * float64(d.total-processed))
+		eta = remaining.Round(time.Second).String()
+	} else if d.total > 0 && processed >= d.total {
+		eta = "done"
+	}
+
+	worker := "idle"
+	if d.current != "" {
+		worker = fmt.Sprintf("%s (%s)", d.current, now.Sub(d.began).Round(time.Second))
+	}
+
+	lines := []string{
+		fmt.Sprintf("snag [%s]  ok=%d fail=%d skipped=%d", progress, d.ok, d.failed, d.skipped),
+		fmt.Sprintf("%.1f pages/min  elapsed %s  eta %s", rate, elapsed.Round(time.Second), eta),
+		"worker 1: " + worker,
+		strings.Repeat("─", min(d.width, 40)),
+	}
+	lines = append(lines, d.recent...)
+
+	for i, line := range lines {
+		lines[i] = clipLine(line, d.width)
+	}
+	return lines
+}
+
+// clipLine shortens line to width runes so that it never wraps, which
+// would throw off the redraw. ANSI color sequences are not counted.
+func clipLine(line string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(line) <= width {
+		return line
+	}
+
+	var b strings.Builder
+	visible := 0
+	inEscape := false
+	for _, r := range line {
+		switch {
+		case r == '\033':
+			inEscape = true
+		case inEscape:
+			if r >= '@' && r <= '~' && r != '[' {
+				inEscape = false
+			}
+		default:
+			if visible == width-1 {
+				b.WriteString("…" + colorReset)
+				return b.String()
+			}
+			visible++
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// isTerminal reports whether f is attached to a terminal.
+func isTerminal(f *os.File) bool {
+	fileInfo, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return (fileInfo.Mode() & os.ModeCharDevice) != 0
+}
+
+// terminalWidth returns the width of the terminal from $COLUMNS, falling
+// back to MaxTabLineLength.
+func terminalWidth() int {
+	var width int
+	if _, err := fmt.Sscan(os.Getenv("COLUMNS"), &width); err == nil && width > 0 {
+		return width
+	}
+	return MaxTabLineLength
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDashboardView(t *testing.T) {
+	start := time.Date(2025, 2, 4, 10, 0, 0, 0, time.UTC)
+	d := &Dashboard{
+		width:   80,
+		total:   10,
+		ok:      3,
+		failed:  1,
+		start:   start,
+		current: "https://example.com/page",
+		began:   start.Add(2 * time.Minute),
+	}
+	fmt.Fprintln(d, "✓ Saved page.md")
+
+	lines := d.view(start.Add(2*time.Minute + 5*time.Second))
+
+	want := []string{
+		"snag [4/10 40%]  ok=3 fail=1 skipped=0",
+		"1.9 pages/min  elapsed 2m5s  eta 3m8s",
+		"worker 1: https://example.com/page (5s)",
+	}
+	for i, w := range want {
+		if lines[i] != w {
+			t.Errorf("line %d = %q, want %q", i, lines[i], w)
+		}
+	}
+	if last := lines[len(lines)-1]; last != "✓ Saved page.md" {
+		t.Errorf("last line = %q, want the recent log line", last)
+	}
+}
+
+func TestDashboardWrite_KeepsRecentLines(t *testing.T) {
+	d := &Dashboard{}
+	for i := range DashboardLogLines + 3 {
+		fmt.Fprintf(d, "line %d\n", i)
+	}
+	fmt.Fprint(d, "partial")
+
+	if len(d.recent) != DashboardLogLines {
+		t.Fatalf("kept %d lines, want %d", len(d.recent), DashboardLogLines)
+	}
+	if d.recent[0] != "line 3" || d.partial != "partial" {
+		t.Errorf("recent = %v, partial = %q", d.recent, d.partial)
+	}
+}
+
+func TestDashboard_Nil(t *testing.T) {
+	var d *Dashboard
+	d.Begin("https://example.com")
+	d.Done(true, false)
+	d.Stop()
+}
+
+func TestClipLine(t *testing.T) {
+	tests := []struct {
+		line  string
+		width int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"0123456789abc", 10, "012345678…" + colorReset},
+		{colorGreen + "✓" + colorReset + " 0123456789", 5, colorGreen + "✓" + colorReset + " 01…" + colorReset},
+		{"anything", 0, "anything"},
+	}
+
+	for _, tt := range tests {
+		if got := clipLine(tt.line, tt.width); got != tt.want {
+			t.Errorf("clipLine(%q, %d) = %q, want %q", tt.line, tt.width, got, tt.want)
+		}
+	}
+
+	if got := clipLine(strings.Repeat("é", 20), 5); got != "éééé…"+colorReset {
+		t.Errorf("clipLine multibyte = %q", got)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// allowJSEval is the policy for evaluating user-supplied JavaScript. The
+// CLI runs scripts its user wrote, so it is on; snag serve turns it off
+// unless started with --allow-js-eval, so clients of a hosted endpoint
+// cannot run arbitrary code in its browser.
+var allowJSEval = true
+
+// scriptDigest returns a short SHA-256 of script for audit logs, so the
+// exact script can be matched later without logging its contents.
+func scriptDigest(script string) string {
+	sum := sha256.Sum256([]byte(script))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// evalUserScript runs user-supplied JavaScript in page, awaiting a returned
+// promise. Every call is audit logged with its source and digest; the
+// script itself is only logged with --debug. It fails with
+// ErrJSEvalDisabled when the policy forbids evaluation.
+func evalUserScript(page *rod.Page, source, script string) (*proto.RuntimeRemoteObject, error) {
+	digest := scriptDigest(script)
+	if !allowJSEval {
+		logger.Warning("Refused JavaScript from %s (sha256:%s): evaluation is disabled", source, digest)
+		return nil, fmt.Errorf("%w: script from %s", ErrJSEvalDisabled, source)
+	}
+
+	logger.Info("Evaluating JavaScript from %s (%d bytes, sha256:%s)", source, len(script), digest)
+	logger.Debug("Script sha256:%s:\n%s", digest, script)
+
+	res, err := proto.RuntimeEvaluate{
+		Expression:    script,
+		AwaitPromise:  true,
+		ReturnByValue: true,
+		UserGesture:   true,
+	}.Call(page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate script from %s: %w", source, err)
+	}
+	if res.ExceptionDetails != nil {
+		msg := res.ExceptionDetails.Text
+		if res.ExceptionDetails.Exception != nil && res.ExceptionDetails.Exception.Description != "" {
+			msg = res.ExceptionDetails.Exception.Description
+		}
+		return nil, fmt.Errorf("script from %s threw: %s", source, msg)
+	}
+
+	return res.Result, nil
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestScriptDigest(t *testing.T) {
+	a := scriptDigest("document.title")
+	if len(a) != 12 {
+		t.Errorf("scriptDigest length = %d, want 12", len(a))
+	}
+	if a != scriptDigest("document.title") {
+		t.Error("scriptDigest is not stable")
+	}
+	if a == scriptDigest("document.title;") {
+		t.Error("scriptDigest collides for different scripts")
+	}
+}
+
+func TestEvalUserScript_Disabled(t *testing.T) {
+	defer func() { allowJSEval = true }()
+	allowJSEval = false
+
+	_, err := evalUserScript(nil, "test", "1 + 1")
+	if !errors.Is(err, ErrJSEvalDisabled) {
+		t.Errorf("expected ErrJSEvalDisabled, got %v", err)
+	}
+}
@@ -11,7 +11,13 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"text/template"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
 )
 
 func validateURL(urlStr string) (string, error) {
@@ -168,6 +174,12 @@ func normalizeFormat(format string) string {
 		return FormatMarkdown
 	case "txt":
 		return FormatText
+	case "orgmode":
+		return FormatOrg
+	case "restructuredtext":
+		return FormatRST
+	case "notebook":
+		return FormatNotebook
 	default:
 		return format
 	}
@@ -187,12 +199,17 @@ func validateFormat(format string) error {
 		FormatMarkdown: true,
 		FormatHTML:     true,
 		FormatText:     true,
+		FormatReader:   true,
+		FormatOrg:      true,
+		FormatRST:      true,
+		FormatNotebook: true,
 		FormatPDF:      true,
 		FormatPNG:      true,
+		FormatJSON:     true,
 	}
 
 	if !validFormats[format] {
-		logger.Error("Invalid format '%s'. Supported: md, html, text, pdf, png", format)
+		logger.Error("Invalid format '%s'. Supported: md, html, text, reader, org, rst, ipynb, pdf, png, json", format)
 		logger.ErrorWithSuggestion(
 			"Choose a valid format",
 			fmt.Sprintf("snag <url> --format %s", FormatMarkdown),
@@ -350,3 +367,663 @@ func validateUserDataDir(path string) (string, error) {
 
 	return path, nil
 }
+
+// throttleProfile describes emulated network conditions. Throughput is in
+// kilobits per second and latency is the added round-trip time in milliseconds.
+type throttleProfile struct {
+	downKbps  float64
+	upKbps    float64
+	latencyMs float64
+}
+
+// throttlePresets mirror the Chrome DevTools network throttling presets.
+var throttlePresets = map[string]throttleProfile{
+	"slow-3g": {downKbps: 400, upKbps: 400, latencyMs: 2000},
+	"3g":      {downKbps: 1600, upKbps: 750, latencyMs: 562.5},
+	"slow-4g": {downKbps: 4000, upKbps: 1500, latencyMs: 150},
+	"4g":      {downKbps: 9000, upKbps: 1500, latencyMs: 60},
+}
+
+// MaxViewportSize is the largest --viewport width or height in CSS pixels.
+const MaxViewportSize = 16384
+
+// parseViewport reads a --viewport value such as "1280x800". It returns
+// zeros for an empty value.
+func parseViewport(spec string) (int, int, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	if spec == "" {
+		return 0, 0, nil
+	}
+
+	w, h, found := strings.Cut(spec, "x")
+	if !found {
+		return 0, 0, fmt.Errorf("expected WIDTHxHEIGHT, got %s", spec)
+	}
+	width, errW := strconv.Atoi(strings.TrimSpace(w))
+	height, errH := strconv.Atoi(strings.TrimSpace(h))
+	if errW != nil || errH != nil {
+		return 0, 0, fmt.Errorf("expected WIDTHxHEIGHT, got %s", spec)
+	}
+	if width < 1 || height < 1 || width > MaxViewportSize || height > MaxViewportSize {
+		return 0, 0, fmt.Errorf("width and height must be between 1 and %d", MaxViewportSize)
+	}
+	return width, height, nil
+}
+
+func validateViewport(spec string) error {
+	if _, _, err := parseViewport(spec); err != nil {
+		logger.Error("Invalid --viewport: %v", err)
+		logger.ErrorWithSuggestion(
+			"Give the viewport size in CSS pixels",
+			"snag -f png --viewport 1280x800 <url>",
+		)
+		return fmt.Errorf("invalid viewport: %w", err)
+	}
+	return nil
+}
+
+// validatePDFLayout checks the --pdf-paper, --pdf-margins, and --pdf-scale
+// values.
+func validatePDFLayout(paper, margins string, scale float64) error {
+	if _, _, err := parsePDFPaper(paper); err != nil {
+		logger.Error("Invalid --pdf-paper: %s", paper)
+		logger.ErrorWithSuggestion(
+			"Use one of: a3, a4, a5, letter, legal, tabloid",
+			"snag -f pdf --pdf-paper a4 <url>",
+		)
+		return fmt.Errorf("invalid pdf-paper: %w", err)
+	}
+	if _, err := parsePDFMargins(margins); err != nil {
+		logger.Error("Invalid --pdf-margins: %v", err)
+		logger.ErrorWithSuggestion(
+			"Give 1 to 4 lengths (top right bottom left) with units mm, cm, in, pt, or px",
+			"snag -f pdf --pdf-margins \"20mm 15mm\" <url>",
+		)
+		return fmt.Errorf("invalid pdf-margins: %w", err)
+	}
+	if scale < MinPDFScale || scale > MaxPDFScale {
+		logger.Error("Invalid --pdf-scale: %g", scale)
+		logger.ErrorWithSuggestion(
+			fmt.Sprintf("Scale must be between %g and %g", MinPDFScale, MaxPDFScale),
+			"snag -f pdf --pdf-scale 0.8 <url>",
+		)
+		return fmt.Errorf("invalid pdf-scale: %g", scale)
+	}
+	return nil
+}
+
+// validateThrottle parses a --throttle value (a preset name or
+// custom:down,up,rtt) into CDP network conditions. Returns nil when empty.
+func validateThrottle(spec string) (*proto.NetworkEmulateNetworkConditions, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	if spec == "" {
+		return nil, nil
+	}
+
+	profile, ok := throttlePresets[spec]
+	if !ok {
+		custom, found := strings.CutPrefix(spec, "custom:")
+		if !found {
+			logger.Error("Invalid throttle profile: %s", spec)
+			logger.ErrorWithSuggestion(
+				"Use slow-3g, 3g, slow-4g, 4g, or custom:down,up,rtt (kbps, kbps, ms)",
+				"snag --throttle custom:1600,750,150 <url>",
+			)
+			return nil, fmt.Errorf("invalid throttle profile: %s", spec)
+		}
+
+		parts := strings.Split(custom, ",")
+		if len(parts) != 3 {
+			logger.Error("Invalid custom throttle: %s", spec)
+			logger.ErrorWithSuggestion(
+				"Custom throttle needs download kbps, upload kbps, and round-trip ms",
+				"snag --throttle custom:1600,750,150 <url>",
+			)
+			return nil, fmt.Errorf("invalid custom throttle: %s", spec)
+		}
+
+		values := make([]float64, len(parts))
+		for i, part := range parts {
+			v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil || v < 0 {
+				logger.Error("Invalid custom throttle value: %s", part)
+				logger.ErrorWithSuggestion(
+					"Throttle values must be non-negative numbers",
+					"snag --throttle custom:1600,750,150 <url>",
+				)
+				return nil, fmt.Errorf("invalid custom throttle value: %s", part)
+			}
+			values[i] = v
+		}
+
+		profile = throttleProfile{downKbps: values[0], upKbps: values[1], latencyMs: values[2]}
+	}
+
+	return &proto.NetworkEmulateNetworkConditions{
+		Latency:            profile.latencyMs,
+		DownloadThroughput: profile.downKbps * 1000 / 8,
+		UploadThroughput:   profile.upKbps * 1000 / 8,
+	}, nil
+}
+
+// validateRecord checks the --record output path and --duration value.
+func validateRecord(path string, duration time.Duration) error {
+	if err := validateOutputPath(path); err != nil {
+		return err
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".gif" && ext != ".webp" {
+		logger.Error("Unsupported recording format: %s", path)
+		logger.ErrorWithSuggestion(
+			"Recordings must be saved as .gif or .webp",
+			"snag --record demo.gif <url>",
+		)
+		return fmt.Errorf("unsupported recording format: %s", path)
+	}
+
+	if duration <= 0 || duration > MaxRecordDuration {
+		logger.Error("Invalid recording duration: %s", duration)
+		logger.ErrorWithSuggestion(
+			fmt.Sprintf("Duration must be greater than zero and at most %s", MaxRecordDuration),
+			"snag --record demo.gif --duration 10s <url>",
+		)
+		return fmt.Errorf("invalid recording duration: %s", duration)
+	}
+
+	return nil
+}
+
+// validateStateFile checks a --state file path. The file is created on first
+// use unless mustExist is set.
+func validateStateFile(path string, mustExist bool) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) && mustExist {
+		logger.Error("State file does not exist: %s", path)
+		logger.ErrorWithSuggestion(
+			"Create a state file by running a batch with --state",
+			"snag --url-file urls.txt --state crawl.db -d pages/",
+		)
+		return fmt.Errorf("state file does not exist: %s", path)
+	}
+
+	return validateOutputPath(path)
+}
+
+// validateOutputName checks an output filename given for a URL in a URL
+// file. Names are relative to the output directory and may include
+// subdirectories, but must not be absolute or escape the directory.
+func validateOutputName(name string) error {
+	if strings.HasSuffix(name, "/") || strings.HasSuffix(name, "\\") {
+		return fmt.Errorf("output name must be a file, not a directory: %s", name)
+	}
+
+	if !filepath.IsLocal(name) || strings.Contains(name, "\\") {
+		return fmt.Errorf("output name must be a relative path inside the output directory: %s", name)
+	}
+
+	return nil
+}
+
+func validateBaseURL(base string) error {
+	parsed, err := url.Parse(base)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		logger.Error("Invalid base URL: %s", base)
+		logger.ErrorWithSuggestion(
+			"Base URL must be an absolute http:// or https:// address",
+			"snag convert - --base-url https://example.com/docs/",
+		)
+		return ErrInvalidURL
+	}
+	return nil
+}
+
+func normalizeEOL(eol string) string {
+	return strings.ToLower(strings.TrimSpace(eol))
+}
+
+func validateEOL(eol string) error {
+	switch normalizeEOL(eol) {
+	case "", EOLLF, EOLCRLF:
+		return nil
+	}
+
+	logger.Error("Invalid --eol: %s", eol)
+	logger.ErrorWithSuggestion(
+		"Line endings must be lf or crlf",
+		"snag --eol crlf -o page.md <url>",
+	)
+	return fmt.Errorf("invalid eol: %s", eol)
+}
+
+// validateInfoFields parses a comma-separated --fields list, returning the
+// default fields when it is empty. Duplicates are dropped.
+func validateInfoFields(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return DefaultInfoFields, nil
+	}
+
+	var fields []string
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(value, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field == "" || seen[field] {
+			continue
+		}
+		if !slices.Contains(InfoFields, field) {
+			logger.Error("Unknown field: %s", field)
+			logger.ErrorWithSuggestion(
+				"Fields must be one of: "+strings.Join(InfoFields, ", "),
+				"snag --info --fields title,url,content example.com",
+			)
+			return nil, fmt.Errorf("unknown field: %s", field)
+		}
+		seen[field] = true
+		fields = append(fields, field)
+	}
+
+	if len(fields) == 0 {
+		logger.Error("No fields given to --fields")
+		return nil, fmt.Errorf("no fields given")
+	}
+
+	return fields, nil
+}
+
+func normalizeTokenOverflow(overflow string) string {
+	return strings.ToLower(strings.TrimSpace(overflow))
+}
+
+func validateTokenBudget(maxTokens int, overflow string) error {
+	if maxTokens < 0 {
+		logger.Error("Invalid --max-tokens: %d", maxTokens)
+		logger.ErrorWithSuggestion(
+			"Max tokens must be zero (unlimited) or a positive number",
+			"snag --max-tokens 8000 <url>",
+		)
+		return fmt.Errorf("invalid max tokens: %d", maxTokens)
+	}
+
+	switch normalizeTokenOverflow(overflow) {
+	case TokenOverflowTruncate, TokenOverflowSplit:
+		return nil
+	}
+
+	logger.Error("Invalid --token-overflow: %s", overflow)
+	logger.ErrorWithSuggestion(
+		"Token overflow must be truncate or split",
+		"snag --max-tokens 8000 --token-overflow split -d chunks/ <url>",
+	)
+	return fmt.Errorf("invalid token overflow: %s", overflow)
+}
+
+func validateFilenameLang(lang string) error {
+	lang = normalizeFilenameLang(lang)
+	if _, ok := slugLanguages[lang]; ok || lang == "" {
+		return nil
+	}
+
+	logger.Error("Invalid --filename-lang: %s", lang)
+	logger.ErrorWithSuggestion(
+		"Supported languages: "+strings.Join(slugLanguageNames(), ", "),
+		"snag --filename-lang de -d pages/ <url>",
+	)
+	return fmt.Errorf("invalid filename language: %s", lang)
+}
+
+func validateTitleFrom(value string) error {
+	if _, err := parseTitleSources(value); err != nil {
+		logger.Error("Invalid --title-from: %v", err)
+		logger.ErrorWithSuggestion(
+			"List title sources in order from: og, title, h1, path",
+			"snag --title-from h1,title -d pages/ <url>",
+		)
+		return fmt.Errorf("invalid title-from: %w", err)
+	}
+	return nil
+}
+
+func normalizeDuplicates(mode string) string {
+	return strings.ToLower(strings.TrimSpace(mode))
+}
+
+func validateDuplicates(mode string) error {
+	switch normalizeDuplicates(mode) {
+	case "", DuplicatesReport, DuplicatesSkip:
+		return nil
+	}
+
+	logger.Error("Invalid --duplicates: %s", mode)
+	logger.ErrorWithSuggestion(
+		"Duplicates mode must be report or skip",
+		"snag --url-file urls.txt -d pages/ --duplicates skip",
+	)
+	return fmt.Errorf("invalid duplicates mode: %s", mode)
+}
+
+func normalizeTextEngine(engine string) string {
+	engine = strings.ToLower(strings.TrimSpace(engine))
+	if engine == "" {
+		return TextEngineHTML2Text
+	}
+	return engine
+}
+
+func validateTextEngine(engine string) error {
+	switch normalizeTextEngine(engine) {
+	case TextEngineHTML2Text, TextEngineDOM, TextEngineReadability:
+		return nil
+	}
+
+	logger.Error("Invalid --text-engine: %s", engine)
+	logger.ErrorWithSuggestion(
+		"Text engine must be html2text, dom, or readability",
+		"snag -f text --text-engine dom <url>",
+	)
+	return fmt.Errorf("invalid text engine: %s", engine)
+}
+
+func normalizeEngine(engine string) string {
+	engine = strings.ToLower(strings.TrimSpace(engine))
+	if engine == "" {
+		return EngineChromium
+	}
+	return engine
+}
+
+func validateEngine(engine string) error {
+	switch normalizeEngine(engine) {
+	case EngineChromium, EngineWebKit, EngineHTTP:
+		return nil
+	}
+
+	logger.Error("Invalid --engine: %s", engine)
+	logger.ErrorWithSuggestion(
+		"Engine must be chromium, webkit (Safari, macOS only), or http (no browser)",
+		"snag --engine webkit <url>",
+	)
+	return fmt.Errorf("invalid engine: %s", engine)
+}
+
+func normalizeLogFormat(logFormat string) string {
+	logFormat = strings.ToLower(strings.TrimSpace(logFormat))
+	if logFormat == "" {
+		return LogFormatText
+	}
+	return logFormat
+}
+
+func validateLogFormat(logFormat string) error {
+	switch normalizeLogFormat(logFormat) {
+	case LogFormatText, LogFormatJSON:
+		return nil
+	}
+
+	logger.Error("Invalid --log-format: %s", logFormat)
+	logger.ErrorWithSuggestion(
+		"Log format must be text or json",
+		"snag --log-format json <url> 2> log.jsonl",
+	)
+	return fmt.Errorf("invalid log format: %s", logFormat)
+}
+
+func normalizeCiteStyle(style string) string {
+	return strings.ToLower(strings.TrimSpace(style))
+}
+
+func validateCiteStyle(style string) error {
+	switch normalizeCiteStyle(style) {
+	case CiteAPA, CiteMLA, CiteChicago, CiteBibTeX:
+		return nil
+	}
+
+	logger.Error("Invalid --cite style: %s", style)
+	logger.ErrorWithSuggestion(
+		"Citation style must be apa, mla, chicago, or bibtex",
+		"snag --cite apa -o notes.md <url>",
+	)
+	return fmt.Errorf("invalid citation style: %s", style)
+}
+
+func validateRedact(patterns []string, file string) error {
+	compiled, err := loadRedactPatterns(patterns, file)
+	if err != nil {
+		logger.Error("Invalid --redact pattern: %v", err)
+		logger.ErrorWithSuggestion(
+			"Patterns use Go regular expression syntax",
+			`snag --redact '[\w.+-]+@[\w-]+\.[\w.]+' <url>`,
+		)
+		return fmt.Errorf("invalid redact pattern: %w", err)
+	}
+	redactPatterns = compiled
+	return nil
+}
+
+// requestHeaders are the parsed --header values sent with page requests.
+var requestHeaders map[string]string
+
+// parseHeader splits a "Name: Value" header. Names must be HTTP tokens.
+func parseHeader(header string) (string, string, error) {
+	name, value, ok := strings.Cut(header, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return "", "", fmt.Errorf("expected \"Name: Value\": %s", header)
+	}
+	for _, r := range name {
+		if r <= ' ' || r >= 0x7f || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, r) {
+			return "", "", fmt.Errorf("invalid header name: %s", name)
+		}
+	}
+	if strings.ContainsAny(value, "\r\n") {
+		return "", "", fmt.Errorf("header value contains a line break: %s", name)
+	}
+	return name, strings.TrimSpace(value), nil
+}
+
+func validateHeaders(values []string) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	parsed := make(map[string]string, len(values))
+	for _, value := range values {
+		name, val, err := parseHeader(value)
+		if err != nil {
+			logger.Error("Invalid --header: %v", err)
+			logger.ErrorWithSuggestion(
+				"Headers take the form \"Name: Value\"",
+				`snag --header "Authorization: Bearer $TOKEN" <url>`,
+			)
+			return fmt.Errorf("invalid header: %w", err)
+		}
+		parsed[name] = val
+	}
+	requestHeaders = parsed
+	return nil
+}
+
+func validateCookies(load, save string) error {
+	if load != "" {
+		cookies, err := loadCookies(load)
+		if err != nil {
+			logger.Error("Failed to read --cookies-file %s: %v", load, err)
+			logger.ErrorWithSuggestion(
+				"Cookie files are JSON (a list of name, value, domain, path) or Netscape cookies.txt",
+				"snag --tab app.example.com --save-cookies cookies.json",
+			)
+			return fmt.Errorf("invalid cookies file: %w", err)
+		}
+		importedCookies = cookies
+	}
+
+	if save != "" {
+		if err := validateOutputPath(save); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readOnlyWriteFlags are the flags that make snag, or the browser it
+// drives, write somewhere other than the output file or directory.
+var readOnlyWriteFlags = []string{
+	"state", "save-cookies", "summary-file", "links-report", "dump-headers", "assets-dir",
+	"emit-curl", "capture-responses", "record", "user-data-dir", "open-browser",
+}
+
+// validateReadOnly refuses flags that would write outside the output
+// target with --read-only. changed reports whether a flag was set.
+func validateReadOnly(changed func(name string) bool) error {
+	for _, name := range readOnlyWriteFlags {
+		if !changed(name) {
+			continue
+		}
+		logger.Error("Cannot use --%s with --read-only (it writes outside the output target)", name)
+		logger.ErrorWithSuggestion(
+			"--read-only only writes the output file or directory",
+			"snag --read-only --force-headless -o page.md https://example.com",
+		)
+		return fmt.Errorf("conflicting flags: --%s and --read-only", name)
+	}
+	return nil
+}
+
+func validateTemplate(path string) error {
+	tmpl, err := loadOutputTemplate(path)
+	if err != nil {
+		logger.Error("Invalid --template: %v", err)
+		logger.ErrorWithSuggestion(
+			"Templates use Go text/template syntax over .Title, .URL, .Markdown, .Links, and .Metadata",
+			"snag --template digest.tmpl -o digest.org <url>",
+		)
+		return fmt.Errorf("invalid template: %w", err)
+	}
+	outputTemplate = tmpl
+	return nil
+}
+
+// validateFilenameTemplate parses a --filename-template and tries it on an
+// example page, so mistakes such as unknown fields fail before fetching.
+func validateFilenameTemplate(text string) error {
+	tmpl, err := template.New("filename").Option("missingkey=error").Parse(text)
+	if err == nil {
+		example := FilenameData{Title: "Example Page", Slug: "example-page", Host: "example.com", Path: "docs", Index: 1,
+			Date: "2025-10-22", Time: "142033", Timestamp: "2025-10-22-142033", Format: FormatMarkdown, Ext: "md"}
+		_, err = renderFilename(tmpl, example)
+	}
+	if err != nil {
+		logger.Error("Invalid --filename-template: %v", err)
+		logger.ErrorWithSuggestion(
+			"Use fields .Title, .Slug, .Host, .Path, .Index, .Date, .Time, .Timestamp, .Format, and .Ext",
+			`snag --filename-template "{{.Host}}/{{.Date}}-{{.Slug}}.{{.Ext}}" -d archive/ <url>`,
+		)
+		return fmt.Errorf("invalid filename template: %w", err)
+	}
+	filenameTemplate = tmpl
+	return nil
+}
+
+func validateExpectStatus(spec string) error {
+	if strings.TrimSpace(spec) == "" {
+		return nil
+	}
+
+	if _, err := parseStatusMatcher(spec); err != nil {
+		logger.Error("Invalid --expect-status: %v", err)
+		logger.ErrorWithSuggestion(
+			"Use statuses, classes, or ranges separated by commas",
+			"snag --expect-status 2xx,304 <url>",
+		)
+		return fmt.Errorf("invalid expect-status: %w", err)
+	}
+	return nil
+}
+
+func validateHostPatterns(allow, block []string) error {
+	if _, err := NewHostPolicy(allow, block, false); err != nil {
+		logger.Error("Invalid --allow-host or --block-host: %v", err)
+		logger.ErrorWithSuggestion(
+			"Use a host, a *.domain wildcard, an IP address, or a CIDR range",
+			"snag --block-host 169.254.0.0/16 --allow-host '*.example.com' <url>",
+		)
+		return err
+	}
+	return nil
+}
+
+func validateBlock(categories, urlPatterns []string) error {
+	if _, err := NewResourceBlocker(categories, urlPatterns); err != nil {
+		logger.Error("Invalid --block or --block-url-pattern: %v", err)
+		logger.ErrorWithSuggestion(
+			"Block images, fonts, media, or analytics, or give a URL pattern using * as a wildcard",
+			"snag --block images,fonts --block-url-pattern '*/ads/*' <url>",
+		)
+		return err
+	}
+	return nil
+}
+
+func validateResourceBudget(maxBytes string, maxRequests int) error {
+	if strings.TrimSpace(maxBytes) != "" {
+		if _, err := parseByteSize(maxBytes); err != nil {
+			logger.Error("Invalid --max-bytes: %s", maxBytes)
+			logger.ErrorWithSuggestion(
+				"Use a positive size with an optional unit: B, KB, MB, or GB",
+				"snag --max-bytes 50MB <url>",
+			)
+			return fmt.Errorf("invalid max-bytes: %w", err)
+		}
+	}
+
+	if maxRequests < 0 {
+		logger.Error("Invalid --max-requests: %d", maxRequests)
+		logger.ErrorWithSuggestion(
+			"Use a positive number of requests, or 0 for unlimited",
+			"snag --max-requests 500 <url>",
+		)
+		return fmt.Errorf("invalid max-requests: %d", maxRequests)
+	}
+
+	return nil
+}
+
+func validateMemoryLimit(limit string) error {
+	if strings.TrimSpace(limit) == "" {
+		return nil
+	}
+
+	if _, err := parseByteSize(limit); err != nil {
+		logger.Error("Invalid --browser-memory-limit: %s", limit)
+		logger.ErrorWithSuggestion(
+			"Use a positive size with an optional unit: B, KB, MB, or GB",
+			"snag --browser-memory-limit 2GB --url-file urls.txt -d out/",
+		)
+		return fmt.Errorf("invalid browser-memory-limit: %w", err)
+	}
+	return nil
+}
+
+// validatePhaseTimeouts checks --nav-timeout, --stabilize-timeout, and
+// --wait-timeout. Zero means the phase falls back to its default.
+func validatePhaseTimeouts() error {
+	phases := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"nav-timeout", navTimeout},
+		{"stabilize-timeout", stabilizeTime},
+		{"wait-timeout", waitTimeout},
+		{"title-wait", titleWait},
+	}
+
+	for _, phase := range phases {
+		if phase.value < 0 {
+			logger.Error("Invalid --%s: %s", phase.name, phase.value)
+			logger.ErrorWithSuggestion(
+				"Timeouts must be positive durations",
+				"snag --"+phase.name+" 30s <url>",
+			)
+			return fmt.Errorf("invalid %s: %s", phase.name, phase.value)
+		}
+	}
+	return nil
+}
@@ -9,8 +9,11 @@ package main
 import (
 	"io"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 )
 
 func init() {
@@ -85,6 +88,7 @@ func TestValidateFormat_Valid(t *testing.T) {
 		FormatText,     // "text"
 		FormatPDF,      // "pdf"
 		FormatPNG,      // "png"
+		FormatJSON,     // "json"
 	}
 
 	for _, format := range validFormats {
@@ -99,7 +103,6 @@ func TestValidateFormat_Invalid(t *testing.T) {
 	// Test with truly invalid formats (not supported by snag)
 	// Note: validateFormat expects already-normalized input
 	invalidFormats := []string{
-		"json",
 		"xml",
 		"yaml",
 		"txt", // Should be normalized to "text" before validation
@@ -615,3 +618,203 @@ func TestValidateWaitFor_Injection(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateThrottle(t *testing.T) {
+	conditions, err := validateThrottle("")
+	if err != nil || conditions != nil {
+		t.Errorf("expected nil conditions for empty throttle, got %v, %v", conditions, err)
+	}
+
+	conditions, err = validateThrottle(" Slow-4G ")
+	if err != nil {
+		t.Fatalf("expected preset to be valid, got error: %v", err)
+	}
+	if conditions.Latency != 150 || conditions.DownloadThroughput != 500000 {
+		t.Errorf("unexpected slow-4g conditions: %+v", conditions)
+	}
+
+	conditions, err = validateThrottle("custom:800,400,300")
+	if err != nil {
+		t.Fatalf("expected custom throttle to be valid, got error: %v", err)
+	}
+	if conditions.DownloadThroughput != 100000 || conditions.UploadThroughput != 50000 || conditions.Latency != 300 {
+		t.Errorf("unexpected custom conditions: %+v", conditions)
+	}
+
+	invalid := []string{"5g", "custom:", "custom:1,2", "custom:a,b,c", "custom:-1,2,3"}
+	for _, spec := range invalid {
+		if _, err := validateThrottle(spec); err == nil {
+			t.Errorf("expected throttle %q to be invalid", spec)
+		}
+	}
+}
+
+func TestValidateRecord(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := []string{"demo.gif", "demo.webp", "DEMO.GIF"}
+	for _, name := range valid {
+		if err := validateRecord(filepath.Join(dir, name), 10*time.Second); err != nil {
+			t.Errorf("expected %s to be valid, got error: %v", name, err)
+		}
+	}
+
+	if err := validateRecord(filepath.Join(dir, "demo.mp4"), 10*time.Second); err == nil {
+		t.Error("expected .mp4 recording to be invalid")
+	}
+
+	invalidDurations := []time.Duration{0, -time.Second, MaxRecordDuration + time.Second}
+	for _, d := range invalidDurations {
+		if err := validateRecord(filepath.Join(dir, "demo.gif"), d); err == nil {
+			t.Errorf("expected duration %s to be invalid", d)
+		}
+	}
+}
+
+func TestValidateOutputName(t *testing.T) {
+	valid := []string{"intro.md", "guide/setup.md", "./page.html"}
+	for _, name := range valid {
+		if err := validateOutputName(name); err != nil {
+			t.Errorf("expected %q to be valid, got: %v", name, err)
+		}
+	}
+
+	invalid := []string{"../escape.md", "guide/../../escape.md", "/etc/passwd", "docs/", "..", `..\escape.md`}
+	for _, name := range invalid {
+		if err := validateOutputName(name); err == nil {
+			t.Errorf("expected %q to be rejected", name)
+		}
+	}
+}
+
+func TestValidatePhaseTimeouts(t *testing.T) {
+	defer func() { navTimeout, stabilizeTime, waitTimeout = 0, 0, 0 }()
+
+	navTimeout, stabilizeTime, waitTimeout = 45*time.Second, 500*time.Millisecond, 0
+	if err := validatePhaseTimeouts(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	stabilizeTime = -time.Second
+	if err := validatePhaseTimeouts(); err == nil {
+		t.Error("expected error for negative --stabilize-timeout")
+	}
+}
+
+func TestValidateTextEngine(t *testing.T) {
+	for _, engine := range []string{"", "html2text", "DOM", " readability "} {
+		if err := validateTextEngine(engine); err != nil {
+			t.Errorf("expected %q to be valid, got: %v", engine, err)
+		}
+	}
+
+	if err := validateTextEngine("lynx"); err == nil {
+		t.Error("expected error for unknown engine")
+	}
+
+	if got := normalizeTextEngine(""); got != TextEngineHTML2Text {
+		t.Errorf("normalizeTextEngine(\"\") = %s, want %s", got, TextEngineHTML2Text)
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	tests := []struct {
+		header string
+		name   string
+		value  string
+		valid  bool
+	}{
+		{"Authorization: Bearer abc123", "Authorization", "Bearer abc123", true},
+		{"X-Api-Key:key", "X-Api-Key", "key", true},
+		{"X-Empty:", "X-Empty", "", true},
+		{"Cookie: a=1; b=2", "Cookie", "a=1; b=2", true},
+		{"NoColon", "", "", false},
+		{": value", "", "", false},
+		{"Bad Name: value", "", "", false},
+		{"X-Inject: a\r\nHost: evil", "", "", false},
+	}
+
+	for _, tt := range tests {
+		name, value, err := parseHeader(tt.header)
+		if (err == nil) != tt.valid {
+			t.Errorf("parseHeader(%q) error = %v, valid = %v", tt.header, err, tt.valid)
+			continue
+		}
+		if tt.valid && (name != tt.name || value != tt.value) {
+			t.Errorf("parseHeader(%q) = %q, %q, want %q, %q", tt.header, name, value, tt.name, tt.value)
+		}
+	}
+}
+
+func TestValidateHeaders(t *testing.T) {
+	defer func() { requestHeaders = nil }()
+
+	if err := validateHeaders([]string{"Authorization: Bearer abc", "X-Team: docs"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(requestHeaders) != 2 || requestHeaders["X-Team"] != "docs" {
+		t.Errorf("requestHeaders = %v", requestHeaders)
+	}
+
+	if err := validateHeaders([]string{"invalid"}); err == nil {
+		t.Error("expected error for header without a colon")
+	}
+}
+
+func TestParseViewport(t *testing.T) {
+	tests := []struct {
+		spec          string
+		width, height int
+		wantErr       bool
+	}{
+		{"", 0, 0, false},
+		{"1280x800", 1280, 800, false},
+		{" 390X844 ", 390, 844, false},
+		{"1280", 0, 0, true},
+		{"1280x", 0, 0, true},
+		{"0x800", 0, 0, true},
+		{"20000x800", 0, 0, true},
+		{"wide x tall", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		width, height, err := parseViewport(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseViewport(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if width != tt.width || height != tt.height {
+			t.Errorf("parseViewport(%q) = %dx%d, expected %dx%d", tt.spec, width, height, tt.width, tt.height)
+		}
+	}
+}
+
+func TestValidateReadOnly(t *testing.T) {
+	changed := func(set ...string) func(string) bool {
+		return func(name string) bool { return slices.Contains(set, name) }
+	}
+
+	if err := validateReadOnly(changed("output", "format", "force-headless")); err != nil {
+		t.Errorf("unexpected error for output flags: %v", err)
+	}
+	for _, name := range []string{"state", "links-report", "capture-responses", "user-data-dir", "open-browser"} {
+		if err := validateReadOnly(changed("output", name)); err == nil {
+			t.Errorf("expected error for --%s", name)
+		}
+	}
+}
+
+func TestValidateFilenameTemplate(t *testing.T) {
+	defer func() { filenameTemplate = nil }()
+
+	for _, text := range []string{"{{.Domain}}.md", "{{.Slug", "../{{.Slug}}.{{.Ext}}", ""} {
+		if err := validateFilenameTemplate(text); err == nil {
+			t.Errorf("validateFilenameTemplate(%q) expected an error", text)
+		}
+	}
+
+	assertNoError(t, validateFilenameTemplate("{{.Host}}/{{.Date}}-{{.Slug}}.{{.Ext}}"))
+	if filenameTemplate == nil {
+		t.Error("expected the template to be set")
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+const (
+	// DefaultWatchInterval is how often --watch checks the tab.
+	DefaultWatchInterval = 30 * time.Second
+
+	// MinWatchInterval is the shortest --interval accepted.
+	MinWatchInterval = time.Second
+
+	// WatchSettleDelay is how long --watch waits after the tab navigates
+	// before reading it, so pages that render after loading have done so.
+	WatchSettleDelay = time.Second
+
+	// WatchMaxFailures is how many checks in a row may fail before --watch
+	// gives up, as when the tab has been closed.
+	WatchMaxFailures = 5
+)
+
+// watchTab checks page every interval, and shortly after it navigates, and
+// saves a new timestamped file in outDir whenever its content has changed
+// since the last save. The first check always saves. It runs until snag is
+// interrupted or WatchMaxFailures checks in a row fail.
+func watchTab(page *rod.Page, outputFormat, outDir string, interval time.Duration) error {
+	navigated, stop := watchNavigation(page)
+	defer stop()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	logger.Info("Watching tab every %s (Ctrl+C to stop)", interval)
+
+	var lastHash string
+	failures := 0
+	for {
+		hash, err := saveIfChanged(page, outputFormat, outDir, lastHash)
+		if err != nil {
+			failures++
+			if failures >= WatchMaxFailures {
+				logger.Error("Stopped watching after %d failed checks: %v", failures, err)
+				return err
+			}
+			logger.Warning("Check failed: %v", err)
+		} else {
+			failures = 0
+			lastHash = hash
+		}
+
+		select {
+		case <-ticker.C:
+		case <-navigated:
+			logger.Verbose("Tab navigated, checking in %s...", WatchSettleDelay)
+			time.Sleep(WatchSettleDelay)
+			drain(navigated)
+		}
+	}
+}
+
+// saveIfChanged hashes the content of page and saves it when the hash
+// differs from lastHash, returning the new hash. With --select only the
+// selected elements are compared, so a clock or ticker elsewhere on a
+// dashboard does not count as a change.
+func saveIfChanged(page *rod.Page, outputFormat, outDir, lastHash string) (string, error) {
+	info, err := page.Info()
+	if err != nil {
+		return "", fmt.Errorf("failed to get page info: %w", err)
+	}
+
+	var html string
+	switch {
+	case selectCSS != "":
+		html, err = extractSelectedHTML(page, selectCSS)
+	case noActivate:
+		html, err = extractHTMLWithoutActivation(page)
+	default:
+		html, err = page.HTML()
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to extract HTML: %w", err)
+	}
+
+	hash := hashContent(html)
+	if hash == lastHash {
+		logger.Verbose("No change in %s", info.URL)
+		return hash, nil
+	}
+
+	outputPath, err := generateOutputFilename(
+		filenameTitle(page, info.Title, info.URL), info.URL, outputFormat,
+		time.Now(), outDir,
+	)
+	if err != nil {
+		return "", err
+	}
+	if lastHash != "" {
+		logger.Info("Content changed: %s", info.URL)
+	}
+	if err := processPageContent(page, outputFormat, outputPath); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// watchNavigation signals on the returned channel when page finishes
+// loading a new document or changes its URL within the document, as
+// single-page apps do. Call the returned function to stop listening.
+func watchNavigation(page *rod.Page) (<-chan struct{}, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	navigated := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case navigated <- struct{}{}:
+		default:
+		}
+	}
+
+	wait := page.Context(ctx).EachEvent(
+		func(e *proto.PageLoadEventFired) {
+			notify()
+		},
+		func(e *proto.PageNavigatedWithinDocument) {
+			if e.FrameID == page.FrameID {
+				notify()
+			}
+		},
+	)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		wait()
+	}()
+
+	return navigated, func() {
+		cancel()
+		<-done
+	}
+}
+
+// drain empties a signal channel.
+func drain(ch <-chan struct{}) {
+	for {
+		select {
+		case <-ch:
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,322 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+const (
+	// SafariDriverStartTimeout bounds how long safaridriver may take to
+	// accept connections.
+	SafariDriverStartTimeout = 10 * time.Second
+
+	// WebDriverPollInterval is how often --wait-for is checked in Safari.
+	WebDriverPollInterval = 250 * time.Millisecond
+)
+
+// webkitEngine fetches pages in Safari through safaridriver. Safari renders
+// the page and snag converts the resulting DOM, so formats that need the
+// Chromium page itself (PDF, and full-page PNG) are not available.
+type webkitEngine struct {
+	wd *WebDriver
+}
+
+func (e *webkitEngine) Start() error {
+	if runtime.GOOS != "darwin" {
+		logger.Error("--engine webkit needs Safari, which is only available on macOS")
+		logger.ErrorWithSuggestion(
+			"Fetch with the default Chromium engine instead",
+			"snag --engine chromium <url>",
+		)
+		return fmt.Errorf("--engine webkit is not supported on %s", runtime.GOOS)
+	}
+
+	logger.Verbose("Starting safaridriver...")
+	wd, err := startSafariDriver()
+	if err != nil {
+		logger.Error("Cannot start Safari: %v", err)
+		logger.ErrorWithSuggestion(
+			"Allow remote automation for Safari once, then try again",
+			"safaridriver --enable",
+		)
+		return err
+	}
+
+	umbrella := time.Duration(timeout) * time.Second
+	if err := wd.NewSession(phaseTimeout(navTimeout, umbrella)); err != nil {
+		wd.Close()
+		logger.Error("Cannot open a Safari session: %v", err)
+		logger.ErrorWithSuggestion(
+			"Turn on Develop > Allow Remote Automation in Safari, and close other automated Safari windows",
+			"safaridriver --enable",
+		)
+		return err
+	}
+	e.wd = wd
+	logger.Success("Connected to Safari")
+	return nil
+}
+
+func (e *webkitEngine) Load(url string) (*LoadedPage, error) {
+	if err := e.wd.Navigate(url); err != nil {
+		return nil, err
+	}
+
+	if selector := validateWaitFor(waitFor, waitFor != ""); selector != "" {
+		umbrella := time.Duration(timeout) * time.Second
+		if err := e.wd.WaitFor(selector, phaseTimeout(waitTimeout, umbrella)); err != nil {
+			return nil, err
+		}
+	}
+
+	page := &LoadedPage{URL: url, FinalURL: url}
+	var err error
+	if page.Title, err = e.wd.Title(); err != nil {
+		return nil, err
+	}
+	if finalURL, err := e.wd.CurrentURL(); err == nil {
+		page.FinalURL = finalURL
+	}
+	if page.HTML, err = e.wd.Source(); err != nil {
+		return nil, fmt.Errorf("failed to extract HTML: %w", err)
+	}
+	return page, nil
+}
+
+func (e *webkitEngine) Screenshot() ([]byte, error) {
+	return e.wd.Screenshot()
+}
+
+func (e *webkitEngine) Close() {
+	if e.wd != nil {
+		e.wd.Close()
+	}
+}
+
+// webDriverError is an error response from a WebDriver server.
+type webDriverError struct {
+	Code    string `json:"error"`
+	Message string `json:"message"`
+}
+
+func (e *webDriverError) Error() string {
+	if e.Message == "" {
+		return e.Code
+	}
+	return e.Code + ": " + e.Message
+}
+
+// WebDriver is a W3C WebDriver session, used to drive Safari through
+// safaridriver for --engine webkit.
+type WebDriver struct {
+	base    string
+	session string
+	client  *http.Client
+	cmd     *exec.Cmd
+}
+
+// startSafariDriver runs safaridriver on a free local port and waits until
+// it accepts commands. safaridriver needs "Allow Remote Automation" turned
+// on in Safari's Develop menu, or a one-time "safaridriver --enable".
+func startSafariDriver() (*WebDriver, error) {
+	path, err := exec.LookPath("safaridriver")
+	if err != nil {
+		return nil, fmt.Errorf("%w: safaridriver not found", ErrBrowserNotFound)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a free port: %w", err)
+	}
+	driverPort := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	cmd := exec.Command(path, "--port", fmt.Sprint(driverPort))
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start safaridriver: %w", err)
+	}
+
+	wd := &WebDriver{
+		base:   fmt.Sprintf("http://127.0.0.1:%d", driverPort),
+		client: &http.Client{},
+		cmd:    cmd,
+	}
+	deadline := time.Now().Add(SafariDriverStartTimeout)
+	for {
+		var status struct {
+			Ready bool `json:"ready"`
+		}
+		if err := wd.command(http.MethodGet, "/status", nil, &status); err == nil && status.Ready {
+			return wd, nil
+		}
+		if time.Now().After(deadline) {
+			wd.Close()
+			return nil, fmt.Errorf("%w: safaridriver did not start within %s", ErrBrowserConnection, SafariDriverStartTimeout)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// NewSession opens a Safari window with pageLoad as its navigation
+// timeout.
+func (wd *WebDriver) NewSession(pageLoad time.Duration) error {
+	var created struct {
+		SessionID string `json:"sessionId"`
+	}
+	body := map[string]any{
+		"capabilities": map[string]any{
+			"alwaysMatch": map[string]any{
+				"browserName": "safari",
+				"timeouts":    map[string]int64{"pageLoad": pageLoad.Milliseconds()},
+			},
+		},
+	}
+	if err := wd.command(http.MethodPost, "/session", body, &created); err != nil {
+		return fmt.Errorf("%w: %w", ErrBrowserConnection, err)
+	}
+	wd.session = created.SessionID
+	return nil
+}
+
+// Navigate loads url and waits for it to finish loading.
+func (wd *WebDriver) Navigate(url string) error {
+	err := wd.sessionCommand(http.MethodPost, "/url", map[string]string{"url": url}, nil)
+	var wdErr *webDriverError
+	if errors.As(err, &wdErr) && wdErr.Code == "timeout" {
+		return fmt.Errorf("%w: %w", ErrPageLoadTimeout, err)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrNavigationFailed, err)
+	}
+	return nil
+}
+
+// WaitFor polls until an element matches selector or timeout passes.
+func (wd *WebDriver) WaitFor(selector string, timeout time.Duration) error {
+	body := map[string]string{"using": "css selector", "value": selector}
+	deadline := time.Now().Add(timeout)
+	for {
+		err := wd.sessionCommand(http.MethodPost, "/element", body, nil)
+		var wdErr *webDriverError
+		if err == nil {
+			return nil
+		}
+		if !errors.As(err, &wdErr) || wdErr.Code != "no such element" {
+			return err
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w: %s not found within %s", ErrPageLoadTimeout, selector, timeout)
+		}
+		time.Sleep(WebDriverPollInterval)
+	}
+}
+
+// Source returns the current DOM serialized as HTML.
+func (wd *WebDriver) Source() (string, error) {
+	var source string
+	err := wd.sessionCommand(http.MethodGet, "/source", nil, &source)
+	return source, err
+}
+
+// Title returns the document title.
+func (wd *WebDriver) Title() (string, error) {
+	var title string
+	err := wd.sessionCommand(http.MethodGet, "/title", nil, &title)
+	return title, err
+}
+
+// CurrentURL returns the URL of the page after any redirects.
+func (wd *WebDriver) CurrentURL() (string, error) {
+	var url string
+	err := wd.sessionCommand(http.MethodGet, "/url", nil, &url)
+	return url, err
+}
+
+// Screenshot returns a PNG of the window's viewport.
+func (wd *WebDriver) Screenshot() ([]byte, error) {
+	var encoded string
+	if err := wd.sessionCommand(http.MethodGet, "/screenshot", nil, &encoded); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(encoded)
+}
+
+// Close ends the session and stops safaridriver.
+func (wd *WebDriver) Close() {
+	if wd.session != "" {
+		if err := wd.sessionCommand(http.MethodDelete, "", nil, nil); err != nil {
+			logger.Debug("Failed to end Safari session: %v", err)
+		}
+		wd.session = ""
+	}
+	if wd.cmd != nil && wd.cmd.Process != nil {
+		wd.cmd.Process.Kill()
+		wd.cmd.Wait()
+	}
+}
+
+func (wd *WebDriver) sessionCommand(method, path string, body, result any) error {
+	if wd.session == "" {
+		return fmt.Errorf("no WebDriver session")
+	}
+	return wd.command(method, "/session/"+wd.session+path, body, result)
+}
+
+// command sends a WebDriver command and decodes the "value" of the
+// response into result, if given.
+func (wd *WebDriver) command(method, path string, body, result any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, wd.base+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := wd.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("invalid WebDriver response to %s %s: %w", method, path, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		wdErr := &webDriverError{}
+		if err := json.Unmarshal(envelope.Value, wdErr); err != nil || wdErr.Code == "" {
+			return fmt.Errorf("WebDriver %s %s: HTTP %d", method, path, resp.StatusCode)
+		}
+		return wdErr
+	}
+	if result != nil {
+		return json.Unmarshal(envelope.Value, result)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeWebDriver answers WebDriver commands for session s1 from routes,
+// keyed by "METHOD /path" relative to the session.
+func fakeWebDriver(t *testing.T, routes map[string]func(body map[string]any) (int, any)) *WebDriver {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if data, _ := io.ReadAll(r.Body); len(data) > 0 {
+			json.Unmarshal(data, &body)
+		}
+		key := r.Method + " " + r.URL.Path[len("/session/s1"):]
+		route, ok := routes[key]
+		if !ok {
+			t.Errorf("unexpected WebDriver command: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]any{"value": map[string]string{"error": "unknown command"}})
+			return
+		}
+		status, value := route(body)
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]any{"value": value})
+	}))
+	t.Cleanup(server.Close)
+	return &WebDriver{base: server.URL, session: "s1", client: server.Client()}
+}
+
+func TestWebDriver_Commands(t *testing.T) {
+	var navigated string
+	wd := fakeWebDriver(t, map[string]func(map[string]any) (int, any){
+		"POST /url": func(body map[string]any) (int, any) {
+			navigated, _ = body["url"].(string)
+			return http.StatusOK, nil
+		},
+		"GET /title":      func(map[string]any) (int, any) { return http.StatusOK, "Example Domain" },
+		"GET /url":        func(map[string]any) (int, any) { return http.StatusOK, "https://example.com/" },
+		"GET /source":     func(map[string]any) (int, any) { return http.StatusOK, "<html><body><h1>Hi</h1></body></html>" },
+		"GET /screenshot": func(map[string]any) (int, any) { return http.StatusOK, "iVBORw0KGgo=" },
+	})
+
+	if err := wd.Navigate("https://example.com"); err != nil {
+		t.Fatalf("Navigate() error: %v", err)
+	}
+	if navigated != "https://example.com" {
+		t.Errorf("navigated to %q", navigated)
+	}
+	if title, err := wd.Title(); err != nil || title != "Example Domain" {
+		t.Errorf("Title() = %q, %v", title, err)
+	}
+	if url, err := wd.CurrentURL(); err != nil || url != "https://example.com/" {
+		t.Errorf("CurrentURL() = %q, %v", url, err)
+	}
+	if source, err := wd.Source(); err != nil || source != "<html><body><h1>Hi</h1></body></html>" {
+		t.Errorf("Source() = %q, %v", source, err)
+	}
+	png, err := wd.Screenshot()
+	if err != nil || !bytes.HasPrefix(png, []byte("\x89PNG")) {
+		t.Errorf("Screenshot() = %q, %v", png, err)
+	}
+}
+
+func TestWebDriver_Errors(t *testing.T) {
+	wd := fakeWebDriver(t, map[string]func(map[string]any) (int, any){
+		"POST /url": func(map[string]any) (int, any) {
+			return http.StatusInternalServerError, map[string]string{"error": "timeout", "message": "page load timed out"}
+		},
+		"POST /element": func(map[string]any) (int, any) {
+			return http.StatusNotFound, map[string]string{"error": "no such element", "message": "not found"}
+		},
+	})
+
+	err := wd.Navigate("https://slow.example.com")
+	if !errors.Is(err, ErrPageLoadTimeout) {
+		t.Errorf("expected ErrPageLoadTimeout, got %v", err)
+	}
+
+	start := time.Now()
+	err = wd.WaitFor(".content", 300*time.Millisecond)
+	if !errors.Is(err, ErrPageLoadTimeout) {
+		t.Errorf("expected ErrPageLoadTimeout, got %v", err)
+	}
+	if time.Since(start) < 300*time.Millisecond {
+		t.Error("WaitFor returned before its timeout")
+	}
+}
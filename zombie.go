@@ -0,0 +1,183 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProfileDirPrefix is where the temporary profiles of browsers snag
+// launches go. It is snag's own, rather than go-rod's shared default, so
+// the zombie cleanup never touches browsers other go-rod programs run.
+var ProfileDirPrefix = filepath.Join(os.TempDir(), "snag", "user-data")
+
+// StaleProfileAge is how old an unused temporary profile must be before it
+// is removed, so a profile a concurrent snag run is just launching with is
+// left alone.
+const StaleProfileAge = time.Minute
+
+// processEntry is one line of `ps -A -o pid=,ppid=,args=`.
+type processEntry struct {
+	PID  int
+	PPID int
+	Args string
+}
+
+func parseProcessList(output string) []processEntry {
+	var procs []processEntry
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		pid, err1 := strconv.Atoi(fields[0])
+		ppid, err2 := strconv.Atoi(fields[1])
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		procs = append(procs, processEntry{PID: pid, PPID: ppid, Args: strings.Join(fields[2:], " ")})
+	}
+	return procs
+}
+
+// managedProfileDir returns the temporary profile directory snag gave a
+// browser it launched, or "" if args are not for such a browser.
+func managedProfileDir(args, prefix string) string {
+	_, rest, ok := strings.Cut(args, "--user-data-dir="+prefix+string(filepath.Separator))
+	if !ok {
+		return ""
+	}
+	name, _, _ := strings.Cut(rest, " ")
+	return filepath.Join(prefix, name)
+}
+
+// findZombieBrowsers returns headless browsers snag launched whose snag
+// process is gone. Launched browsers run under a leakless guard, or snag
+// itself where leakless is unsupported, that kills them when snag exits; a
+// browser that has been reparented was left behind by a run that crashed or
+// was killed. Visible browsers from --open-browser are meant to outlive
+// snag and are never returned.
+func findZombieBrowsers(procs []processEntry, prefix string) []processEntry {
+	byPID := make(map[int]processEntry, len(procs))
+	for _, p := range procs {
+		byPID[p.PID] = p
+	}
+
+	var zombies []processEntry
+	for _, p := range procs {
+		if managedProfileDir(p.Args, prefix) == "" ||
+			!strings.Contains(p.Args, "--headless") ||
+			strings.Contains(p.Args, "--type=") {
+			continue
+		}
+
+		parent, ok := byPID[p.PPID]
+		if ok && p.PPID != 1 && isBrowserGuard(parent.Args) {
+			continue
+		}
+		zombies = append(zombies, p)
+	}
+	return zombies
+}
+
+// isBrowserGuard reports whether args are for a process that launches and
+// watches headless browsers: the leakless guard or snag itself.
+func isBrowserGuard(args string) bool {
+	exe, _, _ := strings.Cut(args, " ")
+	return strings.Contains(args, "leakless") || strings.HasPrefix(filepath.Base(exe), "snag")
+}
+
+// staleProfileDirs returns the managed profile directories under prefix
+// that no running browser is using and that are older than
+// StaleProfileAge.
+func staleProfileDirs(procs []processEntry, prefix string) []string {
+	entries, err := os.ReadDir(prefix)
+	if err != nil {
+		return nil
+	}
+
+	inUse := make(map[string]bool)
+	for _, p := range procs {
+		if dir := managedProfileDir(p.Args, prefix); dir != "" {
+			inUse[dir] = true
+		}
+	}
+
+	var stale []string
+	for _, entry := range entries {
+		dir := filepath.Join(prefix, entry.Name())
+		if !entry.IsDir() || inUse[dir] {
+			continue
+		}
+		if info, err := entry.Info(); err != nil || time.Since(info.ModTime()) < StaleProfileAge {
+			continue
+		}
+		stale = append(stale, dir)
+	}
+	return stale
+}
+
+// newProfileDir creates an empty temporary profile directory under
+// ProfileDirPrefix for a browser snag is about to launch.
+func newProfileDir() (string, error) {
+	if err := os.MkdirAll(ProfileDirPrefix, 0700); err != nil {
+		return "", err
+	}
+	return os.MkdirTemp(ProfileDirPrefix, "")
+}
+
+// cleanupZombieBrowsers kills headless browsers left behind by earlier snag
+// runs and removes their temporary profiles, so a new launch does not fail
+// to bind the debugging port or quietly connect to a stale instance.
+func cleanupZombieBrowsers() {
+	output, err := exec.Command("ps", "-A", "-o", "pid=,ppid=,args=").Output()
+	if err != nil {
+		logger.Debug("Skipping zombie browser check: %v", err)
+		return
+	}
+
+	prefix := ProfileDirPrefix
+	procs := parseProcessList(string(output))
+
+	killed := make(map[int]bool)
+	for _, p := range findZombieBrowsers(procs, prefix) {
+		proc, err := os.FindProcess(p.PID)
+		if err == nil {
+			err = proc.Kill()
+		}
+		if err != nil {
+			logger.Debug("Failed to kill leftover browser (PID %d): %v", p.PID, err)
+			continue
+		}
+		killed[p.PID] = true
+		logger.Warning("Killed headless browser left behind by an earlier snag run (PID %d)", p.PID)
+	}
+
+	var running []processEntry
+	for _, p := range procs {
+		if !killed[p.PID] && !killed[p.PPID] {
+			running = append(running, p)
+		}
+	}
+
+	removed := 0
+	for _, dir := range staleProfileDirs(running, prefix) {
+		if err := os.RemoveAll(dir); err != nil {
+			logger.Debug("Failed to remove stale profile %s: %v", dir, err)
+			continue
+		}
+		removed++
+	}
+	if removed > 0 {
+		logger.Verbose("Removed %d stale browser profile%s from %s", removed, plural(removed), prefix)
+	}
+}
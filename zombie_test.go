@@ -0,0 +1,70 @@
+// Copyright (c) 2025 Grant Carthew
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFindZombieBrowsers(t *testing.T) {
+	prefix := ProfileDirPrefix
+	profile := func(name string) string {
+		return "--user-data-dir=" + filepath.Join(prefix, name)
+	}
+
+	procs := parseProcessList(`
+    1     0 /sbin/init
+  500     1 /usr/local/bin/snag -d out/ example.com
+  501   500 /tmp/leakless-amd64/leakless abc
+  502   501 /usr/bin/chromium --headless --remote-debugging-port=9222 ` + profile("live") + `
+  503   502 /usr/bin/chromium --type=renderer ` + profile("live") + `
+  600     1 /usr/bin/chromium --headless --remote-debugging-port=9222 ` + profile("orphan") + `
+  601   600 /usr/bin/chromium --type=renderer ` + profile("orphan") + `
+  700     1 /usr/bin/chromium --remote-debugging-port=9222 ` + profile("visible") + `
+  800   999 /usr/bin/chromium --headless ` + profile("lost-parent") + `
+  900   500 /usr/bin/chromium --headless ` + profile("no-leakless") + `
+  950     1 /usr/bin/chromium --headless --user-data-dir=/home/me/profile
+`)
+
+	var got []int
+	for _, p := range findZombieBrowsers(procs, prefix) {
+		got = append(got, p.PID)
+	}
+
+	want := []int{600, 800}
+	if !slices.Equal(got, want) {
+		t.Errorf("findZombieBrowsers() = %v, want %v", got, want)
+	}
+}
+
+func TestStaleProfileDirs(t *testing.T) {
+	prefix := t.TempDir()
+	old := time.Now().Add(-2 * StaleProfileAge)
+	for _, name := range []string{"live", "stale", "fresh"} {
+		dir := filepath.Join(prefix, name)
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if name != "fresh" {
+			if err := os.Chtimes(dir, old, old); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	procs := []processEntry{{PID: 10, PPID: 1, Args: "chromium --headless --user-data-dir=" + filepath.Join(prefix, "live")}}
+
+	got := staleProfileDirs(procs, prefix)
+	want := []string{filepath.Join(prefix, "stale")}
+	if !slices.Equal(got, want) {
+		t.Errorf("staleProfileDirs() = %v, want %v", got, want)
+	}
+}